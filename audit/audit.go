@@ -9,30 +9,26 @@ import (
 	"strings"
 	"time"
 
-	"github.com/sirupsen/logrus"
+	"github.com/wrfly/container-web-tty/logging"
 )
 
+var log = logging.Module("audit")
+
 type LogOpts struct {
 	Dir, ContainerID, ClientIP string
 }
 
 func LogTo(ctx context.Context, r io.Reader, opts LogOpts) {
-	logDir := opts.Dir
-	if !strings.HasPrefix(logDir, "/") {
-		pwd, err := os.Getwd()
-		if err != nil {
-			logrus.Errorf("audit get pwd error: %s", err)
-			return
-		}
-		logDir = path.Join(pwd, logDir)
+	logDir, err := containerDir(opts.Dir, opts.ContainerID)
+	if err != nil {
+		log.Errorf("audit get pwd error: %s", err)
+		return
 	}
-
-	logDir = path.Join(logDir, opts.ContainerID[:12])
-	_, err := os.Stat(logDir)
+	_, err = os.Stat(logDir)
 	if os.IsNotExist(err) {
-		logrus.Debugf("create dir %s", logDir)
+		log.Debugf("create dir %s", logDir)
 		if err := os.MkdirAll(logDir, 0755); err != nil {
-			logrus.Errorf("mkdir error: %s", err)
+			log.Errorf("mkdir error: %s", err)
 			return
 		}
 	}
@@ -42,7 +38,7 @@ func LogTo(ctx context.Context, r io.Reader, opts LogOpts) {
 
 	f, err := os.Create(fPath)
 	if err != nil {
-		logrus.Errorf("audit create file [%s] error: %s", fPath, err)
+		log.Errorf("audit create file [%s] error: %s", fPath, err)
 		return
 	}
 	defer f.Close()
@@ -55,13 +51,13 @@ func LogTo(ctx context.Context, r io.Reader, opts LogOpts) {
 			if err == io.EOF {
 				return
 			}
-			logrus.Errorf("audit read container error: %s", err)
+			log.Errorf("audit read container error: %s", err)
 			return
 		}
 
 		_, err = f.WriteAt(buff[:n], start)
 		if err != nil {
-			logrus.Errorf("audit write file error: %s", err)
+			log.Errorf("audit write file error: %s", err)
 			return
 		}
 		start += int64(n)
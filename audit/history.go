@@ -0,0 +1,101 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path"
+	"sync"
+
+	"github.com/wrfly/container-web-tty/types"
+)
+
+// historyFile is the exec history in the audit dir of a container,
+// a JSON line of types.Session per exec session or one-shot command
+const historyFile = "history.jsonl"
+
+// historyMux serializes the appends of the history files
+var historyMux sync.Mutex
+
+// Record appends the closed session to the exec history of its container
+func Record(dir string, s types.Session) error {
+	cDir, err := containerDir(dir, s.ContainerID)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(cDir, 0755); err != nil {
+		return err
+	}
+	bs, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	historyMux.Lock()
+	defer historyMux.Unlock()
+	f, err := os.OpenFile(path.Join(cDir, historyFile),
+		os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(bs, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// History returns the last n sessions of the exec history
+// of the container, newest first
+func History(dir, containerID string, n int) ([]types.Session, error) {
+	cDir, err := containerDir(dir, containerID)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path.Join(cDir, historyFile))
+	if os.IsNotExist(err) {
+		return []types.Session{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sessions := make([]types.Session, 0, n)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+	for scanner.Scan() {
+		var s types.Session
+		// skip the line partly written by a crash
+		if json.Unmarshal(scanner.Bytes(), &s) != nil {
+			continue
+		}
+		if len(sessions) == n {
+			sessions = append(sessions[:0], sessions[1:]...)
+		}
+		sessions = append(sessions, s)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(sessions)-1; i < j; i, j = i+1, j-1 {
+		sessions[i], sessions[j] = sessions[j], sessions[i]
+	}
+	return sessions, nil
+}
+
+// containerDir is the audit dir of the container, a relative
+// dir is in the working dir
+func containerDir(dir, containerID string) (string, error) {
+	if !path.IsAbs(dir) {
+		pwd, err := os.Getwd()
+		if err != nil {
+			return "", err
+		}
+		dir = path.Join(pwd, dir)
+	}
+	if len(containerID) > 12 {
+		containerID = containerID[:12]
+	}
+	return path.Join(dir, containerID), nil
+}
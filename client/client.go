@@ -0,0 +1,904 @@
+// Package client drives a container-web-tty server from Go programs,
+// with its REST API and the terminal sessions over websockets.
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/bits"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/wrfly/container-web-tty/types"
+	"github.com/wrfly/container-web-tty/webtty"
+)
+
+// Client of a container-web-tty server
+type Client struct {
+	base       *url.URL
+	authToken  string
+	adminToken string
+
+	httpClient *http.Client
+	dialer     *websocket.Dialer
+}
+
+// Option is an option of New()
+type Option func(*Client)
+
+// WithAuthToken sets the credential of the server (--credential), sent by
+// the init messages of the terminals and the X-Auth-Token of the requests
+func WithAuthToken(token string) Option {
+	return func(c *Client) {
+		c.authToken = token
+	}
+}
+
+// WithAdminToken sets the token of the admin API (--admin-token), it's sent
+// by all the API requests, the raw container IDs are only accepted of the
+// admins if the server signs the URLs with --session-url-ttl
+func WithAdminToken(token string) Option {
+	return func(c *Client) {
+		c.adminToken = token
+	}
+}
+
+// WithHTTPClient replaces http.DefaultClient for the API requests
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithDialer replaces websocket.DefaultDialer for the sessions and events
+func WithDialer(d *websocket.Dialer) Option {
+	return func(c *Client) {
+		c.dialer = d
+	}
+}
+
+// New returns a Client of the server at addr, e.g. "http://127.0.0.1:8080"
+func New(addr string, opts ...Option) (*Client, error) {
+	base, err := url.Parse(strings.TrimSuffix(addr, "/"))
+	if err != nil {
+		return nil, err
+	}
+	if base.Scheme != "http" && base.Scheme != "https" {
+		return nil, fmt.Errorf("bad server address %s, must be http(s)://host:port", addr)
+	}
+
+	c := &Client{
+		base:       base,
+		httpClient: http.DefaultClient,
+		dialer:     websocket.DefaultDialer,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+func (c *Client) httpURL(path string, query url.Values) string {
+	u := *c.base
+	u.Path += path
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+func (c *Client) wsURL(path string, query url.Values) string {
+	u := *c.base
+	u.Scheme = "ws"
+	if c.base.Scheme == "https" {
+		u.Scheme = "wss"
+	}
+	u.Path += path
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// do sends the request and decodes the JSON response into v,
+// error responses are returned as types.APIError
+func (c *Client) do(ctx context.Context, method, path string, body, v interface{}) error {
+	return c.doQuery(ctx, method, path, nil, body, v)
+}
+
+func (c *Client) doQuery(ctx context.Context, method, path string, query url.Values, body, v interface{}) error {
+	var bs []byte
+	if body != nil {
+		var err error
+		if bs, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	resp, err := c.send(ctx, method, path, query, bs, "")
+	if err != nil {
+		return err
+	}
+	// the admin API requires the challenge after the failed logins
+	if resp.StatusCode == http.StatusUnauthorized && resp.Header.Get(challengeHeader) != "" {
+		resp.Body.Close()
+		solution, err := solveChallenge(resp.Header.Get(challengeHeader))
+		if err != nil {
+			return err
+		}
+		if resp, err = c.send(ctx, method, path, query, bs, solution); err != nil {
+			return err
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return responseError(method, path, resp)
+	}
+	if v == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, body []byte, solution string) (*http.Response, error) {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, c.httpURL(path, query), r)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.authToken != "" {
+		req.Header.Set("X-Auth-Token", c.authToken)
+	}
+	if c.adminToken != "" && strings.HasPrefix(path, "/api/") {
+		req.Header.Set("Authorization", "Bearer "+c.adminToken)
+	}
+	if solution != "" {
+		req.Header.Set(solutionHeader, solution)
+	}
+	return c.httpClient.Do(req)
+}
+
+const (
+	challengeHeader = "X-Login-Challenge"
+	solutionHeader  = "X-Login-Solution"
+)
+
+// solveChallenge returns the nonce of the login challenge of the server,
+// "<bits>:<challenge>", the sha256 of the challenge and the nonce starts
+// with the zero bits
+func solveChallenge(header string) (string, error) {
+	parts := strings.SplitN(header, ":", 2)
+	zeros, err := strconv.Atoi(parts[0])
+	if len(parts) != 2 || err != nil || zeros < 1 || zeros > 32 {
+		return "", fmt.Errorf("bad login challenge %q", header)
+	}
+	challenge := parts[1]
+	for nonce := 0; ; nonce++ {
+		sum := sha256.Sum256([]byte(challenge + strconv.Itoa(nonce)))
+		if bits.LeadingZeros32(binary.BigEndian.Uint32(sum[:4])) >= zeros {
+			return strconv.Itoa(nonce), nil
+		}
+	}
+}
+
+// responseError decodes the types.APIError of the response
+func responseError(method, path string, resp *http.Response) error {
+	var apiErr types.APIError
+	if json.NewDecoder(resp.Body).Decode(&apiErr) != nil || apiErr.Message == "" {
+		return fmt.Errorf("%s %s: %s", method, path, resp.Status)
+	}
+	return apiErr
+}
+
+// Logs streams the logs of the container, it's read until
+// the container stops if opts.Follow is true
+func (c *Client) Logs(ctx context.Context, containerID string, opts types.LogOptions) (io.ReadCloser, error) {
+	return c.logs(ctx, containerID, opts, url.Values{})
+}
+
+// ExportLogs downloads the logs of the container as a file, gzip'ed if
+// compress is true, opts.Follow is ignored
+func (c *Client) ExportLogs(ctx context.Context, containerID string, opts types.LogOptions, compress bool) (io.ReadCloser, error) {
+	query := url.Values{"download": []string{"1"}}
+	if compress {
+		query.Set("gzip", "1")
+	}
+	return c.logs(ctx, containerID, opts, query)
+}
+
+func (c *Client) logs(ctx context.Context, containerID string, opts types.LogOptions, query url.Values) (io.ReadCloser, error) {
+	if opts.Follow {
+		query.Set("follow", "1")
+	}
+	if opts.Tail != "" {
+		query.Set("tail", opts.Tail)
+	}
+	if !opts.Since.IsZero() {
+		query.Set("since", opts.Since.Format(time.RFC3339))
+	}
+	if !opts.Until.IsZero() {
+		query.Set("until", opts.Until.Format(time.RFC3339))
+	}
+	if opts.Timestamps {
+		query.Set("timestamps", "1")
+	}
+
+	path := "/api/containers/" + containerID + "/logs"
+	req, err := http.NewRequest(http.MethodGet, c.httpURL(path, query), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		return nil, responseError(http.MethodGet, path, resp)
+	}
+	return resp.Body, nil
+}
+
+// Stats returns a sample of the resource usage of the container
+func (c *Client) Stats(ctx context.Context, containerID string) (types.Stats, error) {
+	var stats types.Stats
+	err := c.doQuery(ctx, http.MethodGet, "/api/containers/"+containerID+"/stats",
+		url.Values{"stream": {"0"}}, nil, &stats)
+	return stats, err
+}
+
+// Top lists the processes of the container
+func (c *Client) Top(ctx context.Context, containerID string) (types.Processes, error) {
+	var top types.Processes
+	err := c.do(ctx, http.MethodGet, "/api/containers/"+containerID+"/top", nil, &top)
+	return top, err
+}
+
+// Detail returns the env and mounts of the container, the env matching the
+// --mask-env of the server are masked unless reveal is true (--reveal-secrets)
+func (c *Client) Detail(ctx context.Context, containerID string, reveal bool) (types.ContainerDetail, error) {
+	var query url.Values
+	if reveal {
+		query = url.Values{"reveal": {"1"}}
+	}
+	var detail types.ContainerDetail
+	err := c.doQuery(ctx, http.MethodGet, "/api/containers/"+containerID+"/detail", query, nil, &detail)
+	return detail, err
+}
+
+// Volumes lists the mounts of the container
+// to be browsed by VolumeFiles and DownloadVolume
+func (c *Client) Volumes(ctx context.Context, containerID string) ([]types.Mount, error) {
+	var mounts []types.Mount
+	err := c.do(ctx, http.MethodGet, "/api/containers/"+containerID+"/volumes", nil, &mounts)
+	return mounts, err
+}
+
+// VolumeFiles lists the directory of the path (relative to the volume)
+// of the volume (the destination of the mount)
+func (c *Client) VolumeFiles(ctx context.Context, containerID, volume, path string) (types.VolumeListing, error) {
+	var listing types.VolumeListing
+	query := url.Values{"volume": {volume}, "path": {path}}
+	err := c.doQuery(ctx, http.MethodGet, "/api/containers/"+containerID+"/volumes/files", query, nil, &listing)
+	return listing, err
+}
+
+// DownloadVolume downloads the file of the path of the volume,
+// it's a tar archive if the path is a directory
+func (c *Client) DownloadVolume(ctx context.Context, containerID, volume, p string) (io.ReadCloser, error) {
+	path := "/api/containers/" + containerID + "/volumes/download"
+	query := url.Values{"volume": {volume}, "path": {p}}
+	req, err := http.NewRequest(http.MethodGet, c.httpURL(path, query), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		return nil, responseError(http.MethodGet, path, resp)
+	}
+	return resp.Body, nil
+}
+
+// CheckHealth runs the healthcheck command of the container on demand,
+// the exit code 0 of the probe is healthy
+func (c *Client) CheckHealth(ctx context.Context, containerID string) (types.HealthProbe, error) {
+	var probe types.HealthProbe
+	err := c.do(ctx, http.MethodPost, "/api/containers/"+containerID+"/health/check", nil, &probe)
+	return probe, err
+}
+
+// Diff lists the files added, changed or deleted by the container
+func (c *Client) Diff(ctx context.Context, containerID string) ([]types.Change, error) {
+	var changes []types.Change
+	err := c.do(ctx, http.MethodGet, "/api/containers/"+containerID+"/diff", nil, &changes)
+	return changes, err
+}
+
+// KillProcess sends the signal (e.g. KILL, empty for TERM) to a process of the
+// container with the kill command, the server must enable the container kill
+func (c *Client) KillProcess(ctx context.Context, containerID string, pid int, signal string) error {
+	var query url.Values
+	if signal != "" {
+		query = url.Values{"signal": {signal}}
+	}
+	return c.doQuery(ctx, http.MethodPost, fmt.Sprintf("/api/containers/%s/top/%d/kill", containerID, pid),
+		query, nil, nil)
+}
+
+// Run runs a one-shot command in the container without a tty
+func (c *Client) Run(ctx context.Context, containerID string, opts types.RunOptions) (types.RunResult, error) {
+	var result types.RunResult
+	err := c.do(ctx, http.MethodPost, "/api/containers/"+containerID+"/run", opts, &result)
+	return result, err
+}
+
+// BatchRun runs a one-shot command in all the containers matching opts.Selector
+func (c *Client) BatchRun(ctx context.Context, opts types.BatchRunOptions) ([]types.BatchResult, error) {
+	var results []types.BatchResult
+	err := c.do(ctx, http.MethodPost, "/api/exec/batch", opts, &results)
+	return results, err
+}
+
+// History lists the exec history of the container, the active sessions first,
+// followed by the last limit (100 if it's 0) closed sessions and one-shot
+// commands. The server must enable the audit (--enable-audit)
+func (c *Client) History(ctx context.Context, containerID string, limit int) ([]types.Session, error) {
+	query := url.Values{}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	var sessions []types.Session
+	err := c.doQuery(ctx, http.MethodGet, "/api/containers/"+containerID+"/history", query, nil, &sessions)
+	return sessions, err
+}
+
+// Timeline lists the restarts, OOM kills and health transitions of the
+// container seen since the server started, the newest first
+func (c *Client) Timeline(ctx context.Context, containerID string) ([]types.Event, error) {
+	var events []types.Event
+	err := c.do(ctx, http.MethodGet, "/api/containers/"+containerID+"/timeline", nil, &events)
+	return events, err
+}
+
+// Sessions lists the active and recently closed terminal sessions
+func (c *Client) Sessions(ctx context.Context) ([]types.Session, error) {
+	return c.sessions(ctx, "/api/sessions")
+}
+
+// AdminSessions lists the sessions like Sessions with the admin API, which
+// is served on the --admin-addr of the server too
+func (c *Client) AdminSessions(ctx context.Context) ([]types.Session, error) {
+	return c.sessions(ctx, "/api/admin/sessions")
+}
+
+func (c *Client) sessions(ctx context.Context, path string) ([]types.Session, error) {
+	var sessions []types.Session
+	for cursor := ""; ; {
+		var page struct {
+			Items      []types.Session
+			NextCursor string `json:"next_cursor"`
+		}
+		if err := c.doQuery(ctx, http.MethodGet, path, pageQuery(cursor), nil, &page); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, page.Items...)
+		if cursor = page.NextCursor; cursor == "" {
+			return sessions, nil
+		}
+	}
+}
+
+// Containers lists the containers of the server
+func (c *Client) Containers(ctx context.Context) ([]types.Container, error) {
+	var containers []types.Container
+	for cursor := ""; ; {
+		var page struct {
+			Items      []types.Container
+			NextCursor string `json:"next_cursor"`
+		}
+		if err := c.doQuery(ctx, http.MethodGet, "/api/containers", pageQuery(cursor), nil, &page); err != nil {
+			return nil, err
+		}
+		containers = append(containers, page.Items...)
+		if cursor = page.NextCursor; cursor == "" {
+			return containers, nil
+		}
+	}
+}
+
+func pageQuery(cursor string) url.Values {
+	if cursor == "" {
+		return nil
+	}
+	return url.Values{"cursor": {cursor}}
+}
+
+// Start the container, the server must enable the container control
+func (c *Client) Start(ctx context.Context, containerID string) error {
+	return c.do(ctx, http.MethodPost, "/container/start/"+containerID, nil, nil)
+}
+
+// Stop the container, the server must enable the container control
+func (c *Client) Stop(ctx context.Context, containerID string) error {
+	return c.do(ctx, http.MethodPost, "/container/stop/"+containerID, nil, nil)
+}
+
+// Restart the container, the server must enable the container control
+func (c *Client) Restart(ctx context.Context, containerID string) error {
+	return c.do(ctx, http.MethodPost, "/container/restart/"+containerID, nil, nil)
+}
+
+// Kill sends the signal (e.g. SIGTERM, empty for SIGKILL) to the container,
+// the server must enable the container control
+func (c *Client) Kill(ctx context.Context, containerID, signal string) error {
+	var query url.Values
+	if signal != "" {
+		query = url.Values{"signal": {signal}}
+	}
+	return c.doQuery(ctx, http.MethodPost, "/container/kill/"+containerID, query, nil, nil)
+}
+
+// Pause the processes of the container, the server must enable the container control
+func (c *Client) Pause(ctx context.Context, containerID string) error {
+	return c.do(ctx, http.MethodPost, "/container/pause/"+containerID, nil, nil)
+}
+
+// Unpause the processes of the container, the server must enable the container control
+func (c *Client) Unpause(ctx context.Context, containerID string) error {
+	return c.do(ctx, http.MethodPost, "/container/unpause/"+containerID, nil, nil)
+}
+
+// Rename the container, the server must enable the container control
+func (c *Client) Rename(ctx context.Context, containerID, name string) error {
+	return c.do(ctx, http.MethodPost, "/api/containers/"+containerID+"/rename",
+		types.RenameOptions{Name: name}, nil)
+}
+
+// Copy copies a file or a directory of a container into a directory of
+// another one, the server must enable it with --control-copy
+func (c *Client) Copy(ctx context.Context, opts types.CopyOptions) (types.CopyResult, error) {
+	var result types.CopyResult
+	err := c.do(ctx, http.MethodPost, "/api/copy", opts, &result)
+	return result, err
+}
+
+// Commit the container to a new image, the server must enable
+// it with --control-commit
+func (c *Client) Commit(ctx context.Context, containerID string, opts types.CommitOptions) (types.CommitResult, error) {
+	var result types.CommitResult
+	err := c.do(ctx, http.MethodPost, "/api/containers/"+containerID+"/commit", opts, &result)
+	return result, err
+}
+
+// Debug launches a debug container sharing the PID and network namespaces
+// of the container, Attach to result.ID with opts.Attach and opts.AttachStdin
+// for the shell of it. The server must enable it with --control-debug
+func (c *Client) Debug(ctx context.Context, containerID string) (types.DebugResult, error) {
+	var result types.DebugResult
+	err := c.do(ctx, http.MethodPost, "/api/containers/"+containerID+"/debug", nil, &result)
+	return result, err
+}
+
+// SessionURL issues the exec URL of the container with the exec options,
+// it's a token sealing them if the server signs them with --session-url-ttl,
+// valid from the ip only, or from this client if it's empty. The token (the
+// path segment after /exec/) is Attach()ed as the container ID, the options
+// of Attach are ignored then. It's an admin API (WithAdminToken)
+func (c *Client) SessionURL(ctx context.Context, containerID string, opts types.ExecOptions, ip string) (types.SessionURL, error) {
+	var result types.SessionURL
+	query := execArgs(opts)
+	if ip != "" {
+		query.Set("ip", ip)
+	}
+	err := c.doQuery(ctx, http.MethodGet, "/api/containers/"+containerID+"/session-url", query, nil, &result)
+	return result, err
+}
+
+// Prune removes the unused resources of the kind (types.PruneKinds) with
+// the admin API, nothing is removed but reported if dryRun is true.
+// The client must be created WithAdminToken
+func (c *Client) Prune(ctx context.Context, kind string, dryRun bool) (types.PruneReport, error) {
+	method := http.MethodPost
+	if dryRun {
+		method = http.MethodGet
+	}
+	var report types.PruneReport
+	err := c.do(ctx, method, "/api/admin/prune/"+kind, nil, &report)
+	return report, err
+}
+
+// Errors lists the recent panics recovered by the server, newest first.
+// The client must be created WithAdminToken
+func (c *Client) Errors(ctx context.Context) ([]types.ErrorReport, error) {
+	var reports []types.ErrorReport
+	err := c.do(ctx, http.MethodGet, "/api/admin/errors", nil, &reports)
+	return reports, err
+}
+
+// Usage reports the daily usage of the terminal sessions closed since
+// the server started, by the exec user or the container ("" for both of
+// them), of the days from and to (the last 7 days by default)
+func (c *Client) Usage(ctx context.Context, by string, from, to time.Time) ([]types.Usage, error) {
+	query := url.Values{}
+	if by != "" {
+		query.Set("by", by)
+	}
+	if !from.IsZero() {
+		query.Set("from", from.UTC().Format("2006-01-02"))
+	}
+	if !to.IsZero() {
+		query.Set("to", to.UTC().Format("2006-01-02"))
+	}
+	var report []types.Usage
+	err := c.doQuery(ctx, http.MethodGet, "/api/reports", query, nil, &report)
+	return report, err
+}
+
+// Diagnostics downloads the support bundle of the server with the admin
+// API, a tar.gz of its version, config (the secrets redacted), checks,
+// recent panics and goroutines
+func (c *Client) Diagnostics(ctx context.Context) (io.ReadCloser, error) {
+	path := "/api/admin/diagnostics"
+	resp, err := c.send(ctx, http.MethodGet, path, nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		return nil, responseError(http.MethodGet, path, resp)
+	}
+	return resp.Body, nil
+}
+
+// Journal lists the events of the server kept across its restarts with
+// the admin API, newest first, of the kind (any if it's empty), before the
+// ID to page (any if it's 0) and at most limit of them (100 if it's 0)
+func (c *Client) Journal(ctx context.Context, kind string, before int64, limit int) ([]types.ServerEvent, error) {
+	query := url.Values{}
+	if kind != "" {
+		query.Set("kind", kind)
+	}
+	if before > 0 {
+		query.Set("before", strconv.FormatInt(before, 10))
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	var events []types.ServerEvent
+	err := c.doQuery(ctx, http.MethodGet, "/api/admin/journal", query, nil, &events)
+	return events, err
+}
+
+// Version reports the build of the server and the versions of its backend
+func (c *Client) Version(ctx context.Context) (types.Version, error) {
+	var v types.Version
+	err := c.do(ctx, http.MethodGet, "/api/version", nil, &v)
+	return v, err
+}
+
+// UpdateCheck reports the latest release found by the update check of the
+// server with the admin API, a 404 error if it's disabled
+func (c *Client) UpdateCheck(ctx context.Context) (types.UpdateCheck, error) {
+	var u types.UpdateCheck
+	err := c.do(ctx, http.MethodGet, "/api/admin/update", nil, &u)
+	return u, err
+}
+
+// ConfigOptions lists the effective options of the server, their values
+// and sources, with the admin API
+func (c *Client) ConfigOptions(ctx context.Context) ([]types.ConfigOption, error) {
+	var options []types.ConfigOption
+	err := c.do(ctx, http.MethodGet, "/api/admin/config", nil, &options)
+	return options, err
+}
+
+// LogLevels reports the levels of the logs of the server with the admin
+// API
+func (c *Client) LogLevels(ctx context.Context) (types.LogLevels, error) {
+	var levels types.LogLevels
+	err := c.do(ctx, http.MethodGet, "/api/admin/log/levels", nil, &levels)
+	return levels, err
+}
+
+// SetLogLevels changes the levels of the logs of the server until it
+// restarts, the modules left out follow levels.Level
+func (c *Client) SetLogLevels(ctx context.Context, levels types.LogLevels) (types.LogLevels, error) {
+	var set types.LogLevels
+	err := c.do(ctx, http.MethodPut, "/api/admin/log/levels", levels, &set)
+	return set, err
+}
+
+// DebugLog logs the requests and the sessions of the user, the client or
+// the container (debug.Field) at the debug level for debug.Duration, 10
+// minutes by default, and returns the debugged ones
+func (c *Client) DebugLog(ctx context.Context, debug types.LogDebug) ([]types.LogDebug, error) {
+	var debugs []types.LogDebug
+	err := c.do(ctx, http.MethodPost, "/api/admin/log/debug", debug, &debugs)
+	return debugs, err
+}
+
+// Drain drains the server with the admin API, it stops accepting new
+// sessions and exits after the existing ones are closed or the timeout
+// passes, 0 for the --drain-timeout of the server
+func (c *Client) Drain(ctx context.Context, timeout time.Duration) (types.DrainStatus, error) {
+	var query url.Values
+	if timeout > 0 {
+		query = url.Values{"timeout": {timeout.String()}}
+	}
+	var status types.DrainStatus
+	err := c.doQuery(ctx, http.MethodPost, "/api/admin/drain", query, nil, &status)
+	return status, err
+}
+
+// DrainStatus reports the drain of the server with the admin API
+func (c *Client) DrainStatus(ctx context.Context) (types.DrainStatus, error) {
+	var status types.DrainStatus
+	err := c.do(ctx, http.MethodGet, "/api/admin/drain", nil, &status)
+	return status, err
+}
+
+// Maintenance reports the maintenance mode of the server with the admin API
+func (c *Client) Maintenance(ctx context.Context) (types.Maintenance, error) {
+	var m types.Maintenance
+	err := c.do(ctx, http.MethodGet, "/api/admin/maintenance", nil, &m)
+	return m, err
+}
+
+// SetMaintenance turns the maintenance mode on or off with the admin API,
+// the new sessions and commands are refused with the message while it's
+// on, the running sessions are left as they are
+func (c *Client) SetMaintenance(ctx context.Context, enabled bool, message string) (types.Maintenance, error) {
+	var m types.Maintenance
+	err := c.do(ctx, http.MethodPut, "/api/admin/maintenance",
+		types.Maintenance{Enabled: enabled, Message: message}, &m)
+	return m, err
+}
+
+// KillSession closes the running or the detached terminal session of the
+// ID with the admin API
+func (c *Client) KillSession(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/api/admin/sessions/"+id, nil, nil)
+}
+
+// Replay downloads the recent output of the running or the detached
+// session with the admin API, the server must keep it with
+// --resume-timeout and --replay-buffer
+func (c *Client) Replay(ctx context.Context, id string) (io.ReadCloser, error) {
+	path := "/api/admin/sessions/" + id + "/replay"
+	resp, err := c.send(ctx, http.MethodGet, path, nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		return nil, responseError(http.MethodGet, path, resp)
+	}
+	return resp.Body, nil
+}
+
+// CreateToken creates an admin token with the admin API, which is used as
+// the --admin-token until it's revoked or expired. The token is only in
+// the result
+func (c *Client) CreateToken(ctx context.Context, opts types.AdminTokenOptions) (types.AdminToken, error) {
+	var token types.AdminToken
+	err := c.do(ctx, http.MethodPost, "/api/admin/tokens", opts, &token)
+	return token, err
+}
+
+// RevokeToken revokes the admin token of the ID with the admin API
+func (c *Client) RevokeToken(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/api/admin/tokens/"+id, nil, nil)
+}
+
+// Create creates and starts a container with the admin API, Attach to
+// result.ID with opts.Attach and opts.AttachStdin for the terminal of it.
+// The server must enable it with --control-create
+func (c *Client) Create(ctx context.Context, opts types.CreateOptions) (types.CreateResult, error) {
+	var result types.CreateResult
+	err := c.do(ctx, http.MethodPost, "/api/admin/containers", opts, &result)
+	return result, err
+}
+
+// UpdateLabels sets and removes the labels of the container (not supported by docker),
+// the server must enable the container control
+func (c *Client) UpdateLabels(ctx context.Context, containerID string, update types.LabelsUpdate) error {
+	return c.do(ctx, http.MethodPatch, "/api/containers/"+containerID+"/labels", update, nil)
+}
+
+// Events subscribes the container events of the given actions (all if empty),
+// the channel is closed when ctx is done or the connection is lost
+func (c *Client) Events(ctx context.Context, actions ...string) (<-chan types.Event, error) {
+	query := url.Values{}
+	if len(actions) != 0 {
+		query.Set("action", strings.Join(actions, ","))
+	}
+	conn, _, err := c.dialer.DialContext(ctx, c.wsURL("/api/events", query), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan types.Event)
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+	go func() {
+		defer close(events)
+		for {
+			var e types.Event
+			if err := conn.ReadJSON(&e); err != nil {
+				return
+			}
+			select {
+			case events <- e:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+// initMessage is the first message of a session, see route.readInitMessage
+func (c *Client) initMessage(opts types.ExecOptions) ([]byte, error) {
+	return json.Marshal(types.InitMessage{
+		Arguments: "?" + execArgs(opts).Encode(),
+		AuthToken: c.authToken,
+	})
+}
+
+// execArgs returns the query of the exec options
+func execArgs(opts types.ExecOptions) url.Values {
+	args := url.Values{}
+	if opts.Cmd != "" {
+		args.Set("cmd", opts.Cmd)
+	}
+	if opts.Env != "" {
+		args.Set("env", opts.Env)
+	}
+	if opts.User != "" {
+		args.Set("user", opts.User)
+	}
+	if opts.Privileged {
+		args.Set("p", "1")
+	}
+	if opts.NoTTY {
+		args.Set("tty", "0")
+	}
+	if opts.Attach {
+		args.Set("attach", "1")
+	}
+	if opts.AttachStdin {
+		args.Set("stdin", "1")
+	}
+	return args
+}
+
+// Attach execs into the container, the session is a terminal if
+// opts.NoTTY is false, otherwise the stderr is separated. With opts.Attach,
+// it's attached to the main process of the container instead
+func (c *Client) Attach(ctx context.Context, containerID string, opts types.ExecOptions) (*Session, error) {
+	init, err := c.initMessage(opts)
+	if err != nil {
+		return nil, err
+	}
+	return c.dialSession(ctx, c.wsURL("/exec/"+containerID+"/ws", nil), init)
+}
+
+func (c *Client) dialSession(ctx context.Context, wsURL string, init []byte) (*Session, error) {
+	dialer := *c.dialer
+	dialer.Subprotocols = webtty.Protocols
+	conn, _, err := dialer.DialContext(ctx, wsURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.WriteMessage(websocket.TextMessage, init); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return newSession(conn), nil
+}
+
+// Provision starts a terminal session in the container to be joined later,
+// the output is buffered until the JoinURL is opened in a browser or Join()ed,
+// it's an admin API (WithAdminToken)
+func (c *Client) Provision(ctx context.Context, containerID string, opts types.ProvisionOptions) (types.ProvisionResult, error) {
+	var result types.ProvisionResult
+	err := c.do(ctx, http.MethodPost, "/api/admin/containers/"+containerID+"/provision", opts, &result)
+	return result, err
+}
+
+// Forward returns a temporary URL forwarded to the HTTP server listening on
+// the port of the container, the server must enable it with --forward-ttl
+func (c *Client) Forward(ctx context.Context, containerID string, port int, opts types.ForwardOptions) (types.ForwardResult, error) {
+	var result types.ForwardResult
+	err := c.do(ctx, http.MethodPost, fmt.Sprintf("/api/containers/%s/forward/%d", containerID, port), opts, &result)
+	return result, err
+}
+
+// Tunnel connects to the TCP port of the container through the server,
+// the server must enable it with --forward-ttl
+func (c *Client) Tunnel(ctx context.Context, containerID string, port int) (io.ReadWriteCloser, error) {
+	path := fmt.Sprintf("/api/containers/%s/forward/%d", containerID, port)
+	conn, resp, err := c.dialer.DialContext(ctx, c.wsURL(path, nil), nil)
+	if err != nil {
+		if resp != nil && resp.StatusCode >= 300 {
+			return nil, responseError(http.MethodGet, path, resp)
+		}
+		return nil, err
+	}
+	return &tunnel{conn: conn}, nil
+}
+
+// tunnel reads and writes the binary messages of a websocket as a stream
+type tunnel struct {
+	conn *websocket.Conn
+	r    io.Reader
+}
+
+func (t *tunnel) Read(p []byte) (int, error) {
+	for {
+		if t.r == nil {
+			_, r, err := t.conn.NextReader()
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				return 0, io.EOF
+			}
+			if err != nil {
+				return 0, err
+			}
+			t.r = r
+		}
+		n, err := t.r.Read(p)
+		if err == io.EOF {
+			t.r = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+func (t *tunnel) Write(p []byte) (int, error) {
+	if err := t.conn.WriteMessage(websocket.BinaryMessage, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (t *tunnel) Close() error {
+	return t.conn.Close()
+}
+
+// Join joins a provisioned session by its JoinURL
+func (c *Client) Join(ctx context.Context, joinURL string) (*Session, error) {
+	u, err := url.Parse(joinURL)
+	if err != nil {
+		return nil, err
+	}
+	init, err := c.initMessage(types.ExecOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return c.dialSession(ctx, c.wsURL(strings.TrimSuffix(u.Path, "/")+"/ws", nil), init)
+}
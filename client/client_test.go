@@ -0,0 +1,3936 @@
+package client
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha512"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/http2"
+
+	"github.com/wrfly/container-web-tty/config"
+	"github.com/wrfly/container-web-tty/event"
+	"github.com/wrfly/container-web-tty/route"
+	"github.com/wrfly/container-web-tty/types"
+	"github.com/wrfly/container-web-tty/webtty"
+)
+
+// echoTTY echoes the input until "exit\n"
+type echoTTY struct {
+	r *io.PipeReader
+	w *io.PipeWriter
+}
+
+func newEchoTTY() *echoTTY {
+	r, w := io.Pipe()
+	return &echoTTY{r: r, w: w}
+}
+
+func (t *echoTTY) Read(p []byte) (int, error) { return t.r.Read(p) }
+
+func (t *echoTTY) Write(p []byte) (int, error) {
+	if string(p) == "exit\n" {
+		t.w.Close()
+		return len(p), nil
+	}
+	return t.w.Write(p)
+}
+
+func (t *echoTTY) WindowTitleVariables() map[string]interface{} { return nil }
+func (t *echoTTY) ResizeTerminal(columns int, rows int) error   { return nil }
+func (t *echoTTY) Exit() error                                  { return t.w.Close() }
+func (t *echoTTY) ActiveChan() <-chan struct{}                  { return nil }
+func (t *echoTTY) Stderr() io.Reader                            { return nil }
+func (t *echoTTY) ExitCode() (int, error)                       { return 3, nil }
+
+// mainTTY is the main process of the fake container, it writes
+// "resized" when it's resized
+type mainTTY struct{ *echoTTY }
+
+func (t mainTTY) ResizeTerminal(columns int, rows int) error {
+	_, err := t.w.Write([]byte("resized\n"))
+	return err
+}
+
+// newStreamTTY writes the size of output at the rate (bytes per second,
+// 0 for unlimited) like `yes`, then exits
+func newStreamTTY(size, rate int) *echoTTY {
+	t := newEchoTTY()
+	go func() {
+		defer t.w.Close()
+		chunk := bytes.Repeat([]byte("y\r\n"), 4<<10/3)
+		start := time.Now()
+		for sent := 0; sent < size; {
+			n := len(chunk)
+			if size-sent < n {
+				n = size - sent
+			}
+			if _, err := t.w.Write(chunk[:n]); err != nil {
+				return
+			}
+			sent += n
+			if rate > 0 {
+				time.Sleep(time.Until(start.Add(time.Duration(sent) * time.Second / time.Duration(rate))))
+			}
+		}
+	}()
+	return t
+}
+
+// fakeCli is a backend with a single container "abc"
+type fakeCli struct{}
+
+func (fakeCli) GetInfo(ctx context.Context, cid string) types.Container {
+	if cid != "abc" {
+		return types.Container{}
+	}
+	return types.Container{ID: "abc", Name: "fake", Shell: "/bin/sh",
+		IPs: []string{"127.0.0.1"}, Labels: map[string]string{"app": "web"}}
+}
+func (f fakeCli) List(ctx context.Context) []types.Container {
+	return []types.Container{f.GetInfo(ctx, "abc")}
+}
+func (fakeCli) Start(ctx context.Context, cid string) error   { return nil }
+func (fakeCli) Stop(ctx context.Context, cid string) error    { return nil }
+func (fakeCli) Restart(ctx context.Context, cid string) error { return nil }
+func (fakeCli) Pause(ctx context.Context, cid string) error   { return nil }
+func (fakeCli) Kill(ctx context.Context, cid, signal string) error {
+	if signal != "SIGHUP" {
+		return fmt.Errorf("unexpected signal %s", signal)
+	}
+	return nil
+}
+func (fakeCli) Top(ctx context.Context, cid string) (types.Processes, error) {
+	return types.Processes{
+		Titles:    []string{"PID", "USER", "COMMAND"},
+		Processes: [][]string{{"1", "root", "sleep 60"}},
+	}, nil
+}
+func (fakeCli) Inspect(ctx context.Context, cid string) (types.ContainerDetail, error) {
+	return types.ContainerDetail{
+		Env:    []types.EnvVar{{Name: "DB_PASSWORD", Value: "hunter2"}, {Name: "PATH", Value: "/bin"}},
+		Mounts: []types.Mount{{Type: "bind", Source: "/data", Destination: "/var/lib/data"}},
+		Health: &types.Health{Cmd: "curl -f localhost", Status: "unhealthy", FailingStreak: 3},
+	}, nil
+}
+
+// fileArchive is the archive of a file of the content
+func fileArchive(hdr tar.Header, content string) []byte {
+	buf := new(bytes.Buffer)
+	tw := tar.NewWriter(buf)
+	hdr.Size = int64(len(content))
+	tw.WriteHeader(&hdr)
+	tw.Write([]byte(content))
+	tw.Close()
+	return buf.Bytes()
+}
+
+// CopyFrom returns the archive of the file of the base name of the path
+// containing the path, or a file of the kind of the path under /tmp/
+func (fakeCli) CopyFrom(ctx context.Context, cid, path string) (io.ReadCloser, error) {
+	if strings.HasPrefix(path, "/tmp/") {
+		hdr := tar.Header{Mode: 0644, Typeflag: tar.TypeReg}
+		content := ""
+		switch kind := path[len("/tmp/"):]; kind {
+		case "device":
+			hdr.Name, hdr.Typeflag = "device", tar.TypeChar
+		case "traversal":
+			hdr.Name = "../etc/cron.d/evil"
+		case "html":
+			hdr.Name, content = "page", "<html><body>hi</body></html>"
+		case "contiguous":
+			hdr.Name, hdr.Typeflag, content = kind, tar.TypeCont, strings.Repeat("x", 100)
+		default:
+			hdr.Name, content = kind, strings.Repeat("x", 100)
+		}
+		return ioutil.NopCloser(bytes.NewReader(fileArchive(hdr, content))), nil
+	}
+	if path != "/var/lib/data" {
+		hdr := tar.Header{Name: filepath.Base(path), Mode: 0755, Typeflag: tar.TypeReg}
+		return ioutil.NopCloser(bytes.NewReader(fileArchive(hdr, path))), nil
+	}
+	// the archive of the volume
+	buf := new(bytes.Buffer)
+	tw := tar.NewWriter(buf)
+	for _, f := range []struct{ name, content string }{
+		{"data/", ""},
+		{"data/db/", ""},
+		{"data/db/a.db", "aaaa"},
+		{"data/db/b.db", "bb"},
+		{"data/README", "hello"},
+	} {
+		hdr := &tar.Header{Name: f.name, Mode: 0644, Size: int64(len(f.content)), Typeflag: tar.TypeReg}
+		if strings.HasSuffix(f.name, "/") {
+			hdr.Mode, hdr.Typeflag = 0755, tar.TypeDir
+		}
+		tw.WriteHeader(hdr)
+		tw.Write([]byte(f.content))
+	}
+	tw.WriteHeader(&tar.Header{Name: "data/passwd", Linkname: "/etc/passwd", Typeflag: tar.TypeSymlink})
+	tw.Close()
+	return ioutil.NopCloser(buf), nil
+}
+func (fakeCli) CopyTo(ctx context.Context, cid, dir string, content io.Reader) error {
+	tr := tar.NewReader(content)
+	hdr, err := tr.Next()
+	if err != nil {
+		return err
+	}
+	bs, _ := ioutil.ReadAll(tr)
+	if _, err := tr.Next(); err != io.EOF || hdr.Name != "strace" || string(bs) != "/usr/bin/strace" || dir != "/tmp" {
+		return fmt.Errorf("unexpected copy of %s (%s) to %s", hdr.Name, bs, dir)
+	}
+	return nil
+}
+func (fakeCli) Diff(ctx context.Context, cid string) ([]types.Change, error) {
+	return []types.Change{{Kind: "C", Path: "/etc"}, {Kind: "A", Path: "/etc/app.conf"}}, nil
+}
+func (fakeCli) Commit(ctx context.Context, cid string, opts types.CommitOptions) (string, error) {
+	if opts.Image == "panic" {
+		panic("commit panic")
+	}
+	return "sha256:" + opts.Image, nil
+}
+func (fakeCli) Debug(ctx context.Context, cid string, opts types.DebugOptions) (string, error) {
+	return "debug-" + cid + "-" + opts.Image, nil
+}
+func (fakeCli) Prune(ctx context.Context, kind string, dryRun bool) (types.PruneReport, error) {
+	return types.PruneReport{Kind: kind, DryRun: dryRun,
+		Items: []types.PruneItem{{ID: "old", Size: 42}}, Reclaimed: 42}, nil
+}
+func (fakeCli) Create(ctx context.Context, opts types.CreateOptions) (string, error) {
+	if len(opts.Env) != 1 || opts.Env[0] != "A=1" {
+		return "", fmt.Errorf("unexpected env %v", opts.Env)
+	}
+	return "new-" + opts.Name, nil
+}
+func (fakeCli) Rename(ctx context.Context, cid, name string) error {
+	return nil
+}
+func (fakeCli) UpdateLabels(ctx context.Context, cid string, update types.LabelsUpdate) error {
+	if update.Set["team"] != "web" || len(update.Remove) != 1 || update.Remove[0] != "legacy" {
+		return fmt.Errorf("unexpected labels update %+v", update)
+	}
+	return nil
+}
+func (fakeCli) Unpause(ctx context.Context, cid string) error {
+	return fmt.Errorf("container %s is not paused", cid)
+}
+func (fakeCli) Exec(ctx context.Context, c types.Container) (types.TTY, error) {
+	if c.Exec.User == "nobody" {
+		return nil, fmt.Errorf("unable to find user nobody: no matching entries in passwd file")
+	}
+	var size, rate int
+	if _, err := fmt.Sscanf(c.Exec.Cmd, "stream %d %d", &size, &rate); err == nil {
+		return newStreamTTY(size, rate), nil
+	}
+	if c.Exec.Attach {
+		return mainTTY{newEchoTTY()}, nil
+	}
+	return newEchoTTY(), nil
+}
+func (fakeCli) Run(ctx context.Context, c types.Container) (types.RunResult, error) {
+	if c.Exec.Cmd == "kill -s HUP 1" {
+		return types.RunResult{}, nil
+	}
+	return types.RunResult{Stdout: c.Exec.Cmd, ExitCode: 1}, nil
+}
+func (fakeCli) Ping(ctx context.Context) error { return nil }
+func (fakeCli) Close() error                   { return nil }
+func (fakeCli) Version(ctx context.Context) ([]types.BackendVersion, error) {
+	return []types.BackendVersion{{Name: "fake", Version: "1.0"}}, nil
+}
+
+// Logs returns the options as the logs, or three lines of 2019-04-01T10:00:0[0-2]Z
+// if it's timestamped from the beginning, or the lines "line 0".."line <tail-1>"
+// at once if it's followed
+func (fakeCli) Logs(ctx context.Context, opts types.LogOptions) (io.ReadCloser, error) {
+	if n, _ := strconv.Atoi(opts.Tail); opts.Follow && n > 0 {
+		r, w := io.Pipe()
+		go func() {
+			bw := bufio.NewWriter(w)
+			for i := 0; i < n; i++ {
+				fmt.Fprintf(bw, "line %d\n", i)
+			}
+			bw.Flush()
+			w.Close()
+		}()
+		return r, nil
+	}
+	if opts.Timestamps && opts.Since.IsZero() {
+		return ioutil.NopCloser(strings.NewReader("2019-04-01T10:00:00.5Z line 0\n" +
+			"2019-04-01T10:00:01Z line 1\n2019-04-01T10:00:02Z line 2\n")), nil
+	}
+	return ioutil.NopCloser(strings.NewReader(fmt.Sprintf("tail=%s follow=%v timestamps=%v since=%d\n",
+		opts.Tail, opts.Follow, opts.Timestamps, opts.Since.Unix()))), nil
+}
+
+func (fakeCli) Stats(ctx context.Context, cid string) (<-chan types.Stats, error) {
+	ch := make(chan types.Stats, 1)
+	ch <- types.Stats{CPUPercent: 50, MemoryUsage: 1 << 20, PIDs: 2}
+	close(ch)
+	return ch, nil
+}
+
+func newTestServer(t *testing.T) (*Client, func()) {
+	return newTestServerWith(t, config.ServerConfig{
+		RunTimeout:   time.Second,
+		ProvisionTTL: time.Minute,
+	})
+}
+
+func newTestServerWith(t testing.TB, conf config.ServerConfig, opts ...Option) (*Client, func()) {
+	gin.SetMode(gin.TestMode)
+	srv, err := route.New(fakeCli{}, event.NewHub(), conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(srv.Handler())
+	c, err := New(ts.URL, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, ts.Close
+}
+
+func TestRun(t *testing.T) {
+	c, closeServer := newTestServer(t)
+	defer closeServer()
+	ctx := context.Background()
+
+	result, err := c.Run(ctx, "abc", types.RunOptions{Cmd: "hostname"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Stdout != "hostname" || result.ExitCode != 1 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	_, err = c.Run(ctx, "xyz", types.RunOptions{Cmd: "hostname"})
+	apiErr, ok := err.(types.APIError)
+	if !ok || apiErr.Code != 404 || apiErr.RequestID == "" {
+		t.Fatalf("expect an API error of unknown container, got %v", err)
+	}
+
+	containers, err := c.Containers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(containers) != 1 || containers[0].ID != "abc" {
+		t.Fatalf("unexpected containers: %+v", containers)
+	}
+}
+
+func TestRunLimits(t *testing.T) {
+	c, closeServer := newTestServerWith(t, config.ServerConfig{
+		Credential: "pass",
+		RunTimeout: time.Minute,
+	}, WithAuthToken("pass"))
+	defer closeServer()
+	ctx := context.Background()
+
+	if _, err := c.Run(ctx, "abc", types.RunOptions{Cmd: "hostname"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, timeout := range []string{"0s", "-1s"} {
+		_, err := c.Run(ctx, "abc", types.RunOptions{Cmd: "hostname", Timeout: timeout})
+		if apiErr, ok := err.(types.APIError); !ok || apiErr.Code != 400 {
+			t.Fatalf("expect an API error of the timeout %s, got %v", timeout, err)
+		}
+	}
+
+	anonymous := *c
+	anonymous.authToken = ""
+	_, err := anonymous.Run(ctx, "abc", types.RunOptions{Cmd: "hostname"})
+	if apiErr, ok := err.(types.APIError); !ok || apiErr.Code != 401 {
+		t.Fatalf("expect an API error without the credential, got %v", err)
+	}
+}
+
+func TestLogs(t *testing.T) {
+	c, closeServer := newTestServer(t)
+	defer closeServer()
+	ctx := context.Background()
+
+	since := time.Unix(1554112800, 0)
+	rc, err := c.Logs(ctx, "abc", types.LogOptions{Tail: "5", Timestamps: true, Since: since})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	logs, err := ioutil.ReadAll(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(logs) != "tail=5 follow=false timestamps=true since=1554112800\n" {
+		t.Fatalf("unexpected logs: %q", logs)
+	}
+
+	if _, err := c.Logs(ctx, "abc", types.LogOptions{Tail: "-1"}); err == nil {
+		t.Fatal("expect an error of a bad tail")
+	}
+	if _, err := c.Logs(ctx, "nope", types.LogOptions{}); err == nil {
+		t.Fatal("expect an error of an unknown container")
+	}
+}
+
+func TestLogsDropped(t *testing.T) {
+	c, closeServer := newTestServerWith(t, config.ServerConfig{LogsBuffer: 4096})
+	defer closeServer()
+
+	rc, err := c.Logs(context.Background(), "abc", types.LogOptions{Tail: "300000", Follow: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	// too slow for the container
+	time.Sleep(300 * time.Millisecond)
+	logs, err := ioutil.ReadAll(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(logs, []byte(" lines dropped, the client is too slow]\n")) ||
+		!bytes.HasSuffix(logs, []byte("\nline 299999\n")) {
+		t.Fatalf("unexpected logs of %d bytes: %q...", len(logs), logs[len(logs)-200:])
+	}
+}
+
+func TestStats(t *testing.T) {
+	c, closeServer := newTestServer(t)
+	defer closeServer()
+	ctx := context.Background()
+
+	stats, err := c.Stats(ctx, "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.CPUPercent != 50 || stats.MemoryUsage != 1<<20 || stats.PIDs != 2 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if _, err := c.Stats(ctx, "nope"); err == nil {
+		t.Fatal("expect an error of an unknown container")
+	}
+}
+
+func TestTopAndDiff(t *testing.T) {
+	c, closeServer := newTestServer(t)
+	defer closeServer()
+
+	top, err := c.Top(context.Background(), "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(top.Titles) != 3 || len(top.Processes) != 1 || top.Processes[0][2] != "sleep 60" {
+		t.Fatalf("unexpected processes: %+v", top)
+	}
+
+	changes, err := c.Diff(context.Background(), "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 || changes[1] != (types.Change{Kind: "A", Path: "/etc/app.conf"}) {
+		t.Fatalf("unexpected changes: %+v", changes)
+	}
+}
+
+func TestDetail(t *testing.T) {
+	c, closeServer := newTestServerWith(t, config.ServerConfig{MaskEnv: "(?i)PASSWORD"})
+	defer closeServer()
+	ctx := context.Background()
+
+	detail, err := c.Detail(ctx, "abc", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(detail.Env) != 2 || !detail.Env[0].Masked || detail.Env[0].Value == "hunter2" ||
+		detail.Env[1].Masked || detail.Env[1].Value != "/bin" {
+		t.Fatalf("unexpected env: %+v", detail.Env)
+	}
+	if len(detail.Mounts) != 1 || detail.Mounts[0].Destination != "/var/lib/data" {
+		t.Fatalf("unexpected mounts: %+v", detail.Mounts)
+	}
+	if _, err := c.Detail(ctx, "abc", true); err == nil {
+		t.Fatal("expect an error of revealing without permission")
+	}
+}
+
+func TestCheckHealth(t *testing.T) {
+	c, closeServer := newTestServer(t)
+	defer closeServer()
+	ctx := context.Background()
+
+	detail, err := c.Detail(ctx, "abc", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if detail.Health == nil || detail.Health.Status != "unhealthy" {
+		t.Fatalf("unexpected health: %+v", detail.Health)
+	}
+	probe, err := c.CheckHealth(ctx, "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if probe.ExitCode != 1 || probe.Output != "curl -f localhost" || probe.End.Before(probe.Start) {
+		t.Fatalf("unexpected probe: %+v", probe)
+	}
+}
+
+func TestForward(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Cookie") != "" || r.Header.Get("Authorization") != "" {
+			t.Errorf("the credentials are forwarded: %v", r.Header)
+		}
+		http.SetCookie(w, &http.Cookie{Name: "csrf_token", Value: "forged"})
+		fmt.Fprintf(w, "debug %s", r.URL.Path)
+	}))
+	defer backend.Close()
+	port, _ := strconv.Atoi(backend.URL[strings.LastIndex(backend.URL, ":")+1:])
+
+	c, closeServer := newTestServerWith(t, config.ServerConfig{ForwardTTL: time.Minute})
+	defer closeServer()
+	ctx := context.Background()
+
+	result, err := c.Forward(ctx, "abc", port, types.ForwardOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a path without --external-url
+	if !strings.HasPrefix(result.URL, "/forward/") {
+		t.Fatalf("unexpected URL: %s", result.URL)
+	}
+	req, _ := http.NewRequest(http.MethodGet, c.httpURL(result.URL+"vars", nil), nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	req.Header.Set("Cookie", "admin_session=s3cret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "debug /vars" {
+		t.Fatalf("unexpected response: %q", body)
+	}
+	// sandboxed on the origin of the UI
+	if csp := resp.Header.Get("Content-Security-Policy"); csp != "sandbox" {
+		t.Fatalf("unexpected CSP: %q", csp)
+	}
+	for _, cookie := range resp.Cookies() {
+		if cookie.Value == "forged" {
+			t.Fatalf("the cookie of the app is set: %+v", cookie)
+		}
+	}
+
+	tun, err := c.Tunnel(ctx, "abc", port)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tun.Close()
+	fmt.Fprintf(tun, "GET /metrics HTTP/1.0\r\n\r\n")
+	resp, err = http.ReadResponse(bufio.NewReader(tun), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ = ioutil.ReadAll(resp.Body)
+	if string(body) != "debug /metrics" {
+		t.Fatalf("unexpected response: %q", body)
+	}
+
+	if _, err := c.Forward(ctx, "abc", 70000, types.ForwardOptions{}); err == nil {
+		t.Fatal("expect an error of a bad port")
+	}
+	if _, err := c.Tunnel(ctx, "nope", port); err == nil {
+		t.Fatal("expect an error of an unknown container")
+	}
+}
+
+func TestForwardURL(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "debug %s", r.URL.Path)
+	}))
+	defer backend.Close()
+	port, _ := strconv.Atoi(backend.URL[strings.LastIndex(backend.URL, ":")+1:])
+
+	c, closeServer := newTestServerWith(t, config.ServerConfig{
+		ForwardTTL: time.Minute,
+		ForwardURL: "https://forward.example.com",
+	})
+	defer closeServer()
+	ctx := context.Background()
+
+	result, err := c.Forward(ctx, "abc", port, types.ForwardOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(result.URL, "https://forward.example.com/forward/") {
+		t.Fatalf("unexpected URL: %s", result.URL)
+	}
+	path := strings.TrimPrefix(result.URL, "https://forward.example.com")
+
+	get := func(host, path string) (int, string) {
+		req, _ := http.NewRequest(http.MethodGet, c.httpURL(path, nil), nil)
+		req.Host = host
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+	if code, body := get("forward.example.com", path+"vars"); code != http.StatusOK || body != "debug /vars" {
+		t.Fatalf("unexpected response: %d %q", code, body)
+	}
+	// the forwards are only on the forward origin, the UI and the API are not
+	if code, _ := get(c.base.Host, path+"vars"); code != http.StatusNotFound {
+		t.Fatalf("expect 404 of the forward on the UI origin, got %d", code)
+	}
+	for _, p := range []string{"/", "/api/containers"} {
+		if code, _ := get("forward.example.com", p); code != http.StatusNotFound {
+			t.Fatalf("expect 404 of %s on the forward origin, got %d", p, code)
+		}
+	}
+}
+
+func TestContainerActions(t *testing.T) {
+	c, closeServer := newTestServerWith(t, config.ServerConfig{
+		Control: config.ControlConfig{Enable: true, Pause: true, Kill: true, Edit: true},
+	})
+	defer closeServer()
+	ctx := context.Background()
+
+	if err := c.Pause(ctx, "abc"); err != nil {
+		t.Fatal(err)
+	}
+	err := c.Unpause(ctx, "abc")
+	if apiErr, ok := err.(types.APIError); !ok || apiErr.Message != "container abc is not paused" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.Kill(ctx, "abc", "sighup"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Kill(ctx, "abc", "SIGTERM; rm"); err == nil {
+		t.Fatal("expect an error of a bad signal")
+	}
+	if err := c.Rename(ctx, "abc", "web-2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Rename(ctx, "abc", "/bad name"); err == nil {
+		t.Fatal("expect an error of a bad name")
+	}
+	if err := c.UpdateLabels(ctx, "abc", types.LabelsUpdate{
+		Set:    map[string]string{"team": "web"},
+		Remove: []string{"legacy"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.UpdateLabels(ctx, "abc", types.LabelsUpdate{}); err == nil {
+		t.Fatal("expect an error of an empty update")
+	}
+	if err := c.KillProcess(ctx, "abc", 1, "hup"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.KillProcess(ctx, "abc", -1, ""); err == nil {
+		t.Fatal("expect an error of a bad pid")
+	}
+	// not enabled
+	if err := c.Stop(ctx, "abc"); err == nil {
+		t.Fatal("expect an error of a disabled action")
+	}
+	if _, err := c.Commit(ctx, "abc", types.CommitOptions{Image: "debug/web:1"}); err == nil {
+		t.Fatal("expect an error of a disabled commit")
+	}
+}
+
+func TestCopy(t *testing.T) {
+	c, closeServer := newTestServerWith(t, config.ServerConfig{
+		Control: config.ControlConfig{Enable: true, Copy: true},
+	})
+	defer closeServer()
+	ctx := context.Background()
+
+	result, err := c.Copy(ctx, types.CopyOptions{
+		From: types.CopyPath{ID: "abc", Path: "/usr/bin/strace"},
+		To:   types.CopyPath{ID: "abc", Path: "/tmp"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive := fileArchive(tar.Header{Name: "strace", Mode: 0755, Typeflag: tar.TypeReg}, "/usr/bin/strace")
+	if result.Bytes != int64(len(archive)) {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if _, err := c.Copy(ctx, types.CopyOptions{
+		From: types.CopyPath{ID: "abc", Path: "/usr/bin/strace"},
+		To:   types.CopyPath{ID: "nope", Path: "/tmp"},
+	}); err == nil {
+		t.Fatal("expect an error of an unknown container")
+	}
+	if _, err := c.Copy(ctx, types.CopyOptions{
+		From: types.CopyPath{ID: "abc", Path: "bin"},
+		To:   types.CopyPath{ID: "abc", Path: "/tmp"},
+	}); err == nil {
+		t.Fatal("expect an error of a relative path")
+	}
+}
+
+func TestCopyAuth(t *testing.T) {
+	c, closeServer := newTestServerWith(t, config.ServerConfig{
+		Control:       config.ControlConfig{Enable: true, Copy: true},
+		Credential:    "pass",
+		SessionURLTTL: time.Minute,
+		AdminToken:    "s3cret",
+	}, WithAuthToken("pass"), WithAdminToken("s3cret"))
+	defer closeServer()
+	ctx := context.Background()
+
+	opts := types.CopyOptions{
+		From: types.CopyPath{ID: "abc", Path: "/usr/bin/strace"},
+		To:   types.CopyPath{ID: "abc", Path: "/tmp"},
+	}
+	if _, err := c.Copy(ctx, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	anonymous := *c
+	anonymous.authToken = ""
+	_, err := anonymous.Copy(ctx, opts)
+	if apiErr, ok := err.(types.APIError); !ok || apiErr.Code != 401 {
+		t.Fatalf("expect an API error without the credential, got %v", err)
+	}
+	// the raw IDs are for the admins with the signed URLs
+	user := *c
+	user.adminToken = ""
+	_, err = user.Copy(ctx, opts)
+	if apiErr, ok := err.(types.APIError); !ok || apiErr.Code != 401 {
+		t.Fatalf("expect an API error of a user, got %v", err)
+	}
+}
+
+func TestTransferLimits(t *testing.T) {
+	c, closeServer := newTestServerWith(t, config.ServerConfig{
+		Control:           config.ControlConfig{Enable: true, Copy: true, Browse: true},
+		MaxTransferSize:   50,
+		TransferDenyPaths: []string{"/proc", "/var/lib/data/db"},
+		TransferDenyMIME:  []string{"text/html"},
+	})
+	defer closeServer()
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		from string
+		code int
+	}{
+		{"/proc/self/environ", http.StatusForbidden},
+		{"/tmp/device", http.StatusForbidden},
+		{"/tmp/traversal", http.StatusBadRequest},
+		{"/tmp/html", http.StatusForbidden},
+		{"/tmp/large", http.StatusRequestEntityTooLarge},
+		{"/tmp/contiguous", http.StatusRequestEntityTooLarge},
+	} {
+		_, err := c.Copy(ctx, types.CopyOptions{
+			From: types.CopyPath{ID: "abc", Path: tc.from},
+			To:   types.CopyPath{ID: "abc", Path: "/tmp"},
+		})
+		if apiErr, ok := err.(types.APIError); !ok || apiErr.Code != tc.code {
+			t.Errorf("expect %d of the copy of %s, got %v", tc.code, tc.from, err)
+		}
+	}
+
+	rc, err := c.DownloadVolume(ctx, "abc", "/var/lib/data", "/README")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc.Close()
+	for _, p := range []string{"/db", "/db/a.db"} {
+		_, err := c.DownloadVolume(ctx, "abc", "/var/lib/data", p)
+		if apiErr, ok := err.(types.APIError); !ok || apiErr.Code != http.StatusForbidden {
+			t.Errorf("expect 403 of the download of %s, got %v", p, err)
+		}
+	}
+}
+
+func TestExportLogs(t *testing.T) {
+	c, closeServer := newTestServer(t)
+	defer closeServer()
+	ctx := context.Background()
+
+	until := time.Date(2019, 4, 1, 10, 0, 1, 0, time.UTC)
+	for _, compress := range []bool{false, true} {
+		rc, err := c.ExportLogs(ctx, "abc", types.LogOptions{Until: until}, compress)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := io.Reader(rc)
+		if compress {
+			if r, err = gzip.NewReader(rc); err != nil {
+				t.Fatal(err)
+			}
+		}
+		logs, err := ioutil.ReadAll(r)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(logs) != "line 0\nline 1\n" {
+			t.Fatalf("unexpected logs: %q", logs)
+		}
+	}
+
+	if _, err := c.Logs(ctx, "abc", types.LogOptions{Since: until, Until: until.Add(-time.Hour)}); err == nil {
+		t.Fatal("expect an error of until before since")
+	}
+}
+
+func TestCommit(t *testing.T) {
+	c, closeServer := newTestServerWith(t, config.ServerConfig{
+		Control: config.ControlConfig{Enable: true, Commit: true},
+	})
+	defer closeServer()
+	ctx := context.Background()
+
+	result, err := c.Commit(ctx, "abc", types.CommitOptions{Image: "debug/web:1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ID != "sha256:debug/web:1" || result.Image != "debug/web:1" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if _, err := c.Commit(ctx, "abc", types.CommitOptions{Image: "bad image"}); err == nil {
+		t.Fatal("expect an error of a bad image")
+	}
+}
+
+func TestDebug(t *testing.T) {
+	c, closeServer := newTestServerWith(t, config.ServerConfig{
+		Control:    config.ControlConfig{Enable: true, Debug: true},
+		DebugImage: "busybox",
+	})
+	defer closeServer()
+	ctx := context.Background()
+
+	result, err := c.Debug(ctx, "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ID != "debug-abc-busybox" || result.URL != "/exec/debug-abc-busybox/?attach=1&stdin=1" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if _, err := c.Debug(ctx, "xyz"); err == nil {
+		t.Fatal("expect an error of a not found container")
+	}
+}
+
+func TestPrune(t *testing.T) {
+	conf := config.ServerConfig{AdminToken: "s3cret"}
+	c, closeServer := newTestServerWith(t, conf)
+	defer closeServer()
+	ctx := context.Background()
+
+	_, err := c.Prune(ctx, "images", true)
+	if apiErr, ok := err.(types.APIError); !ok || apiErr.Code != http.StatusUnauthorized {
+		t.Fatalf("expect an unauthorized error, got %v", err)
+	}
+
+	c, closeServer = newTestServerWith(t, conf, WithAdminToken("s3cret"))
+	defer closeServer()
+	for _, dryRun := range []bool{true, false} {
+		report, err := c.Prune(ctx, "images", dryRun)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.DryRun != dryRun || len(report.Items) != 1 || report.Reclaimed != 42 {
+			t.Fatalf("unexpected report: %+v", report)
+		}
+	}
+	if _, err := c.Prune(ctx, "networks", true); err == nil {
+		t.Fatal("expect an error of a bad kind")
+	}
+}
+
+func TestLoginChallenge(t *testing.T) {
+	if _, err := route.New(fakeCli{}, event.NewHub(), config.ServerConfig{
+		AdminToken:          "s3cret",
+		LoginChallengeAfter: 2,
+	}); err == nil {
+		t.Fatal("expect an error of the challenge without bits")
+	}
+
+	conf := config.ServerConfig{
+		AdminToken:          "s3cret",
+		LoginChallengeAfter: 2,
+		LoginChallengeBits:  8,
+	}
+	c, closeServer := newTestServerWith(t, conf, WithAdminToken("guess"))
+	defer closeServer()
+	ctx := context.Background()
+
+	prune := func(token, solution string) *http.Response {
+		req, _ := http.NewRequest(http.MethodGet, c.httpURL("/api/admin/prune/images", nil), nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		if solution != "" {
+			req.Header.Set("X-Login-Solution", solution)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+	if resp := prune("guess", ""); resp.StatusCode != http.StatusUnauthorized ||
+		resp.Header.Get("X-Login-Challenge") != "" {
+		t.Fatalf("unexpected response: %s %v", resp.Status, resp.Header)
+	}
+	resp := prune("guess", "")
+	challenge := resp.Header.Get("X-Login-Challenge")
+	if resp.StatusCode != http.StatusUnauthorized || !strings.HasPrefix(challenge, "8:") {
+		t.Fatalf("expect a challenge after the failures, got %s %v", resp.Status, resp.Header)
+	}
+	// even the right token is refused without the solution
+	if resp := prune("s3cret", ""); resp.StatusCode != http.StatusUnauthorized ||
+		resp.Header.Get("X-Login-Challenge") != challenge {
+		t.Fatalf("unexpected response: %s %v", resp.Status, resp.Header)
+	}
+
+	// the client solves it, and keeps failing by the wrong token
+	if _, err := c.Prune(ctx, "images", true); err == nil || err.(types.APIError).Message != "admin token required" {
+		t.Fatalf("expect an unauthorized error, got %v", err)
+	}
+	c, closeServer = newTestServerWith(t, conf, WithAdminToken("s3cret"))
+	defer closeServer()
+	for i := 0; i < 2; i++ {
+		prune("guess", "")
+	}
+	if _, err := c.Prune(ctx, "images", true); err != nil {
+		t.Fatal(err)
+	}
+	// forgotten after the success
+	if resp := prune("s3cret", ""); resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected response: %s", resp.Status)
+	}
+}
+
+func TestLoginChallengeRawID(t *testing.T) {
+	c, closeServer := newTestServerWith(t, config.ServerConfig{
+		AdminToken:          "s3cret",
+		SessionURLTTL:       time.Minute,
+		LoginChallengeAfter: 2,
+		LoginChallengeBits:  8,
+	})
+	defer closeServer()
+
+	// the admins may use the raw IDs, the guesses are counted
+	top := func(token string) *http.Response {
+		req, _ := http.NewRequest(http.MethodGet, c.httpURL("/api/containers/abc/top", nil), nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+	if resp := top("s3cret"); resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected response of the admin: %s", resp.Status)
+	}
+	for i := 0; i < 2; i++ {
+		if resp := top("guess"); resp.StatusCode != http.StatusForbidden {
+			t.Fatalf("unexpected response of a guess: %s", resp.Status)
+		}
+	}
+	resp := top("s3cret")
+	if resp.StatusCode != http.StatusForbidden || !strings.HasPrefix(resp.Header.Get("X-Login-Challenge"), "8:") {
+		t.Fatalf("expect a challenge after the failures, got %s %v", resp.Status, resp.Header)
+	}
+}
+
+func TestSessionCookie(t *testing.T) {
+	if _, err := route.New(fakeCli{}, event.NewHub(), config.ServerConfig{
+		Cookie: config.CookieConfig{SameSite: "none", Secure: "never"},
+	}); err == nil {
+		t.Fatal("expect an error of an insecure SameSite=None cookie")
+	}
+
+	server := func(keys string) (*Client, func()) {
+		return newTestServerWith(t, config.ServerConfig{
+			AdminToken: "s3cret",
+			Cookie:     config.CookieConfig{Name: "sess", HTTPOnly: true, MaxAge: time.Hour, Keys: keys},
+		})
+	}
+	request := func(c *Client, method, path, token string, cookie *http.Cookie) *http.Response {
+		req, _ := http.NewRequest(method, c.httpURL(path, nil), nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		if cookie != nil {
+			req.AddCookie(cookie)
+			req.AddCookie(&http.Cookie{Name: "csrf_token", Value: "t"})
+			req.Header.Set("X-CSRF-Token", "t")
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+	sessionCookie := func(resp *http.Response) *http.Cookie {
+		for _, cookie := range resp.Cookies() {
+			if cookie.Name == "sess" {
+				return cookie
+			}
+		}
+		return nil
+	}
+
+	c, closeServer := server("old-secret-key-1")
+	defer closeServer()
+	if resp := request(c, http.MethodPost, "/api/admin/session", "guess", nil); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("unexpected response: %s", resp.Status)
+	}
+	resp := request(c, http.MethodPost, "/api/admin/session", "s3cret", nil)
+	old := sessionCookie(resp)
+	if resp.StatusCode != http.StatusNoContent || old == nil || !old.HttpOnly ||
+		old.SameSite != http.SameSiteStrictMode || old.MaxAge != 3600 || old.Path != "/" {
+		t.Fatalf("unexpected response: %s %v", resp.Status, resp.Header)
+	}
+	resp = request(c, http.MethodGet, "/api/admin/prune/images", "", old)
+	if resp.StatusCode != http.StatusOK || sessionCookie(resp) != nil {
+		t.Fatalf("unexpected response: %s %v", resp.Status, resp.Header)
+	}
+	if resp := request(c, http.MethodGet, "/api/admin/prune/images", "",
+		&http.Cookie{Name: "sess", Value: old.Value[1:]}); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expect a tampered cookie refused, got %s", resp.Status)
+	}
+
+	// the old cookies are sealed again by the new key
+	c, closeServer = server("new-secret-key-1,old-secret-key-1")
+	defer closeServer()
+	resp = request(c, http.MethodGet, "/api/admin/prune/images", "", old)
+	renewed := sessionCookie(resp)
+	if resp.StatusCode != http.StatusOK || renewed == nil || renewed.Value == old.Value {
+		t.Fatalf("unexpected response: %s %v", resp.Status, resp.Header)
+	}
+	c, closeServer = server("new-secret-key-1")
+	defer closeServer()
+	if resp := request(c, http.MethodGet, "/api/admin/prune/images", "", old); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expect the cookie of the removed key refused, got %s", resp.Status)
+	}
+	if resp := request(c, http.MethodGet, "/api/admin/prune/images", "", renewed); resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected response: %s", resp.Status)
+	}
+
+	resp = request(c, http.MethodDelete, "/api/admin/session", "", renewed)
+	if cookie := sessionCookie(resp); resp.StatusCode != http.StatusNoContent || cookie == nil || cookie.MaxAge != -1 {
+		t.Fatalf("unexpected response: %s %v", resp.Status, resp.Header)
+	}
+}
+
+func TestCreate(t *testing.T) {
+	if _, err := route.New(fakeCli{}, event.NewHub(), config.ServerConfig{
+		Control: config.ControlConfig{Create: true},
+	}); err == nil {
+		t.Fatal("expect an error without an admin token")
+	}
+
+	c, closeServer := newTestServerWith(t, config.ServerConfig{
+		AdminToken: "s3cret",
+		Control:    config.ControlConfig{Create: true},
+	}, WithAdminToken("s3cret"))
+	defer closeServer()
+	ctx := context.Background()
+
+	result, err := c.Create(ctx, types.CreateOptions{Image: "ubuntu:18.04", Name: "web", Env: []string{"A=1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ID != "new-web" || result.URL != "/exec/new-web/?attach=1&stdin=1" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if _, err := c.Create(ctx, types.CreateOptions{Image: "ubuntu", Env: []string{"=1"}}); err == nil {
+		t.Fatal("expect an error of a bad env")
+	}
+}
+
+func TestHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	c, closeServer := newTestServerWith(t, config.ServerConfig{
+		RunTimeout:  time.Second,
+		EnableAudit: true,
+		AuditLogDir: dir,
+	})
+	defer closeServer()
+	ctx := context.Background()
+
+	for _, cmd := range []string{"hostname", "uptime"} {
+		if _, err := c.Run(ctx, "abc", types.RunOptions{Cmd: cmd}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	history, err := c.History(ctx, "abc", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || history[0].Cmd != "uptime" || history[0].ExitCode == nil ||
+		*history[0].ExitCode != 1 || history[0].EndAt == nil {
+		t.Fatalf("unexpected history: %+v", history)
+	}
+
+	c, closeServer = newTestServer(t)
+	defer closeServer()
+	if _, err := c.History(ctx, "abc", 0); err == nil {
+		t.Fatal("expect an error without the audit")
+	}
+}
+
+func TestBatchRun(t *testing.T) {
+	c, closeServer := newTestServer(t)
+	defer closeServer()
+	ctx := context.Background()
+
+	results, err := c.BatchRun(ctx, types.BatchRunOptions{
+		RunOptions: types.RunOptions{Cmd: "hostname"},
+		Selector:   "app=web,!legacy",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].ID != "abc" ||
+		results[0].Result == nil || results[0].Result.Stdout != "hostname" {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+
+	results, err = c.BatchRun(ctx, types.BatchRunOptions{
+		RunOptions: types.RunOptions{Cmd: "hostname"},
+		Selector:   "app!=web",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 0 {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+
+	if _, err := c.BatchRun(ctx, types.BatchRunOptions{
+		RunOptions: types.RunOptions{Cmd: "hostname"},
+	}); err == nil {
+		t.Fatal("expect an error of empty selector")
+	}
+}
+
+func TestBatchRunAuth(t *testing.T) {
+	c, closeServer := newTestServerWith(t, config.ServerConfig{
+		Credential:    "pass",
+		SessionURLTTL: time.Minute,
+		AdminToken:    "s3cret",
+	}, WithAuthToken("pass"), WithAdminToken("s3cret"))
+	defer closeServer()
+	ctx := context.Background()
+
+	opts := types.BatchRunOptions{
+		RunOptions: types.RunOptions{Cmd: "hostname"},
+		Selector:   "app=web",
+	}
+	if results, err := c.BatchRun(ctx, opts); err != nil || len(results) != 1 {
+		t.Fatalf("unexpected results of the admin: %+v, %v", results, err)
+	}
+
+	anonymous := *c
+	anonymous.authToken = ""
+	_, err := anonymous.BatchRun(ctx, opts)
+	if apiErr, ok := err.(types.APIError); !ok || apiErr.Code != 401 {
+		t.Fatalf("expect an API error without the credential, got %v", err)
+	}
+	// the selectors are for the admins with the signed URLs
+	user := *c
+	user.adminToken = ""
+	_, err = user.BatchRun(ctx, opts)
+	if apiErr, ok := err.(types.APIError); !ok || apiErr.Code != 401 {
+		t.Fatalf("expect an API error of a user, got %v", err)
+	}
+}
+
+func TestAttach(t *testing.T) {
+	c, closeServer := newTestServer(t)
+	defer closeServer()
+	ctx := context.Background()
+
+	s, err := c.Attach(ctx, "abc", types.ExecOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if err := s.Resize(80, 24); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Write([]byte("hello\n")); err != nil {
+		t.Fatal(err)
+	}
+	line, err := bufio.NewReader(s).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line != "hello\n" {
+		t.Fatalf("unexpected output: %q", line)
+	}
+
+	s.Write([]byte("exit\n"))
+	code, err := s.Wait()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code != 3 {
+		t.Fatalf("unexpected exit code: %d", code)
+	}
+
+	// the session is recorded when the handler returns
+	var sessions []types.Session
+	for i := 0; i < 20; i++ {
+		sessions, err = c.Sessions(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sessions) == 1 && sessions[0].EndAt != nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(sessions) != 1 || sessions[0].ExitCode == nil || *sessions[0].ExitCode != 3 {
+		t.Fatalf("unexpected sessions: %+v", sessions)
+	}
+}
+
+func TestLargeInput(t *testing.T) {
+	c, closeServer := newTestServerWith(t, config.ServerConfig{MaxInputFrame: 1024})
+	defer closeServer()
+	ctx := context.Background()
+
+	s, err := c.Attach(ctx, "abc", types.ExecOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	r := bufio.NewReader(s)
+	s.Write([]byte("hi\n"))
+	if _, err := r.ReadString('\n'); err != nil {
+		t.Fatal(err)
+	}
+	s.wMux.Lock()
+	maxInput := s.maxInput
+	s.wMux.Unlock()
+	if maxInput != 1024 {
+		t.Fatalf("unexpected max input: %d", maxInput)
+	}
+
+	// split into the messages of the max input, not in a rune
+	paste := strings.Repeat("é", 50000) + "\n"
+	if _, err := s.Write([]byte(paste)); err != nil {
+		t.Fatal(err)
+	}
+	if line, err := r.ReadString('\n'); err != nil || line != paste {
+		t.Fatalf("unexpected echo of %d bytes: %v", len(line), err)
+	}
+
+	// a message longer than the max input of an old browser is read on
+	dialer := websocket.Dialer{Subprotocols: webtty.Protocols}
+	conn, _, err := dialer.DialContext(ctx, c.wsURL("/exec/abc/ws", nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	init, _ := c.initMessage(types.ExecOptions{})
+	conn.WriteMessage(websocket.TextMessage, init)
+	conn.WriteMessage(websocket.TextMessage, []byte("1"+paste))
+	var echoed []byte
+	for len(echoed) < len(paste) {
+		output, _ := base64.StdEncoding.DecodeString(readMessage(t, conn, webtty.Output))
+		echoed = append(echoed, output...)
+	}
+	if string(echoed) != paste {
+		t.Fatalf("unexpected echo of %d bytes", len(echoed))
+	}
+}
+
+func TestAttachMainProcess(t *testing.T) {
+	c, closeServer := newTestServer(t)
+	defer closeServer()
+	ctx := context.Background()
+
+	for _, stdin := range []bool{false, true} {
+		s, err := c.Attach(ctx, "abc", types.ExecOptions{Attach: true, AttachStdin: stdin})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the input is handled before the resize
+		if _, err := s.Write([]byte("hello\n")); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Resize(80, 24); err != nil {
+			t.Fatal(err)
+		}
+		line, err := bufio.NewReader(s).ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expect := map[bool]string{false: "resized\n", true: "hello\n"}[stdin]; line != expect {
+			t.Fatalf("stdin %v: unexpected output %q, expect %q", stdin, line, expect)
+		}
+		s.Close()
+	}
+}
+
+func TestProvision(t *testing.T) {
+	conf := config.ServerConfig{
+		AdminToken:   "s3cret",
+		ExternalURL:  "https://tty.example.com/",
+		ProvisionTTL: time.Minute,
+	}
+	c, closeServer := newTestServerWith(t, conf, WithAdminToken("s3cret"))
+	defer closeServer()
+	ctx := context.Background()
+
+	// an admin API
+	anonymous, err := New(strings.TrimSuffix(c.httpURL("", nil), "/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = anonymous.Provision(ctx, "abc", types.ProvisionOptions{})
+	if apiErr, ok := err.(types.APIError); !ok || apiErr.Code != http.StatusUnauthorized {
+		t.Fatalf("expect 401 without the admin token, got %v", err)
+	}
+	for _, ttl := range []string{"0s", "-1m"} {
+		_, err = c.Provision(ctx, "abc", types.ProvisionOptions{TTL: ttl})
+		if apiErr, ok := err.(types.APIError); !ok || apiErr.Code != http.StatusBadRequest {
+			t.Fatalf("expect 400 of the ttl %s, got %v", ttl, err)
+		}
+	}
+
+	p, err := c.Provision(ctx, "abc", types.ProvisionOptions{TTL: "10s"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(p.JoinURL, "https://tty.example.com/join/") || p.SessionID == "" {
+		t.Fatalf("unexpected result: %+v", p)
+	}
+
+	s, err := c.Join(ctx, p.JoinURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if _, err := s.Write([]byte("hello\n")); err != nil {
+		t.Fatal(err)
+	}
+	line, err := bufio.NewReader(s).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line != "hello\n" {
+		t.Fatalf("unexpected output: %q", line)
+	}
+
+	// a session can only be joined once
+	if _, err := c.Join(ctx, p.JoinURL); err == nil {
+		t.Fatal("expect an error of joining twice")
+	}
+
+	s.Write([]byte("exit\n"))
+	if code, err := s.Wait(); err != nil || code != 3 {
+		t.Fatalf("unexpected exit: %d %v", code, err)
+	}
+}
+
+func TestKeepalive(t *testing.T) {
+	c, closeServer := newTestServerWith(t, config.ServerConfig{
+		WSPingInterval: 20 * time.Millisecond,
+		WSPingTimeout:  100 * time.Millisecond,
+	})
+	defer closeServer()
+	ctx := context.Background()
+
+	// a session that answers the pings is kept alive
+	s, err := c.Attach(ctx, "abc", types.ExecOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	// a session that never reads can't answer the pings
+	init, _ := c.initMessage(types.ExecOptions{})
+	dialer := websocket.Dialer{Subprotocols: webtty.Protocols}
+	conn, _, err := dialer.DialContext(ctx, c.wsURL("/exec/abc/ws", nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.WriteMessage(websocket.TextMessage, init)
+
+	time.Sleep(300 * time.Millisecond)
+	sessions, err := c.Sessions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var active, closed int
+	for _, sess := range sessions {
+		if sess.EndAt == nil {
+			active++
+		} else {
+			closed++
+		}
+	}
+	if active != 1 || closed != 1 {
+		t.Fatalf("unexpected sessions: %+v", sessions)
+	}
+}
+
+func TestCompression(t *testing.T) {
+	dialer := &websocket.Dialer{EnableCompression: true}
+	c, closeServer := newTestServerWith(t, config.ServerConfig{
+		WSCompression:      true,
+		WSCompressionLevel: 1,
+	}, WithDialer(dialer))
+	defer closeServer()
+	ctx := context.Background()
+
+	conn, resp, err := dialer.DialContext(ctx, c.wsURL("/exec/abc/ws", nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+	if ext := resp.Header.Get("Sec-Websocket-Extensions"); !strings.Contains(ext, "permessage-deflate") {
+		t.Fatalf("compression is not negotiated: %q", ext)
+	}
+
+	s, err := c.Attach(ctx, "abc", types.ExecOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	msg := strings.Repeat("compressed ", 50) + "\n"
+	if _, err := s.Write([]byte(msg)); err != nil {
+		t.Fatal(err)
+	}
+	line, err := bufio.NewReader(s).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line != msg {
+		t.Fatalf("unexpected output: %q", line)
+	}
+}
+
+// readMessage reads the websocket until a message of the type
+func readMessage(t *testing.T, conn *websocket.Conn, typ byte) string {
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(msg) != 0 && msg[0] == typ {
+			return string(msg[1:])
+		}
+	}
+}
+
+// readEvent reads a server-sent event, its name and its data
+func readEvent(t *testing.T, r *bufio.Reader) (string, string) {
+	var event string
+	var data []string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "" && data != nil:
+			return event, strings.Join(data, "\n")
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = append(data, strings.TrimPrefix(line, "data: "))
+		}
+	}
+}
+
+func TestSSE(t *testing.T) {
+	c, closeServer := newTestServer(t)
+	defer closeServer()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, _ := http.NewRequest(http.MethodGet, c.httpURL("/exec/abc/sse", nil), nil)
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("unexpected content type %s", ct)
+	}
+	events := bufio.NewReader(resp.Body)
+	event, sid := readEvent(t, events)
+	if event != "session" || sid == "" {
+		t.Fatalf("unexpected first event %s: %s", event, sid)
+	}
+
+	post := func(path string, body string) int {
+		resp, err := http.Post(c.httpURL(path, nil), "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	init, _ := c.initMessage(types.ExecOptions{})
+	// longer than a read of the relay, read as more input messages
+	input := strings.Repeat("x", 3000)
+	msgs, _ := json.Marshal([]string{string(init), "1" + input})
+	if code := post("/exec/abc/sse/"+sid, string(msgs)); code != http.StatusNoContent {
+		t.Fatalf("unexpected status of the input %d", code)
+	}
+	var output string
+	for len(output) < len(input) {
+		_, data := readEvent(t, events)
+		if data[0] == webtty.Output {
+			decoded, _ := base64.StdEncoding.DecodeString(data[1:])
+			output += string(decoded)
+		}
+	}
+	if output != input {
+		t.Fatalf("unexpected output of %d bytes", len(output))
+	}
+
+	for _, tc := range []struct {
+		path, body string
+		code       int
+	}{
+		{"/exec/abc/sse/unknown", `["1ls"]`, http.StatusNotFound},
+		// the session of another path
+		{"/logs/abc/sse/" + sid, `["1ls"]`, http.StatusNotFound},
+		{"/exec/abc/sse/" + sid, `"1ls"`, http.StatusBadRequest},
+	} {
+		if code := post(tc.path, tc.body); code != tc.code {
+			t.Fatalf("unexpected status %d of %s %s, expect %d", code, tc.path, tc.body, tc.code)
+		}
+	}
+
+	// the session is gone with the event stream
+	resp.Body.Close()
+	for i := 0; ; i++ {
+		code := post("/exec/abc/sse/"+sid, `["1ls"]`)
+		if code == http.StatusNotFound || code == http.StatusGone {
+			break
+		}
+		if i == 50 {
+			t.Fatalf("unexpected status %d after closing the stream", code)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestWSBinaryMessage(t *testing.T) {
+	c, closeServer := newTestServer(t)
+	defer closeServer()
+
+	dialer := websocket.Dialer{Subprotocols: webtty.Protocols}
+	conn, _, err := dialer.Dial(c.wsURL("/exec/abc/ws", nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	init, _ := c.initMessage(types.ExecOptions{})
+	conn.WriteMessage(websocket.BinaryMessage, init)
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		_, _, err := conn.ReadMessage()
+		if err == nil {
+			continue
+		}
+		if ne, ok := err.(net.Error); ok && ne.Timeout() {
+			t.Fatal("the connection sending a binary message is not closed")
+		}
+		break
+	}
+}
+
+func TestResume(t *testing.T) {
+	c, closeServer := newTestServerWith(t, config.ServerConfig{
+		ResumeTimeout: time.Minute,
+		ReplayBuffer:  1024,
+	})
+	defer closeServer()
+	ctx := context.Background()
+
+	dialer := websocket.Dialer{Subprotocols: webtty.Protocols}
+	conn, _, err := dialer.DialContext(ctx, c.wsURL("/exec/abc/ws", nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	init, _ := c.initMessage(types.ExecOptions{})
+	conn.WriteMessage(websocket.TextMessage, init)
+	token := readMessage(t, conn, webtty.SetResumeToken)
+	conn.WriteMessage(websocket.TextMessage, []byte("1hello\n"))
+	readMessage(t, conn, webtty.Output)
+	conn.Close()
+	time.Sleep(100 * time.Millisecond)
+
+	// the output before the blip is replayed
+	conn, _, err = dialer.DialContext(ctx, c.wsURL("/exec/abc/ws", nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	init, _ = json.Marshal(types.InitMessage{ResumeToken: token})
+	conn.WriteMessage(websocket.TextMessage, init)
+	output, err := base64.StdEncoding.DecodeString(readMessage(t, conn, webtty.Output))
+	if err != nil || string(output) != "hello\n" {
+		t.Fatalf("unexpected replay: %q %v", output, err)
+	}
+
+	sessions, err := c.Sessions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 || sessions[0].EndAt != nil {
+		t.Fatalf("unexpected sessions: %+v", sessions)
+	}
+	// the bytes of the connection before the blip are kept
+	if sessions[0].BytesIn < int64(len("1hello\n")) || sessions[0].BytesOut == 0 {
+		t.Fatalf("unexpected bytes of the session: %+v", sessions[0])
+	}
+}
+
+func TestBannerMOTD(t *testing.T) {
+	c, closeServer := newTestServerWith(t, config.ServerConfig{
+		Banner:        "<b>authorized</b> use only",
+		MOTD:          "This session is recorded",
+		ResumeTimeout: time.Minute,
+		ReplayBuffer:  1024,
+	})
+	defer closeServer()
+	ctx := context.Background()
+
+	for path, want := range map[string]string{
+		"/":          `<p class="banner">&lt;b&gt;authorized&lt;/b&gt; use only</p>`,
+		"/config.js": `var gotty_banner = '\u003Cb\u003Eauthorized\u003C/b\u003E use only';`,
+	} {
+		resp, err := http.Get(c.httpURL(path, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if !strings.Contains(string(body), want) {
+			t.Fatalf("no banner in %s: %s", path, body)
+		}
+	}
+
+	dialer := websocket.Dialer{Subprotocols: webtty.Protocols}
+	conn, _, err := dialer.DialContext(ctx, c.wsURL("/exec/abc/ws", nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	init, _ := c.initMessage(types.ExecOptions{})
+	conn.WriteMessage(websocket.TextMessage, init)
+	token := readMessage(t, conn, webtty.SetResumeToken)
+	output, _ := base64.StdEncoding.DecodeString(readMessage(t, conn, webtty.Output))
+	if string(output) != "This session is recorded\r\n" {
+		t.Fatalf("unexpected motd: %q", output)
+	}
+	conn.WriteMessage(websocket.TextMessage, []byte("1hello\n"))
+	readMessage(t, conn, webtty.Output)
+	conn.Close()
+	time.Sleep(100 * time.Millisecond)
+
+	// not again after resuming, nor in the replay
+	conn, _, err = dialer.DialContext(ctx, c.wsURL("/exec/abc/ws", nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	init, _ = json.Marshal(types.InitMessage{ResumeToken: token})
+	conn.WriteMessage(websocket.TextMessage, init)
+	output, _ = base64.StdEncoding.DecodeString(readMessage(t, conn, webtty.Output))
+	if string(output) != "hello\n" {
+		t.Fatalf("unexpected output after resuming: %q", output)
+	}
+}
+
+func TestTitleFormat(t *testing.T) {
+	c, closeServer := newTestServerWith(t, config.ServerConfig{
+		TitleFormat:     "{{ .user }}@{{ .containerName }} {{ .backend }}",
+		TitleFormatKube: "{{ .namespace }}/{{ .podName }}",
+	})
+	defer closeServer()
+	ctx := context.Background()
+
+	dialer := websocket.Dialer{Subprotocols: webtty.Protocols}
+	conn, _, err := dialer.DialContext(ctx, c.wsURL("/exec/abc/ws", nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	init, _ := c.initMessage(types.ExecOptions{User: "app"})
+	conn.WriteMessage(websocket.TextMessage, init)
+	if title := readMessage(t, conn, webtty.SetWindowTitle); title != "app@fake docker" {
+		t.Fatalf("unexpected title: %q", title)
+	}
+
+	resp, err := http.Get(c.httpURL("/exec/abc", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), "<title>@fake docker</title>") {
+		t.Fatalf("unexpected title of the page: %s", body)
+	}
+
+	for _, conf := range []config.ServerConfig{
+		{TitleFormat: "{{ .containerName "},
+		{TitleFormatDocker: "{{ end }}"},
+	} {
+		if _, err := route.New(fakeCli{}, event.NewHub(), conf); err == nil {
+			t.Fatalf("expect an error of the bad title format %+v", conf)
+		}
+	}
+}
+
+func TestClipboard(t *testing.T) {
+	c, closeServer := newTestServerWith(t, config.ServerConfig{
+		Clipboard:      "ask",
+		ClipboardUsers: map[string]string{"root": "off"},
+	})
+	defer closeServer()
+	ctx := context.Background()
+
+	dialer := websocket.Dialer{Subprotocols: webtty.Protocols}
+	for _, user := range []string{"app", "root"} {
+		conn, _, err := dialer.DialContext(ctx, c.wsURL("/exec/abc/ws", nil), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		init, _ := c.initMessage(types.ExecOptions{User: user})
+		conn.WriteMessage(websocket.TextMessage, init)
+		// echoed by the fake container
+		conn.WriteMessage(websocket.TextMessage, []byte("1\x1b]52;c;aGk=\a"))
+		conn.WriteMessage(websocket.TextMessage, []byte("1done"))
+
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		var clipboard string
+		for output := ""; !strings.Contains(output, "done"); {
+			_, msg, err := conn.ReadMessage()
+			if err != nil {
+				t.Fatal(err)
+			}
+			switch msg[0] {
+			case webtty.SetClipboard:
+				clipboard = string(msg[1:])
+			case webtty.Output:
+				decoded, _ := base64.StdEncoding.DecodeString(string(msg[1:]))
+				if output += string(decoded); strings.Contains(output, "]52;") {
+					t.Fatalf("the sequence is in the output: %q", output)
+				}
+			}
+		}
+		conn.Close()
+
+		expect := `{"ask":true,"data":"aGk="}`
+		if user == "root" {
+			expect = ""
+		}
+		if clipboard != expect {
+			t.Fatalf("unexpected clipboard of %s: %q", user, clipboard)
+		}
+	}
+
+	for _, conf := range []config.ServerConfig{
+		{Clipboard: "always"},
+		{ClipboardUsers: map[string]string{"root": "yes"}},
+	} {
+		if _, err := route.New(fakeCli{}, event.NewHub(), conf); err == nil {
+			t.Fatalf("expect an error of the bad clipboard policy %+v", conf)
+		}
+	}
+}
+
+func TestTimezone(t *testing.T) {
+	if _, err := route.New(fakeCli{}, event.NewHub(), config.ServerConfig{
+		Timezone: "Mars/Olympus",
+	}); err == nil {
+		t.Fatal("expect an error of the unknown timezone")
+	}
+
+	dir, err := ioutil.TempDir("", "cwt-timezone")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	c, closeServer := newTestServerWith(t, config.ServerConfig{
+		RunTimeout:  time.Second,
+		EnableAudit: true,
+		AuditLogDir: dir,
+		Timezone:    "Asia/Tokyo",
+		Clock:       true,
+	})
+	defer closeServer()
+	ctx := context.Background()
+
+	for path, want := range map[string]string{
+		"/exec/abc":  `<span id="clock"></span>`,
+		"/config.js": `var gotty_timezone = 'Asia/Tokyo';`,
+	} {
+		resp, err := http.Get(c.httpURL(path, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if !strings.Contains(string(body), want) {
+			t.Fatalf("no %s in %s: %s", want, path, body)
+		}
+	}
+
+	if _, err := c.Run(ctx, "abc", types.RunOptions{Cmd: "hostname"}); err != nil {
+		t.Fatal(err)
+	}
+	history, err := c.History(ctx, "abc", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 {
+		t.Fatalf("unexpected history: %+v", history)
+	}
+	if _, offset := history[0].StartAt.Zone(); offset != 9*3600 {
+		t.Fatalf("the history isn't in the timezone: %s", history[0].StartAt)
+	}
+}
+
+func TestStorage(t *testing.T) {
+	for _, conf := range []config.ServerConfig{
+		{ReplicaURL: "http://10.0.0.1:8080", ResumeTimeout: time.Minute},
+		{Storage: "memory://", RedisAddr: "localhost:6379"},
+		{Storage: "mysql://localhost"},
+	} {
+		if _, err := route.New(fakeCli{}, event.NewHub(), conf); err == nil {
+			t.Fatalf("expect an error of the storage %+v", conf)
+		}
+	}
+
+	dir, err := ioutil.TempDir("", "cwt-storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	c, closeServer := newTestServerWith(t, config.ServerConfig{
+		RunTimeout:  time.Second,
+		EnableAudit: true,
+		AuditLogDir: dir,
+		Storage:     "bolt://" + filepath.Join(dir, "state.db"),
+	})
+	defer closeServer()
+	ctx := context.Background()
+
+	if _, err := c.Run(ctx, "abc", types.RunOptions{Cmd: "hostname"}); err != nil {
+		t.Fatal(err)
+	}
+	history, err := c.History(ctx, "abc", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || history[0].Cmd != "hostname" {
+		t.Fatalf("unexpected history: %+v", history)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "abc", "history.jsonl")); !os.IsNotExist(err) {
+		t.Fatalf("the history is in the audit dir: %v", err)
+	}
+
+	s, err := c.Attach(ctx, "abc", types.ExecOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Write([]byte("exit\n"))
+	if _, err := s.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	var sessions []types.Session
+	for i := 0; i < 20; i++ {
+		if sessions, err = c.Sessions(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if len(sessions) == 1 && sessions[0].EndAt != nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(sessions) != 1 || sessions[0].EndAt == nil {
+		t.Fatalf("unexpected sessions: %+v", sessions)
+	}
+}
+
+func TestMaintenance(t *testing.T) {
+	c, closeServer := newTestServerWith(t, config.ServerConfig{
+		RunTimeout: time.Second,
+		AdminToken: "secret",
+	}, WithAdminToken("secret"))
+	defer closeServer()
+	ctx := context.Background()
+
+	// the running session is left as it is
+	s, err := c.Attach(ctx, "abc", types.ExecOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	m, err := c.SetMaintenance(ctx, true, "backend upgrade until 10:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.Enabled || m.Since == nil || m.Message != "backend upgrade until 10:00" {
+		t.Fatalf("unexpected maintenance: %+v", m)
+	}
+
+	_, err = c.Run(ctx, "abc", types.RunOptions{Cmd: "hostname"})
+	if apiErr, ok := err.(types.APIError); !ok || apiErr.Code != http.StatusServiceUnavailable ||
+		apiErr.Message != m.Message {
+		t.Fatalf("expect the maintenance error of a command, got %v", err)
+	}
+	dialer := websocket.Dialer{Subprotocols: webtty.Protocols}
+	conn, _, err := dialer.DialContext(ctx, c.wsURL("/exec/abc/ws", nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	init, _ := c.initMessage(types.ExecOptions{})
+	conn.WriteMessage(websocket.TextMessage, init)
+	if notice := readMessage(t, conn, webtty.Notice); !strings.Contains(notice, m.Message) {
+		t.Fatalf("unexpected notice: %s", notice)
+	}
+	conn.Close()
+
+	resp, err := http.Get(c.httpURL("/", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), `<p class="banner maintenance">backend upgrade until 10:00</p>`) {
+		t.Fatalf("no maintenance on the list page: %s", body)
+	}
+
+	if _, err := s.Write([]byte("hello\n")); err != nil {
+		t.Fatal(err)
+	}
+	if line, err := bufio.NewReader(s).ReadString('\n'); err != nil || line != "hello\n" {
+		t.Fatalf("unexpected output of the running session: %q %v", line, err)
+	}
+
+	if m, err = c.SetMaintenance(ctx, false, ""); err != nil || m.Enabled {
+		t.Fatalf("unexpected maintenance: %+v %v", m, err)
+	}
+	if _, err := c.Run(ctx, "abc", types.RunOptions{Cmd: "hostname"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAdminSessions(t *testing.T) {
+	c, closeServer := newTestServerWith(t, config.ServerConfig{
+		ResumeTimeout: time.Minute,
+		ReplayBuffer:  1024,
+		AdminToken:    "secret",
+	}, WithAdminToken("secret"))
+	defer closeServer()
+	ctx := context.Background()
+
+	s, err := c.Attach(ctx, "abc", types.ExecOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if _, err := s.Write([]byte("hello\n")); err != nil {
+		t.Fatal(err)
+	}
+	if line, err := bufio.NewReader(s).ReadString('\n'); err != nil || line != "hello\n" {
+		t.Fatalf("unexpected output: %q %v", line, err)
+	}
+
+	// an admin token created by the API is the admin token until revoked
+	token, err := c.CreateToken(ctx, types.AdminTokenOptions{Name: "ci", TTL: "1h"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(token.Token, "cwt_"+token.ID+"_") || token.ExpiresAt == nil {
+		t.Fatalf("unexpected token: %+v", token)
+	}
+	tc, _ := New(c.base.String(), WithAdminToken(token.Token))
+	sessions, err := tc.AdminSessions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 || sessions[0].EndAt != nil {
+		t.Fatalf("unexpected sessions: %+v", sessions)
+	}
+	id := sessions[0].ID
+
+	replay, err := tc.Replay(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, _ := ioutil.ReadAll(replay)
+	replay.Close()
+	if string(output) != "hello\n" {
+		t.Fatalf("unexpected replay: %q", output)
+	}
+
+	if err := tc.KillSession(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Wait(); err == nil {
+		t.Fatal("expect the killed session closed")
+	}
+	if sessions, err = c.AdminSessions(ctx); err != nil || len(sessions) != 1 ||
+		sessions[0].EndAt == nil || sessions[0].Reason != "killed by the admin" {
+		t.Fatalf("unexpected sessions: %+v %v", sessions, err)
+	}
+	if err := c.KillSession(ctx, id); err == nil {
+		t.Fatal("expect an error of killing a closed session")
+	}
+
+	if err := c.RevokeToken(ctx, token.ID); err != nil {
+		t.Fatal(err)
+	}
+	_, err = tc.AdminSessions(ctx)
+	if apiErr, ok := err.(types.APIError); !ok || apiErr.Code != http.StatusUnauthorized {
+		t.Fatalf("expect 401 of a revoked token, got %v", err)
+	}
+	if err := c.RevokeToken(ctx, token.ID); err == nil {
+		t.Fatal("expect an error of revoking a revoked token")
+	}
+}
+
+func TestReplayEvictions(t *testing.T) {
+	level := log.GetLevel()
+	log.SetLevel(log.DebugLevel) // serves /debug/vars
+	c, closeServer := newTestServerWith(t, config.ServerConfig{
+		ResumeTimeout: time.Minute,
+		ReplayBuffer:  1024,
+	})
+	log.SetLevel(level)
+	defer closeServer()
+	ctx := context.Background()
+
+	dialer := websocket.Dialer{Subprotocols: webtty.Protocols}
+	conn, _, err := dialer.DialContext(ctx, c.wsURL("/exec/abc/ws", nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	init, _ := c.initMessage(types.ExecOptions{})
+	conn.WriteMessage(websocket.TextMessage, init)
+	token := readMessage(t, conn, webtty.SetResumeToken)
+	var written []byte
+	for _, b := range []byte("abc") {
+		line := append(bytes.Repeat([]byte{b}, 699), '\n')
+		written = append(written, line...)
+		conn.WriteMessage(websocket.TextMessage, append([]byte{webtty.Input}, line...))
+		for n := 0; n < len(line); {
+			output, _ := base64.StdEncoding.DecodeString(readMessage(t, conn, webtty.Output))
+			n += len(output)
+		}
+	}
+	conn.Close()
+	time.Sleep(100 * time.Millisecond)
+
+	// the last 1024 bytes are replayed from the wrapped ring
+	conn, _, err = dialer.DialContext(ctx, c.wsURL("/exec/abc/ws", nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	init, _ = json.Marshal(types.InitMessage{ResumeToken: token})
+	conn.WriteMessage(websocket.TextMessage, init)
+	var replayed []byte
+	for len(replayed) < 1024 {
+		output, _ := base64.StdEncoding.DecodeString(readMessage(t, conn, webtty.Output))
+		replayed = append(replayed, output...)
+	}
+	if !bytes.Equal(replayed, written[len(written)-1024:]) {
+		t.Fatalf("unexpected replay: %q", replayed)
+	}
+
+	resp, err := http.Get(c.httpURL("/debug/vars", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var vars struct {
+		Replay map[string]int64 `json:"replay"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&vars); err != nil {
+		t.Fatal(err)
+	}
+	if vars.Replay["buffers"] < 1 || vars.Replay["evicted_bytes"] < 3*700-1024 {
+		t.Fatalf("unexpected metrics: %v", vars.Replay)
+	}
+}
+
+func TestSessionSetupMetrics(t *testing.T) {
+	level := log.GetLevel()
+	log.SetLevel(log.DebugLevel) // serves /debug/vars
+	c, closeServer := newTestServer(t)
+	log.SetLevel(level)
+	defer closeServer()
+	ctx := context.Background()
+
+	metrics := func() map[string]int64 {
+		resp, err := http.Get(c.httpURL("/debug/vars", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var vars struct {
+			Setup map[string]int64 `json:"session_setup"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&vars); err != nil {
+			t.Fatal(err)
+		}
+		return vars.Setup
+	}
+	before := metrics()
+
+	s, err := c.Attach(ctx, "abc", types.ExecOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Write([]byte("hello\n"))
+	if _, err := bufio.NewReader(s).ReadString('\n'); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	// a bad init message fails the exec
+	dialer := websocket.Dialer{Subprotocols: webtty.Protocols}
+	conn, _, err := dialer.DialContext(ctx, c.wsURL("/exec/abc/ws", nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.WriteMessage(websocket.TextMessage, []byte("{bad"))
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for err == nil {
+		_, _, err = conn.ReadMessage()
+	}
+	conn.Close()
+
+	time.Sleep(100 * time.Millisecond)
+	after := metrics()
+	for key, delta := range map[string]int64{
+		"attempts": 2, "established": 1, "le_inf": 1, "failures": 1, "failures_exec": 1,
+	} {
+		if after[key]-before[key] != delta {
+			t.Fatalf("expect %s +%d, got %v -> %v", key, delta, before, after)
+		}
+	}
+}
+
+func TestTimeline(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	events := event.NewHub()
+	srv, err := route.New(fakeCli{}, events, config.ServerConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+	c, err := New(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	events.Publish(types.Event{Action: "start", ID: "abc"})
+	events.Publish(types.Event{Action: "oom", ID: "abc", Detail: "exit code 137"})
+	events.Publish(types.Event{Action: "resize", ID: "abc"})
+	timeline, err := c.Timeline(ctx, "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(timeline) != 2 || timeline[0].Action != "oom" || timeline[0].Detail != "exit code 137" {
+		t.Fatalf("unexpected timeline: %+v", timeline)
+	}
+	if _, err := c.Timeline(ctx, "xyz"); err == nil {
+		t.Fatal("expect an error of unknown container")
+	}
+}
+
+func TestVolumes(t *testing.T) {
+	c, closeServer := newTestServer(t)
+	if _, err := c.Volumes(context.Background(), "abc"); err == nil {
+		t.Fatal("expect an error without --control-browse")
+	}
+	closeServer()
+
+	c, closeServer = newTestServerWith(t, config.ServerConfig{
+		Control: config.ControlConfig{Enable: true, Browse: true},
+	})
+	defer closeServer()
+	ctx := context.Background()
+
+	mounts, err := c.Volumes(ctx, "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mounts) != 1 || mounts[0].Destination != "/var/lib/data" {
+		t.Fatalf("unexpected mounts: %+v", mounts)
+	}
+
+	listing, err := c.VolumeFiles(ctx, "abc", "/var/lib/data", "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listing.Entries) != 3 || listing.Entries[0].Name != "db" || listing.Entries[0].Size != 6 ||
+		listing.Entries[2].Type != "symlink" || listing.Entries[2].Link != "/etc/passwd" {
+		t.Fatalf("unexpected listing: %+v", listing)
+	}
+	// it can't go out of the volume
+	listing, err = c.VolumeFiles(ctx, "abc", "/var/lib/data", "../../db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if listing.Path != "/db" || len(listing.Entries) != 2 {
+		t.Fatalf("unexpected listing: %+v", listing)
+	}
+	if _, err := c.VolumeFiles(ctx, "abc", "/etc", "/"); err == nil {
+		t.Fatal("expect an error of a path which is not a volume")
+	}
+	if _, err := c.VolumeFiles(ctx, "abc", "/var/lib/data", "/README"); err == nil {
+		t.Fatal("expect an error of listing a file")
+	}
+
+	rc, err := c.DownloadVolume(ctx, "abc", "/var/lib/data", "/README")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, _ := ioutil.ReadAll(rc)
+	rc.Close()
+	if string(bs) != "hello" {
+		t.Fatalf("unexpected file: %q", bs)
+	}
+
+	rc, err = c.DownloadVolume(ctx, "abc", "/var/lib/data", "/db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	var names []string
+	tr := tar.NewReader(rc)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, hdr.Name)
+	}
+	if strings.Join(names, ",") != "db/,db/a.db,db/b.db" {
+		t.Fatalf("unexpected archive: %v", names)
+	}
+	if _, err := c.DownloadVolume(ctx, "abc", "/var/lib/data", "/passwd"); err == nil {
+		t.Fatal("expect an error of downloading a symlink")
+	}
+}
+
+func TestDrain(t *testing.T) {
+	c, closeServer := newTestServerWith(t, config.ServerConfig{
+		AdminToken:   "s3cret",
+		DrainTimeout: 300 * time.Millisecond,
+		ProvisionTTL: time.Minute,
+	}, WithAdminToken("s3cret"))
+	defer closeServer()
+	ctx := context.Background()
+
+	s, err := c.Attach(ctx, "abc", types.ExecOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.Write([]byte("hello\n"))
+	if _, err := bufio.NewReader(s).ReadString('\n'); err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := c.Drain(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Draining || status.Deadline == nil || status.Sessions != 1 {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+	if _, err := c.Drain(ctx, 0); err == nil {
+		t.Fatal("expect an error of draining again")
+	}
+
+	// the connected session is notified and closed after the deadline
+	select {
+	case notice := <-s.Notices():
+		if notice.Seconds == 0 || notice.Message == "" {
+			t.Fatalf("unexpected notice: %+v", notice)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no notice of the drain")
+	}
+	select {
+	case <-s.done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("the session is not closed after the deadline")
+	}
+
+	// no new sessions
+	if _, err := c.Provision(ctx, "abc", types.ProvisionOptions{}); err == nil {
+		t.Fatal("expect an error of provisioning while draining")
+	}
+	s, err = c.Attach(ctx, "abc", types.ExecOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Wait(); err == nil {
+		t.Fatal("expect the new session to be rejected")
+	}
+	resp, err := http.Get(c.httpURL("/readyz", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expect a draining server not to be ready, got %s", resp.Status)
+	}
+}
+
+func TestConnectionLimits(t *testing.T) {
+	c, closeServer := newTestServerWith(t, config.ServerConfig{
+		MaxConnectionPerIP: 1,
+		InitTimeout:        100 * time.Millisecond,
+	})
+	defer closeServer()
+	ctx := context.Background()
+
+	s, err := c.Attach(ctx, "abc", types.ExecOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Attach(ctx, "abc", types.ExecOptions{}); err == nil {
+		t.Fatal("expect an error beyond the connections of the IP")
+	}
+	s.Close()
+	s.Wait()
+
+	// the connection without the init message is closed after the timeout
+	dialer := websocket.Dialer{Subprotocols: webtty.Protocols}
+	var conn *websocket.Conn
+	for i := 0; i < 20; i++ {
+		// the closed session may still be counted for a while
+		if conn, _, err = dialer.DialContext(ctx, c.wsURL("/exec/abc/ws", nil), nil); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	if _, _, err := conn.ReadMessage(); err == nil {
+		t.Fatal("expect the connection to be closed")
+	} else if e, ok := err.(net.Error); ok && e.Timeout() {
+		t.Fatal("the connection without the init message is not closed")
+	}
+}
+
+func TestSizeLimits(t *testing.T) {
+	c, closeServer := newTestServerWith(t, config.ServerConfig{
+		MaxBodySize:   100,
+		MaxInputFrame: 1024,
+		MaxWSMessage:  2048,
+	})
+	defer closeServer()
+	ctx := context.Background()
+
+	body := `{"cmd": "` + strings.Repeat("x", 200) + `"}`
+	for _, r := range []io.Reader{
+		strings.NewReader(body),
+		// chunked, without the length
+		ioutil.NopCloser(strings.NewReader(body)),
+	} {
+		resp, err := http.Post(c.httpURL("/api/containers/abc/run", nil), "application/json", r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusRequestEntityTooLarge {
+			t.Errorf("unexpected status of a large body: %d", resp.StatusCode)
+		}
+	}
+	if _, err := c.Run(ctx, "abc", types.RunOptions{Cmd: "ls"}); err != nil {
+		t.Fatal(err)
+	}
+
+	dialer := websocket.Dialer{Subprotocols: webtty.Protocols}
+	conn, _, err := dialer.DialContext(ctx, c.wsURL("/exec/abc/ws", nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	init, _ := c.initMessage(types.ExecOptions{})
+	conn.WriteMessage(websocket.TextMessage, init)
+	conn.WriteMessage(websocket.TextMessage, []byte("1"+strings.Repeat("x", 4096)))
+	for {
+		if _, _, err = conn.ReadMessage(); err != nil {
+			break
+		}
+	}
+	if !websocket.IsCloseError(err, websocket.CloseMessageTooBig) {
+		t.Fatalf("expect 1009 of a large message, got %v", err)
+	}
+
+	srv, err := route.New(fakeCli{}, event.NewHub(), config.ServerConfig{MaxInputFrame: 1024, MaxWSMessage: 1024})
+	if err == nil || srv != nil {
+		t.Fatal("no error of a websocket limit not larger than the input frame")
+	}
+}
+
+func TestH2C(t *testing.T) {
+	c, closeServer := newTestServerWith(t, config.ServerConfig{H2C: true})
+	defer closeServer()
+	ctx := context.Background()
+
+	h2 := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLS: func(network, addr string, cfg *tls.Config) (net.Conn, error) {
+			return net.Dial(network, addr)
+		},
+	}}
+	resp, err := h2.Get(c.httpURL("/api/containers", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.ProtoMajor != 2 {
+		t.Fatalf("unexpected response: %s %s", resp.Proto, resp.Status)
+	}
+
+	// the websockets upgrade from HTTP/1.1 as before
+	s, err := c.Attach(ctx, "abc", types.ExecOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.Write([]byte("hello\n"))
+	if line, err := bufio.NewReader(s).ReadString('\n'); err != nil || line != "hello\n" {
+		t.Fatalf("unexpected output: %q %v", line, err)
+	}
+}
+
+func TestCSRF(t *testing.T) {
+	c, closeServer := newTestServer(t)
+	defer closeServer()
+
+	jar, _ := cookiejar.New(nil)
+	browser := &http.Client{Jar: jar}
+	resp, err := browser.Get(c.httpURL("/", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	var token string
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == "csrf_token" {
+			token = cookie.Value
+		}
+	}
+	if token == "" {
+		t.Fatal("no CSRF token of the page")
+	}
+
+	run := func(client *http.Client, header http.Header) int {
+		req, _ := http.NewRequest(http.MethodPost, c.httpURL("/api/containers/abc/run", nil),
+			strings.NewReader(`{"cmd": "hostname"}`))
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	for _, tc := range []struct {
+		client *http.Client
+		header http.Header
+		code   int
+	}{
+		// the API clients without cookies
+		{http.DefaultClient, nil, http.StatusOK},
+		{browser, nil, http.StatusForbidden},
+		{browser, http.Header{"X-Csrf-Token": {"bad"}}, http.StatusForbidden},
+		{browser, http.Header{"X-Csrf-Token": {token}}, http.StatusOK},
+		{browser, http.Header{"X-Csrf-Token": {token}, "Origin": {"http://evil.example.com"}}, http.StatusForbidden},
+		{http.DefaultClient, http.Header{"Origin": {"http://evil.example.com"}}, http.StatusForbidden},
+	} {
+		if code := run(tc.client, tc.header); code != tc.code {
+			t.Errorf("expect %d of %v, got %d", tc.code, tc.header, code)
+		}
+	}
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	srv, err := route.New(fakeCli{}, event.NewHub(), config.ServerConfig{HSTSMaxAge: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewTLSServer(srv.Handler())
+	defer ts.Close()
+
+	resp, err := ts.Client().Get(ts.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	csp := resp.Header.Get("Content-Security-Policy")
+	if !strings.Contains(csp, "script-src 'self';") || !strings.Contains(csp, "frame-ancestors 'self'") ||
+		resp.Header.Get("X-Frame-Options") != "SAMEORIGIN" ||
+		resp.Header.Get("Strict-Transport-Security") != "max-age=3600" ||
+		resp.Header.Get("Referrer-Policy") != "same-origin" {
+		t.Fatalf("unexpected headers: %v", resp.Header)
+	}
+	// allowed by the CSP
+	if bytes.Contains(page, []byte("<script>")) {
+		t.Fatalf("inline script in the page: %s", page)
+	}
+
+	c, closeServer := newTestServerWith(t, config.ServerConfig{
+		FrameAncestors: "https://dashboard.example.com",
+	})
+	defer closeServer()
+	resp, err = http.Get(c.httpURL("/exec/abc/", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if csp := resp.Header.Get("Content-Security-Policy"); !strings.HasSuffix(csp, "frame-ancestors https://dashboard.example.com") ||
+		resp.Header.Get("X-Frame-Options") != "" || resp.Header.Get("Strict-Transport-Security") != "" {
+		t.Fatalf("unexpected headers: %v", resp.Header)
+	}
+}
+
+func TestWSOrigin(t *testing.T) {
+	ctx := context.Background()
+	dialer := websocket.Dialer{Subprotocols: webtty.Protocols}
+	for _, tc := range []struct {
+		conf    config.ServerConfig
+		origin  string
+		upgrade bool
+	}{
+		{config.ServerConfig{}, "", true},
+		{config.ServerConfig{}, "same", true},
+		{config.ServerConfig{}, "http://evil.example.com", false},
+		{config.ServerConfig{WSOrigin: `^https://tty\.example\.com$`}, "https://tty.example.com", true},
+		{config.ServerConfig{WSOrigin: `^https://tty\.example\.com$`}, "http://evil.example.com", false},
+		{config.ServerConfig{WSOriginAny: true}, "http://evil.example.com", true},
+	} {
+		c, closeServer := newTestServerWith(t, tc.conf)
+		header := http.Header{}
+		if tc.origin == "same" {
+			header.Set("Origin", c.httpURL("", nil))
+		} else if tc.origin != "" {
+			header.Set("Origin", tc.origin)
+		}
+		conn, _, err := dialer.DialContext(ctx, c.wsURL("/exec/abc/ws", nil), header)
+		if err == nil {
+			conn.Close()
+		}
+		if (err == nil) != tc.upgrade {
+			t.Errorf("expect the upgrade %v of %q with %+v, got %v", tc.upgrade, tc.origin, tc.conf, err)
+		}
+		closeServer()
+	}
+
+	if _, err := route.New(fakeCli{}, event.NewHub(), config.ServerConfig{
+		WSOrigin: ".*", WSOriginAny: true,
+	}); err == nil {
+		t.Fatal("no error of both the regexp and any")
+	}
+}
+
+func TestBasePath(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	srv, err := route.New(fakeCli{}, event.NewHub(), config.ServerConfig{BasePath: "/tty/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+	c, err := New(ts.URL + "/tty")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := c.Attach(context.Background(), "abc", types.ExecOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Write([]byte("hello\n"))
+	if line, err := bufio.NewReader(s).ReadString('\n'); err != nil || line != "hello\n" {
+		t.Fatalf("unexpected output: %q %v", line, err)
+	}
+	s.Close()
+
+	get := func(path string) (int, string) {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+	code, page := get("/tty")
+	if code != http.StatusOK || !strings.Contains(page, `src="/tty/js/control.js?v=`) ||
+		!strings.Contains(page, `href="/tty/exec/abc`) || !strings.Contains(page, `src="/tty/config.js"`) {
+		t.Fatalf("unexpected page: %d %s", code, page)
+	}
+	if code, script := get("/tty/config.js"); code != http.StatusOK || !strings.Contains(script, "gotty_base_path = '/tty'") {
+		t.Fatalf("unexpected config: %d %s", code, script)
+	}
+	if code, _ := get("/tty/js/control.js"); code != http.StatusOK {
+		t.Fatalf("the asset is not served under the base path: %d", code)
+	}
+	if code, _ := get("/api/containers"); code != http.StatusNotFound {
+		t.Fatalf("served out of the base path: %d", code)
+	}
+
+	if _, err := route.New(fakeCli{}, event.NewHub(), config.ServerConfig{BasePath: "tty"}); err == nil {
+		t.Fatal("no error of a relative base path")
+	}
+}
+
+func TestTrustedProxies(t *testing.T) {
+	ctx := context.Background()
+	dialer := websocket.Dialer{Subprotocols: webtty.Protocols}
+	forwarded := http.Header{"X-Forwarded-For": {"10.9.9.9, 1.2.3.4"}}
+	for _, tc := range []struct {
+		proxies []string
+		client  string
+	}{
+		// a client can't fake its IP
+		{nil, "127.0.0.1"},
+		{[]string{"10.0.0.0/8"}, "127.0.0.1"},
+		// the proxy forwarding it is trusted
+		{[]string{"127.0.0.1"}, "1.2.3.4"},
+		{[]string{"127.0.0.0/8", "1.2.3.4"}, "10.9.9.9"},
+	} {
+		c, closeServer := newTestServerWith(t, config.ServerConfig{TrustedProxies: tc.proxies})
+		conn, _, err := dialer.DialContext(ctx, c.wsURL("/exec/abc/ws", nil), forwarded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		init, _ := c.initMessage(types.ExecOptions{})
+		conn.WriteMessage(websocket.TextMessage, init)
+		conn.WriteMessage(websocket.TextMessage, []byte("1hello\n"))
+		readMessage(t, conn, webtty.Output)
+
+		sessions, err := c.Sessions(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sessions) != 1 || !strings.HasPrefix(sessions[0].Client, tc.client+":") {
+			t.Errorf("expect the client %s with the proxies %v, got %+v", tc.client, tc.proxies, sessions)
+		}
+		conn.Close()
+		closeServer()
+	}
+
+	if _, err := route.New(fakeCli{}, event.NewHub(), config.ServerConfig{
+		TrustedProxies: []string{"10.0.0.0/33"},
+	}); err == nil {
+		t.Fatal("no error of a bad CIDR")
+	}
+}
+
+func TestSessionURL(t *testing.T) {
+	ctx := context.Background()
+	c, closeServer := newTestServerWith(t, config.ServerConfig{
+		SessionURLTTL:  time.Second,
+		TrustedProxies: []string{"127.0.0.1"},
+		AdminToken:     "s3cret",
+	}, WithAdminToken("s3cret"))
+	defer closeServer()
+
+	// the raw IDs are refused
+	if _, err := c.Attach(ctx, "abc", types.ExecOptions{}); err == nil {
+		t.Fatal("attached by the raw ID")
+	}
+	get := func(path string, header http.Header) int {
+		req, _ := http.NewRequest(http.MethodGet, c.httpURL(path, nil), nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	for _, path := range []string{"/api/containers/abc/top", "/c/abc/top/", "/logs/abc/"} {
+		if code := get(path, nil); code != http.StatusForbidden {
+			t.Fatalf("unexpected status of %s by the raw ID: %d", path, code)
+		}
+	}
+	// but not for the admins
+	admin := http.Header{"Authorization": {"Bearer s3cret"}}
+	if code := get("/api/containers/abc/top", admin); code != http.StatusOK {
+		t.Fatalf("unexpected status of the admin by the raw ID: %d", code)
+	}
+	// the tokens are minted by the admins only
+	if code := get("/api/containers/abc/session-url", nil); code != http.StatusUnauthorized {
+		t.Fatalf("unexpected status of a session URL without auth: %d", code)
+	}
+	result, err := c.SessionURL(ctx, "abc", types.ExecOptions{User: "nobody"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := strings.TrimSuffix(strings.TrimPrefix(result.URL, "/exec/"), "/")
+	if strings.Contains(token, "abc") || result.ExpireAt == nil {
+		t.Fatalf("unexpected session URL: %+v", result)
+	}
+	sess, err := c.Attach(ctx, token, types.ExecOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sess.Close()
+	if code := get("/api/containers/"+token+"/top", nil); code != http.StatusOK {
+		t.Fatalf("unexpected status by the token: %d", code)
+	}
+	if code := get("/c/"+token+"/top/", nil); code != http.StatusOK {
+		t.Fatalf("unexpected status of the page by the token: %d", code)
+	}
+
+	// the list page links to the tokens
+	resp, err := http.Get(c.httpURL("/", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !bytes.Contains(page, []byte(`href="/exec/`)) || bytes.Contains(page, []byte(`href="/exec/abc/"`)) {
+		t.Fatalf("unexpected exec links: %s", page)
+	}
+
+	// not valid from another IP
+	dialer := websocket.Dialer{Subprotocols: webtty.Protocols}
+	forwarded := http.Header{"X-Forwarded-For": {"1.2.3.4"}}
+	if _, resp, err := dialer.DialContext(ctx, c.wsURL("/exec/"+token+"/ws", nil), forwarded); err == nil ||
+		resp == nil || resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("attached from another IP: %v", err)
+	}
+
+	// the exec options are of the token, not of the init message
+	pinned, err := c.SessionURL(ctx, "abc", types.ExecOptions{Cmd: "top"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pinnedToken := strings.TrimSuffix(strings.TrimPrefix(pinned.URL, "/exec/"), "/")
+	sess, err = c.Attach(ctx, pinnedToken, types.ExecOptions{Cmd: "sh", User: "root", Privileged: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sess.Write([]byte("hello\n")); err != nil {
+		t.Fatal(err)
+	}
+	if line, err := bufio.NewReader(sess).ReadString('\n'); err != nil || line != "hello\n" {
+		t.Fatalf("unexpected output of the pinned session: %q, %v", line, err)
+	}
+	sessions, err := c.AdminSessions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 || sessions[0].Cmd != "top" || sessions[0].User != "" {
+		t.Fatalf("unexpected sessions of the pinned token: %+v", sessions)
+	}
+	sess.Close()
+
+	// issued for another IP, valid from it only
+	_, err = c.SessionURL(ctx, "abc", types.ExecOptions{}, "nope")
+	if apiErr, ok := err.(types.APIError); !ok || apiErr.Code != http.StatusBadRequest {
+		t.Fatalf("expect an API error of a bad ip, got %v", err)
+	}
+	other, err := c.SessionURL(ctx, "abc", types.ExecOptions{}, "1.2.3.4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherToken := strings.TrimSuffix(strings.TrimPrefix(other.URL, "/exec/"), "/")
+	req, _ := http.NewRequest(http.MethodGet, c.httpURL(other.URL, nil), nil)
+	req.Header.Set("X-Forwarded-For", "1.2.3.4")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status of the URL from its IP: %d", resp.StatusCode)
+	}
+	init, _ := c.initMessage(types.ExecOptions{})
+	conn, _, err := dialer.DialContext(ctx, c.wsURL("/exec/"+otherToken+"/ws", nil), forwarded)
+	if err != nil {
+		t.Fatalf("attach from the IP of the URL: %v", err)
+	}
+	if err := conn.WriteMessage(websocket.TextMessage, init); err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+	if code := get(other.URL, nil); code != http.StatusForbidden {
+		t.Fatalf("unexpected status of the URL from the admin IP: %d", code)
+	}
+
+	// nor after the TTL
+	time.Sleep(2 * time.Second)
+	resp, err = http.Get(c.httpURL(result.URL, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("unexpected status of an expired URL: %d", resp.StatusCode)
+	}
+}
+
+func TestStaticAssets(t *testing.T) {
+	c, closeServer := newTestServer(t)
+	defer closeServer()
+
+	resp, err := http.Get(c.httpURL("/exec/abc/", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	i := bytes.Index(page, []byte("/js/gotty-bundle.js?v="))
+	if i == -1 {
+		t.Fatalf("no versioned URL of the bundle: %s", page)
+	}
+	version := string(page[i+len("/js/gotty-bundle.js?v=") : i+bytes.IndexByte(page[i:], '"')])
+	integrity := page[i+bytes.IndexByte(page[i:], '"'):]
+	if !bytes.HasPrefix(integrity, []byte(`" integrity="sha384-`)) {
+		t.Fatalf("no integrity of the bundle: %s", page)
+	}
+	integrity = integrity[len(`" integrity="`):]
+	integrity = integrity[:bytes.IndexByte(integrity, '"')]
+
+	req, _ := http.NewRequest(http.MethodGet,
+		c.httpURL("/js/gotty-bundle.js", url.Values{"v": {version}}), nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gr, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(gr)
+	resp.Body.Close()
+	if resp.Header.Get("Content-Encoding") != "gzip" || len(body) == 0 ||
+		!strings.Contains(resp.Header.Get("Cache-Control"), "immutable") {
+		t.Fatalf("unexpected response: %v", resp.Header)
+	}
+	if sum := sha512.Sum384(body); string(integrity) != "sha384-"+base64.StdEncoding.EncodeToString(sum[:]) {
+		t.Fatalf("integrity %s doesn't match the bundle", integrity)
+	}
+
+	// revalidated by the ETag without the version
+	req, _ = http.NewRequest(http.MethodGet, c.httpURL("/js/gotty-bundle.js", nil), nil)
+	req.Header.Set("If-None-Match", resp.Header.Get("ETag"))
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotModified || resp.Header.Get("Cache-Control") != "no-cache" {
+		t.Fatalf("unexpected response: %s %v", resp.Status, resp.Header)
+	}
+}
+
+func TestDevAssets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dev-assets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	c, closeServer := newTestServerWith(t, config.ServerConfig{DevAssets: dir})
+	defer closeServer()
+
+	write := func(name, content string) {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	get := func(path string) string {
+		resp, err := http.Get(c.httpURL(path, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("get %s: %s", path, resp.Status)
+		}
+		return string(body)
+	}
+
+	write("list.html", `<link href="/css/list.css"><p>{{ len .containers }} containers</p>`)
+	write("list.css", "p {}")
+
+	if page := get("/"); !strings.Contains(page, "1 containers") || !strings.Contains(page, "/css/list.css?v=") {
+		t.Fatalf("unexpected page: %s", page)
+	}
+	// reloaded without restarting
+	write("list.html", "<p>edited</p>")
+	write("list.css", "p { color: red }")
+	if page, css := get("/"), get("/css/list.css"); page != "<p>edited</p>" || css != "p { color: red }" {
+		t.Fatalf("not reloaded: %s %s", page, css)
+	}
+	// the embedded ones are served if they're not in the dir
+	get("/js/gotty-bundle.js")
+
+	if _, err := route.New(fakeCli{}, event.NewHub(), config.ServerConfig{
+		DevAssets: filepath.Join(dir, "nonexistent"),
+	}); err == nil {
+		t.Fatal("no error of a nonexistent dir")
+	}
+}
+
+func TestStaticDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "static-dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for name, content := range map[string]string{
+		"custom.css": "body { background: black }",
+		"logo.svg":   "<svg></svg>",
+		"list.html":  `<html><head></head><p>{{ len .containers }} containers</p></html>`,
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c, closeServer := newTestServerWith(t, config.ServerConfig{StaticDir: dir})
+	defer closeServer()
+
+	get := func(path string) (string, string) {
+		resp, err := http.Get(c.httpURL(path, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("get %s: %s", path, resp.Status)
+		}
+		return string(body), resp.Header.Get("Content-Type")
+	}
+	// the replaced page links the custom style, and so do the embedded ones
+	if page, _ := get("/"); !strings.Contains(page, "1 containers") ||
+		!strings.Contains(page, `href="/css/custom.css?v=`) {
+		t.Fatalf("unexpected page: %s", page)
+	}
+	if page, _ := get("/admin.html"); !strings.Contains(page, `href="/css/custom.css?v=`) {
+		t.Fatalf("no custom style: %s", page)
+	}
+	if css, _ := get("/css/custom.css"); css != "body { background: black }" {
+		t.Fatalf("unexpected style: %s", css)
+	}
+	if svg, cType := get("/logo.svg"); svg != "<svg></svg>" || cType != "image/svg+xml" {
+		t.Fatalf("unexpected image: %s %s", svg, cType)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "list.html"), []byte("{{ .bad"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := route.New(fakeCli{}, event.NewHub(), config.ServerConfig{StaticDir: dir}); err == nil {
+		t.Fatal("no error of a bad page")
+	}
+	if _, err := route.New(fakeCli{}, event.NewHub(), config.ServerConfig{
+		StaticDir: dir, DevAssets: dir,
+	}); err == nil {
+		t.Fatal("no error of both the dirs")
+	}
+}
+
+func TestTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+
+	writeCert := func(name string, mod time.Time) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tmpl := &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Subject:      pkix.Name{CommonName: name},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for file, block := range map[string]*pem.Block{
+			certFile: {Type: "CERTIFICATE", Bytes: der},
+			keyFile:  {Type: "EC PRIVATE KEY", Bytes: keyDER},
+		} {
+			if err := ioutil.WriteFile(file, pem.EncodeToMemory(block), 0600); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chtimes(file, mod, mod); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	writeCert("first", time.Now().Add(-time.Minute))
+
+	srv, err := route.New(fakeCli{}, event.NewHub(), config.ServerConfig{
+		TLSCert: certFile, TLSKey: keyFile, TLSProfile: "modern",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// not StartTLS(), its certificate would be served without SNI
+	ts := httptest.NewUnstartedServer(srv.Handler())
+	ts.Listener = tls.NewListener(ts.Listener, srv.TLSConfig())
+	ts.Start()
+	defer ts.Close()
+
+	served := func() string {
+		conn, err := tls.Dial("tcp", ts.Listener.Addr().String(), &tls.Config{
+			InsecureSkipVerify: true,
+			MaxVersion:         tls.VersionTLS12,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		state := conn.ConnectionState()
+		// tls.InsecureCipherSuites() of Go 1.14
+		switch state.CipherSuite {
+		case tls.TLS_RSA_WITH_RC4_128_SHA, tls.TLS_RSA_WITH_3DES_EDE_CBC_SHA,
+			tls.TLS_RSA_WITH_AES_128_CBC_SHA256, tls.TLS_ECDHE_ECDSA_WITH_RC4_128_SHA,
+			tls.TLS_ECDHE_RSA_WITH_RC4_128_SHA, tls.TLS_ECDHE_RSA_WITH_3DES_EDE_CBC_SHA,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA256, tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA256:
+			t.Fatalf("insecure cipher: %#04x", state.CipherSuite)
+		}
+		return state.PeerCertificates[0].Subject.CommonName
+	}
+	if name := served(); name != "first" {
+		t.Fatalf("unexpected certificate: %s", name)
+	}
+	// a renewed certificate is served without restarting
+	writeCert("second", time.Now())
+	if name := served(); name != "second" {
+		t.Fatalf("not reloaded: %s", name)
+	}
+
+	if _, err := route.New(fakeCli{}, event.NewHub(), config.ServerConfig{TLSCert: certFile}); err == nil {
+		t.Fatal("no error of a certificate without the key")
+	}
+
+	// only the FIPS-approved ciphers of the fips profile
+	fips, err := route.New(fakeCli{}, event.NewHub(), config.ServerConfig{
+		TLSCert: certFile, TLSKey: keyFile, TLSProfile: "fips",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		suite uint16
+		ok    bool
+	}{
+		{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256, true},
+		{tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305, false},
+	} {
+		ln, err := tls.Listen("tcp", "127.0.0.1:0", fips.TLSConfig())
+		if err != nil {
+			t.Fatal(err)
+		}
+		go func() {
+			if conn, err := ln.Accept(); err == nil {
+				conn.(*tls.Conn).Handshake()
+				conn.Close()
+			}
+		}()
+		conn, err := tls.Dial("tcp", ln.Addr().String(), &tls.Config{
+			InsecureSkipVerify: true,
+			MaxVersion:         tls.VersionTLS12,
+			CipherSuites:       []uint16{tc.suite},
+		})
+		if (err == nil) != tc.ok {
+			t.Errorf("unexpected handshake of %#04x: %v", tc.suite, err)
+		}
+		if err == nil {
+			conn.Close()
+		}
+		ln.Close()
+	}
+	if _, err := route.New(fakeCli{}, event.NewHub(), config.ServerConfig{TLSProfile: "legacy"}); err == nil {
+		t.Fatal("no error of an unknown TLS profile")
+	}
+}
+
+func TestLetsEncrypt(t *testing.T) {
+	srv, err := route.New(fakeCli{}, event.NewHub(), config.ServerConfig{
+		LetsEncrypt: true, Domains: []string{"tty.example.com"}, ACMECacheDir: "acme",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := srv.TLSConfig()
+	if conf == nil || conf.GetCertificate == nil {
+		t.Fatal("no TLS config of Let's Encrypt")
+	}
+	alpn := false
+	for _, proto := range conf.NextProtos {
+		alpn = alpn || proto == "acme-tls/1"
+	}
+	if !alpn {
+		t.Fatalf("no TLS-ALPN challenges: %v", conf.NextProtos)
+	}
+	// refused before asking the CA
+	if _, err := conf.GetCertificate(&tls.ClientHelloInfo{ServerName: "other.example.com"}); err == nil {
+		t.Fatal("no error of an unknown host")
+	}
+
+	for _, conf := range []config.ServerConfig{
+		{LetsEncrypt: true, ACMECacheDir: "acme"},
+		{LetsEncrypt: true, Domains: []string{"tty.example.com"}, ACMECacheDir: "acme", TLSCert: "tls.crt", TLSKey: "tls.key"},
+	} {
+		if _, err := route.New(fakeCli{}, event.NewHub(), conf); err == nil {
+			t.Fatalf("no error of %+v", conf)
+		}
+	}
+}
+
+var (
+	benchSessions = flag.Int("sessions", 0, "concurrent sessions of BenchmarkSessions, 0 for 1, 10 and 100")
+	benchSize     = flag.Int("size", 1<<20, "output bytes of every session of BenchmarkSessions")
+	benchRate     = flag.Int("rate", 0, "output bytes per second of every session of BenchmarkSessions, 0 for unlimited")
+)
+
+// BenchmarkSessions streams the output of concurrent sessions through the
+// relay path, e.g. 100 sessions of 1MB/s for 5s each:
+//
+//	go test ./client -run - -bench Sessions -sessions 100 -size 5242880 -rate 1048576
+func BenchmarkSessions(b *testing.B) {
+	level := log.GetLevel()
+	log.SetLevel(log.WarnLevel)
+	defer log.SetLevel(level)
+
+	counts := []int{1, 10, 100}
+	if *benchSessions > 0 {
+		counts = []int{*benchSessions}
+	}
+	for _, n := range counts {
+		b.Run(fmt.Sprintf("sessions=%d", n), func(b *testing.B) {
+			benchmarkSessions(b, n, *benchSize, *benchRate)
+		})
+	}
+}
+
+func benchmarkSessions(b *testing.B, sessions, size, rate int) {
+	c, closeServer := newTestServerWith(b, config.ServerConfig{})
+	defer closeServer()
+	cmd := fmt.Sprintf("stream %d %d", size, rate)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(sessions * size))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var wg sync.WaitGroup
+		errs := make(chan error, sessions)
+		for j := 0; j < sessions; j++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s, err := c.Attach(context.Background(), "abc", types.ExecOptions{Cmd: cmd})
+				if err != nil {
+					errs <- err
+					return
+				}
+				defer s.Close()
+				n, err := io.Copy(ioutil.Discard, s)
+				if err == nil && n != int64(size) {
+					err = fmt.Errorf("read %d bytes of %d", n, size)
+				}
+				if err != nil {
+					errs <- err
+				}
+			}()
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "access-log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "access.log")
+	c, closeServer := newTestServerWith(t, config.ServerConfig{
+		AccessLog: config.AccessLogConfig{
+			Path:        file,
+			Format:      "json",
+			SampleRatio: 0,
+			SkipPaths:   []string{"/healthz"},
+		},
+	})
+	defer closeServer()
+
+	for _, path := range []string{"/healthz", "/api/containers", "/api/containers/xyz"} {
+		resp, err := http.Get(c.httpURL(path, url.Values{"q": {"1"}}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	// the successful requests are not sampled, the health checks skipped
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expect the failed request logged only, got %q", content)
+	}
+	var entry struct {
+		RequestID string `json:"request_id"`
+		Method    string
+		Path      string
+		Status    int
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Method != "GET" || entry.Path != "/api/containers/xyz?q=1" ||
+		entry.Status != 404 || entry.RequestID == "" {
+		t.Fatalf("bad access log %s", lines[0])
+	}
+
+	if _, err := route.New(fakeCli{}, event.NewHub(), config.ServerConfig{
+		AccessLog: config.AccessLogConfig{Path: file, Format: "common"},
+	}); err == nil {
+		t.Fatal("expect an error of a bad format")
+	}
+}
+
+func TestPanicReports(t *testing.T) {
+	c, closeServer := newTestServerWith(t, config.ServerConfig{
+		AdminToken: "secret",
+		Control:    config.ControlConfig{Enable: true, Commit: true},
+	}, WithAdminToken("secret"))
+	defer closeServer()
+	ctx := context.Background()
+
+	_, err := c.Commit(ctx, "abc", types.CommitOptions{Image: "panic"})
+	apiErr, ok := err.(types.APIError)
+	if !ok || apiErr.Code != 500 {
+		t.Fatalf("expect an internal error of the panic, got %v", err)
+	}
+
+	reports, err := c.Errors(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) == 0 {
+		t.Fatal("expect the report of the panic")
+	}
+	r := reports[0]
+	if r.Message != "commit panic" || r.RequestID != apiErr.RequestID ||
+		r.Path != "/api/containers/abc/commit" || !strings.Contains(apiErr.Message, r.ID) ||
+		!strings.Contains(r.Stack, "fakeCli.Commit") {
+		t.Fatalf("unexpected report: %+v", r)
+	}
+}
+
+func TestLogLevels(t *testing.T) {
+	c, closeServer := newTestServerWith(t, config.ServerConfig{AdminToken: "secret"},
+		WithAdminToken("secret"))
+	defer closeServer()
+	ctx := context.Background()
+
+	levels, err := c.LogLevels(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if levels.Level == "" {
+		t.Fatalf("expect the level of the logs, got %+v", levels)
+	}
+	for _, bad := range []types.LogLevels{
+		{Level: "loud"},
+		{Level: "info", Modules: map[string]string{"nothing": "debug"}},
+	} {
+		_, err := c.SetLogLevels(ctx, bad)
+		if apiErr, ok := err.(types.APIError); !ok || apiErr.Code != 400 {
+			t.Fatalf("expect a bad request of %+v, got %v", bad, err)
+		}
+	}
+
+	if _, err := c.DebugLog(ctx, types.LogDebug{Field: "pod", Value: "x"}); err == nil {
+		t.Fatal("expect an error of the bad field")
+	}
+	debugs, err := c.DebugLog(ctx, types.LogDebug{Field: "user", Value: "log-test", Duration: "1m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, d := range debugs {
+		found = found || (d.Field == "user" && d.Value == "log-test" && time.Until(d.Until) > 50*time.Second)
+	}
+	if !found {
+		t.Fatalf("expect the debugged user, got %+v", debugs)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	c, closeServer := newTestServer(t)
+	defer closeServer()
+	ctx := context.Background()
+
+	dialer := websocket.Dialer{Subprotocols: webtty.Protocols}
+	for _, user := range []string{"alice", "bob", "alice"} {
+		conn, _, err := dialer.DialContext(ctx, c.wsURL("/exec/abc/ws", nil), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		init, _ := c.initMessage(types.ExecOptions{User: user})
+		conn.WriteMessage(websocket.TextMessage, init)
+		conn.WriteMessage(websocket.TextMessage, []byte("1hello\n"))
+		readMessage(t, conn, webtty.Output)
+		conn.Close()
+	}
+
+	var report []types.Usage
+	for i := 0; i < 50; i++ {
+		var err error
+		if report, err = c.Usage(ctx, "user", time.Time{}, time.Time{}); err != nil {
+			t.Fatal(err)
+		}
+		if len(report) == 2 && report[0].Sessions+report[1].Sessions == 3 {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	today := time.Now().UTC().Format("2006-01-02")
+	if len(report) != 2 || report[0].User != "alice" || report[0].Sessions != 2 ||
+		report[0].Day != today || report[0].ContainerID != "" || report[0].BytesIn == 0 ||
+		report[1].User != "bob" || report[1].Sessions != 1 {
+		t.Fatalf("unexpected usage: %+v", report)
+	}
+	if report, _ := c.Usage(ctx, "", time.Time{}, time.Now().AddDate(0, 0, -2)); len(report) != 0 {
+		t.Fatalf("expect no usage of the days before, got %+v", report)
+	}
+
+	resp, err := http.Get(c.httpURL("/api/reports", url.Values{"format": {"csv"}, "by": {"container"}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	rows, err := csv.NewReader(resp.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[0][0] != "day" || rows[1][2] != "abc" || rows[1][3] != "3" {
+		t.Fatalf("unexpected CSV: %v", rows)
+	}
+}
+
+func TestSessionErrors(t *testing.T) {
+	c, closeServer := newTestServer(t)
+	defer closeServer()
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		id, user, code string
+	}{
+		{"abc", "nobody", types.SessionExecDenied},
+		{"gone", "", types.SessionContainerGone},
+	} {
+		sess, err := c.Attach(ctx, tc.id, types.ExecOptions{User: tc.user})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = sess.Wait()
+		failure, ok := err.(types.SessionError)
+		if !ok || failure.Code != tc.code || failure.RequestID == "" {
+			t.Fatalf("expect the %s error, got %v", tc.code, err)
+		}
+		// the error of the backend is not told
+		if _, err := ioutil.ReadAll(sess); err != failure || strings.Contains(failure.Message, "passwd") {
+			t.Fatalf("unexpected error of the output: %v", err)
+		}
+		sess.Close()
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	c, closeServer := newTestServerWith(t, config.ServerConfig{
+		AdminToken: "secret",
+		Build:      config.BuildInfo{Version: "v1.2.3"},
+		Redacted:   map[string]string{"admin_token": "<redacted>"},
+		Effective: []types.ConfigOption{
+			{Name: "admin-token", Value: "<redacted>", Source: types.SourceEnv, Env: "WEB_TTY_ADMIN_TOKEN"},
+			{Name: "port", Value: "9000", Source: types.SourceFile},
+		},
+	}, WithAdminToken("secret"))
+	defer closeServer()
+
+	options, err := c.ConfigOptions(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(options) != 2 || options[1].Value != "9000" || options[1].Source != types.SourceFile {
+		t.Fatalf("unexpected options: %+v", options)
+	}
+
+	r, err := c.Diagnostics(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]string)
+	tr := tar.NewReader(gr)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := ioutil.ReadAll(tr)
+		files[filepath.Base(h.Name)] = string(data)
+	}
+	var checks map[string]struct{ Result string }
+	json.Unmarshal([]byte(files["checks.json"]), &checks)
+	if !strings.Contains(files["version.json"], `"v1.2.3"`) ||
+		strings.Contains(files["config.json"], "secret") ||
+		checks["backend"].Result != "ok" ||
+		!strings.Contains(files["goroutines.txt"], "goroutine") ||
+		files["errors.json"] == "" || files["runtime.json"] == "" ||
+		!strings.Contains(files["options.json"], `"source": "file"`) {
+		t.Fatalf("unexpected bundle: %v", files)
+	}
+}
+
+func TestJournal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	c, closeServer := newTestServerWith(t, config.ServerConfig{
+		AdminToken:     "secret",
+		JournalDir:     dir,
+		JournalMaxSize: 1 << 20,
+	}, WithAdminToken("secret"))
+	defer closeServer()
+	ctx := context.Background()
+
+	dialer := websocket.Dialer{Subprotocols: webtty.Protocols}
+	conn, _, err := dialer.DialContext(ctx, c.wsURL("/exec/abc/ws", nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	init, _ := c.initMessage(types.ExecOptions{User: "alice"})
+	conn.WriteMessage(websocket.TextMessage, init)
+	conn.WriteMessage(websocket.TextMessage, []byte("1hello\n"))
+	readMessage(t, conn, webtty.Output)
+	conn.Close()
+
+	var events []types.ServerEvent
+	for i := 0; i < 50; i++ {
+		if events, err = c.Journal(ctx, "session", 0, 10); err != nil {
+			t.Fatal(err)
+		}
+		if len(events) > 0 {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if len(events) != 1 || events[0].ID == 0 || events[0].Fields["container"] != "abc" ||
+		events[0].Fields["user"] != "alice" || events[0].Fields["bytes_in"] == "0" {
+		t.Fatalf("unexpected session events: %+v", events)
+	}
+	if events, err = c.Journal(ctx, "session", events[0].ID, 10); err != nil || len(events) != 0 {
+		t.Fatalf("expect no events before the first, got %+v, %v", events, err)
+	}
+	if _, err := c.Journal(ctx, "", 0, 5000); err == nil {
+		t.Fatal("expect an error of the limit")
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	srv, err := route.New(fakeCli{}, event.NewHub(), config.ServerConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var seen []string
+	ts := httptest.NewServer(srv.Handler(route.WithoutGinLogger(), route.WithMiddleware(func(c *gin.Context) {
+		// after the middleware of the server
+		c.Header("X-Request-ID-Seen", strconv.FormatBool(c.GetString("request_id") != ""))
+		c.Next()
+		seen = append(seen, c.Request.URL.Path)
+	})))
+	defer ts.Close()
+
+	for _, path := range []string{"/healthz", "/nonexistent"} {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.Header.Get("X-Request-ID-Seen") != "true" {
+			t.Fatalf("middleware not run after the server's of %s: %v", path, resp.Header)
+		}
+	}
+	if len(seen) != 2 {
+		t.Fatalf("unexpected requests seen: %v", seen)
+	}
+}
+
+func TestReload(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	srv, err := route.New(fakeCli{}, event.NewHub(), config.ServerConfig{AdminToken: "old"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+	ctx := context.Background()
+
+	old, _ := New(ts.URL, WithAdminToken("old"))
+	if _, err := old.LogLevels(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// the admin API is refused without the token, and back with it
+	if err := srv.Reload(config.ServerConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = old.LogLevels(ctx)
+	if apiErr, ok := err.(types.APIError); !ok || apiErr.Code != http.StatusForbidden {
+		t.Fatalf("expect the admin API refused without the token, got %v", err)
+	}
+	if err := srv.Reload(config.ServerConfig{AdminToken: "new"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := old.LogLevels(ctx); err == nil {
+		t.Fatal("expect the old admin token denied")
+	}
+	c, _ := New(ts.URL, WithAdminToken("new"))
+	if _, err := c.LogLevels(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAdminAPIWithoutToken(t *testing.T) {
+	c, closeServer := newTestServerWith(t, config.ServerConfig{}, WithAdminToken("guess"))
+	defer closeServer()
+
+	// registered, but refused
+	_, err := c.LogLevels(context.Background())
+	if apiErr, ok := err.(types.APIError); !ok || apiErr.Code != http.StatusForbidden {
+		t.Fatalf("expect the admin API refused without the token, got %v", err)
+	}
+}
+
+func TestListeners(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	dir, err := ioutil.TempDir("", "listen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	public, admin := filepath.Join(dir, "public.sock"), filepath.Join(dir, "admin.sock")
+	srv, err := route.New(fakeCli{}, event.NewHub(), config.ServerConfig{
+		AdminToken: "secret",
+		Listen:     []string{"public@unix:" + public, "admin@unix:" + admin},
+		SocketMode: "0600",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	ready := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Run(ctx, route.WithReady(func() { close(ready) }))
+	}()
+	select {
+	case <-ready:
+	case err := <-done:
+		t.Fatal(err)
+	}
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	if info, err := os.Stat(admin); err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("unexpected socket %s: %v %v", admin, info, err)
+	}
+	unixClient := func(path string) *Client {
+		hc := &http.Client{Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", path)
+			},
+		}}
+		c, err := New("http://localhost", WithHTTPClient(hc), WithAdminToken("secret"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	pc, ac := unixClient(public), unixClient(admin)
+	if _, err := pc.Containers(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pc.LogLevels(ctx); err == nil {
+		t.Fatal("expect the admin API not found on the public listener")
+	}
+	if _, err := ac.LogLevels(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ac.Containers(ctx); err == nil {
+		t.Fatal("expect the containers not found on the admin listener")
+	}
+}
+
+func TestIPFamily(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+
+	run := func(conf config.ServerConfig) func() {
+		conf.Port = port
+		srv, err := route.New(fakeCli{}, event.NewHub(), conf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		ready := make(chan struct{})
+		done := make(chan error, 1)
+		go func() {
+			done <- srv.Run(ctx, route.WithReady(func() { close(ready) }))
+		}()
+		select {
+		case <-ready:
+		case err := <-done:
+			cancel()
+			t.Fatalf("run %+v: %s", conf, err)
+		}
+		return func() {
+			cancel()
+			<-done
+		}
+	}
+	reachable := func(host string) bool {
+		resp, err := http.Get("http://" + net.JoinHostPort(host, strconv.Itoa(port)) + "/healthz")
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}
+
+	// separate listeners of both the families
+	stop := run(config.ServerConfig{Address: "127.0.0.1,[::1]"})
+	if !reachable("127.0.0.1") || !reachable("::1") {
+		t.Fatal("expect both the listeners reachable")
+	}
+	stop()
+	// the IPs of the interface of the family
+	stop = run(config.ServerConfig{Address: "lo", IPFamily: "ipv4"})
+	if !reachable("127.0.0.1") || reachable("::1") {
+		t.Fatal("expect the IPv4 listener of lo only")
+	}
+	stop()
+
+	srv, err := route.New(fakeCli{}, event.NewHub(), config.ServerConfig{
+		Address: "::1", Port: port, IPFamily: "ipv4",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.Run(context.Background()); err == nil {
+		t.Fatal("expect an error of an IPv6 address of the family ipv4")
+	}
+	if _, err := route.New(fakeCli{}, event.NewHub(), config.ServerConfig{IPFamily: "ipv5"}); err == nil {
+		t.Fatal("expect an error of a bad family")
+	}
+}
+
+func TestAdminAddr(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	dir, err := ioutil.TempDir("", "admin-addr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	admin := filepath.Join(dir, "admin.sock")
+	srv, err := route.New(fakeCli{}, event.NewHub(), config.ServerConfig{
+		AdminToken: "secret",
+		AdminAddr:  "unix:" + admin,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	ready := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Run(ctx, route.WithListener(ln), route.WithReady(func() { close(ready) }))
+	}()
+	select {
+	case <-ready:
+	case err := <-done:
+		t.Fatal(err)
+	}
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	public, err := New("http://"+ln.Addr().String(), WithAdminToken("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := public.Containers(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := public.LogLevels(ctx); err == nil {
+		t.Fatal("expect the admin API not found on the public listener")
+	}
+	resp, err := http.Get("http://" + ln.Addr().String() + "/debug/vars")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expect the metrics not found on the public listener, got %d", resp.StatusCode)
+	}
+
+	hc := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", admin)
+		},
+	}}
+	c, err := New("http://localhost", WithHTTPClient(hc), WithAdminToken("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.LogLevels(ctx); err != nil {
+		t.Fatal(err)
+	}
+	resp, err = hc.Get("http://localhost/debug/vars")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expect the metrics on the admin listener, got %d", resp.StatusCode)
+	}
+}
+
+func TestSetup(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	defaults := types.SetupConfig{Backend: "docker", Address: "127.0.0.1", Port: 8080, Fixed: []string{"port"}}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var written types.SetupConfig
+	done := make(chan error, 1)
+	go func() {
+		_, err := route.RunSetup(ctx, addr, "code", defaults, func(s types.SetupConfig) error {
+			written = s
+			return nil
+		})
+		done <- err
+	}()
+
+	post := func(body string) int {
+		for i := 0; ; i++ {
+			resp, err := http.Post("http://"+addr+"/api/setup", "application/json", strings.NewReader(body))
+			if err != nil {
+				if i < 50 {
+					time.Sleep(20 * time.Millisecond)
+					continue
+				}
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			return resp.StatusCode
+		}
+	}
+	if code := post(`{"code":"bad","backend":"docker","port":9000}`); code != http.StatusUnauthorized {
+		t.Fatalf("expect 401 of a bad setup code, got %d", code)
+	}
+	if code := post(`{"code":"code","backend":"docker","port":9000,"admin_token":"short"}`); code != http.StatusBadRequest {
+		t.Fatalf("expect 400 of a short admin token, got %d", code)
+	}
+	if code := post(`{"code":"code","backend":"docker","address":"127.0.0.1","port":9000}`); code != http.StatusOK {
+		t.Fatalf("expect the config saved, got %d", code)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if written.Port != 8080 || len(written.AdminToken) < 16 || written.Code != "" {
+		t.Fatalf("unexpected config %+v", written)
+	}
+}
+
+func TestVersion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	release := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Cookie") != "" || strings.Contains(r.UserAgent(), "0.1.8") {
+			t.Errorf("unexpected request of the update check: %v", r.Header)
+		}
+		fmt.Fprint(w, `{"tag_name":"v0.2.0","html_url":"https://example.com/v0.2.0"}`)
+	}))
+	defer release.Close()
+
+	srv, err := route.New(fakeCli{}, event.NewHub(), config.ServerConfig{
+		AdminToken:     "secret",
+		Build:          config.BuildInfo{Version: "0.1.8", CommitID: "abc"},
+		UpdateCheck:    true,
+		UpdateCheckURL: release.URL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.Run(ctx, route.WithListener(ln)) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	c, err := New("http://"+ln.Addr().String(), WithAdminToken("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := c.Version(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Version != "0.1.8" || v.CommitID != "abc" || len(v.Backends) != 1 || v.Backends[0].Name != "fake" {
+		t.Fatalf("unexpected version: %+v", v)
+	}
+
+	var u types.UpdateCheck
+	for i := 0; i < 100 && u.CheckedAt.IsZero(); i++ {
+		time.Sleep(10 * time.Millisecond)
+		if u, err = c.UpdateCheck(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !u.Available || u.Latest != "v0.2.0" || u.URL != "https://example.com/v0.2.0" || u.Error != "" {
+		t.Fatalf("unexpected update check: %+v", u)
+	}
+
+	off, closeOff := newTestServerWith(t, config.ServerConfig{AdminToken: "secret"}, WithAdminToken("secret"))
+	defer closeOff()
+	if _, err := off.UpdateCheck(ctx); err == nil {
+		t.Fatal("expect the update check disabled")
+	}
+}
@@ -0,0 +1,206 @@
+package client
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/wrfly/container-web-tty/types"
+	"github.com/wrfly/container-web-tty/webtty"
+)
+
+// ErrNoExitCode is returned by Wait() if the session is closed
+// without the exit code of the process, e.g. the backend doesn't
+// report it or the session is closed by the client
+var ErrNoExitCode = errors.New("session closed without an exit code")
+
+// Session is an exec attached with the webtty protocol,
+// Read() reads the output and Write() writes the input
+type Session struct {
+	conn *websocket.Conn
+	wMux sync.Mutex
+
+	stdoutR, stderrR *io.PipeReader
+	stdoutW, stderrW *io.PipeWriter
+
+	exitCode *int
+	notices  chan Notice
+	done     chan struct{}
+	// max bytes of an input message, 0 for unlimited
+	maxInput int
+	// the failure of the session sent by the server, if any
+	failure *types.SessionError
+}
+
+// Notice is a notice of the server, like the restart of a draining
+// server, with a countdown of the Seconds if it's not 0
+type Notice struct {
+	Message string `json:"message"`
+	Seconds int    `json:"seconds"`
+}
+
+func newSession(conn *websocket.Conn) *Session {
+	s := &Session{
+		conn:    conn,
+		notices: make(chan Notice, 16),
+		done:    make(chan struct{}),
+	}
+	s.stdoutR, s.stdoutW = io.Pipe()
+	s.stderrR, s.stderrW = io.Pipe()
+	go s.readLoop()
+	return s
+}
+
+// readLoop dispatches the messages of the server until the connection
+// is closed, the server closes it as soon as the process exits
+func (s *Session) readLoop() {
+	defer close(s.done)
+	defer close(s.notices)
+
+	var err error
+	defer func() {
+		if err == nil && s.failure != nil {
+			err = *s.failure
+		}
+		if err == nil {
+			err = io.EOF
+		}
+		s.stdoutW.CloseWithError(err)
+		s.stderrW.CloseWithError(err)
+	}()
+
+	for {
+		_, msg, e := s.conn.ReadMessage()
+		if e != nil {
+			return
+		}
+		if len(msg) == 0 {
+			continue
+		}
+
+		payload := msg[1:]
+		switch msg[0] {
+		case webtty.Output, webtty.OutputStderr:
+			var data []byte
+			if data, err = base64.StdEncoding.DecodeString(string(payload)); err != nil {
+				return
+			}
+			w := s.stdoutW
+			if msg[0] == webtty.OutputStderr {
+				w = s.stderrW
+			}
+			w.Write(data)
+		case webtty.Exited:
+			var exited struct{ Code int }
+			if json.Unmarshal(payload, &exited) == nil {
+				s.exitCode = &exited.Code
+			}
+		case webtty.SetMaxInput:
+			var maxInput struct{ Bytes int }
+			if json.Unmarshal(payload, &maxInput) == nil {
+				s.wMux.Lock()
+				s.maxInput = maxInput.Bytes
+				s.wMux.Unlock()
+			}
+		case webtty.Error:
+			var failure types.SessionError
+			if json.Unmarshal(payload, &failure) == nil {
+				s.failure = &failure
+			}
+		case webtty.Notice:
+			var notice Notice
+			if json.Unmarshal(payload, &notice) == nil {
+				select {
+				case s.notices <- notice:
+				default: // not read
+				}
+			}
+		}
+	}
+}
+
+func (s *Session) send(typ byte, payload []byte) error {
+	s.wMux.Lock()
+	defer s.wMux.Unlock()
+	return s.conn.WriteMessage(websocket.TextMessage, append([]byte{typ}, payload...))
+}
+
+// Read reads the output of the process, it's the stdout and
+// stderr of a tty session, or only the stdout without a tty
+func (s *Session) Read(p []byte) (int, error) {
+	return s.stdoutR.Read(p)
+}
+
+// Stderr returns the stderr of a session without a tty, it must
+// be drained while reading the output since they share a connection
+func (s *Session) Stderr() io.Reader {
+	return s.stderrR
+}
+
+// Write writes the input of the process, it's split into the messages
+// of the max input of the server
+func (s *Session) Write(p []byte) (int, error) {
+	s.wMux.Lock()
+	maxInput := s.maxInput
+	s.wMux.Unlock()
+
+	n := 0
+	for n < len(p) {
+		chunk := p[n:]
+		if maxInput > 0 && len(chunk) > maxInput {
+			size := maxInput
+			// not splitting a rune
+			for size > 1 && !utf8.RuneStart(chunk[size]) {
+				size--
+			}
+			chunk = chunk[:size]
+		}
+		if err := s.send(webtty.Input, chunk); err != nil {
+			return n, err
+		}
+		n += len(chunk)
+	}
+	return n, nil
+}
+
+// Resize resizes the terminal of the session
+func (s *Session) Resize(columns, rows int) error {
+	size, _ := json.Marshal(map[string]int{
+		"columns": columns,
+		"rows":    rows,
+	})
+	return s.send(webtty.ResizeTerminal, size)
+}
+
+// Notices returns the notices of the server, the ones not read in time
+// are dropped, it's closed with the session
+func (s *Session) Notices() <-chan Notice {
+	return s.notices
+}
+
+// Wait waits for the session to end and returns the exit code of the
+// process, a types.SessionError if the session failed
+func (s *Session) Wait() (int, error) {
+	<-s.done
+	if s.exitCode == nil && s.failure != nil {
+		return 0, *s.failure
+	}
+	if s.exitCode == nil {
+		return 0, ErrNoExitCode
+	}
+	return *s.exitCode, nil
+}
+
+// Close closes the session, the process is terminated by the server
+func (s *Session) Close() error {
+	s.wMux.Lock()
+	s.conn.WriteMessage(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	s.wMux.Unlock()
+	return s.conn.Close()
+}
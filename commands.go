@@ -0,0 +1,389 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/urfave/cli.v2"
+
+	"github.com/wrfly/container-web-tty/client"
+	"github.com/wrfly/container-web-tty/config"
+	"github.com/wrfly/container-web-tty/types"
+)
+
+// adminTimeout is the timeout of the commands talking to the server
+const adminTimeout = 30 * time.Second
+
+// commands are the subcommands of the binary, the ones of the sessions,
+// the tokens and the replays talk to the running server of the config with
+// the admin API
+func commands(conf *config.Config) []*cli.Command {
+	serverFlag := &cli.StringFlag{
+		Name:  "server",
+		Usage: "URL of the server, e.g. https://tty.example.com/base, the --admin-addr (or the port without it) of the local server by default",
+	}
+	return []*cli.Command{
+		{
+			Name:   "serve",
+			Usage:  "serve the terminals, the same as no command",
+			Action: serve(conf),
+		},
+		{
+			Name:  "sessions",
+			Usage: "list or kill the terminal sessions of the server",
+			Flags: []cli.Flag{serverFlag},
+			Subcommands: []*cli.Command{
+				{
+					Name:  "list",
+					Usage: "list the running and the recently closed sessions",
+					Flags: []cli.Flag{
+						&cli.BoolFlag{
+							Name:  "json",
+							Usage: "print the sessions as JSON",
+						},
+					},
+					Action: withAdmin(conf, func(c *cli.Context, ctx context.Context, api *client.Client) error {
+						sessions, err := api.AdminSessions(ctx)
+						if err != nil {
+							return err
+						}
+						return printSessions(os.Stdout, sessions, c.Bool("json"))
+					}),
+				},
+				{
+					Name:      "kill",
+					Usage:     "close the running or the detached session",
+					ArgsUsage: "ID",
+					Action: withAdmin(conf, func(c *cli.Context, ctx context.Context, api *client.Client) error {
+						id, err := argID(c)
+						if err != nil {
+							return err
+						}
+						if err := api.KillSession(ctx, id); err != nil {
+							return err
+						}
+						fmt.Printf("killed the session %s\n", id)
+						return nil
+					}),
+				},
+			},
+		},
+		{
+			Name:  "token",
+			Usage: "create or revoke the admin tokens of the server",
+			Flags: []cli.Flag{serverFlag},
+			Subcommands: []*cli.Command{
+				{
+					Name:  "create",
+					Usage: "create an admin token, which is printed only once",
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "name",
+							Usage: "name of the token, e.g. who it's for",
+						},
+						&cli.StringFlag{
+							Name:  "ttl",
+							Usage: "duration the token is valid for, e.g. 720h, never expires if it's empty",
+						},
+						&cli.BoolFlag{
+							Name:  "json",
+							Usage: "print the token with its ID and expiry as JSON",
+						},
+					},
+					Action: withAdmin(conf, func(c *cli.Context, ctx context.Context, api *client.Client) error {
+						token, err := api.CreateToken(ctx, types.AdminTokenOptions{
+							Name: c.String("name"),
+							TTL:  c.String("ttl"),
+						})
+						if err != nil {
+							return err
+						}
+						if c.Bool("json") {
+							enc := json.NewEncoder(os.Stdout)
+							enc.SetIndent("", "  ")
+							return enc.Encode(token)
+						}
+						fmt.Println(token.Token)
+						fmt.Fprintf(os.Stderr, "created the admin token %s, revoke it by 'token revoke %s'\n",
+							token.ID, token.ID)
+						return nil
+					}),
+				},
+				{
+					Name:      "revoke",
+					Usage:     "revoke the admin token",
+					ArgsUsage: "ID",
+					Action: withAdmin(conf, func(c *cli.Context, ctx context.Context, api *client.Client) error {
+						id, err := argID(c)
+						if err != nil {
+							return err
+						}
+						if err := api.RevokeToken(ctx, id); err != nil {
+							return err
+						}
+						fmt.Printf("revoked the admin token %s\n", id)
+						return nil
+					}),
+				},
+			},
+		},
+		{
+			Name:  "replay",
+			Usage: "export the recent output of the sessions of the server",
+			Flags: []cli.Flag{serverFlag},
+			Subcommands: []*cli.Command{
+				{
+					Name:      "export",
+					Usage:     "export the output replayed to a resumed browser of the running or the detached session, the server must keep it by --resume-timeout and --replay-buffer",
+					ArgsUsage: "ID",
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:    "output",
+							Aliases: []string{"o"},
+							Usage:   "file the output is written to, stdout by default",
+						},
+					},
+					Action: withAdmin(conf, func(c *cli.Context, ctx context.Context, api *client.Client) error {
+						id, err := argID(c)
+						if err != nil {
+							return err
+						}
+						replay, err := api.Replay(ctx, id)
+						if err != nil {
+							return err
+						}
+						defer replay.Close()
+						var w io.Writer = os.Stdout
+						if path := c.String("output"); path != "" {
+							f, err := os.Create(path)
+							if err != nil {
+								return err
+							}
+							defer f.Close()
+							w = f
+						}
+						_, err = io.Copy(w, replay)
+						return err
+					}),
+				},
+			},
+		},
+		{
+			Name:  "config",
+			Usage: "check the config file or print the effective options",
+			Subcommands: []*cli.Command{
+				{
+					Name:      "validate",
+					Usage:     "validate the config file of the arg or --config with the other options, then exit",
+					ArgsUsage: "[config.yaml]",
+					Action: func(c *cli.Context) error {
+						app := appContext(c)
+						path := c.Args().First()
+						if path == "" {
+							path = configPath(app)
+						}
+						if path == "" {
+							return cli.Exit("no config file, set it by the arg or --config", 1)
+						}
+						if err := setup(app, conf, path); err != nil {
+							return cli.Exit(err, 1)
+						}
+						fmt.Printf("%s is valid\n", path)
+						return nil
+					},
+				},
+				{
+					Name:      "dump",
+					Usage:     "print the effective options of the defaults, the config file, the env and the args, and where they're from, with the secrets redacted, then exit",
+					ArgsUsage: "[config.yaml]",
+					Flags: []cli.Flag{
+						&cli.BoolFlag{
+							Name:  "json",
+							Usage: "print the options as JSON",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						app := appContext(c)
+						path := c.Args().First()
+						if path == "" {
+							path = configPath(app)
+						}
+						if err := setup(app, conf, path); err != nil {
+							return cli.Exit(err, 1)
+						}
+						options, _ := conf.Server.Effective.([]types.ConfigOption)
+						return printOptions(os.Stdout, options, c.Bool("json"))
+					},
+				},
+			},
+		},
+	}
+}
+
+// appContext is the context of the app of the command, the global options
+// are set in it
+func appContext(c *cli.Context) *cli.Context {
+	lineage := c.Lineage()
+	return lineage[len(lineage)-1]
+}
+
+// serve is the action serving the terminals, of the app and the serve
+// command
+func serve(conf *config.Config) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		if c.Bool("help") {
+			return cli.ShowAppHelp(c)
+		}
+		c = appContext(c)
+		if err := setup(c, conf, configPath(c)); err != nil {
+			logrus.Fatal(err)
+		}
+		if needsSetup(c, conf) {
+			path, err := runSetup(c, conf)
+			if err == context.Canceled {
+				return nil
+			}
+			if err != nil {
+				logrus.Fatalf("setup error: %s", err)
+			}
+			if err := setup(c, conf, path); err != nil {
+				logrus.Fatal(err)
+			}
+		}
+		logrus.Debugf("got config: %+v", redacted(*conf))
+
+		run(c, *conf)
+		return nil
+	}
+}
+
+// withAdmin is the action of the command with the client of the admin API
+// of the server, the admin token is the one of the config
+func withAdmin(conf *config.Config, action func(*cli.Context, context.Context, *client.Client) error) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		app := appContext(c)
+		if err := setup(app, conf, configPath(app)); err != nil {
+			return cli.Exit(err, 1)
+		}
+		opts := []client.Option{client.WithAdminToken(conf.Server.AdminToken)}
+		addr := c.String("server")
+		if addr == "" {
+			var (
+				hc  *http.Client
+				err error
+			)
+			if addr, hc, err = localServer(conf.Server); err != nil {
+				return cli.Exit(err, 1)
+			}
+			opts = append(opts, client.WithHTTPClient(hc))
+		}
+		api, err := client.New(addr, opts...)
+		if err != nil {
+			return exit(err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), adminTimeout)
+		defer cancel()
+		return exit(action(c, ctx, api))
+	}
+}
+
+// exit exits with the error of the command
+func exit(err error) error {
+	if err == nil {
+		return nil
+	}
+	return cli.Exit(err, 1)
+}
+
+// localServer is the address of the admin API of the server of the options
+// on this host and the HTTP client of it, the --admin-addr (a unix socket
+// or host:port), or the port of the loopback address without it. The
+// certificate isn't verified with TLS, it's of the domains of the server.
+func localServer(options config.ServerConfig) (string, *http.Client, error) {
+	addr := strings.TrimPrefix(options.AdminAddr, "admin@")
+	transport := &http.Transport{}
+	scheme := "http"
+	if options.TLSCert != "" || options.LetsEncrypt {
+		scheme = "https"
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+
+	var host string
+	switch {
+	case strings.HasPrefix(addr, "unix:"):
+		path := strings.TrimPrefix(addr, "unix:")
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", path)
+		}
+		host = "localhost"
+	case addr != "":
+		h, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return "", nil, fmt.Errorf("bad admin address %s: %s", addr, err)
+		}
+		if ip := net.ParseIP(h); h == "" || ip != nil && ip.IsUnspecified() {
+			h = "127.0.0.1"
+		}
+		host = net.JoinHostPort(h, port)
+	case options.Port > 0:
+		host = net.JoinHostPort("127.0.0.1", strconv.Itoa(options.Port))
+	default:
+		return "", nil, fmt.Errorf("neither --admin-addr nor --port of the server, set --server")
+	}
+	return scheme + "://" + host + options.BasePath, &http.Client{Transport: transport}, nil
+}
+
+// argID is the ID of the arg of the command
+func argID(c *cli.Context) (string, error) {
+	id := c.Args().First()
+	if id == "" {
+		return "", fmt.Errorf("no ID, usage: %s %s", c.Command.FullName(), c.Command.ArgsUsage)
+	}
+	return id, nil
+}
+
+// printSessions prints the sessions as a table, or as JSON
+func printSessions(w io.Writer, sessions []types.Session, asJSON bool) error {
+	if asJSON {
+		if sessions == nil {
+			sessions = []types.Session{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(sessions)
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tCONTAINER\tUSER\tCLIENT\tSTARTED\tSTATUS")
+	for _, s := range sessions {
+		status := "running"
+		if s.EndAt != nil {
+			status = "closed " + s.EndAt.Sub(s.StartAt).Round(time.Second).String()
+			if s.Reason != "" {
+				status += ": " + s.Reason
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", s.ID, shortID(s.ContainerID),
+			s.User, s.Client, s.StartAt.Format(time.RFC3339), status)
+	}
+	return tw.Flush()
+}
+
+// shortID is the short ID of the container
+func shortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
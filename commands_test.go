@@ -0,0 +1,248 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/urfave/cli.v2"
+
+	"github.com/wrfly/container-web-tty/client"
+	"github.com/wrfly/container-web-tty/config"
+	"github.com/wrfly/container-web-tty/container"
+	"github.com/wrfly/container-web-tty/event"
+	"github.com/wrfly/container-web-tty/route"
+	"github.com/wrfly/container-web-tty/types"
+)
+
+// fakeCli has the container "abc", its terminals echo the input
+type fakeCli struct{ container.Unimplemented }
+
+func (fakeCli) List(context.Context) []types.Container {
+	return []types.Container{{ID: "abc", Name: "web", State: "running", Shell: "sh"}}
+}
+
+func (f fakeCli) GetInfo(ctx context.Context, id string) types.Container {
+	if id == "abc" {
+		return f.List(ctx)[0]
+	}
+	return types.Container{}
+}
+
+func (fakeCli) Exec(ctx context.Context, c types.Container) (types.TTY, error) {
+	r, w := io.Pipe()
+	return &echoTTY{r: r, w: w}, nil
+}
+
+type echoTTY struct {
+	r *io.PipeReader
+	w *io.PipeWriter
+}
+
+func (t *echoTTY) Read(p []byte) (int, error)                   { return t.r.Read(p) }
+func (t *echoTTY) Write(p []byte) (int, error)                  { return t.w.Write(p) }
+func (t *echoTTY) WindowTitleVariables() map[string]interface{} { return nil }
+func (t *echoTTY) ResizeTerminal(columns int, rows int) error   { return nil }
+func (t *echoTTY) Exit() error                                  { return t.w.Close() }
+func (t *echoTTY) ActiveChan() <-chan struct{}                  { return nil }
+func (t *echoTTY) Stderr() io.Reader                            { return nil }
+func (t *echoTTY) ExitCode() (int, error)                       { return 0, nil }
+
+// runCommand runs the app with the args, the stdout is returned
+func runCommand(t *testing.T, args ...string) (string, error) {
+	out, err := ioutil.TempFile("", "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(out.Name())
+	defer out.Close()
+
+	// the errors of the commands are not exiting the test
+	exiter, errWriter, stdout := cli.OsExiter, cli.ErrWriter, os.Stdout
+	cli.OsExiter, cli.ErrWriter, os.Stdout = func(int) {}, ioutil.Discard, out
+	defer func() { cli.OsExiter, cli.ErrWriter, os.Stdout = exiter, errWriter, stdout }()
+
+	conf := config.New()
+	app := &cli.App{
+		Name:     "container-web-tty",
+		Flags:    appFlags(conf),
+		HideHelp: true,
+		Commands: commands(conf),
+	}
+	err = app.Run(append([]string{"container-web-tty"}, args...))
+	bs, _ := ioutil.ReadFile(out.Name())
+	return string(bs), err
+}
+
+func TestAdminCommands(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	srv, err := route.New(fakeCli{}, event.NewHub(), config.ServerConfig{
+		AdminToken:    "s3cret",
+		ResumeTimeout: time.Minute,
+		ReplayBuffer:  4096,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+	os.Setenv("WEB_TTY_ADMIN_TOKEN", "s3cret")
+	defer os.Unsetenv("WEB_TTY_ADMIN_TOKEN")
+	ctx := context.Background()
+
+	api, err := client.New(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sess, err := api.Attach(ctx, "abc", types.ExecOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sess.Close()
+	if _, err := sess.Write([]byte("hello\n")); err != nil {
+		t.Fatal(err)
+	}
+	if line, err := bufio.NewReader(sess).ReadString('\n'); err != nil || line != "hello\n" {
+		t.Fatalf("unexpected output: %q, %v", line, err)
+	}
+
+	// sessions list
+	out, err := runCommand(t, "sessions", "--server", ts.URL, "list", "--json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sessions []types.Session
+	if err := json.Unmarshal([]byte(out), &sessions); err != nil {
+		t.Fatalf("bad sessions %q: %s", out, err)
+	}
+	if len(sessions) != 1 || sessions[0].ContainerID != "abc" || sessions[0].EndAt != nil {
+		t.Fatalf("unexpected sessions: %+v", sessions)
+	}
+	id := sessions[0].ID
+	out, err = runCommand(t, "sessions", "--server", ts.URL, "list")
+	if err != nil || !strings.HasPrefix(out, "ID ") || !strings.Contains(out, id+"  abc") || !strings.Contains(out, "running") {
+		t.Fatalf("unexpected table of the sessions: %q, %v", out, err)
+	}
+
+	// replay export
+	dir, err := ioutil.TempDir("", "replay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	replay := filepath.Join(dir, "replay")
+	if _, err := runCommand(t, "replay", "--server", ts.URL, "export", "--output", replay, id); err != nil {
+		t.Fatal(err)
+	}
+	if bs, _ := ioutil.ReadFile(replay); !strings.Contains(string(bs), "hello") {
+		t.Fatalf("unexpected replay: %q", bs)
+	}
+	if _, err := runCommand(t, "replay", "--server", ts.URL, "export", "nope"); err == nil {
+		t.Fatal("expect an error of an unknown session")
+	}
+
+	// sessions kill
+	if _, err := runCommand(t, "sessions", "--server", ts.URL, "kill"); err == nil ||
+		!strings.Contains(err.Error(), "no ID") {
+		t.Fatalf("expect an error without the ID, got %v", err)
+	}
+	out, err = runCommand(t, "sessions", "--server", ts.URL, "kill", id)
+	if err != nil || out != "killed the session "+id+"\n" {
+		t.Fatalf("unexpected output of kill: %q, %v", out, err)
+	}
+	if _, err := bufio.NewReader(sess).ReadString('\n'); err == nil {
+		t.Fatal("the killed session is still open")
+	}
+
+	// token create and revoke
+	out, err = runCommand(t, "token", "--server", ts.URL, "create", "--name", "ci", "--ttl", "1h", "--json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var token types.AdminToken
+	if err := json.Unmarshal([]byte(out), &token); err != nil || token.ID == "" || token.Token == "" || token.Name != "ci" {
+		t.Fatalf("unexpected token %q: %v", out, err)
+	}
+	ci, _ := client.New(ts.URL, client.WithAdminToken(token.Token))
+	if _, err := ci.LogLevels(ctx); err != nil {
+		t.Fatal(err)
+	}
+	out, err = runCommand(t, "token", "--server", ts.URL, "revoke", token.ID)
+	if err != nil || out != "revoked the admin token "+token.ID+"\n" {
+		t.Fatalf("unexpected output of revoke: %q, %v", out, err)
+	}
+	if _, err := ci.LogLevels(ctx); err == nil {
+		t.Fatal("expect the revoked token denied")
+	}
+
+	// the admin token of the config is required
+	os.Setenv("WEB_TTY_ADMIN_TOKEN", "guess")
+	if _, err := runCommand(t, "sessions", "--server", ts.URL, "list"); err == nil {
+		t.Fatal("expect an error of a wrong admin token")
+	}
+}
+
+func TestConfigCommands(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	good, bad := filepath.Join(dir, "good.yaml"), filepath.Join(dir, "bad.yaml")
+	ioutil.WriteFile(good, []byte("port: 9090\nadmin-token: s3cret\n"), 0600)
+	ioutil.WriteFile(bad, []byte("no-such-option: 1\n"), 0600)
+
+	// validate
+	if out, err := runCommand(t, "config", "validate", good); err != nil || out != good+" is valid\n" {
+		t.Fatalf("unexpected output of validate: %q, %v", out, err)
+	}
+	if out, err := runCommand(t, "--config", good, "config", "validate"); err != nil || out != good+" is valid\n" {
+		t.Fatalf("unexpected output of validate --config: %q, %v", out, err)
+	}
+	if _, err := runCommand(t, "config", "validate", bad); err == nil ||
+		!strings.Contains(err.Error(), "unknown option no-such-option") {
+		t.Fatalf("expect an error of the unknown option, got %v", err)
+	}
+	if _, err := runCommand(t, "config", "validate"); err == nil {
+		t.Fatal("expect an error without the config file")
+	}
+
+	// dump
+	out, err := runCommand(t, "--addr", "127.0.0.1", "config", "dump", "--json", good)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var options []types.ConfigOption
+	if err := json.Unmarshal([]byte(out), &options); err != nil {
+		t.Fatalf("bad options %q: %s", out, err)
+	}
+	byName := make(map[string]types.ConfigOption)
+	for _, opt := range options {
+		byName[opt.Name] = opt
+	}
+	for name, want := range map[string]types.ConfigOption{
+		"port":        {Name: "port", Value: "9090", Source: types.SourceFile, Env: "WEB_TTY_PORT"},
+		"addr":        {Name: "addr", Value: "127.0.0.1", Source: types.SourceFlag, Env: "WEB_TTY_ADDRESS"},
+		"admin-token": {Name: "admin-token", Value: "<redacted>", Source: types.SourceFile, Env: "WEB_TTY_ADMIN_TOKEN"},
+	} {
+		if byName[name] != want {
+			t.Errorf("unexpected option %s: %+v", name, byName[name])
+		}
+	}
+	if strings.Contains(out, "s3cret") {
+		t.Fatalf("the secret is dumped: %s", out)
+	}
+	out, err = runCommand(t, "config", "dump", good)
+	if err != nil || !strings.HasPrefix(out, "NAME ") || !strings.Contains(out, "admin-token") || strings.Contains(out, "s3cret") {
+		t.Fatalf("unexpected table of the options: %q, %v", out, err)
+	}
+}
@@ -11,6 +11,27 @@ var SHELL_LIST = [...]string{
 type DockerConfig struct {
 	DockerHost string // default is /var/run/docker.sock
 	PsOptions  string
+	Keepalive  time.Duration // of the exec streams
+	// idle connections of the API calls kept to be reused
+	MaxIdleConns    int
+	IdleConnTimeout time.Duration
+	// copied from the backend config
+	ExecPolicy ExecPolicy
+}
+
+// ExecPolicy is the user of the exec'ed processes, the one requested
+// by the client is replaced by the one of the image or the global one
+type ExecPolicy struct {
+	User string
+	// users by the images, with or without the tags
+	ImageUsers map[string]string
+	// refuse to exec as root
+	NoRoot bool
+}
+
+// Enabled reports whether the policy changes anything
+func (p ExecPolicy) Enabled() bool {
+	return p.User != "" || len(p.ImageUsers) > 0 || p.NoRoot
 }
 
 type KubeConfig struct {
@@ -21,6 +42,10 @@ type GRPCConfig struct {
 	Servers []string
 	Auth    string
 	Proxy   string // http or socks5
+	// interval of the keepalive pings
+	Keepalive time.Duration
+	// max time to list the containers of a server
+	ListTimeout time.Duration
 }
 
 type BackendConfig struct {
@@ -28,6 +53,15 @@ type BackendConfig struct {
 	Docker DockerConfig
 	Kube   KubeConfig
 	GRPC   GRPCConfig
+	// keepalive of the backend connections, copied to the
+	// configs of the backends, 0 to disable
+	Keepalive time.Duration
+	// cache the containers and their details, 0 to disable
+	CacheTTL time.Duration
+	// create the backend in the background instead of before serving
+	Lazy bool
+	// the user of the exec'ed processes, only enforced by docker
+	ExecPolicy ExecPolicy
 }
 
 type ControlConfig struct {
@@ -36,36 +70,284 @@ type ControlConfig struct {
 	Start   bool
 	Stop    bool
 	Restart bool
+	// pause and unpause
+	Pause bool
+	// kill with a signal
+	Kill bool
+	// rename and label editing
+	Edit bool
+	// copy paths between the containers
+	Copy bool
+	// browse and download the files of the volumes
+	Browse bool
+	// commit to an image, not enabled by All
+	Commit bool
+	// launch debug containers, not enabled by All
+	Debug bool
+	// create and start containers with the admin token, not enabled by All
+	Create bool
+}
+
+// CookieConfig is the session cookie of the admin logins
+type CookieConfig struct {
+	Name string
+	// auto (by TLS), always or never
+	Secure   string
+	HTTPOnly bool
+	// strict, lax or none
+	SameSite string
+	// the sessions expire after it since their last use, 0 to disable them
+	MaxAge time.Duration
+	// the secrets of the cookies separated by commas, the first seals
+	// them and all of them open them, random if it's empty
+	Keys string
+}
+
+// AccessLogConfig is the log of the HTTP requests
+type AccessLogConfig struct {
+	// the file appended to, "-" for stdout, empty to disable it
+	Path string
+	// combined or json
+	Format string
+	// the ratio of the logged requests, the failed ones are always logged
+	SampleRatio float64
+	// the paths under the base path not logged, e.g. the health checks
+	SkipPaths []string
+}
+
+// BuildInfo is the version of the server, set by the linker flags
+type BuildInfo struct {
+	Version  string
+	CommitID string
+	BuildAt  string
 }
 
 type ServerConfig struct {
-	Address  string
-	Port     int
-	GrpcPort int
-	IdleTime time.Duration
-
-	Credential      string
-	EnableReconnect bool
-	ReconnectTime   int
-	MaxConnection   int
-	WSOrigin        string
-	Term            string `default:"xterm"`
-	ShowLocation    bool
-	EnableShare     bool
+	Address string
+	Port    int
+	// dual, ipv4 or ipv6, the IP family of the TCP listeners
+	IPFamily string `default:"dual"`
+	// the other listeners, [routes@]host:port or [routes@]unix:/path, the
+	// routes are all, public or admin
+	Listen []string
+	// the listener of the admin routes, the metrics and the profiles,
+	// host:port or unix:/path, the others don't serve them if it's set
+	AdminAddr string
+	// the mode and the owner (user[:group]) of the unix sockets
+	SocketMode  string `default:"0660"`
+	SocketOwner string
+	// the path the routes are under, e.g. /tty behind a proxy, empty for /
+	BasePath string
+	// the URL the users reach the server at, e.g. https://tty.example.com,
+	// of the URLs returned by the API, they are paths without it
+	ExternalURL string
+	GrpcPort    int
+	IdleTime    time.Duration
+
+	Credential string
+	// bearer token of the admin API, empty to disable it
+	AdminToken string
+	// the failed admin logins of a client IP after which it solves a
+	// challenge of the zero bits before each try, 0 to disable it
+	LoginChallengeAfter int
+	LoginChallengeBits  int
+	EnableReconnect     bool
+	ReconnectTime       int
+	// max terminal connections of the server and of a client IP, 0 for unlimited
+	MaxConnection      int
+	MaxConnectionPerIP int
+	// max time to get the init message of a new connection, 0 to wait forever
+	InitTimeout time.Duration
+	// regexp of the origins allowed to upgrade the websockets, empty
+	// for the same origin only, unless WSOriginAny allows any
+	WSOrigin    string
+	WSOriginAny bool
+	// keepalive of the browser connections, 0 to disable
+	WSPingInterval time.Duration
+	WSPingTimeout  time.Duration
+	// permessage-deflate of the websockets, messages shorter
+	// than the threshold are sent uncompressed
+	WSCompression          bool
+	WSCompressionLevel     int
+	WSCompressionThreshold int
+	// sizes of the websocket buffers and the reads of the relay
+	WSReadBufferSize  int
+	WSWriteBufferSize int
+	RelayBufferSize   int
+	// max bytes of an input message, the browsers split a larger paste
+	MaxInputFrame int
+	// max bytes of a request body, of the request headers and of a
+	// websocket message, 0 for unlimited (the default of Go for the headers)
+	MaxBodySize    int64
+	MaxHeaderBytes int
+	MaxWSMessage   int64
+	// the output is sent when the window passes or the size
+	// is reached, 0 to send it as soon as it's read
+	CoalesceWindow time.Duration
+	CoalesceSize   int
+	EmbedOrigin    string
+	// frame-ancestors of the CSP, empty for 'self' (or any with EmbedOrigin)
+	FrameAncestors string
+	// max-age of HSTS sent with TLS, 0 to not send it
+	HSTSMaxAge    time.Duration
+	Term          string `default:"xterm"`
+	ShowLocation  bool
+	EnableShare   bool
+	EnableGraphQL bool
+	RunTimeout    time.Duration
+	// max commands running at the same time of a batch run
+	BatchConcurrency int
+	// max time a provisioned session waits to be joined
+	ProvisionTTL time.Duration
+	// max time a forwarded URL is valid, 0 to disable port forwarding
+	ForwardTTL time.Duration
+	// the URL of a separate origin the forwards are served at, e.g.
+	// https://forward.example.com reaching this server, empty to serve
+	// them sandboxed on the origin of the UI
+	ForwardURL string
+	// the browsers receive and send the files of sz and rz
+	EnableZmodem bool
+	// the OSC 52 sequences of the terminals setting the clipboards of the
+	// browsers, off, on or ask, by the exec users overriding it, and the
+	// max bytes of a copied text
+	Clipboard        string `default:"off"`
+	ClipboardUsers   map[string]string
+	ClipboardMaxSize int
+	// toolbox image of the debug containers
+	DebugImage string
+	// max time to drain the sessions before exiting on SIGTERM,
+	// 0 to exit without draining
+	DrainTimeout time.Duration
+	// the storage of the state (the detached sessions of the replicas, the
+	// closed sessions and the exec history), a URL of memory, bolt, sqlite,
+	// postgres or redis, or the redis shared by the replicas, and the URL of
+	// this replica reachable by the other ones
+	Storage    string
+	RedisAddr  string
+	ReplicaURL string
+	// max time a session waits for its browser to reconnect, 0 to disable
+	ResumeTimeout time.Duration
+	// bytes of the recent output replayed to a reconnected browser
+	ReplayBuffer int
+	// regexp of the names of the env hidden in the container detail,
+	// they're shown with ?reveal=1 if RevealSecrets is true
+	MaskEnv       string
+	RevealSecrets bool
+	// checks of /readyz
+	ReadyChecks []string
+	// limits of the archives copied and downloaded from the containers,
+	// 0 for unlimited, the entries under the denied paths, of the denied
+	// sniffed MIME types and the device nodes are refused
+	MaxTransferSize    int64
+	MaxTransferEntries int
+	TransferDenyPaths  []string
+	TransferDenyMIME   []string
+	// the exec URLs are the tokens of the containers and the users valid
+	// for the TTL if it's positive instead of the raw IDs, signed by the
+	// secret shared by the replicas
+	SessionURLTTL    time.Duration
+	SessionURLSecret string
+	// CIDRs of the proxies in front whose X-Forwarded-For is believed
+	TrustedProxies []string
+	// serve HTTP/2 without TLS
+	H2C bool
+	// the certificate and key files served with TLS, reloaded once
+	// they're changed, TLSProfile is the ciphers and curves allowed,
+	// "default", "modern" or "fips" (the default in the FIPS mode)
+	TLSCert    string
+	TLSKey     string
+	TLSProfile string
+	// the certificates of the domains got from Let's Encrypt (or the
+	// directory of another ACME CA), cached in the dir, the HTTP-01
+	// challenges are served in the HTTP address, empty to only use TLS-ALPN
+	LetsEncrypt   bool
+	Domains       []string
+	ACMECacheDir  string
+	ACMEEmail     string
+	ACMEDirectory string
+	ACMEHTTPAddr  string
+	// listening address of /debug/pprof, empty to disable
+	PprofAddr string
+	// check the latest release of the URL once a day
+	UpdateCheck    bool
+	UpdateCheckURL string
+	// resources dir read on every request instead of the embedded assets
+	DevAssets string
+	// the files over the embedded assets, read once
+	StaticDir string
+	// text on top of the list and the login pages, and the message
+	// written to the terminals when the sessions start
+	Banner string
+	MOTD   string
+	// the zone of the times of the audit logs, the downloads and the
+	// pages: browser, server, UTC or an IANA name, and the clock of the
+	// terminal page
+	Timezone string `default:"browser"`
+	Clock    bool
+	// the window title template of the terminals, the ones of the docker
+	// and the kube containers instead if they're set
+	TitleFormat       string
+	TitleFormatDocker string
+	TitleFormatKube   string
+	// bytes of the followed logs read ahead of a slow client, the oldest
+	// lines are dropped beyond it, 0 to not drop
+	LogsBuffer int
+
+	// the build of the server and its whole config with the secrets
+	// redacted, of the diagnostics bundle, and the effective options of
+	// the flags (types.ConfigOption), set by main
+	Build     BuildInfo
+	Redacted  interface{} `json:"-"`
+	Effective interface{} `json:"-"`
 
 	// audit
 	EnableAudit bool
 	AuditLogDir string `default:"log"`
+	// the dir of the journal of the server events, empty to disable it,
+	// the oldest events are dropped beyond the max bytes
+	JournalDir     string
+	JournalMaxSize int64 `default:"67108864"`
 
-	Control ControlConfig
+	Control   ControlConfig
+	Cookie    CookieConfig
+	AccessLog AccessLogConfig
 
 	// EnableBasicAuth bool `default:"false"`
 	// Once            bool `default:"false"`
 	// TitleVariables map[string]interface{}
 }
 
+// LogConfig is the format and the levels of the logs
+type LogConfig struct {
+	// text or json
+	Format string
+	Level  string
+	// the levels of the modules, e.g. route=debug,docker=warn
+	Modules string
+}
+
+// TraceConfig is the OTLP collector the spans are exported to
+type TraceConfig struct {
+	// e.g. http://otel-collector:4318, empty to disable
+	Endpoint string
+	Service  string
+	// the ratio of the sampled traces started by the server
+	SampleRatio float64
+}
+
+// ReportConfig is where the recovered panics are reported, empty to not
+// report them
+type ReportConfig struct {
+	SentryDSN string
+	// posted the reports as JSON
+	Webhook string
+}
+
 type Config struct {
 	Debug   bool
+	Log     LogConfig
+	Trace   TraceConfig
+	Report  ReportConfig
 	Backend BackendConfig
 	Server  ServerConfig
 }
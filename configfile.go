@@ -0,0 +1,197 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/urfave/cli.v2"
+	"gopkg.in/yaml.v2"
+
+	"github.com/wrfly/container-web-tty/config"
+	"github.com/wrfly/container-web-tty/types"
+)
+
+// loadConfigFile sets the options of the YAML (or JSON) file of the path,
+// the keys are the names of the flags, their parts may be nested (e.g.
+// `tls: {cert: a.pem}` is `tls-cert: a.pem`), and the lists are joined by
+// commas. The flags set by the args or the env are kept, so the precedence
+// is flags > env > file > defaults. It returns the names of the flags set
+// by the file.
+func loadConfigFile(c *cli.Context, path string) (map[string]bool, error) {
+	if path == "" {
+		return nil, nil
+	}
+	bs, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read the config file error: %s", err)
+	}
+	var doc map[interface{}]interface{}
+	if err := yaml.Unmarshal(bs, &doc); err != nil {
+		return nil, fmt.Errorf("parse the config file %s error: %s", path, err)
+	}
+	values := make(map[string]interface{})
+	flattenConfig("", doc, values)
+
+	flags := make(map[string]cli.Flag)
+	for _, f := range c.App.Flags {
+		for _, name := range f.Names() {
+			flags[name] = f
+		}
+	}
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	seen := make(map[string]bool)
+	fromFile := make(map[string]bool)
+	for _, key := range keys {
+		f, ok := flags[key]
+		if !ok || key == "config" || key == "help" {
+			return nil, fmt.Errorf("unknown option %s in the config file %s", key, path)
+		}
+		names := f.Names()
+		if seen[names[0]] {
+			return nil, fmt.Errorf("option %s is set twice in the config file %s", names[0], path)
+		}
+		seen[names[0]] = true
+		value := values[key]
+		if value == nil || isSet(c, names) {
+			continue
+		}
+		if list, ok := value.([]interface{}); ok {
+			items := make([]string, len(list))
+			for i, item := range list {
+				items[i] = fmt.Sprint(item)
+			}
+			value = strings.Join(items, ",")
+		}
+		if err := c.Set(names[0], fmt.Sprint(value)); err != nil {
+			return nil, fmt.Errorf("bad %s %q in the config file %s: %s", key, fmt.Sprint(value), path, err)
+		}
+		fromFile[names[0]] = true
+	}
+	return fromFile, nil
+}
+
+// effectiveOptions are the options of all the flags by their names, their
+// values after the defaults, the file, the env and the args, and where they
+// are from, the secrets are redacted
+func effectiveOptions(c *cli.Context, conf *config.Config, fromFile map[string]bool) []types.ConfigOption {
+	visited := make(map[string]bool)
+	for _, name := range c.LocalFlagNames() {
+		visited[name] = true
+	}
+	secretValues := make(map[string]string)
+	for _, s := range secrets(conf) {
+		secretValues[s.name] = *s.dst
+	}
+
+	options := make([]types.ConfigOption, 0, len(c.App.Flags))
+	for _, f := range c.App.Flags {
+		names := f.Names()
+		name := names[0]
+		if name == "help" {
+			continue
+		}
+		opt := types.ConfigOption{Name: name, Value: c.String(name), Source: types.SourceDefault}
+		envs := flagEnvVars(f)
+		if len(envs) > 0 {
+			opt.Env = envs[0]
+		}
+		switch {
+		case fromFile[name]:
+			opt.Source = types.SourceFile
+		case anyVisited(visited, names):
+			opt.Source = types.SourceFlag
+		case isSet(c, names):
+			opt.Source = types.SourceEnv
+		}
+		if value, ok := secretValues[name]; ok {
+			if c.String(name+"-file") != "" {
+				opt.Source = types.SourceSecretFile
+			}
+			opt.Value = value
+			if value != "" {
+				opt.Value = "<redacted>"
+			}
+		}
+		options = append(options, opt)
+	}
+	return options
+}
+
+// flagEnvVars are the env names of the flag
+func flagEnvVars(f cli.Flag) []string {
+	v := reflect.ValueOf(f)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if envs := v.FieldByName("EnvVars"); envs.IsValid() {
+		if names, ok := envs.Interface().([]string); ok {
+			return names
+		}
+	}
+	return nil
+}
+
+func anyVisited(visited map[string]bool, names []string) bool {
+	for _, name := range names {
+		if visited[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// printOptions prints the options as a table, or as JSON
+func printOptions(w io.Writer, options []types.ConfigOption, asJSON bool) error {
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(options)
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tVALUE\tSOURCE")
+	for _, opt := range options {
+		source := opt.Source
+		if source == types.SourceEnv {
+			source += " " + opt.Env
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", opt.Name, opt.Value, source)
+	}
+	return tw.Flush()
+}
+
+// flattenConfig joins the keys of the nested maps by dashes
+func flattenConfig(prefix string, v interface{}, values map[string]interface{}) {
+	m, ok := v.(map[interface{}]interface{})
+	if !ok {
+		values[prefix] = v
+		return
+	}
+	for k, sub := range m {
+		key := fmt.Sprint(k)
+		if prefix != "" {
+			key = prefix + "-" + key
+		}
+		flattenConfig(key, sub, values)
+	}
+}
+
+// isSet tells if any name of the flag is set by the args or the env
+func isSet(c *cli.Context, names []string) bool {
+	for _, name := range names {
+		if c.IsSet(name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/urfave/cli.v2"
+
+	"github.com/wrfly/container-web-tty/config"
+	"github.com/wrfly/container-web-tty/types"
+)
+
+// setupApp runs setup of the args, the env and the config file of the
+// content, the effective options are returned by their names
+func setupApp(t *testing.T, args []string, env map[string]string, file string) (*config.Config, map[string]types.ConfigOption) {
+	path := ""
+	if file != "" {
+		dir, err := ioutil.TempDir("", "config")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		path = filepath.Join(dir, "config.yaml")
+		if err := ioutil.WriteFile(path, []byte(file), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for k, v := range env {
+		os.Setenv(k, v)
+		defer os.Unsetenv(k)
+	}
+
+	conf := config.New()
+	app := &cli.App{
+		Name:     "container-web-tty",
+		Flags:    appFlags(conf),
+		HideHelp: true,
+		Action: func(c *cli.Context) error {
+			return setup(c, conf, path)
+		},
+	}
+	if err := app.Run(append([]string{"container-web-tty"}, args...)); err != nil {
+		t.Fatal(err)
+	}
+	options := make(map[string]types.ConfigOption)
+	effective, _ := conf.Server.Effective.([]types.ConfigOption)
+	for _, opt := range effective {
+		options[opt.Name] = opt
+	}
+	return conf, options
+}
+
+func TestConfigPrecedence(t *testing.T) {
+	for _, c := range []struct {
+		name   string
+		args   []string
+		env    map[string]string
+		file   string
+		want   string
+		source string
+	}{
+		{"default", nil, nil, "", "0.0.0.0", types.SourceDefault},
+		{"file", nil, nil, "addr: 10.0.0.1", "10.0.0.1", types.SourceFile},
+		{"env over file", nil, map[string]string{"WEB_TTY_ADDRESS": "10.0.0.2"},
+			"addr: 10.0.0.1", "10.0.0.2", types.SourceEnv},
+		{"flag over env", []string{"--addr", "10.0.0.3"}, map[string]string{"WEB_TTY_ADDRESS": "10.0.0.2"},
+			"addr: 10.0.0.1", "10.0.0.3", types.SourceFlag},
+		{"flag over file", []string{"--addr", "10.0.0.3"}, nil, "addr: 10.0.0.1", "10.0.0.3", types.SourceFlag},
+	} {
+		conf, options := setupApp(t, c.args, c.env, c.file)
+		if conf.Server.Address != c.want {
+			t.Errorf("%s: unexpected addr %q", c.name, conf.Server.Address)
+		}
+		if opt := options["addr"]; opt.Value != c.want || opt.Source != c.source || opt.Env != "WEB_TTY_ADDRESS" {
+			t.Errorf("%s: unexpected option %+v", c.name, opt)
+		}
+	}
+
+	// the nested keys and the lists of the file
+	conf, options := setupApp(t, nil, nil, "tls:\n  cert: a.pem\ntrusted-proxies: [10.0.0.1, 10.0.0.2]\n")
+	if conf.Server.TLSCert != "a.pem" || options["tls-cert"].Source != types.SourceFile {
+		t.Errorf("unexpected tls-cert %q, %+v", conf.Server.TLSCert, options["tls-cert"])
+	}
+	if len(conf.Server.TrustedProxies) != 2 || options["trusted-proxies"].Value != "10.0.0.1,10.0.0.2" {
+		t.Errorf("unexpected trusted-proxies %v, %+v", conf.Server.TrustedProxies, options["trusted-proxies"])
+	}
+}
+
+func TestEffectiveOptionsRedacted(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	secretFile := filepath.Join(dir, "token")
+	if err := ioutil.WriteFile(secretFile, []byte("s3cret-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		name   string
+		args   []string
+		env    map[string]string
+		file   string
+		want   string
+		value  string
+		source string
+	}{
+		{"unset", nil, nil, "", "", "", types.SourceDefault},
+		{"file", nil, nil, "admin-token: s3cret", "s3cret", "<redacted>", types.SourceFile},
+		{"env", nil, map[string]string{"WEB_TTY_ADMIN_TOKEN": "s3cret-env"}, "", "s3cret-env", "<redacted>", types.SourceEnv},
+		{"flag", []string{"--admin-token", "s3cret-flag"}, nil, "", "s3cret-flag", "<redacted>", types.SourceFlag},
+		{"secret file", []string{"--admin-token-file", secretFile}, nil, "", "s3cret-file", "<redacted>", types.SourceSecretFile},
+	} {
+		conf, options := setupApp(t, c.args, c.env, c.file)
+		if conf.Server.AdminToken != c.want {
+			t.Errorf("%s: unexpected admin token %q", c.name, conf.Server.AdminToken)
+		}
+		if opt := options["admin-token"]; opt.Value != c.value || opt.Source != c.source {
+			t.Errorf("%s: unexpected option %+v", c.name, opt)
+		}
+	}
+
+	// every secret is redacted
+	args := []string{}
+	for _, s := range secrets(config.New()) {
+		args = append(args, "--"+s.name, "s3cret")
+	}
+	_, options := setupApp(t, args, nil, "")
+	for _, s := range secrets(config.New()) {
+		if opt := options[s.name]; opt.Value != "<redacted>" {
+			t.Errorf("unexpected option of the secret %s: %+v", s.name, opt)
+		}
+	}
+}
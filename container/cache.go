@@ -0,0 +1,207 @@
+package container
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/wrfly/container-web-tty/event"
+	"github.com/wrfly/container-web-tty/logging"
+	"github.com/wrfly/container-web-tty/types"
+)
+
+var log = logging.Module("container")
+
+// Refresher is a Cli caching the containers, Refresh drops the cache
+// so the next List and Inspect read the backend
+type Refresher interface {
+	Refresh()
+}
+
+// refreshTimeout is the max time of a refresh in the background
+const refreshTimeout = 30 * time.Second
+
+type cachedList struct {
+	containers []types.Container
+	at         time.Time
+	refreshing bool
+}
+
+type cachedDetail struct {
+	detail     types.ContainerDetail
+	at         time.Time
+	refreshing bool
+}
+
+// cachedCli caches the List and Inspect of the backend for the ttl, the
+// expired ones are returned while they are refreshed in the background
+// (stale-while-revalidate). The cache is dropped by the container events
+// and the changes made through it.
+type cachedCli struct {
+	Cli
+	ttl time.Duration
+
+	m       sync.Mutex
+	list    *cachedList
+	details map[string]*cachedDetail
+}
+
+// NewCachedCli caches the List and Inspect of the cli for the ttl,
+// the events of the hub drop the cache of the containers
+func NewCachedCli(cli Cli, ttl time.Duration, events *event.Hub) Cli {
+	c := &cachedCli{
+		Cli:     cli,
+		ttl:     ttl,
+		details: make(map[string]*cachedDetail),
+	}
+	sub, _ := events.Subscribe(100)
+	go func() {
+		for e := range sub {
+			c.drop(e.ID)
+		}
+	}()
+	return c
+}
+
+// drop drops the list and the detail of the container
+func (c *cachedCli) drop(containerID string) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.list = nil
+	delete(c.details, containerID)
+}
+
+func (c *cachedCli) Refresh() {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.list = nil
+	c.details = make(map[string]*cachedDetail)
+}
+
+func (c *cachedCli) List(ctx context.Context) []types.Container {
+	c.m.Lock()
+	list := c.list
+	if list != nil && time.Since(list.at) > c.ttl && !list.refreshing {
+		list.refreshing = true
+		go c.refreshList(list)
+	}
+	c.m.Unlock()
+	if list != nil {
+		return list.containers
+	}
+
+	containers := c.Cli.List(ctx)
+	c.m.Lock()
+	c.list = &cachedList{containers: containers, at: time.Now()}
+	c.m.Unlock()
+	return containers
+}
+
+func (c *cachedCli) refreshList(cached *cachedList) {
+	ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+	defer cancel()
+	start := time.Now()
+	containers := c.Cli.List(ctx)
+	log.Debugf("refreshed %d containers in %s", len(containers), time.Since(start))
+
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.list == cached {
+		c.list = &cachedList{containers: containers, at: time.Now()}
+	}
+}
+
+func (c *cachedCli) Inspect(ctx context.Context, containerID string) (types.ContainerDetail, error) {
+	c.m.Lock()
+	cached := c.details[containerID]
+	if cached != nil && time.Since(cached.at) > c.ttl && !cached.refreshing {
+		cached.refreshing = true
+		go c.refreshDetail(containerID, cached)
+	}
+	c.m.Unlock()
+	if cached != nil {
+		return cached.detail, nil
+	}
+
+	detail, err := c.Cli.Inspect(ctx, containerID)
+	if err != nil {
+		return detail, err
+	}
+	c.m.Lock()
+	c.details[containerID] = &cachedDetail{detail: detail, at: time.Now()}
+	c.m.Unlock()
+	return detail, nil
+}
+
+func (c *cachedCli) refreshDetail(containerID string, cached *cachedDetail) {
+	ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+	defer cancel()
+	detail, err := c.Cli.Inspect(ctx, containerID)
+
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.details[containerID] != cached {
+		// dropped or refilled during the refresh
+		return
+	}
+	if err != nil {
+		log.Errorf("refresh detail of container %s error: %s", containerID, err)
+		delete(c.details, containerID)
+		return
+	}
+	c.details[containerID] = &cachedDetail{detail: detail, at: time.Now()}
+}
+
+// the changes drop the cache, some of them are not reported by the events
+
+func (c *cachedCli) Start(ctx context.Context, containerID string) error {
+	defer c.drop(containerID)
+	return c.Cli.Start(ctx, containerID)
+}
+
+func (c *cachedCli) Stop(ctx context.Context, containerID string) error {
+	defer c.drop(containerID)
+	return c.Cli.Stop(ctx, containerID)
+}
+
+func (c *cachedCli) Restart(ctx context.Context, containerID string) error {
+	defer c.drop(containerID)
+	return c.Cli.Restart(ctx, containerID)
+}
+
+func (c *cachedCli) Pause(ctx context.Context, containerID string) error {
+	defer c.drop(containerID)
+	return c.Cli.Pause(ctx, containerID)
+}
+
+func (c *cachedCli) Unpause(ctx context.Context, containerID string) error {
+	defer c.drop(containerID)
+	return c.Cli.Unpause(ctx, containerID)
+}
+
+func (c *cachedCli) Kill(ctx context.Context, containerID, signal string) error {
+	defer c.drop(containerID)
+	return c.Cli.Kill(ctx, containerID, signal)
+}
+
+func (c *cachedCli) Rename(ctx context.Context, containerID, name string) error {
+	defer c.drop(containerID)
+	return c.Cli.Rename(ctx, containerID, name)
+}
+
+func (c *cachedCli) UpdateLabels(ctx context.Context, containerID string, update types.LabelsUpdate) error {
+	defer c.drop(containerID)
+	return c.Cli.UpdateLabels(ctx, containerID, update)
+}
+
+func (c *cachedCli) Create(ctx context.Context, opts types.CreateOptions) (string, error) {
+	defer c.Refresh()
+	return c.Cli.Create(ctx, opts)
+}
+
+func (c *cachedCli) Prune(ctx context.Context, kind string, dryRun bool) (types.PruneReport, error) {
+	if !dryRun {
+		defer c.Refresh()
+	}
+	return c.Cli.Prune(ctx, kind, dryRun)
+}
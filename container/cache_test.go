@@ -0,0 +1,68 @@
+package container
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/wrfly/container-web-tty/event"
+	"github.com/wrfly/container-web-tty/types"
+)
+
+// slowCli counts the lists from the backend
+type slowCli struct {
+	Cli
+	lists int32
+}
+
+func (s *slowCli) List(ctx context.Context) []types.Container {
+	n := atomic.AddInt32(&s.lists, 1)
+	return make([]types.Container, n)
+}
+
+func (s *slowCli) Start(ctx context.Context, containerID string) error {
+	return nil
+}
+
+func TestCachedCli(t *testing.T) {
+	backend := &slowCli{}
+	events := event.NewHub()
+	cli := NewCachedCli(backend, 50*time.Millisecond, events)
+	ctx := context.Background()
+
+	if n := len(cli.List(ctx)); n != 1 {
+		t.Fatalf("expect the first list, got %d", n)
+	}
+	if n := len(cli.List(ctx)); n != 1 {
+		t.Fatalf("expect the cached list, got %d", n)
+	}
+
+	// the expired list is returned while it's refreshed
+	time.Sleep(60 * time.Millisecond)
+	if n := len(cli.List(ctx)); n != 1 {
+		t.Fatalf("expect the stale list, got %d", n)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if n := len(cli.List(ctx)); n != 2 {
+		t.Fatalf("expect the refreshed list, got %d", n)
+	}
+
+	cli.Start(ctx, "abc")
+	if n := len(cli.List(ctx)); n != 3 {
+		t.Fatalf("expect a new list after the change, got %d", n)
+	}
+	cli.(Refresher).Refresh()
+	if n := len(cli.List(ctx)); n != 4 {
+		t.Fatalf("expect a new list after the refresh, got %d", n)
+	}
+
+	events.Publish(types.Event{Action: "die", ID: "abc"})
+	for i := 0; i < 100; i++ {
+		if len(cli.List(ctx)) == 5 {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("expect a new list after the event")
+}
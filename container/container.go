@@ -9,6 +9,8 @@ import (
 	"github.com/wrfly/container-web-tty/container/docker"
 	"github.com/wrfly/container-web-tty/container/grpc"
 	"github.com/wrfly/container-web-tty/container/kube"
+	"github.com/wrfly/container-web-tty/event"
+	"github.com/wrfly/container-web-tty/tracing"
 	"github.com/wrfly/container-web-tty/types"
 )
 
@@ -21,25 +23,73 @@ type Cli interface {
 	Start(ctx context.Context, containerID string) error
 	Stop(ctx context.Context, containerID string) error
 	Restart(ctx context.Context, containerID string) error
+	Pause(ctx context.Context, containerID string) error
+	Unpause(ctx context.Context, containerID string) error
+	// send the signal (name or number) to the main process
+	Kill(ctx context.Context, containerID, signal string) error
+	Rename(ctx context.Context, containerID, name string) error
+	// Commit returns the ID of the image
+	Commit(ctx context.Context, containerID string, opts types.CommitOptions) (string, error)
+	// Create creates and starts a container, returns the ID of it
+	Create(ctx context.Context, opts types.CreateOptions) (string, error)
+	// Debug runs a throwaway container sharing the PID and network
+	// namespaces of the container, returns the ID of the debug container
+	Debug(ctx context.Context, containerID string, opts types.DebugOptions) (string, error)
+	Top(ctx context.Context, containerID string) (types.Processes, error)
+	Diff(ctx context.Context, containerID string) ([]types.Change, error)
+	Inspect(ctx context.Context, containerID string) (types.ContainerDetail, error)
+	// CopyFrom returns a tar archive of the path in the container
+	CopyFrom(ctx context.Context, containerID, path string) (io.ReadCloser, error)
+	// CopyTo extracts the tar archive into the dir of the container
+	CopyTo(ctx context.Context, containerID, dir string, content io.Reader) error
+	UpdateLabels(ctx context.Context, containerID string, update types.LabelsUpdate) error
+	// Prune removes the unused resources of the kind (types.PruneKinds),
+	// nothing is removed but reported if dryRun is true
+	Prune(ctx context.Context, kind string, dryRun bool) (types.PruneReport, error)
 	// exec into container
 	Exec(ctx context.Context, container types.Container) (types.TTY, error)
+	// run a one-shot command (container.Exec.Cmd) without a tty
+	Run(ctx context.Context, container types.Container) (types.RunResult, error)
+	// check the connectivity of the backend
+	Ping(ctx context.Context) error
+	// the versions of the backend
+	Version(ctx context.Context) ([]types.BackendVersion, error)
 	// close the connections
 	Close() error
 	// read logs
 	Logs(ctx context.Context, opts types.LogOptions) (io.ReadCloser, error)
+	// stream the resource usage until ctx is done, the channel is closed
+	// when the stream ends
+	Stats(ctx context.Context, containerID string) (<-chan types.Stats, error)
 }
 
-// NewCliBackend returns the client backend
-func NewCliBackend(conf config.BackendConfig) (cli Cli, err error) {
-	switch conf.Type {
-	case "docker":
-		cli, err = docker.NewCli(conf.Docker)
-	case "kube":
-		cli, err = kube.NewCli(conf.Kube)
-	case "grpc":
-		cli, err = grpc.NewCli(conf.GRPC)
-	default:
-		err = fmt.Errorf("unknown backend type %s", conf.Type)
+// NewCliBackend returns the client backend,
+// container events of the backend are published to the hub
+func NewCliBackend(conf config.BackendConfig, events *event.Hub) (cli Cli, err error) {
+	backends := map[string]func() (Cli, error){
+		"docker": func() (Cli, error) { return docker.NewCli(conf.Docker, events) },
+		"kube":   func() (Cli, error) { return kube.NewCli(conf.Kube, events) },
+		"grpc":   func() (Cli, error) { return grpc.NewCli(conf.GRPC, events) },
+	}
+	create, ok := backends[conf.Type]
+	if !ok {
+		return nil, fmt.Errorf("unknown backend type %s", conf.Type)
+	}
+	if conf.ExecPolicy.Enabled() && conf.Type != "docker" {
+		// kubectl exec can't choose the user, and the upstream
+		// servers of grpc enforce their own policies
+		return nil, fmt.Errorf("the exec user policy is not supported by the %s backend", conf.Type)
+	}
+	if conf.Lazy {
+		cli = NewLazyCli(create)
+	} else if cli, err = create(); err != nil {
+		return nil, err
+	}
+	if tracing.Enabled() {
+		cli = NewTracedCli(cli)
+	}
+	if conf.CacheTTL > 0 {
+		cli = NewCachedCli(cli, conf.CacheTTL, events)
 	}
 
 	return
@@ -2,29 +2,45 @@ package docker
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"net"
 	"strconv"
 	"strings"
 	"time"
 
 	apiTypes "github.com/docker/docker/api/types"
+	dockerContainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/client"
-	"github.com/sirupsen/logrus"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/docker/go-connections/nat"
 
 	"github.com/wrfly/container-web-tty/config"
+	"github.com/wrfly/container-web-tty/event"
+	"github.com/wrfly/container-web-tty/logging"
+	"github.com/wrfly/container-web-tty/tracing"
 	"github.com/wrfly/container-web-tty/types"
+	"github.com/wrfly/container-web-tty/util"
 )
 
+var log = logging.Module("docker")
+
 type DockerCli struct {
 	cli         *client.Client
 	containers  *types.Containers
 	listOptions apiTypes.ContainerListOptions
 	lastList    time.Time
+	events      *event.Hub
+	keepalive   time.Duration
+	policy      execPolicy
 }
 
-func NewCli(conf config.DockerConfig) (*DockerCli, error) {
+// NewCli returns the DockerCli, container events are published to the hub
+func NewCli(conf config.DockerConfig, events *event.Hub) (*DockerCli, error) {
 	host := conf.DockerHost
 	if host[:1] == "/" {
 		host = "unix://" + host
@@ -32,11 +48,15 @@ func NewCli(conf config.DockerConfig) (*DockerCli, error) {
 		host = "tcp://" + host
 	}
 	version := "v1.24"
-	logrus.Infof("Docker connecting to %s", host)
+	log.Infof("Docker connecting to %s", host)
 	UA := map[string]string{"User-Agent": "engine-api-cli-1.0"}
-	cli, err := client.NewClient(host, version, nil, UA)
+	httpCli, err := newHTTPClient(host, conf)
+	if err != nil {
+		return nil, fmt.Errorf("create http client error: %s", err)
+	}
+	cli, err := client.NewClient(host, version, httpCli, UA)
 	if err != nil {
-		logrus.Errorf("create new docker client error: %s", err)
+		log.Errorf("create new docker client error: %s", err)
 		return nil, err
 	}
 
@@ -47,9 +67,9 @@ func NewCli(conf config.DockerConfig) (*DockerCli, error) {
 	if err != nil {
 		return nil, err
 	}
-	cli, err = client.NewClient(host, v.APIVersion, nil, UA)
+	cli, err = client.NewClient(host, v.APIVersion, httpCli, UA)
 	if err != nil {
-		logrus.Errorf("create new docker client error: %s", err)
+		log.Errorf("create new docker client error: %s", err)
 		return nil, err
 	}
 
@@ -57,19 +77,22 @@ func NewCli(conf config.DockerConfig) (*DockerCli, error) {
 	if err != nil {
 		return nil, fmt.Errorf("build ps options error: %s", err)
 	}
-	logrus.Debugf("list options: %+v", listOptions)
+	log.Debugf("list options: %+v", listOptions)
 
 	ping, err := cli.Ping(ctx)
 	if err != nil {
 		return nil, err
 	}
-	logrus.Infof("New docker client: API [%s]", ping.APIVersion)
+	log.Infof("New docker client: API [%s]", ping.APIVersion)
 	dockerCli := &DockerCli{
 		cli:         cli,
 		containers:  &types.Containers{},
 		listOptions: listOptions,
+		events:      events,
+		keepalive:   conf.Keepalive,
+		policy:      execPolicy(conf.ExecPolicy),
 	}
-	logrus.Infof("Warm up containers info...")
+	log.Infof("Warm up containers info...")
 
 	// when docker restarted, should restart the program as well
 	// since the socket file is gone (as the restart of docker daemon)
@@ -80,7 +103,7 @@ func NewCli(conf config.DockerConfig) (*DockerCli, error) {
 		cancel()
 
 		dockerCli.watchEvents() // will block here
-		logrus.Fatal("lost connection to docker daemon")
+		log.Fatal("lost connection to docker daemon")
 	}()
 
 	return dockerCli, nil
@@ -126,7 +149,11 @@ func (docker *DockerCli) watchEvents() {
 			if event.Type != "container" {
 				continue
 			}
-			logrus.Debugf("container event: %+v", event)
+			log.Debugf("container event: %+v", event)
+			// skip exec_create, exec_start...
+			if !strings.HasPrefix(event.Action, "exec_") {
+				docker.events.Publish(convertEvent(event))
+			}
 			switch event.Action {
 			case "start", "destroy":
 				ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
@@ -136,12 +163,35 @@ func (docker *DockerCli) watchEvents() {
 		}
 	}()
 
-	logrus.Errorf("docker cli watch events error: %s", <-errChan)
+	log.Errorf("docker cli watch events error: %s", <-errChan)
+}
+
+// convertEvent splits the status of "health_status: healthy" into
+// the detail, the exit code of a dead container as well
+func convertEvent(msg events.Message) types.Event {
+	e := types.Event{
+		Action: msg.Action,
+		ID:     msg.Actor.ID,
+		Name:   msg.Actor.Attributes["name"],
+		Time:   time.Unix(0, msg.TimeNano),
+	}
+	if i := strings.Index(e.Action, ":"); i != -1 {
+		e.Action, e.Detail = e.Action[:i], strings.TrimSpace(e.Action[i+1:])
+	}
+	switch e.Action {
+	case "die":
+		if code := msg.Actor.Attributes["exitCode"]; code != "" {
+			e.Detail = "exit code " + code
+		}
+	case "kill":
+		e.Detail = msg.Actor.Attributes["signal"]
+	}
+	return e
 }
 
 func (docker *DockerCli) GetInfo(ctx context.Context, cid string) types.Container {
 	if docker.containers.Len() == 0 {
-		logrus.Debugf("zero containers, get cid %s", cid)
+		log.Debugf("zero containers, get cid %s", cid)
 		docker.List(ctx)
 	}
 
@@ -152,14 +202,14 @@ func (docker *DockerCli) GetInfo(ctx context.Context, cid string) types.Containe
 			container.Shell = shell
 			docker.containers.SetShell(cid, shell)
 		}
-		logrus.Debugf("found valid container: %s (%s)", container.ID, container.Shell)
+		log.Debugf("found valid container: %s (%s)", container.ID, container.Shell)
 		return container
 	}
 
 	// didn't get this container, this is rarelly happens
 	cjson, err := docker.cli.ContainerInspect(ctx, cid)
 	if err != nil {
-		logrus.Errorf("inspect container %s error: %s", cid, err)
+		log.Errorf("inspect container %s error: %s", cid, err)
 		return types.Container{}
 	}
 
@@ -189,6 +239,7 @@ func (docker *DockerCli) convertCjsonToContainre(cjson apiTypes.ContainerJSON) t
 		Status:  cjson.State.Status,
 		State:   cjson.State.Status,
 		Shell:   shell,
+		Labels:  cjson.Config.Labels,
 	}
 
 	return c
@@ -200,10 +251,10 @@ func (docker *DockerCli) listContainers(ctx context.Context, force bool) []types
 	}
 
 	start := time.Now()
-	logrus.Debug("list conatiners")
+	log.Debug("list conatiners")
 	cs, err := docker.cli.ContainerList(ctx, docker.listOptions)
 	if err != nil {
-		logrus.Errorf("list containers eror: %s", err)
+		log.Errorf("list containers eror: %s", err)
 		return nil
 	}
 
@@ -228,13 +279,14 @@ func (docker *DockerCli) listContainers(ctx context.Context, force bool) []types
 			Status:  container.Status,
 			State:   container.State,
 			Shell:   shell,
+			Labels:  container.Labels,
 		}
 	}
 
 	docker.containers.Set(containers)
 
 	docker.lastList = time.Now()
-	logrus.Debugf("list %d containers, use %s", len(containers), time.Now().Sub(start))
+	log.Debugf("list %d containers, use %s", len(containers), time.Now().Sub(start))
 	return containers
 }
 
@@ -253,7 +305,7 @@ func (docker *DockerCli) exist(ctx context.Context, cid, path string) bool {
 func (docker *DockerCli) getShell(ctx context.Context, cid string) string {
 	for _, sh := range config.SHELL_LIST {
 		if docker.exist(ctx, cid, sh) {
-			logrus.Debugf("container [%s] use [%s]", cid, sh)
+			log.Debugf("container [%s] use [%s]", cid, sh)
 			return sh
 		}
 	}
@@ -276,6 +328,273 @@ func (docker *DockerCli) Restart(ctx context.Context, cid string) error {
 	return docker.cli.ContainerRestart(ctx, cid, nil)
 }
 
+func (docker *DockerCli) Pause(ctx context.Context, cid string) error {
+	return docker.cli.ContainerPause(ctx, cid)
+}
+
+func (docker *DockerCli) Unpause(ctx context.Context, cid string) error {
+	return docker.cli.ContainerUnpause(ctx, cid)
+}
+
+func (docker *DockerCli) Kill(ctx context.Context, cid, signal string) error {
+	return docker.cli.ContainerKill(ctx, cid, signal)
+}
+
+func (docker *DockerCli) Top(ctx context.Context, cid string) (types.Processes, error) {
+	top, err := docker.cli.ContainerTop(ctx, cid, nil)
+	if err != nil {
+		return types.Processes{}, err
+	}
+	return types.Processes{Titles: top.Titles, Processes: top.Processes}, nil
+}
+
+func (docker *DockerCli) Inspect(ctx context.Context, cid string) (types.ContainerDetail, error) {
+	cjson, err := docker.cli.ContainerInspect(ctx, cid)
+	if err != nil {
+		return types.ContainerDetail{}, err
+	}
+
+	detail := types.ContainerDetail{
+		Env:    []types.EnvVar{},
+		Mounts: []types.Mount{},
+	}
+	if cjson.Config != nil {
+		for _, env := range cjson.Config.Env {
+			kv := strings.SplitN(env, "=", 2)
+			e := types.EnvVar{Name: kv[0]}
+			if len(kv) == 2 {
+				e.Value = kv[1]
+			}
+			detail.Env = append(detail.Env, e)
+		}
+	}
+	for _, m := range cjson.Mounts {
+		detail.Mounts = append(detail.Mounts, types.Mount{
+			Type:        string(m.Type),
+			Source:      m.Source,
+			Destination: m.Destination,
+			ReadOnly:    !m.RW,
+		})
+	}
+	detail.Health = convertHealth(cjson)
+	return detail, nil
+}
+
+// convertHealth returns the healthcheck of the container, nil if it
+// has no healthcheck or it's disabled
+func convertHealth(cjson apiTypes.ContainerJSON) *types.Health {
+	if cjson.Config == nil || cjson.Config.Healthcheck == nil {
+		return nil
+	}
+	test := cjson.Config.Healthcheck.Test
+	if len(test) == 0 || test[0] == "NONE" {
+		return nil
+	}
+	health := &types.Health{Probes: []types.HealthProbe{}}
+	switch test[0] {
+	case "CMD":
+		health.Cmd = util.ShellJoin(test[1:])
+	case "CMD-SHELL":
+		health.Cmd = strings.Join(test[1:], " ")
+	}
+	if cjson.State == nil || cjson.State.Health == nil {
+		return health
+	}
+	health.Status = cjson.State.Health.Status
+	health.FailingStreak = cjson.State.Health.FailingStreak
+	for _, probe := range cjson.State.Health.Log {
+		if probe == nil {
+			continue
+		}
+		health.Probes = append(health.Probes, types.HealthProbe{
+			Start:    probe.Start,
+			End:      probe.End,
+			ExitCode: probe.ExitCode,
+			Output:   probe.Output,
+		})
+	}
+	return health
+}
+
+func (docker *DockerCli) CopyFrom(ctx context.Context, cid, path string) (io.ReadCloser, error) {
+	rc, _, err := docker.cli.CopyFromContainer(ctx, cid, path)
+	return rc, err
+}
+
+func (docker *DockerCli) CopyTo(ctx context.Context, cid, dir string, content io.Reader) error {
+	return docker.cli.CopyToContainer(ctx, cid, dir, content, apiTypes.CopyToContainerOptions{})
+}
+
+func (docker *DockerCli) Diff(ctx context.Context, cid string) ([]types.Change, error) {
+	changes, err := docker.cli.ContainerDiff(ctx, cid)
+	if err != nil {
+		return nil, err
+	}
+	diff := make([]types.Change, len(changes))
+	for i, c := range changes {
+		kind := "C"
+		switch c.Kind {
+		case 1:
+			kind = "A"
+		case 2:
+			kind = "D"
+		}
+		diff[i] = types.Change{Kind: kind, Path: c.Path}
+	}
+	return diff, nil
+}
+
+func (docker *DockerCli) Commit(ctx context.Context, cid string, opts types.CommitOptions) (string, error) {
+	resp, err := docker.cli.ContainerCommit(ctx, cid, apiTypes.ContainerCommitOptions{
+		Reference: opts.Image,
+		Comment:   opts.Comment,
+		Author:    "container-web-tty",
+		Pause:     opts.Pause,
+	})
+	if err != nil {
+		return "", err
+	}
+	return resp.ID, nil
+}
+
+// debugLabel is the label of the debug containers, the value is
+// the ID of the debugged container
+const debugLabel = "container-web-tty.debug"
+
+func (docker *DockerCli) Debug(ctx context.Context, cid string, opts types.DebugOptions) (string, error) {
+	cjson, err := docker.cli.ContainerInspect(ctx, cid)
+	if err != nil {
+		return "", err
+	}
+	if cjson.State == nil || !cjson.State.Running {
+		return "", fmt.Errorf("container %s is not running", cid)
+	}
+
+	// the shell runs as the user of the image, it's picked by the policy
+	// as the user of an exec
+	user, err := docker.policy.user(opts.Image, "", func() (string, error) {
+		return docker.imageUser(ctx, opts.Image)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	ns := "container:" + cjson.ID
+	conf := &dockerContainer.Config{
+		Image: opts.Image,
+		User:  user,
+		Cmd:   []string{"sh"},
+		Tty:   true,
+		// the debug container exits (and is removed) on detaching
+		OpenStdin: true,
+		StdinOnce: true,
+		Labels:    map[string]string{debugLabel: cjson.ID},
+	}
+	hostConf := &dockerContainer.HostConfig{
+		AutoRemove:  true,
+		PidMode:     dockerContainer.PidMode(ns),
+		NetworkMode: dockerContainer.NetworkMode(ns),
+	}
+	id, err := docker.create(ctx, conf, hostConf, "")
+	if err != nil {
+		return "", err
+	}
+	docker.listContainers(ctx, true)
+	return id, nil
+}
+
+// imageUser returns the default user of the image, it's pulled if it's
+// not found
+func (docker *DockerCli) imageUser(ctx context.Context, image string) (string, error) {
+	info, _, err := docker.cli.ImageInspectWithRaw(ctx, image)
+	if client.IsErrNotFound(err) {
+		log.Infof("pull image %s", image)
+		if err = docker.pull(ctx, image); err != nil {
+			return "", fmt.Errorf("pull image %s error: %s", image, err)
+		}
+		info, _, err = docker.cli.ImageInspectWithRaw(ctx, image)
+	}
+	if err != nil {
+		return "", err
+	}
+	if info.Config == nil {
+		return "", nil
+	}
+	return info.Config.User, nil
+}
+
+func (docker *DockerCli) Create(ctx context.Context, opts types.CreateOptions) (string, error) {
+	exposed, bindings, err := nat.ParsePortSpecs(opts.Ports)
+	if err != nil {
+		return "", err
+	}
+	conf := &dockerContainer.Config{
+		Image:        opts.Image,
+		Cmd:          opts.Cmd,
+		Env:          opts.Env,
+		ExposedPorts: exposed,
+		Tty:          true,
+		OpenStdin:    true,
+	}
+	hostConf := &dockerContainer.HostConfig{
+		Binds:        opts.Volumes,
+		PortBindings: bindings,
+	}
+	id, err := docker.create(ctx, conf, hostConf, opts.Name)
+	if err != nil {
+		return "", err
+	}
+	docker.listContainers(ctx, true)
+	return id, nil
+}
+
+// create creates and starts the container, the image is pulled if
+// it's not found, the container is removed if it cannot be started
+func (docker *DockerCli) create(ctx context.Context, conf *dockerContainer.Config,
+	hostConf *dockerContainer.HostConfig, name string) (string, error) {
+	resp, err := docker.cli.ContainerCreate(ctx, conf, hostConf, nil, name)
+	if client.IsErrImageNotFound(err) {
+		log.Infof("pull image %s", conf.Image)
+		if err = docker.pull(ctx, conf.Image); err != nil {
+			return "", fmt.Errorf("pull image %s error: %s", conf.Image, err)
+		}
+		resp, err = docker.cli.ContainerCreate(ctx, conf, hostConf, nil, name)
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := docker.cli.ContainerStart(ctx, resp.ID, apiTypes.ContainerStartOptions{}); err != nil {
+		docker.cli.ContainerRemove(ctx, resp.ID, apiTypes.ContainerRemoveOptions{Force: true})
+		return "", err
+	}
+	return resp.ID, nil
+}
+
+// pull the image and wait until it's done
+func (docker *DockerCli) pull(ctx context.Context, image string) error {
+	progress, err := docker.cli.ImagePull(ctx, image, apiTypes.ImagePullOptions{})
+	if err != nil {
+		return err
+	}
+	defer progress.Close()
+	_, err = io.Copy(ioutil.Discard, progress)
+	return err
+}
+
+func (docker *DockerCli) Rename(ctx context.Context, cid, name string) error {
+	if err := docker.cli.ContainerRename(ctx, cid, name); err != nil {
+		return err
+	}
+	docker.listContainers(ctx, true)
+	return nil
+}
+
+// UpdateLabels is not supported, labels of a docker container
+// cannot be changed after it's created
+func (docker *DockerCli) UpdateLabels(ctx context.Context, cid string, update types.LabelsUpdate) error {
+	return fmt.Errorf("labels of a docker container cannot be changed")
+}
+
 func buildListOptions(options string) (apiTypes.ContainerListOptions, error) {
 	// ["-a", "-f", "key=val"]
 	// https://docs.docker.com/engine/reference/commandline/ps/#filtering
@@ -311,6 +630,10 @@ func buildListOptions(options string) (apiTypes.ContainerListOptions, error) {
 }
 
 func (docker *DockerCli) Exec(ctx context.Context, container types.Container) (types.TTY, error) {
+	if container.Exec.Attach {
+		return docker.attach(ctx, container)
+	}
+
 	// `-l` for a login shell
 	cmds := []string{container.Shell, "-l"}
 	opts := container.Exec
@@ -318,26 +641,33 @@ func (docker *DockerCli) Exec(ctx context.Context, container types.Container) (t
 		cmds = append(cmds, "-c")
 		cmds = append(cmds, fmt.Sprintf("\"\"%s\"\"", cmd))
 	}
-	logrus.Debugf("exec cmd: %v", cmds)
+	log.Debugf("exec cmd: %v", cmds)
 
+	user, err := docker.execUser(ctx, container)
+	if err != nil {
+		return nil, err
+	}
 	execConfig := apiTypes.ExecConfig{
 		AttachStdin:  true,
 		AttachStderr: true,
 		AttachStdout: true,
-		Tty:          true,
+		Tty:          !opts.NoTTY,
 		Privileged:   opts.Privileged,
+		User:         user,
 		Cmd:          cmds,
 		Env:          []string{"HISTCONTROL=ignoredups", "TERM=xterm"},
 	}
-	if opts.User != "" {
-		execConfig.User = opts.User
-	}
 	if opts.Env != "" {
 		execConfig.Env = append(execConfig.Env,
 			strings.Split(opts.Env, " ")...)
 	}
 
+	// the spans tell the slow daemon from the slow network of the
+	// sessions, the attach dials a new connection
+	_, span := tracing.Start(ctx, "docker.exec.create", tracing.KindClient)
 	response, err := docker.cli.ContainerExecCreate(ctx, container.ID, execConfig)
+	span.SetError(err)
+	span.End()
 	if err != nil {
 		return nil, err
 	}
@@ -347,10 +677,14 @@ func (docker *DockerCli) Exec(ctx context.Context, container types.Container) (t
 		return nil, fmt.Errorf("exec ID empty")
 	}
 
+	_, span = tracing.Start(ctx, "docker.exec.attach", tracing.KindClient)
 	resp, err := docker.cli.ContainerExecAttach(ctx, execID, execConfig)
+	span.SetError(err)
+	span.End()
 	if err != nil {
 		return nil, err
 	}
+	docker.setKeepalive(resp.Conn)
 
 	resizeFunc := func(width int, height int) error {
 		return docker.cli.ContainerExecResize(ctx, execID,
@@ -360,7 +694,189 @@ func (docker *DockerCli) Exec(ctx context.Context, container types.Container) (t
 			})
 	}
 
-	return newExecInjector(resp, resizeFunc), nil
+	exitCodeFunc := func() (int, error) {
+		// the ctx may be canceled already when the tab is closed
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		return docker.waitExec(ctx, execID)
+	}
+
+	return newExecInjector(resp, resizeFunc, exitCodeFunc, execConfig.Tty), nil
+}
+
+// attach attaches to the stdio of the main process of the container,
+// like docker attach
+func (docker *DockerCli) attach(ctx context.Context, container types.Container) (types.TTY, error) {
+	cjson, err := docker.cli.ContainerInspect(ctx, container.ID)
+	if err != nil {
+		return nil, err
+	}
+	if cjson.State == nil || !cjson.State.Running {
+		return nil, fmt.Errorf("container %s is not running", container.ID)
+	}
+	mainUser := ""
+	if cjson.Config != nil {
+		mainUser = cjson.Config.User
+	}
+	if err := docker.policy.attachable(container.Image, mainUser); err != nil {
+		return nil, err
+	}
+	tty := cjson.Config != nil && cjson.Config.Tty
+	stdin := container.Exec.AttachStdin
+	if stdin && (cjson.Config == nil || !cjson.Config.OpenStdin) {
+		return nil, fmt.Errorf("the stdin of container %s is not open (docker run -i)", container.ID)
+	}
+	log.Debugf("attach container: %s (stdin: %v, tty: %v)", container.ID, stdin, tty)
+
+	resp, err := docker.cli.ContainerAttach(ctx, container.ID, apiTypes.ContainerAttachOptions{
+		Stream: true,
+		Stdin:  stdin,
+		Stdout: true,
+		Stderr: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	docker.setKeepalive(resp.Conn)
+
+	resizeFunc := func(width int, height int) error {
+		if !tty {
+			return nil
+		}
+		return docker.cli.ContainerResize(ctx, container.ID,
+			apiTypes.ResizeOptions{
+				Width:  uint(width),
+				Height: uint(height),
+			})
+	}
+
+	exitCodeFunc := func() (int, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		cjson, err := docker.cli.ContainerInspect(ctx, container.ID)
+		if err != nil {
+			return 0, err
+		}
+		if cjson.State.Running {
+			return 0, fmt.Errorf("container %s is still running", container.ID)
+		}
+		return cjson.State.ExitCode, nil
+	}
+
+	enj := newExecInjector(resp, resizeFunc, exitCodeFunc, tty)
+	enj.attach = true
+	return enj, nil
+}
+
+// setKeepalive enables the TCP keepalive of an exec stream of a remote
+// docker host, so a dead host is detected instead of hanging the session
+func (docker *DockerCli) setKeepalive(conn net.Conn) {
+	tcpConn, ok := conn.(*net.TCPConn)
+	if !ok || docker.keepalive <= 0 {
+		return // the unix socket
+	}
+	if err := tcpConn.SetKeepAlive(true); err != nil {
+		log.Warnf("set keepalive of exec stream error: %s", err)
+		return
+	}
+	tcpConn.SetKeepAlivePeriod(docker.keepalive)
+}
+
+func (docker *DockerCli) Run(ctx context.Context, container types.Container) (types.RunResult, error) {
+	opts := container.Exec
+	user, err := docker.execUser(ctx, container)
+	if err != nil {
+		return types.RunResult{}, err
+	}
+	execConfig := apiTypes.ExecConfig{
+		AttachStderr: true,
+		AttachStdout: true,
+		Privileged:   opts.Privileged,
+		User:         user,
+		Cmd:          []string{container.Shell, "-c", opts.Cmd},
+	}
+	if opts.Env != "" {
+		execConfig.Env = strings.Split(opts.Env, " ")
+	}
+	log.Debugf("run cmd: %v", execConfig.Cmd)
+
+	start := time.Now()
+	response, err := docker.cli.ContainerExecCreate(ctx, container.ID, execConfig)
+	if err != nil {
+		return types.RunResult{}, err
+	}
+	resp, err := docker.cli.ContainerExecAttach(ctx, response.ID, execConfig)
+	if err != nil {
+		return types.RunResult{}, err
+	}
+	docker.setKeepalive(resp.Conn)
+	defer resp.Close()
+
+	stdout := util.NewCappedBuffer(types.MaxRunOutput)
+	stderr := util.NewCappedBuffer(types.MaxRunOutput)
+	copyErr := make(chan error, 1)
+	go func() {
+		_, err := stdcopy.StdCopy(stdout, stderr, resp.Reader)
+		copyErr <- err
+	}()
+	select {
+	case <-ctx.Done():
+		return types.RunResult{}, ctx.Err()
+	case err := <-copyErr:
+		if err != nil {
+			return types.RunResult{}, err
+		}
+	}
+
+	exitCode, err := docker.waitExec(ctx, response.ID)
+	if err != nil {
+		return types.RunResult{}, err
+	}
+
+	return types.RunResult{
+		Stdout:    stdout.String(),
+		Stderr:    stderr.String(),
+		ExitCode:  exitCode,
+		Truncated: stdout.Truncated || stderr.Truncated,
+		Duration:  time.Since(start).String(),
+	}, nil
+}
+
+// waitExec waits for the exec to exit and returns its exit code,
+// the outputs may end before the process exits
+func (docker *DockerCli) waitExec(ctx context.Context, execID string) (int, error) {
+	for {
+		inspect, err := docker.cli.ContainerExecInspect(ctx, execID)
+		if err != nil {
+			return 0, err
+		}
+		if !inspect.Running {
+			return inspect.ExitCode, nil
+		}
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-time.After(time.Millisecond * 50):
+		}
+	}
+}
+
+func (docker *DockerCli) Ping(ctx context.Context) error {
+	_, err := docker.cli.Ping(ctx)
+	return err
+}
+
+func (docker *DockerCli) Version(ctx context.Context) ([]types.BackendVersion, error) {
+	v, err := docker.cli.ServerVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return []types.BackendVersion{{
+		Name:       "docker",
+		Version:    v.Version,
+		APIVersion: v.APIVersion,
+		Platform:   v.Os + "/" + v.Arch,
+	}}, nil
 }
 
 func (docker *DockerCli) Close() error {
@@ -368,10 +884,125 @@ func (docker *DockerCli) Close() error {
 }
 
 func (docker *DockerCli) Logs(ctx context.Context, opts types.LogOptions) (io.ReadCloser, error) {
-	return docker.cli.ContainerLogs(ctx, opts.ID, apiTypes.ContainerLogsOptions{
+	cjson, err := docker.cli.ContainerInspect(ctx, opts.ID)
+	if err != nil {
+		return nil, err
+	}
+	logOpts := apiTypes.ContainerLogsOptions{
 		ShowStderr: true,
 		ShowStdout: true,
 		Follow:     opts.Follow,
 		Tail:       opts.Tail,
-	})
+		Timestamps: opts.Timestamps,
+	}
+	if !opts.Since.IsZero() {
+		logOpts.Since = strconv.FormatInt(opts.Since.Unix(), 10)
+	}
+	rc, err := docker.cli.ContainerLogs(ctx, opts.ID, logOpts)
+	if err != nil || (cjson.Config != nil && cjson.Config.Tty) {
+		return rc, err
+	}
+
+	// the logs of a container without a tty are multiplexed
+	pr, pw := io.Pipe()
+	go func() {
+		_, err := stdcopy.StdCopy(pw, pw, rc)
+		pw.CloseWithError(err)
+	}()
+	return &demuxedLogs{pr, rc}, nil
+}
+
+type demuxedLogs struct {
+	*io.PipeReader
+	rc io.Closer
+}
+
+func (l *demuxedLogs) Close() error {
+	l.PipeReader.Close()
+	return l.rc.Close()
+}
+
+func (docker *DockerCli) Stats(ctx context.Context, containerID string) (<-chan types.Stats, error) {
+	resp, err := docker.cli.ContainerStats(ctx, containerID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan types.Stats)
+	go func() {
+		defer close(ch)
+		defer resp.Body.Close()
+		dec := json.NewDecoder(resp.Body)
+		for {
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				if err != io.EOF && ctx.Err() == nil {
+					log.Errorf("decode stats of %s error: %s", containerID, err)
+				}
+				return
+			}
+			var s apiTypes.StatsJSON
+			if err := json.Unmarshal(raw, &s); err != nil {
+				log.Errorf("decode stats of %s error: %s", containerID, err)
+				return
+			}
+			// online_cpus is not known by this version of the API types
+			var cpus struct {
+				CPUStats struct {
+					OnlineCPUs uint64 `json:"online_cpus"`
+				} `json:"cpu_stats"`
+			}
+			json.Unmarshal(raw, &cpus)
+
+			select {
+			case ch <- convertStats(s, cpus.CPUStats.OnlineCPUs):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// convertStats calculates the stats like `docker stats`
+func convertStats(s apiTypes.StatsJSON, onlineCPUs uint64) types.Stats {
+	stats := types.Stats{
+		Time:        s.Read,
+		MemoryUsage: s.MemoryStats.Usage,
+		MemoryLimit: s.MemoryStats.Limit,
+		PIDs:        s.PidsStats.Current,
+	}
+
+	if onlineCPUs == 0 {
+		onlineCPUs = uint64(len(s.CPUStats.CPUUsage.PercpuUsage))
+	}
+	cpuDelta := float64(s.CPUStats.CPUUsage.TotalUsage) - float64(s.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(s.CPUStats.SystemUsage) - float64(s.PreCPUStats.SystemUsage)
+	if cpuDelta > 0 && systemDelta > 0 {
+		stats.CPUPercent = cpuDelta / systemDelta * float64(onlineCPUs) * 100
+	}
+
+	// the page cache is not counted, total_inactive_file of
+	// cgroup v1 or inactive_file of cgroup v2
+	cache, ok := s.MemoryStats.Stats["total_inactive_file"]
+	if !ok {
+		cache = s.MemoryStats.Stats["inactive_file"]
+	}
+	if cache < stats.MemoryUsage {
+		stats.MemoryUsage -= cache
+	}
+
+	for _, n := range s.Networks {
+		stats.NetworkRx += n.RxBytes
+		stats.NetworkTx += n.TxBytes
+	}
+	for _, entry := range s.BlkioStats.IoServiceBytesRecursive {
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			stats.BlockRead += entry.Value
+		case "write":
+			stats.BlockWrite += entry.Value
+		}
+	}
+	return stats
 }
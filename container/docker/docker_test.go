@@ -2,7 +2,15 @@ package docker
 
 import (
 	"context"
+	"expvar"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/wrfly/container-web-tty/config"
 )
@@ -22,3 +30,121 @@ func TestDocker(t *testing.T) {
 		}
 	})
 }
+
+func TestHTTPClient(t *testing.T) {
+	dir, err := ioutil.TempDir("", "docker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	sock := filepath.Join(dir, "docker.sock")
+	ln, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	}))
+	srv.Listener = ln
+	srv.Start()
+	defer srv.Close()
+
+	httpCli, err := newHTTPClient("unix://"+sock, config.DockerConfig{
+		MaxIdleConns:    4,
+		IdleConnTimeout: time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	metric := func(key string) int64 {
+		if v, ok := connMetrics.Get(key).(*expvar.Int); ok {
+			return v.Value()
+		}
+		return 0
+	}
+	dials := metric("dials")
+	for i := 0; i < 5; i++ {
+		resp, err := httpCli.Get("http://docker/_ping")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+	}
+	// the connection is reused
+	if n := metric("dials") - dials; n != 1 || metric("open") != 1 {
+		t.Fatalf("unexpected connections: %d dials, %d open", n, metric("open"))
+	}
+	httpCli.Transport.(*http.Transport).CloseIdleConnections()
+	if metric("open") != 0 {
+		t.Fatalf("unexpected open connections: %d", metric("open"))
+	}
+}
+
+func TestExecPolicy(t *testing.T) {
+	policy := execPolicy{
+		User:       "nobody",
+		ImageUsers: map[string]string{"nginx": "www-data", "redis:6": "999"},
+		NoRoot:     true,
+	}
+	defaultUser := func(user string) func() (string, error) {
+		return func() (string, error) { return user, nil }
+	}
+	for _, c := range []struct {
+		image, requested, defaultUser string
+		want                          string
+		err                           error
+	}{
+		{"nginx:1.19", "root", "", "www-data", nil},
+		{"redis:6", "", "", "999", nil},
+		{"redis:5", "root", "", "nobody", nil},
+		{"localhost:5000/nginx", "", "", "nobody", nil},
+	} {
+		got, err := policy.user(c.image, c.requested, defaultUser(c.defaultUser))
+		if err != c.err || got != c.want {
+			t.Errorf("user of %s: got %q, %v", c.image, got, err)
+		}
+	}
+
+	policy = execPolicy{NoRoot: true}
+	for _, c := range []struct {
+		requested, defaultUser string
+		err                    error
+	}{
+		{"", "", errRootExec},
+		{"0:0", "", errRootExec},
+		{"root", "app", errRootExec},
+		{"", "app", nil},
+		{"1000", "", nil},
+	} {
+		got, err := policy.user("alpine", c.requested, defaultUser(c.defaultUser))
+		if err != c.err || (err == nil && got != c.requested) {
+			t.Errorf("user %q of %q by default: got %q, %v", c.requested, c.defaultUser, got, err)
+		}
+	}
+
+	// without no-root the requested one is kept
+	if got, _ := (execPolicy{}).user("alpine", "root", nil); got != "root" {
+		t.Errorf("unexpected user %q", got)
+	}
+
+	// the main process is attached only as the user of the policy
+	for _, c := range []struct {
+		policy          execPolicy
+		image, mainUser string
+		err             error
+	}{
+		{execPolicy{}, "alpine", "", nil},
+		{execPolicy{NoRoot: true}, "alpine", "", errRootExec},
+		{execPolicy{NoRoot: true}, "alpine", "0:0", errRootExec},
+		{execPolicy{NoRoot: true}, "alpine", "app", nil},
+		{execPolicy{User: "nobody"}, "alpine", "root", errAttachUser},
+		{execPolicy{User: "nobody"}, "alpine", "nobody:nogroup", nil},
+		{execPolicy{User: "nobody", ImageUsers: map[string]string{"nginx": "www-data"}}, "nginx:1.19", "nobody", errAttachUser},
+		{execPolicy{User: "nobody", ImageUsers: map[string]string{"nginx": "www-data"}}, "nginx:1.19", "www-data", nil},
+	} {
+		if err := c.policy.attachable(c.image, c.mainUser); err != c.err {
+			t.Errorf("attach %q of %s by %+v: got %v", c.mainUser, c.image, c.policy, err)
+		}
+	}
+}
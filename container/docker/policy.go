@@ -0,0 +1,102 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/wrfly/container-web-tty/config"
+	"github.com/wrfly/container-web-tty/types"
+)
+
+var (
+	errRootExec       = errors.New("exec as root is forbidden by the server")
+	errPrivilegedExec = errors.New("privileged exec is forbidden by the server")
+	errAttachUser     = errors.New("attach to the main process of another user is forbidden by the server")
+)
+
+// execPolicy picks the user of the exec'ed processes
+type execPolicy config.ExecPolicy
+
+// user returns the user to exec as in the image instead of the requested
+// one, defaultUser is the user of the container used if it's empty
+func (p execPolicy) user(image, requested string, defaultUser func() (string, error)) (string, error) {
+	user := requested
+	if u, ok := p.imageUser(image); ok {
+		user = u
+	} else if p.User != "" {
+		user = p.User
+	}
+	if !p.NoRoot {
+		return user, nil
+	}
+
+	effective := user
+	if effective == "" {
+		var err error
+		if effective, err = defaultUser(); err != nil {
+			return "", err
+		}
+	}
+	if isRoot(effective) {
+		return "", errRootExec
+	}
+	return user, nil
+}
+
+// attachable checks the user of the main process of the container against
+// the policy before attaching, it can't be changed as the user of an exec
+func (p execPolicy) attachable(image, mainUser string) error {
+	pinned, ok := p.imageUser(image)
+	if !ok {
+		pinned = p.User
+	}
+	if pinned != "" && userName(pinned) != userName(mainUser) {
+		return errAttachUser
+	}
+	if p.NoRoot && isRoot(mainUser) {
+		return errRootExec
+	}
+	return nil
+}
+
+// imageUser returns the user of the image, or of the image without the tag
+func (p execPolicy) imageUser(image string) (string, bool) {
+	if u, ok := p.ImageUsers[image]; ok {
+		return u, true
+	}
+	// not the port of a registry
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		u, ok := p.ImageUsers[image[:i]]
+		return u, ok
+	}
+	return "", false
+}
+
+// isRoot reports whether the user[:group] is root, it's root by default
+func isRoot(user string) bool {
+	name := userName(user)
+	return name == "" || name == "root" || name == "0"
+}
+
+// userName returns the user of the user[:group]
+func userName(user string) string {
+	return strings.SplitN(user, ":", 2)[0]
+}
+
+// execUser returns the user to exec in the container as
+func (docker *DockerCli) execUser(ctx context.Context, container types.Container) (string, error) {
+	if docker.policy.NoRoot && container.Exec.Privileged {
+		return "", errPrivilegedExec
+	}
+	return docker.policy.user(container.Image, container.Exec.User, func() (string, error) {
+		info, err := docker.cli.ContainerInspect(ctx, container.ID)
+		if err != nil {
+			return "", err
+		}
+		if info.Config == nil {
+			return "", nil
+		}
+		return info.Config.User, nil
+	})
+}
@@ -0,0 +1,145 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	apiTypes "github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+
+	"github.com/wrfly/container-web-tty/types"
+)
+
+// Prune removes the stopped containers, the dangling images or the
+// unused volumes, like docker container|image|volume prune
+func (docker *DockerCli) Prune(ctx context.Context, kind string, dryRun bool) (types.PruneReport, error) {
+	report := types.PruneReport{Kind: kind, DryRun: dryRun, Items: []types.PruneItem{}}
+	var err error
+	switch kind {
+	case "containers":
+		err = docker.pruneContainers(ctx, &report)
+	case "images":
+		err = docker.pruneImages(ctx, &report)
+	case "volumes":
+		err = docker.pruneVolumes(ctx, &report)
+	default:
+		err = fmt.Errorf("unknown prune kind %s", kind)
+	}
+	if err != nil {
+		return report, err
+	}
+	if dryRun {
+		for _, item := range report.Items {
+			if item.Size > 0 {
+				report.Reclaimed += uint64(item.Size)
+			}
+		}
+		return report, nil
+	}
+	if kind == "containers" {
+		docker.listContainers(ctx, true)
+	}
+	return report, nil
+}
+
+func (docker *DockerCli) pruneContainers(ctx context.Context, report *types.PruneReport) error {
+	if !report.DryRun {
+		resp, err := docker.cli.ContainersPrune(ctx, filters.NewArgs())
+		if err != nil {
+			return err
+		}
+		for _, id := range resp.ContainersDeleted {
+			report.Items = append(report.Items, types.PruneItem{ID: id})
+		}
+		report.Reclaimed = resp.SpaceReclaimed
+		return nil
+	}
+
+	// the containers not running or paused are pruned
+	args := filters.NewArgs()
+	for _, status := range []string{"created", "exited", "dead"} {
+		args.Add("status", status)
+	}
+	cs, err := docker.cli.ContainerList(ctx, apiTypes.ContainerListOptions{
+		All:     true,
+		Size:    true,
+		Filters: args,
+	})
+	if err != nil {
+		return err
+	}
+	for _, c := range cs {
+		item := types.PruneItem{ID: c.ID, Size: c.SizeRw}
+		if len(c.Names) != 0 {
+			item.Name = strings.TrimPrefix(c.Names[0], "/")
+		}
+		report.Items = append(report.Items, item)
+	}
+	return nil
+}
+
+func (docker *DockerCli) pruneImages(ctx context.Context, report *types.PruneReport) error {
+	// only the dangling images are pruned, like docker image prune without -a
+	args := filters.NewArgs()
+	args.Add("dangling", "true")
+	if !report.DryRun {
+		resp, err := docker.cli.ImagesPrune(ctx, args)
+		if err != nil {
+			return err
+		}
+		for _, img := range resp.ImagesDeleted {
+			if img.Deleted != "" {
+				report.Items = append(report.Items, types.PruneItem{ID: img.Deleted})
+			} else {
+				report.Items = append(report.Items, types.PruneItem{Name: img.Untagged})
+			}
+		}
+		report.Reclaimed = resp.SpaceReclaimed
+		return nil
+	}
+
+	images, err := docker.cli.ImageList(ctx, apiTypes.ImageListOptions{Filters: args})
+	if err != nil {
+		return err
+	}
+	for _, img := range images {
+		// the shared layers are not reclaimed
+		size := img.Size
+		if img.SharedSize > 0 {
+			size -= img.SharedSize
+		}
+		report.Items = append(report.Items, types.PruneItem{ID: img.ID, Size: size})
+	}
+	return nil
+}
+
+func (docker *DockerCli) pruneVolumes(ctx context.Context, report *types.PruneReport) error {
+	if !report.DryRun {
+		resp, err := docker.cli.VolumesPrune(ctx, filters.NewArgs())
+		if err != nil {
+			return err
+		}
+		for _, name := range resp.VolumesDeleted {
+			report.Items = append(report.Items, types.PruneItem{ID: name, Name: name})
+		}
+		report.Reclaimed = resp.SpaceReclaimed
+		return nil
+	}
+
+	args := filters.NewArgs()
+	args.Add("dangling", "true")
+	volumes, err := docker.cli.VolumeList(ctx, args)
+	if err != nil {
+		return err
+	}
+	for _, v := range volumes.Volumes {
+		item := types.PruneItem{ID: v.Name, Name: v.Name}
+		// the size is only known when the usage is calculated (docker system df)
+		if v.UsageData != nil {
+			item.Size = v.UsageData.Size
+		}
+		report.Items = append(report.Items, item)
+	}
+	return nil
+}
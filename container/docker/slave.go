@@ -1,26 +1,53 @@
 package docker
 
 import (
+	"io"
 	"time"
 
 	apiTypes "github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/stdcopy"
 )
 
 // execInjector implement webtty.Slave
 type execInjector struct {
 	hResp      apiTypes.HijackedResponse
 	resize     resizeFunction
+	exitCode   exitCodeFunction
 	activeChan chan struct{}
+
+	// demultiplexed outputs of a non-tty exec
+	stdout, stderr io.Reader
+	// attached to the main process, which is kept running on exit
+	attach bool
 }
 
 type resizeFunction func(width int, height int) error
 
-func newExecInjector(resp apiTypes.HijackedResponse, resize resizeFunction) *execInjector {
-	return &execInjector{
+type exitCodeFunction func() (int, error)
+
+func newExecInjector(resp apiTypes.HijackedResponse, resize resizeFunction,
+	exitCode exitCodeFunction, tty bool) *execInjector {
+	enj := &execInjector{
 		hResp:      resp,
 		resize:     resize,
+		exitCode:   exitCode,
 		activeChan: make(chan struct{}, 5),
 	}
+	if tty {
+		return enj
+	}
+
+	// without a tty, docker multiplexes stdout and stderr in one stream
+	outR, outW := io.Pipe()
+	errR, errW := io.Pipe()
+	go func() {
+		_, err := stdcopy.StdCopy(outW, errW, resp.Reader)
+		outW.CloseWithError(err)
+		errW.CloseWithError(err)
+	}()
+	enj.stdout, enj.stderr = outR, errR
+
+	return enj
 }
 
 func (enj *execInjector) Read(p []byte) (n int, err error) {
@@ -31,6 +58,9 @@ func (enj *execInjector) Read(p []byte) (n int, err error) {
 		enj.activeChan <- struct{}{}
 	}()
 	// logrus.Debugf("output: %s\n", p)
+	if enj.stdout != nil {
+		return enj.stdout.Read(p)
+	}
 	return enj.hResp.Reader.Read(p)
 }
 
@@ -40,7 +70,9 @@ func (enj *execInjector) Write(p []byte) (n int, err error) {
 }
 
 func (enj *execInjector) Exit() error {
-	enj.Write([]byte("exit\n"))
+	if !enj.attach {
+		enj.Write([]byte("exit\n"))
+	}
 	close(enj.activeChan)
 	return enj.hResp.Conn.Close()
 }
@@ -49,6 +81,14 @@ func (enj *execInjector) ActiveChan() <-chan struct{} {
 	return enj.activeChan
 }
 
+func (enj *execInjector) Stderr() io.Reader {
+	return enj.stderr
+}
+
+func (enj *execInjector) ExitCode() (int, error) {
+	return enj.exitCode()
+}
+
 func (enj *execInjector) WindowTitleVariables() map[string]interface{} {
 	return map[string]interface{}{}
 }
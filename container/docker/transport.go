@@ -0,0 +1,75 @@
+package docker
+
+import (
+	"expvar"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/docker/docker/client"
+	"github.com/docker/go-connections/sockets"
+
+	"github.com/wrfly/container-web-tty/config"
+)
+
+// dialTimeout is the timeout of dialing the docker daemon
+const dialTimeout = 32 * time.Second
+
+// connMetrics are the connections of the docker client, served in
+// /debug/vars, the exec streams are not counted
+var connMetrics = expvar.NewMap("docker_conns")
+
+// newHTTPClient returns the client shared by the API calls, the idle
+// connections are kept to be reused instead of dialing every request
+func newHTTPClient(host string, conf config.DockerConfig) (*http.Client, error) {
+	proto, addr, _, err := client.ParseHost(host)
+	if err != nil {
+		return nil, err
+	}
+
+	tr := &http.Transport{
+		MaxIdleConns:        conf.MaxIdleConns,
+		MaxIdleConnsPerHost: conf.MaxIdleConns,
+		IdleConnTimeout:     conf.IdleConnTimeout,
+	}
+	if err := sockets.ConfigureTransport(tr, proto, addr); err != nil {
+		return nil, err
+	}
+	if proto == "tcp" {
+		dialer, err := sockets.DialerFromEnvironment(&net.Dialer{
+			Timeout:   dialTimeout,
+			KeepAlive: conf.Keepalive,
+		})
+		if err != nil {
+			return nil, err
+		}
+		tr.Dial = dialer.Dial
+	}
+
+	dial := tr.Dial
+	tr.Dial = func(network, address string) (net.Conn, error) {
+		conn, err := dial(network, address)
+		if err != nil {
+			connMetrics.Add("dial_errors", 1)
+			return nil, err
+		}
+		connMetrics.Add("dials", 1)
+		connMetrics.Add("open", 1)
+		return &countedConn{Conn: conn}, nil
+	}
+	return &http.Client{Transport: tr}, nil
+}
+
+// countedConn is an open connection of the docker client
+type countedConn struct {
+	net.Conn
+	closed int32
+}
+
+func (c *countedConn) Close() error {
+	if atomic.CompareAndSwapInt32(&c.closed, 0, 1) {
+		connMetrics.Add("open", -1)
+	}
+	return c.Conn.Close()
+}
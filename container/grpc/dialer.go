@@ -7,7 +7,6 @@ import (
 	"time"
 
 	"github.com/elazarl/goproxy"
-	"github.com/sirupsen/logrus"
 	"golang.org/x/net/proxy"
 	"google.golang.org/grpc"
 )
@@ -61,7 +60,7 @@ func newDialOption(proxyStr string) (grpc.DialOption, error) {
 		}
 	case "http", "https":
 		p := goproxy.NewProxyHttpServer()
-		p.Logger.SetOutput(logrus.StandardLogger().Out)
+		p.Logger.SetOutput(log.Logger.Out)
 		httpDialer := p.NewConnectDialToProxy(proxyStr)
 
 		if _, err := dialWithTimeout(&dialer{proxyD: httpDialer},
@@ -92,7 +91,7 @@ func dialWithTimeout(dialer proxy.Dialer, addr string,
 			connChan <- conn
 			return
 		}
-		logrus.Errorf("dial to %s error: %s", addr, err)
+		log.Errorf("dial to %s error: %s", addr, err)
 	}()
 
 	select {
@@ -4,17 +4,27 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
-	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/status"
 
 	"github.com/wrfly/container-web-tty/config"
+	"github.com/wrfly/container-web-tty/event"
+	"github.com/wrfly/container-web-tty/logging"
 	pb "github.com/wrfly/container-web-tty/proxy/pb"
 	"github.com/wrfly/container-web-tty/types"
 	"github.com/wrfly/container-web-tty/util"
 )
 
+var log = logging.Module("grpc")
+
 type grpcCli struct {
 	addr, auth string
 
@@ -33,10 +43,10 @@ func (g *grpcCli) waitForAvaliable(ctx context.Context) {
 func (g *grpcCli) alive() bool {
 	pong, err := g.client.Ping(context.Background(), &pb.Empty{Auth: g.auth})
 	if err != nil {
-		logrus.Errorf("connot connect to [%s], %s", g.addr, err)
+		log.Errorf("connot connect to [%s], %s", g.addr, err)
 		return false
 	}
-	logrus.Debugf("pong from %s: %s", g.addr, pong.GetMsg())
+	log.Debugf("pong from %s: %s", g.addr, pong.GetMsg())
 	return true
 }
 
@@ -44,30 +54,68 @@ func (g *grpcCli) state() connectivity.State {
 	return g.conn.GetState()
 }
 
+// watchEvents relays the container events of the remote server to the hub
+func (g *grpcCli) watchEvents(events *event.Hub) {
+	for {
+		err := g.recvEvents(events)
+		if status.Code(err) == codes.Unimplemented {
+			log.Warnf("remote server [%s] doesn't support events", g.addr)
+			return
+		}
+		log.Errorf("receive events from [%s] error: %s", g.addr, err)
+		time.Sleep(time.Second * 5)
+	}
+}
+
+func (g *grpcCli) recvEvents(events *event.Hub) error {
+	stream, err := g.client.Events(context.Background(), &pb.Empty{Auth: g.auth})
+	if err != nil {
+		return err
+	}
+	for {
+		e, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		events.Publish(types.Event{
+			Action:    e.Action,
+			ID:        e.Id,
+			Name:      e.Name,
+			LocServer: g.addr,
+			Time:      time.Unix(0, e.Time),
+			Detail:    e.Detail,
+		})
+	}
+}
+
 // GrpcCli connect to the remote server
 type GrpcCli struct {
 	servers    []string
 	auth       string
 	clients    map[string]grpcCli
 	containers *types.Containers
+	// max time to list the containers of a server, 0 for no limit
+	listTimeout time.Duration
 }
 
-// NewCli returns the GrpcCli
-func NewCli(conf config.GRPCConfig) (*GrpcCli, error) {
-	logrus.Infof("New gRPC client connect to %v with auth [%s]",
+// NewCli returns the GrpcCli, the container events of
+// the remote servers are published to the hub
+func NewCli(conf config.GRPCConfig, events *event.Hub) (*GrpcCli, error) {
+	log.Infof("New gRPC client connect to %v with auth [%s]",
 		conf.Servers, conf.Auth)
 	gCli := &GrpcCli{
-		servers:    conf.Servers,
-		auth:       conf.Auth,
-		clients:    make(map[string]grpcCli, len(conf.Servers)),
-		containers: new(types.Containers),
+		servers:     conf.Servers,
+		auth:        conf.Auth,
+		clients:     make(map[string]grpcCli, len(conf.Servers)),
+		containers:  new(types.Containers),
+		listTimeout: conf.ListTimeout,
 	}
 
 	var opts []grpc.DialOption
 
 	// add a proxy
 	if conf.Proxy != "" {
-		logrus.Infof("setting grpc proxy with %s", conf.Proxy)
+		log.Infof("setting grpc proxy with %s", conf.Proxy)
 		dialerOption, err := newDialOption(conf.Proxy)
 		if err != nil {
 			return nil, fmt.Errorf("create proxy dialer error: %s", err)
@@ -86,11 +134,20 @@ func NewCli(conf config.GRPCConfig) (*GrpcCli, error) {
 			opts = append(opts, grpc.WithTransportCredentials(creds))
 		}
 	*/
+	if conf.Keepalive > 0 {
+		// the servers must permit pings of this interval,
+		// see the enforcement policy of proxy.New
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                conf.Keepalive,
+			Timeout:             conf.Keepalive,
+			PermitWithoutStream: true,
+		}))
+	}
 	opts = append(opts, grpc.WithInsecure())
 	for _, serverAddr := range conf.Servers {
 		conn, err := grpc.Dial(serverAddr, opts...)
 		if err != nil {
-			logrus.Errorf("fail to dial: %v", err)
+			log.Errorf("fail to dial: %v", err)
 			continue
 		}
 
@@ -110,61 +167,101 @@ func NewCli(conf config.GRPCConfig) (*GrpcCli, error) {
 		delLists = append(delLists, addr)
 	}
 	for _, addr := range delLists {
-		logrus.Infof("connot ping remote server %s, remove it from clients", addr)
+		log.Infof("connot ping remote server %s, remove it from clients", addr)
 		delete(gCli.clients, addr)
 	}
 
+	if events != nil {
+		for _, cli := range gCli.clients {
+			cli := cli
+			go cli.watchEvents(events)
+		}
+	}
+
 	return gCli, nil
 }
 
 func (gCli GrpcCli) GetInfo(ctx context.Context, cid string) types.Container {
 	if gCli.containers.Len() == 0 {
-		logrus.Debugf("zero containers, get cid %s", cid)
+		log.Debugf("zero containers, get cid %s", cid)
 		gCli.List(ctx)
 	}
 
 	container := gCli.containers.Find(cid)
 	if container.ID == "" {
-		logrus.Errorf("no such container: %s", cid)
+		log.Errorf("no such container: %s", cid)
 		return types.Container{}
 	}
 	if container.Shell != "" {
-		logrus.Debugf("found valid container: %s (%s)", container.ID, container.Shell)
+		log.Debugf("found valid container: %s (%s)", container.ID, container.Shell)
 		return container
 	}
 
 	remoteAddr := container.LocServer
 	remoteClient, exist := gCli.clients[remoteAddr]
 	if !exist {
-		logrus.Errorf("no remote client: %s", remoteAddr)
+		log.Errorf("no remote client: %s", remoteAddr)
 		return types.Container{}
 	}
 	pbContainer, err := remoteClient.client.GetInfo(ctx,
 		&pb.ContainerID{Id: cid, Auth: gCli.auth})
 	if err != nil {
-		logrus.Errorf("grpc get container error: %s", err)
+		log.Errorf("grpc get container error: %s", err)
 		return types.Container{}
 	}
 	gCli.containers.SetShell(cid, pbContainer.GetShell())
 	return util.ConvertPbContainer(pbContainer)
 }
 
+// listServer lists the containers of the remote server in the list timeout
+func (gCli GrpcCli) listServer(ctx context.Context, cli grpcCli) ([]*pb.Container, error) {
+	if gCli.listTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, gCli.listTimeout)
+		defer cancel()
+	}
+	cs, err := cli.client.List(ctx, &pb.Empty{Auth: gCli.auth})
+	if err != nil {
+		return nil, err
+	}
+	return cs.Cs, nil
+}
+
+// List lists the containers of all the servers at the same time, the
+// ones failed or timed out are left out so a slow server doesn't block
+// the others
 func (gCli GrpcCli) List(ctx context.Context) []types.Container {
 	allContainers := make([]types.Container, 0)
 	containerIDMap := make(map[string]bool, 0)
 
-	for addr, cli := range gCli.clients {
-		if !cli.alive() {
-			logrus.Warnf("remote server %s is not ready: %s", addr, cli.state())
-			continue
-		}
-		cs, err := cli.client.List(ctx, &pb.Empty{Auth: gCli.auth})
-		if err != nil {
-			logrus.Errorf("get container info error: %s", err)
-			continue
+	// in the order of the servers, the first one wins a duplicated ID
+	addrs := make([]string, 0, len(gCli.clients))
+	for _, addr := range gCli.servers {
+		if _, ok := gCli.clients[addr]; ok {
+			addrs = append(addrs, addr)
 		}
-		for _, c := range cs.Cs {
-			c.LocServer = addr
+	}
+	results := make([][]*pb.Container, len(addrs))
+	var wg sync.WaitGroup
+	for i, addr := range addrs {
+		wg.Add(1)
+		go func(i int, addr string) {
+			defer wg.Done()
+			start := time.Now()
+			cs, err := gCli.listServer(ctx, gCli.clients[addr])
+			if err != nil {
+				log.Errorf("list containers of remote server %s error in %s: %s",
+					addr, time.Since(start), err)
+				return
+			}
+			results[i] = cs
+		}(i, addr)
+	}
+	wg.Wait()
+
+	for i, cs := range results {
+		for _, c := range cs {
+			c.LocServer = addrs[i]
 			if !containerIDMap[c.Id] {
 				allContainers = append(allContainers,
 					util.ConvertPbContainer(c))
@@ -174,7 +271,7 @@ func (gCli GrpcCli) List(ctx context.Context) []types.Container {
 	}
 
 	gCli.containers.Set(allContainers)
-	logrus.Debugf("list %d containers", len(allContainers))
+	log.Debugf("list %d containers", len(allContainers))
 
 	return allContainers
 }
@@ -198,6 +295,10 @@ func (gCli GrpcCli) containerAction(ctx context.Context, action, containerID str
 			err1, err2 = cli.client.Stop(ctx, pbCID)
 		case "restart":
 			err1, err2 = cli.client.Restart(ctx, pbCID)
+		case "pause":
+			err1, err2 = cli.client.Pause(ctx, pbCID)
+		case "unpause":
+			err1, err2 = cli.client.Unpause(ctx, pbCID)
 		default:
 			return fmt.Errorf("unknown action: %s", action)
 		}
@@ -205,8 +306,8 @@ func (gCli GrpcCli) containerAction(ctx context.Context, action, containerID str
 		if err2 != nil {
 			return err2
 		}
-		if err1 != nil {
-			return fmt.Errorf(err1.Err)
+		if err1.GetErr() != "" {
+			return fmt.Errorf("%s", err1.Err)
 		}
 		return nil
 	}
@@ -221,12 +322,360 @@ func (gCli GrpcCli) Stop(ctx context.Context, containerID string) error {
 	return gCli.containerAction(ctx, "stop", containerID)
 }
 
+func (gCli GrpcCli) Pause(ctx context.Context, containerID string) error {
+	return gCli.containerAction(ctx, "pause", containerID)
+}
+
+func (gCli GrpcCli) Unpause(ctx context.Context, containerID string) error {
+	return gCli.containerAction(ctx, "unpause", containerID)
+}
+
 func (gCli GrpcCli) Restart(ctx context.Context, containerID string) error {
 	return gCli.containerAction(ctx, "restart", containerID)
 }
 
+func (gCli GrpcCli) Kill(ctx context.Context, containerID, signal string) error {
+	return gCli.call(containerID, func(cli pb.ContainerServerClient, cid *pb.ContainerID) (*pb.Err, error) {
+		return cli.Kill(ctx, &pb.KillOpts{C: cid, Signal: signal})
+	})
+}
+
+func (gCli GrpcCli) Top(ctx context.Context, containerID string) (types.Processes, error) {
+	info := gCli.containers.Find(containerID)
+	if info.ID == "" {
+		return types.Processes{}, fmt.Errorf("container not found")
+	}
+	cli, exist := gCli.clients[info.LocServer]
+	if !exist {
+		return types.Processes{}, fmt.Errorf("location server [%s] not found", info.LocServer)
+	}
+	ps, err := cli.client.Top(ctx, &pb.ContainerID{
+		Id:   containerID,
+		Auth: gCli.auth,
+	})
+	if err != nil {
+		return types.Processes{}, err
+	}
+	top := types.Processes{Titles: ps.Titles, Processes: [][]string{}}
+	for _, p := range ps.Ps {
+		top.Processes = append(top.Processes, p.GetFields())
+	}
+	return top, nil
+}
+
+func (gCli GrpcCli) Inspect(ctx context.Context, containerID string) (types.ContainerDetail, error) {
+	info := gCli.containers.Find(containerID)
+	if info.ID == "" {
+		return types.ContainerDetail{}, fmt.Errorf("container not found")
+	}
+	cli, exist := gCli.clients[info.LocServer]
+	if !exist {
+		return types.ContainerDetail{}, fmt.Errorf("location server [%s] not found", info.LocServer)
+	}
+	d, err := cli.client.Inspect(ctx, &pb.ContainerID{
+		Id:   containerID,
+		Auth: gCli.auth,
+	})
+	if err != nil {
+		return types.ContainerDetail{}, err
+	}
+	detail := types.ContainerDetail{
+		Env:    make([]types.EnvVar, 0, len(d.Env)),
+		Mounts: make([]types.Mount, 0, len(d.Mounts)),
+	}
+	for _, e := range d.Env {
+		detail.Env = append(detail.Env, types.EnvVar{Name: e.Name, Value: e.Value})
+	}
+	for _, m := range d.Mounts {
+		detail.Mounts = append(detail.Mounts, types.Mount{
+			Type:        m.Type,
+			Source:      m.Source,
+			Destination: m.Destination,
+			ReadOnly:    m.ReadOnly,
+		})
+	}
+	if h := d.Health; h != nil {
+		detail.Health = &types.Health{
+			Cmd:           h.Cmd,
+			Status:        h.Status,
+			FailingStreak: int(h.FailingStreak),
+			Probes:        make([]types.HealthProbe, 0, len(h.Probes)),
+		}
+		for _, p := range h.Probes {
+			detail.Health.Probes = append(detail.Health.Probes, types.HealthProbe{
+				Start:    time.Unix(0, p.Start),
+				End:      time.Unix(0, p.End),
+				ExitCode: int(p.ExitCode),
+				Output:   p.Output,
+			})
+		}
+	}
+	return detail, nil
+}
+
+func (gCli GrpcCli) CopyFrom(ctx context.Context, containerID, path string) (io.ReadCloser, error) {
+	info := gCli.containers.Find(containerID)
+	if info.ID == "" {
+		return nil, fmt.Errorf("container not found")
+	}
+	cli, exist := gCli.clients[info.LocServer]
+	if !exist {
+		return nil, fmt.Errorf("location server [%s] not found", info.LocServer)
+	}
+	copyClient, err := cli.client.CopyFrom(ctx, &pb.CopyOpts{
+		C: &pb.ContainerID{
+			Id:   containerID,
+			Auth: gCli.auth,
+		},
+		Path: path,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		for {
+			out, err := copyClient.Recv()
+			if err != nil {
+				if err == io.EOF {
+					err = nil
+				}
+				pw.CloseWithError(err)
+				return
+			}
+			if _, err := pw.Write(out.GetOut()); err != nil {
+				return
+			}
+		}
+	}()
+	return pr, nil
+}
+
+func (gCli GrpcCli) CopyTo(ctx context.Context, containerID, dir string, content io.Reader) error {
+	info := gCli.containers.Find(containerID)
+	if info.ID == "" {
+		return fmt.Errorf("container not found")
+	}
+	cli, exist := gCli.clients[info.LocServer]
+	if !exist {
+		return fmt.Errorf("location server [%s] not found", info.LocServer)
+	}
+	copyClient, err := cli.client.CopyTo(ctx)
+	if err != nil {
+		return err
+	}
+	err = copyClient.Send(&pb.CopyOpts{
+		C: &pb.ContainerID{
+			Id:   containerID,
+			Auth: gCli.auth,
+		},
+		Path: dir,
+	})
+	if err != nil {
+		return err
+	}
+
+	buff := make([]byte, 32<<10)
+	for {
+		n, err := content.Read(buff)
+		if n > 0 {
+			if err := copyClient.Send(&pb.CopyOpts{Data: buff[:n]}); err != nil {
+				break // the error is returned by CloseAndRecv
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			copyClient.CloseSend()
+			return err
+		}
+	}
+	e, err := copyClient.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+	if e.GetErr() != "" {
+		return fmt.Errorf("%s", e.Err)
+	}
+	return nil
+}
+
+func (gCli GrpcCli) Diff(ctx context.Context, containerID string) ([]types.Change, error) {
+	info := gCli.containers.Find(containerID)
+	if info.ID == "" {
+		return nil, fmt.Errorf("container not found")
+	}
+	cli, exist := gCli.clients[info.LocServer]
+	if !exist {
+		return nil, fmt.Errorf("location server [%s] not found", info.LocServer)
+	}
+	changes, err := cli.client.Diff(ctx, &pb.ContainerID{
+		Id:   containerID,
+		Auth: gCli.auth,
+	})
+	if err != nil {
+		return nil, err
+	}
+	diff := make([]types.Change, 0, len(changes.Cs))
+	for _, c := range changes.Cs {
+		diff = append(diff, types.Change{Kind: c.Kind, Path: c.Path})
+	}
+	return diff, nil
+}
+
+func (gCli GrpcCli) Commit(ctx context.Context, containerID string, opts types.CommitOptions) (string, error) {
+	info := gCli.containers.Find(containerID)
+	if info.ID == "" {
+		return "", fmt.Errorf("container not found")
+	}
+	cli, exist := gCli.clients[info.LocServer]
+	if !exist {
+		return "", fmt.Errorf("location server [%s] not found", info.LocServer)
+	}
+	image, err := cli.client.Commit(ctx, &pb.CommitOpts{
+		C: &pb.ContainerID{
+			Id:   containerID,
+			Auth: gCli.auth,
+		},
+		Image:   opts.Image,
+		Comment: opts.Comment,
+		Pause:   opts.Pause,
+	})
+	if err != nil {
+		return "", err
+	}
+	return image.Id, nil
+}
+
+// Create creates the container in the opts.Server, which can be
+// omitted if there's only one remote server
+func (gCli GrpcCli) Create(ctx context.Context, opts types.CreateOptions) (string, error) {
+	addr := opts.Server
+	if addr == "" && len(gCli.clients) == 1 {
+		for a := range gCli.clients {
+			addr = a
+		}
+	}
+	if addr == "" {
+		return "", fmt.Errorf("location server is required")
+	}
+	cli, exist := gCli.clients[addr]
+	if !exist {
+		return "", fmt.Errorf("location server [%s] not found", addr)
+	}
+	created, err := cli.client.Create(ctx, &pb.CreateOpts{
+		Auth:    gCli.auth,
+		Image:   opts.Image,
+		Name:    opts.Name,
+		Cmd:     opts.Cmd,
+		Env:     opts.Env,
+		Ports:   opts.Ports,
+		Volumes: opts.Volumes,
+	})
+	if err != nil {
+		return "", err
+	}
+	// list again to find the new container
+	gCli.List(ctx)
+	return created.Id, nil
+}
+
+func (gCli GrpcCli) Debug(ctx context.Context, containerID string, opts types.DebugOptions) (string, error) {
+	info := gCli.containers.Find(containerID)
+	if info.ID == "" {
+		return "", fmt.Errorf("container not found")
+	}
+	cli, exist := gCli.clients[info.LocServer]
+	if !exist {
+		return "", fmt.Errorf("location server [%s] not found", info.LocServer)
+	}
+	debug, err := cli.client.Debug(ctx, &pb.DebugOpts{
+		C: &pb.ContainerID{
+			Id:   containerID,
+			Auth: gCli.auth,
+		},
+		Image: opts.Image,
+	})
+	if err != nil {
+		return "", err
+	}
+	// list again to find the debug container
+	gCli.List(ctx)
+	return debug.Id, nil
+}
+
+// Prune prunes the resources of all the remote servers
+func (gCli GrpcCli) Prune(ctx context.Context, kind string, dryRun bool) (types.PruneReport, error) {
+	report := types.PruneReport{Kind: kind, DryRun: dryRun, Items: []types.PruneItem{}}
+	failed := []string{}
+	for addr, cli := range gCli.clients {
+		r, err := cli.client.Prune(ctx, &pb.PruneOpts{
+			Auth:   gCli.auth,
+			Kind:   kind,
+			DryRun: dryRun,
+		})
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %s", addr, err))
+			continue
+		}
+		for _, item := range r.Items {
+			report.Items = append(report.Items, types.PruneItem{
+				ID:   item.Id,
+				Name: item.Name,
+				Size: item.Size,
+			})
+		}
+		report.Reclaimed += r.Reclaimed
+	}
+	if len(failed) != 0 {
+		return report, fmt.Errorf("prune remote servers error: %s", strings.Join(failed, "; "))
+	}
+	if !dryRun && kind == "containers" {
+		gCli.List(ctx)
+	}
+	return report, nil
+}
+
+func (gCli GrpcCli) Rename(ctx context.Context, containerID, name string) error {
+	return gCli.call(containerID, func(cli pb.ContainerServerClient, cid *pb.ContainerID) (*pb.Err, error) {
+		return cli.Rename(ctx, &pb.RenameOpts{C: cid, Name: name})
+	})
+}
+
+func (gCli GrpcCli) UpdateLabels(ctx context.Context, containerID string, update types.LabelsUpdate) error {
+	return gCli.call(containerID, func(cli pb.ContainerServerClient, cid *pb.ContainerID) (*pb.Err, error) {
+		return cli.UpdateLabels(ctx, &pb.LabelsUpdate{C: cid, Set: update.Set, Remove: update.Remove})
+	})
+}
+
+// call calls the server of the container, the error in the pb.Err is returned
+func (gCli GrpcCli) call(containerID string,
+	fn func(pb.ContainerServerClient, *pb.ContainerID) (*pb.Err, error)) error {
+	info := gCli.containers.Find(containerID)
+	if info.ID == "" {
+		return fmt.Errorf("container not found")
+	}
+	cli, exist := gCli.clients[info.LocServer]
+	if !exist {
+		return fmt.Errorf("location server [%s] not found", info.LocServer)
+	}
+	e, err := fn(cli.client, &pb.ContainerID{
+		Id:   containerID,
+		Auth: gCli.auth,
+	})
+	if err != nil {
+		return err
+	}
+	if e.GetErr() != "" {
+		return fmt.Errorf("%s", e.Err)
+	}
+	return nil
+}
+
 func (gCli GrpcCli) Exec(ctx context.Context, container types.Container) (types.TTY, error) {
-	logrus.Debugf("exec into container: %s (%s) (%v)",
+	log.Debugf("exec into container: %s (%s) (%v)",
 		container.ID, container.Shell, container.Exec)
 	if container.ID == "" {
 		return nil, fmt.Errorf("container not found")
@@ -255,20 +704,83 @@ func (gCli GrpcCli) Exec(ctx context.Context, container types.Container) (types.
 	}
 
 	// start to read and write using this exec wrapper
-	return newExecWrapper(execClient), nil
+	return newExecWrapper(execClient, !container.Exec.NoTTY), nil
+}
+
+func (gCli GrpcCli) Run(ctx context.Context, container types.Container) (types.RunResult, error) {
+	log.Debugf("run in container: %s (%s) (%v)",
+		container.ID, container.Shell, container.Exec)
+	if container.ID == "" {
+		return types.RunResult{}, fmt.Errorf("container not found")
+	}
+
+	cli, exist := gCli.clients[container.LocServer]
+	if !exist {
+		return types.RunResult{}, fmt.Errorf("location server [%s] not found", container.LocServer)
+	}
+
+	result, err := cli.client.Run(ctx, &pb.ExecOptions{
+		C:    util.ConvertTpContainer(container),
+		Auth: gCli.auth,
+	})
+	if err != nil {
+		return types.RunResult{}, err
+	}
+
+	return types.RunResult{
+		Stdout:    string(result.GetStdout()),
+		Stderr:    string(result.GetStderr()),
+		ExitCode:  int(result.GetExitCode()),
+		Truncated: result.GetTruncated(),
+		Duration:  result.GetDuration(),
+	}, nil
+}
+
+// Ping all the remote servers, fails if any of them is unreachable
+func (gCli GrpcCli) Ping(ctx context.Context) error {
+	if len(gCli.clients) == 0 {
+		return fmt.Errorf("no remote server available")
+	}
+	failed := []string{}
+	for addr, cli := range gCli.clients {
+		if _, err := cli.client.Ping(ctx, &pb.Empty{Auth: cli.auth}); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %s", addr, err))
+		}
+	}
+	if len(failed) != 0 {
+		return fmt.Errorf("ping remote servers error: %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// Version lists the upstream servers and the errors of their pings, their
+// versions are not known over grpc
+func (gCli GrpcCli) Version(ctx context.Context) ([]types.BackendVersion, error) {
+	versions := make([]types.BackendVersion, 0, len(gCli.clients))
+	for addr, cli := range gCli.clients {
+		v := types.BackendVersion{Name: "grpc", Server: addr}
+		if _, err := cli.client.Ping(ctx, &pb.Empty{Auth: cli.auth}); err != nil {
+			v.Error = err.Error()
+		}
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Server < versions[j].Server
+	})
+	return versions, nil
 }
 
 func (gCli GrpcCli) Close() error {
 	for addr, cli := range gCli.clients {
 		if err := cli.close(); err != nil {
-			logrus.Errorf("close %s error: %s", addr, err)
+			log.Errorf("close %s error: %s", addr, err)
 		}
 	}
 	return nil
 }
 
 func (gCli GrpcCli) Logs(ctx context.Context, opts types.LogOptions) (io.ReadCloser, error) {
-	logrus.Debugf("get container logs, id: %s", opts.ID)
+	log.Debugf("get container logs, id: %s", opts.ID)
 	info := gCli.containers.Find(opts.ID)
 	if info.ID == "" {
 		return nil, fmt.Errorf("container not found")
@@ -282,9 +794,15 @@ func (gCli GrpcCli) Logs(ctx context.Context, opts types.LogOptions) (io.ReadClo
 		return nil, fmt.Errorf("remote server %s is not ready: %s", info.LocServer, cli.state())
 	}
 
+	var since int64
+	if !opts.Since.IsZero() {
+		since = opts.Since.Unix()
+	}
 	logsClient, err := cli.client.Logs(ctx, &pb.LogOpts{
-		Follow: opts.Follow,
-		Tail:   opts.Tail,
+		Follow:     opts.Follow,
+		Tail:       opts.Tail,
+		Since:      since,
+		Timestamps: opts.Timestamps,
 		C: &pb.ContainerID{
 			Id:   info.ID,
 			Auth: gCli.auth,
@@ -294,7 +812,7 @@ func (gCli GrpcCli) Logs(ctx context.Context, opts types.LogOptions) (io.ReadClo
 		return nil, err
 	}
 
-	logrus.Debugf("start to pipe...")
+	log.Debugf("start to pipe...")
 	pr, pw := io.Pipe()
 	go func() {
 		defer pw.Close()
@@ -303,12 +821,12 @@ func (gCli GrpcCli) Logs(ctx context.Context, opts types.LogOptions) (io.ReadClo
 			in, err := logsClient.Recv()
 			if err != nil {
 				if grpc.ErrorDesc(err) != context.Canceled.Error() {
-					logrus.Errorf("logs recv error: %s", err)
+					log.Errorf("logs recv error: %s", err)
 				}
 				break
 			}
 			if _, err = pw.Write(in.GetIn()); err != nil {
-				logrus.Errorf("logs write to remote error: %s", err)
+				log.Errorf("logs write to remote error: %s", err)
 				break
 			}
 		}
@@ -316,3 +834,56 @@ func (gCli GrpcCli) Logs(ctx context.Context, opts types.LogOptions) (io.ReadClo
 
 	return pr, nil
 }
+
+func (gCli GrpcCli) Stats(ctx context.Context, containerID string) (<-chan types.Stats, error) {
+	info := gCli.containers.Find(containerID)
+	if info.ID == "" {
+		return nil, fmt.Errorf("container not found")
+	}
+
+	cli, exist := gCli.clients[info.LocServer]
+	if !exist {
+		return nil, fmt.Errorf("location server [%s] not found", info.LocServer)
+	}
+	if !cli.alive() {
+		return nil, fmt.Errorf("remote server %s is not ready: %s", info.LocServer, cli.state())
+	}
+
+	statsClient, err := cli.client.Stats(ctx, &pb.ContainerID{
+		Id:   info.ID,
+		Auth: gCli.auth,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan types.Stats)
+	go func() {
+		defer close(ch)
+		for {
+			s, err := statsClient.Recv()
+			if err != nil {
+				if err != io.EOF && ctx.Err() == nil {
+					log.Errorf("stats recv error: %s", err)
+				}
+				return
+			}
+			select {
+			case ch <- types.Stats{
+				Time:        time.Unix(0, s.Time),
+				CPUPercent:  s.CpuPercent,
+				MemoryUsage: s.MemoryUsage,
+				MemoryLimit: s.MemoryLimit,
+				NetworkRx:   s.NetworkRx,
+				NetworkTx:   s.NetworkTx,
+				BlockRead:   s.BlockRead,
+				BlockWrite:  s.BlockWrite,
+				PIDs:        s.Pids,
+			}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
@@ -1,6 +1,9 @@
 package grpc
 
 import (
+	"fmt"
+	"io"
+	"sync"
 	"time"
 
 	pb "github.com/wrfly/container-web-tty/proxy/pb"
@@ -10,15 +13,30 @@ import (
 type execWrapper struct {
 	exec       pb.ContainerServer_ExecClient
 	activeChan chan struct{}
+
+	// stderr of a non-tty exec
+	stderrR *io.PipeReader
+	stderrW *io.PipeWriter
+
+	// closed when the server sends the exit code or the stream breaks
+	exited   chan struct{}
+	exitOnce sync.Once
+	exitCode int
+	exitErr  error
 }
 
 type resizeFunction func(width int, height int) error
 
-func newExecWrapper(client pb.ContainerServer_ExecClient) *execWrapper {
-	return &execWrapper{
+func newExecWrapper(client pb.ContainerServer_ExecClient, tty bool) *execWrapper {
+	enj := &execWrapper{
 		exec:       client,
 		activeChan: make(chan struct{}, 5),
+		exited:     make(chan struct{}),
 	}
+	if !tty {
+		enj.stderrR, enj.stderrW = io.Pipe()
+	}
+	return enj
 }
 
 func (enj *execWrapper) Read(p []byte) (n int, err error) {
@@ -29,14 +47,33 @@ func (enj *execWrapper) Read(p []byte) (n int, err error) {
 		enj.activeChan <- struct{}{}
 	}()
 
-	execOpts, err := enj.exec.Recv()
-	if err != nil {
-		return 0, err
+	for {
+		execOpts, err := enj.exec.Recv()
+		if err != nil {
+			if enj.stderrW != nil {
+				enj.stderrW.CloseWithError(err)
+			}
+			enj.exit(0, err)
+			return 0, err
+		}
+		if execOpts.Cmd.GetExited() {
+			enj.exit(int(execOpts.Cmd.GetExitCode()), nil)
+			if enj.stderrW != nil {
+				enj.stderrW.Close()
+			}
+			return 0, io.EOF
+		}
+		if e := execOpts.Cmd.GetErr(); len(e) != 0 {
+			if enj.stderrW != nil {
+				enj.stderrW.Write(e)
+			}
+			continue
+		}
+		// logrus.Debugf("output: %s\n", execOpts.Cmd.Out)
+		out := execOpts.Cmd.GetOut()
+		copy(p, out)
+		return len(out), nil
 	}
-	// logrus.Debugf("output: %s\n", execOpts.Cmd.Out)
-	copy(p, execOpts.Cmd.Out)
-	return len(execOpts.Cmd.Out), nil
-
 }
 
 func (enj *execWrapper) Write(p []byte) (n int, err error) {
@@ -58,6 +95,29 @@ func (enj *execWrapper) ActiveChan() <-chan struct{} {
 	return enj.activeChan
 }
 
+func (enj *execWrapper) Stderr() io.Reader {
+	if enj.stderrR == nil {
+		return nil
+	}
+	return enj.stderrR
+}
+
+func (enj *execWrapper) exit(code int, err error) {
+	enj.exitOnce.Do(func() {
+		enj.exitCode, enj.exitErr = code, err
+		close(enj.exited)
+	})
+}
+
+func (enj *execWrapper) ExitCode() (int, error) {
+	select {
+	case <-enj.exited:
+		return enj.exitCode, enj.exitErr
+	case <-time.After(3 * time.Second):
+		return 0, fmt.Errorf("wait for exit code timeout")
+	}
+}
+
 func (enj *execWrapper) WindowTitleVariables() map[string]interface{} {
 	return map[string]interface{}{}
 }
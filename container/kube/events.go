@@ -0,0 +1,149 @@
+package kube
+
+import (
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/wrfly/container-web-tty/types"
+)
+
+// containerState is the last seen state of a container of a pod
+type containerState struct {
+	id       string
+	action   string
+	restarts int32
+	ready    bool
+}
+
+// watchEvents watches the pods and publishes the state changes of their
+// containers like the docker events: start, die, oom and destroy, and the
+// restarts and readiness transitions for the timelines
+func (kube KubeCli) watchEvents() {
+	// keyed by namespace/pod/container, the ID changes after a restart
+	states := make(map[string]containerState)
+
+	for {
+		w, err := kube.cli.CoreV1().Pods("").Watch(metav1.ListOptions{})
+		if err != nil {
+			log.Errorf("kubectl watch pods error: %s", err)
+			time.Sleep(time.Second * 5)
+			continue
+		}
+		for e := range w.ResultChan() {
+			pod, ok := e.Object.(*v1.Pod)
+			if !ok {
+				continue
+			}
+			for _, status := range pod.Status.ContainerStatuses {
+				id := trimContainerIDPrefix(status.ContainerID)
+				if id == "" {
+					continue
+				}
+				key := pod.Namespace + "/" + pod.Name + "/" + status.Name
+				if e.Type == watch.Deleted {
+					kube.events.Publish(types.Event{
+						Action: "destroy",
+						ID:     id,
+						Name:   status.Name,
+						Time:   time.Now(),
+					})
+					delete(states, key)
+					continue
+				}
+				states[key] = kube.publishChanges(pod, status, states[key])
+			}
+		}
+		// the watch is closed by the server from time to time
+		log.Debug("kubectl watch pods closed, rewatch")
+	}
+}
+
+// publishChanges publishes the events between the last seen state
+// and the status of the container, and returns the new state
+func (kube KubeCli) publishChanges(pod *v1.Pod, status v1.ContainerStatus, last containerState) containerState {
+	id := trimContainerIDPrefix(status.ContainerID)
+	state := containerState{
+		id:       id,
+		action:   last.action,
+		restarts: status.RestartCount,
+		ready:    status.Ready,
+	}
+	publish := func(action, detail string) {
+		kube.events.Publish(types.Event{
+			Action: action,
+			ID:     id,
+			Name:   status.Name,
+			Time:   time.Now(),
+			Detail: detail,
+		})
+	}
+
+	if last.id != "" && status.RestartCount > last.restarts {
+		terminated := status.LastTerminationState.Terminated
+		if terminated != nil && last.action == "start" {
+			// the death was missed, e.g. it's waiting in a crash loop
+			kube.events.Publish(types.Event{
+				Action: terminatedAction(terminated),
+				ID:     last.id,
+				Name:   status.Name,
+				Time:   terminated.FinishedAt.Time,
+				Detail: fmt.Sprintf("exit code %d", terminated.ExitCode),
+			})
+		}
+		// keep the timeline of the container after the restart
+		if id != last.id {
+			kube.events.Move(last.id, id)
+		}
+		publish("restart", fmt.Sprintf("restart %d", status.RestartCount))
+	}
+
+	if action := containerAction(status.State); action != "" &&
+		(action != last.action || id != last.id) {
+		detail := ""
+		if status.State.Terminated != nil {
+			detail = fmt.Sprintf("exit code %d", status.State.Terminated.ExitCode)
+		}
+		publish(action, detail)
+		state.action = action
+	}
+
+	if last.id != "" && status.Ready != last.ready &&
+		status.State.Running != nil && hasReadinessProbe(pod, status.Name) {
+		if status.Ready {
+			publish("health_status", "healthy")
+		} else {
+			publish("health_status", "unhealthy")
+		}
+	}
+	return state
+}
+
+func hasReadinessProbe(pod *v1.Pod, name string) bool {
+	for _, container := range pod.Spec.Containers {
+		if container.Name == name {
+			return container.ReadinessProbe != nil
+		}
+	}
+	return false
+}
+
+func containerAction(state v1.ContainerState) string {
+	switch {
+	case state.Running != nil:
+		return "start"
+	case state.Terminated != nil:
+		return terminatedAction(state.Terminated)
+	}
+	return ""
+}
+
+func terminatedAction(terminated *v1.ContainerStateTerminated) string {
+	if terminated.Reason == "OOMKilled" {
+		return "oom"
+	}
+	return "die"
+}
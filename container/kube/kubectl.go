@@ -3,32 +3,43 @@ package kube
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"path"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/sirupsen/logrus"
 	api "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ktypes "k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	restclient "k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
 
 	"github.com/wrfly/container-web-tty/config"
+	"github.com/wrfly/container-web-tty/event"
+	"github.com/wrfly/container-web-tty/logging"
 	"github.com/wrfly/container-web-tty/types"
+	"github.com/wrfly/container-web-tty/util"
 )
 
+var log = logging.Module("kube")
+
 type KubeCli struct {
 	cli        *kubernetes.Clientset
 	config     *restclient.Config
 	containers *types.Containers
+	events     *event.Hub
 }
 
-func NewCli(conf config.KubeConfig) (*KubeCli, error) {
+// NewCli returns the KubeCli, container events are published to the hub
+func NewCli(conf config.KubeConfig, events *event.Hub) (*KubeCli, error) {
 	// use the current context in kubeconfig
 	kubeConfig, err := clientcmd.BuildConfigFromFlags("", conf.ConfigPath)
 	if err != nil {
@@ -50,27 +61,31 @@ func NewCli(conf config.KubeConfig) (*KubeCli, error) {
 	for _, namespace := range namespaceList.Items {
 		ns = append(ns, namespace.Name)
 	}
-	logrus.Infof("New kube client: host [%s], namespaces [%s]",
+	log.Infof("New kube client: host [%s], namespaces [%s]",
 		kubeConfig.Host, strings.Join(ns, ","))
 
 	k := &KubeCli{
 		cli:        clientset,
 		containers: &types.Containers{},
 		config:     kubeConfig,
+		events:     events,
 	}
 	k.List(context.Background())
+	if events != nil {
+		go k.watchEvents()
+	}
 
 	return k, nil
 }
 
 func (kube KubeCli) GetInfo(ctx context.Context, cid string) types.Container {
 	if kube.containers.Len() == 0 {
-		logrus.Debugf("zero containers, get cid %s", cid)
+		log.Debugf("zero containers, get cid %s", cid)
 		kube.List(ctx)
 	}
 
 	// find in containers
-	logrus.Debugf("find cid: %s", cid)
+	log.Debugf("find cid: %s", cid)
 	container := kube.containers.Find(cid)
 	if container.ID != "" {
 		if container.Shell == "" {
@@ -105,7 +120,7 @@ func containerStartTime(state v1.ContainerState) time.Duration {
 func (kube KubeCli) List(ctx context.Context) []types.Container {
 	pods, err := kube.cli.CoreV1().Pods("").List(metav1.ListOptions{})
 	if err != nil {
-		logrus.Errorf("kubectl list pods error: %s", err)
+		log.Errorf("kubectl list pods error: %s", err)
 		return nil
 	}
 
@@ -153,8 +168,9 @@ func (kube KubeCli) List(ctx context.Context) []types.Container {
 				}(),
 				Image:   containerMap[container.Name].Image,
 				Command: containerMap[container.Name].Command,
+				Labels:  pod.GetLabels(),
 			}
-			logrus.Debugf("get container: %+v\n", c)
+			log.Debugf("get container: %+v\n", c)
 			containers = append(containers, c)
 		}
 	}
@@ -184,10 +200,10 @@ func (kube KubeCli) exist(ctx context.Context, containerID, path string) bool {
 		Param("stdin", "false").
 		Param("tty", "false")
 
-	logrus.Debugf("POST to %s", req.URL())
+	log.Debugf("POST to %s", req.URL())
 	exec, err := remotecommand.NewSPDYExecutor(kube.config, "POST", req.URL())
 	if err != nil {
-		logrus.Errorf("exist exec: setup executor error: [%v]", err)
+		log.Errorf("exist exec: setup executor error: [%v]", err)
 		return false
 	}
 
@@ -196,20 +212,20 @@ func (kube KubeCli) exist(ctx context.Context, containerID, path string) bool {
 		Tty:    false,
 	})
 	if err != nil {
-		logrus.Debugf("exist exec error: [%v]", err)
+		log.Debugf("exist exec error: [%v]", err)
 		return false
 	}
 
-	logrus.Debugf("container %s exist %s", containerID, path)
+	log.Debugf("container %s exist %s", containerID, path)
 	return true
 
 }
 
 func (kube KubeCli) getShell(ctx context.Context, cid string) string {
-	logrus.Debugf("get container's shell path, cid: %s", cid)
+	log.Debugf("get container's shell path, cid: %s", cid)
 	for _, sh := range config.SHELL_LIST {
 		if kube.exist(ctx, cid, sh) {
-			logrus.Debugf("get shell path %s", sh)
+			log.Debugf("get shell path %s", sh)
 			return sh
 		}
 	}
@@ -229,8 +245,267 @@ func (kube KubeCli) Restart(ctx context.Context, cid string) error {
 	return nil
 }
 
+func (kube KubeCli) Pause(ctx context.Context, cid string) error {
+	return fmt.Errorf("pause is not supported by the kube backend")
+}
+
+func (kube KubeCli) Unpause(ctx context.Context, cid string) error {
+	return fmt.Errorf("unpause is not supported by the kube backend")
+}
+
+func (kube KubeCli) Kill(ctx context.Context, cid, signal string) error {
+	return fmt.Errorf("kill is not supported by the kube backend")
+}
+
+// Top runs ps in the container, there is no API of the processes of a pod
+func (kube KubeCli) Top(ctx context.Context, cid string) (types.Processes, error) {
+	c := kube.GetInfo(ctx, cid)
+	if c.Shell == "" {
+		return types.Processes{}, fmt.Errorf("cannot find a valid shell in container %s", cid)
+	}
+	c.Exec.Cmd = "ps -o pid,user,time,args 2>/dev/null || ps"
+	result, err := kube.Run(ctx, c)
+	if err != nil {
+		return types.Processes{}, err
+	}
+	if result.ExitCode != 0 {
+		return types.Processes{}, fmt.Errorf("ps exited with %d: %s", result.ExitCode, result.Stderr)
+	}
+	return util.ParsePS(result.Stdout), nil
+}
+
+// Inspect returns the env and mounts of the pod spec, the values
+// from the secrets and config maps are not resolved
+func (kube KubeCli) Inspect(ctx context.Context, cid string) (types.ContainerDetail, error) {
+	c := kube.GetInfo(ctx, cid)
+	if c.PodName == "" || c.Namespace == "" {
+		return types.ContainerDetail{}, fmt.Errorf("PodName or Namespace is empty")
+	}
+	pod, err := kube.cli.CoreV1().Pods(c.Namespace).Get(c.PodName, metav1.GetOptions{})
+	if err != nil {
+		return types.ContainerDetail{}, err
+	}
+
+	detail := types.ContainerDetail{
+		Env:    []types.EnvVar{},
+		Mounts: []types.Mount{},
+	}
+	for _, container := range pod.Spec.Containers {
+		if container.Name != c.ContainerName {
+			continue
+		}
+		for _, env := range container.Env {
+			detail.Env = append(detail.Env, types.EnvVar{
+				Name:  env.Name,
+				Value: envValue(env),
+			})
+		}
+		for _, m := range container.VolumeMounts {
+			mount := types.Mount{
+				Type:        "volume",
+				Source:      m.Name,
+				Destination: m.MountPath,
+				ReadOnly:    m.ReadOnly,
+			}
+			for _, v := range pod.Spec.Volumes {
+				if v.Name == m.Name {
+					mount.Type, mount.Source = volumeSource(v)
+				}
+			}
+			detail.Mounts = append(detail.Mounts, mount)
+		}
+		detail.Health = probeHealth(pod, container)
+	}
+	return detail, nil
+}
+
+// probeHealth returns the readiness probe (or the liveness probe) of the
+// container as its healthcheck, the results of the probes are not kept
+// by kube but the readiness, nil if the container has no probe
+func probeHealth(pod *api.Pod, container api.Container) *types.Health {
+	probe := container.ReadinessProbe
+	if probe == nil {
+		probe = container.LivenessProbe
+	}
+	if probe == nil {
+		return nil
+	}
+	health := &types.Health{Probes: []types.HealthProbe{}}
+	if probe.Exec != nil {
+		health.Cmd = util.ShellJoin(probe.Exec.Command)
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name != container.Name {
+			continue
+		}
+		switch {
+		case status.Ready:
+			health.Status = "healthy"
+		case status.State.Running != nil:
+			health.Status = "unhealthy"
+		default:
+			health.Status = "starting"
+		}
+	}
+	return health
+}
+
+func envValue(env v1.EnvVar) string {
+	from := env.ValueFrom
+	switch {
+	case from == nil:
+		return env.Value
+	case from.SecretKeyRef != nil:
+		return fmt.Sprintf("<secret %s/%s>", from.SecretKeyRef.Name, from.SecretKeyRef.Key)
+	case from.ConfigMapKeyRef != nil:
+		return fmt.Sprintf("<config map %s/%s>", from.ConfigMapKeyRef.Name, from.ConfigMapKeyRef.Key)
+	case from.FieldRef != nil:
+		return fmt.Sprintf("<field %s>", from.FieldRef.FieldPath)
+	case from.ResourceFieldRef != nil:
+		return fmt.Sprintf("<resource %s>", from.ResourceFieldRef.Resource)
+	}
+	return ""
+}
+
+func volumeSource(v v1.Volume) (string, string) {
+	switch {
+	case v.HostPath != nil:
+		return "hostPath", v.HostPath.Path
+	case v.EmptyDir != nil:
+		return "emptyDir", v.Name
+	case v.Secret != nil:
+		return "secret", v.Secret.SecretName
+	case v.ConfigMap != nil:
+		return "configMap", v.ConfigMap.Name
+	case v.PersistentVolumeClaim != nil:
+		return "persistentVolumeClaim", v.PersistentVolumeClaim.ClaimName
+	}
+	return "volume", v.Name
+}
+
+// CopyFrom runs tar in the container, like kubectl cp
+func (kube KubeCli) CopyFrom(ctx context.Context, cid, src string) (io.ReadCloser, error) {
+	c := kube.GetInfo(ctx, cid)
+	if c.PodName == "" || c.Namespace == "" {
+		return nil, fmt.Errorf("PodName or Namespace is empty")
+	}
+
+	src = path.Clean(src)
+	pr, pw := io.Pipe()
+	go func() {
+		err := kube.stream(ctx, c, []string{"tar", "cf", "-", "-C", path.Dir(src), path.Base(src)}, nil, pw)
+		pw.CloseWithError(err)
+	}()
+	return pr, nil
+}
+
+func (kube KubeCli) CopyTo(ctx context.Context, cid, dir string, content io.Reader) error {
+	c := kube.GetInfo(ctx, cid)
+	if c.PodName == "" || c.Namespace == "" {
+		return fmt.Errorf("PodName or Namespace is empty")
+	}
+	return kube.stream(ctx, c, []string{"tar", "xf", "-", "-C", dir}, content, ioutil.Discard)
+}
+
+// stream runs the command in the container without a tty,
+// the error has the stderr if the command fails
+func (kube KubeCli) stream(ctx context.Context, c types.Container, cmd []string, stdin io.Reader, stdout io.Writer) error {
+	req := kube.cli.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(c.PodName).
+		Namespace(c.Namespace).
+		SubResource("exec").
+		Param("container", c.ContainerName).
+		Param("stdin", strconv.FormatBool(stdin != nil)).
+		Param("stdout", "true").
+		Param("stderr", "true").
+		Param("tty", "false")
+	for _, arg := range cmd {
+		req = req.Param("command", arg)
+	}
+
+	exec, err := remotecommand.NewSPDYExecutor(kube.config, "POST", req.URL())
+	if err != nil {
+		return err
+	}
+	stderr := util.NewCappedBuffer(4096)
+	streamErr := make(chan error, 1)
+	go func() {
+		streamErr <- exec.Stream(remotecommand.StreamOptions{
+			Stdin:  stdin,
+			Stdout: stdout,
+			Stderr: stderr,
+		})
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-streamErr:
+		if err != nil && stderr.Len() != 0 {
+			return fmt.Errorf("%s: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return err
+	}
+}
+
+func (kube KubeCli) Diff(ctx context.Context, cid string) ([]types.Change, error) {
+	return nil, fmt.Errorf("diff is not supported by the kube backend")
+}
+
+func (kube KubeCli) Commit(ctx context.Context, cid string, opts types.CommitOptions) (string, error) {
+	return "", fmt.Errorf("commit is not supported by the kube backend")
+}
+
+func (kube KubeCli) Create(ctx context.Context, opts types.CreateOptions) (string, error) {
+	return "", fmt.Errorf("create is not supported by the kube backend")
+}
+
+// Debug is not supported, the ephemeral containers are not
+// available in this version of the kube API
+func (kube KubeCli) Debug(ctx context.Context, cid string, opts types.DebugOptions) (string, error) {
+	return "", fmt.Errorf("debug container is not supported by the kube backend")
+}
+
+func (kube KubeCli) Prune(ctx context.Context, kind string, dryRun bool) (types.PruneReport, error) {
+	return types.PruneReport{}, fmt.Errorf("prune is not supported by the kube backend")
+}
+
+func (kube KubeCli) Rename(ctx context.Context, cid, name string) error {
+	return fmt.Errorf("rename is not supported by the kube backend")
+}
+
+// UpdateLabels patches the labels of the pod of the container
+func (kube KubeCli) UpdateLabels(ctx context.Context, cid string, update types.LabelsUpdate) error {
+	c := kube.GetInfo(ctx, cid)
+	if c.PodName == "" || c.Namespace == "" {
+		return fmt.Errorf("PodName or Namespace is empty")
+	}
+
+	// null removes the label in a merge patch
+	labels := map[string]interface{}{}
+	for _, k := range update.Remove {
+		labels[k] = nil
+	}
+	for k, v := range update.Set {
+		labels[k] = v
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"labels": labels},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = kube.cli.CoreV1().Pods(c.Namespace).Patch(c.PodName, ktypes.MergePatchType, patch)
+	if err != nil {
+		return err
+	}
+	kube.List(ctx)
+	return nil
+}
+
 func (kube KubeCli) Exec(ctx context.Context, c types.Container) (types.TTY, error) {
-	logrus.Debugf("exec pod: %v", c)
+	log.Debugf("exec pod: %v", c)
 	if c.PodName == "" || c.Namespace == "" {
 		return nil, fmt.Errorf("PodName or Namespace is empty")
 	}
@@ -248,18 +523,34 @@ func (kube KubeCli) Exec(ctx context.Context, c types.Container) (types.TTY, err
 	if opts := c.Exec; opts.Cmd != "" {
 		cmds = []string{c.Shell, "-l", opts.Cmd}
 	}
-	logrus.Debugf("exec with cmd: %v", cmds)
-
+	log.Debugf("exec with cmd: %v", cmds)
+
+	tty := !c.Exec.NoTTY
+	stdin := true
+	subResource := "exec"
+	if c.Exec.Attach {
+		// the tty and stdin of the main process are in the spec
+		subResource, stdin, tty, cmds = "attach", c.Exec.AttachStdin, false, nil
+		for _, container := range pod.Spec.Containers {
+			if container.Name == c.ContainerName {
+				tty = container.TTY
+				if stdin && !container.Stdin {
+					return nil, fmt.Errorf("the stdin of container %s is not open", c.ContainerName)
+				}
+			}
+		}
+	}
 	restClient := kube.cli.CoreV1().RESTClient()
 	req := restClient.Post().
 		Resource("pods").
 		Name(c.PodName).
 		Namespace(c.Namespace).
-		SubResource("exec").
+		SubResource(subResource).
 		Param("container", c.ContainerName).
-		Param("stdin", "true").
+		Param("stdin", strconv.FormatBool(stdin)).
 		Param("stdout", "true").
-		Param("tty", "true")
+		Param("stderr", strconv.FormatBool(!tty)).
+		Param("tty", strconv.FormatBool(tty))
 	// TODO: k8s exec user & env
 
 	// set commands
@@ -267,34 +558,122 @@ func (kube KubeCli) Exec(ctx context.Context, c types.Container) (types.TTY, err
 		req.Param("command", cmd)
 	}
 
-	enj := newInjector(ctx)
+	enj := newInjector(ctx, tty)
+	enj.attach = c.Exec.Attach
 
-	logrus.Debugf("POST to %s", req.URL())
+	log.Debugf("POST to %s", req.URL())
 	exec, err := remotecommand.NewSPDYExecutor(kube.config, "POST", req.URL())
 	if err != nil {
 		return nil, err
 	}
 
+	streamOpts := remotecommand.StreamOptions{
+		Stdin:             enj.ttyIn,
+		Stdout:            enj.ttyOut,
+		Tty:               tty,
+		TerminalSizeQueue: enj.sq,
+	}
+	if !tty {
+		streamOpts.Stderr = enj.ttyErr
+		streamOpts.TerminalSizeQueue = nil
+	}
+	if !stdin {
+		streamOpts.Stdin = nil
+	}
+
 	go func() {
-		err = exec.Stream(remotecommand.StreamOptions{
-			Stdin:             enj.ttyIn,
-			Stdout:            enj.ttyOut,
-			Tty:               true,
-			TerminalSizeQueue: enj.sq,
-		})
-		if err != nil {
-			logrus.Errorf("exec error: [%v]", err)
+		err := exec.Stream(streamOpts)
+		if _, exited := err.(utilexec.ExitError); err != nil && !exited {
+			log.Errorf("exec error: [%v]", err)
 		}
-		logrus.Debug("exec done")
+		log.Debug("exec done")
+		enj.done(err)
 		// close in and out
 		enj.ttyIn.Close()
 		enj.ttyOut.Close()
+		if enj.ttyErr != nil {
+			enj.ttyErr.Close()
+		}
 	}()
 
-	logrus.Debug("return enj")
+	log.Debug("return enj")
 	return &enj, nil
 }
 
+func (kube KubeCli) Run(ctx context.Context, c types.Container) (types.RunResult, error) {
+	log.Debugf("run in pod: %v", c)
+	if c.PodName == "" || c.Namespace == "" {
+		return types.RunResult{}, fmt.Errorf("PodName or Namespace is empty")
+	}
+
+	req := kube.cli.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(c.PodName).
+		Namespace(c.Namespace).
+		SubResource("exec").
+		Param("container", c.ContainerName).
+		Param("command", c.Shell).
+		Param("command", "-c").
+		Param("command", c.Exec.Cmd).
+		Param("stdin", "false").
+		Param("stdout", "true").
+		Param("stderr", "true").
+		Param("tty", "false")
+
+	exec, err := remotecommand.NewSPDYExecutor(kube.config, "POST", req.URL())
+	if err != nil {
+		return types.RunResult{}, err
+	}
+
+	start := time.Now()
+	stdout := util.NewCappedBuffer(types.MaxRunOutput)
+	stderr := util.NewCappedBuffer(types.MaxRunOutput)
+	streamErr := make(chan error, 1)
+	go func() {
+		streamErr <- exec.Stream(remotecommand.StreamOptions{
+			Stdout: stdout,
+			Stderr: stderr,
+		})
+	}()
+
+	exitCode := 0
+	select {
+	case <-ctx.Done():
+		return types.RunResult{}, ctx.Err()
+	case err := <-streamErr:
+		if exitErr, ok := err.(utilexec.ExitError); ok {
+			exitCode = exitErr.ExitStatus()
+		} else if err != nil {
+			return types.RunResult{}, err
+		}
+	}
+
+	return types.RunResult{
+		Stdout:    stdout.String(),
+		Stderr:    stderr.String(),
+		ExitCode:  exitCode,
+		Truncated: stdout.Truncated || stderr.Truncated,
+		Duration:  time.Since(start).String(),
+	}, nil
+}
+
+func (kube KubeCli) Ping(ctx context.Context) error {
+	_, err := kube.cli.Discovery().ServerVersion()
+	return err
+}
+
+func (kube KubeCli) Version(ctx context.Context) ([]types.BackendVersion, error) {
+	v, err := kube.cli.Discovery().ServerVersion()
+	if err != nil {
+		return nil, err
+	}
+	return []types.BackendVersion{{
+		Name:     "kubernetes",
+		Version:  v.GitVersion,
+		Platform: v.Platform,
+	}}, nil
+}
+
 func (kube KubeCli) Close() error {
 	// no need to close
 	return nil
@@ -302,7 +681,7 @@ func (kube KubeCli) Close() error {
 
 func (kube KubeCli) Logs(ctx context.Context, opts types.LogOptions) (io.ReadCloser, error) {
 	c := kube.GetInfo(ctx, opts.ID)
-	logrus.Debugf("get pod logs: %v", c)
+	log.Debugf("get pod logs: %v", c)
 	if c.PodName == "" || c.Namespace == "" {
 		return nil, fmt.Errorf("PodName or Namespace is empty")
 	}
@@ -324,17 +703,14 @@ func (kube KubeCli) Logs(ctx context.Context, opts types.LogOptions) (io.ReadClo
 		SubResource("log").
 		Param("follow", strconv.FormatBool(opts.Follow)).
 		Param("container", c.ContainerName).
-		Param("tailLines", opts.Tail)
+		Param("tailLines", opts.Tail).
+		Param("timestamps", strconv.FormatBool(opts.Timestamps))
 
 	// Param("previous", strconv.FormatBool(logOptions.Previous)).
-	// Param("timestamps", strconv.FormatBool(logOptions.Timestamps))
 
-	// if logOptions.SinceSeconds != nil {
-	// 	req.Param("sinceSeconds", strconv.FormatInt(*logOptions.SinceSeconds, 10))
-	// }
-	// if logOptions.SinceTime != nil {
-	// 	req.Param("sinceTime", logOptions.SinceTime.Format(time.RFC3339))
-	// }
+	if !opts.Since.IsZero() {
+		req.Param("sinceTime", opts.Since.UTC().Format(time.RFC3339))
+	}
 	// if logOptions.LimitBytes != nil {
 	// 	req.Param("limitBytes", strconv.FormatInt(*logOptions.LimitBytes, 10))
 	// }
@@ -0,0 +1,106 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/wrfly/container-web-tty/types"
+)
+
+// statsInterval is the resolution of metrics-server
+const statsInterval = 15 * time.Second
+
+// podMetrics is the PodMetrics of metrics.k8s.io
+type podMetrics struct {
+	Timestamp  time.Time `json:"timestamp"`
+	Containers []struct {
+		Name  string `json:"name"`
+		Usage struct {
+			CPU    resource.Quantity `json:"cpu"`
+			Memory resource.Quantity `json:"memory"`
+		} `json:"usage"`
+	} `json:"containers"`
+}
+
+// Stats polls the metrics API (metrics-server), only the
+// CPU and memory of the containers are known
+func (kube KubeCli) Stats(ctx context.Context, containerID string) (<-chan types.Stats, error) {
+	c := kube.GetInfo(ctx, containerID)
+	if c.PodName == "" || c.Namespace == "" {
+		return nil, fmt.Errorf("PodName or Namespace is empty")
+	}
+	pod, err := kube.cli.CoreV1().Pods(c.Namespace).
+		Get(c.PodName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var memoryLimit uint64
+	for _, container := range pod.Spec.Containers {
+		if container.Name == c.ContainerName {
+			if limit, ok := container.Resources.Limits["memory"]; ok {
+				memoryLimit = uint64(limit.Value())
+			}
+		}
+	}
+
+	// fail fast if metrics-server is not installed
+	first, err := kube.podMetrics(c)
+	if err != nil {
+		return nil, fmt.Errorf("get metrics of pod %s error: %s", c.PodName, err)
+	}
+
+	ch := make(chan types.Stats)
+	go func() {
+		defer close(ch)
+		ticker := time.NewTicker(statsInterval)
+		defer ticker.Stop()
+		s := first
+		for {
+			s.MemoryLimit = memoryLimit
+			select {
+			case ch <- s:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+			var err error
+			if s, err = kube.podMetrics(c); err != nil {
+				log.Errorf("get metrics of pod %s error: %s", c.PodName, err)
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+func (kube KubeCli) podMetrics(c types.Container) (types.Stats, error) {
+	body, err := kube.cli.Discovery().RESTClient().Get().
+		AbsPath("/apis/metrics.k8s.io/v1beta1/namespaces", c.Namespace, "pods", c.PodName).
+		DoRaw()
+	if err != nil {
+		return types.Stats{}, err
+	}
+	var m podMetrics
+	if err := json.Unmarshal(body, &m); err != nil {
+		return types.Stats{}, err
+	}
+	for _, container := range m.Containers {
+		if container.Name == c.ContainerName {
+			return types.Stats{
+				Time:        m.Timestamp,
+				CPUPercent:  float64(container.Usage.CPU.MilliValue()) / 10,
+				MemoryUsage: uint64(container.Usage.Memory.Value()),
+			}, nil
+		}
+	}
+	return types.Stats{}, fmt.Errorf("container %s not found in the metrics", c.ContainerName)
+}
@@ -2,11 +2,12 @@ package kube
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"time"
 
-	"github.com/sirupsen/logrus"
 	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
 )
 
 type execInjector struct {
@@ -15,11 +16,23 @@ type execInjector struct {
 	ttyIn  io.ReadCloser
 	ttyOut io.WriteCloser
 
+	// separate stderr of a non-tty exec
+	e      io.ReadCloser
+	ttyErr io.WriteCloser
+
 	sq         *sizeQueue
 	activeChan chan struct{}
+
+	// closed when the stream is done with the exit code set
+	exited   chan struct{}
+	exitCode int
+	exitErr  error
+
+	// attached to the main process, which is kept running on exit
+	attach bool
 }
 
-func newInjector(ctx context.Context) execInjector {
+func newInjector(ctx context.Context, tty bool) execInjector {
 
 	r, out := io.Pipe()
 	in, w := io.Pipe()
@@ -34,6 +47,10 @@ func newInjector(ctx context.Context) execInjector {
 		ttyOut:     out,
 		sq:         sq,
 		activeChan: make(chan struct{}, 5),
+		exited:     make(chan struct{}),
+	}
+	if !tty {
+		enj.e, enj.ttyErr = io.Pipe()
 	}
 
 	return enj
@@ -55,12 +72,18 @@ func (enj *execInjector) Write(p []byte) (n int, err error) {
 }
 
 func (enj *execInjector) Exit() error {
-	enj.Write([]byte("exit\n"))
+	if !enj.attach {
+		enj.Write([]byte("exit\n"))
+	}
 
 	enj.r.Close()
 	enj.w.Close()
 	enj.ttyIn.Close()
 	enj.ttyOut.Close()
+	if enj.e != nil {
+		enj.e.Close()
+		enj.ttyErr.Close()
+	}
 	enj.sq.close()
 	close(enj.activeChan)
 
@@ -71,12 +94,42 @@ func (enj *execInjector) ActiveChan() <-chan struct{} {
 	return enj.activeChan
 }
 
+func (enj *execInjector) Stderr() io.Reader {
+	if enj.e == nil {
+		return nil
+	}
+	return enj.e
+}
+
+// done records the result of the stream
+func (enj *execInjector) done(err error) {
+	if exitErr, ok := err.(utilexec.ExitError); ok {
+		enj.exitCode = exitErr.ExitStatus()
+	} else {
+		enj.exitErr = err
+	}
+	close(enj.exited)
+}
+
+func (enj *execInjector) ExitCode() (int, error) {
+	select {
+	case <-enj.exited:
+		return enj.exitCode, enj.exitErr
+	case <-time.After(3 * time.Second):
+		return 0, fmt.Errorf("wait for exit code timeout")
+	}
+}
+
 func (enj *execInjector) WindowTitleVariables() map[string]interface{} {
 	return map[string]interface{}{}
 }
 
 func (enj *execInjector) ResizeTerminal(width int, height int) (err error) {
-	logrus.Debugf("resize terminal to: %dx%d", width, height)
+	if enj.e != nil {
+		// no tty, nothing to resize
+		return nil
+	}
+	log.Debugf("resize terminal to: %dx%d", width, height)
 	for i := 0; i < 3; i++ {
 		// there is a delay somehow, use this trick method to avoid it
 		enj.sq.resize(width, height)
@@ -0,0 +1,298 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/wrfly/container-web-tty/types"
+)
+
+// lazyRetry is the min interval of creating a failed backend again
+const lazyRetry = 5 * time.Second
+
+var errBackendCreating = errors.New("the backend is being created")
+
+// lazyCli creates the backend in the background so the server starts
+// at once, the calls wait for the creation until their contexts are done,
+// and a failed creation is retried by the calls after the retry interval
+type lazyCli struct {
+	create func() (Cli, error)
+	retry  time.Duration
+
+	m sync.Mutex
+	// closed once the creation ends
+	created  chan struct{}
+	cli      Cli
+	err      error
+	failedAt time.Time
+}
+
+// NewLazyCli creates the backend with create in the background
+func NewLazyCli(create func() (Cli, error)) Cli {
+	l := &lazyCli{create: create, retry: lazyRetry}
+	l.m.Lock()
+	l.start()
+	l.m.Unlock()
+	return l
+}
+
+// start creates the backend, l.m is held
+func (l *lazyCli) start() {
+	created := make(chan struct{})
+	l.created = created
+	go func() {
+		start := time.Now()
+		cli, err := l.create()
+
+		l.m.Lock()
+		if err != nil {
+			// not a typed nil of the backend
+			l.cli, l.err = nil, err
+			l.failedAt = time.Now()
+			log.Errorf("create backend error: %s", err)
+		} else {
+			l.cli, l.err = cli, nil
+			log.Infof("backend created in %s", time.Since(start))
+		}
+		l.m.Unlock()
+		close(created)
+	}()
+}
+
+// backend waits for the backend until ctx is done
+func (l *lazyCli) backend(ctx context.Context) (Cli, error) {
+	l.m.Lock()
+	created := l.created
+	l.m.Unlock()
+	select {
+	case <-created:
+	case <-ctx.Done():
+		return nil, errBackendCreating
+	}
+
+	l.m.Lock()
+	defer l.m.Unlock()
+	if l.cli != nil {
+		return l.cli, nil
+	}
+	err := fmt.Errorf("create backend error: %s", l.err)
+	if l.created == created && time.Since(l.failedAt) >= l.retry {
+		l.start()
+	}
+	return nil, err
+}
+
+func (l *lazyCli) GetInfo(ctx context.Context, containerID string) types.Container {
+	cli, err := l.backend(ctx)
+	if err != nil {
+		return types.Container{}
+	}
+	return cli.GetInfo(ctx, containerID)
+}
+
+func (l *lazyCli) List(ctx context.Context) []types.Container {
+	cli, err := l.backend(ctx)
+	if err != nil {
+		return nil
+	}
+	return cli.List(ctx)
+}
+
+func (l *lazyCli) Start(ctx context.Context, containerID string) error {
+	cli, err := l.backend(ctx)
+	if err != nil {
+		return err
+	}
+	return cli.Start(ctx, containerID)
+}
+
+func (l *lazyCli) Stop(ctx context.Context, containerID string) error {
+	cli, err := l.backend(ctx)
+	if err != nil {
+		return err
+	}
+	return cli.Stop(ctx, containerID)
+}
+
+func (l *lazyCli) Restart(ctx context.Context, containerID string) error {
+	cli, err := l.backend(ctx)
+	if err != nil {
+		return err
+	}
+	return cli.Restart(ctx, containerID)
+}
+
+func (l *lazyCli) Pause(ctx context.Context, containerID string) error {
+	cli, err := l.backend(ctx)
+	if err != nil {
+		return err
+	}
+	return cli.Pause(ctx, containerID)
+}
+
+func (l *lazyCli) Unpause(ctx context.Context, containerID string) error {
+	cli, err := l.backend(ctx)
+	if err != nil {
+		return err
+	}
+	return cli.Unpause(ctx, containerID)
+}
+
+func (l *lazyCli) Kill(ctx context.Context, containerID, signal string) error {
+	cli, err := l.backend(ctx)
+	if err != nil {
+		return err
+	}
+	return cli.Kill(ctx, containerID, signal)
+}
+
+func (l *lazyCli) Rename(ctx context.Context, containerID, name string) error {
+	cli, err := l.backend(ctx)
+	if err != nil {
+		return err
+	}
+	return cli.Rename(ctx, containerID, name)
+}
+
+func (l *lazyCli) Commit(ctx context.Context, containerID string, opts types.CommitOptions) (string, error) {
+	cli, err := l.backend(ctx)
+	if err != nil {
+		return "", err
+	}
+	return cli.Commit(ctx, containerID, opts)
+}
+
+func (l *lazyCli) Create(ctx context.Context, opts types.CreateOptions) (string, error) {
+	cli, err := l.backend(ctx)
+	if err != nil {
+		return "", err
+	}
+	return cli.Create(ctx, opts)
+}
+
+func (l *lazyCli) Debug(ctx context.Context, containerID string, opts types.DebugOptions) (string, error) {
+	cli, err := l.backend(ctx)
+	if err != nil {
+		return "", err
+	}
+	return cli.Debug(ctx, containerID, opts)
+}
+
+func (l *lazyCli) Top(ctx context.Context, containerID string) (types.Processes, error) {
+	cli, err := l.backend(ctx)
+	if err != nil {
+		return types.Processes{}, err
+	}
+	return cli.Top(ctx, containerID)
+}
+
+func (l *lazyCli) Diff(ctx context.Context, containerID string) ([]types.Change, error) {
+	cli, err := l.backend(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return cli.Diff(ctx, containerID)
+}
+
+func (l *lazyCli) Inspect(ctx context.Context, containerID string) (types.ContainerDetail, error) {
+	cli, err := l.backend(ctx)
+	if err != nil {
+		return types.ContainerDetail{}, err
+	}
+	return cli.Inspect(ctx, containerID)
+}
+
+func (l *lazyCli) CopyFrom(ctx context.Context, containerID, path string) (io.ReadCloser, error) {
+	cli, err := l.backend(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return cli.CopyFrom(ctx, containerID, path)
+}
+
+func (l *lazyCli) CopyTo(ctx context.Context, containerID, dir string, content io.Reader) error {
+	cli, err := l.backend(ctx)
+	if err != nil {
+		return err
+	}
+	return cli.CopyTo(ctx, containerID, dir, content)
+}
+
+func (l *lazyCli) UpdateLabels(ctx context.Context, containerID string, update types.LabelsUpdate) error {
+	cli, err := l.backend(ctx)
+	if err != nil {
+		return err
+	}
+	return cli.UpdateLabels(ctx, containerID, update)
+}
+
+func (l *lazyCli) Prune(ctx context.Context, kind string, dryRun bool) (types.PruneReport, error) {
+	cli, err := l.backend(ctx)
+	if err != nil {
+		return types.PruneReport{}, err
+	}
+	return cli.Prune(ctx, kind, dryRun)
+}
+
+func (l *lazyCli) Exec(ctx context.Context, container types.Container) (types.TTY, error) {
+	cli, err := l.backend(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return cli.Exec(ctx, container)
+}
+
+func (l *lazyCli) Run(ctx context.Context, container types.Container) (types.RunResult, error) {
+	cli, err := l.backend(ctx)
+	if err != nil {
+		return types.RunResult{}, err
+	}
+	return cli.Run(ctx, container)
+}
+
+func (l *lazyCli) Ping(ctx context.Context) error {
+	cli, err := l.backend(ctx)
+	if err != nil {
+		return err
+	}
+	return cli.Ping(ctx)
+}
+
+func (l *lazyCli) Version(ctx context.Context) ([]types.BackendVersion, error) {
+	cli, err := l.backend(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return cli.Version(ctx)
+}
+
+// Close closes the backend if it's created
+func (l *lazyCli) Close() error {
+	l.m.Lock()
+	cli := l.cli
+	l.m.Unlock()
+	if cli == nil {
+		return nil
+	}
+	return cli.Close()
+}
+
+func (l *lazyCli) Logs(ctx context.Context, opts types.LogOptions) (io.ReadCloser, error) {
+	cli, err := l.backend(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return cli.Logs(ctx, opts)
+}
+
+func (l *lazyCli) Stats(ctx context.Context, containerID string) (<-chan types.Stats, error) {
+	cli, err := l.backend(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return cli.Stats(ctx, containerID)
+}
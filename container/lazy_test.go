@@ -0,0 +1,42 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLazyCli(t *testing.T) {
+	release := make(chan struct{})
+	fail := true
+	cli := NewLazyCli(func() (Cli, error) {
+		<-release
+		if fail {
+			return nil, errors.New("daemon is down")
+		}
+		return &slowCli{}, nil
+	}).(*lazyCli)
+	cli.retry = 0
+
+	// the calls wait until their contexts are done
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := cli.Start(ctx, "abc"); err != errBackendCreating {
+		t.Fatalf("expect the backend being created, got %v", err)
+	}
+
+	// the failed creation is retried by the next call
+	release <- struct{}{}
+	if err := cli.Start(context.Background(), "abc"); err == nil {
+		t.Fatal("expect the error of the creation")
+	}
+	fail = false
+	release <- struct{}{}
+	if err := cli.Start(context.Background(), "abc"); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(cli.List(context.Background())); n != 1 {
+		t.Fatalf("unexpected list: %d", n)
+	}
+}
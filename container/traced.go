@@ -0,0 +1,83 @@
+package container
+
+import (
+	"context"
+	"sync"
+
+	"github.com/wrfly/container-web-tty/tracing"
+	"github.com/wrfly/container-web-tty/types"
+)
+
+// tracedCli records the spans of the calls of the backend which the
+// sessions wait for, the list, the inspect and the exec until the stream
+// of it ends
+type tracedCli struct {
+	Cli
+}
+
+// NewTracedCli records the spans of the cli, see tracing.Setup
+func NewTracedCli(cli Cli) Cli {
+	return &tracedCli{Cli: cli}
+}
+
+func (c *tracedCli) List(ctx context.Context) []types.Container {
+	ctx, span := tracing.Start(ctx, "container.list", tracing.KindInternal)
+	defer span.End()
+	containers := c.Cli.List(ctx)
+	span.SetAttr("containers", len(containers))
+	return containers
+}
+
+func (c *tracedCli) GetInfo(ctx context.Context, containerID string) types.Container {
+	ctx, span := tracing.Start(ctx, "container.get_info", tracing.KindInternal)
+	defer span.End()
+	span.SetAttr("container.id", containerID)
+	return c.Cli.GetInfo(ctx, containerID)
+}
+
+func (c *tracedCli) Inspect(ctx context.Context, containerID string) (types.ContainerDetail, error) {
+	ctx, span := tracing.Start(ctx, "container.inspect", tracing.KindInternal)
+	defer span.End()
+	span.SetAttr("container.id", containerID)
+	detail, err := c.Cli.Inspect(ctx, containerID)
+	span.SetError(err)
+	return detail, err
+}
+
+func (c *tracedCli) Run(ctx context.Context, container types.Container) (types.RunResult, error) {
+	ctx, span := tracing.Start(ctx, "container.run", tracing.KindInternal)
+	defer span.End()
+	span.SetAttr("container.id", container.ID)
+	result, err := c.Cli.Run(ctx, container)
+	span.SetError(err)
+	return result, err
+}
+
+// Exec records the span of creating the exec, and the one of its stream
+// that ends on the exit of the tty
+func (c *tracedCli) Exec(ctx context.Context, container types.Container) (types.TTY, error) {
+	ctx, span := tracing.Start(ctx, "container.exec", tracing.KindInternal)
+	span.SetAttr("container.id", container.ID)
+	span.SetAttr("exec.user", container.Exec.User)
+	tty, err := c.Cli.Exec(ctx, container)
+	span.SetError(err)
+	span.End()
+	if err != nil || span == nil {
+		return tty, err
+	}
+	_, stream := tracing.Start(ctx, "container.exec.stream", tracing.KindInternal)
+	stream.SetAttr("container.id", container.ID)
+	return &tracedTTY{TTY: tty, span: stream}, nil
+}
+
+type tracedTTY struct {
+	types.TTY
+	span *tracing.Span
+	once sync.Once
+}
+
+func (t *tracedTTY) Exit() error {
+	err := t.TTY.Exit()
+	t.once.Do(t.span.End)
+	return err
+}
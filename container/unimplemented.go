@@ -0,0 +1,107 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/wrfly/container-web-tty/types"
+)
+
+// ErrUnimplemented is returned by the methods of Unimplemented
+var ErrUnimplemented = errors.New("not supported by the backend")
+
+// Unimplemented is embedded by the backends of other programs, so they
+// implement the methods they support only (List, GetInfo and Exec at
+// least) and are still a Cli as it grows. The others fail with
+// ErrUnimplemented, the routes of them report the error.
+type Unimplemented struct{}
+
+var _ Cli = Unimplemented{}
+
+func (Unimplemented) GetInfo(ctx context.Context, containerID string) types.Container {
+	return types.Container{}
+}
+
+func (Unimplemented) List(context.Context) []types.Container { return nil }
+
+func (Unimplemented) Start(ctx context.Context, containerID string) error { return ErrUnimplemented }
+
+func (Unimplemented) Stop(ctx context.Context, containerID string) error { return ErrUnimplemented }
+
+func (Unimplemented) Restart(ctx context.Context, containerID string) error { return ErrUnimplemented }
+
+func (Unimplemented) Pause(ctx context.Context, containerID string) error { return ErrUnimplemented }
+
+func (Unimplemented) Unpause(ctx context.Context, containerID string) error { return ErrUnimplemented }
+
+func (Unimplemented) Kill(ctx context.Context, containerID, signal string) error {
+	return ErrUnimplemented
+}
+
+func (Unimplemented) Rename(ctx context.Context, containerID, name string) error {
+	return ErrUnimplemented
+}
+
+func (Unimplemented) Commit(ctx context.Context, containerID string, opts types.CommitOptions) (string, error) {
+	return "", ErrUnimplemented
+}
+
+func (Unimplemented) Create(ctx context.Context, opts types.CreateOptions) (string, error) {
+	return "", ErrUnimplemented
+}
+
+func (Unimplemented) Debug(ctx context.Context, containerID string, opts types.DebugOptions) (string, error) {
+	return "", ErrUnimplemented
+}
+
+func (Unimplemented) Top(ctx context.Context, containerID string) (types.Processes, error) {
+	return types.Processes{}, ErrUnimplemented
+}
+
+func (Unimplemented) Diff(ctx context.Context, containerID string) ([]types.Change, error) {
+	return nil, ErrUnimplemented
+}
+
+func (Unimplemented) Inspect(ctx context.Context, containerID string) (types.ContainerDetail, error) {
+	return types.ContainerDetail{}, ErrUnimplemented
+}
+
+func (Unimplemented) CopyFrom(ctx context.Context, containerID, path string) (io.ReadCloser, error) {
+	return nil, ErrUnimplemented
+}
+
+func (Unimplemented) CopyTo(ctx context.Context, containerID, dir string, content io.Reader) error {
+	return ErrUnimplemented
+}
+
+func (Unimplemented) UpdateLabels(ctx context.Context, containerID string, update types.LabelsUpdate) error {
+	return ErrUnimplemented
+}
+
+func (Unimplemented) Prune(ctx context.Context, kind string, dryRun bool) (types.PruneReport, error) {
+	return types.PruneReport{}, ErrUnimplemented
+}
+
+func (Unimplemented) Exec(ctx context.Context, container types.Container) (types.TTY, error) {
+	return nil, ErrUnimplemented
+}
+
+func (Unimplemented) Run(ctx context.Context, container types.Container) (types.RunResult, error) {
+	return types.RunResult{}, ErrUnimplemented
+}
+
+// Ping succeeds, a backend of a program is up as long as the program
+func (Unimplemented) Ping(ctx context.Context) error { return nil }
+
+func (Unimplemented) Version(ctx context.Context) ([]types.BackendVersion, error) { return nil, nil }
+
+func (Unimplemented) Close() error { return nil }
+
+func (Unimplemented) Logs(ctx context.Context, opts types.LogOptions) (io.ReadCloser, error) {
+	return nil, ErrUnimplemented
+}
+
+func (Unimplemented) Stats(ctx context.Context, containerID string) (<-chan types.Stats, error) {
+	return nil, ErrUnimplemented
+}
@@ -0,0 +1,113 @@
+// Package crash keeps the recent panics recovered from the requests for
+// the admins, and reports them to Sentry or a webhook once it's set up
+package crash
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/wrfly/container-web-tty/logging"
+	"github.com/wrfly/container-web-tty/types"
+)
+
+var log = logging.Module("crash")
+
+const (
+	// the panics kept in memory
+	maxRecent = 50
+	// the reports waiting to be sent, the later ones are dropped
+	queueSize = 100
+)
+
+// reporter sends a report to a service
+type reporter interface {
+	report(types.ErrorReport) error
+}
+
+var (
+	m       sync.Mutex
+	recent  []types.ErrorReport // oldest first
+	queue   chan types.ErrorReport
+	client  = &http.Client{Timeout: 10 * time.Second}
+	release string
+)
+
+// Setup reports the panics to the Sentry of the DSN and the webhook of
+// the URL if they're not empty, the webhook is posted the report as JSON.
+// The release is the version of the server
+func Setup(sentryDSN, webhook, version string) error {
+	var reporters []reporter
+	if sentryDSN != "" {
+		s, err := newSentry(sentryDSN)
+		if err != nil {
+			return err
+		}
+		reporters = append(reporters, s)
+	}
+	if webhook != "" {
+		w, err := newWebhook(webhook)
+		if err != nil {
+			return err
+		}
+		reporters = append(reporters, w)
+	}
+	release = version
+	if len(reporters) == 0 {
+		return nil
+	}
+
+	q := make(chan types.ErrorReport, queueSize)
+	go func() {
+		for r := range q {
+			for _, rep := range reporters {
+				if err := rep.report(r); err != nil {
+					log.Errorf("report the panic %s error: %s", r.ID, err)
+				}
+			}
+		}
+	}()
+	m.Lock()
+	queue = q
+	m.Unlock()
+	return nil
+}
+
+// Capture keeps the report and queues it to be reported, its ID and time
+// are set, the report is returned with them
+func Capture(r types.ErrorReport) types.ErrorReport {
+	id := make([]byte, 16)
+	rand.Read(id)
+	r.ID = hex.EncodeToString(id)
+	if r.Time.IsZero() {
+		r.Time = time.Now()
+	}
+
+	m.Lock()
+	defer m.Unlock()
+	recent = append(recent, r)
+	if len(recent) > maxRecent {
+		recent = recent[len(recent)-maxRecent:]
+	}
+	if queue != nil {
+		select {
+		case queue <- r:
+		default:
+			log.Warnf("too many panics to report, dropped %s", r.ID)
+		}
+	}
+	return r
+}
+
+// Recent returns the recent panics, newest first
+func Recent() []types.ErrorReport {
+	m.Lock()
+	defer m.Unlock()
+	reports := make([]types.ErrorReport, 0, len(recent))
+	for i := len(recent) - 1; i >= 0; i-- {
+		reports = append(reports, recent[i])
+	}
+	return reports
+}
@@ -0,0 +1,90 @@
+package crash
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"runtime/debug"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wrfly/container-web-tty/types"
+)
+
+func TestReport(t *testing.T) {
+	got := make(chan *http.Request, 2)
+	bodies := make(chan []byte, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		got <- r
+		bodies <- body
+	}))
+	defer srv.Close()
+
+	for _, dsn := range []string{"ftp://key@sentry.io/1", "https://sentry.io/1", "https://key@sentry.io/project"} {
+		if err := Setup(dsn, "", "test"); err == nil {
+			t.Fatalf("expect an error of the DSN %s", dsn)
+		}
+	}
+	dsn := strings.Replace(srv.URL, "http://", "http://public@", 1) + "/sentry/42"
+	if err := Setup(dsn, srv.URL+"/hook", "v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { queue = nil }()
+
+	r := Capture(types.ErrorReport{
+		Message:   "boom",
+		Stack:     string(debug.Stack()),
+		RequestID: "req-1",
+		Method:    "GET",
+		Path:      "/api/containers",
+		Client:    "10.0.0.7",
+	})
+	if len(r.ID) != 32 || r.Time.IsZero() {
+		t.Fatalf("bad report %+v", r)
+	}
+	if recent := Recent(); len(recent) == 0 || recent[0].ID != r.ID {
+		t.Fatalf("expect the report in the recent ones, got %+v", recent)
+	}
+
+	for i := 0; i < 2; i++ {
+		var req *http.Request
+		select {
+		case req = <-got:
+		case <-time.After(5 * time.Second):
+			t.Fatal("the report is not sent")
+		}
+		body := <-bodies
+		switch req.URL.Path {
+		case "/sentry/api/42/store/":
+			if auth := req.Header.Get("X-Sentry-Auth"); !strings.Contains(auth, "sentry_key=public") {
+				t.Fatalf("bad auth of Sentry: %s", auth)
+			}
+			var e sentryEvent
+			if err := json.Unmarshal(body, &e); err != nil {
+				t.Fatal(err)
+			}
+			// the newest frames are the last
+			frames := e.Exception.Values[0].Stacktrace.Frames
+			test := frames[len(frames)-2]
+			if e.EventID != r.ID || e.Release != "v1.0.0" || e.Tags["request_id"] != "req-1" ||
+				test.Function != "github.com/wrfly/container-web-tty/crash.TestReport" ||
+				!strings.HasSuffix(test.Filename, "crash_test.go") || test.Lineno == 0 ||
+				frames[0].Function != "testing.(*T).Run" {
+				t.Fatalf("bad event of Sentry: %s", body)
+			}
+		case "/hook":
+			var hooked types.ErrorReport
+			if err := json.Unmarshal(body, &hooked); err != nil {
+				t.Fatal(err)
+			}
+			if hooked.ID != r.ID || hooked.Path != "/api/containers" {
+				t.Fatalf("bad report of the webhook: %s", body)
+			}
+		default:
+			t.Fatalf("unexpected report to %s", req.URL.Path)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package crash
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wrfly/container-web-tty/types"
+)
+
+// sentry sends the reports as the events of the store API of Sentry, the
+// DSN is https://<key>@<host>/<project>
+type sentry struct {
+	url  string
+	auth string
+	host string
+}
+
+func newSentry(dsn string) (*sentry, error) {
+	u, err := url.Parse(dsn)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") ||
+		u.User == nil || u.User.Username() == "" {
+		return nil, fmt.Errorf("bad Sentry DSN, must be https://<key>@<host>/<project>")
+	}
+	path := strings.Trim(u.Path, "/")
+	i := strings.LastIndex(path, "/")
+	project := path[i+1:]
+	if _, err := strconv.Atoi(project); err != nil {
+		return nil, fmt.Errorf("bad project of the Sentry DSN: %q", project)
+	}
+	prefix := ""
+	if i > 0 {
+		prefix = "/" + path[:i]
+	}
+	auth := "Sentry sentry_version=7, sentry_client=container-web-tty, sentry_key=" + u.User.Username()
+	if secret, ok := u.User.Password(); ok {
+		auth += ", sentry_secret=" + secret
+	}
+	h, _ := os.Hostname()
+	return &sentry{
+		url:  fmt.Sprintf("%s://%s%s/api/%s/store/", u.Scheme, u.Host, prefix, project),
+		auth: auth,
+		host: h,
+	}, nil
+}
+
+type sentryEvent struct {
+	EventID    string            `json:"event_id"`
+	Timestamp  string            `json:"timestamp"`
+	Level      string            `json:"level"`
+	Platform   string            `json:"platform"`
+	Logger     string            `json:"logger"`
+	Release    string            `json:"release,omitempty"`
+	ServerName string            `json:"server_name,omitempty"`
+	Message    string            `json:"message"`
+	Exception  sentryExceptions  `json:"exception"`
+	Request    *sentryRequest    `json:"request,omitempty"`
+	Tags       map[string]string `json:"tags,omitempty"`
+	User       *sentryUser       `json:"user,omitempty"`
+}
+
+type sentryExceptions struct {
+	Values []sentryException `json:"values"`
+}
+
+type sentryException struct {
+	Type       string           `json:"type"`
+	Value      string           `json:"value"`
+	Stacktrace sentryStacktrace `json:"stacktrace"`
+}
+
+type sentryStacktrace struct {
+	Frames []sentryFrame `json:"frames"`
+}
+
+type sentryFrame struct {
+	Function string `json:"function"`
+	Filename string `json:"filename"`
+	Lineno   int    `json:"lineno"`
+}
+
+type sentryRequest struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+type sentryUser struct {
+	IPAddress string `json:"ip_address"`
+}
+
+func (s *sentry) event(r types.ErrorReport) sentryEvent {
+	e := sentryEvent{
+		EventID:    r.ID,
+		Timestamp:  r.Time.UTC().Format(time.RFC3339),
+		Level:      "error",
+		Platform:   "go",
+		Logger:     "container-web-tty",
+		Release:    release,
+		ServerName: s.host,
+		Message:    r.Message,
+		Exception: sentryExceptions{Values: []sentryException{{
+			Type:       "panic",
+			Value:      r.Message,
+			Stacktrace: sentryStacktrace{Frames: stackFrames(r.Stack)},
+		}}},
+	}
+	if r.Path != "" {
+		e.Request = &sentryRequest{Method: r.Method, URL: r.Path}
+		if r.UserAgent != "" {
+			e.Request.Headers = map[string]string{"User-Agent": r.UserAgent}
+		}
+	}
+	if r.RequestID != "" {
+		e.Tags = map[string]string{"request_id": r.RequestID}
+	}
+	if r.Client != "" {
+		e.User = &sentryUser{IPAddress: r.Client}
+	}
+	return e
+}
+
+func (s *sentry) report(r types.ErrorReport) error {
+	body, err := json.Marshal(s.event(r))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", s.auth)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("sentry: %s", resp.Status)
+	}
+	return nil
+}
+
+// stackFrames parses the stack of debug.Stack, the frames of Sentry are
+// the oldest first
+func stackFrames(stack string) []sentryFrame {
+	var frames []sentryFrame
+	lines := strings.Split(stack, "\n")
+	// the first line is the goroutine, followed by the pairs of the
+	// function and its "\tfile:line +0x..."
+	for i := 1; i+1 < len(lines); i += 2 {
+		fn, loc := lines[i], strings.TrimSpace(lines[i+1])
+		if strings.HasPrefix(fn, "created by ") {
+			fn = strings.TrimPrefix(fn, "created by ")
+			if j := strings.Index(fn, " in goroutine"); j > 0 {
+				fn = fn[:j]
+			}
+		} else if j := strings.LastIndex(fn, "("); j > 0 {
+			fn = fn[:j]
+		}
+		if j := strings.LastIndex(loc, " +0x"); j > 0 {
+			loc = loc[:j]
+		}
+		f := sentryFrame{Function: fn, Filename: loc}
+		if j := strings.LastIndex(loc, ":"); j > 0 {
+			f.Filename = loc[:j]
+			f.Lineno, _ = strconv.Atoi(loc[j+1:])
+		}
+		frames = append([]sentryFrame{f}, frames...)
+	}
+	return frames
+}
@@ -0,0 +1,42 @@
+package crash
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/wrfly/container-web-tty/types"
+)
+
+// webhook posts the reports as JSON (types.ErrorReport) with the release
+type webhook struct {
+	url string
+}
+
+func newWebhook(hook string) (*webhook, error) {
+	u, err := url.Parse(hook)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("bad error webhook, must be an http(s) URL")
+	}
+	return &webhook{url: hook}, nil
+}
+
+func (w *webhook) report(r types.ErrorReport) error {
+	body, err := json.Marshal(struct {
+		types.ErrorReport
+		Release string `json:"release,omitempty"`
+	}{r, release})
+	if err != nil {
+		return err
+	}
+	resp, err := client.Post(w.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook: %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package event
+
+import (
+	"sync"
+
+	"github.com/wrfly/container-web-tty/types"
+)
+
+// timelineSize is the max events kept in the timeline of a container
+const timelineSize = 100
+
+// timelineActions are the actions telling the stability of a container
+var timelineActions = map[string]bool{
+	"start":         true,
+	"restart":       true,
+	"die":           true,
+	"oom":           true,
+	"kill":          true,
+	"stop":          true,
+	"health_status": true,
+}
+
+// Hub broadcasts the container events to the subscribers,
+// a nil Hub drops all the events
+type Hub struct {
+	m         sync.RWMutex
+	subs      map[chan types.Event]struct{}
+	timelines map[string][]types.Event
+}
+
+// NewHub returns an empty Hub
+func NewHub() *Hub {
+	return &Hub{
+		subs:      make(map[chan types.Event]struct{}),
+		timelines: make(map[string][]types.Event),
+	}
+}
+
+// Publish sends the event to all subscribers without blocking,
+// a subscriber misses the event if its buffer is full
+func (h *Hub) Publish(e types.Event) {
+	if h == nil {
+		return
+	}
+	h.record(e)
+
+	h.m.RLock()
+	defer h.m.RUnlock()
+	for sub := range h.subs {
+		select {
+		case sub <- e:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel of the events and a function to cancel
+// the subscription, the channel is closed after the cancelation
+func (h *Hub) Subscribe(buffer int) (<-chan types.Event, func()) {
+	sub := make(chan types.Event, buffer)
+	if h == nil {
+		close(sub)
+		return sub, func() {}
+	}
+
+	h.m.Lock()
+	h.subs[sub] = struct{}{}
+	h.m.Unlock()
+
+	var once sync.Once
+	return sub, func() {
+		once.Do(func() {
+			h.m.Lock()
+			delete(h.subs, sub)
+			h.m.Unlock()
+			close(sub)
+		})
+	}
+}
+
+// record appends the event to the timeline of the container,
+// the timeline is dropped when the container is destroyed
+func (h *Hub) record(e types.Event) {
+	if e.Action != "destroy" && !timelineActions[e.Action] {
+		return
+	}
+	h.m.Lock()
+	defer h.m.Unlock()
+	if e.Action == "destroy" {
+		delete(h.timelines, e.ID)
+		return
+	}
+	timeline := append(h.timelines[e.ID], e)
+	if len(timeline) > timelineSize {
+		timeline = timeline[len(timeline)-timelineSize:]
+	}
+	h.timelines[e.ID] = timeline
+}
+
+// Move moves the timeline of a container to its new ID,
+// e.g. a restarted container of a pod
+func (h *Hub) Move(from, to string) {
+	if h == nil {
+		return
+	}
+	h.m.Lock()
+	defer h.m.Unlock()
+	if timeline, ok := h.timelines[from]; ok {
+		timeline = append(timeline, h.timelines[to]...)
+		if len(timeline) > timelineSize {
+			timeline = timeline[len(timeline)-timelineSize:]
+		}
+		h.timelines[to] = timeline
+		delete(h.timelines, from)
+	}
+}
+
+// Timeline returns the restarts, OOM kills and health transitions
+// of the container since the hub was created, the newest first
+func (h *Hub) Timeline(containerID string) []types.Event {
+	if h == nil {
+		return []types.Event{}
+	}
+	h.m.RLock()
+	defer h.m.RUnlock()
+	timeline := h.timelines[containerID]
+	events := make([]types.Event, 0, len(timeline))
+	for i := len(timeline) - 1; i >= 0; i-- {
+		events = append(events, timeline[i])
+	}
+	return events
+}
@@ -0,0 +1,56 @@
+package event
+
+import (
+	"testing"
+
+	"github.com/wrfly/container-web-tty/types"
+)
+
+func TestHub(t *testing.T) {
+	h := NewHub()
+	sub, cancel := h.Subscribe(1)
+
+	h.Publish(types.Event{Action: "start", ID: "a"})
+	// the buffer is full, dropped
+	h.Publish(types.Event{Action: "die", ID: "a"})
+
+	if e := <-sub; e.Action != "start" {
+		t.Fatalf("unexpected event: %+v", e)
+	}
+	cancel()
+	cancel()
+	if _, ok := <-sub; ok {
+		t.Fatal("channel should be closed after cancel")
+	}
+	h.Publish(types.Event{Action: "destroy", ID: "a"})
+
+	var nilHub *Hub
+	nilHub.Publish(types.Event{})
+	nilSub, _ := nilHub.Subscribe(1)
+	if _, ok := <-nilSub; ok {
+		t.Fatal("nil hub should return a closed channel")
+	}
+}
+
+func TestTimeline(t *testing.T) {
+	h := NewHub()
+	for i := 0; i < timelineSize+10; i++ {
+		h.Publish(types.Event{Action: "start", ID: "a"})
+	}
+	h.Publish(types.Event{Action: "exec_start", ID: "a"})
+	h.Publish(types.Event{Action: "oom", ID: "a"})
+	h.Publish(types.Event{Action: "die", ID: "b"})
+
+	timeline := h.Timeline("a")
+	if len(timeline) != timelineSize || timeline[0].Action != "oom" {
+		t.Fatalf("unexpected timeline: %d events, newest %+v", len(timeline), timeline[0])
+	}
+	h.Move("b", "c")
+	if timeline := h.Timeline("c"); len(timeline) != 1 || timeline[0].ID != "b" {
+		t.Fatalf("timeline should be moved: %+v", timeline)
+	}
+	h.Publish(types.Event{Action: "destroy", ID: "c"})
+	if timeline := h.Timeline("c"); len(timeline) != 0 {
+		t.Fatalf("timeline should be dropped after destroy: %+v", timeline)
+	}
+}
@@ -0,0 +1,208 @@
+// Package graphql executes a subset of GraphQL queries against
+// resolvers: fields, aliases, arguments and variables are supported,
+// fragments, directives, mutations and introspection are not. The depth
+// and the fields resolved of a query are limited.
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Object is a GraphQL object, Resolve returns the value of the field:
+// a scalar, a slice of scalars, an Object or a slice of Objects
+type Object interface {
+	Resolve(ctx context.Context, field string, args map[string]interface{}) (interface{}, error)
+}
+
+// Request is the body of a GraphQL request
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Error is an error of the execution, Path locates the failed field
+type Error struct {
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path,omitempty"`
+}
+
+// Response is the result of a GraphQL request
+type Response struct {
+	Data   interface{} `json:"data"`
+	Errors []Error     `json:"errors,omitempty"`
+}
+
+// fields keeps the order of the selections in the JSON output
+type fields struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func (f *fields) set(key string, v interface{}) {
+	if _, ok := f.values[key]; !ok {
+		f.keys = append(f.keys, key)
+	}
+	f.values[key] = v
+}
+
+func (f *fields) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBufferString("{")
+	for i, k := range f.keys {
+		if i != 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(k)
+		buf.Write(key)
+		buf.WriteByte(':')
+		v, err := json.Marshal(f.values[k])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+const (
+	// MaxDepth is the max nesting of the selection sets of a query
+	MaxDepth = 10
+	// MaxComplexity is the max fields resolved by a query, a field of
+	// every object of a list counts
+	MaxComplexity = 10000
+)
+
+type executor struct {
+	variables map[string]interface{}
+	errors    []Error
+	// resolved fields, the execution stops after MaxComplexity
+	complexity int
+}
+
+// Execute runs the query of the request against the root object
+func Execute(ctx context.Context, root Object, req Request) Response {
+	ops, err := parse(req.Query)
+	if err != nil {
+		return Response{Errors: []Error{{Message: err.Error()}}}
+	}
+
+	var op *operation
+	for i := range ops {
+		if req.OperationName == "" || ops[i].name == req.OperationName {
+			op = &ops[i]
+			break
+		}
+	}
+	if op == nil || (req.OperationName == "" && len(ops) > 1) {
+		return Response{Errors: []Error{{Message: "unknown or ambiguous operation name"}}}
+	}
+	if depth(op.selections) > MaxDepth {
+		return Response{Errors: []Error{{Message: fmt.Sprintf("query is too deep, max depth is %d", MaxDepth)}}}
+	}
+
+	e := &executor{variables: op.defaults}
+	for k, v := range req.Variables {
+		e.variables[k] = v
+	}
+	data := e.object(ctx, root, op.selections, nil)
+	if e.complexity > MaxComplexity {
+		return Response{Errors: []Error{{Message: fmt.Sprintf("query is too complex, max fields resolved is %d", MaxComplexity)}}}
+	}
+	return Response{Data: data, Errors: e.errors}
+}
+
+// depth is the nesting of the selection sets
+func depth(selections []selection) int {
+	if len(selections) == 0 {
+		return 0
+	}
+	max := 0
+	for _, s := range selections {
+		if d := depth(s.selections); d > max {
+			max = d
+		}
+	}
+	return max + 1
+}
+
+func (e *executor) object(ctx context.Context, obj Object, selections []selection, path []interface{}) *fields {
+	result := &fields{values: map[string]interface{}{}}
+	for _, s := range selections {
+		if e.complexity++; e.complexity > MaxComplexity {
+			return result
+		}
+		fieldPath := append(append([]interface{}{}, path...), s.alias)
+		v, err := e.field(ctx, obj, s, fieldPath)
+		if err != nil {
+			e.errors = append(e.errors, Error{Message: err.Error(), Path: fieldPath})
+			v = nil
+		}
+		result.set(s.alias, v)
+	}
+	return result
+}
+
+func (e *executor) field(ctx context.Context, obj Object, s selection, path []interface{}) (interface{}, error) {
+	args := make(map[string]interface{}, len(s.args))
+	for k, v := range s.args {
+		args[k] = e.resolveVariables(v)
+	}
+
+	v, err := obj.Resolve(ctx, s.name, args)
+	if err != nil || v == nil {
+		return nil, err
+	}
+
+	switch v := v.(type) {
+	case Object:
+		if s.selections == nil {
+			return nil, fmt.Errorf("field %q must have a selection set", s.name)
+		}
+		return e.object(ctx, v, s.selections, path), nil
+	case []Object:
+		if s.selections == nil {
+			return nil, fmt.Errorf("field %q must have a selection set", s.name)
+		}
+		list := make([]interface{}, len(v))
+		for i, o := range v {
+			list[i] = e.object(ctx, o, s.selections, append(path, i))
+		}
+		return list, nil
+	}
+
+	if s.selections != nil {
+		return nil, fmt.Errorf("field %q is a scalar, cannot have a selection set", s.name)
+	}
+	return v, nil
+}
+
+func (e *executor) resolveVariables(v interface{}) interface{} {
+	switch v := v.(type) {
+	case variable:
+		return e.variables[string(v)]
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i := range v {
+			list[i] = e.resolveVariables(v[i])
+		}
+		return list
+	}
+	return v
+}
+
+// StringArg returns the string argument of the name, "" if it's not set
+func StringArg(args map[string]interface{}, name string) (string, error) {
+	v, ok := args[name]
+	if !ok || v == nil {
+		return "", nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("argument %q must be a string", name)
+	}
+	return s, nil
+}
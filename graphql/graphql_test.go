@@ -0,0 +1,124 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type testObject map[string]interface{}
+
+func (o testObject) Resolve(ctx context.Context, field string, args map[string]interface{}) (interface{}, error) {
+	if field == "echo" {
+		return StringArg(args, "s")
+	}
+	v, ok := o[field]
+	if !ok {
+		return nil, fmt.Errorf("unknown field %q", field)
+	}
+	return v, nil
+}
+
+func TestExecute(t *testing.T) {
+	root := testObject{
+		"name": "root",
+		"children": []Object{
+			testObject{"id": 1, "tags": []string{"a"}},
+			testObject{"id": 2, "tags": []string{}},
+		},
+	}
+
+	tests := []struct {
+		query     string
+		variables map[string]interface{}
+		expect    string
+	}{
+		{
+			query:  `{ name children { id tags } }`,
+			expect: `{"data":{"name":"root","children":[{"id":1,"tags":["a"]},{"id":2,"tags":[]}]}}`,
+		},
+		{
+			query:  `query Q { n: name, first: echo(s: "x\"y") }`,
+			expect: `{"data":{"n":"root","first":"x\"y"}}`,
+		},
+		{
+			query:     `query ($s: String = "default") { echo(s: $s) }`,
+			variables: map[string]interface{}{"s": "var"},
+			expect:    `{"data":{"echo":"var"}}`,
+		},
+		{
+			query:  `query ($s: String = "default") { echo(s: $s) }`,
+			expect: `{"data":{"echo":"default"}}`,
+		},
+		{
+			query:  `{ name unknown }`,
+			expect: `{"data":{"name":"root","unknown":null},"errors":[{"message":"unknown field \"unknown\"","path":["unknown"]}]}`,
+		},
+		{
+			query:  `{ name { id } }`,
+			expect: `{"data":{"name":null},"errors":[{"message":"field \"name\" is a scalar, cannot have a selection set","path":["name"]}]}`,
+		},
+		{
+			query:  `mutation { name }`,
+			expect: `{"data":null,"errors":[{"message":"unsupported operation \"mutation\" at 0"}]}`,
+		},
+		{
+			query:  `{ ...f }`,
+			expect: `{"data":null,"errors":[{"message":"fragments and directives are not supported (at 2)"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		resp := Execute(context.Background(), root, Request{Query: tt.query, Variables: tt.variables})
+		bs, err := json.Marshal(resp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(bs) != tt.expect {
+			t.Errorf("query %s:\n got %s\nwant %s", tt.query, bs, tt.expect)
+		}
+	}
+}
+
+type nestedObject struct{ children int }
+
+func (o nestedObject) Resolve(ctx context.Context, field string, args map[string]interface{}) (interface{}, error) {
+	switch field {
+	case "id":
+		return 1, nil
+	case "child":
+		return o, nil
+	case "children":
+		list := make([]Object, o.children)
+		for i := range list {
+			list[i] = o
+		}
+		return list, nil
+	}
+	return nil, fmt.Errorf("unknown field %q", field)
+}
+
+func TestLimits(t *testing.T) {
+	query := strings.Repeat("{ child ", MaxDepth-1) + "{ id }" + strings.Repeat(" }", MaxDepth-1)
+	if resp := Execute(context.Background(), nestedObject{}, Request{Query: query}); resp.Errors != nil {
+		t.Errorf("depth %d: %v", MaxDepth, resp.Errors)
+	}
+	query = "{ child " + query + " }"
+	resp := Execute(context.Background(), nestedObject{}, Request{Query: query})
+	if resp.Data != nil || len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0].Message, "too deep") {
+		t.Errorf("depth %d: %+v", MaxDepth+1, resp)
+	}
+
+	// 1 + 100 * (1 + 100) fields
+	query = "{ children { children { id } } }"
+	resp = Execute(context.Background(), nestedObject{children: 100}, Request{Query: query})
+	if resp.Data != nil || len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0].Message, "too complex") {
+		t.Errorf("complexity: %+v", resp.Errors)
+	}
+	query = "{ children { id } }"
+	if resp = Execute(context.Background(), nestedObject{children: 100}, Request{Query: query}); resp.Errors != nil {
+		t.Errorf("complexity: %v", resp.Errors)
+	}
+}
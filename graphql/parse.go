@@ -0,0 +1,322 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// selection is a field of a selection set
+type selection struct {
+	alias, name string
+	args        map[string]interface{}
+	selections  []selection
+}
+
+// variable is a reference of a variable in an argument, e.g. $id
+type variable string
+
+type operation struct {
+	name       string
+	defaults   map[string]interface{}
+	selections []selection
+}
+
+type token struct {
+	kind byte // 'n'ame, 's'tring, 'i'nt, 'f'loat, or the punctuator
+	text string
+	pos  int
+}
+
+func tokenize(src string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(src); {
+		ch := src[i]
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r' || ch == ',':
+			i++
+		case ch == '#':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.IndexByte("{}():$!=[]@", ch) >= 0:
+			tokens = append(tokens, token{kind: ch, text: string(ch), pos: i})
+			i++
+		case ch == '.':
+			if !strings.HasPrefix(src[i:], "...") {
+				return nil, fmt.Errorf("unexpected %q at %d", ch, i)
+			}
+			tokens = append(tokens, token{kind: '.', text: "...", pos: i})
+			i += 3
+		case ch == '"':
+			start := i
+			i++
+			for i < len(src) && src[i] != '"' {
+				if src[i] == '\\' {
+					i++
+				}
+				if i < len(src) && src[i] == '\n' {
+					break
+				}
+				i++
+			}
+			if i >= len(src) || src[i] != '"' {
+				return nil, fmt.Errorf("unterminated string at %d", start)
+			}
+			i++
+			s, err := strconv.Unquote(src[start:i])
+			if err != nil {
+				return nil, fmt.Errorf("bad string at %d: %s", start, err)
+			}
+			tokens = append(tokens, token{kind: 's', text: s, pos: start})
+		case ch == '-' || (ch >= '0' && ch <= '9'):
+			start := i
+			kind := byte('i')
+			i++
+			for i < len(src) && strings.IndexByte("0123456789.eE+-", src[i]) >= 0 {
+				if strings.IndexByte(".eE", src[i]) >= 0 {
+					kind = 'f'
+				}
+				i++
+			}
+			tokens = append(tokens, token{kind: kind, text: src[start:i], pos: start})
+		case ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z'):
+			start := i
+			for i < len(src) && (src[i] == '_' || (src[i] >= 'a' && src[i] <= 'z') ||
+				(src[i] >= 'A' && src[i] <= 'Z') || (src[i] >= '0' && src[i] <= '9')) {
+				i++
+			}
+			tokens = append(tokens, token{kind: 'n', text: src[start:i], pos: start})
+		default:
+			return nil, fmt.Errorf("unexpected %q at %d", ch, i)
+		}
+	}
+	return tokens, nil
+}
+
+type parser struct {
+	tokens []token
+	i      int
+}
+
+func (p *parser) peek() token {
+	if p.i >= len(p.tokens) {
+		return token{kind: 0, text: "EOF"}
+	}
+	return p.tokens[p.i]
+}
+
+func (p *parser) next() token {
+	t := p.peek()
+	p.i++
+	return t
+}
+
+func (p *parser) expect(kind byte) (token, error) {
+	t := p.next()
+	if t.kind != kind {
+		return t, fmt.Errorf("unexpected %q at %d", t.text, t.pos)
+	}
+	return t, nil
+}
+
+// parse parses the operations of a query document, only queries
+// are supported: no mutations, subscriptions, fragments or directives
+func parse(src string) ([]operation, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+
+	var ops []operation
+	for p.peek().kind != 0 {
+		op, err := p.parseOperation()
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, op)
+	}
+	if len(ops) == 0 {
+		return nil, fmt.Errorf("no operation in the query")
+	}
+	return ops, nil
+}
+
+func (p *parser) parseOperation() (operation, error) {
+	op := operation{defaults: map[string]interface{}{}}
+	if t := p.peek(); t.kind == 'n' {
+		if t.text != "query" {
+			return op, fmt.Errorf("unsupported operation %q at %d", t.text, t.pos)
+		}
+		p.next()
+		if p.peek().kind == 'n' {
+			op.name = p.next().text
+		}
+		if p.peek().kind == '(' {
+			if err := p.parseVariableDefinitions(op.defaults); err != nil {
+				return op, err
+			}
+		}
+	}
+
+	selections, err := p.parseSelectionSet()
+	if err != nil {
+		return op, err
+	}
+	op.selections = selections
+	return op, nil
+}
+
+// parseVariableDefinitions keeps the default values, the types are not checked
+func (p *parser) parseVariableDefinitions(defaults map[string]interface{}) error {
+	p.next() // (
+	for p.peek().kind != ')' {
+		if _, err := p.expect('$'); err != nil {
+			return err
+		}
+		name, err := p.expect('n')
+		if err != nil {
+			return err
+		}
+		if _, err := p.expect(':'); err != nil {
+			return err
+		}
+		if err := p.skipType(); err != nil {
+			return err
+		}
+		if p.peek().kind == '=' {
+			p.next()
+			v, err := p.parseValue()
+			if err != nil {
+				return err
+			}
+			defaults[name.text] = v
+		}
+	}
+	p.next() // )
+	return nil
+}
+
+func (p *parser) skipType() error {
+	if p.peek().kind == '[' {
+		p.next()
+		if err := p.skipType(); err != nil {
+			return err
+		}
+		if _, err := p.expect(']'); err != nil {
+			return err
+		}
+	} else if _, err := p.expect('n'); err != nil {
+		return err
+	}
+	if p.peek().kind == '!' {
+		p.next()
+	}
+	return nil
+}
+
+func (p *parser) parseSelectionSet() ([]selection, error) {
+	if _, err := p.expect('{'); err != nil {
+		return nil, err
+	}
+	var selections []selection
+	for p.peek().kind != '}' {
+		t := p.peek()
+		if t.kind == '.' || t.kind == '@' {
+			return nil, fmt.Errorf("fragments and directives are not supported (at %d)", t.pos)
+		}
+		s, err := p.parseField()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, s)
+	}
+	p.next() // }
+	if len(selections) == 0 {
+		return nil, fmt.Errorf("empty selection set")
+	}
+	return selections, nil
+}
+
+func (p *parser) parseField() (selection, error) {
+	var s selection
+	name, err := p.expect('n')
+	if err != nil {
+		return s, err
+	}
+	s.name = name.text
+	s.alias = name.text
+	if p.peek().kind == ':' {
+		p.next()
+		if name, err = p.expect('n'); err != nil {
+			return s, err
+		}
+		s.name = name.text
+	}
+
+	if p.peek().kind == '(' {
+		p.next()
+		s.args = map[string]interface{}{}
+		for p.peek().kind != ')' {
+			arg, err := p.expect('n')
+			if err != nil {
+				return s, err
+			}
+			if _, err := p.expect(':'); err != nil {
+				return s, err
+			}
+			v, err := p.parseValue()
+			if err != nil {
+				return s, err
+			}
+			s.args[arg.text] = v
+		}
+		p.next() // )
+	}
+
+	if p.peek().kind == '{' {
+		if s.selections, err = p.parseSelectionSet(); err != nil {
+			return s, err
+		}
+	}
+	return s, nil
+}
+
+func (p *parser) parseValue() (interface{}, error) {
+	t := p.next()
+	switch t.kind {
+	case '$':
+		name, err := p.expect('n')
+		return variable(name.text), err
+	case 's':
+		return t.text, nil
+	case 'i':
+		return strconv.Atoi(t.text)
+	case 'f':
+		return strconv.ParseFloat(t.text, 64)
+	case 'n':
+		switch t.text {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+		// enum values are passed as strings
+		return t.text, nil
+	case '[':
+		list := []interface{}{}
+		for p.peek().kind != ']' {
+			v, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		p.next() // ]
+		return list, nil
+	}
+	return nil, fmt.Errorf("unexpected %q at %d", t.text, t.pos)
+}
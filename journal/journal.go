@@ -0,0 +1,272 @@
+// Package journal persists the events of the server (its starts and stops,
+// the reloads, the outages of the backend and the summaries of the
+// sessions) in an embedded store of JSON lines segment files in a dir, so
+// they're browsed across the restarts
+package journal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wrfly/container-web-tty/logging"
+	"github.com/wrfly/container-web-tty/types"
+)
+
+var log = logging.Module("journal")
+
+// The kinds of the events
+const (
+	KindStart       = "start"
+	KindStop        = "stop"
+	KindReload      = "reload"
+	KindDrain       = "drain"
+	KindMaintenance = "maintenance"
+	KindBackendDown = "backend_down"
+	KindBackendUp   = "backend_up"
+	KindSession     = "session"
+)
+
+const (
+	segmentPrefix = "journal-"
+	segmentSuffix = ".jsonl"
+	// the segments of the max size, the oldest is removed after rotating
+	// beyond them
+	segments = 4
+)
+
+// Journal is the event log of a dir, a nil Journal drops all the events
+type Journal struct {
+	dir         string
+	segmentSize int64
+
+	m       sync.Mutex
+	file    *os.File
+	size    int64
+	segment int
+	lastID  int64
+}
+
+// Open opens the journal of the dir, which is created if it doesn't exist,
+// the events are kept up to about maxSize bytes, the oldest are dropped
+func Open(dir string, maxSize int64) (*Journal, error) {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, err
+	}
+	j := &Journal{dir: dir, segmentSize: maxSize / segments}
+	if j.segmentSize <= 0 {
+		return nil, fmt.Errorf("bad size of the journal: %d", maxSize)
+	}
+	names, err := j.segments()
+	if err != nil {
+		return nil, err
+	}
+	if len(names) > 0 {
+		fmt.Sscanf(names[len(names)-1], segmentPrefix+"%d"+segmentSuffix, &j.segment)
+	}
+	// the last ID is of the newest segment not empty
+	for i := len(names) - 1; i >= 0 && j.lastID == 0; i-- {
+		events, err := readSegment(filepath.Join(dir, names[i]))
+		if err != nil {
+			return nil, err
+		}
+		if len(events) > 0 {
+			j.lastID = events[len(events)-1].ID
+		}
+	}
+	if err := j.openSegment(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// segments returns the names of the segment files, oldest first
+func (j *Journal) segments() ([]string, error) {
+	files, err := ioutil.ReadDir(j.dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, f := range files {
+		if strings.HasPrefix(f.Name(), segmentPrefix) && strings.HasSuffix(f.Name(), segmentSuffix) {
+			names = append(names, f.Name())
+		}
+	}
+	// the numbers are zero padded
+	sort.Strings(names)
+	return names, nil
+}
+
+func (j *Journal) segmentPath(n int) string {
+	return filepath.Join(j.dir, fmt.Sprintf("%s%08d%s", segmentPrefix, n, segmentSuffix))
+}
+
+// openSegment opens the current segment to append, it's a new one if
+// the current one is full, j.m is held
+func (j *Journal) openSegment() error {
+	if j.segment == 0 {
+		j.segment = 1
+	}
+	if info, err := os.Stat(j.segmentPath(j.segment)); err == nil && info.Size() >= j.segmentSize {
+		j.segment++
+	}
+	f, err := os.OpenFile(j.segmentPath(j.segment), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	j.file, j.size = f, info.Size()
+	if j.size > 0 {
+		// the last line may be written partially by a crash
+		last := make([]byte, 1)
+		if r, err := os.Open(f.Name()); err == nil {
+			r.ReadAt(last, j.size-1)
+			r.Close()
+		}
+		if last[0] != '\n' {
+			f.Write([]byte{'\n'})
+			j.size++
+		}
+	}
+	return nil
+}
+
+// Record appends the event with the next ID, and the time of now if it
+// has none
+func (j *Journal) Record(e types.ServerEvent) error {
+	if j == nil {
+		return nil
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	j.m.Lock()
+	defer j.m.Unlock()
+	if j.file == nil {
+		return fmt.Errorf("the journal is closed")
+	}
+	e.ID = j.lastID + 1
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if _, err := j.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	j.lastID = e.ID
+	j.size += int64(len(line) + 1)
+	if j.size >= j.segmentSize {
+		return j.rotate()
+	}
+	return nil
+}
+
+// rotate starts the next segment and removes the oldest ones beyond
+// segments, j.m is held
+func (j *Journal) rotate() error {
+	j.file.Close()
+	j.file = nil
+	j.segment++
+	if err := j.openSegment(); err != nil {
+		return err
+	}
+	names, err := j.segments()
+	if err != nil {
+		return err
+	}
+	for len(names) > segments+1 {
+		if err := os.Remove(filepath.Join(j.dir, names[0])); err != nil {
+			return err
+		}
+		names = names[1:]
+	}
+	return nil
+}
+
+// Log records the event and logs the error of it, for the callers not
+// failing with the journal
+func (j *Journal) Log(kind, message string, fields map[string]string) {
+	err := j.Record(types.ServerEvent{Kind: kind, Message: message, Fields: fields})
+	if err != nil {
+		log.Errorf("record the %s event error: %s", kind, err)
+	}
+}
+
+// Query returns the events of the kind (any if it's empty) before the ID
+// (any if it's 0), newest first, at most limit of them
+func (j *Journal) Query(kind string, before int64, limit int) ([]types.ServerEvent, error) {
+	events := []types.ServerEvent{}
+	if j == nil {
+		return events, nil
+	}
+	j.m.Lock()
+	defer j.m.Unlock()
+	names, err := j.segments()
+	if err != nil {
+		return nil, err
+	}
+	for i := len(names) - 1; i >= 0 && len(events) < limit; i-- {
+		segment, err := readSegment(filepath.Join(j.dir, names[i]))
+		if err != nil {
+			return nil, err
+		}
+		for k := len(segment) - 1; k >= 0 && len(events) < limit; k-- {
+			e := segment[k]
+			if (before == 0 || e.ID < before) && (kind == "" || e.Kind == kind) {
+				events = append(events, e)
+			}
+		}
+	}
+	return events, nil
+}
+
+// readSegment reads the events of the segment, the bad lines (e.g. the
+// last one written partially by a crash) are skipped
+func readSegment(path string) ([]types.ServerEvent, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var events []types.ServerEvent
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		var e types.ServerEvent
+		if json.Unmarshal(scanner.Bytes(), &e) != nil {
+			continue
+		}
+		events = append(events, e)
+	}
+	return events, scanner.Err()
+}
+
+// Close closes the journal, the events after it are not recorded
+func (j *Journal) Close() error {
+	if j == nil {
+		return nil
+	}
+	j.m.Lock()
+	defer j.m.Unlock()
+	if j.file == nil {
+		return nil
+	}
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
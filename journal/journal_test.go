@@ -0,0 +1,73 @@
+package journal
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wrfly/container-web-tty/types"
+)
+
+func TestJournal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	j, err := Open(dir, 4096)
+	if err != nil {
+		t.Fatal(err)
+	}
+	j.Log(KindStart, "started", map[string]string{"version": "v1"})
+	for i := 0; i < 100; i++ {
+		j.Log(KindSession, "closed "+strings.Repeat("x", 50), nil)
+	}
+	j.Log(KindBackendDown, "daemon down", nil)
+
+	events, err := j.Query("", 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 10 || events[0].Kind != KindBackendDown || events[0].ID != 102 || events[1].ID != 101 {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+	// rotated, the oldest dropped
+	names, _ := j.segments()
+	if len(names) > segments+1 {
+		t.Fatalf("expect at most %d segments, got %v", segments+1, names)
+	}
+	if all, _ := j.Query(KindStart, 0, 1000); len(all) != 0 {
+		t.Fatalf("expect the start dropped, got %+v", all)
+	}
+	if before, _ := j.Query(KindSession, 100, 2); len(before) != 2 || before[0].ID != 99 {
+		t.Fatalf("unexpected events before 100: %+v", before)
+	}
+	j.Close()
+	if err := j.Record(types.ServerEvent{Kind: KindStop}); err == nil {
+		t.Fatal("expect an error of the closed journal")
+	}
+
+	// reopened after a crash in the middle of a line
+	last := filepath.Join(dir, names[len(names)-1])
+	f, _ := os.OpenFile(last, os.O_WRONLY|os.O_APPEND, 0640)
+	f.WriteString(`{"id":103,"ki`)
+	f.Close()
+	if j, err = Open(dir, 4096); err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+	j.Log(KindStart, "restarted", nil)
+	events, _ = j.Query("", 0, 2)
+	if len(events) != 2 || events[0].ID != 103 || events[0].Message != "restarted" || events[1].ID != 102 {
+		t.Fatalf("unexpected events after reopening: %+v", events)
+	}
+
+	var nilJournal *Journal
+	nilJournal.Log(KindStart, "dropped", nil)
+	if events, err := nilJournal.Query("", 0, 10); err != nil || len(events) != 0 {
+		t.Fatalf("unexpected events of a nil journal: %v %v", events, err)
+	}
+}
@@ -89,4 +89,16 @@
  * @module xterm/addons/terminado/terminado
  * @license MIT
  */
-!function(t){e.exports=t(r(0))}(function(e){"use strict";var t={terminadoAttach:function(e,t,r,i){r=void 0===r||r,e.socket=t,e._flushBuffer=function(){e.write(e._attachSocketBuffer),e._attachSocketBuffer=null,clearTimeout(e._attachSocketBufferTimer),e._attachSocketBufferTimer=null},e._pushToBuffer=function(t){e._attachSocketBuffer?e._attachSocketBuffer+=t:(e._attachSocketBuffer=t,setTimeout(e._flushBuffer,10))},e._getMessage=function(t){var r=JSON.parse(t.data);"stdout"==r[0]&&(i?e._pushToBuffer(r[1]):e.write(r[1]))},e._sendData=function(e){t.send(JSON.stringify(["stdin",e]))},e._setSize=function(e){t.send(JSON.stringify(["set_size",e.rows,e.cols]))},t.addEventListener("message",e._getMessage),r&&e.on("data",e._sendData),e.on("resize",e._setSize),t.addEventListener("close",e.terminadoDetach.bind(e,t)),t.addEventListener("error",e.terminadoDetach.bind(e,t))},terminadoDetach:function(e,t){e.off("data",e._sendData),(t=void 0===t?e.socket:t)&&t.removeEventListener("message",e._getMessage),delete e.socket}};return e.prototype.terminadoAttach=function(e,r,i){return t.terminadoAttach(this,e,r,i)},e.prototype.terminadoDetach=function(e){return t.terminadoDetach(this,e)},t})},function(e,t,r){"use strict";Object.defineProperty(t,"__esModule",{value:!0});var i=r(32),o="undefined"==typeof navigator,s=o?"node":navigator.userAgent,n=o?"node":navigator.platform;t.isFirefox=!!~s.indexOf("Firefox"),t.isMSIE=!!~s.indexOf("MSIE")||!!~s.indexOf("Trident"),t.isMac=i.contains(["Macintosh","MacIntel","MacPPC","Mac68K"],n),t.isIpad="iPad"===n,t.isIphone="iPhone"===n,t.isMSWindows=i.contains(["Windows","Win16","Win32","WinCE"],n),t.isLinux=n.indexOf("Linux")>=0},function(e,t,r){"use strict";Object.defineProperty(t,"__esModule",{value:!0});var i=1,o=2;t.translateBufferLineToString=function(e,t,r,s){void 0===r&&(r=0),void 0===s&&(s=null);for(var n="",a=r,l=s,h=0;h<e.length;h++){var c=e[h];n+=c[i],0===c[o]&&(r>=h&&a--,s>=h&&l--)}var u=l||e.length;if(t){var f=n.search(/\s+$/);if(-1!==f&&(u=Math.min(u,f)),u<=a)return""}return n.substring(a,u)}},function(e,t,r){"use strict";function i(e,t){if(null==e.pageX)return null;for(var r=e.pageX,i=e.pageY;t&&t!==self.document.documentElement;)r-=t.offsetLeft,i-=t.offsetTop,t="offsetParent"in t?t.offsetParent:t.parentElement;return[r,i]}function o(e,t,r,o,s,n){if(!r.width||!r.height)return null;var a=i(e,t);return a?(a[0]=Math.ceil((a[0]+(n?r.width/2:0))/r.width),a[1]=Math.ceil(a[1]/r.height),a[0]=Math.min(Math.max(a[0],1),o+1),a[1]=Math.min(Math.max(a[1],1),s+1),a):null}Object.defineProperty(t,"__esModule",{value:!0}),t.getCoordsRelativeToElement=i,t.getCoords=o,t.getRawByteCoords=function(e,t,r,i,s){var n=o(e,t,r,i,s),a=n[0],l=n[1];return{x:a+=32,y:l+=32}}},function(e,t,r){"use strict";Object.defineProperty(t,"__esModule",{value:!0});var i=r(4),o=function(){function e(e){this.elem=e,i.hterm.defaultStorage=new i.lib.Storage.Memory,this.term=new i.hterm.Terminal,this.term.getPrefs().set("send-encoding","raw"),this.term.decorate(this.elem),this.io=this.term.io.push(),this.term.installKeyboard()}return e.prototype.info=function(){return{columns:this.columns,rows:this.rows}},e.prototype.output=function(e){null!=this.term.io&&this.term.io.writeUTF8(e)},e.prototype.showMessage=function(e,t){this.message=e,t>0?this.term.io.showOverlay(e,t):this.term.io.showOverlay(e,null)},e.prototype.removeMessage=function(){this.term.io.showOverlay(this.message,0)},e.prototype.setWindowTitle=function(e){this.term.setWindowTitle(e)},e.prototype.setPreferences=function(e){var t=this;Object.keys(e).forEach(function(r){t.term.getPrefs().set(r,e[r])})},e.prototype.onInput=function(e){this.io.onVTKeystroke=function(t){e(t)},this.io.sendString=function(t){e(t)}},e.prototype.onResize=function(e){var t=this;this.io.onTerminalResize=function(r,i){t.columns=r,t.rows=i,e(r,i)}},e.prototype.deactivate=function(){this.io.onVTKeystroke=function(){},this.io.sendString=function(){},this.io.onTerminalResize=function(){},this.term.uninstallKeyboard()},e.prototype.reset=function(){this.removeMessage(),this.term.installKeyboard()},e.prototype.close=function(){this.term.uninstallKeyboard()},e}();t.Hterm=o},function(e,t,r){"use strict";Object.defineProperty(t,"__esModule",{value:!0});var i=function(){function e(e,t){this.url=e,this.protocols=t}return e.prototype.create=function(){return new o(this.url,this.protocols)},e}();t.ConnectionFactory=i;var o=function(){function e(e,t){this.bare=new WebSocket(e,t)}return e.prototype.open=function(){},e.prototype.close=function(){this.bare.close()},e.prototype.send=function(e){this.bare.send(e)},e.prototype.isOpen=function(){return this.bare.readyState==WebSocket.CONNECTING||this.bare.readyState==WebSocket.OPEN},e.prototype.onOpen=function(e){this.bare.onopen=function(t){e()}},e.prototype.onReceive=function(e){this.bare.onmessage=function(t){e(t.data)}},e.prototype.onClose=function(e){this.bare.onclose=function(t){e()}},e}();t.Connection=o},function(e,t,r){"use strict";Object.defineProperty(t,"__esModule",{value:!0}),t.protocols=["webtty"],t.msgInputUnknown="0",t.msgInput="1",t.msgPing="2",t.msgResizeTerminal="3",t.msgUnknownOutput="0",t.msgOutput="1",t.msgPong="2",t.msgSetWindowTitle="3",t.msgSetPreferences="4",t.msgSetReconnect="5";var i=function(){function e(e,t,r,i){this.term=e,this.connectionFactory=t,this.args=r,this.authToken=i,this.reconnect=-1}return e.prototype.open=function(){var e,r,i=this,o=this.connectionFactory.create(),s=function(){o.onOpen(function(){var r=i.term.info();o.send(JSON.stringify({Arguments:i.args,AuthToken:i.authToken}));var s=function(e,r){o.send(t.msgResizeTerminal+JSON.stringify({columns:e,rows:r}))};i.term.onResize(s),s(r.columns,r.rows),i.term.onInput(function(e){o.send(t.msgInput+e)}),e=setInterval(function(){o.send(t.msgPing)},3e4)}),o.onReceive(function(e){var r=e.slice(1);switch(e[0]){case t.msgOutput:i.term.output(atob(r));break;case t.msgPong:break;case t.msgSetWindowTitle:i.term.setWindowTitle(r);break;case t.msgSetPreferences:var o=JSON.parse(r);i.term.setPreferences(o);break;case t.msgSetReconnect:var s=JSON.parse(r);console.log("Enabling reconnect: "+s+" seconds"),i.reconnect=s}}),o.onClose(function(){clearInterval(e),i.term.deactivate(),i.term.showMessage("Connection Closed",0),i.reconnect>0&&(r=setTimeout(function(){o=i.connectionFactory.create(),i.term.reset(),s()},1e3*i.reconnect))}),o.open()};return s(),function(){clearTimeout(r),o.close()}},e}();t.WebTTY=i},function(e,t,r){"use strict";Object.defineProperty(t,"__esModule",{value:!0});var i=r(0),o=r(4);i.loadAddon("fit");var s=function(){function e(e){var t=this;this.elem=e,this.term=new i,this.message=e.ownerDocument.createElement("div"),this.message.className="xterm-overlay",this.messageTimeout=2e3,this.resizeListener=function(){t.term.fit(),t.term.scrollToBottom(),t.showMessage(String(t.term.cols)+"x"+String(t.term.rows),t.messageTimeout)},this.term.on("open",function(){t.resizeListener(),window.addEventListener("resize",function(){t.resizeListener()})}),this.term.open(e,!0),this.decoder=new o.lib.UTF8Decoder}return e.prototype.info=function(){return{columns:this.term.cols,rows:this.term.rows}},e.prototype.output=function(e){this.term.write(this.decoder.decode(e))},e.prototype.showMessage=function(e,t){var r=this;this.message.textContent=e,this.elem.appendChild(this.message),this.messageTimer&&clearTimeout(this.messageTimer),t>0&&(this.messageTimer=setTimeout(function(){r.elem.removeChild(r.message)},t))},e.prototype.removeMessage=function(){this.message.parentNode==this.elem&&this.elem.removeChild(this.message)},e.prototype.setWindowTitle=function(e){document.title=e},e.prototype.setPreferences=function(e){},e.prototype.onInput=function(e){this.term.on("data",function(t){e(t)})},e.prototype.onResize=function(e){this.term.on("resize",function(t){e(t.cols,t.rows)})},e.prototype.deactivate=function(){this.term.off("data"),this.term.off("resize"),this.term.blur()},e.prototype.reset=function(){this.removeMessage(),this.term.clear()},e.prototype.close=function(){window.removeEventListener("resize",this.resizeListener),this.term.destroy()},e}();t.Xterm=s},function(e,t,r){"use strict";Object.defineProperty(t,"__esModule",{value:!0});var i=r(30),o=function(){function e(e){this._terminal=e,this.clear()}return Object.defineProperty(e.prototype,"lines",{get:function(){return this._lines},enumerable:!0,configurable:!0}),e.prototype.fillViewportRows=function(){if(0===this._lines.length)for(var e=this._terminal.rows;e--;)this.lines.push(this._terminal.blankLine())},e.prototype.clear=function(){this.ydisp=0,this.ybase=0,this.y=0,this.x=0,this.scrollBottom=0,this.scrollTop=0,this.tabs={},this._lines=new i.CircularList(this._terminal.scrollback),this.scrollBottom=this._terminal.rows-1},e.prototype.resize=function(e,t){if(0!==this._lines.length){if(this._terminal.cols<e)for(var r=[this._terminal.defAttr," ",1],i=0;i<this._lines.length;i++)for(void 0===this._lines.get(i)&&this._lines.set(i,this._terminal.blankLine(void 0,void 0,e));this._lines.get(i).length<e;)this._lines.get(i).push(r);var o=0;if(this._terminal.rows<t)for(var s=this._terminal.rows;s<t;s++)this._lines.length<t+this.ybase&&(this.ybase>0&&this._lines.length<=this.ybase+this.y+o+1?(this.ybase--,o++,this.ydisp>0&&this.ydisp--):this._lines.push(this._terminal.blankLine(void 0,void 0,e)));else for(s=this._terminal.rows;s>t;s--)this._lines.length>t+this.ybase&&(this._lines.length>this.ybase+this.y+1?this._lines.pop():(this.ybase++,this.ydisp++));this.y>=t&&(this.y=t-1),o&&(this.y+=o),this.x>=e&&(this.x=e-1),this.scrollTop=0,this.scrollBottom=t-1}},e}();t.Buffer=o},function(e,t,r){"use strict";var i=this&&this.__extends||function(){var e=Object.setPrototypeOf||{__proto__:[]}instanceof Array&&function(e,t){e.__proto__=t}||function(e,t){for(var r in t)t.hasOwnProperty(r)&&(e[r]=t[r])};return function(t,r){function i(){this.constructor=t}e(t,r),t.prototype=null===r?Object.create(r):(i.prototype=r.prototype,new i)}}();Object.defineProperty(t,"__esModule",{value:!0});var o=r(18),s=function(e){function t(t){var r=e.call(this)||this;return r._terminal=t,r._normal=new o.Buffer(r._terminal),r._normal.fillViewportRows(),r._alt=new o.Buffer(r._terminal),r._activeBuffer=r._normal,r}return i(t,e),Object.defineProperty(t.prototype,"alt",{get:function(){return this._alt},enumerable:!0,configurable:!0}),Object.defineProperty(t.prototype,"active",{get:function(){return this._activeBuffer},enumerable:!0,configurable:!0}),Object.defineProperty(t.prototype,"normal",{get:function(){return this._normal},enumerable:!0,configurable:!0}),t.prototype.activateNormalBuffer=function(){this._alt.clear(),this._activeBuffer=this._normal,this.emit("activate",this._normal)},t.prototype.activateAltBuffer=function(){this._alt.fillViewportRows(),this._activeBuffer=this._alt,this.emit("activate",this._alt)},t.prototype.resize=function(e,t){this._normal.resize(e,t),this._alt.resize(e,t)},t}(r(1).EventEmitter);t.BufferSet=s},function(e,t,r){"use strict";Object.defineProperty(t,"__esModule",{value:!0});var i=function(){function e(e,t,r){this.textarea=e,this.compositionView=t,this.terminal=r,this.isComposing=!1,this.isSendingComposition=!1,this.compositionPosition={start:null,end:null}}return e.prototype.compositionstart=function(){this.isComposing=!0,this.compositionPosition.start=this.textarea.value.length,this.compositionView.textContent="",this.compositionView.classList.add("active")},e.prototype.compositionupdate=function(e){var t=this;this.compositionView.textContent=e.data,this.updateCompositionElements(),setTimeout(function(){t.compositionPosition.end=t.textarea.value.length},0)},e.prototype.compositionend=function(){this.finalizeComposition(!0)},e.prototype.keydown=function(e){if(this.isComposing||this.isSendingComposition){if(229===e.keyCode)return!1;if(16===e.keyCode||17===e.keyCode||18===e.keyCode)return!1;this.finalizeComposition(!1)}return 229!==e.keyCode||(this.handleAnyTextareaChanges(),!1)},e.prototype.finalizeComposition=function(e){var t=this;if(this.compositionView.classList.remove("active"),this.isComposing=!1,this.clearTextareaPosition(),e){var r={start:this.compositionPosition.start,end:this.compositionPosition.end};this.isSendingComposition=!0,setTimeout(function(){if(t.isSendingComposition){t.isSendingComposition=!1;var e=void 0;e=t.isComposing?t.textarea.value.substring(r.start,r.end):t.textarea.value.substring(r.start),t.terminal.handler(e)}},0)}else{this.isSendingComposition=!1;var i=this.textarea.value.substring(this.compositionPosition.start,this.compositionPosition.end);this.terminal.handler(i)}},e.prototype.handleAnyTextareaChanges=function(){var e=this,t=this.textarea.value;setTimeout(function(){if(!e.isComposing){var r=e.textarea.value.replace(t,"");r.length>0&&e.terminal.handler(r)}},0)},e.prototype.updateCompositionElements=function(e){var t=this;if(this.isComposing){var r=this.terminal.element.querySelector(".terminal-cursor");if(r){var i=this.terminal.element.querySelector(".xterm-rows").offsetTop+r.offsetTop;this.compositionView.style.left=r.offsetLeft+"px",this.compositionView.style.top=i+"px",this.compositionView.style.height=r.offsetHeight+"px",this.compositionView.style.lineHeight=r.offsetHeight+"px";var o=this.compositionView.getBoundingClientRect();this.textarea.style.left=r.offsetLeft+"px",this.textarea.style.top=i+"px",this.textarea.style.width=o.width+"px",this.textarea.style.height=o.height+"px",this.textarea.style.lineHeight=o.height+"px"}e||setTimeout(function(){return t.updateCompositionElements(!0)},0)}},e.prototype.clearTextareaPosition=function(){this.textarea.style.left="",this.textarea.style.top=""},e}();t.CompositionHelper=i},function(e,t,r){"use strict";Object.defineProperty(t,"__esModule",{value:!0});var i=r(2),o=r(5),s=function(){function e(e){this._terminal=e}return e.prototype.addChar=function(e,r){if(e>=" "){var i=t.wcwidth(r);this._terminal.charset&&this._terminal.charset[e]&&(e=this._terminal.charset[e]);var o=this._terminal.buffer.y+this._terminal.buffer.ybase;if(!i&&this._terminal.buffer.x)return void(this._terminal.buffer.lines.get(o)[this._terminal.buffer.x-1]&&(this._terminal.buffer.lines.get(o)[this._terminal.buffer.x-1][2]?this._terminal.buffer.lines.get(o)[this._terminal.buffer.x-1][1]+=e:this._terminal.buffer.lines.get(o)[this._terminal.buffer.x-2]&&(this._terminal.buffer.lines.get(o)[this._terminal.buffer.x-2][1]+=e),this._terminal.updateRange(this._terminal.buffer.y)));if(this._terminal.buffer.x+i-1>=this._terminal.cols)if(this._terminal.wraparoundMode)this._terminal.buffer.x=0,this._terminal.buffer.y++,this._terminal.buffer.y>this._terminal.buffer.scrollBottom?(this._terminal.buffer.y--,this._terminal.scroll(!0)):this._terminal.buffer.lines.get(this._terminal.buffer.y).isWrapped=!0;else if(2===i)return;if(o=this._terminal.buffer.y+this._terminal.buffer.ybase,this._terminal.insertMode)for(var s=0;s<i;++s){0===this._terminal.buffer.lines.get(this._terminal.buffer.y+this._terminal.buffer.ybase).pop()[2]&&this._terminal.buffer.lines.get(o)[this._terminal.cols-2]&&2===this._terminal.buffer.lines.get(o)[this._terminal.cols-2][2]&&(this._terminal.buffer.lines.get(o)[this._terminal.cols-2]=[this._terminal.curAttr," ",1]),this._terminal.buffer.lines.get(o).splice(this._terminal.buffer.x,0,[this._terminal.curAttr," ",1])}this._terminal.buffer.lines.get(o)[this._terminal.buffer.x]=[this._terminal.curAttr,e,i],this._terminal.buffer.x++,this._terminal.updateRange(this._terminal.buffer.y),2===i&&(this._terminal.buffer.lines.get(o)[this._terminal.buffer.x]=[this._terminal.curAttr,"",0],this._terminal.buffer.x++)}},e.prototype.bell=function(){var e=this;this._terminal.visualBell&&(this._terminal.element.style.borderColor="white",setTimeout(function(){return e._terminal.element.style.borderColor=""},10),this._terminal.popOnBell&&this._terminal.focus())},e.prototype.lineFeed=function(){this._terminal.convertEol&&(this._terminal.buffer.x=0),this._terminal.buffer.y++,this._terminal.buffer.y>this._terminal.buffer.scrollBottom&&(this._terminal.buffer.y--,this._terminal.scroll()),this._terminal.buffer.x>=this._terminal.cols&&this._terminal.buffer.x--,this._terminal.emit("lineFeed")},e.prototype.carriageReturn=function(){this._terminal.buffer.x=0},e.prototype.backspace=function(){this._terminal.buffer.x>0&&this._terminal.buffer.x--},e.prototype.tab=function(){this._terminal.buffer.x=this._terminal.nextStop()},e.prototype.shiftOut=function(){this._terminal.setgLevel(1)},e.prototype.shiftIn=function(){this._terminal.setgLevel(0)},e.prototype.insertChars=function(e){var t,r,i,o;for((t=e[0])<1&&(t=1),r=this._terminal.buffer.y+this._terminal.buffer.ybase,i=this._terminal.buffer.x,o=[this._terminal.eraseAttr()," ",1];t--&&i<this._terminal.cols;)this._terminal.buffer.lines.get(r).splice(i++,0,o),this._terminal.buffer.lines.get(r).pop()},e.prototype.cursorUp=function(e){var t=e[0];t<1&&(t=1),this._terminal.buffer.y-=t,this._terminal.buffer.y<0&&(this._terminal.buffer.y=0)},e.prototype.cursorDown=function(e){var t=e[0];t<1&&(t=1),this._terminal.buffer.y+=t,this._terminal.buffer.y>=this._terminal.rows&&(this._terminal.buffer.y=this._terminal.rows-1),this._terminal.buffer.x>=this._terminal.cols&&this._terminal.buffer.x--},e.prototype.cursorForward=function(e){var t=e[0];t<1&&(t=1),this._terminal.buffer.x+=t,this._terminal.buffer.x>=this._terminal.cols&&(this._terminal.buffer.x=this._terminal.cols-1)},e.prototype.cursorBackward=function(e){var t=e[0];t<1&&(t=1),this._terminal.buffer.x>=this._terminal.cols&&this._terminal.buffer.x--,this._terminal.buffer.x-=t,this._terminal.buffer.x<0&&(this._terminal.buffer.x=0)},e.prototype.cursorNextLine=function(e){var t=e[0];t<1&&(t=1),this._terminal.buffer.y+=t,this._terminal.buffer.y>=this._terminal.rows&&(this._terminal.buffer.y=this._terminal.rows-1),this._terminal.buffer.x=0},e.prototype.cursorPrecedingLine=function(e){var t=e[0];t<1&&(t=1),this._terminal.buffer.y-=t,this._terminal.buffer.y<0&&(this._terminal.buffer.y=0),this._terminal.buffer.x=0},e.prototype.cursorCharAbsolute=function(e){var t=e[0];t<1&&(t=1),this._terminal.buffer.x=t-1},e.prototype.cursorPosition=function(e){var t,r;t=e[0]-1,r=e.length>=2?e[1]-1:0,t<0?t=0:t>=this._terminal.rows&&(t=this._terminal.rows-1),r<0?r=0:r>=this._terminal.cols&&(r=this._terminal.cols-1),this._terminal.buffer.x=r,this._terminal.buffer.y=t},e.prototype.cursorForwardTab=function(e){for(var t=e[0]||1;t--;)this._terminal.buffer.x=this._terminal.nextStop()},e.prototype.eraseInDisplay=function(e){var t;switch(e[0]){case 0:for(this._terminal.eraseRight(this._terminal.buffer.x,this._terminal.buffer.y),t=this._terminal.buffer.y+1;t<this._terminal.rows;t++)this._terminal.eraseLine(t);break;case 1:for(this._terminal.eraseLeft(this._terminal.buffer.x,this._terminal.buffer.y),t=this._terminal.buffer.y;t--;)this._terminal.eraseLine(t);break;case 2:for(t=this._terminal.rows;t--;)this._terminal.eraseLine(t);break;case 3:var r=this._terminal.buffer.lines.length-this._terminal.rows;r>0&&(this._terminal.buffer.lines.trimStart(r),this._terminal.buffer.ybase=Math.max(this._terminal.buffer.ybase-r,0),this._terminal.buffer.ydisp=Math.max(this._terminal.buffer.ydisp-r,0),this._terminal.emit("scroll",0))}},e.prototype.eraseInLine=function(e){switch(e[0]){case 0:this._terminal.eraseRight(this._terminal.buffer.x,this._terminal.buffer.y);break;case 1:this._terminal.eraseLeft(this._terminal.buffer.x,this._terminal.buffer.y);break;case 2:this._terminal.eraseLine(this._terminal.buffer.y)}},e.prototype.insertLines=function(e){var t,r,i;for((t=e[0])<1&&(t=1),r=this._terminal.buffer.y+this._terminal.buffer.ybase,i=this._terminal.rows-1-this._terminal.buffer.scrollBottom,i=this._terminal.rows-1+this._terminal.buffer.ybase-i+1;t--;)this._terminal.buffer.lines.length===this._terminal.buffer.lines.maxLength&&(this._terminal.buffer.lines.trimStart(1),this._terminal.buffer.ybase--,this._terminal.buffer.ydisp--,r--,i--),this._terminal.buffer.lines.splice(r,0,this._terminal.blankLine(!0)),this._terminal.buffer.lines.splice(i,1);this._terminal.updateRange(this._terminal.buffer.y),this._terminal.updateRange(this._terminal.buffer.scrollBottom)},e.prototype.deleteLines=function(e){var t,r,i;for((t=e[0])<1&&(t=1),r=this._terminal.buffer.y+this._terminal.buffer.ybase,i=this._terminal.rows-1-this._terminal.buffer.scrollBottom,i=this._terminal.rows-1+this._terminal.buffer.ybase-i;t--;)this._terminal.buffer.lines.length===this._terminal.buffer.lines.maxLength&&(this._terminal.buffer.lines.trimStart(1),this._terminal.buffer.ybase-=1,this._terminal.buffer.ydisp-=1),this._terminal.buffer.lines.splice(i+1,0,this._terminal.blankLine(!0)),this._terminal.buffer.lines.splice(r,1);this._terminal.updateRange(this._terminal.buffer.y),this._terminal.updateRange(this._terminal.buffer.scrollBottom)},e.prototype.deleteChars=function(e){var t,r,i;for((t=e[0])<1&&(t=1),r=this._terminal.buffer.y+this._terminal.buffer.ybase,i=[this._terminal.eraseAttr()," ",1];t--;)this._terminal.buffer.lines.get(r).splice(this._terminal.buffer.x,1),this._terminal.buffer.lines.get(r).push(i)},e.prototype.scrollUp=function(e){for(var t=e[0]||1;t--;)this._terminal.buffer.lines.splice(this._terminal.buffer.ybase+this._terminal.buffer.scrollTop,1),this._terminal.buffer.lines.splice(this._terminal.buffer.ybase+this._terminal.buffer.scrollBottom,0,this._terminal.blankLine());this._terminal.updateRange(this._terminal.buffer.scrollTop),this._terminal.updateRange(this._terminal.buffer.scrollBottom)},e.prototype.scrollDown=function(e){for(var t=e[0]||1;t--;)this._terminal.buffer.lines.splice(this._terminal.buffer.ybase+this._terminal.buffer.scrollBottom,1),this._terminal.buffer.lines.splice(this._terminal.buffer.ybase+this._terminal.buffer.scrollTop,0,this._terminal.blankLine());this._terminal.updateRange(this._terminal.buffer.scrollTop),this._terminal.updateRange(this._terminal.buffer.scrollBottom)},e.prototype.eraseChars=function(e){var t,r,i,o;for((t=e[0])<1&&(t=1),r=this._terminal.buffer.y+this._terminal.buffer.ybase,i=this._terminal.buffer.x,o=[this._terminal.eraseAttr()," ",1];t--&&i<this._terminal.cols;)this._terminal.buffer.lines.get(r)[i++]=o},e.prototype.cursorBackwardTab=function(e){for(var t=e[0]||1;t--;)this._terminal.buffer.x=this._terminal.prevStop()},e.prototype.charPosAbsolute=function(e){var t=e[0];t<1&&(t=1),this._terminal.buffer.x=t-1,this._terminal.buffer.x>=this._terminal.cols&&(this._terminal.buffer.x=this._terminal.cols-1)},e.prototype.HPositionRelative=function(e){var t=e[0];t<1&&(t=1),this._terminal.buffer.x+=t,this._terminal.buffer.x>=this._terminal.cols&&(this._terminal.buffer.x=this._terminal.cols-1)},e.prototype.repeatPrecedingCharacter=function(e){for(var t=e[0]||1,r=this._terminal.buffer.lines.get(this._terminal.buffer.ybase+this._terminal.buffer.y),i=r[this._terminal.buffer.x-1]||[this._terminal.defAttr," ",1];t--;)r[this._terminal.buffer.x++]=i},e.prototype.sendDeviceAttributes=function(e){e[0]>0||(this._terminal.prefix?">"===this._terminal.prefix&&(this._terminal.is("xterm")?this._terminal.send(i.C0.ESC+"[>0;276;0c"):this._terminal.is("rxvt-unicode")?this._terminal.send(i.C0.ESC+"[>85;95;0c"):this._terminal.is("linux")?this._terminal.send(e[0]+"c"):this._terminal.is("screen")&&this._terminal.send(i.C0.ESC+"[>83;40003;0c")):this._terminal.is("xterm")||this._terminal.is("rxvt-unicode")||this._terminal.is("screen")?this._terminal.send(i.C0.ESC+"[?1;2c"):this._terminal.is("linux")&&this._terminal.send(i.C0.ESC+"[?6c"))},e.prototype.linePosAbsolute=function(e){var t=e[0];t<1&&(t=1),this._terminal.buffer.y=t-1,this._terminal.buffer.y>=this._terminal.rows&&(this._terminal.buffer.y=this._terminal.rows-1)},e.prototype.VPositionRelative=function(e){var t=e[0];t<1&&(t=1),this._terminal.buffer.y+=t,this._terminal.buffer.y>=this._terminal.rows&&(this._terminal.buffer.y=this._terminal.rows-1),this._terminal.buffer.x>=this._terminal.cols&&this._terminal.buffer.x--},e.prototype.HVPosition=function(e){e[0]<1&&(e[0]=1),e[1]<1&&(e[1]=1),this._terminal.buffer.y=e[0]-1,this._terminal.buffer.y>=this._terminal.rows&&(this._terminal.buffer.y=this._terminal.rows-1),this._terminal.buffer.x=e[1]-1,this._terminal.buffer.x>=this._terminal.cols&&(this._terminal.buffer.x=this._terminal.cols-1)},e.prototype.tabClear=function(e){var t=e[0];t<=0?delete this._terminal.buffer.tabs[this._terminal.buffer.x]:3===t&&(this._terminal.buffer.tabs={})},e.prototype.setMode=function(e){if(e.length>1)for(var t=0;t<e.length;t++)this.setMode([e[t]]);else if(this._terminal.prefix){if("?"===this._terminal.prefix)switch(e[0]){case 1:this._terminal.applicationCursor=!0;break;case 2:this._terminal.setgCharset(0,o.DEFAULT_CHARSET),this._terminal.setgCharset(1,o.DEFAULT_CHARSET),this._terminal.setgCharset(2,o.DEFAULT_CHARSET),this._terminal.setgCharset(3,o.DEFAULT_CHARSET);break;case 3:this._terminal.savedCols=this._terminal.cols,this._terminal.resize(132,this._terminal.rows);break;case 6:this._terminal.originMode=!0;break;case 7:this._terminal.wraparoundMode=!0;break;case 12:break;case 66:this._terminal.log("Serial port requested application keypad."),this._terminal.applicationKeypad=!0,this._terminal.viewport.syncScrollArea();break;case 9:case 1e3:case 1002:case 1003:this._terminal.x10Mouse=9===e[0],this._terminal.vt200Mouse=1e3===e[0],this._terminal.normalMouse=e[0]>1e3,this._terminal.mouseEvents=!0,this._terminal.element.classList.add("enable-mouse-events"),this._terminal.selectionManager.disable(),this._terminal.log("Binding to mouse events.");break;case 1004:this._terminal.sendFocus=!0;break;case 1005:this._terminal.utfMouse=!0;break;case 1006:this._terminal.sgrMouse=!0;break;case 1015:this._terminal.urxvtMouse=!0;break;case 25:this._terminal.cursorHidden=!1;break;case 1049:case 47:case 1047:this._terminal.buffers.activateAltBuffer(),this._terminal.viewport.syncScrollArea(),this._terminal.showCursor()}}else switch(e[0]){case 4:this._terminal.insertMode=!0}},e.prototype.resetMode=function(e){if(e.length>1)for(var t=0;t<e.length;t++)this.resetMode([e[t]]);else if(this._terminal.prefix){if("?"===this._terminal.prefix)switch(e[0]){case 1:this._terminal.applicationCursor=!1;break;case 3:132===this._terminal.cols&&this._terminal.savedCols&&this._terminal.resize(this._terminal.savedCols,this._terminal.rows),delete this._terminal.savedCols;break;case 6:this._terminal.originMode=!1;break;case 7:this._terminal.wraparoundMode=!1;break;case 12:break;case 66:this._terminal.log("Switching back to normal keypad."),this._terminal.applicationKeypad=!1,this._terminal.viewport.syncScrollArea();break;case 9:case 1e3:case 1002:case 1003:this._terminal.x10Mouse=!1,this._terminal.vt200Mouse=!1,this._terminal.normalMouse=!1,this._terminal.mouseEvents=!1,this._terminal.element.classList.remove("enable-mouse-events"),this._terminal.selectionManager.enable();break;case 1004:this._terminal.sendFocus=!1;break;case 1005:this._terminal.utfMouse=!1;break;case 1006:this._terminal.sgrMouse=!1;break;case 1015:this._terminal.urxvtMouse=!1;break;case 25:this._terminal.cursorHidden=!0;break;case 1049:case 47:case 1047:this._terminal.buffers.activateNormalBuffer(),this._terminal.selectionManager.setBuffer(this._terminal.buffer.lines),this._terminal.refresh(0,this._terminal.rows-1),this._terminal.viewport.syncScrollArea(),this._terminal.showCursor()}}else switch(e[0]){case 4:this._terminal.insertMode=!1}},e.prototype.charAttributes=function(e){if(1!==e.length||0!==e[0]){for(var t,r=e.length,i=0,o=this._terminal.curAttr>>18,s=this._terminal.curAttr>>9&511,n=511&this._terminal.curAttr;i<r;i++)(t=e[i])>=30&&t<=37?s=t-30:t>=40&&t<=47?n=t-40:t>=90&&t<=97?s=(t+=8)-90:t>=100&&t<=107?n=(t+=8)-100:0===t?(o=this._terminal.defAttr>>18,s=this._terminal.defAttr>>9&511,n=511&this._terminal.defAttr):1===t?o|=1:4===t?o|=2:5===t?o|=4:7===t?o|=8:8===t?o|=16:22===t?o&=-2:24===t?o&=-3:25===t?o&=-5:27===t?o&=-9:28===t?o&=-17:39===t?s=this._terminal.defAttr>>9&511:49===t?n=511&this._terminal.defAttr:38===t?2===e[i+1]?(i+=2,-1===(s=this._terminal.matchColor(255&e[i],255&e[i+1],255&e[i+2]))&&(s=511),i+=2):5===e[i+1]&&(s=t=255&e[i+=2]):48===t?2===e[i+1]?(i+=2,-1===(n=this._terminal.matchColor(255&e[i],255&e[i+1],255&e[i+2]))&&(n=511),i+=2):5===e[i+1]&&(n=t=255&e[i+=2]):100===t?(s=this._terminal.defAttr>>9&511,n=511&this._terminal.defAttr):this._terminal.error("Unknown SGR attribute: %d.",t);this._terminal.curAttr=o<<18|s<<9|n}else this._terminal.curAttr=this._terminal.defAttr},e.prototype.deviceStatus=function(e){if(this._terminal.prefix){if("?"===this._terminal.prefix)switch(e[0]){case 6:this._terminal.send(i.C0.ESC+"[?"+(this._terminal.buffer.y+1)+";"+(this._terminal.buffer.x+1)+"R")}}else switch(e[0]){case 5:this._terminal.send(i.C0.ESC+"[0n");break;case 6:this._terminal.send(i.C0.ESC+"["+(this._terminal.buffer.y+1)+";"+(this._terminal.buffer.x+1)+"R")}},e.prototype.softReset=function(e){this._terminal.cursorHidden=!1,this._terminal.insertMode=!1,this._terminal.originMode=!1,this._terminal.wraparoundMode=!0,this._terminal.applicationKeypad=!1,this._terminal.viewport.syncScrollArea(),this._terminal.applicationCursor=!1,this._terminal.buffer.scrollTop=0,this._terminal.buffer.scrollBottom=this._terminal.rows-1,this._terminal.curAttr=this._terminal.defAttr,this._terminal.buffer.x=this._terminal.buffer.y=0,this._terminal.charset=null,this._terminal.glevel=0,this._terminal.charsets=[null]},e.prototype.setCursorStyle=function(e){var t=e[0]<1?1:e[0];switch(t){case 1:case 2:this._terminal.setOption("cursorStyle","block");break;case 3:case 4:this._terminal.setOption("cursorStyle","underline");break;case 5:case 6:this._terminal.setOption("cursorStyle","bar")}var r=t%2==1;this._terminal.setOption("cursorBlink",r)},e.prototype.setScrollRegion=function(e){this._terminal.prefix||(this._terminal.buffer.scrollTop=(e[0]||1)-1,this._terminal.buffer.scrollBottom=(e[1]&&e[1]<=this._terminal.rows?e[1]:this._terminal.rows)-1,this._terminal.buffer.x=0,this._terminal.buffer.y=0)},e.prototype.saveCursor=function(e){this._terminal.buffer.savedX=this._terminal.buffer.x,this._terminal.buffer.savedY=this._terminal.buffer.y},e.prototype.restoreCursor=function(e){this._terminal.buffer.x=this._terminal.buffer.savedX||0,this._terminal.buffer.y=this._terminal.buffer.savedY||0},e}();t.InputHandler=s,t.wcwidth=function(e){var t=[[768,879],[1155,1158],[1160,1161],[1425,1469],[1471,1471],[1473,1474],[1476,1477],[1479,1479],[1536,1539],[1552,1557],[1611,1630],[1648,1648],[1750,1764],[1767,1768],[1770,1773],[1807,1807],[1809,1809],[1840,1866],[1958,1968],[2027,2035],[2305,2306],[2364,2364],[2369,2376],[2381,2381],[2385,2388],[2402,2403],[2433,2433],[2492,2492],[2497,2500],[2509,2509],[2530,2531],[2561,2562],[2620,2620],[2625,2626],[2631,2632],[2635,2637],[2672,2673],[2689,2690],[2748,2748],[2753,2757],[2759,2760],[2765,2765],[2786,2787],[2817,2817],[2876,2876],[2879,2879],[2881,2883],[2893,2893],[2902,2902],[2946,2946],[3008,3008],[3021,3021],[3134,3136],[3142,3144],[3146,3149],[3157,3158],[3260,3260],[3263,3263],[3270,3270],[3276,3277],[3298,3299],[3393,3395],[3405,3405],[3530,3530],[3538,3540],[3542,3542],[3633,3633],[3636,3642],[3655,3662],[3761,3761],[3764,3769],[3771,3772],[3784,3789],[3864,3865],[3893,3893],[3895,3895],[3897,3897],[3953,3966],[3968,3972],[3974,3975],[3984,3991],[3993,4028],[4038,4038],[4141,4144],[4146,4146],[4150,4151],[4153,4153],[4184,4185],[4448,4607],[4959,4959],[5906,5908],[5938,5940],[5970,5971],[6002,6003],[6068,6069],[6071,6077],[6086,6086],[6089,6099],[6109,6109],[6155,6157],[6313,6313],[6432,6434],[6439,6440],[6450,6450],[6457,6459],[6679,6680],[6912,6915],[6964,6964],[6966,6970],[6972,6972],[6978,6978],[7019,7027],[7616,7626],[7678,7679],[8203,8207],[8234,8238],[8288,8291],[8298,8303],[8400,8431],[12330,12335],[12441,12442],[43014,43014],[43019,43019],[43045,43046],[64286,64286],[65024,65039],[65056,65059],[65279,65279],[65529,65531]],r=[[68097,68099],[68101,68102],[68108,68111],[68152,68154],[68159,68159],[119143,119145],[119155,119170],[119173,119179],[119210,119213],[119362,119364],[917505,917505],[917536,917631],[917760,917999]];function i(e,t){var r,i=0,o=t.length-1;if(e<t[0][0]||e>t[o][1])return!1;for(;o>=i;)if(e>t[r=i+o>>1][1])i=r+1;else{if(!(e<t[r][0]))return!0;o=r-1}return!1}function o(r){return 0===r?e.nul:r<32||r>=127&&r<160?e.control:i(r,t)?0:function(e){return e>=4352&&(e<=4447||9001===e||9002===e||e>=11904&&e<=42191&&12351!==e||e>=44032&&e<=55203||e>=63744&&e<=64255||e>=65040&&e<=65049||e>=65072&&e<=65135||e>=65280&&e<=65376||e>=65504&&e<=65510)}(r)?2:1}var s=0|e.control,n=null;return function(e){if((e|=0)<32)return 0|s;if(e<127)return 1;var t=n||function(){n="undefined"==typeof Uint32Array?new Array(4096):new Uint32Array(4096);for(var e=0;e<4096;++e){for(var t=0,r=16;r--;)t=t<<2|o(16*e+r);n[e]=t}return n}();return e<65536?t[e>>4]>>((15&e)<<1)&3:function(e){return i(e,r)?0:e>=131072&&e<=196605||e>=196608&&e<=262141?2:1}(e)}}({nul:0,control:0})},function(e,t,r){"use strict";Object.defineProperty(t,"__esModule",{value:!0});var i=new RegExp("(?:^|[^\\da-z\\.-]+)((https?:\\/\\/)((([\\da-z\\.-]+)\\.([a-z\\.]{2,6}))|((\\d{1,3}\\.){3}\\d{1,3})|(localhost))(:\\d{1,5})?(\\/[\\/\\w\\.\\-%~]*)*(\\?[0-9\\w\\[\\]\\(\\)\\/\\?\\!#@$%&'*+,:;~\\=\\.\\-]*)?(#[0-9\\w\\[\\]\\(\\)\\/\\?\\!#@$%&'*+,:;~\\=\\.\\-]*)?)($|[^\\/\\w\\.\\-%]+)"),o=0,s=function(){function e(){this._nextLinkMatcherId=o,this._rowTimeoutIds=[],this._linkMatchers=[],this.registerLinkMatcher(i,null,{matchIndex:1})}return e.prototype.attachToDom=function(e,t){this._document=e,this._rows=t},e.prototype.linkifyRow=function(t){if(this._document){var r=this._rowTimeoutIds[t];r&&clearTimeout(r),this._rowTimeoutIds[t]=setTimeout(this._linkifyRow.bind(this,t),e.TIME_BEFORE_LINKIFY)}},e.prototype.setHypertextLinkHandler=function(e){this._linkMatchers[o].handler=e},e.prototype.setHypertextValidationCallback=function(e){this._linkMatchers[o].validationCallback=e},e.prototype.registerLinkMatcher=function(e,t,r){if(void 0===r&&(r={}),this._nextLinkMatcherId!==o&&!t)throw new Error("handler must be defined");var i={id:this._nextLinkMatcherId++,regex:e,handler:t,matchIndex:r.matchIndex,validationCallback:r.validationCallback,priority:r.priority||0};return this._addLinkMatcherToList(i),i.id},e.prototype._addLinkMatcherToList=function(e){if(0!==this._linkMatchers.length){for(var t=this._linkMatchers.length-1;t>=0;t--)if(e.priority<=this._linkMatchers[t].priority)return void this._linkMatchers.splice(t+1,0,e);this._linkMatchers.splice(0,0,e)}else this._linkMatchers.push(e)},e.prototype.deregisterLinkMatcher=function(e){for(var t=1;t<this._linkMatchers.length;t++)if(this._linkMatchers[t].id===e)return this._linkMatchers.splice(t,1),!0;return!1},e.prototype._linkifyRow=function(e){var t=this._rows[e];if(t){t.textContent;for(var r=0;r<this._linkMatchers.length;r++){var i=this._linkMatchers[r],o=this._doLinkifyRow(t,i);if(o.length>0){if(i.validationCallback)for(var s=function(e){var t=o[e];i.validationCallback(t.textContent,t,function(e){e||t.classList.add("xterm-invalid-link")})},n=0;n<o.length;n++)s(n);return}}}},e.prototype._doLinkifyRow=function(e,t){var r=[],i=t.id===o,s=e.childNodes,n=e.textContent.match(t.regex);if(!n||0===n.length)return r;for(var a=n["number"!=typeof t.matchIndex?0:t.matchIndex],l=n.index+a.length,h=0;h<s.length;h++){var c=s[h],u=c.textContent.indexOf(a);if(u>=0){var f=this._createAnchorElement(a,t.handler,i);if(c.textContent.length===a.length)if(3===c.nodeType)this._replaceNode(c,f);else{var p=c;if("A"===p.nodeName)return r;p.innerHTML="",p.appendChild(f)}else if(c.childNodes.length>1)for(var d=0;d<c.childNodes.length;d++){var g=c.childNodes[d],m=g.textContent.indexOf(a);if(-1!==m){this._replaceNodeSubstringWithNode(g,f,a,m);break}}else{h+=this._replaceNodeSubstringWithNode(c,f,a,u)}if(r.push(f),!(n=e.textContent.substring(l).match(t.regex))||0===n.length)return r;a=n["number"!=typeof t.matchIndex?0:t.matchIndex],l+=n.index+a.length}}return r},e.prototype._createAnchorElement=function(e,t,r){var i=this._document.createElement("a");return i.textContent=e,i.draggable=!1,r?(i.href=e,i.target="_blank",i.addEventListener("click",function(r){if(t)return t(r,e)})):i.addEventListener("click",function(r){if(!i.classList.contains("xterm-invalid-link"))return t(r,e)}),i},e.prototype._replaceNode=function(e){for(var t=[],r=1;r<arguments.length;r++)t[r-1]=arguments[r];for(var i=e.parentNode,o=0;o<t.length;o++)i.insertBefore(t[o],e);i.removeChild(e)},e.prototype._replaceNodeSubstringWithNode=function(e,t,r,i){if(1===e.childNodes.length&&(e=e.childNodes[0]),3!==e.nodeType)throw new Error("targetNode must be a text node or only contain a single text node");var o=e.textContent;if(0===i){var s=o.substring(r.length),n=this._document.createTextNode(s);return this._replaceNode(e,t,n),0}if(i===e.textContent.length-r.length){var a=o.substring(0,i),l=this._document.createTextNode(a);return this._replaceNode(e,l,t),0}var h=o.substring(0,i),c=this._document.createTextNode(h),u=o.substring(i+r.length),f=this._document.createTextNode(u);return this._replaceNode(e,c,t,f),1},e}();s.TIME_BEFORE_LINKIFY=200,t.Linkifier=s},function(e,t,r){"use strict";Object.defineProperty(t,"__esModule",{value:!0});var i=r(2),o=r(5),s={};s[i.C0.BEL]=function(e,t){return t.bell()},s[i.C0.LF]=function(e,t){return t.lineFeed()},s[i.C0.VT]=s[i.C0.LF],s[i.C0.FF]=s[i.C0.LF],s[i.C0.CR]=function(e,t){return t.carriageReturn()},s[i.C0.BS]=function(e,t){return t.backspace()},s[i.C0.HT]=function(e,t){return t.tab()},s[i.C0.SO]=function(e,t){return t.shiftOut()},s[i.C0.SI]=function(e,t){return t.shiftIn()},s[i.C0.ESC]=function(e,t){return e.setState(l.ESCAPED)};var n={"[":function(e,t){t.params=[],t.currentParam=0,e.setState(l.CSI_PARAM)},"]":function(e,t){t.params=[],t.currentParam=0,e.setState(l.OSC)},P:function(e,t){t.params=[],t.currentParam=0,e.setState(l.DCS)},_:function(e,t){e.setState(l.IGNORE)},"^":function(e,t){e.setState(l.IGNORE)},c:function(e,t){t.reset()},E:function(e,t){t.buffer.x=0,t.index(),e.setState(l.NORMAL)},D:function(e,t){t.index(),e.setState(l.NORMAL)},M:function(e,t){t.reverseIndex(),e.setState(l.NORMAL)},"%":function(e,t){t.setgLevel(0),t.setgCharset(0,o.DEFAULT_CHARSET),e.setState(l.NORMAL),e.skipNextChar()}};n[i.C0.CAN]=function(e){return e.setState(l.NORMAL)};var a={"?":function(e){return e.setPrefix("?")},">":function(e){return e.setPrefix(">")},"!":function(e){return e.setPrefix("!")},0:function(e){return e.setParam(10*e.getParam())},1:function(e){return e.setParam(10*e.getParam()+1)},2:function(e){return e.setParam(10*e.getParam()+2)},3:function(e){return e.setParam(10*e.getParam()+3)},4:function(e){return e.setParam(10*e.getParam()+4)},5:function(e){return e.setParam(10*e.getParam()+5)},6:function(e){return e.setParam(10*e.getParam()+6)},7:function(e){return e.setParam(10*e.getParam()+7)},8:function(e){return e.setParam(10*e.getParam()+8)},9:function(e){return e.setParam(10*e.getParam()+9)},$:function(e){return e.setPostfix("$")},'"':function(e){return e.setPostfix('"')}," ":function(e){return e.setPostfix(" ")},"'":function(e){return e.setPostfix("'")},";":function(e){return e.finalizeParam()}};a[i.C0.CAN]=function(e){return e.setState(l.NORMAL)};var l,h={};h["@"]=function(e,t,r){return e.insertChars(t)},h.A=function(e,t,r){return e.cursorUp(t)},h.B=function(e,t,r){return e.cursorDown(t)},h.C=function(e,t,r){return e.cursorForward(t)},h.D=function(e,t,r){return e.cursorBackward(t)},h.E=function(e,t,r){return e.cursorNextLine(t)},h.F=function(e,t,r){return e.cursorPrecedingLine(t)},h.G=function(e,t,r){return e.cursorCharAbsolute(t)},h.H=function(e,t,r){return e.cursorPosition(t)},h.I=function(e,t,r){return e.cursorForwardTab(t)},h.J=function(e,t,r){return e.eraseInDisplay(t)},h.K=function(e,t,r){return e.eraseInLine(t)},h.L=function(e,t,r){return e.insertLines(t)},h.M=function(e,t,r){return e.deleteLines(t)},h.P=function(e,t,r){return e.deleteChars(t)},h.S=function(e,t,r){return e.scrollUp(t)},h.T=function(e,t,r){t.length<2&&!r&&e.scrollDown(t)},h.X=function(e,t,r){return e.eraseChars(t)},h.Z=function(e,t,r){return e.cursorBackwardTab(t)},h["`"]=function(e,t,r){return e.charPosAbsolute(t)},h.a=function(e,t,r){return e.HPositionRelative(t)},h.b=function(e,t,r){return e.repeatPrecedingCharacter(t)},h.c=function(e,t,r){return e.sendDeviceAttributes(t)},h.d=function(e,t,r){return e.linePosAbsolute(t)},h.e=function(e,t,r){return e.VPositionRelative(t)},h.f=function(e,t,r){return e.HVPosition(t)},h.g=function(e,t,r){return e.tabClear(t)},h.h=function(e,t,r){return e.setMode(t)},h.l=function(e,t,r){return e.resetMode(t)},h.m=function(e,t,r){return e.charAttributes(t)},h.n=function(e,t,r){return e.deviceStatus(t)},h.p=function(e,t,r){switch(r){case"!":e.softReset(t)}},h.q=function(e,t,r,i){" "===i&&e.setCursorStyle(t)},h.r=function(e,t){return e.setScrollRegion(t)},h.s=function(e,t){return e.saveCursor(t)},h.u=function(e,t){return e.restoreCursor(t)},h[i.C0.CAN]=function(e,t,r,i,o){return o.setState(l.NORMAL)},function(e){e[e.NORMAL=0]="NORMAL",e[e.ESCAPED=1]="ESCAPED",e[e.CSI_PARAM=2]="CSI_PARAM",e[e.CSI=3]="CSI",e[e.OSC=4]="OSC",e[e.CHARSET=5]="CHARSET",e[e.DCS=6]="DCS",e[e.IGNORE=7]="IGNORE"}(l||(l={}));var c=function(){function e(e,t){this._inputHandler=e,this._terminal=t,this._state=l.NORMAL}return e.prototype.parse=function(e){var t,r,c,u,f=e.length;for(this._terminal.debug&&this._terminal.log("data: "+e),this._position=0,this._terminal.surrogate_high&&(e=this._terminal.surrogate_high+e,this._terminal.surrogate_high="");this._position<f;this._position++){if(r=e[this._position],55296<=(c=e.charCodeAt(this._position))&&c<=56319){if(u=e.charCodeAt(this._position+1),isNaN(u)){this._terminal.surrogate_high=r;continue}c=1024*(c-55296)+(u-56320)+65536,r+=e.charAt(this._position+1)}if(!(56320<=c&&c<=57343))switch(this._state){case l.NORMAL:r in s?s[r](this,this._inputHandler):this._inputHandler.addChar(r,c);break;case l.ESCAPED:if(r in n){n[r](this,this._terminal);break}switch(r){case"(":case")":case"*":case"+":case"-":case".":switch(r){case"(":this._terminal.gcharset=0;break;case")":this._terminal.gcharset=1;break;case"*":this._terminal.gcharset=2;break;case"+":this._terminal.gcharset=3;break;case"-":this._terminal.gcharset=1;break;case".":this._terminal.gcharset=2}this._state=l.CHARSET;break;case"/":this._terminal.gcharset=3,this._state=l.CHARSET,this._position--;break;case"N":case"O":break;case"n":this._terminal.setgLevel(2);break;case"o":case"|":this._terminal.setgLevel(3);break;case"}":this._terminal.setgLevel(2);break;case"~":this._terminal.setgLevel(1);break;case"7":this._inputHandler.saveCursor(),this._state=l.NORMAL;break;case"8":this._inputHandler.restoreCursor(),this._state=l.NORMAL;break;case"#":this._state=l.NORMAL,this._position++;break;case"H":this._terminal.tabSet(),this._state=l.NORMAL;break;case"=":this._terminal.log("Serial port requested application keypad."),this._terminal.applicationKeypad=!0,this._terminal.viewport.syncScrollArea(),this._state=l.NORMAL;break;case">":this._terminal.log("Switching back to normal keypad."),this._terminal.applicationKeypad=!1,this._terminal.viewport.syncScrollArea(),this._state=l.NORMAL;break;default:this._state=l.NORMAL,this._terminal.error("Unknown ESC control: %s.",r)}break;case l.CHARSET:r in o.CHARSETS?(t=o.CHARSETS[r],"/"===r&&this.skipNextChar()):t=o.DEFAULT_CHARSET,this._terminal.setgCharset(this._terminal.gcharset,t),this._terminal.gcharset=null,this._state=l.NORMAL;break;case l.OSC:if(r===i.C0.ESC||r===i.C0.BEL){switch(r===i.C0.ESC&&this._position++,this._terminal.params.push(this._terminal.currentParam),this._terminal.params[0]){case 0:case 1:case 2:this._terminal.params[1]&&(this._terminal.title=this._terminal.params[1],this._terminal.handleTitle(this._terminal.title))}this._terminal.params=[],this._terminal.currentParam=0,this._state=l.NORMAL}else this._terminal.params.length?this._terminal.currentParam+=r:r>="0"&&r<="9"?this._terminal.currentParam=10*this._terminal.currentParam+r.charCodeAt(0)-48:";"===r&&(this._terminal.params.push(this._terminal.currentParam),this._terminal.currentParam="");break;case l.CSI_PARAM:if(r in a){a[r](this);break}this.finalizeParam(),this._state=l.CSI;case l.CSI:r in h?(this._terminal.debug&&this._terminal.log("CSI "+(this._terminal.prefix?this._terminal.prefix:"")+" "+(this._terminal.params?this._terminal.params.join(";"):"")+" "+(this._terminal.postfix?this._terminal.postfix:"")+" "+r),h[r](this._inputHandler,this._terminal.params,this._terminal.prefix,this._terminal.postfix,this)):this._terminal.error("Unknown CSI code: %s.",r),this._state=l.NORMAL,this._terminal.prefix="",this._terminal.postfix="";break;case l.DCS:if(r===i.C0.ESC||r===i.C0.BEL){r===i.C0.ESC&&this._position++;var p=void 0,d=void 0;switch(this._terminal.prefix){case"":break;case"$q":switch(d=!1,p=this._terminal.currentParam){case'"q':p='0"q';break;case'"p':p='61"p';break;case"r":p=this._terminal.buffer.scrollTop+1+";"+(this._terminal.buffer.scrollBottom+1)+"r";break;case"m":p="0m";break;default:this._terminal.error("Unknown DCS Pt: %s.",p),p=""}this._terminal.send(i.C0.ESC+"P"+ +d+"$r"+p+i.C0.ESC+"\\");break;case"+p":break;case"+q":p=this._terminal.currentParam,d=!1,this._terminal.send(i.C0.ESC+"P"+ +d+"+r"+p+i.C0.ESC+"\\");break;default:this._terminal.error("Unknown DCS prefix: %s.",this._terminal.prefix)}this._terminal.currentParam=0,this._terminal.prefix="",this._state=l.NORMAL}else this._terminal.currentParam?this._terminal.currentParam+=r:this._terminal.prefix||"$"===r||"+"===r?2===this._terminal.prefix.length?this._terminal.currentParam=r:this._terminal.prefix+=r:this._terminal.currentParam=r;break;case l.IGNORE:r!==i.C0.ESC&&r!==i.C0.BEL||(r===i.C0.ESC&&this._position++,this._state=l.NORMAL)}}return this._state},e.prototype.setState=function(e){this._state=e},e.prototype.setPrefix=function(e){this._terminal.prefix=e},e.prototype.setPostfix=function(e){this._terminal.postfix=e},e.prototype.setParam=function(e){this._terminal.currentParam=e},e.prototype.getParam=function(){return this._terminal.currentParam},e.prototype.finalizeParam=function(){this._terminal.params.push(this._terminal.currentParam),this._terminal.currentParam=0},e.prototype.skipNextChar=function(){this._position++},e}();t.Parser=c},function(e,t,r){"use strict";Object.defineProperty(t,"__esModule",{value:!0});var i,o=r(31);!function(e){e[e.BOLD=1]="BOLD",e[e.UNDERLINE=2]="UNDERLINE",e[e.BLINK=4]="BLINK",e[e.INVERSE=8]="INVERSE",e[e.INVISIBLE=16]="INVISIBLE"}(i||(i={}));var s=null,n=function(){function e(e){this._terminal=e,this._refreshRowsQueue=[],this._refreshFramesSkipped=0,this._refreshAnimationFrame=null,this._spanElementObjectPool=new o.DomElementObjectPool("span"),null===s&&(s=function(e){var t=e.ownerDocument.createElement("span");t.innerHTML="hello world",e.appendChild(t);var r=t.offsetWidth,i=t.offsetHeight;t.style.fontWeight="bold";var o=t.offsetWidth,s=t.offsetHeight;return e.removeChild(t),r!==o||i!==s}(this._terminal.element)),this._spanElementObjectPool=new o.DomElementObjectPool("span")}return e.prototype.queueRefresh=function(e,t){this._refreshRowsQueue.push({start:e,end:t}),this._refreshAnimationFrame||(this._refreshAnimationFrame=window.requestAnimationFrame(this._refreshLoop.bind(this)))},e.prototype._refreshLoop=function(){if(this._terminal.writeBuffer.length>0&&this._refreshFramesSkipped++<=5)this._refreshAnimationFrame=window.requestAnimationFrame(this._refreshLoop.bind(this));else{var e,t;if(this._refreshFramesSkipped=0,this._refreshRowsQueue.length>4)e=0,t=this._terminal.rows-1;else{e=this._refreshRowsQueue[0].start,t=this._refreshRowsQueue[0].end;for(var r=1;r<this._refreshRowsQueue.length;r++)this._refreshRowsQueue[r].start<e&&(e=this._refreshRowsQueue[r].start),this._refreshRowsQueue[r].end>t&&(t=this._refreshRowsQueue[r].end)}this._refreshRowsQueue=[],this._refreshAnimationFrame=null,this._refresh(e,t)}},e.prototype._refresh=function(e,t){var r;t-e>=this._terminal.rows/2&&(r=this._terminal.element.parentNode)&&this._terminal.element.removeChild(this._terminal.rowContainer);var o=this._terminal.cols,n=e;for(t>=this._terminal.rows&&(this._terminal.log("`end` is too large. Most likely a bad CSR."),t=this._terminal.rows-1);n<=t;n++){var a=n+this._terminal.buffer.ydisp,l=this._terminal.buffer.lines.get(a),h=void 0;h=this._terminal.buffer.y===n-(this._terminal.buffer.ybase-this._terminal.buffer.ydisp)&&this._terminal.cursorState&&!this._terminal.cursorHidden?this._terminal.buffer.x:-1;for(var c=this._terminal.defAttr,u=document.createDocumentFragment(),f="",p=void 0;this._terminal.children[n].children.length;){var d=this._terminal.children[n].children[0];this._terminal.children[n].removeChild(d),this._spanElementObjectPool.release(d)}for(var g=0;g<o;g++){var m=l[g][0],A=l[g][1],b=l[g][2],y=g===h;if(b){if((m!==c||y)&&(c===this._terminal.defAttr||y||(f&&(p.innerHTML=f,f=""),u.appendChild(p),p=null),m!==this._terminal.defAttr||y)){f&&!p&&(p=this._spanElementObjectPool.acquire()),p&&(f&&(p.innerHTML=f,f=""),u.appendChild(p)),p=this._spanElementObjectPool.acquire();var C=511&m,_=m>>9&511,w=m>>18;if(y&&(p.classList.add("reverse-video"),p.classList.add("terminal-cursor")),w&i.BOLD&&(s||p.classList.add("xterm-bold"),_<8&&(_+=8)),w&i.UNDERLINE&&p.classList.add("xterm-underline"),w&i.BLINK&&p.classList.add("xterm-blink"),w&i.INVERSE){var S=C;C=_,_=S,1&w&&_<8&&(_+=8)}w&i.INVISIBLE&&!y&&p.classList.add("xterm-hidden"),w&i.INVERSE&&(257===C&&(C=15),256===_&&(_=0)),C<256&&p.classList.add("xterm-bg-color-"+C),_<256&&p.classList.add("xterm-color-"+_)}if(2===b)f+='<span class="xterm-wide-char">'+A+"</span>";else if(A.charCodeAt(0)>255)f+='<span class="xterm-normal-char">'+A+"</span>";else switch(A){case"&":f+="&amp;";break;case"<":f+="&lt;";break;case">":f+="&gt;";break;default:f+=A<=" "?"&nbsp;":A}c=y?-1:m}}f&&!p&&(p=this._spanElementObjectPool.acquire()),p&&(f&&(p.innerHTML=f,f=""),u.appendChild(p),p=null),this._terminal.children[n].appendChild(u)}r&&this._terminal.element.appendChild(this._terminal.rowContainer),this._terminal.emit("refresh",{element:this._terminal.element,start:e,end:t})},e.prototype.refreshSelection=function(e,t){for(;this._terminal.selectionContainer.children.length;)this._terminal.selectionContainer.removeChild(this._terminal.selectionContainer.children[0]);if(e&&t){var r=e[1]-this._terminal.buffer.ydisp,i=t[1]-this._terminal.buffer.ydisp,o=Math.max(r,0),s=Math.min(i,this._terminal.rows-1);if(!(o>=this._terminal.rows||s<0)){var n=document.createDocumentFragment(),a=r===o?e[0]:0,l=o===s?t[0]:this._terminal.cols;n.appendChild(this._createSelectionElement(o,a,l));var h=s-o-1;if(n.appendChild(this._createSelectionElement(o+1,0,this._terminal.cols,h)),o!==s){var c=i===s?t[0]:this._terminal.cols;n.appendChild(this._createSelectionElement(s,0,c))}this._terminal.selectionContainer.appendChild(n)}}},e.prototype._createSelectionElement=function(e,t,r,i){void 0===i&&(i=1);var o=document.createElement("div");return o.style.height=i*this._terminal.charMeasure.height+"px",o.style.top=e*this._terminal.charMeasure.height+"px",o.style.left=t*this._terminal.charMeasure.width+"px",o.style.width=this._terminal.charMeasure.width*(r-t)+"px",o},e}();t.Renderer=n},function(e,t,r){"use strict";var i=this&&this.__extends||function(){var e=Object.setPrototypeOf||{__proto__:[]}instanceof Array&&function(e,t){e.__proto__=t}||function(e,t){for(var r in t)t.hasOwnProperty(r)&&(e[r]=t[r])};return function(t,r){function i(){this.constructor=t}e(t,r),t.prototype=null===r?Object.create(r):(i.prototype=r.prototype,new i)}}();Object.defineProperty(t,"__esModule",{value:!0});var o,s=r(13),n=r(11),a=r(1),l=r(26),h=r(12),c=String.fromCharCode(160),u=new RegExp(c,"g");!function(e){e[e.NORMAL=0]="NORMAL",e[e.WORD=1]="WORD",e[e.LINE=2]="LINE"}(o||(o={}));var f=function(e){function t(t,r,i,s){var n=e.call(this)||this;return n._terminal=t,n._buffer=r,n._rowContainer=i,n._charMeasure=s,n._enabled=!0,n._initListeners(),n.enable(),n._model=new l.SelectionModel(t),n._activeSelectionMode=o.NORMAL,n}return i(t,e),t.prototype._initListeners=function(){var e=this;this._mouseMoveListener=function(t){return e._onMouseMove(t)},this._mouseUpListener=function(t){return e._onMouseUp(t)},this._rowContainer.addEventListener("mousedown",function(t){return e._onMouseDown(t)}),this._buffer.on("trim",function(t){return e._onTrim(t)})},t.prototype.disable=function(){this.clearSelection(),this._enabled=!1},t.prototype.enable=function(){this._enabled=!0},t.prototype.setBuffer=function(e){this._buffer=e,this.clearSelection()},Object.defineProperty(t.prototype,"selectionStart",{get:function(){return this._model.finalSelectionStart},enumerable:!0,configurable:!0}),Object.defineProperty(t.prototype,"selectionEnd",{get:function(){return this._model.finalSelectionEnd},enumerable:!0,configurable:!0}),Object.defineProperty(t.prototype,"hasSelection",{get:function(){var e=this._model.finalSelectionStart,t=this._model.finalSelectionEnd;return!(!e||!t)&&(e[0]!==t[0]||e[1]!==t[1])},enumerable:!0,configurable:!0}),Object.defineProperty(t.prototype,"selectionText",{get:function(){var e=this._model.finalSelectionStart,t=this._model.finalSelectionEnd;if(!e||!t)return"";var r=e[1]===t[1]?t[0]:null,i=[];i.push(h.translateBufferLineToString(this._buffer.get(e[1]),!0,e[0],r));for(var o=e[1]+1;o<=t[1]-1;o++){var s=this._buffer.get(o),a=h.translateBufferLineToString(s,!0);s.isWrapped?i[i.length-1]+=a:i.push(a)}if(e[1]!==t[1]){s=this._buffer.get(t[1]),a=h.translateBufferLineToString(s,!0,0,t[0]);s.isWrapped?i[i.length-1]+=a:i.push(a)}return i.map(function(e){return e.replace(u," ")}).join(n.isMSWindows?"\r\n":"\n")},enumerable:!0,configurable:!0}),t.prototype.clearSelection=function(){this._model.clearSelection(),this._removeMouseDownListeners(),this.refresh()},t.prototype.refresh=function(e){var t=this;(this._refreshAnimationFrame||(this._refreshAnimationFrame=window.requestAnimationFrame(function(){return t._refresh()})),n.isLinux&&e)&&(this.selectionText.length&&this.emit("newselection",this.selectionText))},t.prototype._refresh=function(){this._refreshAnimationFrame=null,this.emit("refresh",{start:this._model.finalSelectionStart,end:this._model.finalSelectionEnd})},t.prototype.selectAll=function(){this._model.isSelectAllActive=!0,this.refresh()},t.prototype._onTrim=function(e){this._model.onTrim(e)&&this.refresh()},t.prototype._getMouseBufferCoords=function(e){var t=s.getCoords(e,this._rowContainer,this._charMeasure,this._terminal.cols,this._terminal.rows,!0);return t?(t[0]--,t[1]--,t[1]+=this._terminal.buffer.ydisp,t):null},t.prototype._getMouseEventScrollAmount=function(e){var t=s.getCoordsRelativeToElement(e,this._rowContainer)[1],r=this._terminal.rows*this._charMeasure.height;return t>=0&&t<=r?0:(t>r&&(t-=r),t=Math.min(Math.max(t,-50),50),(t/=50)/Math.abs(t)+Math.round(14*t))},t.prototype._onMouseDown=function(e){if(2===e.button&&this.hasSelection)e.stopPropagation();else if(0===e.button){if(!this._enabled){if(!(n.isMac&&e.altKey))return;e.stopPropagation()}e.preventDefault(),this._dragScrollAmount=0,this._enabled&&e.shiftKey?this._onIncrementalClick(e):1===e.detail?this._onSingleClick(e):2===e.detail?this._onDoubleClick(e):3===e.detail&&this._onTripleClick(e),this._addMouseDownListeners(),this.refresh(!0)}},t.prototype._addMouseDownListeners=function(){var e=this;this._rowContainer.ownerDocument.addEventListener("mousemove",this._mouseMoveListener),this._rowContainer.ownerDocument.addEventListener("mouseup",this._mouseUpListener),this._dragScrollIntervalTimer=setInterval(function(){return e._dragScroll()},50)},t.prototype._removeMouseDownListeners=function(){this._rowContainer.ownerDocument.removeEventListener("mousemove",this._mouseMoveListener),this._rowContainer.ownerDocument.removeEventListener("mouseup",this._mouseUpListener),clearInterval(this._dragScrollIntervalTimer),this._dragScrollIntervalTimer=null},t.prototype._onIncrementalClick=function(e){this._model.selectionStart&&(this._model.selectionEnd=this._getMouseBufferCoords(e))},t.prototype._onSingleClick=function(e){if(this._model.selectionStartLength=0,this._model.isSelectAllActive=!1,this._activeSelectionMode=o.NORMAL,this._model.selectionStart=this._getMouseBufferCoords(e),this._model.selectionStart){this._model.selectionEnd=null;var t=this._buffer.get(this._model.selectionStart[1]);if(t)0===t[this._model.selectionStart[0]][2]&&this._model.selectionStart[0]++}},t.prototype._onDoubleClick=function(e){var t=this._getMouseBufferCoords(e);t&&(this._activeSelectionMode=o.WORD,this._selectWordAt(t))},t.prototype._onTripleClick=function(e){var t=this._getMouseBufferCoords(e);t&&(this._activeSelectionMode=o.LINE,this._selectLineAt(t[1]))},t.prototype._onMouseMove=function(e){var t=this._model.selectionEnd?[this._model.selectionEnd[0],this._model.selectionEnd[1]]:null;if(this._model.selectionEnd=this._getMouseBufferCoords(e),this._model.selectionEnd){if(this._activeSelectionMode===o.LINE?this._model.selectionEnd[1]<this._model.selectionStart[1]?this._model.selectionEnd[0]=0:this._model.selectionEnd[0]=this._terminal.cols:this._activeSelectionMode===o.WORD&&this._selectToWordAt(this._model.selectionEnd),this._dragScrollAmount=this._getMouseEventScrollAmount(e),this._dragScrollAmount>0?this._model.selectionEnd[0]=this._terminal.cols-1:this._dragScrollAmount<0&&(this._model.selectionEnd[0]=0),this._model.selectionEnd[1]<this._buffer.length){var r=this._buffer.get(this._model.selectionEnd[1])[this._model.selectionEnd[0]];r&&0===r[2]&&this._model.selectionEnd[0]++}t&&t[0]===this._model.selectionEnd[0]&&t[1]===this._model.selectionEnd[1]||this.refresh(!0)}else this.refresh(!0)},t.prototype._dragScroll=function(){this._dragScrollAmount&&(this._terminal.scrollDisp(this._dragScrollAmount,!1),this._dragScrollAmount>0?this._model.selectionEnd=[this._terminal.cols-1,this._terminal.buffer.ydisp+this._terminal.rows]:this._model.selectionEnd=[0,this._terminal.buffer.ydisp],this.refresh())},t.prototype._onMouseUp=function(e){this._removeMouseDownListeners()},t.prototype._convertViewportColToCharacterIndex=function(e,t){for(var r=t[0],i=0;t[0]>=i;i++){0===e[i][2]&&r--}return r},t.prototype.setSelection=function(e,t,r){this._model.clearSelection(),this._removeMouseDownListeners(),this._model.selectionStart=[e,t],this._model.selectionStartLength=r,this.refresh()},t.prototype._getWordAt=function(e){var t=this._buffer.get(e[1]);if(!t)return null;var r=h.translateBufferLineToString(t,!1),i=this._convertViewportColToCharacterIndex(t,e),o=i,s=e[0]-o,n=0,a=0;if(" "===r.charAt(o)){for(;o>0&&" "===r.charAt(o-1);)o--;for(;i<r.length&&" "===r.charAt(i+1);)i++}else{var l=e[0],c=e[0];for(0===t[l][2]&&(n++,l--),2===t[c][2]&&(a++,c++);o>0&&!this._isCharWordSeparator(r.charAt(o-1));)0===t[l-1][2]&&(n++,l--),o--,l--;for(;i+1<r.length&&!this._isCharWordSeparator(r.charAt(i+1));)2===t[c+1][2]&&(a++,c++),i++,c++}return{start:o+s-n,length:Math.min(i-o+n+a+1,this._terminal.cols)}},t.prototype._selectWordAt=function(e){var t=this._getWordAt(e);t&&(this._model.selectionStart=[t.start,e[1]],this._model.selectionStartLength=t.length)},t.prototype._selectToWordAt=function(e){var t=this._getWordAt(e);t&&(this._model.selectionEnd=[this._model.areSelectionValuesReversed()?t.start:t.start+t.length,e[1]])},t.prototype._isCharWordSeparator=function(e){return" ()[]{}'\"".indexOf(e)>=0},t.prototype._selectLineAt=function(e){this._model.selectionStart=[0,e],this._model.selectionStartLength=this._terminal.cols},t}(a.EventEmitter);t.SelectionManager=f},function(e,t,r){"use strict";Object.defineProperty(t,"__esModule",{value:!0});var i=function(){function e(e){this._terminal=e,this.clearSelection()}return e.prototype.clearSelection=function(){this.selectionStart=null,this.selectionEnd=null,this.isSelectAllActive=!1,this.selectionStartLength=0},Object.defineProperty(e.prototype,"finalSelectionStart",{get:function(){return this.isSelectAllActive?[0,0]:this.selectionEnd&&this.selectionStart&&this.areSelectionValuesReversed()?this.selectionEnd:this.selectionStart},enumerable:!0,configurable:!0}),Object.defineProperty(e.prototype,"finalSelectionEnd",{get:function(){return this.isSelectAllActive?[this._terminal.cols,this._terminal.buffer.ybase+this._terminal.rows-1]:this.selectionStart?!this.selectionEnd||this.areSelectionValuesReversed()?[this.selectionStart[0]+this.selectionStartLength,this.selectionStart[1]]:this.selectionStartLength&&this.selectionEnd[1]===this.selectionStart[1]?[Math.max(this.selectionStart[0]+this.selectionStartLength,this.selectionEnd[0]),this.selectionEnd[1]]:this.selectionEnd:null},enumerable:!0,configurable:!0}),e.prototype.areSelectionValuesReversed=function(){var e=this.selectionStart,t=this.selectionEnd;return e[1]>t[1]||e[1]===t[1]&&e[0]>t[0]},e.prototype.onTrim=function(e){return this.selectionStart&&(this.selectionStart[1]-=e),this.selectionEnd&&(this.selectionEnd[1]-=e),this.selectionEnd&&this.selectionEnd[1]<0?(this.clearSelection(),!0):(this.selectionStart&&this.selectionStart[1]<0&&(this.selectionStart[1]=0),!1)},e}();t.SelectionModel=i},function(e,t,r){"use strict";Object.defineProperty(t,"__esModule",{value:!0});var i=function(){function e(e,t,r,i){var o=this;this.terminal=e,this.viewportElement=t,this.scrollArea=r,this.charMeasure=i,this.currentRowHeight=0,this.lastRecordedBufferLength=0,this.lastRecordedViewportHeight=0,this.terminal.on("scroll",this.syncScrollArea.bind(this)),this.terminal.on("resize",this.syncScrollArea.bind(this)),this.viewportElement.addEventListener("scroll",this.onScroll.bind(this)),setTimeout(function(){return o.syncScrollArea()},0)}return e.prototype.refresh=function(){if(this.charMeasure.height>0){var e=this.charMeasure.height!==this.currentRowHeight;e&&(this.currentRowHeight=this.charMeasure.height,this.viewportElement.style.lineHeight=this.charMeasure.height+"px",this.terminal.rowContainer.style.lineHeight=this.charMeasure.height+"px");var t=this.lastRecordedViewportHeight!==this.terminal.rows;(e||t)&&(this.lastRecordedViewportHeight=this.terminal.rows,this.viewportElement.style.height=this.charMeasure.height*this.terminal.rows+"px",this.terminal.selectionContainer.style.height=this.viewportElement.style.height),this.scrollArea.style.height=this.charMeasure.height*this.lastRecordedBufferLength+"px"}},e.prototype.syncScrollArea=function(){this.lastRecordedBufferLength!==this.terminal.buffer.lines.length?(this.lastRecordedBufferLength=this.terminal.buffer.lines.length,this.refresh()):this.lastRecordedViewportHeight!==this.terminal.rows?this.refresh():this.charMeasure.height!==this.currentRowHeight&&this.refresh();var e=this.terminal.buffer.ydisp*this.currentRowHeight;this.viewportElement.scrollTop!==e&&(this.viewportElement.scrollTop=e)},e.prototype.onScroll=function(e){var t=Math.round(this.viewportElement.scrollTop/this.currentRowHeight)-this.terminal.buffer.ydisp;this.terminal.scrollDisp(t,!0)},e.prototype.onWheel=function(e){if(0!==e.deltaY){var t=1;e.deltaMode===WheelEvent.DOM_DELTA_LINE?t=this.currentRowHeight:e.deltaMode===WheelEvent.DOM_DELTA_PAGE&&(t=this.currentRowHeight*this.terminal.rows),this.viewportElement.scrollTop+=e.deltaY*t,e.preventDefault()}},e.prototype.onTouchStart=function(e){this.lastTouchY=e.touches[0].pageY},e.prototype.onTouchMove=function(e){var t=this.lastTouchY-e.touches[0].pageY;this.lastTouchY=e.touches[0].pageY,0!==t&&(this.viewportElement.scrollTop+=t,e.preventDefault())},e}();t.Viewport=i},function(e,t,r){"use strict";function i(e,t){return t?e.replace(/\r?\n/g,"\r"):e}function o(e,t){t.style.position="fixed",t.style.width="20px",t.style.height="20px",t.style.left=e.clientX-10+"px",t.style.top=e.clientY-10+"px",t.style.zIndex="1000",t.focus(),setTimeout(function(){t.style.position=null,t.style.width=null,t.style.height=null,t.style.left=null,t.style.top=null,t.style.zIndex=null},4)}Object.defineProperty(t,"__esModule",{value:!0}),t.prepareTextForTerminal=i,t.copyHandler=function(e,t,r){t.browser.isMSIE?window.clipboardData.setData("Text",r.selectionText):e.clipboardData.setData("text/plain",r.selectionText),e.preventDefault()},t.pasteHandler=function(e,t){e.stopPropagation();var r=function(r){return r=i(r,t.browser.isMSWindows),t.handler(r),t.textarea.value="",t.emit("paste",r),t.cancel(e)};t.browser.isMSIE?window.clipboardData&&r(window.clipboardData.getData("Text")):e.clipboardData&&r(e.clipboardData.getData("text/plain"))},t.moveTextAreaUnderMouseCursor=o,t.rightClickHandler=function(e,t,r){o(e,t),t.value=r.selectionText,t.select()}},function(e,t,r){"use strict";var i=this&&this.__extends||function(){var e=Object.setPrototypeOf||{__proto__:[]}instanceof Array&&function(e,t){e.__proto__=t}||function(e,t){for(var r in t)t.hasOwnProperty(r)&&(e[r]=t[r])};return function(t,r){function i(){this.constructor=t}e(t,r),t.prototype=null===r?Object.create(r):(i.prototype=r.prototype,new i)}}();Object.defineProperty(t,"__esModule",{value:!0});var o=function(e){function t(t,r){var i=e.call(this)||this;return i._document=t,i._parentElement=r,i}return i(t,e),Object.defineProperty(t.prototype,"width",{get:function(){return this._width},enumerable:!0,configurable:!0}),Object.defineProperty(t.prototype,"height",{get:function(){return this._height},enumerable:!0,configurable:!0}),t.prototype.measure=function(){var e=this;this._measureElement?this._doMeasure():(this._measureElement=this._document.createElement("span"),this._measureElement.style.position="absolute",this._measureElement.style.top="0",this._measureElement.style.left="-9999em",this._measureElement.textContent="W",this._measureElement.setAttribute("aria-hidden","true"),this._parentElement.appendChild(this._measureElement),setTimeout(function(){return e._doMeasure()},0))},t.prototype._doMeasure=function(){var e=this._measureElement.getBoundingClientRect();0!==e.width&&0!==e.height&&(this._width===e.width&&this._height===e.height||(this._width=e.width,this._height=e.height,this.emit("charsizechanged")))},t}(r(1).EventEmitter);t.CharMeasure=o},function(e,t,r){"use strict";var i=this&&this.__extends||function(){var e=Object.setPrototypeOf||{__proto__:[]}instanceof Array&&function(e,t){e.__proto__=t}||function(e,t){for(var r in t)t.hasOwnProperty(r)&&(e[r]=t[r])};return function(t,r){function i(){this.constructor=t}e(t,r),t.prototype=null===r?Object.create(r):(i.prototype=r.prototype,new i)}}();Object.defineProperty(t,"__esModule",{value:!0});var o=function(e){function t(t){var r=e.call(this)||this;return r._array=new Array(t),r._startIndex=0,r._length=0,r}return i(t,e),Object.defineProperty(t.prototype,"maxLength",{get:function(){return this._array.length},set:function(e){for(var t=new Array(e),r=0;r<Math.min(e,this.length);r++)t[r]=this._array[this._getCyclicIndex(r)];this._array=t,this._startIndex=0},enumerable:!0,configurable:!0}),Object.defineProperty(t.prototype,"length",{get:function(){return this._length},set:function(e){if(e>this._length)for(var t=this._length;t<e;t++)this._array[t]=void 0;this._length=e},enumerable:!0,configurable:!0}),Object.defineProperty(t.prototype,"forEach",{get:function(){var e=this;return function(t){for(var r=e.length,i=0;i<r;i++)t(e.get(i),i)}},enumerable:!0,configurable:!0}),t.prototype.get=function(e){return this._array[this._getCyclicIndex(e)]},t.prototype.set=function(e,t){this._array[this._getCyclicIndex(e)]=t},t.prototype.push=function(e){this._array[this._getCyclicIndex(this._length)]=e,this._length===this.maxLength?(this._startIndex++,this._startIndex===this.maxLength&&(this._startIndex=0),this.emit("trim",1)):this._length++},t.prototype.pop=function(){return this._array[this._getCyclicIndex(this._length---1)]},t.prototype.splice=function(e,t){for(var r=[],i=2;i<arguments.length;i++)r[i-2]=arguments[i];if(t){for(var o=e;o<this._length-t;o++)this._array[this._getCyclicIndex(o)]=this._array[this._getCyclicIndex(o+t)];this._length-=t}if(r&&r.length){for(o=this._length-1;o>=e;o--)this._array[this._getCyclicIndex(o+r.length)]=this._array[this._getCyclicIndex(o)];for(o=0;o<r.length;o++)this._array[this._getCyclicIndex(e+o)]=r[o];if(this._length+r.length>this.maxLength){var s=this._length+r.length-this.maxLength;this._startIndex+=s,this._length=this.maxLength,this.emit("trim",s)}else this._length+=r.length}},t.prototype.trimStart=function(e){e>this._length&&(e=this._length),this._startIndex+=e,this._length-=e,this.emit("trim",e)},t.prototype.shiftElements=function(e,t,r){if(!(t<=0)){if(e<0||e>=this._length)throw new Error("start argument out of range");if(e+r<0)throw new Error("Cannot shift elements in list beyond index 0");if(r>0){for(var i=t-1;i>=0;i--)this.set(e+i+r,this.get(e+i));var o=e+t+r-this._length;if(o>0)for(this._length+=o;this._length>this.maxLength;)this._length--,this._startIndex++,this.emit("trim",1)}else for(i=0;i<t;i++)this.set(e+i+r,this.get(e+i))}},t.prototype._getCyclicIndex=function(e){return(this._startIndex+e)%this.maxLength},t}(r(1).EventEmitter);t.CircularList=o},function(e,t,r){"use strict";Object.defineProperty(t,"__esModule",{value:!0});var i=function(){function e(e){this.type=e,this._type=e,this._pool=[],this._inUse={}}return e.prototype.acquire=function(){var t;return t=0===this._pool.length?this._createNew():this._pool.pop(),this._inUse[t.getAttribute(e.OBJECT_ID_ATTRIBUTE)]=t,t},e.prototype.release=function(t){if(!this._inUse[t.getAttribute(e.OBJECT_ID_ATTRIBUTE)])throw new Error("Could not release an element not yet acquired");delete this._inUse[t.getAttribute(e.OBJECT_ID_ATTRIBUTE)],this._cleanElement(t),this._pool.push(t)},e.prototype._createNew=function(){var t=document.createElement(this._type),r=e._objectCount++;return t.setAttribute(e.OBJECT_ID_ATTRIBUTE,r.toString(10)),t},e.prototype._cleanElement=function(e){e.className="",e.innerHTML=""},e}();i.OBJECT_ID_ATTRIBUTE="data-obj-id",i._objectCount=0,t.DomElementObjectPool=i},function(e,t,r){"use strict";Object.defineProperty(t,"__esModule",{value:!0}),t.contains=function(e,t){return e.indexOf(t)>=0}},function(e,t,r){"use strict";Object.defineProperty(t,"__esModule",{value:!0});var i=r(14),o=r(17),s=r(16),n=r(15),a=document.getElementById("terminal");if(null!==a){var l;l="hterm"==gotty_term?new i.Hterm(a):new o.Xterm(a);var h=("https:"==window.location.protocol?"wss://":"ws://")+window.location.host+window.location.pathname+"ws",c=window.location.search,u=new n.ConnectionFactory(h,s.protocols),f=new s.WebTTY(l,u,c,gotty_auth_token).open();window.addEventListener("unload",function(){f(),l.close()})}},function(e,t,r){var i={"./attach/attach":6,"./attach/attach.js":6,"./attach/package.json":35,"./fit/fit":7,"./fit/fit.js":7,"./fit/package.json":36,"./fullscreen/fullscreen":8,"./fullscreen/fullscreen.css":37,"./fullscreen/fullscreen.js":8,"./fullscreen/package.json":38,"./search/SearchHelper":3,"./search/SearchHelper.js":3,"./search/SearchHelper.js.map":39,"./search/search":9,"./search/search.js":9,"./search/search.js.map":40,"./terminado/package.json":41,"./terminado/terminado":10,"./terminado/terminado.js":10};function o(e){return r(s(e))}function s(e){var t=i[e];if(!(t+1))throw new Error("Cannot find module '"+e+"'.");return t}o.keys=function(){return Object.keys(i)},o.resolve=s,e.exports=o,o.id=34},function(e,t){e.exports={name:"xterm.attach",main:"attach.js",private:!0}},function(e,t){e.exports={name:"xterm.fit",main:"fit.js",private:!0}},function(e,t){throw new Error("Module parse failed: /home/mr/Documents/workspace/golang/src/github.com/wrfly/container-web-tty/js/node_modules/xterm/lib/addons/fullscreen/fullscreen.css Unexpected token (1:0)\nYou may need an appropriate loader to handle this file type.\n| .xterm.fullscreen {\n|     position: fixed;\n|     top: 0;")},function(e,t){e.exports={name:"xterm.fullscreen",main:"fullscreen.js",private:!0}},function(e,t){throw new Error('Module parse failed: /home/mr/Documents/workspace/golang/src/github.com/wrfly/container-web-tty/js/node_modules/xterm/lib/addons/search/SearchHelper.js.map Unexpected token (1:10)\nYou may need an appropriate loader to handle this file type.\n| {"version":3,"sources":["../../../src/addons/search/SearchHelper.ts"],"names":[],"mappings":";;AAgBA;IACE,sBAAoB,SAAc,EAAU,4BAAiC;QAAzD,cAAS,GAAT,SAAS,CAAK;QAAU,iCAA4B,GAA5B,4BAA4B,CAAK;IAK7E,CAAC;IAQM,+BAAQ,GAAf,UAAgB,IAAY;QAC1B,EAAE,CAAC,CAAC,CAAC,IAAI,IAAI,IAAI,CAAC,MAAM,KAAK,CAAC,CAAC,CAAC,CAAC;YAC/B,MAAM,CAAC,KAAK,CAAC;QACf,CAAC;QAED,IAAI,MAAqB,CAAC;QAE1B,IAAI,QAAQ,GAAG,IAAI,CAAC,SAAS,CAAC,MAAM,CAAC,KAAK,CAAC;QAC3C,EAAE,CAAC,CAAC,IAAI,CAAC,SAAS,CAAC,gBAAgB,CAAC,YAAY,CAAC,CAAC,CAAC;YAEjD,QAAQ,GAAG,IAAI,CAAC,SAAS,CAAC,gBAAgB,CAAC,YAAY,CAAC,CAAC,CAAC,CAAC;QAC7D,CAAC;QAGD,GAAG,CAAC,CAAC,IAAI,CAAC,GAAG,QAAQ,GAAG,CAAC,EAAE,CAAC,GAAG,IAAI,CAAC,SAAS,CAAC,MAAM,CAAC,KAAK,GAAG,IAAI,CAAC,SAAS,CAAC,IAAI,EAAE,CAAC,EAAE,EAAE,CAAC;YACtF,MAAM,GAAG,IAAI,CAAC,WAAW,CAAC,IAAI,EAAE,CAAC,CAAC,CAAC;YACnC,EAAE,CAAC,CAAC,MAAM,CAAC,CAAC,CAAC;gBACX,KAAK,CAAC;YACR,CAAC;QACH,CAAC;QAGD,EAAE,CAAC,CAAC,CAAC,MAAM,CAAC,CAAC,CAAC;YACZ,GAAG,CAAC,CAAC,IAAI,CAAC,GAAG,CAAC,EAAE,CAAC,GAAG,QAAQ,EAAE,CAAC,EAAE,EAAE,CAAC;gBAClC,MAAM,GAAG,IAAI,CAAC,WAAW,CAAC,IAAI,EAAE,CAAC,CAAC,CAAC;gBACnC,EAAE,CAAC,CAAC,MAAM,CAAC,CAAC,CAAC;oBACX,KAAK,CAAC;gBACR,CAAC;YACH,CAAC;QACH,CAAC;QAGD,MAAM,CAAC,IAAI,CAAC,aAAa,CAAC,MAAM,CAAC,CAAC;IACpC,CAAC;IAQM,mCAAY,GAAnB,UAAoB,IAAY;QAC9B,EAAE,CAAC,CAAC,CAAC,IAAI,IAAI,IAAI,CAAC,MAAM,KAAK,CAAC,CAAC,CAAC,CAAC;YAC/B,MAAM,CAAC,KAAK,CAAC;QACf,CAAC;QAED,IAAI,MAAqB,CAAC;QAE1B,IAAI,QAAQ,GAAG,IAAI,CAAC,SAAS,CAAC,MAAM,CAAC,KAAK,CAAC;QAC3C,EAAE,CAAC,CAAC,IAAI,CAAC,SAAS,CAAC,gBAAgB,CAAC,cAAc,CAAC,CAAC,CAAC;YAEnD,QAAQ,GAAG,IAAI,CAAC,SAAS,CAAC,gBAAgB,CAAC,cAAc,CAAC,CAAC,CAAC,CAAC;QAC/D,CAAC;QAGD,GAAG,CAAC,CAAC,IAAI,CAAC,GAAG,QAAQ,GAAG,CAAC,EAAE,CAAC,IAAI,CAAC,EAAE,CAAC,EAAE,EAAE,CAAC;YACvC,MAAM,GAAG,IAAI,CAAC,WAAW,CAAC,IAAI,EAAE,CAAC,CAAC,CAAC;YACnC,EAAE,CAAC,CAAC,MAAM,CAAC,CAAC,CAAC;gBACX,KAAK,CAAC;YACR,CAAC;QACH,CAAC;QAGD,EAAE,CAAC,CAAC,CAAC,MAAM,CAAC,CAAC,CAAC;YACZ,GAAG,CAAC,CAAC,IAAI,CAAC,GAAG,IAAI,CAAC,SAAS,CAAC,MAAM,CAAC,KAAK,GAAG,IAAI,CAAC,SAAS,CAAC,IAAI,GAAG,CAAC,EAAE,CAAC,GAAG,QAAQ,EAAE,CAAC,EAAE,EAAE,CAAC;gBACtF,MAAM,GAAG,IAAI,CAAC,WAAW,CAAC,IAAI,EAAE,CAAC,CAAC,CAAC;gBACnC,EAAE,CAAC,CAAC,MAAM,CAAC,CAAC,CAAC;oBACX,KAAK,CAAC;gBACR,CAAC;YACH,CAAC;QACH,CAAC;QAGD,MAAM,CAAC,IAAI,CAAC,aAAa,CAAC,MAAM,CAAC,CAAC;IACpC,CAAC;IAQO,kCAAW,GAAnB,UAAoB,IAAY,EAAE,CAAS;QACzC,IAAM,UAAU,GAAG,IAAI,CAAC,SAAS,CAAC,MAAM,CAAC,KAAK,CAAC,GAAG,CAAC,CAAC,CAAC,CAAC;QACtD,IAAM,eAAe,GAAG,IAAI,CAAC,4BAA4B,CAAC,UAAU,EAAE,IAAI,CAAC,CAAC,WAAW,EAAE,CAAC;QAC1F,IAAM,SAAS,GAAG,IAAI,CAAC,WAAW,EAAE,CAAC;QACrC,IAAM,WAAW,GAAG,eAAe,CAAC,OAAO,CAAC,SAAS,CAAC,CAAC;QACvD,EAAE,CAAC,CAAC,WAAW,IAAI,CAAC,CAAC,CAAC,CAAC;YACrB,MAAM,CAAC;gBACL,IAAI,MAAA;gBACJ,GAAG,EAAE,WAAW;gBAChB,GAAG,EAAE,CAAC;aACP,CAAC;QACJ,CAAC;IACH,CAAC;IAOO,oCAAa,GAArB,UAAsB,MAAqB;QACzC,EAAE,CAAC,CAAC,CAAC,MAAM,CAAC,CAAC,CAAC;YACZ,MAAM,CAAC,KAAK,CAAC;QACf,CAAC;QACD,IAAI,CAAC,SAAS,CAAC,gBAAgB,CAAC,YAAY,CAAC,MAAM,CAAC,GAAG,EAAE,MAAM,CAAC,GAAG,EAAE,MAAM,CAAC,IAAI,CAAC,MAAM,CAAC,CAAC;QACzF,IAAI,CAAC,SAAS,CAAC,UAAU,CAAC,MAAM,CAAC,GAAG,GAAG,IAAI,CAAC,SAAS,CAAC,MAAM,CAAC,KAAK,EAAE,KAAK,CAAC,CAAC;QAC3E,MAAM,CAAC,IAAI,CAAC;IACd,CAAC;IACH,mBAAC;AAAD,CA3HA,AA2HC,IAAA;AA3HY,oCAAY","file":"SearchHelper.js","sourceRoot":"."}')},function(e,t){throw new Error('Module parse failed: /home/mr/Documents/workspace/golang/src/github.com/wrfly/container-web-tty/js/node_modules/xterm/lib/addons/search/search.js.map Unexpected token (1:10)\nYou may need an appropriate loader to handle this file type.\n| {"version":3,"sources":["../../../src/addons/search/search.ts"],"names":[],"mappings":";;AAIA,+CAA8C;AAQ9C,CAAC,UAAU,KAAK;IACd,EAAE,CAAC,CAAC,UAAU,IAAI,MAAM,CAAC,CAAC,CAAC;QAIzB,KAAK,CAAC,MAAM,CAAC,QAAQ,CAAC,CAAC;IACzB,CAAC;IAAC,IAAI,CAAC,EAAE,CAAC,CAAC,OAAO,OAAO,KAAK,QAAQ,IAAI,OAAO,MAAM,KAAK,QAAQ,CAAC,CAAC,CAAC;QAIrE,MAAM,CAAC,OAAO,GAAG,KAAK,CAAC,OAAO,CAAC,aAAa,CAAC,CAAC,CAAC;IACjD,CAAC;IAAC,IAAI,CAAC,EAAE,CAAC,CAAC,OAAO,MAAM,IAAI,UAAU,CAAC,CAAC,CAAC;QAIvC,MAAM,CAAC,CAAC,aAAa,CAAC,EAAE,KAAK,CAAC,CAAC;IACjC,CAAC;AACH,CAAC,CAAC,CAAC,UAAC,QAAa;IAOf,QAAQ,CAAC,SAAS,CAAC,QAAQ,GAAG,UAAS,IAAY;QACjD,EAAE,CAAC,CAAC,CAAC,IAAI,CAAC,aAAa,CAAC,CAAC,CAAC;YACxB,IAAI,CAAC,YAAY,GAAG,IAAI,2BAAY,CAAC,IAAI,EAAE,QAAQ,CAAC,2BAA2B,CAAC,CAAC;QACnF,CAAC;QACD,MAAM,CAAgB,IAAI,CAAC,YAAa,CAAC,QAAQ,CAAC,IAAI,CAAC,CAAC;IAC1D,CAAC,CAAC;IAQF,QAAQ,CAAC,SAAS,CAAC,YAAY,GAAG,UAAS,IAAY;QACrD,EAAE,CAAC,CAAC,CAAC,IAAI,CAAC,aAAa,CAAC,CAAC,CAAC;YACxB,IAAI,CAAC,YAAY,GAAG,IAAI,2BAAY,CAAC,IAAI,EAAE,QAAQ,CAAC,2BAA2B,CAAC,CAAC;QACnF,CAAC;QACD,MAAM,CAAgB,IAAI,CAAC,YAAa,CAAC,YAAY,CAAC,IAAI,CAAC,CAAC;IAC9D,CAAC,CAAC;AACJ,CAAC,CAAC,CAAC","file":"search.js","sourceRoot":"."}')},function(e,t){e.exports={name:"xterm.terminado",main:"terminado.js",private:!0}}]);
\ No newline at end of file
+!function(t){e.exports=t(r(0))}(function(e){"use strict";var t={terminadoAttach:function(e,t,r,i){r=void 0===r||r,e.socket=t,e._flushBuffer=function(){e.write(e._attachSocketBuffer),e._attachSocketBuffer=null,clearTimeout(e._attachSocketBufferTimer),e._attachSocketBufferTimer=null},e._pushToBuffer=function(t){e._attachSocketBuffer?e._attachSocketBuffer+=t:(e._attachSocketBuffer=t,setTimeout(e._flushBuffer,10))},e._getMessage=function(t){var r=JSON.parse(t.data);"stdout"==r[0]&&(i?e._pushToBuffer(r[1]):e.write(r[1]))},e._sendData=function(e){t.send(JSON.stringify(["stdin",e]))},e._setSize=function(e){t.send(JSON.stringify(["set_size",e.rows,e.cols]))},t.addEventListener("message",e._getMessage),r&&e.on("data",e._sendData),e.on("resize",e._setSize),t.addEventListener("close",e.terminadoDetach.bind(e,t)),t.addEventListener("error",e.terminadoDetach.bind(e,t))},terminadoDetach:function(e,t){e.off("data",e._sendData),(t=void 0===t?e.socket:t)&&t.removeEventListener("message",e._getMessage),delete e.socket}};return e.prototype.terminadoAttach=function(e,r,i){return t.terminadoAttach(this,e,r,i)},e.prototype.terminadoDetach=function(e){return t.terminadoDetach(this,e)},t})},function(e,t,r){"use strict";Object.defineProperty(t,"__esModule",{value:!0});var i=r(32),o="undefined"==typeof navigator,s=o?"node":navigator.userAgent,n=o?"node":navigator.platform;t.isFirefox=!!~s.indexOf("Firefox"),t.isMSIE=!!~s.indexOf("MSIE")||!!~s.indexOf("Trident"),t.isMac=i.contains(["Macintosh","MacIntel","MacPPC","Mac68K"],n),t.isIpad="iPad"===n,t.isIphone="iPhone"===n,t.isMSWindows=i.contains(["Windows","Win16","Win32","WinCE"],n),t.isLinux=n.indexOf("Linux")>=0},function(e,t,r){"use strict";Object.defineProperty(t,"__esModule",{value:!0});var i=1,o=2;t.translateBufferLineToString=function(e,t,r,s){void 0===r&&(r=0),void 0===s&&(s=null);for(var n="",a=r,l=s,h=0;h<e.length;h++){var c=e[h];n+=c[i],0===c[o]&&(r>=h&&a--,s>=h&&l--)}var u=l||e.length;if(t){var f=n.search(/\s+$/);if(-1!==f&&(u=Math.min(u,f)),u<=a)return""}return n.substring(a,u)}},function(e,t,r){"use strict";function i(e,t){if(null==e.pageX)return null;for(var r=e.pageX,i=e.pageY;t&&t!==self.document.documentElement;)r-=t.offsetLeft,i-=t.offsetTop,t="offsetParent"in t?t.offsetParent:t.parentElement;return[r,i]}function o(e,t,r,o,s,n){if(!r.width||!r.height)return null;var a=i(e,t);return a?(a[0]=Math.ceil((a[0]+(n?r.width/2:0))/r.width),a[1]=Math.ceil(a[1]/r.height),a[0]=Math.min(Math.max(a[0],1),o+1),a[1]=Math.min(Math.max(a[1],1),s+1),a):null}Object.defineProperty(t,"__esModule",{value:!0}),t.getCoordsRelativeToElement=i,t.getCoords=o,t.getRawByteCoords=function(e,t,r,i,s){var n=o(e,t,r,i,s),a=n[0],l=n[1];return{x:a+=32,y:l+=32}}},function(e,t){},function(e,t){},function(e,t){},function(e,t){},function(e,t,r){"use strict";Object.defineProperty(t,"__esModule",{value:!0});var i=r(30),o=function(){function e(e){this._terminal=e,this.clear()}return Object.defineProperty(e.prototype,"lines",{get:function(){return this._lines},enumerable:!0,configurable:!0}),e.prototype.fillViewportRows=function(){if(0===this._lines.length)for(var e=this._terminal.rows;e--;)this.lines.push(this._terminal.blankLine())},e.prototype.clear=function(){this.ydisp=0,this.ybase=0,this.y=0,this.x=0,this.scrollBottom=0,this.scrollTop=0,this.tabs={},this._lines=new i.CircularList(this._terminal.scrollback),this.scrollBottom=this._terminal.rows-1},e.prototype.resize=function(e,t){if(0!==this._lines.length){if(this._terminal.cols<e)for(var r=[this._terminal.defAttr," ",1],i=0;i<this._lines.length;i++)for(void 0===this._lines.get(i)&&this._lines.set(i,this._terminal.blankLine(void 0,void 0,e));this._lines.get(i).length<e;)this._lines.get(i).push(r);var o=0;if(this._terminal.rows<t)for(var s=this._terminal.rows;s<t;s++)this._lines.length<t+this.ybase&&(this.ybase>0&&this._lines.length<=this.ybase+this.y+o+1?(this.ybase--,o++,this.ydisp>0&&this.ydisp--):this._lines.push(this._terminal.blankLine(void 0,void 0,e)));else for(s=this._terminal.rows;s>t;s--)this._lines.length>t+this.ybase&&(this._lines.length>this.ybase+this.y+1?this._lines.pop():(this.ybase++,this.ydisp++));this.y>=t&&(this.y=t-1),o&&(this.y+=o),this.x>=e&&(this.x=e-1),this.scrollTop=0,this.scrollBottom=t-1}},e}();t.Buffer=o},function(e,t,r){"use strict";var i=this&&this.__extends||function(){var e=Object.setPrototypeOf||{__proto__:[]}instanceof Array&&function(e,t){e.__proto__=t}||function(e,t){for(var r in t)t.hasOwnProperty(r)&&(e[r]=t[r])};return function(t,r){function i(){this.constructor=t}e(t,r),t.prototype=null===r?Object.create(r):(i.prototype=r.prototype,new i)}}();Object.defineProperty(t,"__esModule",{value:!0});var o=r(18),s=function(e){function t(t){var r=e.call(this)||this;return r._terminal=t,r._normal=new o.Buffer(r._terminal),r._normal.fillViewportRows(),r._alt=new o.Buffer(r._terminal),r._activeBuffer=r._normal,r}return i(t,e),Object.defineProperty(t.prototype,"alt",{get:function(){return this._alt},enumerable:!0,configurable:!0}),Object.defineProperty(t.prototype,"active",{get:function(){return this._activeBuffer},enumerable:!0,configurable:!0}),Object.defineProperty(t.prototype,"normal",{get:function(){return this._normal},enumerable:!0,configurable:!0}),t.prototype.activateNormalBuffer=function(){this._alt.clear(),this._activeBuffer=this._normal,this.emit("activate",this._normal)},t.prototype.activateAltBuffer=function(){this._alt.fillViewportRows(),this._activeBuffer=this._alt,this.emit("activate",this._alt)},t.prototype.resize=function(e,t){this._normal.resize(e,t),this._alt.resize(e,t)},t}(r(1).EventEmitter);t.BufferSet=s},function(e,t,r){"use strict";Object.defineProperty(t,"__esModule",{value:!0});var i=function(){function e(e,t,r){this.textarea=e,this.compositionView=t,this.terminal=r,this.isComposing=!1,this.isSendingComposition=!1,this.compositionPosition={start:null,end:null}}return e.prototype.compositionstart=function(){this.isComposing=!0,this.compositionPosition.start=this.textarea.value.length,this.compositionView.textContent="",this.compositionView.classList.add("active")},e.prototype.compositionupdate=function(e){var t=this;this.compositionView.textContent=e.data,this.updateCompositionElements(),setTimeout(function(){t.compositionPosition.end=t.textarea.value.length},0)},e.prototype.compositionend=function(){this.finalizeComposition(!0)},e.prototype.keydown=function(e){if(this.isComposing||this.isSendingComposition){if(229===e.keyCode)return!1;if(16===e.keyCode||17===e.keyCode||18===e.keyCode)return!1;this.finalizeComposition(!1)}return 229!==e.keyCode||(this.handleAnyTextareaChanges(),!1)},e.prototype.finalizeComposition=function(e){var t=this;if(this.compositionView.classList.remove("active"),this.isComposing=!1,this.clearTextareaPosition(),e){var r={start:this.compositionPosition.start,end:this.compositionPosition.end};this.isSendingComposition=!0,setTimeout(function(){if(t.isSendingComposition){t.isSendingComposition=!1;var e=void 0;e=t.isComposing?t.textarea.value.substring(r.start,r.end):t.textarea.value.substring(r.start),t.terminal.handler(e)}},0)}else{this.isSendingComposition=!1;var i=this.textarea.value.substring(this.compositionPosition.start,this.compositionPosition.end);this.terminal.handler(i)}},e.prototype.handleAnyTextareaChanges=function(){var e=this,t=this.textarea.value;setTimeout(function(){if(!e.isComposing){var r=e.textarea.value.replace(t,"");r.length>0&&e.terminal.handler(r)}},0)},e.prototype.updateCompositionElements=function(e){var t=this;if(this.isComposing){var r=this.terminal.element.querySelector(".terminal-cursor");if(r){var i=this.terminal.element.querySelector(".xterm-rows").offsetTop+r.offsetTop;this.compositionView.style.left=r.offsetLeft+"px",this.compositionView.style.top=i+"px",this.compositionView.style.height=r.offsetHeight+"px",this.compositionView.style.lineHeight=r.offsetHeight+"px";var o=this.compositionView.getBoundingClientRect();this.textarea.style.left=r.offsetLeft+"px",this.textarea.style.top=i+"px",this.textarea.style.width=o.width+"px",this.textarea.style.height=o.height+"px",this.textarea.style.lineHeight=o.height+"px"}e||setTimeout(function(){return t.updateCompositionElements(!0)},0)}},e.prototype.clearTextareaPosition=function(){this.textarea.style.left="",this.textarea.style.top=""},e}();t.CompositionHelper=i},function(e,t,r){"use strict";Object.defineProperty(t,"__esModule",{value:!0});var i=r(2),o=r(5),s=function(){function e(e){this._terminal=e}return e.prototype.addChar=function(e,r){if(e>=" "){var i=t.wcwidth(r);this._terminal.charset&&this._terminal.charset[e]&&(e=this._terminal.charset[e]);var o=this._terminal.buffer.y+this._terminal.buffer.ybase;if(!i&&this._terminal.buffer.x)return void(this._terminal.buffer.lines.get(o)[this._terminal.buffer.x-1]&&(this._terminal.buffer.lines.get(o)[this._terminal.buffer.x-1][2]?this._terminal.buffer.lines.get(o)[this._terminal.buffer.x-1][1]+=e:this._terminal.buffer.lines.get(o)[this._terminal.buffer.x-2]&&(this._terminal.buffer.lines.get(o)[this._terminal.buffer.x-2][1]+=e),this._terminal.updateRange(this._terminal.buffer.y)));if(this._terminal.buffer.x+i-1>=this._terminal.cols)if(this._terminal.wraparoundMode)this._terminal.buffer.x=0,this._terminal.buffer.y++,this._terminal.buffer.y>this._terminal.buffer.scrollBottom?(this._terminal.buffer.y--,this._terminal.scroll(!0)):this._terminal.buffer.lines.get(this._terminal.buffer.y).isWrapped=!0;else if(2===i)return;if(o=this._terminal.buffer.y+this._terminal.buffer.ybase,this._terminal.insertMode)for(var s=0;s<i;++s){0===this._terminal.buffer.lines.get(this._terminal.buffer.y+this._terminal.buffer.ybase).pop()[2]&&this._terminal.buffer.lines.get(o)[this._terminal.cols-2]&&2===this._terminal.buffer.lines.get(o)[this._terminal.cols-2][2]&&(this._terminal.buffer.lines.get(o)[this._terminal.cols-2]=[this._terminal.curAttr," ",1]),this._terminal.buffer.lines.get(o).splice(this._terminal.buffer.x,0,[this._terminal.curAttr," ",1])}this._terminal.buffer.lines.get(o)[this._terminal.buffer.x]=[this._terminal.curAttr,e,i],this._terminal.buffer.x++,this._terminal.updateRange(this._terminal.buffer.y),2===i&&(this._terminal.buffer.lines.get(o)[this._terminal.buffer.x]=[this._terminal.curAttr,"",0],this._terminal.buffer.x++)}},e.prototype.bell=function(){var e=this;this._terminal.visualBell&&(this._terminal.element.style.borderColor="white",setTimeout(function(){return e._terminal.element.style.borderColor=""},10),this._terminal.popOnBell&&this._terminal.focus())},e.prototype.lineFeed=function(){this._terminal.convertEol&&(this._terminal.buffer.x=0),this._terminal.buffer.y++,this._terminal.buffer.y>this._terminal.buffer.scrollBottom&&(this._terminal.buffer.y--,this._terminal.scroll()),this._terminal.buffer.x>=this._terminal.cols&&this._terminal.buffer.x--,this._terminal.emit("lineFeed")},e.prototype.carriageReturn=function(){this._terminal.buffer.x=0},e.prototype.backspace=function(){this._terminal.buffer.x>0&&this._terminal.buffer.x--},e.prototype.tab=function(){this._terminal.buffer.x=this._terminal.nextStop()},e.prototype.shiftOut=function(){this._terminal.setgLevel(1)},e.prototype.shiftIn=function(){this._terminal.setgLevel(0)},e.prototype.insertChars=function(e){var t,r,i,o;for((t=e[0])<1&&(t=1),r=this._terminal.buffer.y+this._terminal.buffer.ybase,i=this._terminal.buffer.x,o=[this._terminal.eraseAttr()," ",1];t--&&i<this._terminal.cols;)this._terminal.buffer.lines.get(r).splice(i++,0,o),this._terminal.buffer.lines.get(r).pop()},e.prototype.cursorUp=function(e){var t=e[0];t<1&&(t=1),this._terminal.buffer.y-=t,this._terminal.buffer.y<0&&(this._terminal.buffer.y=0)},e.prototype.cursorDown=function(e){var t=e[0];t<1&&(t=1),this._terminal.buffer.y+=t,this._terminal.buffer.y>=this._terminal.rows&&(this._terminal.buffer.y=this._terminal.rows-1),this._terminal.buffer.x>=this._terminal.cols&&this._terminal.buffer.x--},e.prototype.cursorForward=function(e){var t=e[0];t<1&&(t=1),this._terminal.buffer.x+=t,this._terminal.buffer.x>=this._terminal.cols&&(this._terminal.buffer.x=this._terminal.cols-1)},e.prototype.cursorBackward=function(e){var t=e[0];t<1&&(t=1),this._terminal.buffer.x>=this._terminal.cols&&this._terminal.buffer.x--,this._terminal.buffer.x-=t,this._terminal.buffer.x<0&&(this._terminal.buffer.x=0)},e.prototype.cursorNextLine=function(e){var t=e[0];t<1&&(t=1),this._terminal.buffer.y+=t,this._terminal.buffer.y>=this._terminal.rows&&(this._terminal.buffer.y=this._terminal.rows-1),this._terminal.buffer.x=0},e.prototype.cursorPrecedingLine=function(e){var t=e[0];t<1&&(t=1),this._terminal.buffer.y-=t,this._terminal.buffer.y<0&&(this._terminal.buffer.y=0),this._terminal.buffer.x=0},e.prototype.cursorCharAbsolute=function(e){var t=e[0];t<1&&(t=1),this._terminal.buffer.x=t-1},e.prototype.cursorPosition=function(e){var t,r;t=e[0]-1,r=e.length>=2?e[1]-1:0,t<0?t=0:t>=this._terminal.rows&&(t=this._terminal.rows-1),r<0?r=0:r>=this._terminal.cols&&(r=this._terminal.cols-1),this._terminal.buffer.x=r,this._terminal.buffer.y=t},e.prototype.cursorForwardTab=function(e){for(var t=e[0]||1;t--;)this._terminal.buffer.x=this._terminal.nextStop()},e.prototype.eraseInDisplay=function(e){var t;switch(e[0]){case 0:for(this._terminal.eraseRight(this._terminal.buffer.x,this._terminal.buffer.y),t=this._terminal.buffer.y+1;t<this._terminal.rows;t++)this._terminal.eraseLine(t);break;case 1:for(this._terminal.eraseLeft(this._terminal.buffer.x,this._terminal.buffer.y),t=this._terminal.buffer.y;t--;)this._terminal.eraseLine(t);break;case 2:for(t=this._terminal.rows;t--;)this._terminal.eraseLine(t);break;case 3:var r=this._terminal.buffer.lines.length-this._terminal.rows;r>0&&(this._terminal.buffer.lines.trimStart(r),this._terminal.buffer.ybase=Math.max(this._terminal.buffer.ybase-r,0),this._terminal.buffer.ydisp=Math.max(this._terminal.buffer.ydisp-r,0),this._terminal.emit("scroll",0))}},e.prototype.eraseInLine=function(e){switch(e[0]){case 0:this._terminal.eraseRight(this._terminal.buffer.x,this._terminal.buffer.y);break;case 1:this._terminal.eraseLeft(this._terminal.buffer.x,this._terminal.buffer.y);break;case 2:this._terminal.eraseLine(this._terminal.buffer.y)}},e.prototype.insertLines=function(e){var t,r,i;for((t=e[0])<1&&(t=1),r=this._terminal.buffer.y+this._terminal.buffer.ybase,i=this._terminal.rows-1-this._terminal.buffer.scrollBottom,i=this._terminal.rows-1+this._terminal.buffer.ybase-i+1;t--;)this._terminal.buffer.lines.length===this._terminal.buffer.lines.maxLength&&(this._terminal.buffer.lines.trimStart(1),this._terminal.buffer.ybase--,this._terminal.buffer.ydisp--,r--,i--),this._terminal.buffer.lines.splice(r,0,this._terminal.blankLine(!0)),this._terminal.buffer.lines.splice(i,1);this._terminal.updateRange(this._terminal.buffer.y),this._terminal.updateRange(this._terminal.buffer.scrollBottom)},e.prototype.deleteLines=function(e){var t,r,i;for((t=e[0])<1&&(t=1),r=this._terminal.buffer.y+this._terminal.buffer.ybase,i=this._terminal.rows-1-this._terminal.buffer.scrollBottom,i=this._terminal.rows-1+this._terminal.buffer.ybase-i;t--;)this._terminal.buffer.lines.length===this._terminal.buffer.lines.maxLength&&(this._terminal.buffer.lines.trimStart(1),this._terminal.buffer.ybase-=1,this._terminal.buffer.ydisp-=1),this._terminal.buffer.lines.splice(i+1,0,this._terminal.blankLine(!0)),this._terminal.buffer.lines.splice(r,1);this._terminal.updateRange(this._terminal.buffer.y),this._terminal.updateRange(this._terminal.buffer.scrollBottom)},e.prototype.deleteChars=function(e){var t,r,i;for((t=e[0])<1&&(t=1),r=this._terminal.buffer.y+this._terminal.buffer.ybase,i=[this._terminal.eraseAttr()," ",1];t--;)this._terminal.buffer.lines.get(r).splice(this._terminal.buffer.x,1),this._terminal.buffer.lines.get(r).push(i)},e.prototype.scrollUp=function(e){for(var t=e[0]||1;t--;)this._terminal.buffer.lines.splice(this._terminal.buffer.ybase+this._terminal.buffer.scrollTop,1),this._terminal.buffer.lines.splice(this._terminal.buffer.ybase+this._terminal.buffer.scrollBottom,0,this._terminal.blankLine());this._terminal.updateRange(this._terminal.buffer.scrollTop),this._terminal.updateRange(this._terminal.buffer.scrollBottom)},e.prototype.scrollDown=function(e){for(var t=e[0]||1;t--;)this._terminal.buffer.lines.splice(this._terminal.buffer.ybase+this._terminal.buffer.scrollBottom,1),this._terminal.buffer.lines.splice(this._terminal.buffer.ybase+this._terminal.buffer.scrollTop,0,this._terminal.blankLine());this._terminal.updateRange(this._terminal.buffer.scrollTop),this._terminal.updateRange(this._terminal.buffer.scrollBottom)},e.prototype.eraseChars=function(e){var t,r,i,o;for((t=e[0])<1&&(t=1),r=this._terminal.buffer.y+this._terminal.buffer.ybase,i=this._terminal.buffer.x,o=[this._terminal.eraseAttr()," ",1];t--&&i<this._terminal.cols;)this._terminal.buffer.lines.get(r)[i++]=o},e.prototype.cursorBackwardTab=function(e){for(var t=e[0]||1;t--;)this._terminal.buffer.x=this._terminal.prevStop()},e.prototype.charPosAbsolute=function(e){var t=e[0];t<1&&(t=1),this._terminal.buffer.x=t-1,this._terminal.buffer.x>=this._terminal.cols&&(this._terminal.buffer.x=this._terminal.cols-1)},e.prototype.HPositionRelative=function(e){var t=e[0];t<1&&(t=1),this._terminal.buffer.x+=t,this._terminal.buffer.x>=this._terminal.cols&&(this._terminal.buffer.x=this._terminal.cols-1)},e.prototype.repeatPrecedingCharacter=function(e){for(var t=e[0]||1,r=this._terminal.buffer.lines.get(this._terminal.buffer.ybase+this._terminal.buffer.y),i=r[this._terminal.buffer.x-1]||[this._terminal.defAttr," ",1];t--;)r[this._terminal.buffer.x++]=i},e.prototype.sendDeviceAttributes=function(e){e[0]>0||(this._terminal.prefix?">"===this._terminal.prefix&&(this._terminal.is("xterm")?this._terminal.send(i.C0.ESC+"[>0;276;0c"):this._terminal.is("rxvt-unicode")?this._terminal.send(i.C0.ESC+"[>85;95;0c"):this._terminal.is("linux")?this._terminal.send(e[0]+"c"):this._terminal.is("screen")&&this._terminal.send(i.C0.ESC+"[>83;40003;0c")):this._terminal.is("xterm")||this._terminal.is("rxvt-unicode")||this._terminal.is("screen")?this._terminal.send(i.C0.ESC+"[?1;2c"):this._terminal.is("linux")&&this._terminal.send(i.C0.ESC+"[?6c"))},e.prototype.linePosAbsolute=function(e){var t=e[0];t<1&&(t=1),this._terminal.buffer.y=t-1,this._terminal.buffer.y>=this._terminal.rows&&(this._terminal.buffer.y=this._terminal.rows-1)},e.prototype.VPositionRelative=function(e){var t=e[0];t<1&&(t=1),this._terminal.buffer.y+=t,this._terminal.buffer.y>=this._terminal.rows&&(this._terminal.buffer.y=this._terminal.rows-1),this._terminal.buffer.x>=this._terminal.cols&&this._terminal.buffer.x--},e.prototype.HVPosition=function(e){e[0]<1&&(e[0]=1),e[1]<1&&(e[1]=1),this._terminal.buffer.y=e[0]-1,this._terminal.buffer.y>=this._terminal.rows&&(this._terminal.buffer.y=this._terminal.rows-1),this._terminal.buffer.x=e[1]-1,this._terminal.buffer.x>=this._terminal.cols&&(this._terminal.buffer.x=this._terminal.cols-1)},e.prototype.tabClear=function(e){var t=e[0];t<=0?delete this._terminal.buffer.tabs[this._terminal.buffer.x]:3===t&&(this._terminal.buffer.tabs={})},e.prototype.setMode=function(e){if(e.length>1)for(var t=0;t<e.length;t++)this.setMode([e[t]]);else if(this._terminal.prefix){if("?"===this._terminal.prefix)switch(e[0]){case 1:this._terminal.applicationCursor=!0;break;case 2:this._terminal.setgCharset(0,o.DEFAULT_CHARSET),this._terminal.setgCharset(1,o.DEFAULT_CHARSET),this._terminal.setgCharset(2,o.DEFAULT_CHARSET),this._terminal.setgCharset(3,o.DEFAULT_CHARSET);break;case 3:this._terminal.savedCols=this._terminal.cols,this._terminal.resize(132,this._terminal.rows);break;case 6:this._terminal.originMode=!0;break;case 7:this._terminal.wraparoundMode=!0;break;case 12:break;case 66:this._terminal.log("Serial port requested application keypad."),this._terminal.applicationKeypad=!0,this._terminal.viewport.syncScrollArea();break;case 9:case 1e3:case 1002:case 1003:this._terminal.x10Mouse=9===e[0],this._terminal.vt200Mouse=1e3===e[0],this._terminal.normalMouse=e[0]>1e3,this._terminal.mouseEvents=!0,this._terminal.element.classList.add("enable-mouse-events"),this._terminal.selectionManager.disable(),this._terminal.log("Binding to mouse events.");break;case 1004:this._terminal.sendFocus=!0;break;case 1005:this._terminal.utfMouse=!0;break;case 1006:this._terminal.sgrMouse=!0;break;case 1015:this._terminal.urxvtMouse=!0;break;case 25:this._terminal.cursorHidden=!1;break;case 1049:case 47:case 1047:this._terminal.buffers.activateAltBuffer(),this._terminal.viewport.syncScrollArea(),this._terminal.showCursor()}}else switch(e[0]){case 4:this._terminal.insertMode=!0}},e.prototype.resetMode=function(e){if(e.length>1)for(var t=0;t<e.length;t++)this.resetMode([e[t]]);else if(this._terminal.prefix){if("?"===this._terminal.prefix)switch(e[0]){case 1:this._terminal.applicationCursor=!1;break;case 3:132===this._terminal.cols&&this._terminal.savedCols&&this._terminal.resize(this._terminal.savedCols,this._terminal.rows),delete this._terminal.savedCols;break;case 6:this._terminal.originMode=!1;break;case 7:this._terminal.wraparoundMode=!1;break;case 12:break;case 66:this._terminal.log("Switching back to normal keypad."),this._terminal.applicationKeypad=!1,this._terminal.viewport.syncScrollArea();break;case 9:case 1e3:case 1002:case 1003:this._terminal.x10Mouse=!1,this._terminal.vt200Mouse=!1,this._terminal.normalMouse=!1,this._terminal.mouseEvents=!1,this._terminal.element.classList.remove("enable-mouse-events"),this._terminal.selectionManager.enable();break;case 1004:this._terminal.sendFocus=!1;break;case 1005:this._terminal.utfMouse=!1;break;case 1006:this._terminal.sgrMouse=!1;break;case 1015:this._terminal.urxvtMouse=!1;break;case 25:this._terminal.cursorHidden=!0;break;case 1049:case 47:case 1047:this._terminal.buffers.activateNormalBuffer(),this._terminal.selectionManager.setBuffer(this._terminal.buffer.lines),this._terminal.refresh(0,this._terminal.rows-1),this._terminal.viewport.syncScrollArea(),this._terminal.showCursor()}}else switch(e[0]){case 4:this._terminal.insertMode=!1}},e.prototype.charAttributes=function(e){if(1!==e.length||0!==e[0]){for(var t,r=e.length,i=0,o=this._terminal.curAttr>>18,s=this._terminal.curAttr>>9&511,n=511&this._terminal.curAttr;i<r;i++)(t=e[i])>=30&&t<=37?s=t-30:t>=40&&t<=47?n=t-40:t>=90&&t<=97?s=(t+=8)-90:t>=100&&t<=107?n=(t+=8)-100:0===t?(o=this._terminal.defAttr>>18,s=this._terminal.defAttr>>9&511,n=511&this._terminal.defAttr):1===t?o|=1:4===t?o|=2:5===t?o|=4:7===t?o|=8:8===t?o|=16:22===t?o&=-2:24===t?o&=-3:25===t?o&=-5:27===t?o&=-9:28===t?o&=-17:39===t?s=this._terminal.defAttr>>9&511:49===t?n=511&this._terminal.defAttr:38===t?2===e[i+1]?(i+=2,-1===(s=this._terminal.matchColor(255&e[i],255&e[i+1],255&e[i+2]))&&(s=511),i+=2):5===e[i+1]&&(s=t=255&e[i+=2]):48===t?2===e[i+1]?(i+=2,-1===(n=this._terminal.matchColor(255&e[i],255&e[i+1],255&e[i+2]))&&(n=511),i+=2):5===e[i+1]&&(n=t=255&e[i+=2]):100===t?(s=this._terminal.defAttr>>9&511,n=511&this._terminal.defAttr):this._terminal.error("Unknown SGR attribute: %d.",t);this._terminal.curAttr=o<<18|s<<9|n}else this._terminal.curAttr=this._terminal.defAttr},e.prototype.deviceStatus=function(e){if(this._terminal.prefix){if("?"===this._terminal.prefix)switch(e[0]){case 6:this._terminal.send(i.C0.ESC+"[?"+(this._terminal.buffer.y+1)+";"+(this._terminal.buffer.x+1)+"R")}}else switch(e[0]){case 5:this._terminal.send(i.C0.ESC+"[0n");break;case 6:this._terminal.send(i.C0.ESC+"["+(this._terminal.buffer.y+1)+";"+(this._terminal.buffer.x+1)+"R")}},e.prototype.softReset=function(e){this._terminal.cursorHidden=!1,this._terminal.insertMode=!1,this._terminal.originMode=!1,this._terminal.wraparoundMode=!0,this._terminal.applicationKeypad=!1,this._terminal.viewport.syncScrollArea(),this._terminal.applicationCursor=!1,this._terminal.buffer.scrollTop=0,this._terminal.buffer.scrollBottom=this._terminal.rows-1,this._terminal.curAttr=this._terminal.defAttr,this._terminal.buffer.x=this._terminal.buffer.y=0,this._terminal.charset=null,this._terminal.glevel=0,this._terminal.charsets=[null]},e.prototype.setCursorStyle=function(e){var t=e[0]<1?1:e[0];switch(t){case 1:case 2:this._terminal.setOption("cursorStyle","block");break;case 3:case 4:this._terminal.setOption("cursorStyle","underline");break;case 5:case 6:this._terminal.setOption("cursorStyle","bar")}var r=t%2==1;this._terminal.setOption("cursorBlink",r)},e.prototype.setScrollRegion=function(e){this._terminal.prefix||(this._terminal.buffer.scrollTop=(e[0]||1)-1,this._terminal.buffer.scrollBottom=(e[1]&&e[1]<=this._terminal.rows?e[1]:this._terminal.rows)-1,this._terminal.buffer.x=0,this._terminal.buffer.y=0)},e.prototype.saveCursor=function(e){this._terminal.buffer.savedX=this._terminal.buffer.x,this._terminal.buffer.savedY=this._terminal.buffer.y},e.prototype.restoreCursor=function(e){this._terminal.buffer.x=this._terminal.buffer.savedX||0,this._terminal.buffer.y=this._terminal.buffer.savedY||0},e}();t.InputHandler=s,t.wcwidth=function(e){var t=[[768,879],[1155,1158],[1160,1161],[1425,1469],[1471,1471],[1473,1474],[1476,1477],[1479,1479],[1536,1539],[1552,1557],[1611,1630],[1648,1648],[1750,1764],[1767,1768],[1770,1773],[1807,1807],[1809,1809],[1840,1866],[1958,1968],[2027,2035],[2305,2306],[2364,2364],[2369,2376],[2381,2381],[2385,2388],[2402,2403],[2433,2433],[2492,2492],[2497,2500],[2509,2509],[2530,2531],[2561,2562],[2620,2620],[2625,2626],[2631,2632],[2635,2637],[2672,2673],[2689,2690],[2748,2748],[2753,2757],[2759,2760],[2765,2765],[2786,2787],[2817,2817],[2876,2876],[2879,2879],[2881,2883],[2893,2893],[2902,2902],[2946,2946],[3008,3008],[3021,3021],[3134,3136],[3142,3144],[3146,3149],[3157,3158],[3260,3260],[3263,3263],[3270,3270],[3276,3277],[3298,3299],[3393,3395],[3405,3405],[3530,3530],[3538,3540],[3542,3542],[3633,3633],[3636,3642],[3655,3662],[3761,3761],[3764,3769],[3771,3772],[3784,3789],[3864,3865],[3893,3893],[3895,3895],[3897,3897],[3953,3966],[3968,3972],[3974,3975],[3984,3991],[3993,4028],[4038,4038],[4141,4144],[4146,4146],[4150,4151],[4153,4153],[4184,4185],[4448,4607],[4959,4959],[5906,5908],[5938,5940],[5970,5971],[6002,6003],[6068,6069],[6071,6077],[6086,6086],[6089,6099],[6109,6109],[6155,6157],[6313,6313],[6432,6434],[6439,6440],[6450,6450],[6457,6459],[6679,6680],[6912,6915],[6964,6964],[6966,6970],[6972,6972],[6978,6978],[7019,7027],[7616,7626],[7678,7679],[8203,8207],[8234,8238],[8288,8291],[8298,8303],[8400,8431],[12330,12335],[12441,12442],[43014,43014],[43019,43019],[43045,43046],[64286,64286],[65024,65039],[65056,65059],[65279,65279],[65529,65531]],r=[[68097,68099],[68101,68102],[68108,68111],[68152,68154],[68159,68159],[119143,119145],[119155,119170],[119173,119179],[119210,119213],[119362,119364],[917505,917505],[917536,917631],[917760,917999]];function i(e,t){var r,i=0,o=t.length-1;if(e<t[0][0]||e>t[o][1])return!1;for(;o>=i;)if(e>t[r=i+o>>1][1])i=r+1;else{if(!(e<t[r][0]))return!0;o=r-1}return!1}function o(r){return 0===r?e.nul:r<32||r>=127&&r<160?e.control:i(r,t)?0:function(e){return e>=4352&&(e<=4447||9001===e||9002===e||e>=11904&&e<=42191&&12351!==e||e>=44032&&e<=55203||e>=63744&&e<=64255||e>=65040&&e<=65049||e>=65072&&e<=65135||e>=65280&&e<=65376||e>=65504&&e<=65510)}(r)?2:1}var s=0|e.control,n=null;return function(e){if((e|=0)<32)return 0|s;if(e<127)return 1;var t=n||function(){n="undefined"==typeof Uint32Array?new Array(4096):new Uint32Array(4096);for(var e=0;e<4096;++e){for(var t=0,r=16;r--;)t=t<<2|o(16*e+r);n[e]=t}return n}();return e<65536?t[e>>4]>>((15&e)<<1)&3:function(e){return i(e,r)?0:e>=131072&&e<=196605||e>=196608&&e<=262141?2:1}(e)}}({nul:0,control:0})},function(e,t,r){"use strict";Object.defineProperty(t,"__esModule",{value:!0});var i=new RegExp("(?:^|[^\\da-z\\.-]+)((https?:\\/\\/)((([\\da-z\\.-]+)\\.([a-z\\.]{2,6}))|((\\d{1,3}\\.){3}\\d{1,3})|(localhost))(:\\d{1,5})?(\\/[\\/\\w\\.\\-%~]*)*(\\?[0-9\\w\\[\\]\\(\\)\\/\\?\\!#@$%&'*+,:;~\\=\\.\\-]*)?(#[0-9\\w\\[\\]\\(\\)\\/\\?\\!#@$%&'*+,:;~\\=\\.\\-]*)?)($|[^\\/\\w\\.\\-%]+)"),o=0,s=function(){function e(){this._nextLinkMatcherId=o,this._rowTimeoutIds=[],this._linkMatchers=[],this.registerLinkMatcher(i,null,{matchIndex:1})}return e.prototype.attachToDom=function(e,t){this._document=e,this._rows=t},e.prototype.linkifyRow=function(t){if(this._document){var r=this._rowTimeoutIds[t];r&&clearTimeout(r),this._rowTimeoutIds[t]=setTimeout(this._linkifyRow.bind(this,t),e.TIME_BEFORE_LINKIFY)}},e.prototype.setHypertextLinkHandler=function(e){this._linkMatchers[o].handler=e},e.prototype.setHypertextValidationCallback=function(e){this._linkMatchers[o].validationCallback=e},e.prototype.registerLinkMatcher=function(e,t,r){if(void 0===r&&(r={}),this._nextLinkMatcherId!==o&&!t)throw new Error("handler must be defined");var i={id:this._nextLinkMatcherId++,regex:e,handler:t,matchIndex:r.matchIndex,validationCallback:r.validationCallback,priority:r.priority||0};return this._addLinkMatcherToList(i),i.id},e.prototype._addLinkMatcherToList=function(e){if(0!==this._linkMatchers.length){for(var t=this._linkMatchers.length-1;t>=0;t--)if(e.priority<=this._linkMatchers[t].priority)return void this._linkMatchers.splice(t+1,0,e);this._linkMatchers.splice(0,0,e)}else this._linkMatchers.push(e)},e.prototype.deregisterLinkMatcher=function(e){for(var t=1;t<this._linkMatchers.length;t++)if(this._linkMatchers[t].id===e)return this._linkMatchers.splice(t,1),!0;return!1},e.prototype._linkifyRow=function(e){var t=this._rows[e];if(t){t.textContent;for(var r=0;r<this._linkMatchers.length;r++){var i=this._linkMatchers[r],o=this._doLinkifyRow(t,i);if(o.length>0){if(i.validationCallback)for(var s=function(e){var t=o[e];i.validationCallback(t.textContent,t,function(e){e||t.classList.add("xterm-invalid-link")})},n=0;n<o.length;n++)s(n);return}}}},e.prototype._doLinkifyRow=function(e,t){var r=[],i=t.id===o,s=e.childNodes,n=e.textContent.match(t.regex);if(!n||0===n.length)return r;for(var a=n["number"!=typeof t.matchIndex?0:t.matchIndex],l=n.index+a.length,h=0;h<s.length;h++){var c=s[h],u=c.textContent.indexOf(a);if(u>=0){var f=this._createAnchorElement(a,t.handler,i);if(c.textContent.length===a.length)if(3===c.nodeType)this._replaceNode(c,f);else{var p=c;if("A"===p.nodeName)return r;p.innerHTML="",p.appendChild(f)}else if(c.childNodes.length>1)for(var d=0;d<c.childNodes.length;d++){var g=c.childNodes[d],m=g.textContent.indexOf(a);if(-1!==m){this._replaceNodeSubstringWithNode(g,f,a,m);break}}else{h+=this._replaceNodeSubstringWithNode(c,f,a,u)}if(r.push(f),!(n=e.textContent.substring(l).match(t.regex))||0===n.length)return r;a=n["number"!=typeof t.matchIndex?0:t.matchIndex],l+=n.index+a.length}}return r},e.prototype._createAnchorElement=function(e,t,r){var i=this._document.createElement("a");return i.textContent=e,i.draggable=!1,r?(i.href=e,i.target="_blank",i.addEventListener("click",function(r){if(t)return t(r,e)})):i.addEventListener("click",function(r){if(!i.classList.contains("xterm-invalid-link"))return t(r,e)}),i},e.prototype._replaceNode=function(e){for(var t=[],r=1;r<arguments.length;r++)t[r-1]=arguments[r];for(var i=e.parentNode,o=0;o<t.length;o++)i.insertBefore(t[o],e);i.removeChild(e)},e.prototype._replaceNodeSubstringWithNode=function(e,t,r,i){if(1===e.childNodes.length&&(e=e.childNodes[0]),3!==e.nodeType)throw new Error("targetNode must be a text node or only contain a single text node");var o=e.textContent;if(0===i){var s=o.substring(r.length),n=this._document.createTextNode(s);return this._replaceNode(e,t,n),0}if(i===e.textContent.length-r.length){var a=o.substring(0,i),l=this._document.createTextNode(a);return this._replaceNode(e,l,t),0}var h=o.substring(0,i),c=this._document.createTextNode(h),u=o.substring(i+r.length),f=this._document.createTextNode(u);return this._replaceNode(e,c,t,f),1},e}();s.TIME_BEFORE_LINKIFY=200,t.Linkifier=s},function(e,t,r){"use strict";Object.defineProperty(t,"__esModule",{value:!0});var i=r(2),o=r(5),s={};s[i.C0.BEL]=function(e,t){return t.bell()},s[i.C0.LF]=function(e,t){return t.lineFeed()},s[i.C0.VT]=s[i.C0.LF],s[i.C0.FF]=s[i.C0.LF],s[i.C0.CR]=function(e,t){return t.carriageReturn()},s[i.C0.BS]=function(e,t){return t.backspace()},s[i.C0.HT]=function(e,t){return t.tab()},s[i.C0.SO]=function(e,t){return t.shiftOut()},s[i.C0.SI]=function(e,t){return t.shiftIn()},s[i.C0.ESC]=function(e,t){return e.setState(l.ESCAPED)};var n={"[":function(e,t){t.params=[],t.currentParam=0,e.setState(l.CSI_PARAM)},"]":function(e,t){t.params=[],t.currentParam=0,e.setState(l.OSC)},P:function(e,t){t.params=[],t.currentParam=0,e.setState(l.DCS)},_:function(e,t){e.setState(l.IGNORE)},"^":function(e,t){e.setState(l.IGNORE)},c:function(e,t){t.reset()},E:function(e,t){t.buffer.x=0,t.index(),e.setState(l.NORMAL)},D:function(e,t){t.index(),e.setState(l.NORMAL)},M:function(e,t){t.reverseIndex(),e.setState(l.NORMAL)},"%":function(e,t){t.setgLevel(0),t.setgCharset(0,o.DEFAULT_CHARSET),e.setState(l.NORMAL),e.skipNextChar()}};n[i.C0.CAN]=function(e){return e.setState(l.NORMAL)};var a={"?":function(e){return e.setPrefix("?")},">":function(e){return e.setPrefix(">")},"!":function(e){return e.setPrefix("!")},0:function(e){return e.setParam(10*e.getParam())},1:function(e){return e.setParam(10*e.getParam()+1)},2:function(e){return e.setParam(10*e.getParam()+2)},3:function(e){return e.setParam(10*e.getParam()+3)},4:function(e){return e.setParam(10*e.getParam()+4)},5:function(e){return e.setParam(10*e.getParam()+5)},6:function(e){return e.setParam(10*e.getParam()+6)},7:function(e){return e.setParam(10*e.getParam()+7)},8:function(e){return e.setParam(10*e.getParam()+8)},9:function(e){return e.setParam(10*e.getParam()+9)},$:function(e){return e.setPostfix("$")},'"':function(e){return e.setPostfix('"')}," ":function(e){return e.setPostfix(" ")},"'":function(e){return e.setPostfix("'")},";":function(e){return e.finalizeParam()}};a[i.C0.CAN]=function(e){return e.setState(l.NORMAL)};var l,h={};h["@"]=function(e,t,r){return e.insertChars(t)},h.A=function(e,t,r){return e.cursorUp(t)},h.B=function(e,t,r){return e.cursorDown(t)},h.C=function(e,t,r){return e.cursorForward(t)},h.D=function(e,t,r){return e.cursorBackward(t)},h.E=function(e,t,r){return e.cursorNextLine(t)},h.F=function(e,t,r){return e.cursorPrecedingLine(t)},h.G=function(e,t,r){return e.cursorCharAbsolute(t)},h.H=function(e,t,r){return e.cursorPosition(t)},h.I=function(e,t,r){return e.cursorForwardTab(t)},h.J=function(e,t,r){return e.eraseInDisplay(t)},h.K=function(e,t,r){return e.eraseInLine(t)},h.L=function(e,t,r){return e.insertLines(t)},h.M=function(e,t,r){return e.deleteLines(t)},h.P=function(e,t,r){return e.deleteChars(t)},h.S=function(e,t,r){return e.scrollUp(t)},h.T=function(e,t,r){t.length<2&&!r&&e.scrollDown(t)},h.X=function(e,t,r){return e.eraseChars(t)},h.Z=function(e,t,r){return e.cursorBackwardTab(t)},h["`"]=function(e,t,r){return e.charPosAbsolute(t)},h.a=function(e,t,r){return e.HPositionRelative(t)},h.b=function(e,t,r){return e.repeatPrecedingCharacter(t)},h.c=function(e,t,r){return e.sendDeviceAttributes(t)},h.d=function(e,t,r){return e.linePosAbsolute(t)},h.e=function(e,t,r){return e.VPositionRelative(t)},h.f=function(e,t,r){return e.HVPosition(t)},h.g=function(e,t,r){return e.tabClear(t)},h.h=function(e,t,r){return e.setMode(t)},h.l=function(e,t,r){return e.resetMode(t)},h.m=function(e,t,r){return e.charAttributes(t)},h.n=function(e,t,r){return e.deviceStatus(t)},h.p=function(e,t,r){switch(r){case"!":e.softReset(t)}},h.q=function(e,t,r,i){" "===i&&e.setCursorStyle(t)},h.r=function(e,t){return e.setScrollRegion(t)},h.s=function(e,t){return e.saveCursor(t)},h.u=function(e,t){return e.restoreCursor(t)},h[i.C0.CAN]=function(e,t,r,i,o){return o.setState(l.NORMAL)},function(e){e[e.NORMAL=0]="NORMAL",e[e.ESCAPED=1]="ESCAPED",e[e.CSI_PARAM=2]="CSI_PARAM",e[e.CSI=3]="CSI",e[e.OSC=4]="OSC",e[e.CHARSET=5]="CHARSET",e[e.DCS=6]="DCS",e[e.IGNORE=7]="IGNORE"}(l||(l={}));var c=function(){function e(e,t){this._inputHandler=e,this._terminal=t,this._state=l.NORMAL}return e.prototype.parse=function(e){var t,r,c,u,f=e.length;for(this._terminal.debug&&this._terminal.log("data: "+e),this._position=0,this._terminal.surrogate_high&&(e=this._terminal.surrogate_high+e,this._terminal.surrogate_high="");this._position<f;this._position++){if(r=e[this._position],55296<=(c=e.charCodeAt(this._position))&&c<=56319){if(u=e.charCodeAt(this._position+1),isNaN(u)){this._terminal.surrogate_high=r;continue}c=1024*(c-55296)+(u-56320)+65536,r+=e.charAt(this._position+1)}if(!(56320<=c&&c<=57343))switch(this._state){case l.NORMAL:r in s?s[r](this,this._inputHandler):this._inputHandler.addChar(r,c);break;case l.ESCAPED:if(r in n){n[r](this,this._terminal);break}switch(r){case"(":case")":case"*":case"+":case"-":case".":switch(r){case"(":this._terminal.gcharset=0;break;case")":this._terminal.gcharset=1;break;case"*":this._terminal.gcharset=2;break;case"+":this._terminal.gcharset=3;break;case"-":this._terminal.gcharset=1;break;case".":this._terminal.gcharset=2}this._state=l.CHARSET;break;case"/":this._terminal.gcharset=3,this._state=l.CHARSET,this._position--;break;case"N":case"O":break;case"n":this._terminal.setgLevel(2);break;case"o":case"|":this._terminal.setgLevel(3);break;case"}":this._terminal.setgLevel(2);break;case"~":this._terminal.setgLevel(1);break;case"7":this._inputHandler.saveCursor(),this._state=l.NORMAL;break;case"8":this._inputHandler.restoreCursor(),this._state=l.NORMAL;break;case"#":this._state=l.NORMAL,this._position++;break;case"H":this._terminal.tabSet(),this._state=l.NORMAL;break;case"=":this._terminal.log("Serial port requested application keypad."),this._terminal.applicationKeypad=!0,this._terminal.viewport.syncScrollArea(),this._state=l.NORMAL;break;case">":this._terminal.log("Switching back to normal keypad."),this._terminal.applicationKeypad=!1,this._terminal.viewport.syncScrollArea(),this._state=l.NORMAL;break;default:this._state=l.NORMAL,this._terminal.error("Unknown ESC control: %s.",r)}break;case l.CHARSET:r in o.CHARSETS?(t=o.CHARSETS[r],"/"===r&&this.skipNextChar()):t=o.DEFAULT_CHARSET,this._terminal.setgCharset(this._terminal.gcharset,t),this._terminal.gcharset=null,this._state=l.NORMAL;break;case l.OSC:if(r===i.C0.ESC||r===i.C0.BEL){switch(r===i.C0.ESC&&this._position++,this._terminal.params.push(this._terminal.currentParam),this._terminal.params[0]){case 0:case 1:case 2:this._terminal.params[1]&&(this._terminal.title=this._terminal.params[1],this._terminal.handleTitle(this._terminal.title))}this._terminal.params=[],this._terminal.currentParam=0,this._state=l.NORMAL}else this._terminal.params.length?this._terminal.currentParam+=r:r>="0"&&r<="9"?this._terminal.currentParam=10*this._terminal.currentParam+r.charCodeAt(0)-48:";"===r&&(this._terminal.params.push(this._terminal.currentParam),this._terminal.currentParam="");break;case l.CSI_PARAM:if(r in a){a[r](this);break}this.finalizeParam(),this._state=l.CSI;case l.CSI:r in h?(this._terminal.debug&&this._terminal.log("CSI "+(this._terminal.prefix?this._terminal.prefix:"")+" "+(this._terminal.params?this._terminal.params.join(";"):"")+" "+(this._terminal.postfix?this._terminal.postfix:"")+" "+r),h[r](this._inputHandler,this._terminal.params,this._terminal.prefix,this._terminal.postfix,this)):this._terminal.error("Unknown CSI code: %s.",r),this._state=l.NORMAL,this._terminal.prefix="",this._terminal.postfix="";break;case l.DCS:if(r===i.C0.ESC||r===i.C0.BEL){r===i.C0.ESC&&this._position++;var p=void 0,d=void 0;switch(this._terminal.prefix){case"":break;case"$q":switch(d=!1,p=this._terminal.currentParam){case'"q':p='0"q';break;case'"p':p='61"p';break;case"r":p=this._terminal.buffer.scrollTop+1+";"+(this._terminal.buffer.scrollBottom+1)+"r";break;case"m":p="0m";break;default:this._terminal.error("Unknown DCS Pt: %s.",p),p=""}this._terminal.send(i.C0.ESC+"P"+ +d+"$r"+p+i.C0.ESC+"\\");break;case"+p":break;case"+q":p=this._terminal.currentParam,d=!1,this._terminal.send(i.C0.ESC+"P"+ +d+"+r"+p+i.C0.ESC+"\\");break;default:this._terminal.error("Unknown DCS prefix: %s.",this._terminal.prefix)}this._terminal.currentParam=0,this._terminal.prefix="",this._state=l.NORMAL}else this._terminal.currentParam?this._terminal.currentParam+=r:this._terminal.prefix||"$"===r||"+"===r?2===this._terminal.prefix.length?this._terminal.currentParam=r:this._terminal.prefix+=r:this._terminal.currentParam=r;break;case l.IGNORE:r!==i.C0.ESC&&r!==i.C0.BEL||(r===i.C0.ESC&&this._position++,this._state=l.NORMAL)}}return this._state},e.prototype.setState=function(e){this._state=e},e.prototype.setPrefix=function(e){this._terminal.prefix=e},e.prototype.setPostfix=function(e){this._terminal.postfix=e},e.prototype.setParam=function(e){this._terminal.currentParam=e},e.prototype.getParam=function(){return this._terminal.currentParam},e.prototype.finalizeParam=function(){this._terminal.params.push(this._terminal.currentParam),this._terminal.currentParam=0},e.prototype.skipNextChar=function(){this._position++},e}();t.Parser=c},function(e,t,r){"use strict";Object.defineProperty(t,"__esModule",{value:!0});var i,o=r(31);!function(e){e[e.BOLD=1]="BOLD",e[e.UNDERLINE=2]="UNDERLINE",e[e.BLINK=4]="BLINK",e[e.INVERSE=8]="INVERSE",e[e.INVISIBLE=16]="INVISIBLE"}(i||(i={}));var s=null,n=function(){function e(e){this._terminal=e,this._refreshRowsQueue=[],this._refreshFramesSkipped=0,this._refreshAnimationFrame=null,this._spanElementObjectPool=new o.DomElementObjectPool("span"),null===s&&(s=function(e){var t=e.ownerDocument.createElement("span");t.innerHTML="hello world",e.appendChild(t);var r=t.offsetWidth,i=t.offsetHeight;t.style.fontWeight="bold";var o=t.offsetWidth,s=t.offsetHeight;return e.removeChild(t),r!==o||i!==s}(this._terminal.element)),this._spanElementObjectPool=new o.DomElementObjectPool("span")}return e.prototype.queueRefresh=function(e,t){this._refreshRowsQueue.push({start:e,end:t}),this._refreshAnimationFrame||(this._refreshAnimationFrame=window.requestAnimationFrame(this._refreshLoop.bind(this)))},e.prototype._refreshLoop=function(){if(this._terminal.writeBuffer.length>0&&this._refreshFramesSkipped++<=5)this._refreshAnimationFrame=window.requestAnimationFrame(this._refreshLoop.bind(this));else{var e,t;if(this._refreshFramesSkipped=0,this._refreshRowsQueue.length>4)e=0,t=this._terminal.rows-1;else{e=this._refreshRowsQueue[0].start,t=this._refreshRowsQueue[0].end;for(var r=1;r<this._refreshRowsQueue.length;r++)this._refreshRowsQueue[r].start<e&&(e=this._refreshRowsQueue[r].start),this._refreshRowsQueue[r].end>t&&(t=this._refreshRowsQueue[r].end)}this._refreshRowsQueue=[],this._refreshAnimationFrame=null,this._refresh(e,t)}},e.prototype._refresh=function(e,t){var r;t-e>=this._terminal.rows/2&&(r=this._terminal.element.parentNode)&&this._terminal.element.removeChild(this._terminal.rowContainer);var o=this._terminal.cols,n=e;for(t>=this._terminal.rows&&(this._terminal.log("`end` is too large. Most likely a bad CSR."),t=this._terminal.rows-1);n<=t;n++){var a=n+this._terminal.buffer.ydisp,l=this._terminal.buffer.lines.get(a),h=void 0;h=this._terminal.buffer.y===n-(this._terminal.buffer.ybase-this._terminal.buffer.ydisp)&&this._terminal.cursorState&&!this._terminal.cursorHidden?this._terminal.buffer.x:-1;for(var c=this._terminal.defAttr,u=document.createDocumentFragment(),f="",p=void 0;this._terminal.children[n].children.length;){var d=this._terminal.children[n].children[0];this._terminal.children[n].removeChild(d),this._spanElementObjectPool.release(d)}for(var g=0;g<o;g++){var m=l[g][0],A=l[g][1],b=l[g][2],y=g===h;if(b){if((m!==c||y)&&(c===this._terminal.defAttr||y||(f&&(p.innerHTML=f,f=""),u.appendChild(p),p=null),m!==this._terminal.defAttr||y)){f&&!p&&(p=this._spanElementObjectPool.acquire()),p&&(f&&(p.innerHTML=f,f=""),u.appendChild(p)),p=this._spanElementObjectPool.acquire();var C=511&m,_=m>>9&511,w=m>>18;if(y&&(p.classList.add("reverse-video"),p.classList.add("terminal-cursor")),w&i.BOLD&&(s||p.classList.add("xterm-bold"),_<8&&(_+=8)),w&i.UNDERLINE&&p.classList.add("xterm-underline"),w&i.BLINK&&p.classList.add("xterm-blink"),w&i.INVERSE){var S=C;C=_,_=S,1&w&&_<8&&(_+=8)}w&i.INVISIBLE&&!y&&p.classList.add("xterm-hidden"),w&i.INVERSE&&(257===C&&(C=15),256===_&&(_=0)),C<256&&p.classList.add("xterm-bg-color-"+C),_<256&&p.classList.add("xterm-color-"+_)}if(2===b)f+='<span class="xterm-wide-char">'+A+"</span>";else if(A.charCodeAt(0)>255)f+='<span class="xterm-normal-char">'+A+"</span>";else switch(A){case"&":f+="&amp;";break;case"<":f+="&lt;";break;case">":f+="&gt;";break;default:f+=A<=" "?"&nbsp;":A}c=y?-1:m}}f&&!p&&(p=this._spanElementObjectPool.acquire()),p&&(f&&(p.innerHTML=f,f=""),u.appendChild(p),p=null),this._terminal.children[n].appendChild(u)}r&&this._terminal.element.appendChild(this._terminal.rowContainer),this._terminal.emit("refresh",{element:this._terminal.element,start:e,end:t})},e.prototype.refreshSelection=function(e,t){for(;this._terminal.selectionContainer.children.length;)this._terminal.selectionContainer.removeChild(this._terminal.selectionContainer.children[0]);if(e&&t){var r=e[1]-this._terminal.buffer.ydisp,i=t[1]-this._terminal.buffer.ydisp,o=Math.max(r,0),s=Math.min(i,this._terminal.rows-1);if(!(o>=this._terminal.rows||s<0)){var n=document.createDocumentFragment(),a=r===o?e[0]:0,l=o===s?t[0]:this._terminal.cols;n.appendChild(this._createSelectionElement(o,a,l));var h=s-o-1;if(n.appendChild(this._createSelectionElement(o+1,0,this._terminal.cols,h)),o!==s){var c=i===s?t[0]:this._terminal.cols;n.appendChild(this._createSelectionElement(s,0,c))}this._terminal.selectionContainer.appendChild(n)}}},e.prototype._createSelectionElement=function(e,t,r,i){void 0===i&&(i=1);var o=document.createElement("div");return o.style.height=i*this._terminal.charMeasure.height+"px",o.style.top=e*this._terminal.charMeasure.height+"px",o.style.left=t*this._terminal.charMeasure.width+"px",o.style.width=this._terminal.charMeasure.width*(r-t)+"px",o},e}();t.Renderer=n},function(e,t,r){"use strict";var i=this&&this.__extends||function(){var e=Object.setPrototypeOf||{__proto__:[]}instanceof Array&&function(e,t){e.__proto__=t}||function(e,t){for(var r in t)t.hasOwnProperty(r)&&(e[r]=t[r])};return function(t,r){function i(){this.constructor=t}e(t,r),t.prototype=null===r?Object.create(r):(i.prototype=r.prototype,new i)}}();Object.defineProperty(t,"__esModule",{value:!0});var o,s=r(13),n=r(11),a=r(1),l=r(26),h=r(12),c=String.fromCharCode(160),u=new RegExp(c,"g");!function(e){e[e.NORMAL=0]="NORMAL",e[e.WORD=1]="WORD",e[e.LINE=2]="LINE"}(o||(o={}));var f=function(e){function t(t,r,i,s){var n=e.call(this)||this;return n._terminal=t,n._buffer=r,n._rowContainer=i,n._charMeasure=s,n._enabled=!0,n._initListeners(),n.enable(),n._model=new l.SelectionModel(t),n._activeSelectionMode=o.NORMAL,n}return i(t,e),t.prototype._initListeners=function(){var e=this;this._mouseMoveListener=function(t){return e._onMouseMove(t)},this._mouseUpListener=function(t){return e._onMouseUp(t)},this._rowContainer.addEventListener("mousedown",function(t){return e._onMouseDown(t)}),this._buffer.on("trim",function(t){return e._onTrim(t)})},t.prototype.disable=function(){this.clearSelection(),this._enabled=!1},t.prototype.enable=function(){this._enabled=!0},t.prototype.setBuffer=function(e){this._buffer=e,this.clearSelection()},Object.defineProperty(t.prototype,"selectionStart",{get:function(){return this._model.finalSelectionStart},enumerable:!0,configurable:!0}),Object.defineProperty(t.prototype,"selectionEnd",{get:function(){return this._model.finalSelectionEnd},enumerable:!0,configurable:!0}),Object.defineProperty(t.prototype,"hasSelection",{get:function(){var e=this._model.finalSelectionStart,t=this._model.finalSelectionEnd;return!(!e||!t)&&(e[0]!==t[0]||e[1]!==t[1])},enumerable:!0,configurable:!0}),Object.defineProperty(t.prototype,"selectionText",{get:function(){var e=this._model.finalSelectionStart,t=this._model.finalSelectionEnd;if(!e||!t)return"";var r=e[1]===t[1]?t[0]:null,i=[];i.push(h.translateBufferLineToString(this._buffer.get(e[1]),!0,e[0],r));for(var o=e[1]+1;o<=t[1]-1;o++){var s=this._buffer.get(o),a=h.translateBufferLineToString(s,!0);s.isWrapped?i[i.length-1]+=a:i.push(a)}if(e[1]!==t[1]){s=this._buffer.get(t[1]),a=h.translateBufferLineToString(s,!0,0,t[0]);s.isWrapped?i[i.length-1]+=a:i.push(a)}return i.map(function(e){return e.replace(u," ")}).join(n.isMSWindows?"\r\n":"\n")},enumerable:!0,configurable:!0}),t.prototype.clearSelection=function(){this._model.clearSelection(),this._removeMouseDownListeners(),this.refresh()},t.prototype.refresh=function(e){var t=this;(this._refreshAnimationFrame||(this._refreshAnimationFrame=window.requestAnimationFrame(function(){return t._refresh()})),n.isLinux&&e)&&(this.selectionText.length&&this.emit("newselection",this.selectionText))},t.prototype._refresh=function(){this._refreshAnimationFrame=null,this.emit("refresh",{start:this._model.finalSelectionStart,end:this._model.finalSelectionEnd})},t.prototype.selectAll=function(){this._model.isSelectAllActive=!0,this.refresh()},t.prototype._onTrim=function(e){this._model.onTrim(e)&&this.refresh()},t.prototype._getMouseBufferCoords=function(e){var t=s.getCoords(e,this._rowContainer,this._charMeasure,this._terminal.cols,this._terminal.rows,!0);return t?(t[0]--,t[1]--,t[1]+=this._terminal.buffer.ydisp,t):null},t.prototype._getMouseEventScrollAmount=function(e){var t=s.getCoordsRelativeToElement(e,this._rowContainer)[1],r=this._terminal.rows*this._charMeasure.height;return t>=0&&t<=r?0:(t>r&&(t-=r),t=Math.min(Math.max(t,-50),50),(t/=50)/Math.abs(t)+Math.round(14*t))},t.prototype._onMouseDown=function(e){if(2===e.button&&this.hasSelection)e.stopPropagation();else if(0===e.button){if(!this._enabled){if(!(n.isMac&&e.altKey))return;e.stopPropagation()}e.preventDefault(),this._dragScrollAmount=0,this._enabled&&e.shiftKey?this._onIncrementalClick(e):1===e.detail?this._onSingleClick(e):2===e.detail?this._onDoubleClick(e):3===e.detail&&this._onTripleClick(e),this._addMouseDownListeners(),this.refresh(!0)}},t.prototype._addMouseDownListeners=function(){var e=this;this._rowContainer.ownerDocument.addEventListener("mousemove",this._mouseMoveListener),this._rowContainer.ownerDocument.addEventListener("mouseup",this._mouseUpListener),this._dragScrollIntervalTimer=setInterval(function(){return e._dragScroll()},50)},t.prototype._removeMouseDownListeners=function(){this._rowContainer.ownerDocument.removeEventListener("mousemove",this._mouseMoveListener),this._rowContainer.ownerDocument.removeEventListener("mouseup",this._mouseUpListener),clearInterval(this._dragScrollIntervalTimer),this._dragScrollIntervalTimer=null},t.prototype._onIncrementalClick=function(e){this._model.selectionStart&&(this._model.selectionEnd=this._getMouseBufferCoords(e))},t.prototype._onSingleClick=function(e){if(this._model.selectionStartLength=0,this._model.isSelectAllActive=!1,this._activeSelectionMode=o.NORMAL,this._model.selectionStart=this._getMouseBufferCoords(e),this._model.selectionStart){this._model.selectionEnd=null;var t=this._buffer.get(this._model.selectionStart[1]);if(t)0===t[this._model.selectionStart[0]][2]&&this._model.selectionStart[0]++}},t.prototype._onDoubleClick=function(e){var t=this._getMouseBufferCoords(e);t&&(this._activeSelectionMode=o.WORD,this._selectWordAt(t))},t.prototype._onTripleClick=function(e){var t=this._getMouseBufferCoords(e);t&&(this._activeSelectionMode=o.LINE,this._selectLineAt(t[1]))},t.prototype._onMouseMove=function(e){var t=this._model.selectionEnd?[this._model.selectionEnd[0],this._model.selectionEnd[1]]:null;if(this._model.selectionEnd=this._getMouseBufferCoords(e),this._model.selectionEnd){if(this._activeSelectionMode===o.LINE?this._model.selectionEnd[1]<this._model.selectionStart[1]?this._model.selectionEnd[0]=0:this._model.selectionEnd[0]=this._terminal.cols:this._activeSelectionMode===o.WORD&&this._selectToWordAt(this._model.selectionEnd),this._dragScrollAmount=this._getMouseEventScrollAmount(e),this._dragScrollAmount>0?this._model.selectionEnd[0]=this._terminal.cols-1:this._dragScrollAmount<0&&(this._model.selectionEnd[0]=0),this._model.selectionEnd[1]<this._buffer.length){var r=this._buffer.get(this._model.selectionEnd[1])[this._model.selectionEnd[0]];r&&0===r[2]&&this._model.selectionEnd[0]++}t&&t[0]===this._model.selectionEnd[0]&&t[1]===this._model.selectionEnd[1]||this.refresh(!0)}else this.refresh(!0)},t.prototype._dragScroll=function(){this._dragScrollAmount&&(this._terminal.scrollDisp(this._dragScrollAmount,!1),this._dragScrollAmount>0?this._model.selectionEnd=[this._terminal.cols-1,this._terminal.buffer.ydisp+this._terminal.rows]:this._model.selectionEnd=[0,this._terminal.buffer.ydisp],this.refresh())},t.prototype._onMouseUp=function(e){this._removeMouseDownListeners()},t.prototype._convertViewportColToCharacterIndex=function(e,t){for(var r=t[0],i=0;t[0]>=i;i++){0===e[i][2]&&r--}return r},t.prototype.setSelection=function(e,t,r){this._model.clearSelection(),this._removeMouseDownListeners(),this._model.selectionStart=[e,t],this._model.selectionStartLength=r,this.refresh()},t.prototype._getWordAt=function(e){var t=this._buffer.get(e[1]);if(!t)return null;var r=h.translateBufferLineToString(t,!1),i=this._convertViewportColToCharacterIndex(t,e),o=i,s=e[0]-o,n=0,a=0;if(" "===r.charAt(o)){for(;o>0&&" "===r.charAt(o-1);)o--;for(;i<r.length&&" "===r.charAt(i+1);)i++}else{var l=e[0],c=e[0];for(0===t[l][2]&&(n++,l--),2===t[c][2]&&(a++,c++);o>0&&!this._isCharWordSeparator(r.charAt(o-1));)0===t[l-1][2]&&(n++,l--),o--,l--;for(;i+1<r.length&&!this._isCharWordSeparator(r.charAt(i+1));)2===t[c+1][2]&&(a++,c++),i++,c++}return{start:o+s-n,length:Math.min(i-o+n+a+1,this._terminal.cols)}},t.prototype._selectWordAt=function(e){var t=this._getWordAt(e);t&&(this._model.selectionStart=[t.start,e[1]],this._model.selectionStartLength=t.length)},t.prototype._selectToWordAt=function(e){var t=this._getWordAt(e);t&&(this._model.selectionEnd=[this._model.areSelectionValuesReversed()?t.start:t.start+t.length,e[1]])},t.prototype._isCharWordSeparator=function(e){return" ()[]{}'\"".indexOf(e)>=0},t.prototype._selectLineAt=function(e){this._model.selectionStart=[0,e],this._model.selectionStartLength=this._terminal.cols},t}(a.EventEmitter);t.SelectionManager=f},function(e,t,r){"use strict";Object.defineProperty(t,"__esModule",{value:!0});var i=function(){function e(e){this._terminal=e,this.clearSelection()}return e.prototype.clearSelection=function(){this.selectionStart=null,this.selectionEnd=null,this.isSelectAllActive=!1,this.selectionStartLength=0},Object.defineProperty(e.prototype,"finalSelectionStart",{get:function(){return this.isSelectAllActive?[0,0]:this.selectionEnd&&this.selectionStart&&this.areSelectionValuesReversed()?this.selectionEnd:this.selectionStart},enumerable:!0,configurable:!0}),Object.defineProperty(e.prototype,"finalSelectionEnd",{get:function(){return this.isSelectAllActive?[this._terminal.cols,this._terminal.buffer.ybase+this._terminal.rows-1]:this.selectionStart?!this.selectionEnd||this.areSelectionValuesReversed()?[this.selectionStart[0]+this.selectionStartLength,this.selectionStart[1]]:this.selectionStartLength&&this.selectionEnd[1]===this.selectionStart[1]?[Math.max(this.selectionStart[0]+this.selectionStartLength,this.selectionEnd[0]),this.selectionEnd[1]]:this.selectionEnd:null},enumerable:!0,configurable:!0}),e.prototype.areSelectionValuesReversed=function(){var e=this.selectionStart,t=this.selectionEnd;return e[1]>t[1]||e[1]===t[1]&&e[0]>t[0]},e.prototype.onTrim=function(e){return this.selectionStart&&(this.selectionStart[1]-=e),this.selectionEnd&&(this.selectionEnd[1]-=e),this.selectionEnd&&this.selectionEnd[1]<0?(this.clearSelection(),!0):(this.selectionStart&&this.selectionStart[1]<0&&(this.selectionStart[1]=0),!1)},e}();t.SelectionModel=i},function(e,t,r){"use strict";Object.defineProperty(t,"__esModule",{value:!0});var i=function(){function e(e,t,r,i){var o=this;this.terminal=e,this.viewportElement=t,this.scrollArea=r,this.charMeasure=i,this.currentRowHeight=0,this.lastRecordedBufferLength=0,this.lastRecordedViewportHeight=0,this.terminal.on("scroll",this.syncScrollArea.bind(this)),this.terminal.on("resize",this.syncScrollArea.bind(this)),this.viewportElement.addEventListener("scroll",this.onScroll.bind(this)),setTimeout(function(){return o.syncScrollArea()},0)}return e.prototype.refresh=function(){if(this.charMeasure.height>0){var e=this.charMeasure.height!==this.currentRowHeight;e&&(this.currentRowHeight=this.charMeasure.height,this.viewportElement.style.lineHeight=this.charMeasure.height+"px",this.terminal.rowContainer.style.lineHeight=this.charMeasure.height+"px");var t=this.lastRecordedViewportHeight!==this.terminal.rows;(e||t)&&(this.lastRecordedViewportHeight=this.terminal.rows,this.viewportElement.style.height=this.charMeasure.height*this.terminal.rows+"px",this.terminal.selectionContainer.style.height=this.viewportElement.style.height),this.scrollArea.style.height=this.charMeasure.height*this.lastRecordedBufferLength+"px"}},e.prototype.syncScrollArea=function(){this.lastRecordedBufferLength!==this.terminal.buffer.lines.length?(this.lastRecordedBufferLength=this.terminal.buffer.lines.length,this.refresh()):this.lastRecordedViewportHeight!==this.terminal.rows?this.refresh():this.charMeasure.height!==this.currentRowHeight&&this.refresh();var e=this.terminal.buffer.ydisp*this.currentRowHeight;this.viewportElement.scrollTop!==e&&(this.viewportElement.scrollTop=e)},e.prototype.onScroll=function(e){var t=Math.round(this.viewportElement.scrollTop/this.currentRowHeight)-this.terminal.buffer.ydisp;this.terminal.scrollDisp(t,!0)},e.prototype.onWheel=function(e){if(0!==e.deltaY){var t=1;e.deltaMode===WheelEvent.DOM_DELTA_LINE?t=this.currentRowHeight:e.deltaMode===WheelEvent.DOM_DELTA_PAGE&&(t=this.currentRowHeight*this.terminal.rows),this.viewportElement.scrollTop+=e.deltaY*t,e.preventDefault()}},e.prototype.onTouchStart=function(e){this.lastTouchY=e.touches[0].pageY},e.prototype.onTouchMove=function(e){var t=this.lastTouchY-e.touches[0].pageY;this.lastTouchY=e.touches[0].pageY,0!==t&&(this.viewportElement.scrollTop+=t,e.preventDefault())},e}();t.Viewport=i},function(e,t,r){"use strict";function i(e,t){return t?e.replace(/\r?\n/g,"\r"):e}function o(e,t){t.style.position="fixed",t.style.width="20px",t.style.height="20px",t.style.left=e.clientX-10+"px",t.style.top=e.clientY-10+"px",t.style.zIndex="1000",t.focus(),setTimeout(function(){t.style.position=null,t.style.width=null,t.style.height=null,t.style.left=null,t.style.top=null,t.style.zIndex=null},4)}Object.defineProperty(t,"__esModule",{value:!0}),t.prepareTextForTerminal=i,t.copyHandler=function(e,t,r){t.browser.isMSIE?window.clipboardData.setData("Text",r.selectionText):e.clipboardData.setData("text/plain",r.selectionText),e.preventDefault()},t.pasteHandler=function(e,t){e.stopPropagation();var r=function(r){return r=i(r,t.browser.isMSWindows),t.handler(r),t.textarea.value="",t.emit("paste",r),t.cancel(e)};t.browser.isMSIE?window.clipboardData&&r(window.clipboardData.getData("Text")):e.clipboardData&&r(e.clipboardData.getData("text/plain"))},t.moveTextAreaUnderMouseCursor=o,t.rightClickHandler=function(e,t,r){o(e,t),t.value=r.selectionText,t.select()}},function(e,t,r){"use strict";var i=this&&this.__extends||function(){var e=Object.setPrototypeOf||{__proto__:[]}instanceof Array&&function(e,t){e.__proto__=t}||function(e,t){for(var r in t)t.hasOwnProperty(r)&&(e[r]=t[r])};return function(t,r){function i(){this.constructor=t}e(t,r),t.prototype=null===r?Object.create(r):(i.prototype=r.prototype,new i)}}();Object.defineProperty(t,"__esModule",{value:!0});var o=function(e){function t(t,r){var i=e.call(this)||this;return i._document=t,i._parentElement=r,i}return i(t,e),Object.defineProperty(t.prototype,"width",{get:function(){return this._width},enumerable:!0,configurable:!0}),Object.defineProperty(t.prototype,"height",{get:function(){return this._height},enumerable:!0,configurable:!0}),t.prototype.measure=function(){var e=this;this._measureElement?this._doMeasure():(this._measureElement=this._document.createElement("span"),this._measureElement.style.position="absolute",this._measureElement.style.top="0",this._measureElement.style.left="-9999em",this._measureElement.textContent="W",this._measureElement.setAttribute("aria-hidden","true"),this._parentElement.appendChild(this._measureElement),setTimeout(function(){return e._doMeasure()},0))},t.prototype._doMeasure=function(){var e=this._measureElement.getBoundingClientRect();0!==e.width&&0!==e.height&&(this._width===e.width&&this._height===e.height||(this._width=e.width,this._height=e.height,this.emit("charsizechanged")))},t}(r(1).EventEmitter);t.CharMeasure=o},function(e,t,r){"use strict";var i=this&&this.__extends||function(){var e=Object.setPrototypeOf||{__proto__:[]}instanceof Array&&function(e,t){e.__proto__=t}||function(e,t){for(var r in t)t.hasOwnProperty(r)&&(e[r]=t[r])};return function(t,r){function i(){this.constructor=t}e(t,r),t.prototype=null===r?Object.create(r):(i.prototype=r.prototype,new i)}}();Object.defineProperty(t,"__esModule",{value:!0});var o=function(e){function t(t){var r=e.call(this)||this;return r._array=new Array(t),r._startIndex=0,r._length=0,r}return i(t,e),Object.defineProperty(t.prototype,"maxLength",{get:function(){return this._array.length},set:function(e){for(var t=new Array(e),r=0;r<Math.min(e,this.length);r++)t[r]=this._array[this._getCyclicIndex(r)];this._array=t,this._startIndex=0},enumerable:!0,configurable:!0}),Object.defineProperty(t.prototype,"length",{get:function(){return this._length},set:function(e){if(e>this._length)for(var t=this._length;t<e;t++)this._array[t]=void 0;this._length=e},enumerable:!0,configurable:!0}),Object.defineProperty(t.prototype,"forEach",{get:function(){var e=this;return function(t){for(var r=e.length,i=0;i<r;i++)t(e.get(i),i)}},enumerable:!0,configurable:!0}),t.prototype.get=function(e){return this._array[this._getCyclicIndex(e)]},t.prototype.set=function(e,t){this._array[this._getCyclicIndex(e)]=t},t.prototype.push=function(e){this._array[this._getCyclicIndex(this._length)]=e,this._length===this.maxLength?(this._startIndex++,this._startIndex===this.maxLength&&(this._startIndex=0),this.emit("trim",1)):this._length++},t.prototype.pop=function(){return this._array[this._getCyclicIndex(this._length---1)]},t.prototype.splice=function(e,t){for(var r=[],i=2;i<arguments.length;i++)r[i-2]=arguments[i];if(t){for(var o=e;o<this._length-t;o++)this._array[this._getCyclicIndex(o)]=this._array[this._getCyclicIndex(o+t)];this._length-=t}if(r&&r.length){for(o=this._length-1;o>=e;o--)this._array[this._getCyclicIndex(o+r.length)]=this._array[this._getCyclicIndex(o)];for(o=0;o<r.length;o++)this._array[this._getCyclicIndex(e+o)]=r[o];if(this._length+r.length>this.maxLength){var s=this._length+r.length-this.maxLength;this._startIndex+=s,this._length=this.maxLength,this.emit("trim",s)}else this._length+=r.length}},t.prototype.trimStart=function(e){e>this._length&&(e=this._length),this._startIndex+=e,this._length-=e,this.emit("trim",e)},t.prototype.shiftElements=function(e,t,r){if(!(t<=0)){if(e<0||e>=this._length)throw new Error("start argument out of range");if(e+r<0)throw new Error("Cannot shift elements in list beyond index 0");if(r>0){for(var i=t-1;i>=0;i--)this.set(e+i+r,this.get(e+i));var o=e+t+r-this._length;if(o>0)for(this._length+=o;this._length>this.maxLength;)this._length--,this._startIndex++,this.emit("trim",1)}else for(i=0;i<t;i++)this.set(e+i+r,this.get(e+i))}},t.prototype._getCyclicIndex=function(e){return(this._startIndex+e)%this.maxLength},t}(r(1).EventEmitter);t.CircularList=o},function(e,t,r){"use strict";Object.defineProperty(t,"__esModule",{value:!0});var i=function(){function e(e){this.type=e,this._type=e,this._pool=[],this._inUse={}}return e.prototype.acquire=function(){var t;return t=0===this._pool.length?this._createNew():this._pool.pop(),this._inUse[t.getAttribute(e.OBJECT_ID_ATTRIBUTE)]=t,t},e.prototype.release=function(t){if(!this._inUse[t.getAttribute(e.OBJECT_ID_ATTRIBUTE)])throw new Error("Could not release an element not yet acquired");delete this._inUse[t.getAttribute(e.OBJECT_ID_ATTRIBUTE)],this._cleanElement(t),this._pool.push(t)},e.prototype._createNew=function(){var t=document.createElement(this._type),r=e._objectCount++;return t.setAttribute(e.OBJECT_ID_ATTRIBUTE,r.toString(10)),t},e.prototype._cleanElement=function(e){e.className="",e.innerHTML=""},e}();i.OBJECT_ID_ATTRIBUTE="data-obj-id",i._objectCount=0,t.DomElementObjectPool=i},function(e,t,r){"use strict";Object.defineProperty(t,"__esModule",{value:!0}),t.contains=function(e,t){return e.indexOf(t)>=0}},function(module,exports,__webpack_require__){"use strict";var require=function(n){return __webpack_require__({xterm:0,libapps:4}[n])};(function(){"use strict";var __create=Object.create,__defProp=Object.defineProperty,__getOwnPropDesc=Object.getOwnPropertyDescriptor,__getOwnPropNames=Object.getOwnPropertyNames,__getProtoOf=Object.getPrototypeOf,__hasOwnProp=Object.prototype.hasOwnProperty,__copyProps=(e,t,s,i)=>{if(t&&typeof t=="object"||typeof t=="function")for(let r of __getOwnPropNames(t))!__hasOwnProp.call(e,r)&&r!==s&&__defProp(e,r,{get:()=>t[r],enumerable:!(i=__getOwnPropDesc(t,r))||i.enumerable});return e},__toESM=(e,t,s)=>(s=e!=null?__create(__getProtoOf(e)):{},__copyProps(t||!e||!e.__esModule?__defProp(s,"default",{value:e,enumerable:!0}):s,e)),bare=require("libapps"),Hterm=class{constructor(e){this.elem=e,bare.hterm.defaultStorage=new bare.lib.Storage.Memory,this.term=new bare.hterm.Terminal,this.term.getPrefs().set("send-encoding","raw"),this.term.decorate(this.elem),this.io=this.term.io.push(),this.term.installKeyboard()}info(){return{columns:this.columns,rows:this.rows}}output(e){this.term.io!=null&&this.term.io.writeUTF8(e)}showMessage(e,t){this.message=e,t>0?this.term.io.showOverlay(e,t):this.term.io.showOverlay(e,null)}removeMessage(){this.term.io.showOverlay(this.message,0)}setWindowTitle(e){this.term.setWindowTitle(e)}setPreferences(e){Object.keys(e).forEach(t=>{this.term.getPrefs().set(t,e[t])})}onInput(e){this.io.onVTKeystroke=t=>{e(t)},this.io.sendString=t=>{e(t)}}onResize(e){this.io.onTerminalResize=(t,s)=>{this.columns=t,this.rows=s,e(t,s)}}resize(e,t){this.term.setWidth(e),this.term.setHeight(t)}deactivate(){this.io.onVTKeystroke=function(){},this.io.sendString=function(){},this.io.onTerminalResize=function(){},this.term.uninstallKeyboard()}reset(){this.removeMessage(),this.term.installKeyboard()}close(){this.term.uninstallKeyboard()}},bare2=require("xterm"),import_libapps=require("libapps");bare2.loadAddon("fit");var Xterm=class{constructor(e){this.elem=e,this.term=new bare2,this.message=e.ownerDocument.createElement("div"),this.message.className="xterm-overlay",this.messageTimeout=2e3,this.resizeListener=()=>{this.term.fit(),this.term.scrollToBottom(),this.showMessage(String(this.term.cols)+"x"+String(this.term.rows),this.messageTimeout)},this.term.on("open",()=>{this.resizeListener(),window.addEventListener("resize",()=>{this.resizeListener()})}),this.term.open(e,!0),this.decoder=new import_libapps.lib.UTF8Decoder}info(){return{columns:this.term.cols,rows:this.term.rows}}output(e){this.term.write(this.decoder.decode(e))}showMessage(e,t){this.message.textContent=e,this.elem.appendChild(this.message),this.messageTimer&&clearTimeout(this.messageTimer),t>0&&(this.messageTimer=setTimeout(()=>{this.elem.removeChild(this.message)},t))}removeMessage(){this.message.parentNode==this.elem&&this.elem.removeChild(this.message)}setWindowTitle(e){document.title=e}setPreferences(e){}onInput(e){this.term.on("data",t=>{e(t)})}onResize(e){this.term.on("resize",t=>{e(t.cols,t.rows)})}resize(e,t){this.term.resize(e,t)}deactivate(){this.term.off("data"),this.term.off("resize"),this.term.blur()}reset(){this.removeMessage(),this.term.clear()}close(){window.removeEventListener("resize",this.resizeListener),this.term.destroy()}},protocols=["webtty"],msgInput="1",msgPing="2",msgResizeTerminal="3",msgSetFlowWindow="4",msgAck="5",msgInputBinary="6",msgTransfer="7",msgOutput="1",msgPong="2",msgSetWindowTitle="3",msgSetPreferences="4",msgSetReconnect="5",msgStderrOutput="6",msgExited="7",msgOutputTruncated="8",msgSetResumeToken="9",msgNotice="A",msgSetMaxInput="B",msgError="C",msgSetClipboard="D",flowWindow=4*1024*1024,ackInterval=flowWindow/4,pingInterval=5*1e3,inputPacing=10,defaultMaxMessage=1024,permanentErrors=["container_gone","exec_denied","unauthorized","bad_request","no_shell"],WebTTY=class{constructor(e,t,s,i){this.term=e,this.connectionFactory=t,this.args=s,this.authToken=i,this.reconnect=-1,this.resumeToken="",this.interceptor=null,this.writer=null,this.binaryWriter=null,this.exitCode=null,this.error=null}onStderr(e){this.stderrHandler=e}onOutput(e){this.outputHandler=e}onClose(e){this.closeHandler=e}onLatency(e){this.latencyHandler=e}onNotice(e){this.noticeHandler=e}onError(e){this.errorHandler=e}onClipboard(e){this.clipboardHandler=e}intercept(e){this.interceptor=e}write(e){this.writer&&this.writer(e)}writeBinary(e){this.binaryWriter&&this.binaryWriter(e)}open(){let e=this.connectionFactory.create(),t,s,i=0,r=0,n=0,h=defaultMaxMessage,a=[],d=0,u=!1;const o=()=>{const l=this.interceptor!==null&&this.interceptor.active();l!=u&&e.isOpen()&&(u=l,e.send(msgTransfer+JSON.stringify({active:l})))},p=()=>{d=0;const l=a.shift();if(l===void 0||!e.isOpen()){a=[];return}e.send(l),a.length>0&&(d=setTimeout(p,inputPacing))},m=l=>{for(;n>0&&l.length>n;){let c=n;const f=l.charCodeAt(c-1);c>1&&f>=55296&&f<=56319&&c--,a.push(msgInput+l.slice(0,c)),l=l.slice(c)}a.push(msgInput+l),d==0&&p()},v=l=>{const c=Math.max(3,Math.floor((h-1)/4)*3);for(let f=0;f<l.length;f+=c)a.push(msgInputBinary+btoa(l.slice(f,f+c)));d==0&&p()},g=l=>{const c=this.interceptor;c===null||!c.active()?m(l):l.indexOf("")>=0&&(c.cancel(),o())},b=()=>{r==0&&(r=performance.now()),e.send(msgPing)},S=l=>{i+=l,i>=ackInterval&&(e.send(msgAck+i),i=0)},O=()=>{e.onOpen(()=>{const l=this.term.info();this.exitCode=null,this.error=null,e.send(JSON.stringify({Arguments:this.args,AuthToken:this.authToken,ResumeToken:this.resumeToken}));const c=(f,w)=>{e.send(msgResizeTerminal+JSON.stringify({columns:f,rows:w}))};i=0,u=!1,e.send(msgSetFlowWindow+JSON.stringify({window:flowWindow})),this.term.onResize(c),c(l.columns,l.rows),n=0,h=defaultMaxMessage,this.writer=g,this.binaryWriter=v,this.term.onInput(this.writer),r=0,b(),t=setInterval(b,pingInterval)}),e.onReceive(l=>{const c=l.slice(1);switch(l[0]){case msgOutput:let f=atob(c);S(f.length),this.interceptor!==null&&(f=this.interceptor.consume(f),o()),f.length>0&&(this.term.output(f),this.outputHandler&&this.outputHandler(f));break;case msgStderrOutput:const w=atob(c);this.term.output("\x1B[31m"+w+"\x1B[0m"),this.stderrHandler&&this.stderrHandler(w),S(w.length);break;case msgOutputTruncated:const A=JSON.parse(c);this.term.output(`\r
+\x1B[33m[output truncated: `+A.dropped+` bytes dropped]\x1B[0m\r
+`);break;case msgPong:r!=0&&this.latencyHandler&&this.latencyHandler(Math.round(performance.now()-r)),r=0;break;case msgSetWindowTitle:this.term.setWindowTitle(c);break;case msgSetPreferences:const H=JSON.parse(c);this.term.setPreferences(H);break;case msgExited:const T=JSON.parse(c);this.exitCode=T.code,this.term.output(`\r
+\x1B[1mprocess exited with code `+T.code+`\x1B[0m\r
+`);break;case msgSetReconnect:const x=JSON.parse(c);console.log("Enabling reconnect: "+x+" seconds"),this.reconnect=x;break;case msgSetResumeToken:this.resumeToken=c;break;case msgSetMaxInput:h=JSON.parse(c).bytes,n=Math.max(1,Math.floor(h/3));break;case msgError:const y=JSON.parse(c);this.error=y,this.term.output(`\r
+\x1B[31m[`+y.message+" ("+y.code+(y.request_id?", request "+y.request_id:"")+`)]\x1B[0m\r
+`),this.errorHandler&&this.errorHandler(y);break;case msgSetClipboard:const E=JSON.parse(c);let C=atob(E.data);try{C=decodeURIComponent(escape(C))}catch(z){}this.clipboardHandler&&this.clipboardHandler(C,E.ask);break;case msgNotice:const k=JSON.parse(c);this.noticeHandler?this.noticeHandler(k.message,k.seconds):this.term.output(`\r
+\x1B[33m[`+k.message+`]\x1B[0m\r
+`);break}}),e.onClose(()=>{clearInterval(t),clearTimeout(d),d=0,a=[],this.writer=null,this.binaryWriter=null,this.interceptor!==null&&this.interceptor.active()&&this.interceptor.cancel(),this.latencyHandler&&this.latencyHandler(null),this.closeHandler&&this.closeHandler(this.exitCode),this.term.deactivate(),this.term.showMessage(this.error?this.error.message:"Connection Closed",0);const l=this.error!==null&&permanentErrors.indexOf(this.error.code)>=0;this.reconnect>0&&this.exitCode===null&&!l&&(s=setTimeout(()=>{e=this.connectionFactory.create(),this.term.reset(),O()},this.reconnect*1e3))}),e.open()};return O(),()=>{clearTimeout(s),e.close()}}},ConnectionFactory=class{constructor(e,t){this.url=e,this.protocols=t}create(){return new Connection(this.url,this.protocols)}},Connection=class{constructor(e,t){this.bare=new WebSocket(e,t)}open(){}close(){this.bare.close()}send(e){this.bare.send(e)}isOpen(){return this.bare.readyState==WebSocket.CONNECTING||this.bare.readyState==WebSocket.OPEN}onOpen(e){this.bare.onopen=t=>{e()}}onReceive(e){this.bare.onmessage=t=>{e(t.data)}}onClose(e){this.bare.onclose=t=>{e()}}},SSEConnectionFactory=class{constructor(e){this.url=e}create(){return new SSEConnection(this.url)}},SSEConnection=class{constructor(e){this.url=e,this.session="",this.pending=[],this.sending=!1,this.closed=!1}open(){this.bare=new EventSource(this.url),this.bare.addEventListener("session",e=>{this.session=e.data,this.openCallback&&this.openCallback()}),this.bare.onmessage=e=>{this.receiveCallback&&this.receiveCallback(e.data)},this.bare.onerror=()=>{this.close()}}close(){this.closed||(this.closed=!0,this.bare&&this.bare.close(),this.closeCallback&&this.closeCallback())}send(e){this.closed||(this.pending.push(e),this.flush())}flush(){if(this.sending||this.pending.length==0||this.session=="")return;const e=this.pending;this.pending=[],this.sending=!0;const t=new XMLHttpRequest;t.open("POST",this.url+"/"+this.session),t.setRequestHeader("Content-Type","application/json"),t.onload=()=>{if(this.sending=!1,t.status>=300){this.close();return}this.flush()},t.onerror=()=>{this.sending=!1,this.close()},t.send(JSON.stringify(e))}isOpen(){return this.closed||!this.bare?!1:this.bare.readyState!=EventSource.CLOSED}onOpen(e){this.openCallback=e}onReceive(e){this.receiveCallback=e}onClose(e){this.closeCallback=e}},FallbackConnectionFactory=class{constructor(e,t){this.primary=e,this.fallback=t,this.useFallback=!1}create(){return this.useFallback?this.fallback.create():new FallbackConnection(this)}},FallbackConnection=class{constructor(e){this.factory=e,this.opened=!1,this.current=e.primary.create(),this.bind()}bind(){this.current.onOpen(()=>{this.opened=!0,this.openCallback&&this.openCallback()}),this.current.onReceive(e=>{this.receiveCallback&&this.receiveCallback(e)}),this.current.onClose(()=>{if(!this.opened&&!this.factory.useFallback){console.log("Websocket unavailable, falling back to Server-Sent Events"),this.factory.useFallback=!0,this.current=this.factory.fallback.create(),this.bind(),this.current.open();return}this.closeCallback&&this.closeCallback()})}open(){this.current.open()}close(){this.current.close()}send(e){this.current.send(e)}isOpen(){return this.current.isOpen()}onOpen(e){this.openCallback=e}onReceive(e){this.receiveCallback=e}onClose(e){this.closeCallback=e}},Embed=class{constructor(e,t,s){this.origin=new RegExp(s),this.target="",window.addEventListener("message",i=>{if(i.source!==window.parent||!this.origin.test(i.origin))return;const r=i.data;switch(r.type){case"attach":this.target=i.origin;break;case"write":e.write(String(r.data));break;case"resize":t.resize(Number(r.columns),Number(r.rows));break}}),e.onOutput(i=>{this.post({type:"data",data:decodeUTF8(i)})}),e.onError(i=>{this.post({type:"error",code:i.code,message:i.message})}),e.onClose(i=>{this.post({type:"close",code:i})}),window.parent.postMessage({type:"ready"},"*")}post(e){this.target!=""&&window.parent.postMessage(e,this.target)}};function decodeUTF8(e){try{return decodeURIComponent(escape(e))}catch(t){return e}}var ZPAD=42,ZDLE=24,ZBIN=65,ZHEX=66,ZBIN32=67,ZRQINIT=0,ZRINIT=1,ZSINIT=2,ZACK=3,ZFILE=4,ZSKIP=5,ZNAK=6,ZABORT=7,ZFIN=8,ZRPOS=9,ZDATA=10,ZEOF=11,ZFERR=12,ZCRCE=104,ZCRCG=105,ZCRCQ=106,ZCRCW=107,CANFDX=1,CANOVIO=2,CANFC32=32,marker="B0",cancelSequence="\b\b\b\b\b\b\b\b",subpacketSize=1024,windowSize=64*1024,idleTimeout=60*1e3,crc16Table=[],crc32Table=[];for(let e=0;e<256;e++){let t=e<<8;for(let s=0;s<8;s++)t=t&32768?t<<1^4129:t<<1;crc16Table.push(t&65535),t=e;for(let s=0;s<8;s++)t=t&1?3988292384^t>>>1:t>>>1;crc32Table.push(t>>>0)}function crc16(e){let t=0;for(let s=0;s<e.length;s++)t=t<<8&65535^crc16Table[(t>>8^e.charCodeAt(s))&255];return t}function crc32(e){let t=4294967295;for(let s=0;s<e.length;s++)t=crc32Table[(t^e.charCodeAt(s))&255]^t>>>8;return(t^4294967295)>>>0}function hex2(e){return("0"+e.toString(16)).slice(-2)}function le32(e){return String.fromCharCode(e&255,e>>>8&255,e>>>16&255,e>>>24&255)}function posHeader(e,t){return{type:e,p:[t&255,t>>>8&255,t>>>16&255,t>>>24&255]}}function position(e){return e.p[0]+e.p[1]*256+e.p[2]*65536+e.p[3]*16777216}function headerBytes(e){return String.fromCharCode(e.type,e.p[0],e.p[1],e.p[2],e.p[3])}function escape2(e){let t="";for(let s=0;s<e.length;s++){const i=e.charCodeAt(s);switch(i&127){case 16:case 17:case 19:case 24:case 13:t+=String.fromCharCode(ZDLE,i^64);break;default:t+=e.charAt(s)}}return t}function hexHeader(e){const t=headerBytes(e),s=crc16(t);let i="**B";for(let r=0;r<t.length;r++)i+=hex2(t.charCodeAt(r));return i+=hex2(s>>8)+hex2(s&255)+"\r\x8A",e.type==ZACK||e.type==ZFIN?i:i+""}function binHeader(e,t){const s=headerBytes(e);if(t)return"*C"+escape2(s+le32(crc32(s)));const i=crc16(s);return"*A"+escape2(s+String.fromCharCode(i>>8,i&255))}function subpacket(e,t,s){const i=e+String.fromCharCode(t);let r;if(s)r=le32(crc32(i));else{const n=crc16(i);r=String.fromCharCode(n>>8,n&255)}return escape2(e)+String.fromCharCode(ZDLE,t)+escape2(r)}function decode(e,t,s){const i=[];let r=t;for(;s==0||i.length<s;){if(r>=e.length)return null;let n=e.charCodeAt(r++);if(!((n&127)==17||(n&127)==19)){if(n==ZDLE){if(r>=e.length)return null;if(n=e.charCodeAt(r++),n>=ZCRCE&&n<=ZCRCW)return s!=0?{data:i.join(""),end:r,frameEnd:-1}:{data:i.join(""),end:r,frameEnd:n};n=n==108?127:n==109?255:n^64}i.push(String.fromCharCode(n))}}return{data:i.join(""),end:r,frameEnd:-1}}function hexValue(e){const t=[];for(let s=0;s<e.length;s+=2){const i=parseInt(e.substr(s,2),16);if(isNaN(i))return null;t.push(i)}return t}var Zmodem=class{constructor(e,t,s){this.elem=e,this.writer=t,this.output=s,this.carry="",this.pad=!1,this.buf=null,this.files=[],this.readGen=0;const i=e.ownerDocument;this.dialog=i.createElement("div"),this.dialog.className="zmodem",this.status=i.createElement("span"),this.chooser=i.createElement("input"),this.chooser.type="file",this.chooser.multiple=!0,this.chooser.onchange=()=>{this.sendFiles(this.chooser.files)};const r=i.createElement("button");r.textContent="cancel",r.onclick=()=>{this.cancel()},this.dialog.appendChild(this.status),this.dialog.appendChild(this.chooser),this.dialog.appendChild(r)}active(){return this.buf!==null}consume(e){if(this.buf!==null)return this.feed(e);const t=this.carry+e;this.carry="";for(let s=0;;){const i=t.indexOf(marker,s);if(i<0){let a=Math.min(marker.length,t.length);for(;a>0&&marker.indexOf(t.slice(t.length-a))!=0;)a--;this.carry=t.slice(t.length-a);const d=t.slice(0,t.length-a);return d.length>0&&(this.pad=d.charCodeAt(d.length-1)==ZPAD),d}if(i+marker.length>=t.length)return this.carry=t.slice(i),t.slice(0,i);const r=i>0?t.charCodeAt(i-1)==ZPAD:this.pad,n=t.charAt(i+marker.length);if(!r||n!="0"&&n!="1"){s=i+1;continue}let h=t.slice(0,i).replace(/\*+$/,"");return h=h.replace(/rz\r$/,""),this.start(n=="1"),h+this.feed("*"+t.slice(i))}}cancel(){this.buf!==null&&(this.writer(cancelSequence),this.finish("canceled"))}start(e){this.buf="",this.sending=e,this.use32=!1,this.dataHeader=null,this.finishing=!1,this.received=[],this.file=null,this.files=[],this.readGen++,this.chooser.value="",this.chooser.style.display="none",this.setStatus(e?"rz is waiting for the files":"sz is sending the files"),this.elem.appendChild(this.dialog)}finish(e){clearTimeout(this.idleTimer);const t=this.buf;this.buf=null,this.readGen++,this.dialog.parentNode==this.elem&&this.elem.removeChild(this.dialog),this.output(`\r
+\x1B[33m[zmodem: `+e+`]\x1B[0m\r
+`),t&&this.output(t)}setStatus(e){this.status.textContent=e}progress(){const e=this.file;if(e!==null){const t=e.size>0?Math.floor(e.done*100/e.size):100;this.setStatus((this.sending?"sending ":"receiving ")+e.name+" "+t+"%")}}feed(e){if(this.buf===null)return e;clearTimeout(this.idleTimer),this.idleTimer=setTimeout(()=>{this.cancel()},idleTimeout),this.buf+=e;const t=this.buf.indexOf("");if(t>=0)return this.buf=this.buf.slice(t).replace(/^[\x18\b]+/,""),this.finish("canceled by the other side"),"";for(;this.buf!==null;){if(this.finishing){if(this.buf=this.buf.replace(/^[\r\n\x8a\x11]+/,""),this.buf.length<2&&!this.sending)break;this.buf.slice(0,2)=="OO"&&(this.buf=this.buf.slice(2)),this.finish(this.sending?"sent":"received");break}if(this.dataHeader!==null){if(!this.readSubpacket())break}else if(!this.readHeader())break}return""}readHeader(){const e=this.buf,t=e.indexOf("*");if(t<0)return this.buf="",!1;let s=t;for(;s<e.length&&e.charCodeAt(s)==ZPAD;)s++;if(s+1>=e.length)return this.buf=e.slice(t),!1;if(e.charCodeAt(s)!=ZDLE)return this.buf=e.slice(s),!0;const i=e.charCodeAt(s+1);s+=2;let r=null;switch(i){case ZHEX:if(s+14>e.length)return this.buf=e.slice(t),!1;const n=hexValue(e.substr(s,14));s+=14,n!==null&&crc16(String.fromCharCode.apply(null,n.slice(0,5)))==(n[5]<<8|n[6])&&(r={type:n[0],p:n.slice(1,5)});break;case ZBIN:case ZBIN32:const h=i==ZBIN32,a=decode(e,s,h?9:7);if(a===null)return this.buf=e.slice(t),!1;s=a.end;const d=a.data;(h?le32(crc32(d.slice(0,5)))==d.slice(5):crc16(d.slice(0,5))==(d.charCodeAt(5)<<8|d.charCodeAt(6)))&&(r={type:d.charCodeAt(0),p:[1,2,3,4].map(o=>d.charCodeAt(o))},this.use32=h);break}if(i==ZHEX)for(let n=0;n<3&&s<e.length&&/[\r\n\x8a\x11]/.test(e.charAt(s));n++)s++;return this.buf=e.slice(s),r===null?(!this.sending&&(i==ZHEX||i==ZBIN||i==ZBIN32)&&this.send(hexHeader(posHeader(ZNAK,0))),!0):(this.sending?this.senderHeader(r):this.receiverHeader(r),!0)}readSubpacket(){const e=this.buf,t=decode(e,0,0);if(t===null)return!1;const s=decode(e,t.end,this.use32?4:2);if(s===null)return!1;this.buf=e.slice(s.end);const i=t.data+String.fromCharCode(t.frameEnd),r=this.use32?le32(crc32(i))==s.data:crc16(i)==(s.data.charCodeAt(0)<<8|s.data.charCodeAt(1));return this.subpacket(t.data,t.frameEnd,r),!0}send(e){this.writer(e)}receiverHeader(e){switch(e.type){case ZRQINIT:this.send(hexHeader({type:ZRINIT,p:[0,0,0,CANFDX|CANOVIO|CANFC32]}));break;case ZSINIT:case ZFILE:case ZDATA:if(e.type==ZDATA&&(this.file===null||position(e)!=this.file.done)){this.send(hexHeader(posHeader(ZRPOS,this.file===null?0:this.file.done)));break}this.dataHeader=e;break;case ZEOF:const t=this.file;if(t===null||position(e)!=t.done)break;this.save(t.name,this.received.join("")),this.file=null,this.received=[],this.send(hexHeader({type:ZRINIT,p:[0,0,0,CANFDX|CANOVIO|CANFC32]}));break;case ZFIN:this.send(hexHeader(posHeader(ZFIN,0))),this.finishing=!0;break;case ZABORT:case ZFERR:this.send(hexHeader(posHeader(ZFIN,0))),this.finish("aborted by sz");break}}subpacket(e,t,s){const i=this.dataHeader;if((t==ZCRCE||t==ZCRCW)&&(this.dataHeader=null),!s){this.dataHeader=null,i.type==ZDATA&&this.file!==null?this.send(hexHeader(posHeader(ZRPOS,this.file.done))):this.send(hexHeader(posHeader(ZNAK,0)));return}switch(i.type){case ZSINIT:this.send(hexHeader(posHeader(ZACK,0)));break;case ZFILE:const r=e.indexOf("\0"),n=r<0?e:e.slice(0,r),h=parseInt(e.slice(r+1),10);this.file={name:n.replace(/^.*\//,""),size:isNaN(h)?0:h,done:0},this.received=[],this.progress(),this.send(hexHeader(posHeader(ZRPOS,0)));break;case ZDATA:const a=this.file;this.received.push(e),a.done+=e.length,this.progress(),(t==ZCRCQ||t==ZCRCW)&&this.send(hexHeader(posHeader(ZACK,a.done)));break}}save(e,t){const s=new Uint8Array(t.length);for(let n=0;n<t.length;n++)s[n]=t.charCodeAt(n);const i=this.elem.ownerDocument,r=i.createElement("a");r.download=e,r.href=URL.createObjectURL(new Blob([s],{type:"application/octet-stream"})),i.body.appendChild(r),r.click(),i.body.removeChild(r),setTimeout(()=>{URL.revokeObjectURL(r.href)},60*1e3)}senderHeader(e){switch(e.type){case ZRINIT:this.use32=(e.p[3]&CANFC32)!=0,this.file!==null?(this.file=null,this.files.shift(),this.sendFile()):this.files.length==0&&this.chooser.style.display=="none"&&(this.chooser.style.display="",this.setStatus("rz is waiting for the files"));break;case ZSKIP:this.file=null,this.files.shift(),this.sendFile();break;case ZRPOS:case ZACK:this.file!==null&&this.sendData(position(e));break;case ZNAK:this.file!==null&&this.file.done==0&&(this.file=null,this.sendFile());break;case ZFIN:this.send("OO"),this.finishing=!0;break;case ZABORT:case ZFERR:this.finish("aborted by rz");break}}sendFiles(e){if(!(this.buf===null||!this.sending||e===null)){if(e.length==0){this.cancel();return}for(let t=0;t<e.length;t++)this.files.push(e[t]);this.chooser.style.display="none",this.sendFile()}}sendFile(){if(this.files.length==0){this.setStatus("finishing"),this.send(hexHeader(posHeader(ZFIN,0)));return}const e=this.files[0];let t=0;for(let r=0;r<this.files.length;r++)t+=this.files[r].size;this.file={name:e.name,size:e.size,done:0},this.progress();const s=Math.floor((e.lastModified||Date.now())/1e3),i=e.name+"\0"+e.size+" "+s.toString(8)+" 100644 0 "+this.files.length+" "+t+"\0";this.send(binHeader(posHeader(ZFILE,0),this.use32)+subpacket(i,ZCRCW,this.use32))}sendData(e){const t=this.files[0],s=this.file,i=++this.readGen,r=new FileReader;r.onload=()=>{if(i!=this.readGen||this.buf===null)return;const n=new Uint8Array(r.result),h=[];for(let u=0;u<n.length;u+=subpacketSize)h.push(String.fromCharCode.apply(null,n.subarray(u,u+subpacketSize)));const a=e+n.length>=t.size;let d=binHeader(posHeader(ZDATA,e),this.use32);h.length==0&&h.push("");for(let u=0;u<h.length;u++){const o=u<h.length-1?ZCRCG:a?ZCRCE:ZCRCW;d+=subpacket(h[u],o,this.use32)}s.done=e+n.length,a&&(d+=binHeader(posHeader(ZEOF,s.done),this.use32)),this.send(d),this.progress()},r.onerror=()=>{this.cancel()},r.readAsArrayBuffer(t.slice(e,e+windowSize))}},elem=document.getElementById("terminal");if(elem!==null){gotty_term=="hterm"?term=new Hterm(elem):term=new Xterm(elem);const t=(window.location.protocol=="https:"?"wss://":"ws://")+window.location.host+window.location.pathname+"ws",s=window.location.search,i=window.location.protocol+"//"+window.location.host+window.location.pathname+"sse",r=new FallbackConnectionFactory(new ConnectionFactory(t,protocols),new SSEConnectionFactory(i)),n=new WebTTY(term,r,s,gotty_auth_token),h=document.getElementById("stderr");if(h!==null){let o="";n.onStderr(p=>{o+=p,h.style.display="block"}),h.onclick=()=>{const p=new Uint8Array(o.length);for(let m=0;m<o.length;m++)p[m]=o.charCodeAt(m);h.setAttribute("href",URL.createObjectURL(new Blob([p],{type:"text/plain"})))}}const a=document.getElementById("latency");a!==null&&n.onLatency(o=>{if(o===null){a.textContent="offline",a.className="offline";return}a.textContent=o+" ms",a.className=o<100?"good":o<300?"fair":"poor"});const d=document.getElementById("notice");if(d!==null){let o;n.onNotice((p,m)=>{clearInterval(o);const v=Date.now()+m*1e3,g=()=>{const b=Math.max(0,Math.round((v-Date.now())/1e3));d.textContent=m>0?p+" in "+b+"s":p,b==0&&clearInterval(o)};g(),m>0&&(o=setInterval(g,1e3))})}n.onClipboard((o,p)=>{if(p){const g=o.length>200?o.slice(0,200)+"...":o;if(!window.confirm("Copy "+o.length+` characters of the terminal to the clipboard?
+
+`+g))return}const m=()=>{const g=document.activeElement,b=document.createElement("textarea");b.value=o,b.style.position="fixed",b.style.opacity="0",document.body.appendChild(b),b.select(),document.execCommand("copy"),document.body.removeChild(b),g instanceof HTMLElement&&g.focus()},v=navigator.clipboard;v&&v.writeText?v.writeText(o).then(null,m):m()}),typeof gotty_zmodem!="undefined"&&gotty_zmodem&&n.intercept(new Zmodem(elem,o=>{n.writeBinary(o)},o=>{term.output(o)})),document.body.classList.contains("embed")&&typeof gotty_embed_origin!="undefined"&&gotty_embed_origin!=""&&new Embed(n,term,gotty_embed_origin);const u=n.open();window.addEventListener("unload",()=>{u(),term.close()})}var term;})()},function(e,t,r){var i={"./attach/attach":6,"./attach/attach.js":6,"./attach/package.json":35,"./fit/fit":7,"./fit/fit.js":7,"./fit/package.json":36,"./fullscreen/fullscreen":8,"./fullscreen/fullscreen.css":37,"./fullscreen/fullscreen.js":8,"./fullscreen/package.json":38,"./search/SearchHelper":3,"./search/SearchHelper.js":3,"./search/SearchHelper.js.map":39,"./search/search":9,"./search/search.js":9,"./search/search.js.map":40,"./terminado/package.json":41,"./terminado/terminado":10,"./terminado/terminado.js":10};function o(e){return r(s(e))}function s(e){var t=i[e];if(!(t+1))throw new Error("Cannot find module '"+e+"'.");return t}o.keys=function(){return Object.keys(i)},o.resolve=s,e.exports=o,o.id=34},function(e,t){e.exports={name:"xterm.attach",main:"attach.js",private:!0}},function(e,t){e.exports={name:"xterm.fit",main:"fit.js",private:!0}},function(e,t){throw new Error("Module parse failed: /home/mr/Documents/workspace/golang/src/github.com/wrfly/container-web-tty/js/node_modules/xterm/lib/addons/fullscreen/fullscreen.css Unexpected token (1:0)\nYou may need an appropriate loader to handle this file type.\n| .xterm.fullscreen {\n|     position: fixed;\n|     top: 0;")},function(e,t){e.exports={name:"xterm.fullscreen",main:"fullscreen.js",private:!0}},function(e,t){throw new Error('Module parse failed: /home/mr/Documents/workspace/golang/src/github.com/wrfly/container-web-tty/js/node_modules/xterm/lib/addons/search/SearchHelper.js.map Unexpected token (1:10)\nYou may need an appropriate loader to handle this file type.\n| {"version":3,"sources":["../../../src/addons/search/SearchHelper.ts"],"names":[],"mappings":";;AAgBA;IACE,sBAAoB,SAAc,EAAU,4BAAiC;QAAzD,cAAS,GAAT,SAAS,CAAK;QAAU,iCAA4B,GAA5B,4BAA4B,CAAK;IAK7E,CAAC;IAQM,+BAAQ,GAAf,UAAgB,IAAY;QAC1B,EAAE,CAAC,CAAC,CAAC,IAAI,IAAI,IAAI,CAAC,MAAM,KAAK,CAAC,CAAC,CAAC,CAAC;YAC/B,MAAM,CAAC,KAAK,CAAC;QACf,CAAC;QAED,IAAI,MAAqB,CAAC;QAE1B,IAAI,QAAQ,GAAG,IAAI,CAAC,SAAS,CAAC,MAAM,CAAC,KAAK,CAAC;QAC3C,EAAE,CAAC,CAAC,IAAI,CAAC,SAAS,CAAC,gBAAgB,CAAC,YAAY,CAAC,CAAC,CAAC;YAEjD,QAAQ,GAAG,IAAI,CAAC,SAAS,CAAC,gBAAgB,CAAC,YAAY,CAAC,CAAC,CAAC,CAAC;QAC7D,CAAC;QAGD,GAAG,CAAC,CAAC,IAAI,CAAC,GAAG,QAAQ,GAAG,CAAC,EAAE,CAAC,GAAG,IAAI,CAAC,SAAS,CAAC,MAAM,CAAC,KAAK,GAAG,IAAI,CAAC,SAAS,CAAC,IAAI,EAAE,CAAC,EAAE,EAAE,CAAC;YACtF,MAAM,GAAG,IAAI,CAAC,WAAW,CAAC,IAAI,EAAE,CAAC,CAAC,CAAC;YACnC,EAAE,CAAC,CAAC,MAAM,CAAC,CAAC,CAAC;gBACX,KAAK,CAAC;YACR,CAAC;QACH,CAAC;QAGD,EAAE,CAAC,CAAC,CAAC,MAAM,CAAC,CAAC,CAAC;YACZ,GAAG,CAAC,CAAC,IAAI,CAAC,GAAG,CAAC,EAAE,CAAC,GAAG,QAAQ,EAAE,CAAC,EAAE,EAAE,CAAC;gBAClC,MAAM,GAAG,IAAI,CAAC,WAAW,CAAC,IAAI,EAAE,CAAC,CAAC,CAAC;gBACnC,EAAE,CAAC,CAAC,MAAM,CAAC,CAAC,CAAC;oBACX,KAAK,CAAC;gBACR,CAAC;YACH,CAAC;QACH,CAAC;QAGD,MAAM,CAAC,IAAI,CAAC,aAAa,CAAC,MAAM,CAAC,CAAC;IACpC,CAAC;IAQM,mCAAY,GAAnB,UAAoB,IAAY;QAC9B,EAAE,CAAC,CAAC,CAAC,IAAI,IAAI,IAAI,CAAC,MAAM,KAAK,CAAC,CAAC,CAAC,CAAC;YAC/B,MAAM,CAAC,KAAK,CAAC;QACf,CAAC;QAED,IAAI,MAAqB,CAAC;QAE1B,IAAI,QAAQ,GAAG,IAAI,CAAC,SAAS,CAAC,MAAM,CAAC,KAAK,CAAC;QAC3C,EAAE,CAAC,CAAC,IAAI,CAAC,SAAS,CAAC,gBAAgB,CAAC,cAAc,CAAC,CAAC,CAAC;YAEnD,QAAQ,GAAG,IAAI,CAAC,SAAS,CAAC,gBAAgB,CAAC,cAAc,CAAC,CAAC,CAAC,CAAC;QAC/D,CAAC;QAGD,GAAG,CAAC,CAAC,IAAI,CAAC,GAAG,QAAQ,GAAG,CAAC,EAAE,CAAC,IAAI,CAAC,EAAE,CAAC,EAAE,EAAE,CAAC;YACvC,MAAM,GAAG,IAAI,CAAC,WAAW,CAAC,IAAI,EAAE,CAAC,CAAC,CAAC;YACnC,EAAE,CAAC,CAAC,MAAM,CAAC,CAAC,CAAC;gBACX,KAAK,CAAC;YACR,CAAC;QACH,CAAC;QAGD,EAAE,CAAC,CAAC,CAAC,MAAM,CAAC,CAAC,CAAC;YACZ,GAAG,CAAC,CAAC,IAAI,CAAC,GAAG,IAAI,CAAC,SAAS,CAAC,MAAM,CAAC,KAAK,GAAG,IAAI,CAAC,SAAS,CAAC,IAAI,GAAG,CAAC,EAAE,CAAC,GAAG,QAAQ,EAAE,CAAC,EAAE,EAAE,CAAC;gBACtF,MAAM,GAAG,IAAI,CAAC,WAAW,CAAC,IAAI,EAAE,CAAC,CAAC,CAAC;gBACnC,EAAE,CAAC,CAAC,MAAM,CAAC,CAAC,CAAC;oBACX,KAAK,CAAC;gBACR,CAAC;YACH,CAAC;QACH,CAAC;QAGD,MAAM,CAAC,IAAI,CAAC,aAAa,CAAC,MAAM,CAAC,CAAC;IACpC,CAAC;IAQO,kCAAW,GAAnB,UAAoB,IAAY,EAAE,CAAS;QACzC,IAAM,UAAU,GAAG,IAAI,CAAC,SAAS,CAAC,MAAM,CAAC,KAAK,CAAC,GAAG,CAAC,CAAC,CAAC,CAAC;QACtD,IAAM,eAAe,GAAG,IAAI,CAAC,4BAA4B,CAAC,UAAU,EAAE,IAAI,CAAC,CAAC,WAAW,EAAE,CAAC;QAC1F,IAAM,SAAS,GAAG,IAAI,CAAC,WAAW,EAAE,CAAC;QACrC,IAAM,WAAW,GAAG,eAAe,CAAC,OAAO,CAAC,SAAS,CAAC,CAAC;QACvD,EAAE,CAAC,CAAC,WAAW,IAAI,CAAC,CAAC,CAAC,CAAC;YACrB,MAAM,CAAC;gBACL,IAAI,MAAA;gBACJ,GAAG,EAAE,WAAW;gBAChB,GAAG,EAAE,CAAC;aACP,CAAC;QACJ,CAAC;IACH,CAAC;IAOO,oCAAa,GAArB,UAAsB,MAAqB;QACzC,EAAE,CAAC,CAAC,CAAC,MAAM,CAAC,CAAC,CAAC;YACZ,MAAM,CAAC,KAAK,CAAC;QACf,CAAC;QACD,IAAI,CAAC,SAAS,CAAC,gBAAgB,CAAC,YAAY,CAAC,MAAM,CAAC,GAAG,EAAE,MAAM,CAAC,GAAG,EAAE,MAAM,CAAC,IAAI,CAAC,MAAM,CAAC,CAAC;QACzF,IAAI,CAAC,SAAS,CAAC,UAAU,CAAC,MAAM,CAAC,GAAG,GAAG,IAAI,CAAC,SAAS,CAAC,MAAM,CAAC,KAAK,EAAE,KAAK,CAAC,CAAC;QAC3E,MAAM,CAAC,IAAI,CAAC;IACd,CAAC;IACH,mBAAC;AAAD,CA3HA,AA2HC,IAAA;AA3HY,oCAAY","file":"SearchHelper.js","sourceRoot":"."}')},function(e,t){throw new Error('Module parse failed: /home/mr/Documents/workspace/golang/src/github.com/wrfly/container-web-tty/js/node_modules/xterm/lib/addons/search/search.js.map Unexpected token (1:10)\nYou may need an appropriate loader to handle this file type.\n| {"version":3,"sources":["../../../src/addons/search/search.ts"],"names":[],"mappings":";;AAIA,+CAA8C;AAQ9C,CAAC,UAAU,KAAK;IACd,EAAE,CAAC,CAAC,UAAU,IAAI,MAAM,CAAC,CAAC,CAAC;QAIzB,KAAK,CAAC,MAAM,CAAC,QAAQ,CAAC,CAAC;IACzB,CAAC;IAAC,IAAI,CAAC,EAAE,CAAC,CAAC,OAAO,OAAO,KAAK,QAAQ,IAAI,OAAO,MAAM,KAAK,QAAQ,CAAC,CAAC,CAAC;QAIrE,MAAM,CAAC,OAAO,GAAG,KAAK,CAAC,OAAO,CAAC,aAAa,CAAC,CAAC,CAAC;IACjD,CAAC;IAAC,IAAI,CAAC,EAAE,CAAC,CAAC,OAAO,MAAM,IAAI,UAAU,CAAC,CAAC,CAAC;QAIvC,MAAM,CAAC,CAAC,aAAa,CAAC,EAAE,KAAK,CAAC,CAAC;IACjC,CAAC;AACH,CAAC,CAAC,CAAC,UAAC,QAAa;IAOf,QAAQ,CAAC,SAAS,CAAC,QAAQ,GAAG,UAAS,IAAY;QACjD,EAAE,CAAC,CAAC,CAAC,IAAI,CAAC,aAAa,CAAC,CAAC,CAAC;YACxB,IAAI,CAAC,YAAY,GAAG,IAAI,2BAAY,CAAC,IAAI,EAAE,QAAQ,CAAC,2BAA2B,CAAC,CAAC;QACnF,CAAC;QACD,MAAM,CAAgB,IAAI,CAAC,YAAa,CAAC,QAAQ,CAAC,IAAI,CAAC,CAAC;IAC1D,CAAC,CAAC;IAQF,QAAQ,CAAC,SAAS,CAAC,YAAY,GAAG,UAAS,IAAY;QACrD,EAAE,CAAC,CAAC,CAAC,IAAI,CAAC,aAAa,CAAC,CAAC,CAAC;YACxB,IAAI,CAAC,YAAY,GAAG,IAAI,2BAAY,CAAC,IAAI,EAAE,QAAQ,CAAC,2BAA2B,CAAC,CAAC;QACnF,CAAC;QACD,MAAM,CAAgB,IAAI,CAAC,YAAa,CAAC,YAAY,CAAC,IAAI,CAAC,CAAC;IAC9D,CAAC,CAAC;AACJ,CAAC,CAAC,CAAC","file":"search.js","sourceRoot":"."}')},function(e,t){e.exports={name:"xterm.terminado",main:"terminado.js",private:!0}}]);
\ No newline at end of file
@@ -0,0 +1,73 @@
+import { SessionError, Terminal, WebTTY } from "./webtty";
+
+// Embed exposes the terminal of an iframe to the parent window with
+// window.postMessage(), only the origins matching allowedOrigin are accepted.
+//
+// parent -> terminal:
+//   {type: "attach"}                         receive the outputs
+//   {type: "write", data: "ls\n"}            type into the terminal
+//   {type: "resize", columns: 80, rows: 24}  resize the terminal
+// terminal -> parent:
+//   {type: "ready"}                          the terminal is loaded
+//   {type: "data", data: "..."}              outputs of the process
+//   {type: "close", code: 0}                 the connection is closed, code is
+//                                            the exit code or null if unknown
+//   {type: "error", code: "container_gone",  the session failed, before the close
+//    message: "..."}
+export class Embed {
+    origin: RegExp;
+    // the outputs are only posted to the parent which has attached
+    target: string;
+
+    constructor(wt: WebTTY, term: Terminal, allowedOrigin: string) {
+        this.origin = new RegExp(allowedOrigin);
+        this.target = "";
+
+        window.addEventListener("message", (event: MessageEvent) => {
+            if (event.source !== window.parent || !this.origin.test(event.origin)) {
+                return;
+            }
+            const msg = event.data;
+            switch (msg.type) {
+                case "attach":
+                    this.target = event.origin;
+                    break;
+                case "write":
+                    wt.write(String(msg.data));
+                    break;
+                case "resize":
+                    term.resize(Number(msg.columns), Number(msg.rows));
+                    break;
+            }
+        });
+
+        wt.onOutput((data: string) => {
+            this.post({ type: "data", data: decodeUTF8(data) });
+        });
+        wt.onError((error: SessionError) => {
+            this.post({ type: "error", code: error.code, message: error.message });
+        });
+        wt.onClose((exitCode: number | null) => {
+            this.post({ type: "close", code: exitCode });
+        });
+
+        // there is nothing secret in it, the origin is unknown yet
+        window.parent.postMessage({ type: "ready" }, "*");
+    };
+
+    post(msg: object) {
+        if (this.target != "") {
+            window.parent.postMessage(msg, this.target);
+        }
+    };
+}
+
+// the outputs are UTF-8 bytes in a string
+function decodeUTF8(data: string): string {
+    try {
+        return decodeURIComponent(escape(data));
+    } catch (e) {
+        // a multi-byte character is split
+        return data;
+    }
+}
@@ -74,6 +74,11 @@ export class Hterm {
         };
     };
 
+    resize(columns: number, rows: number) {
+        this.term.setWidth(columns);
+        this.term.setHeight(rows);
+    };
+
     deactivate(): void {
         this.io.onVTKeystroke    = function(){};
         this.io.sendString       = function(){};
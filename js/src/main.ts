@@ -2,10 +2,15 @@ import { Hterm } from "./hterm";
 import { Xterm } from "./xterm";
 import { Terminal, WebTTY, protocols } from "./webtty";
 import { ConnectionFactory } from "./websocket";
+import { FallbackConnectionFactory, SSEConnectionFactory } from "./sse";
+import { Embed } from "./embed";
+import { Zmodem } from "./zmodem";
 
 // @TODO remove these
 declare var gotty_auth_token: string;
 declare var gotty_term: string;
+declare var gotty_embed_origin: string;
+declare var gotty_zmodem: boolean;
 
 const elem = document.getElementById("terminal")
 
@@ -19,8 +24,110 @@ if (elem !== null) {
     const httpsEnabled = window.location.protocol == "https:";
     const url = (httpsEnabled ? 'wss://' : 'ws://') + window.location.host + window.location.pathname + 'ws';
     const args = window.location.search;
-    const factory = new ConnectionFactory(url, protocols);
+    const sseURL = window.location.protocol + '//' + window.location.host + window.location.pathname + 'sse';
+    const factory = new FallbackConnectionFactory(
+        new ConnectionFactory(url, protocols),
+        new SSEConnectionFactory(sseURL),
+    );
     const wt = new WebTTY(term, factory, args, gotty_auth_token);
+
+    // stderr of a non-tty exec can be downloaded separately
+    const stderrLink = document.getElementById("stderr");
+    if (stderrLink !== null) {
+        let stderr = "";
+        wt.onStderr((data: string) => {
+            stderr += data;
+            stderrLink.style.display = "block";
+        });
+        stderrLink.onclick = () => {
+            const bytes = new Uint8Array(stderr.length);
+            for (let i = 0; i < stderr.length; i++) {
+                bytes[i] = stderr.charCodeAt(i);
+            }
+            stderrLink.setAttribute("href",
+                URL.createObjectURL(new Blob([bytes], { type: "text/plain" })));
+        };
+    }
+
+    // latency of the connection, so network lag can be told from a slow container
+    const latency = document.getElementById("latency");
+    if (latency !== null) {
+        wt.onLatency((rtt: number | null) => {
+            if (rtt === null) {
+                latency.textContent = "offline";
+                latency.className = "offline";
+                return;
+            }
+            latency.textContent = rtt + " ms";
+            latency.className = rtt < 100 ? "good" : rtt < 300 ? "fair" : "poor";
+        });
+    }
+
+    // the notices of the server, counting down to the deadline
+    const noticeBanner = document.getElementById("notice");
+    if (noticeBanner !== null) {
+        let countdown: number;
+        wt.onNotice((message: string, seconds: number) => {
+            clearInterval(countdown);
+            const deadline = Date.now() + seconds * 1000;
+            const render = () => {
+                const left = Math.max(0, Math.round((deadline - Date.now()) / 1000));
+                noticeBanner.textContent = seconds > 0 ? message + " in " + left + "s" : message;
+                if (left == 0) {
+                    clearInterval(countdown);
+                }
+            };
+            render();
+            if (seconds > 0) {
+                countdown = setInterval(render, 1000);
+            }
+        });
+    }
+
+    // the texts copied by the programs in the container, allowed by --clipboard
+    wt.onClipboard((text: string, ask: boolean) => {
+        if (ask) {
+            const preview = text.length > 200 ? text.slice(0, 200) + "..." : text;
+            if (!window.confirm("Copy " + text.length + " characters of the terminal to the clipboard?\n\n" + preview)) {
+                return;
+            }
+        }
+        const fallback = () => {
+            const focused = document.activeElement;
+            const area = document.createElement("textarea");
+            area.value = text;
+            area.style.position = "fixed";
+            area.style.opacity = "0";
+            document.body.appendChild(area);
+            area.select();
+            document.execCommand("copy");
+            document.body.removeChild(area);
+            if (focused instanceof HTMLElement) {
+                focused.focus();
+            }
+        };
+        const clipboard = (<any>navigator).clipboard;
+        if (clipboard && clipboard.writeText) {
+            clipboard.writeText(text).then(null, fallback);
+        } else {
+            fallback();
+        }
+    });
+
+    // sz and rz of --enable-zmodem
+    if (typeof gotty_zmodem !== "undefined" && gotty_zmodem) {
+        wt.intercept(new Zmodem(elem,
+            (data: string) => { wt.writeBinary(data); },
+            (data: string) => { term.output(data); },
+        ));
+    }
+
+    // ?embed=1, rendered by the server
+    if (document.body.classList.contains("embed") &&
+        typeof gotty_embed_origin !== "undefined" && gotty_embed_origin != "") {
+        new Embed(wt, term, gotty_embed_origin);
+    }
+
     const closer = wt.open();
 
     window.addEventListener("unload", () => {
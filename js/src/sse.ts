@@ -0,0 +1,219 @@
+import { Connection, ConnectionFactory } from "./webtty";
+
+// SSEConnectionFactory creates connections carried by Server-Sent Events
+// (server to browser) and POST requests (browser to server), for networks
+// where websockets are blocked.
+export class SSEConnectionFactory {
+    url: string;
+
+    constructor(url: string) {
+        this.url = url;
+    };
+
+    create(): Connection {
+        return new SSEConnection(this.url);
+    };
+}
+
+export class SSEConnection {
+    url: string;
+    bare: EventSource;
+    session: string;
+    // messages waiting for the in-flight POST to finish,
+    // POSTs are sent one by one to keep the input in order
+    pending: string[];
+    sending: boolean;
+    closed: boolean;
+
+    openCallback: () => void;
+    receiveCallback: (data: string) => void;
+    closeCallback: () => void;
+
+    constructor(url: string) {
+        this.url = url;
+        this.session = "";
+        this.pending = [];
+        this.sending = false;
+        this.closed = false;
+    }
+
+    open() {
+        this.bare = new EventSource(this.url);
+        this.bare.addEventListener("session", (event: MessageEvent) => {
+            this.session = event.data;
+            if (this.openCallback) {
+                this.openCallback();
+            }
+        });
+        this.bare.onmessage = (event) => {
+            if (this.receiveCallback) {
+                this.receiveCallback(event.data);
+            }
+        };
+        this.bare.onerror = () => {
+            // EventSource reconnects by itself, but a new stream is a
+            // new session on the server, so give up like a websocket does
+            this.close();
+        };
+    };
+
+    close() {
+        if (this.closed) {
+            return;
+        }
+        this.closed = true;
+        if (this.bare) {
+            this.bare.close();
+        }
+        if (this.closeCallback) {
+            this.closeCallback();
+        }
+    };
+
+    send(data: string) {
+        if (this.closed) {
+            return;
+        }
+        this.pending.push(data);
+        this.flush();
+    };
+
+    flush() {
+        if (this.sending || this.pending.length == 0 || this.session == "") {
+            return;
+        }
+        const messages = this.pending;
+        this.pending = [];
+        this.sending = true;
+
+        const xhr = new XMLHttpRequest();
+        xhr.open("POST", this.url + "/" + this.session);
+        xhr.setRequestHeader("Content-Type", "application/json");
+        xhr.onload = () => {
+            this.sending = false;
+            if (xhr.status >= 300) {
+                this.close();
+                return;
+            }
+            this.flush();
+        };
+        xhr.onerror = () => {
+            this.sending = false;
+            this.close();
+        };
+        xhr.send(JSON.stringify(messages));
+    };
+
+    isOpen(): boolean {
+        if (this.closed || !this.bare) {
+            return false;
+        }
+        return this.bare.readyState != EventSource.CLOSED;
+    }
+
+    onOpen(callback: () => void) {
+        this.openCallback = callback;
+    };
+
+    onReceive(callback: (data: string) => void) {
+        this.receiveCallback = callback;
+    };
+
+    onClose(callback: () => void) {
+        this.closeCallback = callback;
+    };
+}
+
+// FallbackConnectionFactory tries the primary transport first and
+// switches to the fallback one if the primary cannot be established.
+// Once switched, later connections (reconnects) use the fallback directly.
+export class FallbackConnectionFactory {
+    primary: ConnectionFactory;
+    fallback: ConnectionFactory;
+    useFallback: boolean;
+
+    constructor(primary: ConnectionFactory, fallback: ConnectionFactory) {
+        this.primary = primary;
+        this.fallback = fallback;
+        this.useFallback = false;
+    };
+
+    create(): Connection {
+        if (this.useFallback) {
+            return this.fallback.create();
+        }
+        return new FallbackConnection(this);
+    };
+}
+
+export class FallbackConnection {
+    factory: FallbackConnectionFactory;
+    current: Connection;
+    opened: boolean;
+
+    openCallback: () => void;
+    receiveCallback: (data: string) => void;
+    closeCallback: () => void;
+
+    constructor(factory: FallbackConnectionFactory) {
+        this.factory = factory;
+        this.opened = false;
+        this.current = factory.primary.create();
+        this.bind();
+    }
+
+    bind() {
+        this.current.onOpen(() => {
+            this.opened = true;
+            if (this.openCallback) {
+                this.openCallback();
+            }
+        });
+        this.current.onReceive((data: string) => {
+            if (this.receiveCallback) {
+                this.receiveCallback(data);
+            }
+        });
+        this.current.onClose(() => {
+            if (!this.opened && !this.factory.useFallback) {
+                console.log("Websocket unavailable, falling back to Server-Sent Events");
+                this.factory.useFallback = true;
+                this.current = this.factory.fallback.create();
+                this.bind();
+                this.current.open();
+                return;
+            }
+            if (this.closeCallback) {
+                this.closeCallback();
+            }
+        });
+    };
+
+    open() {
+        this.current.open();
+    };
+
+    close() {
+        this.current.close();
+    };
+
+    send(data: string) {
+        this.current.send(data);
+    };
+
+    isOpen(): boolean {
+        return this.current.isOpen();
+    };
+
+    onOpen(callback: () => void) {
+        this.openCallback = callback;
+    };
+
+    onReceive(callback: (data: string) => void) {
+        this.receiveCallback = callback;
+    };
+
+    onClose(callback: () => void) {
+        this.closeCallback = callback;
+    };
+}
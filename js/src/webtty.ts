@@ -4,6 +4,10 @@ export const msgInputUnknown = '0';
 export const msgInput = '1';
 export const msgPing = '2';
 export const msgResizeTerminal = '3';
+export const msgSetFlowWindow = '4';
+export const msgAck = '5';
+export const msgInputBinary = '6';
+export const msgTransfer = '7';
 
 export const msgUnknownOutput = '0';
 export const msgOutput = '1';
@@ -11,6 +15,42 @@ export const msgPong = '2';
 export const msgSetWindowTitle = '3';
 export const msgSetPreferences = '4';
 export const msgSetReconnect = '5';
+export const msgStderrOutput = '6';
+export const msgExited = '7';
+export const msgOutputTruncated = '8';
+export const msgSetResumeToken = '9';
+export const msgNotice = 'A';
+export const msgSetMaxInput = 'B';
+export const msgError = 'C';
+export const msgSetClipboard = 'D';
+
+// max bytes of output in flight, the server drops the output
+// beyond it, so a slow browser doesn't buffer it unboundedly,
+// or waits for the acks during a transfer
+export const flowWindow = 4 * 1024 * 1024;
+const ackInterval = flowWindow / 4;
+
+// the round-trip time is measured by the pings
+const pingInterval = 5 * 1000;
+
+// milliseconds between the messages of a large input (like a paste),
+// so it doesn't stall the relay
+const inputPacing = 10;
+
+// max bytes of a message of the server without SetMaxInput,
+// the default relay buffer
+const defaultMaxMessage = 1024;
+
+// the failures of the sessions not fixed by reconnecting
+const permanentErrors = ["container_gone", "exec_denied", "unauthorized", "bad_request", "no_shell"];
+
+// SessionError is the failure of the session sent by the server, the code
+// is machine-readable, e.g. container_gone or backend_down
+export interface SessionError {
+    code: string;
+    message: string;
+    request_id: string;
+}
 
 
 export interface Terminal {
@@ -22,11 +62,23 @@ export interface Terminal {
     setPreferences(value: object): void;
     onInput(callback: (input: string) => void): void;
     onResize(callback: (colmuns: number, rows: number) => void): void;
+    resize(columns: number, rows: number): void;
     reset(): void;
     deactivate(): void;
     close(): void;
 }
 
+// Interceptor takes over the output and the input of the terminal for a
+// while, like a ZMODEM transfer
+export interface Interceptor {
+    // consume returns the part of the output shown by the terminal
+    consume(output: string): string;
+    // active tells whether it takes the input instead of the process
+    active(): boolean;
+    // cancel aborts it, on Ctrl-C or the closed connection
+    cancel(): void;
+}
+
 export interface Connection {
     open(): void;
     close(): void;
@@ -48,6 +100,23 @@ export class WebTTY {
     args: string;
     authToken: string;
     reconnect: number;
+    // resumes the session after reconnecting, set by the server
+    resumeToken: string;
+    stderrHandler: (data: string) => void;
+    outputHandler: (data: string) => void;
+    closeHandler: (exitCode: number | null) => void;
+    latencyHandler: (rtt: number | null) => void;
+    noticeHandler: (message: string, seconds: number) => void;
+    errorHandler: (error: SessionError) => void;
+    clipboardHandler: (text: string, ask: boolean) => void;
+    interceptor: Interceptor | null;
+    // sends the input to the current connection, set when it's open
+    writer: ((data: string) => void) | null;
+    // sends the bytes (a binary string) to the current connection
+    binaryWriter: ((data: string) => void) | null;
+    exitCode: number | null;
+    // the failure of the current connection, if any
+    error: SessionError | null;
 
     constructor(term: Terminal, connectionFactory: ConnectionFactory, args: string, authToken: string) {
         this.term = term;
@@ -55,21 +124,178 @@ export class WebTTY {
         this.args = args;
         this.authToken = authToken;
         this.reconnect = -1;
+        this.resumeToken = "";
+        this.interceptor = null;
+        this.writer = null;
+        this.binaryWriter = null;
+        this.exitCode = null;
+        this.error = null;
+    };
+
+    // onStderr is called with the stderr of a non-tty exec
+    onStderr(callback: (data: string) => void) {
+        this.stderrHandler = callback;
+    };
+
+    // onOutput is called with the output of the process
+    onOutput(callback: (data: string) => void) {
+        this.outputHandler = callback;
+    };
+
+    // onClose is called when the connection is closed,
+    // with the exit code if the process exited
+    onClose(callback: (exitCode: number | null) => void) {
+        this.closeHandler = callback;
+    };
+
+    // onLatency is called with the round-trip time in milliseconds
+    // of every ping, null when the connection is closed
+    onLatency(callback: (rtt: number | null) => void) {
+        this.latencyHandler = callback;
+    };
+
+    // onNotice is called with the notices of the server, like the restart
+    // of a draining server in the seconds
+    onNotice(callback: (message: string, seconds: number) => void) {
+        this.noticeHandler = callback;
+    };
+
+    // onError is called with the failure of the session, before the
+    // connection is closed
+    onError(callback: (error: SessionError) => void) {
+        this.errorHandler = callback;
+    };
+
+    // onClipboard is called with the texts copied by OSC 52 of the process,
+    // whether the user is asked first is of the policy of the server
+    onClipboard(callback: (text: string, ask: boolean) => void) {
+        this.clipboardHandler = callback;
+    };
+
+    // intercept hands the output to the interceptor first, and the input
+    // to it while it's active
+    intercept(interceptor: Interceptor) {
+        this.interceptor = interceptor;
+    };
+
+    // write sends data to the process as if it were typed
+    write(data: string) {
+        if (this.writer) {
+            this.writer(data);
+        }
+    };
+
+    // writeBinary sends the bytes of the binary string to the process
+    writeBinary(data: string) {
+        if (this.binaryWriter) {
+            this.binaryWriter(data);
+        }
     };
 
     open() {
         let connection = this.connectionFactory.create();
         let pingTimer: number;
         let reconnectTimeout: number;
+        let unacked = 0;
+        // the time the ping in flight was sent, 0 if none
+        let pingSentAt = 0;
+        // max characters of an input message, 0 for unlimited
+        let maxInput = 0;
+        // max bytes of a message of the server, the relay buffer without
+        // the max input
+        let maxMessage = defaultMaxMessage;
+        // the input waiting to be sent after the pacing
+        let queued: string[] = [];
+        let inputTimer: number = 0;
+        // whether the server is told the interceptor is active, the output
+        // isn't dropped then
+        let transferring = false;
+
+        const syncTransfer = () => {
+            const active = this.interceptor !== null && this.interceptor.active();
+            if (active != transferring && connection.isOpen()) {
+                transferring = active;
+                connection.send(msgTransfer + JSON.stringify({ active: active }));
+            }
+        };
+
+        const sendQueued = () => {
+            inputTimer = 0;
+            const input = queued.shift();
+            if (input === undefined || !connection.isOpen()) {
+                queued = [];
+                return;
+            }
+            connection.send(input);
+            if (queued.length > 0) {
+                inputTimer = setTimeout(sendQueued, inputPacing);
+            }
+        };
+
+        const sendInput = (input: string) => {
+            while (maxInput > 0 && input.length > maxInput) {
+                let size = maxInput;
+                // not splitting a surrogate pair
+                const code = input.charCodeAt(size - 1);
+                if (size > 1 && code >= 0xD800 && code <= 0xDBFF) {
+                    size--;
+                }
+                queued.push(msgInput + input.slice(0, size));
+                input = input.slice(size);
+            }
+            queued.push(msgInput + input);
+            if (inputTimer == 0) {
+                sendQueued();
+            }
+        };
+
+        const sendBinary = (data: string) => {
+            // base64 of whole 3 bytes in a message
+            const size = Math.max(3, Math.floor((maxMessage - 1) / 4) * 3);
+            for (let i = 0; i < data.length; i += size) {
+                queued.push(msgInputBinary + btoa(data.slice(i, i + size)));
+            }
+            if (inputTimer == 0) {
+                sendQueued();
+            }
+        };
+
+        const sendTyped = (input: string) => {
+            const interceptor = this.interceptor;
+            if (interceptor === null || !interceptor.active()) {
+                sendInput(input);
+            } else if (input.indexOf("\x03") >= 0) {
+                interceptor.cancel();
+                syncTransfer();
+            }
+        };
+
+        const ping = () => {
+            if (pingSentAt == 0) {
+                pingSentAt = performance.now();
+            }
+            connection.send(msgPing);
+        };
+
+        const ack = (bytes: number) => {
+            unacked += bytes;
+            if (unacked >= ackInterval) {
+                connection.send(msgAck + unacked);
+                unacked = 0;
+            }
+        };
 
         const setup = () => {
             connection.onOpen(() => {
                 const termInfo = this.term.info();
+                this.exitCode = null;
+                this.error = null;
 
                 connection.send(JSON.stringify(
                     {
                         Arguments: this.args,
                         AuthToken: this.authToken,
+                        ResumeToken: this.resumeToken,
                     }
                 ));
 
@@ -85,18 +311,22 @@ export class WebTTY {
                     );
                 };
 
+                unacked = 0;
+                transferring = false;
+                connection.send(msgSetFlowWindow + JSON.stringify({ window: flowWindow }));
+
                 this.term.onResize(resizeHandler);
                 resizeHandler(termInfo.columns, termInfo.rows);
 
-                this.term.onInput(
-                    (input: string) => {
-                        connection.send(msgInput + input);
-                    }
-                );
+                maxInput = 0;
+                maxMessage = defaultMaxMessage;
+                this.writer = sendTyped;
+                this.binaryWriter = sendBinary;
+                this.term.onInput(this.writer);
 
-                pingTimer = setInterval(() => {
-                    connection.send(msgPing)
-                }, 30 * 1000);
+                pingSentAt = 0;
+                ping();
+                pingTimer = setInterval(ping, pingInterval);
 
             });
 
@@ -104,9 +334,37 @@ export class WebTTY {
                 const payload = data.slice(1);
                 switch (data[0]) {
                     case msgOutput:
-                        this.term.output(atob(payload));
+                        let output = atob(payload);
+                        ack(output.length);
+                        if (this.interceptor !== null) {
+                            output = this.interceptor.consume(output);
+                            syncTransfer();
+                        }
+                        if (output.length > 0) {
+                            this.term.output(output);
+                            if (this.outputHandler) {
+                                this.outputHandler(output);
+                            }
+                        }
+                        break;
+                    case msgStderrOutput:
+                        const stderr = atob(payload);
+                        // highlight stderr in red
+                        this.term.output("\x1b[31m" + stderr + "\x1b[0m");
+                        if (this.stderrHandler) {
+                            this.stderrHandler(stderr);
+                        }
+                        ack(stderr.length);
+                        break;
+                    case msgOutputTruncated:
+                        const truncated = JSON.parse(payload);
+                        this.term.output("\r\n\x1b[33m[output truncated: " + truncated.dropped + " bytes dropped]\x1b[0m\r\n");
                         break;
                     case msgPong:
+                        if (pingSentAt != 0 && this.latencyHandler) {
+                            this.latencyHandler(Math.round(performance.now() - pingSentAt));
+                        }
+                        pingSentAt = 0;
                         break;
                     case msgSetWindowTitle:
                         this.term.setWindowTitle(payload);
@@ -115,19 +373,78 @@ export class WebTTY {
                         const preferences = JSON.parse(payload);
                         this.term.setPreferences(preferences);
                         break;
+                    case msgExited:
+                        const exited = JSON.parse(payload);
+                        this.exitCode = exited.code;
+                        this.term.output("\r\n\x1b[1mprocess exited with code " + exited.code + "\x1b[0m\r\n");
+                        break;
                     case msgSetReconnect:
                         const autoReconnect = JSON.parse(payload);
                         console.log("Enabling reconnect: " + autoReconnect + " seconds")
                         this.reconnect = autoReconnect;
                         break;
+                    case msgSetResumeToken:
+                        this.resumeToken = payload;
+                        break;
+                    case msgSetMaxInput:
+                        // a character is at most 3 bytes of UTF-8,
+                        // 2 for each half of a surrogate pair
+                        maxMessage = JSON.parse(payload).bytes;
+                        maxInput = Math.max(1, Math.floor(maxMessage / 3));
+                        break;
+                    case msgError:
+                        const error: SessionError = JSON.parse(payload);
+                        this.error = error;
+                        this.term.output("\r\n\x1b[31m[" + error.message + " (" + error.code +
+                            (error.request_id ? ", request " + error.request_id : "") + ")]\x1b[0m\r\n");
+                        if (this.errorHandler) {
+                            this.errorHandler(error);
+                        }
+                        break;
+                    case msgSetClipboard:
+                        const clipboard = JSON.parse(payload);
+                        let text = atob(clipboard.data);
+                        try {
+                            // UTF-8
+                            text = decodeURIComponent(escape(text));
+                        } catch (e) {
+                        }
+                        if (this.clipboardHandler) {
+                            this.clipboardHandler(text, clipboard.ask);
+                        }
+                        break;
+                    case msgNotice:
+                        const notice = JSON.parse(payload);
+                        if (this.noticeHandler) {
+                            this.noticeHandler(notice.message, notice.seconds);
+                        } else {
+                            this.term.output("\r\n\x1b[33m[" + notice.message + "]\x1b[0m\r\n");
+                        }
+                        break;
                 }
             });
 
             connection.onClose(() => {
                 clearInterval(pingTimer);
+                clearTimeout(inputTimer);
+                inputTimer = 0;
+                queued = [];
+                this.writer = null;
+                this.binaryWriter = null;
+                if (this.interceptor !== null && this.interceptor.active()) {
+                    this.interceptor.cancel();
+                }
+                if (this.latencyHandler) {
+                    this.latencyHandler(null);
+                }
+                if (this.closeHandler) {
+                    this.closeHandler(this.exitCode);
+                }
                 this.term.deactivate();
-                this.term.showMessage("Connection Closed", 0);
-                if (this.reconnect > 0) {
+                this.term.showMessage(this.error ? this.error.message : "Connection Closed", 0);
+                // a new process would be started if it exited
+                const permanent = this.error !== null && permanentErrors.indexOf(this.error.code) >= 0;
+                if (this.reconnect > 0 && this.exitCode === null && !permanent) {
                     reconnectTimeout = setTimeout(() => {
                         connection = this.connectionFactory.create();
                         this.term.reset();
@@ -87,6 +87,10 @@ export class Xterm {
         });
     };
 
+    resize(columns: number, rows: number) {
+        this.term.resize(columns, rows);
+    };
+
     deactivate(): void {
         this.term.off("data");
         this.term.off("resize");
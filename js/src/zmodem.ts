@@ -0,0 +1,731 @@
+import { Interceptor } from "./webtty";
+
+// ZMODEM of sz and rz in the terminals, the output and the input are the
+// binary strings of the bytes, see http://gallium.inria.fr/~doligez/zmodem/zmodem.txt
+
+const ZPAD = 0x2a;
+const ZDLE = 0x18;
+const ZBIN = 0x41;
+const ZHEX = 0x42;
+const ZBIN32 = 0x43;
+
+// frame types
+const ZRQINIT = 0;
+const ZRINIT = 1;
+const ZSINIT = 2;
+const ZACK = 3;
+const ZFILE = 4;
+const ZSKIP = 5;
+const ZNAK = 6;
+const ZABORT = 7;
+const ZFIN = 8;
+const ZRPOS = 9;
+const ZDATA = 10;
+const ZEOF = 11;
+const ZFERR = 12;
+
+// the ends of the data subpackets
+const ZCRCE = 0x68;
+const ZCRCG = 0x69;
+const ZCRCQ = 0x6a;
+const ZCRCW = 0x6b;
+
+// ZRINIT capabilities
+const CANFDX = 0x01;
+const CANOVIO = 0x02;
+const CANFC32 = 0x20;
+
+// the start of a hex header of ZRQINIT (sz) or ZRINIT (rz), after ZPAD
+const marker = "\x18B0";
+// aborts the transfer of the other side
+const cancelSequence = "\x18\x18\x18\x18\x18\x18\x18\x18\b\b\b\b\b\b\b\b";
+// bytes of a data subpacket sent, and of the file read at once, the
+// receiver acknowledges every window
+const subpacketSize = 1024;
+const windowSize = 64 * 1024;
+// the other side is gone if nothing is received for it
+const idleTimeout = 60 * 1000;
+
+const crc16Table: number[] = [];
+const crc32Table: number[] = [];
+for (let i = 0; i < 256; i++) {
+    let c = i << 8;
+    for (let j = 0; j < 8; j++) {
+        c = c & 0x8000 ? (c << 1) ^ 0x1021 : c << 1;
+    }
+    crc16Table.push(c & 0xffff);
+    c = i;
+    for (let j = 0; j < 8; j++) {
+        c = c & 1 ? 0xedb88320 ^ (c >>> 1) : c >>> 1;
+    }
+    crc32Table.push(c >>> 0);
+}
+
+function crc16(data: string): number {
+    let crc = 0;
+    for (let i = 0; i < data.length; i++) {
+        crc = ((crc << 8) & 0xffff) ^ crc16Table[((crc >> 8) ^ data.charCodeAt(i)) & 0xff];
+    }
+    return crc;
+}
+
+function crc32(data: string): number {
+    let crc = 0xffffffff;
+    for (let i = 0; i < data.length; i++) {
+        crc = crc32Table[(crc ^ data.charCodeAt(i)) & 0xff] ^ (crc >>> 8);
+    }
+    return (crc ^ 0xffffffff) >>> 0;
+}
+
+function hex2(n: number): string {
+    return ("0" + n.toString(16)).slice(-2);
+}
+
+// le32 is the little-endian bytes of the number
+function le32(n: number): string {
+    return String.fromCharCode(n & 0xff, (n >>> 8) & 0xff, (n >>> 16) & 0xff, (n >>> 24) & 0xff);
+}
+
+// Header is a frame type and its 4 bytes, ZP0 to ZP3 (ZF3 to ZF0)
+interface Header {
+    type: number;
+    p: number[];
+}
+
+function posHeader(type: number, pos: number): Header {
+    return { type: type, p: [pos & 0xff, (pos >>> 8) & 0xff, (pos >>> 16) & 0xff, (pos >>> 24) & 0xff] };
+}
+
+function position(h: Header): number {
+    return h.p[0] + h.p[1] * 0x100 + h.p[2] * 0x10000 + h.p[3] * 0x1000000;
+}
+
+function headerBytes(h: Header): string {
+    return String.fromCharCode(h.type, h.p[0], h.p[1], h.p[2], h.p[3]);
+}
+
+// escape escapes ZDLE, the flow control and CR (of telnet) for the tty
+function escape(data: string): string {
+    let escaped = "";
+    for (let i = 0; i < data.length; i++) {
+        const c = data.charCodeAt(i);
+        switch (c & 0x7f) {
+            case 0x10: case 0x11: case 0x13: case 0x18: case 0x0d:
+                escaped += String.fromCharCode(ZDLE, c ^ 0x40);
+                break;
+            default:
+                escaped += data.charAt(i);
+        }
+    }
+    return escaped;
+}
+
+function hexHeader(h: Header): string {
+    const bytes = headerBytes(h);
+    const crc = crc16(bytes);
+    let encoded = "**\x18B";
+    for (let i = 0; i < bytes.length; i++) {
+        encoded += hex2(bytes.charCodeAt(i));
+    }
+    encoded += hex2(crc >> 8) + hex2(crc & 0xff) + "\r\x8a";
+    // XON, but not after the last ones
+    return h.type == ZACK || h.type == ZFIN ? encoded : encoded + "\x11";
+}
+
+function binHeader(h: Header, use32: boolean): string {
+    const bytes = headerBytes(h);
+    if (use32) {
+        return "*\x18C" + escape(bytes + le32(crc32(bytes)));
+    }
+    const crc = crc16(bytes);
+    return "*\x18A" + escape(bytes + String.fromCharCode(crc >> 8, crc & 0xff));
+}
+
+function subpacket(data: string, end: number, use32: boolean): string {
+    const checked = data + String.fromCharCode(end);
+    let crc: string;
+    if (use32) {
+        crc = le32(crc32(checked));
+    } else {
+        const c = crc16(checked);
+        crc = String.fromCharCode(c >> 8, c & 0xff);
+    }
+    return escape(data) + String.fromCharCode(ZDLE, end) + escape(crc);
+}
+
+// Decoded is the bytes decoded from the buffer up to the end, and the end
+// of the subpacket if it's one
+interface Decoded {
+    data: string;
+    end: number;
+    frameEnd: number;
+}
+
+// decode decodes the ZDLE escaped bytes of the buffer from the start, n
+// bytes or up to the end of a subpacket if n is 0, null if the buffer is
+// too short
+function decode(buf: string, start: number, n: number): Decoded | null {
+    const out: string[] = [];
+    let i = start;
+    while (n == 0 || out.length < n) {
+        if (i >= buf.length) {
+            return null;
+        }
+        let c = buf.charCodeAt(i++);
+        // XON and XOFF are not data
+        if ((c & 0x7f) == 0x11 || (c & 0x7f) == 0x13) {
+            continue;
+        }
+        if (c == ZDLE) {
+            if (i >= buf.length) {
+                return null;
+            }
+            c = buf.charCodeAt(i++);
+            if (c >= ZCRCE && c <= ZCRCW) {
+                if (n != 0) {
+                    return { data: out.join(""), end: i, frameEnd: -1 };
+                }
+                return { data: out.join(""), end: i, frameEnd: c };
+            }
+            c = c == 0x6c ? 0x7f : c == 0x6d ? 0xff : c ^ 0x40;
+        }
+        out.push(String.fromCharCode(c));
+    }
+    return { data: out.join(""), end: i, frameEnd: -1 };
+}
+
+// hexValue parses the hex bytes of the string, null if it's not hex
+function hexValue(s: string): number[] | null {
+    const bytes: number[] = [];
+    for (let i = 0; i < s.length; i += 2) {
+        const b = parseInt(s.substr(i, 2), 16);
+        if (isNaN(b)) {
+            return null;
+        }
+        bytes.push(b);
+    }
+    return bytes;
+}
+
+// Transfer is the file received or sent
+interface Transfer {
+    name: string;
+    size: number;
+    done: number;
+}
+
+// Zmodem detects sz and rz in the output of the terminal, then receives
+// the files sent by sz as downloads, or sends the files chosen in the
+// browser to rz, the input of the terminal is ignored until the end
+export class Zmodem implements Interceptor {
+    elem: HTMLElement;
+    writer: (data: string) => void;
+    output: (data: string) => void;
+
+    // the output held back, the start of a marker
+    carry: string;
+    // the last output ended with ZPAD
+    pad: boolean;
+    // the bytes of the transfer not parsed yet, null without one
+    buf: string | null;
+    sending: boolean;
+    use32: boolean;
+    // the header waiting for its data subpacket
+    dataHeader: Header | null;
+    finishing: boolean;
+
+    received: string[];
+    file: Transfer | null;
+    files: File[];
+    // increased by every new position of the sent file, the reads of the
+    // old ones are dropped
+    readGen: number;
+    idleTimer: number;
+
+    dialog: HTMLElement;
+    status: HTMLElement;
+    chooser: HTMLInputElement;
+
+    constructor(elem: HTMLElement, writer: (data: string) => void, output: (data: string) => void) {
+        this.elem = elem;
+        this.writer = writer;
+        this.output = output;
+        this.carry = "";
+        this.pad = false;
+        this.buf = null;
+        this.files = [];
+        this.readGen = 0;
+
+        const doc = elem.ownerDocument;
+        this.dialog = doc.createElement("div");
+        this.dialog.className = "zmodem";
+        this.status = doc.createElement("span");
+        this.chooser = doc.createElement("input");
+        this.chooser.type = "file";
+        this.chooser.multiple = true;
+        this.chooser.onchange = () => {
+            this.sendFiles(this.chooser.files);
+        };
+        const cancel = doc.createElement("button");
+        cancel.textContent = "cancel";
+        cancel.onclick = () => {
+            this.cancel();
+        };
+        this.dialog.appendChild(this.status);
+        this.dialog.appendChild(this.chooser);
+        this.dialog.appendChild(cancel);
+    };
+
+    active(): boolean {
+        return this.buf !== null;
+    };
+
+    consume(output: string): string {
+        if (this.buf !== null) {
+            return this.feed(output);
+        }
+        const data = this.carry + output;
+        this.carry = "";
+        for (let from = 0; ;) {
+            const i = data.indexOf(marker, from);
+            if (i < 0) {
+                // a marker may start at the end
+                let keep = Math.min(marker.length, data.length);
+                while (keep > 0 && marker.indexOf(data.slice(data.length - keep)) != 0) {
+                    keep--;
+                }
+                this.carry = data.slice(data.length - keep);
+                const shown = data.slice(0, data.length - keep);
+                if (shown.length > 0) {
+                    this.pad = shown.charCodeAt(shown.length - 1) == ZPAD;
+                }
+                return shown;
+            }
+            if (i + marker.length >= data.length) {
+                this.carry = data.slice(i);
+                return data.slice(0, i);
+            }
+            const padded = i > 0 ? data.charCodeAt(i - 1) == ZPAD : this.pad;
+            const kind = data.charAt(i + marker.length);
+            if (!padded || (kind != "0" && kind != "1")) {
+                from = i + 1;
+                continue;
+            }
+            // without the ZPADs of the header, and "rz\r" of sz
+            let shown = data.slice(0, i).replace(/\*+$/, "");
+            shown = shown.replace(/rz\r$/, "");
+            this.start(kind == "1");
+            return shown + this.feed("*" + data.slice(i));
+        }
+    };
+
+    cancel(): void {
+        if (this.buf === null) {
+            return;
+        }
+        this.writer(cancelSequence);
+        this.finish("canceled");
+    };
+
+    // start starts receiving the files of sz, or sending the files to rz
+    start(sending: boolean) {
+        this.buf = "";
+        this.sending = sending;
+        this.use32 = false;
+        this.dataHeader = null;
+        this.finishing = false;
+        this.received = [];
+        this.file = null;
+        this.files = [];
+        this.readGen++;
+        this.chooser.value = "";
+        this.chooser.style.display = "none";
+        this.setStatus(sending ? "rz is waiting for the files" : "sz is sending the files");
+        this.elem.appendChild(this.dialog);
+    };
+
+    // finish ends the transfer, the rest of the output is shown by the
+    // terminal
+    finish(message: string) {
+        clearTimeout(this.idleTimer);
+        const rest = this.buf;
+        this.buf = null;
+        this.readGen++;
+        if (this.dialog.parentNode == this.elem) {
+            this.elem.removeChild(this.dialog);
+        }
+        this.output("\r\n\x1b[33m[zmodem: " + message + "]\x1b[0m\r\n");
+        if (rest) {
+            this.output(rest);
+        }
+    };
+
+    setStatus(status: string) {
+        this.status.textContent = status;
+    };
+
+    progress() {
+        const file = this.file;
+        if (file !== null) {
+            const percent = file.size > 0 ? Math.floor(file.done * 100 / file.size) : 100;
+            this.setStatus((this.sending ? "sending " : "receiving ") + file.name + " " + percent + "%");
+        }
+    };
+
+    // feed parses the output of the transfer, returns the output after its
+    // end
+    feed(output: string): string {
+        if (this.buf === null) {
+            return output;
+        }
+        clearTimeout(this.idleTimer);
+        this.idleTimer = setTimeout(() => {
+            this.cancel();
+        }, idleTimeout);
+
+        this.buf += output;
+        const canceled = this.buf.indexOf("\x18\x18\x18\x18\x18");
+        if (canceled >= 0) {
+            this.buf = this.buf.slice(canceled).replace(/^[\x18\b]+/, "");
+            this.finish("canceled by the other side");
+            return "";
+        }
+        while (this.buf !== null) {
+            if (this.finishing) {
+                // "OO" of sz after ZFIN
+                this.buf = this.buf.replace(/^[\r\n\x8a\x11]+/, "");
+                if (this.buf.length < 2 && !this.sending) {
+                    break;
+                }
+                if (this.buf.slice(0, 2) == "OO") {
+                    this.buf = this.buf.slice(2);
+                }
+                this.finish(this.sending ? "sent" : "received");
+                break;
+            }
+            if (this.dataHeader !== null) {
+                if (!this.readSubpacket()) {
+                    break;
+                }
+            } else if (!this.readHeader()) {
+                break;
+            }
+        }
+        return "";
+    };
+
+    // readHeader reads a header from the buffer, false if it's too short
+    readHeader(): boolean {
+        const buf = this.buf as string;
+        const pad = buf.indexOf("*");
+        if (pad < 0) {
+            this.buf = "";
+            return false;
+        }
+        let i = pad;
+        while (i < buf.length && buf.charCodeAt(i) == ZPAD) {
+            i++;
+        }
+        if (i + 1 >= buf.length) {
+            this.buf = buf.slice(pad);
+            return false;
+        }
+        if (buf.charCodeAt(i) != ZDLE) {
+            this.buf = buf.slice(i);
+            return true;
+        }
+        const format = buf.charCodeAt(i + 1);
+        i += 2;
+        let h: Header | null = null;
+        switch (format) {
+            case ZHEX:
+                if (i + 14 > buf.length) {
+                    this.buf = buf.slice(pad);
+                    return false;
+                }
+                const bytes = hexValue(buf.substr(i, 14));
+                i += 14;
+                if (bytes !== null && crc16(String.fromCharCode.apply(null, bytes.slice(0, 5))) ==
+                    (bytes[5] << 8 | bytes[6])) {
+                    h = { type: bytes[0], p: bytes.slice(1, 5) };
+                }
+                break;
+            case ZBIN:
+            case ZBIN32:
+                const use32 = format == ZBIN32;
+                const decoded = decode(buf, i, use32 ? 9 : 7);
+                if (decoded === null) {
+                    this.buf = buf.slice(pad);
+                    return false;
+                }
+                i = decoded.end;
+                const data = decoded.data;
+                const valid = use32 ?
+                    le32(crc32(data.slice(0, 5))) == data.slice(5) :
+                    crc16(data.slice(0, 5)) == (data.charCodeAt(5) << 8 | data.charCodeAt(6));
+                if (valid) {
+                    h = { type: data.charCodeAt(0), p: [1, 2, 3, 4].map((n) => data.charCodeAt(n)) };
+                    this.use32 = use32;
+                }
+                break;
+        }
+        if (format == ZHEX) {
+            // CR, LF and XON after a hex header
+            for (let n = 0; n < 3 && i < buf.length && /[\r\n\x8a\x11]/.test(buf.charAt(i)); n++) {
+                i++;
+            }
+        }
+        this.buf = buf.slice(i);
+        if (h === null) {
+            // a bad header is asked again
+            if (!this.sending && (format == ZHEX || format == ZBIN || format == ZBIN32)) {
+                this.send(hexHeader(posHeader(ZNAK, 0)));
+            }
+            return true;
+        }
+        if (this.sending) {
+            this.senderHeader(h);
+        } else {
+            this.receiverHeader(h);
+        }
+        return true;
+    };
+
+    // readSubpacket reads a data subpacket of the header from the buffer,
+    // false if it's too short
+    readSubpacket(): boolean {
+        const buf = this.buf as string;
+        const decoded = decode(buf, 0, 0);
+        if (decoded === null) {
+            return false;
+        }
+        const crc = decode(buf, decoded.end, this.use32 ? 4 : 2);
+        if (crc === null) {
+            return false;
+        }
+        this.buf = buf.slice(crc.end);
+        const checked = decoded.data + String.fromCharCode(decoded.frameEnd);
+        const valid = this.use32 ? le32(crc32(checked)) == crc.data :
+            crc16(checked) == (crc.data.charCodeAt(0) << 8 | crc.data.charCodeAt(1));
+        this.subpacket(decoded.data, decoded.frameEnd, valid);
+        return true;
+    };
+
+    send(data: string) {
+        this.writer(data);
+    };
+
+    // receiverHeader handles the headers of sz
+    receiverHeader(h: Header) {
+        switch (h.type) {
+            case ZRQINIT:
+                this.send(hexHeader({ type: ZRINIT, p: [0, 0, 0, CANFDX | CANOVIO | CANFC32] }));
+                break;
+            case ZSINIT:
+            case ZFILE:
+            case ZDATA:
+                if (h.type == ZDATA && (this.file === null || position(h) != this.file.done)) {
+                    this.send(hexHeader(posHeader(ZRPOS, this.file === null ? 0 : this.file.done)));
+                    break;
+                }
+                this.dataHeader = h;
+                break;
+            case ZEOF:
+                const file = this.file;
+                if (file === null || position(h) != file.done) {
+                    break;
+                }
+                this.save(file.name, this.received.join(""));
+                this.file = null;
+                this.received = [];
+                this.send(hexHeader({ type: ZRINIT, p: [0, 0, 0, CANFDX | CANOVIO | CANFC32] }));
+                break;
+            case ZFIN:
+                this.send(hexHeader(posHeader(ZFIN, 0)));
+                this.finishing = true;
+                break;
+            case ZABORT:
+            case ZFERR:
+                this.send(hexHeader(posHeader(ZFIN, 0)));
+                this.finish("aborted by sz");
+                break;
+        }
+    };
+
+    // subpacket handles the data subpacket of the header
+    subpacket(data: string, end: number, valid: boolean) {
+        const h = this.dataHeader as Header;
+        if (end == ZCRCE || end == ZCRCW) {
+            this.dataHeader = null;
+        }
+        if (!valid) {
+            this.dataHeader = null;
+            if (h.type == ZDATA && this.file !== null) {
+                this.send(hexHeader(posHeader(ZRPOS, this.file.done)));
+            } else {
+                this.send(hexHeader(posHeader(ZNAK, 0)));
+            }
+            return;
+        }
+        switch (h.type) {
+            case ZSINIT:
+                this.send(hexHeader(posHeader(ZACK, 0)));
+                break;
+            case ZFILE:
+                // name\0size mtime mode ...
+                const nul = data.indexOf("\0");
+                const name = nul < 0 ? data : data.slice(0, nul);
+                const size = parseInt(data.slice(nul + 1), 10);
+                this.file = { name: name.replace(/^.*\//, ""), size: isNaN(size) ? 0 : size, done: 0 };
+                this.received = [];
+                this.progress();
+                this.send(hexHeader(posHeader(ZRPOS, 0)));
+                break;
+            case ZDATA:
+                const file = this.file as Transfer;
+                this.received.push(data);
+                file.done += data.length;
+                this.progress();
+                if (end == ZCRCQ || end == ZCRCW) {
+                    this.send(hexHeader(posHeader(ZACK, file.done)));
+                }
+                break;
+        }
+    };
+
+    // save downloads the file received
+    save(name: string, data: string) {
+        const bytes = new Uint8Array(data.length);
+        for (let i = 0; i < data.length; i++) {
+            bytes[i] = data.charCodeAt(i);
+        }
+        const doc = this.elem.ownerDocument;
+        const link = doc.createElement("a");
+        link.download = name;
+        link.href = URL.createObjectURL(new Blob([bytes], { type: "application/octet-stream" }));
+        doc.body.appendChild(link);
+        link.click();
+        doc.body.removeChild(link);
+        setTimeout(() => {
+            URL.revokeObjectURL(link.href);
+        }, 60 * 1000);
+    };
+
+    // senderHeader handles the headers of rz
+    senderHeader(h: Header) {
+        switch (h.type) {
+            case ZRINIT:
+                this.use32 = (h.p[3] & CANFC32) != 0;
+                if (this.file !== null) {
+                    // the file is received
+                    this.file = null;
+                    this.files.shift();
+                    this.sendFile();
+                } else if (this.files.length == 0 && this.chooser.style.display == "none") {
+                    // the files are chosen by a click
+                    this.chooser.style.display = "";
+                    this.setStatus("rz is waiting for the files");
+                }
+                break;
+            case ZSKIP:
+                this.file = null;
+                this.files.shift();
+                this.sendFile();
+                break;
+            case ZRPOS:
+            case ZACK:
+                if (this.file !== null) {
+                    this.sendData(position(h));
+                }
+                break;
+            case ZNAK:
+                if (this.file !== null && this.file.done == 0) {
+                    this.file = null;
+                    this.sendFile();
+                }
+                break;
+            case ZFIN:
+                this.send("OO");
+                this.finishing = true;
+                break;
+            case ZABORT:
+            case ZFERR:
+                this.finish("aborted by rz");
+                break;
+        }
+    };
+
+    // sendFiles sends the files chosen to rz
+    sendFiles(files: FileList | null) {
+        if (this.buf === null || !this.sending || files === null) {
+            return;
+        }
+        if (files.length == 0) {
+            this.cancel();
+            return;
+        }
+        for (let i = 0; i < files.length; i++) {
+            this.files.push(files[i]);
+        }
+        this.chooser.style.display = "none";
+        this.sendFile();
+    };
+
+    // sendFile offers the first file left to rz, then ends the session
+    sendFile() {
+        if (this.files.length == 0) {
+            this.setStatus("finishing");
+            this.send(hexHeader(posHeader(ZFIN, 0)));
+            return;
+        }
+        const f = this.files[0];
+        let left = 0;
+        for (let i = 0; i < this.files.length; i++) {
+            left += this.files[i].size;
+        }
+        this.file = { name: f.name, size: f.size, done: 0 };
+        this.progress();
+        const mtime = Math.floor(((f as any).lastModified || Date.now()) / 1000);
+        const info = f.name + "\0" + f.size + " " + mtime.toString(8) + " 100644 0 " +
+            this.files.length + " " + left + "\0";
+        this.send(binHeader(posHeader(ZFILE, 0), this.use32) + subpacket(info, ZCRCW, this.use32));
+    };
+
+    // sendData sends a window of the file from the position, the last one
+    // with ZEOF
+    sendData(pos: number) {
+        const file = this.files[0];
+        const transfer = this.file as Transfer;
+        const gen = ++this.readGen;
+        const reader = new FileReader();
+        reader.onload = () => {
+            if (gen != this.readGen || this.buf === null) {
+                return;
+            }
+            const bytes = new Uint8Array(reader.result as ArrayBuffer);
+            const chunks: string[] = [];
+            for (let i = 0; i < bytes.length; i += subpacketSize) {
+                chunks.push(String.fromCharCode.apply(null, bytes.subarray(i, i + subpacketSize)));
+            }
+            const last = pos + bytes.length >= file.size;
+            let data = binHeader(posHeader(ZDATA, pos), this.use32);
+            if (chunks.length == 0) {
+                chunks.push("");
+            }
+            for (let i = 0; i < chunks.length; i++) {
+                const end = i < chunks.length - 1 ? ZCRCG : last ? ZCRCE : ZCRCW;
+                data += subpacket(chunks[i], end, this.use32);
+            }
+            transfer.done = pos + bytes.length;
+            if (last) {
+                data += binHeader(posHeader(ZEOF, transfer.done), this.use32);
+            }
+            this.send(data);
+            this.progress();
+        };
+        reader.onerror = () => {
+            this.cancel();
+        };
+        reader.readAsArrayBuffer(file.slice(pos, pos + windowSize));
+    };
+}
@@ -9,6 +9,8 @@ export declare namespace hterm {
         installKeyboard(): void;
         uninstallKeyboard(): void;
         setWindowTitle(title: string): void;
+        setWidth(columnCount: number | null): void;
+        setHeight(rowCount: number | null): void;
         reset(): void;
         softReset(): void;
     }
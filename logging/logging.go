@@ -0,0 +1,220 @@
+// Package logging sets up the logrus loggers of the modules, they log with
+// their module field by the standard logger until it's set up, then by
+// their own loggers of the same output and format, whose levels are
+// changed at runtime
+package logging
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	m       sync.Mutex
+	modules = make(map[string]*logrus.Entry)
+	// the levels of the modules of their own, the others follow the
+	// level of the standard logger
+	levels = make(map[string]logrus.Level)
+	setUp  bool
+
+	// the logger of the debugged requests, see Debug
+	debugLogger *logrus.Logger
+	debugs      []debugTarget
+)
+
+// Module returns the logger of the module, e.g. "route" or "docker"
+func Module(name string) *logrus.Entry {
+	m.Lock()
+	defer m.Unlock()
+	if e, ok := modules[name]; ok {
+		return e
+	}
+	e := logrus.WithField("module", name)
+	if setUp {
+		e.Logger = newLogger(logrus.StandardLogger().GetLevel())
+	}
+	modules[name] = e
+	return e
+}
+
+func newLogger(level logrus.Level) *logrus.Logger {
+	std := logrus.StandardLogger()
+	logger := logrus.New()
+	logger.Out = std.Out
+	logger.Formatter = std.Formatter
+	logger.Hooks = std.Hooks
+	logger.SetLevel(level)
+	return logger
+}
+
+// Setup sets the format (text or json) and the level of the standard
+// logger, and the levels of the modules, e.g. "route=debug,docker=warn",
+// it's called before logging by the modules, which are registered by the
+// imports
+func Setup(format, level, moduleLevels string) error {
+	std := logrus.StandardLogger()
+	switch format {
+	case "", "text":
+		std.SetFormatter(&logrus.TextFormatter{})
+	case "json":
+		std.SetFormatter(&logrus.JSONFormatter{})
+	default:
+		return fmt.Errorf("bad log format %s, must be text or json", format)
+	}
+	if level == "" {
+		level = std.GetLevel().String()
+	}
+	mls := make(map[string]string)
+	for _, ml := range strings.Split(moduleLevels, ",") {
+		if ml = strings.TrimSpace(ml); ml == "" {
+			continue
+		}
+		parts := strings.SplitN(ml, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("bad module level %s, should be module=level", ml)
+		}
+		mls[parts[0]] = parts[1]
+	}
+
+	m.Lock()
+	defer m.Unlock()
+	if setUp {
+		// set up again, e.g. by the tests
+		for _, e := range modules {
+			e.Logger.Out, e.Logger.Formatter = std.Out, std.Formatter
+		}
+		if debugLogger != nil {
+			debugLogger.Out, debugLogger.Formatter = std.Out, std.Formatter
+		}
+	}
+	return setLevels(level, mls)
+}
+
+// SetLevels sets the level of the standard logger and of the modules
+// following it, and the modules' own levels, e.g. {"route": "debug"}, the
+// modules left out follow the standard one
+func SetLevels(level string, moduleLevels map[string]string) error {
+	m.Lock()
+	defer m.Unlock()
+	return setLevels(level, moduleLevels)
+}
+
+func setLevels(level string, moduleLevels map[string]string) error {
+	lvl, err := logrus.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	own := make(map[string]logrus.Level, len(moduleLevels))
+	for name, l := range moduleLevels {
+		if _, ok := modules[name]; !ok {
+			return fmt.Errorf("unknown module %s of the log levels", name)
+		}
+		if own[name], err = logrus.ParseLevel(l); err != nil {
+			return err
+		}
+	}
+
+	if !setUp {
+		// the modules have their own loggers from now on, so their
+		// levels are changed without racing with their logs
+		for _, e := range modules {
+			e.Logger = newLogger(lvl)
+		}
+		setUp = true
+	}
+	logrus.SetLevel(lvl)
+	for name, e := range modules {
+		if l, ok := own[name]; ok {
+			e.Logger.SetLevel(l)
+		} else {
+			e.Logger.SetLevel(lvl)
+		}
+	}
+	levels = own
+	return nil
+}
+
+// Levels returns the level of the standard logger and the modules' own
+// levels
+func Levels() (string, map[string]string) {
+	m.Lock()
+	defer m.Unlock()
+	mls := make(map[string]string, len(levels))
+	for name, l := range levels {
+		mls[name] = l.String()
+	}
+	return logrus.GetLevel().String(), mls
+}
+
+// ModuleNames returns the names of the modules, sorted
+func ModuleNames() []string {
+	m.Lock()
+	defer m.Unlock()
+	names := make([]string, 0, len(modules))
+	for name := range modules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// debugTarget is a field and its value whose logs are debugged
+// until the time
+type debugTarget struct {
+	field, value string
+	until        time.Time
+}
+
+// Debug logs the entries of the field and the value (e.g. user=alice) at
+// the debug level until the time, whatever the levels of their modules,
+// see Debugged
+func Debug(field, value string, until time.Time) {
+	m.Lock()
+	defer m.Unlock()
+	if debugLogger == nil {
+		debugLogger = newLogger(logrus.DebugLevel)
+	}
+	debugs = append(debugs, debugTarget{field, value, until})
+}
+
+// Debugs returns the targets of Debug not expired, as field=value to the
+// time they expire
+func Debugs() map[string]time.Time {
+	m.Lock()
+	defer m.Unlock()
+	targets := make(map[string]time.Time)
+	for _, d := range expire(time.Now()) {
+		targets[d.field+"="+d.value] = d.until
+	}
+	return targets
+}
+
+// expire drops the expired targets, it's called with the lock
+func expire(now time.Time) []debugTarget {
+	alive := debugs[:0]
+	for _, d := range debugs {
+		if now.Before(d.until) {
+			alive = append(alive, d)
+		}
+	}
+	debugs = alive
+	return debugs
+}
+
+// Debugged returns the entry logging at the debug level if any of its
+// fields is a target of Debug, the entry itself if not
+func Debugged(e *logrus.Entry) *logrus.Entry {
+	m.Lock()
+	defer m.Unlock()
+	for _, d := range expire(time.Now()) {
+		if v, ok := e.Data[d.field]; ok && fmt.Sprint(v) == d.value {
+			return logrus.NewEntry(debugLogger).WithFields(e.Data)
+		}
+	}
+	return e
+}
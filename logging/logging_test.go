@@ -0,0 +1,101 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestSetup(t *testing.T) {
+	std := logrus.StandardLogger()
+	out, level, formatter := std.Out, std.Level, std.Formatter
+	defer func() {
+		std.Out, std.Formatter = out, formatter
+		std.SetLevel(level)
+	}()
+	buf := new(bytes.Buffer)
+	std.Out = buf
+
+	route, docker := Module("test-route"), Module("test-docker")
+	if Module("test-route") != route {
+		t.Fatal("expect the same logger of a module")
+	}
+	for _, levels := range []string{"nothing", "test-route=loud", "unknown=debug"} {
+		if err := Setup("text", "info", levels); err == nil {
+			t.Fatalf("expect an error of %s", levels)
+		}
+	}
+	if err := Setup("xml", "info", ""); err == nil {
+		t.Fatal("expect an error of a bad format")
+	}
+
+	if err := Setup("json", "warn", "test-route=debug"); err != nil {
+		t.Fatal(err)
+	}
+	route.Debug("route debug")
+	docker.Info("docker info")
+	docker.Warn("docker warn")
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("unexpected logs: %s", buf)
+	}
+	var entry map[string]string
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry["module"] != "test-route" || entry["msg"] != "route debug" || entry["level"] != "debug" {
+		t.Fatalf("unexpected entry: %s", lines[0])
+	}
+	if !strings.Contains(lines[1], `"msg":"docker warn"`) {
+		t.Fatalf("unexpected entry: %s", lines[1])
+	}
+}
+
+func TestSetLevels(t *testing.T) {
+	std := logrus.StandardLogger()
+	out, level, formatter := std.Out, std.Level, std.Formatter
+	defer func() {
+		std.Out, std.Formatter = out, formatter
+		std.SetLevel(level)
+	}()
+	buf := new(bytes.Buffer)
+	std.Out = buf
+	if err := Setup("text", "info", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	exec := Module("test-exec")
+	if err := SetLevels("info", map[string]string{"test-exec": "loud"}); err == nil {
+		t.Fatal("expect an error of a bad level")
+	}
+	if err := SetLevels("warn", map[string]string{"test-exec": "debug"}); err != nil {
+		t.Fatal(err)
+	}
+	if lvl, mls := Levels(); lvl != "warning" || mls["test-exec"] != "debug" || len(mls) != 1 {
+		t.Fatalf("unexpected levels: %s %v", lvl, mls)
+	}
+	exec.Debug("exec debug")
+	if !strings.Contains(buf.String(), "exec debug") {
+		t.Fatalf("expect the debug log of the module, got %s", buf)
+	}
+
+	buf.Reset()
+	other := Module("test-other")
+	other.WithField("user", "bob").Info("bob info")
+	Debug("user", "alice", time.Now().Add(time.Minute))
+	Debugged(other.WithField("user", "alice")).Debug("alice debug")
+	if s := buf.String(); strings.Contains(s, "bob") || !strings.Contains(s, "alice debug") {
+		t.Fatalf("expect only the debugged user logged, got %s", s)
+	}
+	if until, ok := Debugs()["user=alice"]; !ok || time.Until(until) <= 0 {
+		t.Fatalf("expect the debugged user, got %v", Debugs())
+	}
+	Debug("user", "carol", time.Now().Add(-time.Second))
+	if _, ok := Debugs()["user=carol"]; ok {
+		t.Fatal("expect the expired target dropped")
+	}
+}
@@ -12,19 +12,30 @@ import (
 	"gopkg.in/urfave/cli.v2"
 
 	"github.com/wrfly/container-web-tty/config"
+	"github.com/wrfly/container-web-tty/logging"
+	"github.com/wrfly/container-web-tty/route"
 	"github.com/wrfly/container-web-tty/util"
 )
 
-func main() {
-	conf := config.New()
-	appFlags := []cli.Flag{
+var ginLog = logging.Module("gin")
+
+// appFlags are the flags of the options of the config
+func appFlags(conf *config.Config) []cli.Flag {
+	flags := []cli.Flag{
 		&cli.StringFlag{
 			Name:        "addr",
 			EnvVars:     util.EnvVars("address"),
-			Usage:       "server binding address",
+			Usage:       "server binding address, IPs (v4 or v6), hostnames or interface names (all the IPs of them) listened on separately, use comma for split, e.g. '0.0.0.0,::' or 'eth0'",
 			Value:       "0.0.0.0",
 			Destination: &conf.Server.Address,
 		},
+		&cli.StringFlag{
+			Name:        "ip-family",
+			EnvVars:     util.EnvVars("ip-family"),
+			Usage:       "IP family of the TCP listeners, 'dual' (a wildcard address listens on both), 'ipv4' or 'ipv6' to disable the other",
+			Value:       "dual",
+			Destination: &conf.Server.IPFamily,
+		},
 		&cli.IntFlag{
 			Name:        "port",
 			Aliases:     []string{"p"},
@@ -33,6 +44,47 @@ func main() {
 			Value:       8080,
 			Destination: &conf.Server.Port,
 		},
+		&cli.StringFlag{
+			Name:    "listen",
+			EnvVars: util.EnvVars("listen"),
+			Usage:   "other listeners of the HTTP server, [routes@]host:port or [routes@]unix:/path, the routes are all (default), public or admin, use comma for split",
+		},
+		&cli.StringFlag{
+			Name:        "admin-addr",
+			EnvVars:     util.EnvVars("admin-addr"),
+			Usage:       "listener of the admin API and pages, the metrics (/debug/vars) and pprof, host:port or unix:/path, the other listeners never serve them if it's set",
+			Destination: &conf.Server.AdminAddr,
+		},
+		&cli.BoolFlag{
+			Name:    "no-setup",
+			EnvVars: util.EnvVars("no-setup"),
+			Usage:   "serve the terminals without the setup of the first run when there's neither a config file nor the admin token",
+		},
+		&cli.StringFlag{
+			Name:        "socket-mode",
+			EnvVars:     util.EnvVars("socket-mode"),
+			Usage:       "file mode of the unix sockets, in octal",
+			Value:       "0660",
+			Destination: &conf.Server.SocketMode,
+		},
+		&cli.StringFlag{
+			Name:        "socket-owner",
+			EnvVars:     util.EnvVars("socket-owner"),
+			Usage:       "owner of the unix sockets, user[:group], by names or IDs",
+			Destination: &conf.Server.SocketOwner,
+		},
+		&cli.StringFlag{
+			Name:        "base-path",
+			EnvVars:     util.EnvVars("base-path"),
+			Usage:       "path all the routes are under, e.g. /tty/ behind a proxy forwarding the path as is, empty for /",
+			Destination: &conf.Server.BasePath,
+		},
+		&cli.StringFlag{
+			Name:        "external-url",
+			EnvVars:     util.EnvVars("external-url"),
+			Usage:       "URL the users reach the server at, e.g. https://tty.example.com, of the join and forward URLs returned by the API, they are paths without it",
+			Destination: &conf.Server.ExternalURL,
+		},
 		&cli.BoolFlag{
 			Name:        "debug",
 			Aliases:     []string{"d"},
@@ -41,6 +93,166 @@ func main() {
 			Usage:       "debug mode (log-level=debug enable pprof)",
 			Destination: &conf.Debug,
 		},
+		&cli.StringFlag{
+			Name:        "log-format",
+			EnvVars:     util.EnvVars("log-format"),
+			Usage:       "format of the logs: text or json",
+			Value:       "text",
+			Destination: &conf.Log.Format,
+		},
+		&cli.StringFlag{
+			Name:        "log-level",
+			EnvVars:     util.EnvVars("log-level"),
+			Usage:       "level of the logs: debug, info, warn or error, debug by --debug",
+			Value:       "info",
+			Destination: &conf.Log.Level,
+		},
+		&cli.StringFlag{
+			Name:        "log-levels",
+			EnvVars:     util.EnvVars("log-levels"),
+			Usage:       "levels of the modules separated by commas, e.g. route=debug,docker=warn, the modules are route, docker, kube, grpc, proxy, container, audit, tracing and gin",
+			Destination: &conf.Log.Modules,
+		},
+		&cli.StringFlag{
+			Name:        "access-log",
+			EnvVars:     util.EnvVars("access-log"),
+			Usage:       "file the HTTP requests are logged to, '-' for stdout, empty to disable",
+			Destination: &conf.Server.AccessLog.Path,
+		},
+		&cli.StringFlag{
+			Name:        "access-log-format",
+			EnvVars:     util.EnvVars("access-log-format"),
+			Usage:       "format of the access log: combined or json",
+			Value:       "combined",
+			Destination: &conf.Server.AccessLog.Format,
+		},
+		&cli.Float64Flag{
+			Name:        "access-log-sample-ratio",
+			EnvVars:     util.EnvVars("access-log-sample-ratio"),
+			Usage:       "ratio (0 to 1) of the successful requests logged, the failed ones are always logged",
+			Value:       1,
+			Destination: &conf.Server.AccessLog.SampleRatio,
+		},
+		&cli.StringFlag{
+			Name:    "access-log-skip",
+			EnvVars: util.EnvVars("access-log-skip"),
+			Usage:   "paths under the base path not logged, use comma for split",
+			Value:   "/healthz,/readyz",
+		},
+		&cli.StringFlag{
+			Name:        "sentry-dsn",
+			EnvVars:     util.EnvVars("sentry-dsn"),
+			Usage:       "DSN of the Sentry project the recovered panics are reported to, empty to disable",
+			Destination: &conf.Report.SentryDSN,
+		},
+		&cli.StringFlag{
+			Name:        "error-webhook",
+			EnvVars:     util.EnvVars("error-webhook"),
+			Usage:       "URL the recovered panics are posted to as JSON, empty to disable",
+			Destination: &conf.Report.Webhook,
+		},
+		&cli.StringFlag{
+			Name:        "otlp-endpoint",
+			EnvVars:     util.EnvVars("otlp-endpoint"),
+			Usage:       "OTLP/HTTP endpoint of the collector the traces are exported to, e.g. http://otel-collector:4318, empty to disable",
+			Destination: &conf.Trace.Endpoint,
+		},
+		&cli.StringFlag{
+			Name:        "otlp-service-name",
+			EnvVars:     util.EnvVars("otlp-service-name"),
+			Usage:       "service name of the exported traces",
+			Value:       "container-web-tty",
+			Destination: &conf.Trace.Service,
+		},
+		&cli.Float64Flag{
+			Name:        "trace-sample-ratio",
+			EnvVars:     util.EnvVars("trace-sample-ratio"),
+			Usage:       "ratio (0 to 1) of the sampled traces started by the server, the ones of the clients' traceparent follow them",
+			Value:       1,
+			Destination: &conf.Trace.SampleRatio,
+		},
+		&cli.BoolFlag{
+			Name:        "update-check",
+			EnvVars:     util.EnvVars("update-check"),
+			Usage:       "check the latest release once a day, shown on the admin page, nothing but a GET of --update-check-url is sent",
+			Destination: &conf.Server.UpdateCheck,
+		},
+		&cli.StringFlag{
+			Name:        "update-check-url",
+			EnvVars:     util.EnvVars("update-check-url"),
+			Usage:       "URL of the latest release of the update check, in the format of the GitHub API",
+			Value:       "https://api.github.com/repos/wrfly/container-web-tty/releases/latest",
+			Destination: &conf.Server.UpdateCheckURL,
+		},
+		&cli.StringFlag{
+			Name:        "pprof-addr",
+			EnvVars:     util.EnvVars("pprof-addr"),
+			Usage:       "listening address of a separate pprof server (/debug/pprof/), e.g. 127.0.0.1:6060, empty to disable",
+			Destination: &conf.Server.PprofAddr,
+		},
+		&cli.StringFlag{
+			Name:        "dev-assets",
+			EnvVars:     util.EnvVars("dev-assets"),
+			Usage:       "resources dir read on every request instead of the embedded assets, to develop the UI without rebuilding",
+			Destination: &conf.Server.DevAssets,
+		},
+		&cli.StringFlag{
+			Name:        "static-dir",
+			EnvVars:     util.EnvVars("static-dir"),
+			Usage:       "dir of the files replacing the embedded assets or added to them (e.g. favicon.png, list.html, custom.css linked by all the pages), laid out like the resources dir, read at the start",
+			Destination: &conf.Server.StaticDir,
+		},
+		&cli.StringFlag{
+			Name:        "banner",
+			EnvVars:     util.EnvVars("banner"),
+			Usage:       "text shown on top of the list page and the admin login, e.g. 'authorized use only'",
+			Destination: &conf.Server.Banner,
+		},
+		&cli.StringFlag{
+			Name:        "motd",
+			EnvVars:     util.EnvVars("motd"),
+			Usage:       "message of the day written to the terminals when the sessions start, e.g. 'This session is recorded'",
+			Destination: &conf.Server.MOTD,
+		},
+		&cli.StringFlag{
+			Name:        "timezone",
+			EnvVars:     util.EnvVars("timezone"),
+			Usage:       "zone of the times of the audit logs, the log downloads and the pages: browser (their own), server, UTC or an IANA name, e.g. Europe/Berlin",
+			Value:       "browser",
+			Destination: &conf.Server.Timezone,
+		},
+		&cli.BoolFlag{
+			Name:        "clock",
+			EnvVars:     util.EnvVars("clock"),
+			Usage:       "show a clock in the timezone on the terminal page",
+			Destination: &conf.Server.Clock,
+		},
+		&cli.StringFlag{
+			Name:        "title-format",
+			EnvVars:     util.EnvVars("title-format"),
+			Usage:       "template of the window titles of the terminals, of .containerName, .containerID, .containerImage, .containerLoc, .backend (docker or kube), .host, .user, .namespace and .podName",
+			Value:       route.DefaultTitleFormat,
+			Destination: &conf.Server.TitleFormat,
+		},
+		&cli.StringFlag{
+			Name:        "title-format-docker",
+			EnvVars:     util.EnvVars("title-format-docker"),
+			Usage:       "template of the window titles of the docker containers, --title-format if it's empty",
+			Destination: &conf.Server.TitleFormatDocker,
+		},
+		&cli.StringFlag{
+			Name:        "title-format-kube",
+			EnvVars:     util.EnvVars("title-format-kube"),
+			Usage:       "template of the window titles of the containers of the kube pods, --title-format if it's empty, e.g. '{{ .namespace }}/{{ .podName }}/{{ .containerName }}'",
+			Destination: &conf.Server.TitleFormatKube,
+		},
+		&cli.IntFlag{
+			Name:        "logs-buffer",
+			EnvVars:     util.EnvVars("logs-buffer"),
+			Usage:       "bytes of the followed logs read ahead of a slow client, the oldest lines are dropped beyond it, 0 to not drop",
+			Value:       1 << 20,
+			Destination: &conf.Server.LogsBuffer,
+		},
 		&cli.StringFlag{
 			Name:        "backend",
 			Aliases:     []string{"b"},
@@ -62,6 +274,20 @@ func main() {
 			Usage:       "docker ps options",
 			Destination: &conf.Backend.Docker.PsOptions,
 		},
+		&cli.IntFlag{
+			Name:        "docker-max-idle-conns",
+			EnvVars:     util.EnvVars("docker-max-idle-conns"),
+			Usage:       "max idle connections to the docker daemon kept to be reused by the API calls",
+			Value:       16,
+			Destination: &conf.Backend.Docker.MaxIdleConns,
+		},
+		&cli.DurationFlag{
+			Name:        "docker-idle-timeout",
+			EnvVars:     util.EnvVars("docker-idle-timeout"),
+			Usage:       "time an idle connection to the docker daemon is kept, 0 to keep it forever",
+			Value:       90 * time.Second,
+			Destination: &conf.Backend.Docker.IdleConnTimeout,
+		},
 		&cli.StringFlag{
 			Name:        "kube-config",
 			EnvVars:     util.EnvVars("kube-config"),
@@ -88,6 +314,13 @@ func main() {
 			Value:       "password",
 			Destination: &conf.Backend.GRPC.Auth,
 		},
+		&cli.DurationFlag{
+			Name:        "grpc-list-timeout",
+			EnvVars:     util.EnvVars("grpc-list-timeout"),
+			Usage:       "max time to list the containers of an upstream server, the slower ones are left out of the list, 0 for no limit",
+			Value:       5 * time.Second,
+			Destination: &conf.Backend.GRPC.ListTimeout,
+		},
 		&cli.StringFlag{
 			Name:        "grpc-proxy",
 			EnvVars:     util.EnvVars("grpc-proxy"),
@@ -95,11 +328,409 @@ func main() {
 			Value:       "",
 			Destination: &conf.Backend.GRPC.Proxy,
 		},
+		&cli.StringFlag{
+			Name:    "trusted-proxies",
+			EnvVars: util.EnvVars("trusted-proxies"),
+			Usage:   "CIDRs of the proxies in front whose X-Forwarded-For is believed for the client IPs, use comma for split",
+		},
+		&cli.BoolFlag{
+			Name:        "h2c",
+			EnvVars:     util.EnvVars("h2c"),
+			Usage:       "serve HTTP/2 without TLS (h2c with prior knowledge or upgrade) to the trusted proxies in front",
+			Destination: &conf.Server.H2C,
+		},
+		&cli.StringFlag{
+			Name:        "tls-cert",
+			EnvVars:     util.EnvVars("tls-cert"),
+			Usage:       "certificate file to serve TLS, reloaded once it's changed",
+			Destination: &conf.Server.TLSCert,
+		},
+		&cli.StringFlag{
+			Name:        "tls-key",
+			EnvVars:     util.EnvVars("tls-key"),
+			Usage:       "key file of the TLS certificate, reloaded once it's changed",
+			Destination: &conf.Server.TLSKey,
+		},
+		&cli.StringFlag{
+			Name:        "tls-profile",
+			EnvVars:     util.EnvVars("tls-profile"),
+			Usage:       "ciphers and curves of TLS, 'default', 'modern' (the ECDHE AEAD ciphers of TLS 1.2 and TLS 1.3) or 'fips' (the FIPS-approved ones, the default of a FIPS build)",
+			Destination: &conf.Server.TLSProfile,
+		},
+		&cli.BoolFlag{
+			Name:    "tls-modern",
+			EnvVars: util.EnvVars("tls-modern"),
+			Usage:   "same as --tls-profile modern",
+		},
+		&cli.BoolFlag{
+			Name:        "letsencrypt",
+			EnvVars:     util.EnvVars("letsencrypt"),
+			Usage:       "serve TLS with the certificates of --domain got from Let's Encrypt",
+			Destination: &conf.Server.LetsEncrypt,
+		},
+		&cli.StringFlag{
+			Name:    "domain",
+			EnvVars: util.EnvVars("domain"),
+			Usage:   "domains of the Let's Encrypt certificates, use comma for split",
+		},
+		&cli.StringFlag{
+			Name:        "acme-cache",
+			EnvVars:     util.EnvVars("acme-cache"),
+			Usage:       "dir caching the account key and the certificates of Let's Encrypt",
+			Value:       "acme",
+			Destination: &conf.Server.ACMECacheDir,
+		},
+		&cli.StringFlag{
+			Name:        "acme-email",
+			EnvVars:     util.EnvVars("acme-email"),
+			Usage:       "contact email of the Let's Encrypt account",
+			Destination: &conf.Server.ACMEEmail,
+		},
+		&cli.StringFlag{
+			Name:        "acme-directory",
+			EnvVars:     util.EnvVars("acme-directory"),
+			Usage:       "directory URL of another ACME CA (e.g. the staging one), empty for Let's Encrypt",
+			Destination: &conf.Server.ACMEDirectory,
+		},
+		&cli.StringFlag{
+			Name:        "acme-http-addr",
+			EnvVars:     util.EnvVars("acme-http-addr"),
+			Usage:       "listening address of the HTTP-01 challenges and the redirects to HTTPS, empty to only use TLS-ALPN",
+			Value:       ":80",
+			Destination: &conf.Server.ACMEHTTPAddr,
+		},
 		&cli.StringFlag{
 			Name:    "idle-time",
 			EnvVars: util.EnvVars("idle-time"),
 			Usage:   "time out of an idle connection",
 		},
+		&cli.StringFlag{
+			Name:        "storage",
+			EnvVars:     util.EnvVars("storage"),
+			Usage:       "storage of the closed sessions, the exec history and the detached sessions of the replicas, memory://, bolt:///path, sqlite:///path (built with -tags sqlite), postgres://... or redis://..., the memory and the audit dir if it's empty",
+			Destination: &conf.Server.Storage,
+		},
+		&cli.StringFlag{
+			Name:        "redis-addr",
+			EnvVars:     util.EnvVars("redis-addr"),
+			Usage:       "redis shared by the replicas to resume the sessions detached on others, host:port or redis://:password@host:port/db",
+			Destination: &conf.Server.RedisAddr,
+		},
+		&cli.StringFlag{
+			Name:        "replica-url",
+			EnvVars:     util.EnvVars("replica-url"),
+			Usage:       "URL of this replica reachable by the other replicas, required by --redis-addr, resumes the sessions detached on others in a shared --storage",
+			Destination: &conf.Server.ReplicaURL,
+		},
+		&cli.IntFlag{
+			Name:    "replay-buffer",
+			EnvVars: util.EnvVars("replay-buffer"),
+			Usage:   "KB of the recent output replayed to a reconnected browser",
+			Value:   64,
+		},
+		&cli.DurationFlag{
+			Name:        "drain-timeout",
+			EnvVars:     util.EnvVars("drain-timeout"),
+			Usage:       "max time to drain the sessions on SIGTERM before exiting, 0 to exit at once",
+			Destination: &conf.Server.DrainTimeout,
+		},
+		&cli.DurationFlag{
+			Name:        "resume-timeout",
+			EnvVars:     util.EnvVars("resume-timeout"),
+			Usage:       "max time a session waits for its browser to reconnect, 0 to close it with the connection",
+			Destination: &conf.Server.ResumeTimeout,
+		},
+		&cli.DurationFlag{
+			Name:        "run-timeout",
+			EnvVars:     util.EnvVars("run-timeout"),
+			Usage:       "max time of a one-shot command run by the API",
+			Value:       30 * time.Second,
+			Destination: &conf.Server.RunTimeout,
+		},
+		&cli.StringFlag{
+			Name:        "debug-image",
+			EnvVars:     util.EnvVars("debug-image"),
+			Usage:       "toolbox image of the debug containers launched by --control-debug",
+			Value:       "busybox",
+			Destination: &conf.Server.DebugImage,
+		},
+		&cli.DurationFlag{
+			Name:        "forward-ttl",
+			EnvVars:     util.EnvVars("forward-ttl"),
+			Usage:       "max time a URL forwarded to a port of a container is valid, 0 to disable port forwarding",
+			Destination: &conf.Server.ForwardTTL,
+		},
+		&cli.StringFlag{
+			Name:        "forward-url",
+			EnvVars:     util.EnvVars("forward-url"),
+			Usage:       "URL of a separate origin reaching this server the forwarded ports are served at, e.g. https://forward.example.com, empty to serve them sandboxed on the origin of the UI",
+			Destination: &conf.Server.ForwardURL,
+		},
+		&cli.DurationFlag{
+			Name:        "session-url-ttl",
+			EnvVars:     util.EnvVars("session-url-ttl"),
+			Usage:       "URLs of the containers are tokens of the container and the user valid for the TTL from the IP they're issued to, instead of the IDs, 0 to disable",
+			Destination: &conf.Server.SessionURLTTL,
+		},
+		&cli.StringFlag{
+			Name:        "session-url-secret",
+			EnvVars:     util.EnvVars("session-url-secret"),
+			Usage:       "secret of the exec URL tokens shared by the replicas, random if it's empty",
+			Destination: &conf.Server.SessionURLSecret,
+		},
+		&cli.DurationFlag{
+			Name:        "provision-ttl",
+			EnvVars:     util.EnvVars("provision-ttl"),
+			Usage:       "max time a session provisioned by the API waits to be joined, 0 to disable the API",
+			Value:       10 * time.Minute,
+			Destination: &conf.Server.ProvisionTTL,
+		},
+		&cli.StringFlag{
+			Name:        "ws-origin",
+			EnvVars:     util.EnvVars("ws-origin"),
+			Usage:       "regexp of the origins allowed to upgrade the websockets, e.g. '^https://(tty|ops)\\.example\\.com$', empty for the same origin only",
+			Destination: &conf.Server.WSOrigin,
+		},
+		&cli.BoolFlag{
+			Name:        "ws-origin-any",
+			EnvVars:     util.EnvVars("ws-origin-any"),
+			Usage:       "allow the websockets from any origin (not recommended, any site can open the terminals of its visitors)",
+			Destination: &conf.Server.WSOriginAny,
+		},
+		&cli.DurationFlag{
+			Name:        "ws-ping-interval",
+			EnvVars:     util.EnvVars("ws-ping-interval"),
+			Usage:       "interval of the pings to the browsers, 0 to disable",
+			Value:       30 * time.Second,
+			Destination: &conf.Server.WSPingInterval,
+		},
+		&cli.DurationFlag{
+			Name:        "ws-ping-timeout",
+			EnvVars:     util.EnvVars("ws-ping-timeout"),
+			Usage:       "close a browser connection if nothing is received in this time",
+			Value:       75 * time.Second,
+			Destination: &conf.Server.WSPingTimeout,
+		},
+		&cli.BoolFlag{
+			Name:        "ws-compression",
+			EnvVars:     util.EnvVars("ws-compression"),
+			Usage:       "negotiate permessage-deflate compression of the websockets",
+			Destination: &conf.Server.WSCompression,
+		},
+		&cli.IntFlag{
+			Name:        "ws-compression-level",
+			EnvVars:     util.EnvVars("ws-compression-level"),
+			Usage:       "compression level of the websockets, 1 (best speed) to 9 (best compression)",
+			Value:       1,
+			Destination: &conf.Server.WSCompressionLevel,
+		},
+		&cli.IntFlag{
+			Name:        "ws-compression-threshold",
+			EnvVars:     util.EnvVars("ws-compression-threshold"),
+			Usage:       "websocket messages shorter than this (bytes) are sent uncompressed",
+			Value:       512,
+			Destination: &conf.Server.WSCompressionThreshold,
+		},
+		&cli.IntFlag{
+			Name:        "ws-read-buffer",
+			EnvVars:     util.EnvVars("ws-read-buffer"),
+			Usage:       "read buffer size (bytes) of the websockets",
+			Value:       1024,
+			Destination: &conf.Server.WSReadBufferSize,
+		},
+		&cli.IntFlag{
+			Name:        "ws-write-buffer",
+			EnvVars:     util.EnvVars("ws-write-buffer"),
+			Usage:       "write buffer size (bytes) of the websockets",
+			Value:       1024,
+			Destination: &conf.Server.WSWriteBufferSize,
+		},
+		&cli.IntFlag{
+			Name:        "relay-buffer",
+			EnvVars:     util.EnvVars("relay-buffer"),
+			Usage:       "max bytes of the output read from a container at once",
+			Value:       1024,
+			Destination: &conf.Server.RelayBufferSize,
+		},
+		&cli.IntFlag{
+			Name:        "max-input-frame",
+			EnvVars:     util.EnvVars("max-input-frame"),
+			Usage:       "max bytes of an input message, the browsers split a larger paste into paced messages of it",
+			Value:       16 << 10,
+			Destination: &conf.Server.MaxInputFrame,
+		},
+		&cli.Int64Flag{
+			Name:        "max-body-size",
+			EnvVars:     util.EnvVars("max-body-size"),
+			Usage:       "max bytes of a request body, a larger one is refused with 413 (the forwarded requests are not limited), 0 for unlimited",
+			Value:       1 << 20,
+			Destination: &conf.Server.MaxBodySize,
+		},
+		&cli.IntFlag{
+			Name:        "max-header-size",
+			EnvVars:     util.EnvVars("max-header-size"),
+			Usage:       "max bytes of the request headers, larger ones are refused with 431",
+			Value:       64 << 10,
+			Destination: &conf.Server.MaxHeaderBytes,
+		},
+		&cli.Int64Flag{
+			Name:        "max-ws-message",
+			EnvVars:     util.EnvVars("max-ws-message"),
+			Usage:       "max bytes of a websocket message of the browsers, the websocket is closed with 1009 beyond it, 0 for unlimited",
+			Value:       1 << 20,
+			Destination: &conf.Server.MaxWSMessage,
+		},
+		&cli.DurationFlag{
+			Name:        "coalesce-window",
+			EnvVars:     util.EnvVars("coalesce-window"),
+			Usage:       "coalesce the output of a container into a message for the window, e.g. 5ms, 0 to disable",
+			Destination: &conf.Server.CoalesceWindow,
+		},
+		&cli.IntFlag{
+			Name:        "coalesce-size",
+			EnvVars:     util.EnvVars("coalesce-size"),
+			Usage:       "max bytes of the coalesced output, sent at once when it's reached",
+			Value:       8 << 10,
+			Destination: &conf.Server.CoalesceSize,
+		},
+		&cli.DurationFlag{
+			Name:        "backend-keepalive",
+			EnvVars:     util.EnvVars("backend-keepalive"),
+			Usage:       "keepalive interval of the docker exec streams and gRPC connections, 0 to disable",
+			Value:       30 * time.Second,
+			Destination: &conf.Backend.Keepalive,
+		},
+		&cli.DurationFlag{
+			Name:        "cache-ttl",
+			EnvVars:     util.EnvVars("cache-ttl"),
+			Usage:       "cache the containers and their details listed from the backend, refreshed in the background after the TTL, 0 to disable",
+			Destination: &conf.Backend.CacheTTL,
+		},
+		&cli.BoolFlag{
+			Name:        "lazy-backend",
+			EnvVars:     util.EnvVars("lazy-backend"),
+			Usage:       "connect to the backend in the background and serve at once, the requests wait for it",
+			Destination: &conf.Backend.Lazy,
+		},
+		&cli.StringFlag{
+			Name:        "exec-user",
+			EnvVars:     util.EnvVars("exec-user"),
+			Usage:       "exec in the containers as the user[:group] whatever the requested one, docker only",
+			Destination: &conf.Backend.ExecPolicy.User,
+		},
+		&cli.StringFlag{
+			Name:    "exec-user-image",
+			EnvVars: util.EnvVars("exec-user-image"),
+			Usage:   "exec in the containers of the images as the users, e.g. 'nginx=www-data,redis:6=999', use comma for split",
+		},
+		&cli.BoolFlag{
+			Name:        "exec-no-root",
+			EnvVars:     util.EnvVars("exec-no-root"),
+			Usage:       "refuse to exec as root or privileged, including the default user of the containers",
+			Destination: &conf.Backend.ExecPolicy.NoRoot,
+		},
+		&cli.IntFlag{
+			Name:        "batch-concurrency",
+			EnvVars:     util.EnvVars("batch-concurrency"),
+			Usage:       "max commands running at the same time of a batch run",
+			Value:       10,
+			Destination: &conf.Server.BatchConcurrency,
+		},
+		&cli.Int64Flag{
+			Name:        "transfer-max-size",
+			EnvVars:     util.EnvVars("transfer-max-size"),
+			Usage:       "max bytes of the files of an archive copied or downloaded from a container, 0 for unlimited",
+			Destination: &conf.Server.MaxTransferSize,
+		},
+		&cli.IntFlag{
+			Name:        "transfer-max-entries",
+			EnvVars:     util.EnvVars("transfer-max-entries"),
+			Usage:       "max entries of an archive copied or downloaded from a container, 0 for unlimited",
+			Destination: &conf.Server.MaxTransferEntries,
+		},
+		&cli.StringFlag{
+			Name:    "transfer-deny-paths",
+			EnvVars: util.EnvVars("transfer-deny-paths"),
+			Usage:   "paths of the containers never copied or downloaded, use comma for split",
+			Value:   "/proc,/sys,/dev",
+		},
+		&cli.StringFlag{
+			Name:    "transfer-deny-mime",
+			EnvVars: util.EnvVars("transfer-deny-mime"),
+			Usage:   "sniffed MIME types (or their prefixes) of the files never copied or downloaded, e.g. 'text/html,application/x-', use comma for split",
+		},
+		&cli.StringFlag{
+			Name:    "ready-checks",
+			EnvVars: util.EnvVars("ready-checks"),
+			Usage:   "checks of /readyz, 'backend', 'assets' and 'storage' (or 'redis'), use comma for split, empty to disable",
+			Value:   "backend,assets",
+		},
+		&cli.StringFlag{
+			Name:        "admin-token",
+			EnvVars:     util.EnvVars("admin-token"),
+			Usage:       "bearer token of the admin API and page (/admin.html) to prune the unused resources, empty to disable",
+			Destination: &conf.Server.AdminToken,
+		},
+		&cli.StringFlag{
+			Name:        "credential",
+			EnvVars:     util.EnvVars("credential"),
+			Usage:       "token of the terminals (the AuthToken of the init message) and of the one-shot, batch and copy APIs (X-Auth-Token), empty to disable",
+			Destination: &conf.Server.Credential,
+		},
+		&cli.DurationFlag{
+			Name:        "cookie-max-age",
+			EnvVars:     util.EnvVars("cookie-max-age"),
+			Usage:       "the admin sessions of the browsers (POST /api/admin/session) expire after it since their last use, 0 to disable them",
+			Value:       12 * time.Hour,
+			Destination: &conf.Server.Cookie.MaxAge,
+		},
+		&cli.StringFlag{
+			Name:        "cookie-name",
+			EnvVars:     util.EnvVars("cookie-name"),
+			Usage:       "name of the session cookie",
+			Value:       "web_tty_session",
+			Destination: &conf.Server.Cookie.Name,
+		},
+		&cli.StringFlag{
+			Name:        "cookie-secure",
+			EnvVars:     util.EnvVars("cookie-secure"),
+			Usage:       "the Secure of the session and CSRF cookies: auto (with TLS), always or never",
+			Value:       "auto",
+			Destination: &conf.Server.Cookie.Secure,
+		},
+		&cli.BoolFlag{
+			Name:        "cookie-http-only",
+			EnvVars:     util.EnvVars("cookie-http-only"),
+			Usage:       "the session cookie is HttpOnly, not readable by the scripts",
+			Value:       true,
+			Destination: &conf.Server.Cookie.HTTPOnly,
+		},
+		&cli.StringFlag{
+			Name:        "cookie-same-site",
+			EnvVars:     util.EnvVars("cookie-same-site"),
+			Usage:       "the SameSite of the session cookie: strict, lax or none",
+			Value:       "strict",
+			Destination: &conf.Server.Cookie.SameSite,
+		},
+		&cli.StringFlag{
+			Name:        "cookie-keys",
+			EnvVars:     util.EnvVars("cookie-keys"),
+			Usage:       "secrets of the session cookies separated by commas, the first seals them and any opens them, random if it's empty",
+			Destination: &conf.Server.Cookie.Keys,
+		},
+		&cli.IntFlag{
+			Name:        "login-challenge-after",
+			EnvVars:     util.EnvVars("login-challenge-after"),
+			Usage:       "failed admin logins of a client IP after which it solves a proof-of-work challenge before each try, 0 to disable",
+			Destination: &conf.Server.LoginChallengeAfter,
+		},
+		&cli.IntFlag{
+			Name:        "login-challenge-bits",
+			EnvVars:     util.EnvVars("login-challenge-bits"),
+			Usage:       "difficulty of the login challenge, the zero bits of its sha256, 1 to 32",
+			Value:       16,
+			Destination: &conf.Server.LoginChallengeBits,
+		},
 		&cli.BoolFlag{
 			Name:        "control-all",
 			Aliases:     []string{"ctl-a"},
@@ -121,6 +752,62 @@ func main() {
 			Usage:       "enable container stop   ",
 			Destination: &conf.Server.Control.Stop,
 		},
+		&cli.BoolFlag{
+			Name:        "control-browse",
+			Aliases:     []string{"ctl-b"},
+			EnvVars:     util.EnvVars("ctl-b"),
+			Usage:       "enable browsing and downloading the files of the volumes of containers",
+			Destination: &conf.Server.Control.Browse,
+		},
+		&cli.BoolFlag{
+			Name:        "control-commit",
+			Aliases:     []string{"ctl-c"},
+			EnvVars:     util.EnvVars("ctl-c"),
+			Usage:       "enable committing containers to images, not enabled by --control-all",
+			Destination: &conf.Server.Control.Commit,
+		},
+		&cli.BoolFlag{
+			Name:        "control-copy",
+			Aliases:     []string{"ctl-y"},
+			EnvVars:     util.EnvVars("ctl-y"),
+			Usage:       "enable copying paths between containers",
+			Destination: &conf.Server.Control.Copy,
+		},
+		&cli.BoolFlag{
+			Name:        "control-create",
+			Aliases:     []string{"ctl-n"},
+			EnvVars:     util.EnvVars("ctl-n"),
+			Usage:       "enable creating and starting containers on /run.html, requires --admin-token, not enabled by --control-all",
+			Destination: &conf.Server.Control.Create,
+		},
+		&cli.BoolFlag{
+			Name:        "control-debug",
+			Aliases:     []string{"ctl-d"},
+			EnvVars:     util.EnvVars("ctl-d"),
+			Usage:       "enable launching debug containers sharing the namespaces of containers, not enabled by --control-all",
+			Destination: &conf.Server.Control.Debug,
+		},
+		&cli.BoolFlag{
+			Name:        "control-edit",
+			Aliases:     []string{"ctl-e"},
+			EnvVars:     util.EnvVars("ctl-e"),
+			Usage:       "enable container rename and label editing",
+			Destination: &conf.Server.Control.Edit,
+		},
+		&cli.BoolFlag{
+			Name:        "control-kill",
+			Aliases:     []string{"ctl-k"},
+			EnvVars:     util.EnvVars("ctl-k"),
+			Usage:       "enable container kill with a signal",
+			Destination: &conf.Server.Control.Kill,
+		},
+		&cli.BoolFlag{
+			Name:        "control-pause",
+			Aliases:     []string{"ctl-p"},
+			EnvVars:     util.EnvVars("ctl-p"),
+			Usage:       "enable container pause and unpause",
+			Destination: &conf.Server.Control.Pause,
+		},
 		&cli.BoolFlag{
 			Name:        "control-restart",
 			Aliases:     []string{"ctl-r"},
@@ -135,6 +822,88 @@ func main() {
 			Usage:       "enable share the container's terminal",
 			Destination: &conf.Server.EnableShare,
 		},
+		&cli.BoolFlag{
+			Name:        "enable-graphql",
+			EnvVars:     util.EnvVars("enable-graphql"),
+			Usage:       "enable the GraphQL endpoint /api/graphql",
+			Destination: &conf.Server.EnableGraphQL,
+		},
+		&cli.BoolFlag{
+			Name:        "enable-zmodem",
+			EnvVars:     util.EnvVars("enable-zmodem"),
+			Usage:       "enable the ZMODEM file transfers of the terminals, sz in a container downloads the files and rz uploads them (lrzsz must be installed in it), not limited by the transfer options",
+			Destination: &conf.Server.EnableZmodem,
+		},
+		&cli.StringFlag{
+			Name:        "clipboard",
+			EnvVars:     util.EnvVars("clipboard"),
+			Usage:       "OSC 52 sequences of the terminals setting the clipboards of the browsers: off (dropped), on, or ask (confirmed by the users)",
+			Value:       "off",
+			Destination: &conf.Server.Clipboard,
+		},
+		&cli.StringFlag{
+			Name:    "clipboard-user",
+			EnvVars: util.EnvVars("clipboard-user"),
+			Usage:   "OSC 52 policies of the exec users overriding --clipboard, e.g. 'root=off,dev=on', use comma for split",
+		},
+		&cli.IntFlag{
+			Name:        "clipboard-max-size",
+			EnvVars:     util.EnvVars("clipboard-max-size"),
+			Usage:       "max bytes of a text copied by OSC 52, the longer ones are dropped",
+			Value:       1 << 20,
+			Destination: &conf.Server.ClipboardMaxSize,
+		},
+		&cli.IntFlag{
+			Name:        "max-conn",
+			EnvVars:     util.EnvVars("max-conn"),
+			Usage:       "max terminal connections of the server, 0 for unlimited",
+			Destination: &conf.Server.MaxConnection,
+		},
+		&cli.IntFlag{
+			Name:        "max-conn-per-ip",
+			EnvVars:     util.EnvVars("max-conn-per-ip"),
+			Usage:       "max terminal connections of a client IP, 0 for unlimited",
+			Destination: &conf.Server.MaxConnectionPerIP,
+		},
+		&cli.DurationFlag{
+			Name:        "init-timeout",
+			EnvVars:     util.EnvVars("init-timeout"),
+			Usage:       "max time to get the request headers and the init message of a new connection, 0 to wait forever",
+			Value:       10 * time.Second,
+			Destination: &conf.Server.InitTimeout,
+		},
+		&cli.StringFlag{
+			Name:        "mask-env",
+			EnvVars:     util.EnvVars("mask-env"),
+			Usage:       "regexp of the env names whose values are hidden in the container detail, empty to show all",
+			Value:       "(?i)PASSWORD|SECRET|TOKEN|KEY",
+			Destination: &conf.Server.MaskEnv,
+		},
+		&cli.BoolFlag{
+			Name:        "reveal-secrets",
+			EnvVars:     util.EnvVars("reveal-secrets"),
+			Usage:       "allow revealing the masked env of the container detail",
+			Destination: &conf.Server.RevealSecrets,
+		},
+		&cli.StringFlag{
+			Name:        "embed-origin",
+			EnvVars:     util.EnvVars("embed-origin"),
+			Usage:       "regexp of the parent origins allowed to use the postMessage API of an embedded terminal",
+			Destination: &conf.Server.EmbedOrigin,
+		},
+		&cli.StringFlag{
+			Name:        "frame-ancestors",
+			EnvVars:     util.EnvVars("frame-ancestors"),
+			Usage:       "CSP frame-ancestors of the pages, e.g. 'https://app.example.com', empty for 'self' (or any with --embed-origin)",
+			Destination: &conf.Server.FrameAncestors,
+		},
+		&cli.DurationFlag{
+			Name:        "hsts-max-age",
+			EnvVars:     util.EnvVars("hsts-max-age"),
+			Usage:       "max-age of the Strict-Transport-Security header sent with TLS, 0 to not send it",
+			Value:       365 * 24 * time.Hour,
+			Destination: &conf.Server.HSTSMaxAge,
+		},
 		&cli.BoolFlag{
 			Name:        "enable-audit",
 			Aliases:     []string{"audit"},
@@ -149,6 +918,29 @@ func main() {
 			Usage:       "container audit log dir path",
 			Destination: &conf.Server.AuditLogDir,
 		},
+		&cli.StringFlag{
+			Name:        "journal-dir",
+			EnvVars:     util.EnvVars("journal-dir"),
+			Usage:       "dir of the journal of the server events kept across restarts, empty to disable",
+			Destination: &conf.Server.JournalDir,
+		},
+		&cli.Int64Flag{
+			Name:        "journal-max-size",
+			EnvVars:     util.EnvVars("journal-max-size"),
+			Value:       64 << 20,
+			Usage:       "max bytes of the journal, the oldest events are dropped beyond it",
+			Destination: &conf.Server.JournalMaxSize,
+		},
+		&cli.StringFlag{
+			Name:    "config",
+			EnvVars: util.EnvVars("config"),
+			Usage:   "YAML (or JSON) file of the options by their names, the args and the env take precedence over it",
+		},
+		&cli.BoolFlag{
+			Name:    "config-watch",
+			EnvVars: util.EnvVars("config-watch"),
+			Usage:   "reload the config once the file of --config is changed, as on SIGHUP",
+		},
 		&cli.BoolFlag{
 			Name:    "help",
 			Aliases: []string{"h"},
@@ -156,52 +948,120 @@ func main() {
 		},
 	}
 
-	sort.Sort(cli.FlagsByName(appFlags))
+	flags = append(flags, secretFileFlags(conf)...)
+	sort.Sort(cli.FlagsByName(flags))
+	return flags
+}
 
+func main() {
+	conf := config.New()
 	app := &cli.App{
 		Name:      "container-web-tty",
 		Usage:     "connect your containers via a web-tty",
-		UsageText: "container-web-tty [global options]",
-		Flags:     appFlags,
+		UsageText: "container-web-tty [global options] [command [command options]]",
+		Flags:     appFlags(conf),
 		HideHelp:  true,
 		Authors:   author,
 		Version: fmt.Sprintf("version: %s\tcommit: %s\tdate: %s",
 			Version, CommitID, BuildAt),
-		Action: func(c *cli.Context) error {
-			if c.Bool("help") {
-				return cli.ShowAppHelp(c)
-			}
-			// parse idleTime
-			t := c.String("idle-time")
-			idleTime, err := time.ParseDuration(t)
-			if err != nil && t != "" {
-				logrus.Fatalf("parse idle-time error: %s", err)
-			} else {
-				conf.Server.IdleTime = idleTime
-			}
+		Action:   serve(conf),
+		Commands: commands(conf),
+	}
 
-			// defaultArgs := "-e HISTCONTROL=ignoredups -e TERM=xterm"
+	app.Run(os.Args)
+}
 
-			ctl := conf.Server.Control
-			if ctl.Start || ctl.Stop || ctl.Restart || ctl.All {
-				conf.Server.Control.Enable = true
-			}
+// setup sets the config of the options, the config file of the path
+// fills the ones not set by the args or the env
+func setup(c *cli.Context, conf *config.Config, path string) error {
+	fromFile, err := loadConfigFile(c, path)
+	if err != nil {
+		return err
+	}
+	// parse idleTime
+	t := c.String("idle-time")
+	idleTime, err := time.ParseDuration(t)
+	if err != nil && t != "" {
+		return fmt.Errorf("parse idle-time error: %s", err)
+	}
+	conf.Server.IdleTime = idleTime
+
+	// defaultArgs := "-e HISTCONTROL=ignoredups -e TERM=xterm"
+
+	ctl := conf.Server.Control
+	if ctl.Start || ctl.Stop || ctl.Restart || ctl.Pause || ctl.Kill || ctl.Edit || ctl.Copy || ctl.Browse || ctl.Commit || ctl.Debug || ctl.All {
+		conf.Server.Control.Enable = true
+	}
 
-			servers := strings.Split(c.String("grpc-servers"), ",")
-			if servers[0] != "" {
-				conf.Backend.GRPC.Servers = servers
+	conf.Server.ReplayBuffer = c.Int("replay-buffer") << 10
+
+	conf.Backend.Docker.Keepalive = conf.Backend.Keepalive
+	conf.Backend.GRPC.Keepalive = conf.Backend.Keepalive
+	if users := c.String("exec-user-image"); users != "" {
+		conf.Backend.ExecPolicy.ImageUsers = make(map[string]string)
+		for _, pair := range strings.Split(users, ",") {
+			kv := strings.SplitN(pair, "=", 2)
+			if len(kv) != 2 || kv[0] == "" || kv[1] == "" {
+				return fmt.Errorf("bad exec-user-image %s", pair)
 			}
-			if conf.Debug {
-				logrus.SetLevel(logrus.DebugLevel)
-			} else {
-				gin.SetMode(gin.ReleaseMode)
+			conf.Backend.ExecPolicy.ImageUsers[kv[0]] = kv[1]
+		}
+	}
+	conf.Backend.Docker.ExecPolicy = conf.Backend.ExecPolicy
+	if users := c.String("clipboard-user"); users != "" {
+		conf.Server.ClipboardUsers = make(map[string]string)
+		for _, pair := range strings.Split(users, ",") {
+			kv := strings.SplitN(pair, "=", 2)
+			if len(kv) != 2 || kv[0] == "" || kv[1] == "" {
+				return fmt.Errorf("bad clipboard-user %s", pair)
 			}
-			logrus.Debugf("got config: %+v", conf)
+			conf.Server.ClipboardUsers[kv[0]] = kv[1]
+		}
+	}
 
-			run(c, *conf)
-			return nil
-		},
+	servers := strings.Split(c.String("grpc-servers"), ",")
+	if servers[0] != "" {
+		conf.Backend.GRPC.Servers = servers
+	}
+	if domains := c.String("domain"); domains != "" {
+		conf.Server.Domains = strings.Split(domains, ",")
+	}
+	if listen := c.String("listen"); listen != "" {
+		conf.Server.Listen = strings.Split(listen, ",")
+	}
+	if proxies := c.String("trusted-proxies"); proxies != "" {
+		conf.Server.TrustedProxies = strings.Split(proxies, ",")
+	}
+	if c.Bool("tls-modern") && conf.Server.TLSProfile == "" {
+		conf.Server.TLSProfile = "modern"
 	}
+	if paths := c.String("transfer-deny-paths"); paths != "" {
+		conf.Server.TransferDenyPaths = strings.Split(paths, ",")
+	}
+	if mimes := c.String("transfer-deny-mime"); mimes != "" {
+		conf.Server.TransferDenyMIME = strings.Split(mimes, ",")
+	}
+	if paths := c.String("access-log-skip"); paths != "" {
+		conf.Server.AccessLog.SkipPaths = strings.Split(paths, ",")
+	}
+	if checks := c.String("ready-checks"); checks != "" {
+		conf.Server.ReadyChecks = strings.Split(checks, ",")
+	}
+	if conf.Debug {
+		conf.Log.Level = "debug"
+	} else {
+		gin.SetMode(gin.ReleaseMode)
+	}
+	if err := logging.Setup(conf.Log.Format, conf.Log.Level, conf.Log.Modules); err != nil {
+		return err
+	}
+	// the routes of the debug mode
+	gin.DefaultWriter = ginLog.WriterLevel(logrus.DebugLevel)
+	gin.DefaultErrorWriter = ginLog.WriterLevel(logrus.ErrorLevel)
 
-	app.Run(os.Args)
+	if err := readSecrets(c, conf, fromFile); err != nil {
+		return err
+	}
+	conf.Server.Effective = effectiveOptions(c, conf, fromFile)
+	return nil
 }
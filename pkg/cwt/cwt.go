@@ -0,0 +1,146 @@
+// Package cwt embeds container-web-tty in other Go programs: the list of
+// the containers, the terminals and the API are an http.Handler mounted on
+// a mux of the program, with the backend and the auth of it.
+//
+// The API of this package is kept stable across the releases, the other
+// packages of the module are internal to the server and may change.
+package cwt
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/wrfly/ecp"
+
+	"github.com/wrfly/container-web-tty/config"
+	"github.com/wrfly/container-web-tty/container"
+	"github.com/wrfly/container-web-tty/event"
+	"github.com/wrfly/container-web-tty/route"
+)
+
+// Backend is the backend of the containers, a backend of the program
+// embeds Unimplemented and implements the methods it supports.
+type Backend = container.Cli
+
+// Unimplemented fails all the methods of a Backend but List, GetInfo, Ping,
+// Version and Close, which return nothing.
+type Unimplemented = container.Unimplemented
+
+// ErrUnimplemented is the error of the methods of Unimplemented.
+var ErrUnimplemented = container.ErrUnimplemented
+
+// Authenticator authenticates all the requests, it returns whether the
+// request is of an admin (allowed to the admin API), or an error to deny
+// it with 401.
+type Authenticator = route.Authenticator
+
+// Config is the config of the server, the options of the flags of
+// container-web-tty. The zero fields are of the defaults of the flags.
+type Config = config.ServerConfig
+
+// BackendConfig is the config of the docker, kube or grpc backend.
+type BackendConfig = config.BackendConfig
+
+// Options are the options of a Server.
+type Options struct {
+	// BasePath is the path the Handler is mounted at, e.g. /tty, it's
+	// the one of Config if empty
+	BasePath string
+	// Authenticate authenticates the requests, nil to leave them to
+	// the program (e.g. a middleware of its mux)
+	Authenticate Authenticator
+	// Backend is the backend of the program, the one of BackendConfig
+	// is created if it's nil
+	Backend       Backend
+	BackendConfig BackendConfig
+	// Config is the other options of the server
+	Config Config
+}
+
+// Server is an embedded container-web-tty.
+type Server struct {
+	srv     *route.Server
+	handler http.Handler
+	backend Backend
+	// the backend is closed with the Server if it's created by New
+	owned bool
+}
+
+// New creates a Server of the options.
+func New(opts Options) (*Server, error) {
+	conf := opts.Config
+	if err := ecp.Default(&conf); err != nil {
+		return nil, err
+	}
+	if opts.BasePath != "" {
+		conf.BasePath = opts.BasePath
+	}
+
+	events := event.NewHub()
+	backend, owned := opts.Backend, false
+	if backend == nil {
+		backendConf := opts.BackendConfig
+		if err := ecp.Default(&backendConf); err != nil {
+			return nil, err
+		}
+		var err error
+		if backend, err = container.NewCliBackend(backendConf, events); err != nil {
+			return nil, err
+		}
+		owned = true
+	}
+	srv, err := route.New(backend, events, conf)
+	if err != nil {
+		if owned {
+			backend.Close()
+		}
+		return nil, err
+	}
+
+	runOptions := []route.RunOption{route.WithoutGinLogger()}
+	if opts.Authenticate != nil {
+		runOptions = append(runOptions, route.WithAuth(opts.Authenticate))
+	}
+	return &Server{
+		srv:     srv,
+		handler: srv.Handler(runOptions...),
+		backend: backend,
+		owned:   owned,
+	}, nil
+}
+
+// Handler is the handler of all the routes under the BasePath, mounted as
+// is (the path is not stripped), e.g. mux.Handle("/tty/", s.Handler()).
+func (s *Server) Handler() http.Handler { return s.handler }
+
+// Start runs the background work of the Server until the ctx is done.
+func (s *Server) Start(ctx context.Context) { s.srv.Start(ctx) }
+
+// Reload applies the reloadable options of the config to the new requests
+// and sessions.
+func (s *Server) Reload(conf Config) error {
+	if err := ecp.Default(&conf); err != nil {
+		return err
+	}
+	return s.srv.Reload(conf)
+}
+
+// Drain stops accepting new sessions and closes the running ones after the
+// timeout, it returns false if the Server is draining already.
+func (s *Server) Drain(timeout time.Duration) bool { return s.srv.Drain(timeout) }
+
+// SetMaintenance refuses the new sessions with the message while it's
+// enabled, the running sessions and the pages are left as they are
+func (s *Server) SetMaintenance(enabled bool, message string) {
+	s.srv.SetMaintenance(enabled, message)
+}
+
+// Close closes the backend created by New, a Backend of the program is
+// left to it.
+func (s *Server) Close() error {
+	if s.owned {
+		return s.backend.Close()
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package cwt
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/wrfly/container-web-tty/types"
+)
+
+type testBackend struct {
+	Unimplemented
+}
+
+func (testBackend) List(ctx context.Context) []types.Container {
+	return []types.Container{{ID: "abc", Name: "web", Image: "nginx", IPs: []string{"10.0.0.2"}}}
+}
+
+func TestHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	srv, err := New(Options{
+		BasePath: "/tty",
+		Authenticate: func(r *http.Request) (bool, error) {
+			switch r.Header.Get("X-User") {
+			case "":
+				return false, errors.New("login required")
+			case "ops":
+				return true, nil
+			}
+			return false, nil
+		},
+		Backend: testBackend{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	mux := http.NewServeMux()
+	mux.Handle("/tty/", srv.Handler())
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("the program")) })
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	get := func(path, user string) (int, string) {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		if user != "" {
+			req.Header.Set("X-User", user)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+	if code, _ := get("/tty/", ""); code != http.StatusUnauthorized {
+		t.Fatalf("expect 401 without the user, got %d", code)
+	}
+	if code, body := get("/tty/", "dev"); code != http.StatusOK || !strings.Contains(body, "nginx") {
+		t.Fatalf("unexpected list: %d %s", code, body)
+	}
+	// the admin API of the admins, without the admin token
+	if code, _ := get("/tty/api/admin/errors", "dev"); code != http.StatusForbidden {
+		t.Fatalf("expect 403 of the admin API, got %d", code)
+	}
+	if code, _ := get("/tty/api/admin/errors", "ops"); code != http.StatusOK {
+		t.Fatalf("expect the admin API of the admins, got %d", code)
+	}
+	// the unsupported methods report the error
+	if code, body := get("/tty/api/containers/abc/detail", "dev"); code == http.StatusOK || !strings.Contains(body, ErrUnimplemented.Error()) {
+		t.Fatalf("unexpected detail: %d %s", code, body)
+	}
+	if _, body := get("/other", ""); body != "the program" {
+		t.Fatalf("unexpected route of the program: %s", body)
+	}
+}
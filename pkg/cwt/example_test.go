@@ -0,0 +1,54 @@
+package cwt_test
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/wrfly/container-web-tty/pkg/cwt"
+	"github.com/wrfly/container-web-tty/types"
+)
+
+// staticBackend lists a fixed container, the terminals of it are not
+// supported
+type staticBackend struct {
+	cwt.Unimplemented
+}
+
+func (staticBackend) List(ctx context.Context) []types.Container {
+	return []types.Container{{ID: "web", Name: "web", Image: "nginx", Status: "running", IPs: []string{"10.0.0.2"}}}
+}
+
+func (b staticBackend) GetInfo(ctx context.Context, containerID string) types.Container {
+	for _, c := range b.List(ctx) {
+		if c.ID == containerID {
+			return c
+		}
+	}
+	return types.Container{}
+}
+
+func Example() {
+	srv, err := cwt.New(cwt.Options{
+		BasePath: "/tty",
+		// the users of the program, the ops are the admins
+		Authenticate: func(r *http.Request) (bool, error) {
+			user, _, ok := r.BasicAuth()
+			if !ok {
+				return false, errors.New("login required")
+			}
+			return user == "ops", nil
+		},
+		Backend: staticBackend{},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer srv.Close()
+	srv.Start(context.Background())
+
+	mux := http.NewServeMux()
+	mux.Handle("/tty/", srv.Handler())
+	log.Fatal(http.ListenAndServe("127.0.0.1:8080", mux))
+}
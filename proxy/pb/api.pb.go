@@ -1,33 +1,14 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // source: api.proto
 
-/*
-Package pbrpc is a generated protocol buffer package.
-
-It is generated from these files:
-	api.proto
-
-It has these top-level messages:
-	Empty
-	Pong
-	Err
-	ContainerID
-	LogOpts
-	Container
-	Containers
-	Io
-	WindowSize
-	ExecOptions
-*/
 package pbrpc
 
-import proto "github.com/golang/protobuf/proto"
-import fmt "fmt"
-import math "math"
-
 import (
-	context "golang.org/x/net/context"
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
 	grpc "google.golang.org/grpc"
+	math "math"
 )
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -39,16 +20,39 @@ var _ = math.Inf
 // is compatible with the proto package it is being compiled against.
 // A compilation error at this line likely means your copy of the
 // proto package needs to be updated.
-const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
 type Empty struct {
-	Auth string `protobuf:"bytes,1,opt,name=auth" json:"auth,omitempty"`
+	Auth                 string   `protobuf:"bytes,1,opt,name=auth,proto3" json:"auth,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Empty) Reset()         { *m = Empty{} }
+func (m *Empty) String() string { return proto.CompactTextString(m) }
+func (*Empty) ProtoMessage()    {}
+func (*Empty) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{0}
+}
+
+func (m *Empty) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Empty.Unmarshal(m, b)
+}
+func (m *Empty) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Empty.Marshal(b, m, deterministic)
+}
+func (m *Empty) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Empty.Merge(m, src)
+}
+func (m *Empty) XXX_Size() int {
+	return xxx_messageInfo_Empty.Size(m)
+}
+func (m *Empty) XXX_DiscardUnknown() {
+	xxx_messageInfo_Empty.DiscardUnknown(m)
 }
 
-func (m *Empty) Reset()                    { *m = Empty{} }
-func (m *Empty) String() string            { return proto.CompactTextString(m) }
-func (*Empty) ProtoMessage()               {}
-func (*Empty) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+var xxx_messageInfo_Empty proto.InternalMessageInfo
 
 func (m *Empty) GetAuth() string {
 	if m != nil {
@@ -58,13 +62,36 @@ func (m *Empty) GetAuth() string {
 }
 
 type Pong struct {
-	Msg string `protobuf:"bytes,1,opt,name=msg" json:"msg,omitempty"`
+	Msg                  string   `protobuf:"bytes,1,opt,name=msg,proto3" json:"msg,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Pong) Reset()         { *m = Pong{} }
+func (m *Pong) String() string { return proto.CompactTextString(m) }
+func (*Pong) ProtoMessage()    {}
+func (*Pong) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{1}
+}
+
+func (m *Pong) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Pong.Unmarshal(m, b)
+}
+func (m *Pong) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Pong.Marshal(b, m, deterministic)
+}
+func (m *Pong) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Pong.Merge(m, src)
+}
+func (m *Pong) XXX_Size() int {
+	return xxx_messageInfo_Pong.Size(m)
+}
+func (m *Pong) XXX_DiscardUnknown() {
+	xxx_messageInfo_Pong.DiscardUnknown(m)
 }
 
-func (m *Pong) Reset()                    { *m = Pong{} }
-func (m *Pong) String() string            { return proto.CompactTextString(m) }
-func (*Pong) ProtoMessage()               {}
-func (*Pong) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+var xxx_messageInfo_Pong proto.InternalMessageInfo
 
 func (m *Pong) GetMsg() string {
 	if m != nil {
@@ -74,13 +101,36 @@ func (m *Pong) GetMsg() string {
 }
 
 type Err struct {
-	Err string `protobuf:"bytes,1,opt,name=err" json:"err,omitempty"`
+	Err                  string   `protobuf:"bytes,1,opt,name=err,proto3" json:"err,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Err) Reset()         { *m = Err{} }
+func (m *Err) String() string { return proto.CompactTextString(m) }
+func (*Err) ProtoMessage()    {}
+func (*Err) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{2}
+}
+
+func (m *Err) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Err.Unmarshal(m, b)
+}
+func (m *Err) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Err.Marshal(b, m, deterministic)
+}
+func (m *Err) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Err.Merge(m, src)
+}
+func (m *Err) XXX_Size() int {
+	return xxx_messageInfo_Err.Size(m)
+}
+func (m *Err) XXX_DiscardUnknown() {
+	xxx_messageInfo_Err.DiscardUnknown(m)
 }
 
-func (m *Err) Reset()                    { *m = Err{} }
-func (m *Err) String() string            { return proto.CompactTextString(m) }
-func (*Err) ProtoMessage()               {}
-func (*Err) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+var xxx_messageInfo_Err proto.InternalMessageInfo
 
 func (m *Err) GetErr() string {
 	if m != nil {
@@ -90,14 +140,37 @@ func (m *Err) GetErr() string {
 }
 
 type ContainerID struct {
-	Id   string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
-	Auth string `protobuf:"bytes,2,opt,name=auth" json:"auth,omitempty"`
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Auth                 string   `protobuf:"bytes,2,opt,name=auth,proto3" json:"auth,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ContainerID) Reset()         { *m = ContainerID{} }
+func (m *ContainerID) String() string { return proto.CompactTextString(m) }
+func (*ContainerID) ProtoMessage()    {}
+func (*ContainerID) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{3}
+}
+
+func (m *ContainerID) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ContainerID.Unmarshal(m, b)
+}
+func (m *ContainerID) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ContainerID.Marshal(b, m, deterministic)
+}
+func (m *ContainerID) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ContainerID.Merge(m, src)
+}
+func (m *ContainerID) XXX_Size() int {
+	return xxx_messageInfo_ContainerID.Size(m)
+}
+func (m *ContainerID) XXX_DiscardUnknown() {
+	xxx_messageInfo_ContainerID.DiscardUnknown(m)
 }
 
-func (m *ContainerID) Reset()                    { *m = ContainerID{} }
-func (m *ContainerID) String() string            { return proto.CompactTextString(m) }
-func (*ContainerID) ProtoMessage()               {}
-func (*ContainerID) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+var xxx_messageInfo_ContainerID proto.InternalMessageInfo
 
 func (m *ContainerID) GetId() string {
 	if m != nil {
@@ -113,549 +186,2717 @@ func (m *ContainerID) GetAuth() string {
 	return ""
 }
 
-type LogOpts struct {
-	C      *ContainerID `protobuf:"bytes,1,opt,name=c" json:"c,omitempty"`
-	Follow bool         `protobuf:"varint,2,opt,name=follow" json:"follow,omitempty"`
-	Tail   string       `protobuf:"bytes,3,opt,name=tail" json:"tail,omitempty"`
+type KillOpts struct {
+	C                    *ContainerID `protobuf:"bytes,1,opt,name=c,proto3" json:"c,omitempty"`
+	Signal               string       `protobuf:"bytes,2,opt,name=signal,proto3" json:"signal,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
 }
 
-func (m *LogOpts) Reset()                    { *m = LogOpts{} }
-func (m *LogOpts) String() string            { return proto.CompactTextString(m) }
-func (*LogOpts) ProtoMessage()               {}
-func (*LogOpts) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+func (m *KillOpts) Reset()         { *m = KillOpts{} }
+func (m *KillOpts) String() string { return proto.CompactTextString(m) }
+func (*KillOpts) ProtoMessage()    {}
+func (*KillOpts) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{4}
+}
 
-func (m *LogOpts) GetC() *ContainerID {
+func (m *KillOpts) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_KillOpts.Unmarshal(m, b)
+}
+func (m *KillOpts) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_KillOpts.Marshal(b, m, deterministic)
+}
+func (m *KillOpts) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_KillOpts.Merge(m, src)
+}
+func (m *KillOpts) XXX_Size() int {
+	return xxx_messageInfo_KillOpts.Size(m)
+}
+func (m *KillOpts) XXX_DiscardUnknown() {
+	xxx_messageInfo_KillOpts.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_KillOpts proto.InternalMessageInfo
+
+func (m *KillOpts) GetC() *ContainerID {
 	if m != nil {
 		return m.C
 	}
 	return nil
 }
 
-func (m *LogOpts) GetFollow() bool {
+func (m *KillOpts) GetSignal() string {
 	if m != nil {
-		return m.Follow
+		return m.Signal
 	}
-	return false
+	return ""
 }
 
-func (m *LogOpts) GetTail() string {
+type Process struct {
+	Fields               []string `protobuf:"bytes,1,rep,name=fields,proto3" json:"fields,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Process) Reset()         { *m = Process{} }
+func (m *Process) String() string { return proto.CompactTextString(m) }
+func (*Process) ProtoMessage()    {}
+func (*Process) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{5}
+}
+
+func (m *Process) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Process.Unmarshal(m, b)
+}
+func (m *Process) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Process.Marshal(b, m, deterministic)
+}
+func (m *Process) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Process.Merge(m, src)
+}
+func (m *Process) XXX_Size() int {
+	return xxx_messageInfo_Process.Size(m)
+}
+func (m *Process) XXX_DiscardUnknown() {
+	xxx_messageInfo_Process.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Process proto.InternalMessageInfo
+
+func (m *Process) GetFields() []string {
 	if m != nil {
-		return m.Tail
+		return m.Fields
 	}
-	return ""
+	return nil
 }
 
-// Container instance
-type Container struct {
-	Id            string   `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
-	Name          string   `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
-	Image         string   `protobuf:"bytes,3,opt,name=image" json:"image,omitempty"`
-	Command       string   `protobuf:"bytes,4,opt,name=command" json:"command,omitempty"`
-	State         string   `protobuf:"bytes,5,opt,name=state" json:"state,omitempty"`
-	Status        string   `protobuf:"bytes,6,opt,name=status" json:"status,omitempty"`
-	Ips           []string `protobuf:"bytes,7,rep,name=ips" json:"ips,omitempty"`
-	Shell         string   `protobuf:"bytes,8,opt,name=shell" json:"shell,omitempty"`
-	PodName       string   `protobuf:"bytes,9,opt,name=pod_name,json=podName" json:"pod_name,omitempty"`
-	ContainerName string   `protobuf:"bytes,10,opt,name=container_name,json=containerName" json:"container_name,omitempty"`
-	Namespace     string   `protobuf:"bytes,11,opt,name=namespace" json:"namespace,omitempty"`
-	RunningNode   string   `protobuf:"bytes,12,opt,name=running_node,json=runningNode" json:"running_node,omitempty"`
-	LocServer     string   `protobuf:"bytes,13,opt,name=loc_server,json=locServer" json:"loc_server,omitempty"`
-	ExecCmd       string   `protobuf:"bytes,14,opt,name=execCmd" json:"execCmd,omitempty"`
-	ExecUser      string   `protobuf:"bytes,15,opt,name=execUser" json:"execUser,omitempty"`
-	ExecEnv       string   `protobuf:"bytes,16,opt,name=execEnv" json:"execEnv,omitempty"`
-}
-
-func (m *Container) Reset()                    { *m = Container{} }
-func (m *Container) String() string            { return proto.CompactTextString(m) }
-func (*Container) ProtoMessage()               {}
-func (*Container) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+type Processes struct {
+	Titles               []string   `protobuf:"bytes,1,rep,name=titles,proto3" json:"titles,omitempty"`
+	Ps                   []*Process `protobuf:"bytes,2,rep,name=ps,proto3" json:"ps,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
 
-func (m *Container) GetId() string {
+func (m *Processes) Reset()         { *m = Processes{} }
+func (m *Processes) String() string { return proto.CompactTextString(m) }
+func (*Processes) ProtoMessage()    {}
+func (*Processes) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{6}
+}
+
+func (m *Processes) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Processes.Unmarshal(m, b)
+}
+func (m *Processes) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Processes.Marshal(b, m, deterministic)
+}
+func (m *Processes) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Processes.Merge(m, src)
+}
+func (m *Processes) XXX_Size() int {
+	return xxx_messageInfo_Processes.Size(m)
+}
+func (m *Processes) XXX_DiscardUnknown() {
+	xxx_messageInfo_Processes.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Processes proto.InternalMessageInfo
+
+func (m *Processes) GetTitles() []string {
 	if m != nil {
-		return m.Id
+		return m.Titles
 	}
-	return ""
+	return nil
 }
 
-func (m *Container) GetName() string {
+func (m *Processes) GetPs() []*Process {
 	if m != nil {
-		return m.Name
+		return m.Ps
 	}
-	return ""
+	return nil
 }
 
-func (m *Container) GetImage() string {
+type EnvVar struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Value                string   `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *EnvVar) Reset()         { *m = EnvVar{} }
+func (m *EnvVar) String() string { return proto.CompactTextString(m) }
+func (*EnvVar) ProtoMessage()    {}
+func (*EnvVar) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{7}
+}
+
+func (m *EnvVar) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EnvVar.Unmarshal(m, b)
+}
+func (m *EnvVar) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EnvVar.Marshal(b, m, deterministic)
+}
+func (m *EnvVar) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EnvVar.Merge(m, src)
+}
+func (m *EnvVar) XXX_Size() int {
+	return xxx_messageInfo_EnvVar.Size(m)
+}
+func (m *EnvVar) XXX_DiscardUnknown() {
+	xxx_messageInfo_EnvVar.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EnvVar proto.InternalMessageInfo
+
+func (m *EnvVar) GetName() string {
 	if m != nil {
-		return m.Image
+		return m.Name
 	}
 	return ""
 }
 
-func (m *Container) GetCommand() string {
+func (m *EnvVar) GetValue() string {
 	if m != nil {
-		return m.Command
+		return m.Value
 	}
 	return ""
 }
 
-func (m *Container) GetState() string {
+type Mount struct {
+	Type                 string   `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Source               string   `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	Destination          string   `protobuf:"bytes,3,opt,name=destination,proto3" json:"destination,omitempty"`
+	ReadOnly             bool     `protobuf:"varint,4,opt,name=readOnly,proto3" json:"readOnly,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Mount) Reset()         { *m = Mount{} }
+func (m *Mount) String() string { return proto.CompactTextString(m) }
+func (*Mount) ProtoMessage()    {}
+func (*Mount) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{8}
+}
+
+func (m *Mount) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Mount.Unmarshal(m, b)
+}
+func (m *Mount) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Mount.Marshal(b, m, deterministic)
+}
+func (m *Mount) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Mount.Merge(m, src)
+}
+func (m *Mount) XXX_Size() int {
+	return xxx_messageInfo_Mount.Size(m)
+}
+func (m *Mount) XXX_DiscardUnknown() {
+	xxx_messageInfo_Mount.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Mount proto.InternalMessageInfo
+
+func (m *Mount) GetType() string {
 	if m != nil {
-		return m.State
+		return m.Type
 	}
 	return ""
 }
 
-func (m *Container) GetStatus() string {
+func (m *Mount) GetSource() string {
 	if m != nil {
-		return m.Status
+		return m.Source
 	}
 	return ""
 }
 
-func (m *Container) GetIps() []string {
+func (m *Mount) GetDestination() string {
 	if m != nil {
-		return m.Ips
+		return m.Destination
 	}
-	return nil
+	return ""
 }
 
-func (m *Container) GetShell() string {
+func (m *Mount) GetReadOnly() bool {
 	if m != nil {
-		return m.Shell
+		return m.ReadOnly
 	}
-	return ""
+	return false
 }
 
-func (m *Container) GetPodName() string {
+// the times of the probes are unix nanoseconds
+type HealthProbe struct {
+	Start                int64    `protobuf:"varint,1,opt,name=start,proto3" json:"start,omitempty"`
+	End                  int64    `protobuf:"varint,2,opt,name=end,proto3" json:"end,omitempty"`
+	ExitCode             int32    `protobuf:"varint,3,opt,name=exitCode,proto3" json:"exitCode,omitempty"`
+	Output               string   `protobuf:"bytes,4,opt,name=output,proto3" json:"output,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *HealthProbe) Reset()         { *m = HealthProbe{} }
+func (m *HealthProbe) String() string { return proto.CompactTextString(m) }
+func (*HealthProbe) ProtoMessage()    {}
+func (*HealthProbe) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{9}
+}
+
+func (m *HealthProbe) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HealthProbe.Unmarshal(m, b)
+}
+func (m *HealthProbe) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HealthProbe.Marshal(b, m, deterministic)
+}
+func (m *HealthProbe) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HealthProbe.Merge(m, src)
+}
+func (m *HealthProbe) XXX_Size() int {
+	return xxx_messageInfo_HealthProbe.Size(m)
+}
+func (m *HealthProbe) XXX_DiscardUnknown() {
+	xxx_messageInfo_HealthProbe.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HealthProbe proto.InternalMessageInfo
+
+func (m *HealthProbe) GetStart() int64 {
 	if m != nil {
-		return m.PodName
+		return m.Start
 	}
-	return ""
+	return 0
 }
 
-func (m *Container) GetContainerName() string {
+func (m *HealthProbe) GetEnd() int64 {
 	if m != nil {
-		return m.ContainerName
+		return m.End
 	}
-	return ""
+	return 0
 }
 
-func (m *Container) GetNamespace() string {
+func (m *HealthProbe) GetExitCode() int32 {
 	if m != nil {
-		return m.Namespace
+		return m.ExitCode
 	}
-	return ""
+	return 0
 }
 
-func (m *Container) GetRunningNode() string {
+func (m *HealthProbe) GetOutput() string {
 	if m != nil {
-		return m.RunningNode
+		return m.Output
 	}
 	return ""
 }
 
-func (m *Container) GetLocServer() string {
+type Health struct {
+	Cmd                  string         `protobuf:"bytes,1,opt,name=cmd,proto3" json:"cmd,omitempty"`
+	Status               string         `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	FailingStreak        int32          `protobuf:"varint,3,opt,name=failingStreak,proto3" json:"failingStreak,omitempty"`
+	Probes               []*HealthProbe `protobuf:"bytes,4,rep,name=probes,proto3" json:"probes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *Health) Reset()         { *m = Health{} }
+func (m *Health) String() string { return proto.CompactTextString(m) }
+func (*Health) ProtoMessage()    {}
+func (*Health) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{10}
+}
+
+func (m *Health) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Health.Unmarshal(m, b)
+}
+func (m *Health) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Health.Marshal(b, m, deterministic)
+}
+func (m *Health) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Health.Merge(m, src)
+}
+func (m *Health) XXX_Size() int {
+	return xxx_messageInfo_Health.Size(m)
+}
+func (m *Health) XXX_DiscardUnknown() {
+	xxx_messageInfo_Health.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Health proto.InternalMessageInfo
+
+func (m *Health) GetCmd() string {
 	if m != nil {
-		return m.LocServer
+		return m.Cmd
 	}
 	return ""
 }
 
-func (m *Container) GetExecCmd() string {
+func (m *Health) GetStatus() string {
 	if m != nil {
-		return m.ExecCmd
+		return m.Status
 	}
 	return ""
 }
 
-func (m *Container) GetExecUser() string {
+func (m *Health) GetFailingStreak() int32 {
 	if m != nil {
-		return m.ExecUser
+		return m.FailingStreak
 	}
-	return ""
+	return 0
 }
 
-func (m *Container) GetExecEnv() string {
+func (m *Health) GetProbes() []*HealthProbe {
 	if m != nil {
-		return m.ExecEnv
+		return m.Probes
 	}
-	return ""
+	return nil
 }
 
-type Containers struct {
-	Cs []*Container `protobuf:"bytes,1,rep,name=cs" json:"cs,omitempty"`
+type Detail struct {
+	Env    []*EnvVar `protobuf:"bytes,1,rep,name=env,proto3" json:"env,omitempty"`
+	Mounts []*Mount  `protobuf:"bytes,2,rep,name=mounts,proto3" json:"mounts,omitempty"`
+	// nil if there's no healthcheck
+	Health               *Health  `protobuf:"bytes,3,opt,name=health,proto3" json:"health,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *Containers) Reset()                    { *m = Containers{} }
-func (m *Containers) String() string            { return proto.CompactTextString(m) }
-func (*Containers) ProtoMessage()               {}
-func (*Containers) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+func (m *Detail) Reset()         { *m = Detail{} }
+func (m *Detail) String() string { return proto.CompactTextString(m) }
+func (*Detail) ProtoMessage()    {}
+func (*Detail) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{11}
+}
 
-func (m *Containers) GetCs() []*Container {
+func (m *Detail) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Detail.Unmarshal(m, b)
+}
+func (m *Detail) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Detail.Marshal(b, m, deterministic)
+}
+func (m *Detail) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Detail.Merge(m, src)
+}
+func (m *Detail) XXX_Size() int {
+	return xxx_messageInfo_Detail.Size(m)
+}
+func (m *Detail) XXX_DiscardUnknown() {
+	xxx_messageInfo_Detail.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Detail proto.InternalMessageInfo
+
+func (m *Detail) GetEnv() []*EnvVar {
 	if m != nil {
-		return m.Cs
+		return m.Env
 	}
 	return nil
 }
 
-type Io struct {
-	In  []byte `protobuf:"bytes,1,opt,name=in,proto3" json:"in,omitempty"`
-	Out []byte `protobuf:"bytes,2,opt,name=out,proto3" json:"out,omitempty"`
-}
-
-func (m *Io) Reset()                    { *m = Io{} }
-func (m *Io) String() string            { return proto.CompactTextString(m) }
-func (*Io) ProtoMessage()               {}
-func (*Io) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
-
-func (m *Io) GetIn() []byte {
+func (m *Detail) GetMounts() []*Mount {
 	if m != nil {
-		return m.In
+		return m.Mounts
 	}
 	return nil
 }
 
-func (m *Io) GetOut() []byte {
+func (m *Detail) GetHealth() *Health {
 	if m != nil {
-		return m.Out
+		return m.Health
 	}
 	return nil
 }
 
-type WindowSize struct {
-	Height int32 `protobuf:"varint,1,opt,name=height" json:"height,omitempty"`
-	Width  int32 `protobuf:"varint,2,opt,name=width" json:"width,omitempty"`
+type Change struct {
+	Kind                 string   `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	Path                 string   `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *WindowSize) Reset()                    { *m = WindowSize{} }
-func (m *WindowSize) String() string            { return proto.CompactTextString(m) }
-func (*WindowSize) ProtoMessage()               {}
-func (*WindowSize) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+func (m *Change) Reset()         { *m = Change{} }
+func (m *Change) String() string { return proto.CompactTextString(m) }
+func (*Change) ProtoMessage()    {}
+func (*Change) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{12}
+}
 
-func (m *WindowSize) GetHeight() int32 {
+func (m *Change) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Change.Unmarshal(m, b)
+}
+func (m *Change) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Change.Marshal(b, m, deterministic)
+}
+func (m *Change) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Change.Merge(m, src)
+}
+func (m *Change) XXX_Size() int {
+	return xxx_messageInfo_Change.Size(m)
+}
+func (m *Change) XXX_DiscardUnknown() {
+	xxx_messageInfo_Change.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Change proto.InternalMessageInfo
+
+func (m *Change) GetKind() string {
 	if m != nil {
-		return m.Height
+		return m.Kind
 	}
-	return 0
+	return ""
 }
 
-func (m *WindowSize) GetWidth() int32 {
+func (m *Change) GetPath() string {
 	if m != nil {
-		return m.Width
+		return m.Path
 	}
-	return 0
+	return ""
 }
 
-type ExecOptions struct {
-	Cmd  *Io         `protobuf:"bytes,1,opt,name=cmd" json:"cmd,omitempty"`
-	C    *Container  `protobuf:"bytes,2,opt,name=c" json:"c,omitempty"`
-	Err  string      `protobuf:"bytes,3,opt,name=err" json:"err,omitempty"`
-	Auth string      `protobuf:"bytes,4,opt,name=auth" json:"auth,omitempty"`
-	Ws   *WindowSize `protobuf:"bytes,5,opt,name=ws" json:"ws,omitempty"`
+type Changes struct {
+	Cs                   []*Change `protobuf:"bytes,1,rep,name=cs,proto3" json:"cs,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
 }
 
-func (m *ExecOptions) Reset()                    { *m = ExecOptions{} }
-func (m *ExecOptions) String() string            { return proto.CompactTextString(m) }
-func (*ExecOptions) ProtoMessage()               {}
-func (*ExecOptions) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+func (m *Changes) Reset()         { *m = Changes{} }
+func (m *Changes) String() string { return proto.CompactTextString(m) }
+func (*Changes) ProtoMessage()    {}
+func (*Changes) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{13}
+}
 
-func (m *ExecOptions) GetCmd() *Io {
+func (m *Changes) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Changes.Unmarshal(m, b)
+}
+func (m *Changes) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Changes.Marshal(b, m, deterministic)
+}
+func (m *Changes) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Changes.Merge(m, src)
+}
+func (m *Changes) XXX_Size() int {
+	return xxx_messageInfo_Changes.Size(m)
+}
+func (m *Changes) XXX_DiscardUnknown() {
+	xxx_messageInfo_Changes.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Changes proto.InternalMessageInfo
+
+func (m *Changes) GetCs() []*Change {
 	if m != nil {
-		return m.Cmd
+		return m.Cs
 	}
 	return nil
 }
 
-func (m *ExecOptions) GetC() *Container {
+type CommitOpts struct {
+	C                    *ContainerID `protobuf:"bytes,1,opt,name=c,proto3" json:"c,omitempty"`
+	Image                string       `protobuf:"bytes,2,opt,name=image,proto3" json:"image,omitempty"`
+	Comment              string       `protobuf:"bytes,3,opt,name=comment,proto3" json:"comment,omitempty"`
+	Pause                bool         `protobuf:"varint,4,opt,name=pause,proto3" json:"pause,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *CommitOpts) Reset()         { *m = CommitOpts{} }
+func (m *CommitOpts) String() string { return proto.CompactTextString(m) }
+func (*CommitOpts) ProtoMessage()    {}
+func (*CommitOpts) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{14}
+}
+
+func (m *CommitOpts) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CommitOpts.Unmarshal(m, b)
+}
+func (m *CommitOpts) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CommitOpts.Marshal(b, m, deterministic)
+}
+func (m *CommitOpts) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CommitOpts.Merge(m, src)
+}
+func (m *CommitOpts) XXX_Size() int {
+	return xxx_messageInfo_CommitOpts.Size(m)
+}
+func (m *CommitOpts) XXX_DiscardUnknown() {
+	xxx_messageInfo_CommitOpts.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CommitOpts proto.InternalMessageInfo
+
+func (m *CommitOpts) GetC() *ContainerID {
 	if m != nil {
 		return m.C
 	}
 	return nil
 }
 
-func (m *ExecOptions) GetErr() string {
+func (m *CommitOpts) GetImage() string {
 	if m != nil {
-		return m.Err
+		return m.Image
 	}
 	return ""
 }
 
-func (m *ExecOptions) GetAuth() string {
+func (m *CommitOpts) GetComment() string {
 	if m != nil {
-		return m.Auth
+		return m.Comment
 	}
 	return ""
 }
 
-func (m *ExecOptions) GetWs() *WindowSize {
+func (m *CommitOpts) GetPause() bool {
 	if m != nil {
-		return m.Ws
+		return m.Pause
 	}
-	return nil
+	return false
 }
 
-func init() {
-	proto.RegisterType((*Empty)(nil), "pbrpc.empty")
-	proto.RegisterType((*Pong)(nil), "pbrpc.pong")
-	proto.RegisterType((*Err)(nil), "pbrpc.err")
-	proto.RegisterType((*ContainerID)(nil), "pbrpc.ContainerID")
-	proto.RegisterType((*LogOpts)(nil), "pbrpc.logOpts")
-	proto.RegisterType((*Container)(nil), "pbrpc.Container")
-	proto.RegisterType((*Containers)(nil), "pbrpc.Containers")
-	proto.RegisterType((*Io)(nil), "pbrpc.io")
-	proto.RegisterType((*WindowSize)(nil), "pbrpc.windowSize")
-	proto.RegisterType((*ExecOptions)(nil), "pbrpc.execOptions")
+type ImageID struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
+func (m *ImageID) Reset()         { *m = ImageID{} }
+func (m *ImageID) String() string { return proto.CompactTextString(m) }
+func (*ImageID) ProtoMessage()    {}
+func (*ImageID) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{15}
+}
+
+func (m *ImageID) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ImageID.Unmarshal(m, b)
+}
+func (m *ImageID) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ImageID.Marshal(b, m, deterministic)
+}
+func (m *ImageID) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ImageID.Merge(m, src)
+}
+func (m *ImageID) XXX_Size() int {
+	return xxx_messageInfo_ImageID.Size(m)
+}
+func (m *ImageID) XXX_DiscardUnknown() {
+	xxx_messageInfo_ImageID.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ImageID proto.InternalMessageInfo
+
+func (m *ImageID) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+type PruneOpts struct {
+	Auth                 string   `protobuf:"bytes,1,opt,name=auth,proto3" json:"auth,omitempty"`
+	Kind                 string   `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`
+	DryRun               bool     `protobuf:"varint,3,opt,name=dryRun,proto3" json:"dryRun,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PruneOpts) Reset()         { *m = PruneOpts{} }
+func (m *PruneOpts) String() string { return proto.CompactTextString(m) }
+func (*PruneOpts) ProtoMessage()    {}
+func (*PruneOpts) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{16}
+}
+
+func (m *PruneOpts) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PruneOpts.Unmarshal(m, b)
+}
+func (m *PruneOpts) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PruneOpts.Marshal(b, m, deterministic)
+}
+func (m *PruneOpts) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PruneOpts.Merge(m, src)
+}
+func (m *PruneOpts) XXX_Size() int {
+	return xxx_messageInfo_PruneOpts.Size(m)
+}
+func (m *PruneOpts) XXX_DiscardUnknown() {
+	xxx_messageInfo_PruneOpts.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PruneOpts proto.InternalMessageInfo
+
+func (m *PruneOpts) GetAuth() string {
+	if m != nil {
+		return m.Auth
+	}
+	return ""
+}
+
+func (m *PruneOpts) GetKind() string {
+	if m != nil {
+		return m.Kind
+	}
+	return ""
+}
+
+func (m *PruneOpts) GetDryRun() bool {
+	if m != nil {
+		return m.DryRun
+	}
+	return false
+}
+
+type PruneItem struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name                 string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Size                 int64    `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PruneItem) Reset()         { *m = PruneItem{} }
+func (m *PruneItem) String() string { return proto.CompactTextString(m) }
+func (*PruneItem) ProtoMessage()    {}
+func (*PruneItem) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{17}
+}
+
+func (m *PruneItem) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PruneItem.Unmarshal(m, b)
+}
+func (m *PruneItem) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PruneItem.Marshal(b, m, deterministic)
+}
+func (m *PruneItem) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PruneItem.Merge(m, src)
+}
+func (m *PruneItem) XXX_Size() int {
+	return xxx_messageInfo_PruneItem.Size(m)
+}
+func (m *PruneItem) XXX_DiscardUnknown() {
+	xxx_messageInfo_PruneItem.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PruneItem proto.InternalMessageInfo
+
+func (m *PruneItem) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *PruneItem) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *PruneItem) GetSize() int64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+type PruneReport struct {
+	Items                []*PruneItem `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	Reclaimed            uint64       `protobuf:"varint,2,opt,name=reclaimed,proto3" json:"reclaimed,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *PruneReport) Reset()         { *m = PruneReport{} }
+func (m *PruneReport) String() string { return proto.CompactTextString(m) }
+func (*PruneReport) ProtoMessage()    {}
+func (*PruneReport) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{18}
+}
+
+func (m *PruneReport) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PruneReport.Unmarshal(m, b)
+}
+func (m *PruneReport) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PruneReport.Marshal(b, m, deterministic)
+}
+func (m *PruneReport) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PruneReport.Merge(m, src)
+}
+func (m *PruneReport) XXX_Size() int {
+	return xxx_messageInfo_PruneReport.Size(m)
+}
+func (m *PruneReport) XXX_DiscardUnknown() {
+	xxx_messageInfo_PruneReport.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PruneReport proto.InternalMessageInfo
+
+func (m *PruneReport) GetItems() []*PruneItem {
+	if m != nil {
+		return m.Items
+	}
+	return nil
+}
+
+func (m *PruneReport) GetReclaimed() uint64 {
+	if m != nil {
+		return m.Reclaimed
+	}
+	return 0
+}
+
+type CreateOpts struct {
+	Auth                 string   `protobuf:"bytes,1,opt,name=auth,proto3" json:"auth,omitempty"`
+	Image                string   `protobuf:"bytes,2,opt,name=image,proto3" json:"image,omitempty"`
+	Name                 string   `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Cmd                  []string `protobuf:"bytes,4,rep,name=cmd,proto3" json:"cmd,omitempty"`
+	Env                  []string `protobuf:"bytes,5,rep,name=env,proto3" json:"env,omitempty"`
+	Ports                []string `protobuf:"bytes,6,rep,name=ports,proto3" json:"ports,omitempty"`
+	Volumes              []string `protobuf:"bytes,7,rep,name=volumes,proto3" json:"volumes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CreateOpts) Reset()         { *m = CreateOpts{} }
+func (m *CreateOpts) String() string { return proto.CompactTextString(m) }
+func (*CreateOpts) ProtoMessage()    {}
+func (*CreateOpts) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{19}
+}
+
+func (m *CreateOpts) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CreateOpts.Unmarshal(m, b)
+}
+func (m *CreateOpts) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CreateOpts.Marshal(b, m, deterministic)
+}
+func (m *CreateOpts) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CreateOpts.Merge(m, src)
+}
+func (m *CreateOpts) XXX_Size() int {
+	return xxx_messageInfo_CreateOpts.Size(m)
+}
+func (m *CreateOpts) XXX_DiscardUnknown() {
+	xxx_messageInfo_CreateOpts.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CreateOpts proto.InternalMessageInfo
+
+func (m *CreateOpts) GetAuth() string {
+	if m != nil {
+		return m.Auth
+	}
+	return ""
+}
+
+func (m *CreateOpts) GetImage() string {
+	if m != nil {
+		return m.Image
+	}
+	return ""
+}
+
+func (m *CreateOpts) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *CreateOpts) GetCmd() []string {
+	if m != nil {
+		return m.Cmd
+	}
+	return nil
+}
+
+func (m *CreateOpts) GetEnv() []string {
+	if m != nil {
+		return m.Env
+	}
+	return nil
+}
+
+func (m *CreateOpts) GetPorts() []string {
+	if m != nil {
+		return m.Ports
+	}
+	return nil
+}
+
+func (m *CreateOpts) GetVolumes() []string {
+	if m != nil {
+		return m.Volumes
+	}
+	return nil
+}
+
+type DebugOpts struct {
+	C                    *ContainerID `protobuf:"bytes,1,opt,name=c,proto3" json:"c,omitempty"`
+	Image                string       `protobuf:"bytes,2,opt,name=image,proto3" json:"image,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *DebugOpts) Reset()         { *m = DebugOpts{} }
+func (m *DebugOpts) String() string { return proto.CompactTextString(m) }
+func (*DebugOpts) ProtoMessage()    {}
+func (*DebugOpts) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{20}
+}
+
+func (m *DebugOpts) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DebugOpts.Unmarshal(m, b)
+}
+func (m *DebugOpts) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DebugOpts.Marshal(b, m, deterministic)
+}
+func (m *DebugOpts) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DebugOpts.Merge(m, src)
+}
+func (m *DebugOpts) XXX_Size() int {
+	return xxx_messageInfo_DebugOpts.Size(m)
+}
+func (m *DebugOpts) XXX_DiscardUnknown() {
+	xxx_messageInfo_DebugOpts.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DebugOpts proto.InternalMessageInfo
+
+func (m *DebugOpts) GetC() *ContainerID {
+	if m != nil {
+		return m.C
+	}
+	return nil
+}
+
+func (m *DebugOpts) GetImage() string {
+	if m != nil {
+		return m.Image
+	}
+	return ""
+}
+
+// the first copyOpts of CopyTo has the container and the
+// path, the content of the tar archive follows in data
+type CopyOpts struct {
+	C                    *ContainerID `protobuf:"bytes,1,opt,name=c,proto3" json:"c,omitempty"`
+	Path                 string       `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Data                 []byte       `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *CopyOpts) Reset()         { *m = CopyOpts{} }
+func (m *CopyOpts) String() string { return proto.CompactTextString(m) }
+func (*CopyOpts) ProtoMessage()    {}
+func (*CopyOpts) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{21}
+}
+
+func (m *CopyOpts) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CopyOpts.Unmarshal(m, b)
+}
+func (m *CopyOpts) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CopyOpts.Marshal(b, m, deterministic)
+}
+func (m *CopyOpts) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CopyOpts.Merge(m, src)
+}
+func (m *CopyOpts) XXX_Size() int {
+	return xxx_messageInfo_CopyOpts.Size(m)
+}
+func (m *CopyOpts) XXX_DiscardUnknown() {
+	xxx_messageInfo_CopyOpts.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CopyOpts proto.InternalMessageInfo
+
+func (m *CopyOpts) GetC() *ContainerID {
+	if m != nil {
+		return m.C
+	}
+	return nil
+}
+
+func (m *CopyOpts) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *CopyOpts) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+type RenameOpts struct {
+	C                    *ContainerID `protobuf:"bytes,1,opt,name=c,proto3" json:"c,omitempty"`
+	Name                 string       `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *RenameOpts) Reset()         { *m = RenameOpts{} }
+func (m *RenameOpts) String() string { return proto.CompactTextString(m) }
+func (*RenameOpts) ProtoMessage()    {}
+func (*RenameOpts) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{22}
+}
+
+func (m *RenameOpts) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RenameOpts.Unmarshal(m, b)
+}
+func (m *RenameOpts) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RenameOpts.Marshal(b, m, deterministic)
+}
+func (m *RenameOpts) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RenameOpts.Merge(m, src)
+}
+func (m *RenameOpts) XXX_Size() int {
+	return xxx_messageInfo_RenameOpts.Size(m)
+}
+func (m *RenameOpts) XXX_DiscardUnknown() {
+	xxx_messageInfo_RenameOpts.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RenameOpts proto.InternalMessageInfo
+
+func (m *RenameOpts) GetC() *ContainerID {
+	if m != nil {
+		return m.C
+	}
+	return nil
+}
+
+func (m *RenameOpts) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+type LabelsUpdate struct {
+	C                    *ContainerID      `protobuf:"bytes,1,opt,name=c,proto3" json:"c,omitempty"`
+	Set                  map[string]string `protobuf:"bytes,2,rep,name=set,proto3" json:"set,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Remove               []string          `protobuf:"bytes,3,rep,name=remove,proto3" json:"remove,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *LabelsUpdate) Reset()         { *m = LabelsUpdate{} }
+func (m *LabelsUpdate) String() string { return proto.CompactTextString(m) }
+func (*LabelsUpdate) ProtoMessage()    {}
+func (*LabelsUpdate) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{23}
+}
+
+func (m *LabelsUpdate) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LabelsUpdate.Unmarshal(m, b)
+}
+func (m *LabelsUpdate) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LabelsUpdate.Marshal(b, m, deterministic)
+}
+func (m *LabelsUpdate) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LabelsUpdate.Merge(m, src)
+}
+func (m *LabelsUpdate) XXX_Size() int {
+	return xxx_messageInfo_LabelsUpdate.Size(m)
+}
+func (m *LabelsUpdate) XXX_DiscardUnknown() {
+	xxx_messageInfo_LabelsUpdate.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LabelsUpdate proto.InternalMessageInfo
+
+func (m *LabelsUpdate) GetC() *ContainerID {
+	if m != nil {
+		return m.C
+	}
+	return nil
+}
+
+func (m *LabelsUpdate) GetSet() map[string]string {
+	if m != nil {
+		return m.Set
+	}
+	return nil
+}
+
+func (m *LabelsUpdate) GetRemove() []string {
+	if m != nil {
+		return m.Remove
+	}
+	return nil
+}
+
+type LogOpts struct {
+	C      *ContainerID `protobuf:"bytes,1,opt,name=c,proto3" json:"c,omitempty"`
+	Follow bool         `protobuf:"varint,2,opt,name=follow,proto3" json:"follow,omitempty"`
+	Tail   string       `protobuf:"bytes,3,opt,name=tail,proto3" json:"tail,omitempty"`
+	// unix seconds, 0 for all the logs
+	Since                int64    `protobuf:"varint,4,opt,name=since,proto3" json:"since,omitempty"`
+	Timestamps           bool     `protobuf:"varint,5,opt,name=timestamps,proto3" json:"timestamps,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LogOpts) Reset()         { *m = LogOpts{} }
+func (m *LogOpts) String() string { return proto.CompactTextString(m) }
+func (*LogOpts) ProtoMessage()    {}
+func (*LogOpts) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{24}
+}
+
+func (m *LogOpts) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LogOpts.Unmarshal(m, b)
+}
+func (m *LogOpts) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LogOpts.Marshal(b, m, deterministic)
+}
+func (m *LogOpts) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LogOpts.Merge(m, src)
+}
+func (m *LogOpts) XXX_Size() int {
+	return xxx_messageInfo_LogOpts.Size(m)
+}
+func (m *LogOpts) XXX_DiscardUnknown() {
+	xxx_messageInfo_LogOpts.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LogOpts proto.InternalMessageInfo
+
+func (m *LogOpts) GetC() *ContainerID {
+	if m != nil {
+		return m.C
+	}
+	return nil
+}
+
+func (m *LogOpts) GetFollow() bool {
+	if m != nil {
+		return m.Follow
+	}
+	return false
+}
+
+func (m *LogOpts) GetTail() string {
+	if m != nil {
+		return m.Tail
+	}
+	return ""
+}
+
+func (m *LogOpts) GetSince() int64 {
+	if m != nil {
+		return m.Since
+	}
+	return 0
+}
+
+func (m *LogOpts) GetTimestamps() bool {
+	if m != nil {
+		return m.Timestamps
+	}
+	return false
+}
+
+// Container instance
+type Container struct {
+	Id                   string            `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name                 string            `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Image                string            `protobuf:"bytes,3,opt,name=image,proto3" json:"image,omitempty"`
+	Command              string            `protobuf:"bytes,4,opt,name=command,proto3" json:"command,omitempty"`
+	State                string            `protobuf:"bytes,5,opt,name=state,proto3" json:"state,omitempty"`
+	Status               string            `protobuf:"bytes,6,opt,name=status,proto3" json:"status,omitempty"`
+	Ips                  []string          `protobuf:"bytes,7,rep,name=ips,proto3" json:"ips,omitempty"`
+	Shell                string            `protobuf:"bytes,8,opt,name=shell,proto3" json:"shell,omitempty"`
+	PodName              string            `protobuf:"bytes,9,opt,name=pod_name,json=podName,proto3" json:"pod_name,omitempty"`
+	ContainerName        string            `protobuf:"bytes,10,opt,name=container_name,json=containerName,proto3" json:"container_name,omitempty"`
+	Namespace            string            `protobuf:"bytes,11,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	RunningNode          string            `protobuf:"bytes,12,opt,name=running_node,json=runningNode,proto3" json:"running_node,omitempty"`
+	LocServer            string            `protobuf:"bytes,13,opt,name=loc_server,json=locServer,proto3" json:"loc_server,omitempty"`
+	ExecCmd              string            `protobuf:"bytes,14,opt,name=execCmd,proto3" json:"execCmd,omitempty"`
+	ExecUser             string            `protobuf:"bytes,15,opt,name=execUser,proto3" json:"execUser,omitempty"`
+	ExecEnv              string            `protobuf:"bytes,16,opt,name=execEnv,proto3" json:"execEnv,omitempty"`
+	ExecNoTTY            bool              `protobuf:"varint,17,opt,name=execNoTTY,proto3" json:"execNoTTY,omitempty"`
+	Labels               map[string]string `protobuf:"bytes,18,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	ExecAttach           bool              `protobuf:"varint,19,opt,name=execAttach,proto3" json:"execAttach,omitempty"`
+	ExecAttachStdin      bool              `protobuf:"varint,20,opt,name=execAttachStdin,proto3" json:"execAttachStdin,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *Container) Reset()         { *m = Container{} }
+func (m *Container) String() string { return proto.CompactTextString(m) }
+func (*Container) ProtoMessage()    {}
+func (*Container) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{25}
+}
+
+func (m *Container) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Container.Unmarshal(m, b)
+}
+func (m *Container) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Container.Marshal(b, m, deterministic)
+}
+func (m *Container) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Container.Merge(m, src)
+}
+func (m *Container) XXX_Size() int {
+	return xxx_messageInfo_Container.Size(m)
+}
+func (m *Container) XXX_DiscardUnknown() {
+	xxx_messageInfo_Container.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Container proto.InternalMessageInfo
+
+func (m *Container) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Container) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Container) GetImage() string {
+	if m != nil {
+		return m.Image
+	}
+	return ""
+}
+
+func (m *Container) GetCommand() string {
+	if m != nil {
+		return m.Command
+	}
+	return ""
+}
+
+func (m *Container) GetState() string {
+	if m != nil {
+		return m.State
+	}
+	return ""
+}
+
+func (m *Container) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *Container) GetIps() []string {
+	if m != nil {
+		return m.Ips
+	}
+	return nil
+}
+
+func (m *Container) GetShell() string {
+	if m != nil {
+		return m.Shell
+	}
+	return ""
+}
+
+func (m *Container) GetPodName() string {
+	if m != nil {
+		return m.PodName
+	}
+	return ""
+}
+
+func (m *Container) GetContainerName() string {
+	if m != nil {
+		return m.ContainerName
+	}
+	return ""
+}
+
+func (m *Container) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *Container) GetRunningNode() string {
+	if m != nil {
+		return m.RunningNode
+	}
+	return ""
+}
+
+func (m *Container) GetLocServer() string {
+	if m != nil {
+		return m.LocServer
+	}
+	return ""
+}
+
+func (m *Container) GetExecCmd() string {
+	if m != nil {
+		return m.ExecCmd
+	}
+	return ""
+}
+
+func (m *Container) GetExecUser() string {
+	if m != nil {
+		return m.ExecUser
+	}
+	return ""
+}
+
+func (m *Container) GetExecEnv() string {
+	if m != nil {
+		return m.ExecEnv
+	}
+	return ""
+}
+
+func (m *Container) GetExecNoTTY() bool {
+	if m != nil {
+		return m.ExecNoTTY
+	}
+	return false
+}
+
+func (m *Container) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+func (m *Container) GetExecAttach() bool {
+	if m != nil {
+		return m.ExecAttach
+	}
+	return false
+}
+
+func (m *Container) GetExecAttachStdin() bool {
+	if m != nil {
+		return m.ExecAttachStdin
+	}
+	return false
+}
+
+type Containers struct {
+	Cs                   []*Container `protobuf:"bytes,1,rep,name=cs,proto3" json:"cs,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *Containers) Reset()         { *m = Containers{} }
+func (m *Containers) String() string { return proto.CompactTextString(m) }
+func (*Containers) ProtoMessage()    {}
+func (*Containers) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{26}
+}
+
+func (m *Containers) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Containers.Unmarshal(m, b)
+}
+func (m *Containers) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Containers.Marshal(b, m, deterministic)
+}
+func (m *Containers) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Containers.Merge(m, src)
+}
+func (m *Containers) XXX_Size() int {
+	return xxx_messageInfo_Containers.Size(m)
+}
+func (m *Containers) XXX_DiscardUnknown() {
+	xxx_messageInfo_Containers.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Containers proto.InternalMessageInfo
+
+func (m *Containers) GetCs() []*Container {
+	if m != nil {
+		return m.Cs
+	}
+	return nil
+}
+
+type Io struct {
+	In  []byte `protobuf:"bytes,1,opt,name=in,proto3" json:"in,omitempty"`
+	Out []byte `protobuf:"bytes,2,opt,name=out,proto3" json:"out,omitempty"`
+	// stderr of a non-tty exec
+	Err []byte `protobuf:"bytes,3,opt,name=err,proto3" json:"err,omitempty"`
+	// the exec exited with exitCode, sent as the last message
+	Exited               bool     `protobuf:"varint,4,opt,name=exited,proto3" json:"exited,omitempty"`
+	ExitCode             int32    `protobuf:"varint,5,opt,name=exitCode,proto3" json:"exitCode,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Io) Reset()         { *m = Io{} }
+func (m *Io) String() string { return proto.CompactTextString(m) }
+func (*Io) ProtoMessage()    {}
+func (*Io) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{27}
+}
+
+func (m *Io) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Io.Unmarshal(m, b)
+}
+func (m *Io) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Io.Marshal(b, m, deterministic)
+}
+func (m *Io) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Io.Merge(m, src)
+}
+func (m *Io) XXX_Size() int {
+	return xxx_messageInfo_Io.Size(m)
+}
+func (m *Io) XXX_DiscardUnknown() {
+	xxx_messageInfo_Io.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Io proto.InternalMessageInfo
+
+func (m *Io) GetIn() []byte {
+	if m != nil {
+		return m.In
+	}
+	return nil
+}
+
+func (m *Io) GetOut() []byte {
+	if m != nil {
+		return m.Out
+	}
+	return nil
+}
+
+func (m *Io) GetErr() []byte {
+	if m != nil {
+		return m.Err
+	}
+	return nil
+}
+
+func (m *Io) GetExited() bool {
+	if m != nil {
+		return m.Exited
+	}
+	return false
+}
+
+func (m *Io) GetExitCode() int32 {
+	if m != nil {
+		return m.ExitCode
+	}
+	return 0
+}
+
+type WindowSize struct {
+	Height               int32    `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+	Width                int32    `protobuf:"varint,2,opt,name=width,proto3" json:"width,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WindowSize) Reset()         { *m = WindowSize{} }
+func (m *WindowSize) String() string { return proto.CompactTextString(m) }
+func (*WindowSize) ProtoMessage()    {}
+func (*WindowSize) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{28}
+}
+
+func (m *WindowSize) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WindowSize.Unmarshal(m, b)
+}
+func (m *WindowSize) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WindowSize.Marshal(b, m, deterministic)
+}
+func (m *WindowSize) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WindowSize.Merge(m, src)
+}
+func (m *WindowSize) XXX_Size() int {
+	return xxx_messageInfo_WindowSize.Size(m)
+}
+func (m *WindowSize) XXX_DiscardUnknown() {
+	xxx_messageInfo_WindowSize.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WindowSize proto.InternalMessageInfo
+
+func (m *WindowSize) GetHeight() int32 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *WindowSize) GetWidth() int32 {
+	if m != nil {
+		return m.Width
+	}
+	return 0
+}
+
+type ExecOptions struct {
+	Cmd                  *Io         `protobuf:"bytes,1,opt,name=cmd,proto3" json:"cmd,omitempty"`
+	C                    *Container  `protobuf:"bytes,2,opt,name=c,proto3" json:"c,omitempty"`
+	Err                  string      `protobuf:"bytes,3,opt,name=err,proto3" json:"err,omitempty"`
+	Auth                 string      `protobuf:"bytes,4,opt,name=auth,proto3" json:"auth,omitempty"`
+	Ws                   *WindowSize `protobuf:"bytes,5,opt,name=ws,proto3" json:"ws,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *ExecOptions) Reset()         { *m = ExecOptions{} }
+func (m *ExecOptions) String() string { return proto.CompactTextString(m) }
+func (*ExecOptions) ProtoMessage()    {}
+func (*ExecOptions) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{29}
+}
+
+func (m *ExecOptions) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ExecOptions.Unmarshal(m, b)
+}
+func (m *ExecOptions) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ExecOptions.Marshal(b, m, deterministic)
+}
+func (m *ExecOptions) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExecOptions.Merge(m, src)
+}
+func (m *ExecOptions) XXX_Size() int {
+	return xxx_messageInfo_ExecOptions.Size(m)
+}
+func (m *ExecOptions) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExecOptions.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExecOptions proto.InternalMessageInfo
+
+func (m *ExecOptions) GetCmd() *Io {
+	if m != nil {
+		return m.Cmd
+	}
+	return nil
+}
+
+func (m *ExecOptions) GetC() *Container {
+	if m != nil {
+		return m.C
+	}
+	return nil
+}
+
+func (m *ExecOptions) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+func (m *ExecOptions) GetAuth() string {
+	if m != nil {
+		return m.Auth
+	}
+	return ""
+}
+
+func (m *ExecOptions) GetWs() *WindowSize {
+	if m != nil {
+		return m.Ws
+	}
+	return nil
+}
+
+type RunResult struct {
+	Stdout               []byte   `protobuf:"bytes,1,opt,name=stdout,proto3" json:"stdout,omitempty"`
+	Stderr               []byte   `protobuf:"bytes,2,opt,name=stderr,proto3" json:"stderr,omitempty"`
+	ExitCode             int32    `protobuf:"varint,3,opt,name=exitCode,proto3" json:"exitCode,omitempty"`
+	Truncated            bool     `protobuf:"varint,4,opt,name=truncated,proto3" json:"truncated,omitempty"`
+	Duration             string   `protobuf:"bytes,5,opt,name=duration,proto3" json:"duration,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RunResult) Reset()         { *m = RunResult{} }
+func (m *RunResult) String() string { return proto.CompactTextString(m) }
+func (*RunResult) ProtoMessage()    {}
+func (*RunResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{30}
+}
+
+func (m *RunResult) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RunResult.Unmarshal(m, b)
+}
+func (m *RunResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RunResult.Marshal(b, m, deterministic)
+}
+func (m *RunResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RunResult.Merge(m, src)
+}
+func (m *RunResult) XXX_Size() int {
+	return xxx_messageInfo_RunResult.Size(m)
+}
+func (m *RunResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_RunResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RunResult proto.InternalMessageInfo
+
+func (m *RunResult) GetStdout() []byte {
+	if m != nil {
+		return m.Stdout
+	}
+	return nil
+}
+
+func (m *RunResult) GetStderr() []byte {
+	if m != nil {
+		return m.Stderr
+	}
+	return nil
+}
+
+func (m *RunResult) GetExitCode() int32 {
+	if m != nil {
+		return m.ExitCode
+	}
+	return 0
+}
+
+func (m *RunResult) GetTruncated() bool {
+	if m != nil {
+		return m.Truncated
+	}
+	return false
+}
+
+func (m *RunResult) GetDuration() string {
+	if m != nil {
+		return m.Duration
+	}
+	return ""
+}
+
+// container event of the backend
+type Event struct {
+	Action string `protobuf:"bytes,1,opt,name=action,proto3" json:"action,omitempty"`
+	Id     string `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Name   string `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	// unix nano
+	Time                 int64    `protobuf:"varint,4,opt,name=time,proto3" json:"time,omitempty"`
+	Detail               string   `protobuf:"bytes,5,opt,name=detail,proto3" json:"detail,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Event) Reset()         { *m = Event{} }
+func (m *Event) String() string { return proto.CompactTextString(m) }
+func (*Event) ProtoMessage()    {}
+func (*Event) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{31}
+}
+
+func (m *Event) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Event.Unmarshal(m, b)
+}
+func (m *Event) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Event.Marshal(b, m, deterministic)
+}
+func (m *Event) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Event.Merge(m, src)
+}
+func (m *Event) XXX_Size() int {
+	return xxx_messageInfo_Event.Size(m)
+}
+func (m *Event) XXX_DiscardUnknown() {
+	xxx_messageInfo_Event.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Event proto.InternalMessageInfo
+
+func (m *Event) GetAction() string {
+	if m != nil {
+		return m.Action
+	}
+	return ""
+}
+
+func (m *Event) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Event) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Event) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+func (m *Event) GetDetail() string {
+	if m != nil {
+		return m.Detail
+	}
+	return ""
+}
+
+// resource usage of a container
+type Stats struct {
+	// unix nano
+	Time                 int64    `protobuf:"varint,1,opt,name=time,proto3" json:"time,omitempty"`
+	CpuPercent           float64  `protobuf:"fixed64,2,opt,name=cpuPercent,proto3" json:"cpuPercent,omitempty"`
+	MemoryUsage          uint64   `protobuf:"varint,3,opt,name=memoryUsage,proto3" json:"memoryUsage,omitempty"`
+	MemoryLimit          uint64   `protobuf:"varint,4,opt,name=memoryLimit,proto3" json:"memoryLimit,omitempty"`
+	NetworkRx            uint64   `protobuf:"varint,5,opt,name=networkRx,proto3" json:"networkRx,omitempty"`
+	NetworkTx            uint64   `protobuf:"varint,6,opt,name=networkTx,proto3" json:"networkTx,omitempty"`
+	BlockRead            uint64   `protobuf:"varint,7,opt,name=blockRead,proto3" json:"blockRead,omitempty"`
+	BlockWrite           uint64   `protobuf:"varint,8,opt,name=blockWrite,proto3" json:"blockWrite,omitempty"`
+	Pids                 uint64   `protobuf:"varint,9,opt,name=pids,proto3" json:"pids,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Stats) Reset()         { *m = Stats{} }
+func (m *Stats) String() string { return proto.CompactTextString(m) }
+func (*Stats) ProtoMessage()    {}
+func (*Stats) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{32}
+}
+
+func (m *Stats) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Stats.Unmarshal(m, b)
+}
+func (m *Stats) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Stats.Marshal(b, m, deterministic)
+}
+func (m *Stats) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Stats.Merge(m, src)
+}
+func (m *Stats) XXX_Size() int {
+	return xxx_messageInfo_Stats.Size(m)
+}
+func (m *Stats) XXX_DiscardUnknown() {
+	xxx_messageInfo_Stats.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Stats proto.InternalMessageInfo
+
+func (m *Stats) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+func (m *Stats) GetCpuPercent() float64 {
+	if m != nil {
+		return m.CpuPercent
+	}
+	return 0
+}
+
+func (m *Stats) GetMemoryUsage() uint64 {
+	if m != nil {
+		return m.MemoryUsage
+	}
+	return 0
+}
+
+func (m *Stats) GetMemoryLimit() uint64 {
+	if m != nil {
+		return m.MemoryLimit
+	}
+	return 0
+}
+
+func (m *Stats) GetNetworkRx() uint64 {
+	if m != nil {
+		return m.NetworkRx
+	}
+	return 0
+}
+
+func (m *Stats) GetNetworkTx() uint64 {
+	if m != nil {
+		return m.NetworkTx
+	}
+	return 0
+}
+
+func (m *Stats) GetBlockRead() uint64 {
+	if m != nil {
+		return m.BlockRead
+	}
+	return 0
+}
+
+func (m *Stats) GetBlockWrite() uint64 {
+	if m != nil {
+		return m.BlockWrite
+	}
+	return 0
+}
+
+func (m *Stats) GetPids() uint64 {
+	if m != nil {
+		return m.Pids
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterType((*Empty)(nil), "pbrpc.empty")
+	proto.RegisterType((*Pong)(nil), "pbrpc.pong")
+	proto.RegisterType((*Err)(nil), "pbrpc.err")
+	proto.RegisterType((*ContainerID)(nil), "pbrpc.ContainerID")
+	proto.RegisterType((*KillOpts)(nil), "pbrpc.killOpts")
+	proto.RegisterType((*Process)(nil), "pbrpc.process")
+	proto.RegisterType((*Processes)(nil), "pbrpc.processes")
+	proto.RegisterType((*EnvVar)(nil), "pbrpc.envVar")
+	proto.RegisterType((*Mount)(nil), "pbrpc.mount")
+	proto.RegisterType((*HealthProbe)(nil), "pbrpc.healthProbe")
+	proto.RegisterType((*Health)(nil), "pbrpc.health")
+	proto.RegisterType((*Detail)(nil), "pbrpc.detail")
+	proto.RegisterType((*Change)(nil), "pbrpc.change")
+	proto.RegisterType((*Changes)(nil), "pbrpc.changes")
+	proto.RegisterType((*CommitOpts)(nil), "pbrpc.commitOpts")
+	proto.RegisterType((*ImageID)(nil), "pbrpc.imageID")
+	proto.RegisterType((*PruneOpts)(nil), "pbrpc.pruneOpts")
+	proto.RegisterType((*PruneItem)(nil), "pbrpc.pruneItem")
+	proto.RegisterType((*PruneReport)(nil), "pbrpc.pruneReport")
+	proto.RegisterType((*CreateOpts)(nil), "pbrpc.createOpts")
+	proto.RegisterType((*DebugOpts)(nil), "pbrpc.debugOpts")
+	proto.RegisterType((*CopyOpts)(nil), "pbrpc.copyOpts")
+	proto.RegisterType((*RenameOpts)(nil), "pbrpc.renameOpts")
+	proto.RegisterType((*LabelsUpdate)(nil), "pbrpc.labelsUpdate")
+	proto.RegisterMapType((map[string]string)(nil), "pbrpc.labelsUpdate.SetEntry")
+	proto.RegisterType((*LogOpts)(nil), "pbrpc.logOpts")
+	proto.RegisterType((*Container)(nil), "pbrpc.Container")
+	proto.RegisterMapType((map[string]string)(nil), "pbrpc.Container.LabelsEntry")
+	proto.RegisterType((*Containers)(nil), "pbrpc.Containers")
+	proto.RegisterType((*Io)(nil), "pbrpc.io")
+	proto.RegisterType((*WindowSize)(nil), "pbrpc.windowSize")
+	proto.RegisterType((*ExecOptions)(nil), "pbrpc.execOptions")
+	proto.RegisterType((*RunResult)(nil), "pbrpc.runResult")
+	proto.RegisterType((*Event)(nil), "pbrpc.event")
+	proto.RegisterType((*Stats)(nil), "pbrpc.stats")
+}
+
+func init() { proto.RegisterFile("api.proto", fileDescriptor_00212fb1f9d3bf1c) }
+
+var fileDescriptor_00212fb1f9d3bf1c = []byte{
+	// 1816 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x18, 0x4d, 0x73, 0xdc, 0xb6,
+	0x55, 0xe4, 0x2e, 0xf7, 0xe3, 0xad, 0x2c, 0xcb, 0x88, 0xc7, 0x65, 0x14, 0xc7, 0x95, 0xd9, 0xb8,
+	0x23, 0xa7, 0x8d, 0x62, 0xab, 0x99, 0x4e, 0x9b, 0x5b, 0x2b, 0xab, 0x1d, 0x4f, 0x3c, 0xb6, 0x06,
+	0x92, 0xdb, 0xe9, 0xc9, 0x43, 0x91, 0xd0, 0x0a, 0x23, 0x12, 0x60, 0x01, 0x70, 0xa5, 0xcd, 0x29,
+	0x87, 0x5e, 0x73, 0xe8, 0xf4, 0xde, 0xdf, 0xd0, 0x9f, 0xd8, 0x79, 0x00, 0xc8, 0xe5, 0xae, 0xd6,
+	0x9d, 0xed, 0xe4, 0xf6, 0xbe, 0xf0, 0x3e, 0xf0, 0x3e, 0xf0, 0x48, 0x18, 0xa7, 0x15, 0x3f, 0xac,
+	0x94, 0x34, 0x92, 0x44, 0xd5, 0x85, 0xaa, 0xb2, 0xe4, 0x33, 0x88, 0x58, 0x59, 0x99, 0x39, 0x21,
+	0xd0, 0x4f, 0x6b, 0x73, 0x15, 0x07, 0xfb, 0xc1, 0xc1, 0x98, 0x5a, 0x38, 0x89, 0xa1, 0x5f, 0x49,
+	0x31, 0x25, 0xbb, 0xd0, 0x2b, 0xf5, 0xd4, 0xb3, 0x10, 0x4c, 0x7e, 0x06, 0x3d, 0xa6, 0x14, 0x32,
+	0x98, 0x52, 0x0d, 0x83, 0x29, 0x95, 0xbc, 0x84, 0xc9, 0xb1, 0x14, 0x26, 0xe5, 0x82, 0xa9, 0xd7,
+	0xaf, 0xc8, 0x0e, 0x84, 0x3c, 0xf7, 0xfc, 0x90, 0xe7, 0xad, 0x95, 0xb0, 0x63, 0xe5, 0x15, 0x8c,
+	0xae, 0x79, 0x51, 0xbc, 0xab, 0x8c, 0x26, 0xfb, 0x10, 0x64, 0x56, 0x7c, 0x72, 0x44, 0x0e, 0xad,
+	0x87, 0x87, 0x1d, 0x75, 0x34, 0xc8, 0xc8, 0x23, 0x18, 0x68, 0x3e, 0x15, 0x69, 0xe1, 0x75, 0x78,
+	0x2c, 0x79, 0x0a, 0xc3, 0x4a, 0xc9, 0x8c, 0x69, 0x8d, 0x22, 0x97, 0x9c, 0x15, 0xb9, 0x8e, 0x83,
+	0xfd, 0x1e, 0x8a, 0x38, 0x2c, 0x39, 0x86, 0xb1, 0x17, 0x61, 0x56, 0xc8, 0x70, 0x53, 0xb0, 0x56,
+	0xc8, 0x61, 0xe4, 0x09, 0x84, 0x95, 0x8e, 0xc3, 0xfd, 0xde, 0xc1, 0xe4, 0x68, 0xc7, 0xbb, 0xe0,
+	0x4f, 0xd1, 0xb0, 0xd2, 0xc9, 0x11, 0x0c, 0x98, 0x98, 0xfd, 0x25, 0x55, 0x18, 0x8b, 0x48, 0x4b,
+	0xd6, 0xdc, 0x18, 0xc2, 0xe4, 0x21, 0x44, 0xb3, 0xb4, 0xa8, 0x99, 0x77, 0xce, 0x21, 0xc9, 0xdf,
+	0x21, 0x2a, 0x65, 0x2d, 0x0c, 0x1e, 0x31, 0xf3, 0xaa, 0x3d, 0x82, 0xb0, 0x0d, 0x48, 0xd6, 0x2a,
+	0x63, 0x6d, 0x40, 0x16, 0x23, 0xfb, 0x30, 0xc9, 0x99, 0x36, 0x5c, 0xa4, 0x86, 0x4b, 0x11, 0xf7,
+	0x2c, 0xb3, 0x4b, 0x22, 0x7b, 0x30, 0x52, 0x2c, 0xcd, 0xdf, 0x89, 0x62, 0x1e, 0xf7, 0xf7, 0x83,
+	0x83, 0x11, 0x6d, 0xf1, 0x84, 0xc3, 0xe4, 0x8a, 0xa5, 0x85, 0xb9, 0x3a, 0x55, 0xf2, 0xc2, 0xfa,
+	0xa5, 0x4d, 0xaa, 0x8c, 0xb5, 0xdc, 0xa3, 0x0e, 0xb1, 0xe9, 0x13, 0xb9, 0xb5, 0xdb, 0xa3, 0x08,
+	0xa2, 0x4a, 0x76, 0xcb, 0xcd, 0xb1, 0xcc, 0x99, 0xb5, 0x18, 0xd1, 0x16, 0x47, 0x47, 0x65, 0x6d,
+	0xaa, 0xda, 0x58, 0x63, 0x63, 0xea, 0xb1, 0xe4, 0x1f, 0x01, 0x0c, 0x9c, 0x2d, 0x54, 0x98, 0x95,
+	0x4d, 0xbe, 0x11, 0xb4, 0xd1, 0x99, 0xd4, 0xd4, 0xba, 0x8d, 0xce, 0x62, 0xe4, 0x0b, 0xb8, 0x77,
+	0x99, 0xf2, 0x82, 0x8b, 0xe9, 0x99, 0x51, 0x2c, 0xbd, 0xf6, 0xd6, 0x96, 0x89, 0xe4, 0x4b, 0x18,
+	0x54, 0xe8, 0xbf, 0x8e, 0xfb, 0xfb, 0xbd, 0x4e, 0x4d, 0x74, 0x42, 0xa3, 0x5e, 0x22, 0x99, 0xc1,
+	0x20, 0x67, 0x26, 0xe5, 0x05, 0xf9, 0x39, 0x86, 0x35, 0xb3, 0x79, 0x9d, 0x1c, 0xdd, 0xf3, 0x47,
+	0x5c, 0xd2, 0x30, 0xca, 0x19, 0xf9, 0x02, 0x06, 0x36, 0x1f, 0x4d, 0x9e, 0xb7, 0xbd, 0x8c, 0x25,
+	0x52, 0xcf, 0x23, 0xcf, 0x9a, 0xb0, 0xac, 0x6f, 0x0b, 0x4d, 0x8e, 0x48, 0x3d, 0x33, 0x79, 0x01,
+	0x83, 0xec, 0x2a, 0x15, 0x53, 0x86, 0xd9, 0xbd, 0xe6, 0xa2, 0x09, 0xdf, 0xc2, 0x48, 0xab, 0xd2,
+	0x45, 0xc1, 0x23, 0x9c, 0x1c, 0xc0, 0xd0, 0x9d, 0xd0, 0xe4, 0x73, 0x08, 0x33, 0xbd, 0xe2, 0xa9,
+	0xe3, 0xd1, 0x30, 0xd3, 0x89, 0x01, 0xc8, 0x64, 0x59, 0x72, 0xb3, 0x61, 0x73, 0x3c, 0x84, 0x88,
+	0x97, 0xe9, 0xb4, 0x2d, 0x3f, 0x8b, 0x90, 0x18, 0x86, 0xa8, 0x85, 0x09, 0xe3, 0xab, 0xa8, 0x41,
+	0x51, 0xbe, 0x4a, 0x6b, 0xcd, 0x7c, 0xf9, 0x38, 0x24, 0xf9, 0x14, 0x86, 0xf6, 0xe0, 0xdd, 0xfe,
+	0x4d, 0xbe, 0xc3, 0x16, 0xaa, 0x05, 0xb3, 0xfe, 0xac, 0x19, 0x19, 0xed, 0x1d, 0x84, 0x9d, 0x3b,
+	0x78, 0x04, 0x83, 0x5c, 0xcd, 0x69, 0xed, 0x8a, 0x78, 0x44, 0x3d, 0xe6, 0xfa, 0xb1, 0x16, 0xec,
+	0xb5, 0x61, 0xe5, 0xba, 0x49, 0x61, 0xbb, 0x2b, 0xec, 0x74, 0x17, 0x81, 0xbe, 0xe6, 0xdf, 0xbb,
+	0xca, 0xec, 0x51, 0x0b, 0x27, 0x67, 0x30, 0xb1, 0x4a, 0x28, 0xab, 0xa4, 0x32, 0xe4, 0x97, 0x10,
+	0x71, 0xc3, 0xca, 0xe6, 0x4e, 0x77, 0xdb, 0x0e, 0xf6, 0x76, 0xa8, 0x63, 0x93, 0xc7, 0x30, 0x56,
+	0x2c, 0x2b, 0x52, 0x5e, 0x32, 0xe7, 0x6c, 0x9f, 0x2e, 0x08, 0xc9, 0xbf, 0x03, 0x80, 0x4c, 0xb1,
+	0xd4, 0x7c, 0x3c, 0xd0, 0xf5, 0x57, 0xdd, 0x78, 0xdd, 0xeb, 0x78, 0xed, 0x9b, 0xa2, 0x6f, 0xc7,
+	0x0c, 0x82, 0xae, 0xef, 0x66, 0x71, 0xe4, 0x28, 0x58, 0x91, 0x98, 0x08, 0xa9, 0x8c, 0x8e, 0x07,
+	0x96, 0xe6, 0x10, 0x4c, 0xdc, 0x4c, 0x16, 0x75, 0xc9, 0x74, 0x3c, 0xb4, 0xf4, 0x06, 0xc5, 0xab,
+	0xcb, 0xd9, 0x45, 0x3d, 0xfd, 0x29, 0x75, 0x91, 0x9c, 0xc3, 0x28, 0x93, 0xd5, 0x7c, 0x43, 0x1d,
+	0x6b, 0x2a, 0x19, 0x69, 0x79, 0x6a, 0x52, 0x1b, 0xee, 0x36, 0xb5, 0x70, 0xf2, 0x47, 0x00, 0xc5,
+	0x30, 0xf0, 0xcd, 0xf5, 0xae, 0x26, 0x3a, 0xf9, 0x4f, 0x00, 0xdb, 0x45, 0x7a, 0xc1, 0x0a, 0xfd,
+	0xbe, 0xca, 0x53, 0xc3, 0x36, 0x50, 0x73, 0x08, 0x3d, 0xcd, 0x8c, 0x6f, 0xe8, 0xc7, 0x5e, 0xa6,
+	0xab, 0xe3, 0xf0, 0x8c, 0x99, 0x13, 0x61, 0xd4, 0x9c, 0xa2, 0x20, 0x16, 0xa5, 0x62, 0xa5, 0x9c,
+	0x61, 0xae, 0xec, 0xfc, 0x77, 0xd8, 0xde, 0x6f, 0x61, 0xd4, 0x08, 0x62, 0x9e, 0xae, 0xd9, 0xbc,
+	0x19, 0x67, 0xd7, 0x6c, 0xbe, 0x7e, 0xbe, 0x7f, 0x1b, 0xfe, 0x2e, 0x48, 0x7e, 0x0c, 0x60, 0x58,
+	0xc8, 0xe9, 0xe6, 0xaf, 0xd8, 0xa5, 0x2c, 0x0a, 0x79, 0x63, 0x15, 0x8d, 0xa8, 0xc7, 0xec, 0x03,
+	0x91, 0xf2, 0xa2, 0xa9, 0x1f, 0x84, 0xed, 0xec, 0xe6, 0x22, 0x73, 0x4d, 0xda, 0xa3, 0x0e, 0x21,
+	0x4f, 0x00, 0x0c, 0x2f, 0x99, 0x36, 0x69, 0x59, 0xe9, 0x38, 0xb2, 0x5a, 0x3a, 0x94, 0xe4, 0x87,
+	0x08, 0xc6, 0xad, 0xd1, 0x8d, 0xba, 0xab, 0x2d, 0x92, 0xde, 0x9a, 0xe1, 0x91, 0x8a, 0xdc, 0x8f,
+	0xfd, 0x06, 0xf5, 0x6f, 0x8a, 0x61, 0xd6, 0xf8, 0x98, 0x3a, 0xa4, 0x33, 0xf0, 0x07, 0x4b, 0x03,
+	0x7f, 0x17, 0x7a, 0xbc, 0x6a, 0xea, 0x18, 0x41, 0x7b, 0xfe, 0x8a, 0x15, 0x45, 0x3c, 0xf2, 0xe7,
+	0x11, 0x21, 0x9f, 0xc2, 0xa8, 0x92, 0xf9, 0x07, 0xeb, 0xdd, 0xd8, 0x19, 0xac, 0x64, 0xfe, 0x16,
+	0x1d, 0x7c, 0x06, 0x3b, 0x59, 0x13, 0x91, 0x13, 0x00, 0x2b, 0x70, 0xaf, 0xa5, 0x5a, 0xb1, 0xc7,
+	0x30, 0x46, 0xa6, 0xae, 0xd2, 0x8c, 0xc5, 0x13, 0x2b, 0xb1, 0x20, 0x90, 0xa7, 0xb0, 0xad, 0x6a,
+	0x21, 0xb8, 0x98, 0x7e, 0x10, 0xf8, 0xca, 0x6d, 0xbb, 0x77, 0xd5, 0xd3, 0xde, 0xe2, 0x43, 0xf7,
+	0x39, 0x40, 0x21, 0xb3, 0x0f, 0x9a, 0xa9, 0x19, 0x53, 0xf1, 0x3d, 0xa7, 0xa1, 0x90, 0xd9, 0x99,
+	0x25, 0xe0, 0x8d, 0xb0, 0x5b, 0x96, 0x1d, 0x97, 0x79, 0xbc, 0xe3, 0x1c, 0xf4, 0xa8, 0x7b, 0x3d,
+	0x59, 0xf6, 0x5e, 0x33, 0x15, 0xdf, 0xb7, 0xac, 0x16, 0x6f, 0x4e, 0x9d, 0x88, 0x59, 0xbc, 0xbb,
+	0x38, 0x75, 0x22, 0x66, 0xe8, 0x2f, 0x82, 0x6f, 0xe5, 0xf9, 0xf9, 0xdf, 0xe2, 0x07, 0x36, 0x91,
+	0x0b, 0x02, 0xf9, 0x06, 0x06, 0xae, 0x8a, 0x63, 0xb2, 0x54, 0xda, 0x6d, 0x6e, 0x0f, 0xdf, 0x58,
+	0xb6, 0x2b, 0x6d, 0x2f, 0x8b, 0xd5, 0x81, 0x2a, 0xfe, 0x60, 0x4c, 0x9a, 0x5d, 0xc5, 0x9f, 0xb8,
+	0xea, 0x58, 0x50, 0xc8, 0x01, 0xdc, 0x5f, 0x60, 0x67, 0x26, 0xe7, 0x22, 0x7e, 0x68, 0x85, 0x56,
+	0xc9, 0x7b, 0xbf, 0x87, 0x49, 0xc7, 0xc0, 0xff, 0xd5, 0x12, 0x87, 0x00, 0xad, 0x97, 0xd8, 0x14,
+	0x61, 0xb6, 0x3a, 0x96, 0x5b, 0xb6, 0x7d, 0xed, 0x0a, 0x08, 0xb9, 0xb4, 0xa5, 0x2a, 0xac, 0x81,
+	0x6d, 0x1a, 0x72, 0x81, 0x16, 0x65, 0x6d, 0xac, 0xf6, 0x6d, 0x8a, 0x60, 0xb3, 0x75, 0xba, 0xa1,
+	0x83, 0x20, 0x16, 0x1d, 0xae, 0x29, 0x2c, 0xf7, 0x0f, 0x99, 0xc7, 0x96, 0xd6, 0x99, 0x68, 0x79,
+	0x9d, 0x49, 0xbe, 0x05, 0xb8, 0xe1, 0x22, 0x97, 0x37, 0x67, 0xfc, 0x7b, 0x5b, 0xb6, 0x57, 0x8c,
+	0x4f, 0xaf, 0xdc, 0x86, 0x14, 0x51, 0x8f, 0x61, 0x74, 0x37, 0x3c, 0xf7, 0x63, 0x2f, 0xa2, 0x0e,
+	0x49, 0xfe, 0x15, 0xc0, 0x04, 0x2f, 0xea, 0x5d, 0x85, 0x8b, 0x98, 0x26, 0x9f, 0x2d, 0xf6, 0x9e,
+	0xc9, 0xd1, 0xd8, 0x07, 0xc7, 0xa5, 0x9b, 0xf6, 0x4f, 0x70, 0x1a, 0x84, 0xfb, 0xc1, 0xda, 0xb8,
+	0x83, 0xac, 0x1b, 0x8e, 0x5b, 0xa2, 0xdb, 0xf7, 0xa6, 0xdf, 0x79, 0x6f, 0x9e, 0x42, 0x78, 0xe3,
+	0xfa, 0x7c, 0x72, 0xf4, 0xc0, 0xab, 0x59, 0xf8, 0x4f, 0xc3, 0x1b, 0x9d, 0xfc, 0x33, 0x80, 0xb1,
+	0xaa, 0x05, 0x65, 0xba, 0x2e, 0x8c, 0x6b, 0xc4, 0x1c, 0xaf, 0xce, 0xdd, 0xa5, 0xc7, 0x3c, 0x1d,
+	0x2d, 0x86, 0x2d, 0x1d, 0x8d, 0xfe, 0xaf, 0xd5, 0xef, 0x31, 0x8c, 0x8d, 0xaa, 0x45, 0x96, 0x2e,
+	0xae, 0x78, 0x41, 0xc0, 0x93, 0x79, 0xad, 0xdc, 0x9a, 0xea, 0x66, 0x41, 0x8b, 0x27, 0x12, 0x22,
+	0x36, 0x63, 0xc2, 0x9a, 0x4d, 0x33, 0x2b, 0xe2, 0x6a, 0xc7, 0x63, 0x7e, 0x32, 0x85, 0x77, 0x26,
+	0x53, 0x6f, 0xf9, 0xdd, 0xc7, 0xc9, 0xe6, 0x07, 0xa0, 0x85, 0xc9, 0xa3, 0x66, 0xdd, 0xf3, 0x26,
+	0x3d, 0x96, 0xfc, 0x18, 0xba, 0xb1, 0xa4, 0xdb, 0x53, 0x41, 0xe7, 0xd4, 0x13, 0x80, 0xac, 0xaa,
+	0x4f, 0x99, 0xca, 0x70, 0x1b, 0x42, 0xab, 0x01, 0xed, 0x50, 0x70, 0xe9, 0x2e, 0x59, 0x29, 0xd5,
+	0xfc, 0xbd, 0x6e, 0x26, 0x61, 0x9f, 0x76, 0x49, 0x0b, 0x89, 0x37, 0xbc, 0xe4, 0x6e, 0x15, 0xee,
+	0xd3, 0x2e, 0xc9, 0xce, 0x1f, 0x66, 0x6e, 0xa4, 0xba, 0xa6, 0xb7, 0xd6, 0xb9, 0x3e, 0x5d, 0x10,
+	0x3a, 0xdc, 0xf3, 0xdb, 0x78, 0xb0, 0xc4, 0x3d, 0xb7, 0xdc, 0x8b, 0x42, 0x66, 0xd7, 0x94, 0xa5,
+	0x79, 0x3c, 0x74, 0xdc, 0x96, 0x80, 0xde, 0x5b, 0xe4, 0xaf, 0x8a, 0x1b, 0x66, 0xc7, 0x66, 0x9f,
+	0x76, 0x28, 0xf6, 0x89, 0xe6, 0xb9, 0xb6, 0x73, 0xb3, 0x4f, 0x2d, 0x7c, 0xf4, 0xc3, 0x18, 0xee,
+	0xb7, 0xf3, 0xd1, 0x4f, 0xb0, 0x97, 0x30, 0xfc, 0x33, 0x33, 0xaf, 0xc5, 0xa5, 0x24, 0x6b, 0xde,
+	0xa7, 0xbd, 0x3b, 0x55, 0x9a, 0x6c, 0x91, 0xe7, 0xd0, 0x7f, 0xc3, 0xb5, 0x21, 0xcd, 0xaa, 0x6c,
+	0x3f, 0x1a, 0xf7, 0x1e, 0xac, 0x4a, 0x6a, 0x2b, 0x1a, 0x9d, 0xd9, 0xcf, 0x8b, 0x75, 0xba, 0xa1,
+	0x39, 0xaf, 0x50, 0xeb, 0x01, 0xf4, 0xcf, 0x8c, 0xac, 0x36, 0x90, 0xfc, 0x15, 0x0c, 0x29, 0xd3,
+	0x1b, 0xaa, 0x7d, 0x0e, 0xd1, 0x29, 0x6e, 0xb2, 0x9b, 0xe9, 0x7d, 0x2f, 0xaa, 0x0d, 0x85, 0x9f,
+	0x41, 0xff, 0x3b, 0x5e, 0x14, 0xe4, 0xbe, 0xa7, 0x36, 0x9f, 0xad, 0x77, 0xcc, 0x0f, 0xa8, 0xdd,
+	0x80, 0x48, 0x73, 0x3f, 0x8b, 0x85, 0x68, 0x45, 0xf4, 0x2b, 0x18, 0x1c, 0xdb, 0x05, 0xbf, 0x15,
+	0x5d, 0xec, 0xfb, 0x7b, 0xcd, 0xe7, 0xa7, 0x5f, 0xc6, 0x93, 0x2d, 0xf2, 0x35, 0x44, 0xaf, 0x70,
+	0xed, 0x23, 0x4d, 0x8a, 0xda, 0x25, 0x70, 0x6f, 0x8d, 0xf7, 0xc9, 0x16, 0x79, 0x09, 0x83, 0x63,
+	0xbb, 0xc7, 0x2e, 0xf4, 0xb7, 0x6b, 0xed, 0x47, 0x8e, 0x7c, 0x05, 0xbd, 0xf3, 0x8f, 0xa4, 0x64,
+	0x77, 0xf9, 0x7b, 0x98, 0x61, 0xb6, 0x7f, 0x0d, 0xfd, 0x57, 0xfc, 0xf2, 0x72, 0xad, 0xfc, 0xce,
+	0xd2, 0x17, 0x0d, 0x4a, 0x1f, 0xc2, 0xf0, 0xb5, 0xd0, 0x15, 0xcb, 0xd6, 0xa7, 0xf1, 0x5e, 0x1b,
+	0x96, 0xed, 0x65, 0xf4, 0x7f, 0xdb, 0x6d, 0x6f, 0xee, 0x0d, 0x22, 0x9f, 0xac, 0x59, 0xec, 0x56,
+	0xae, 0xf4, 0x6b, 0x88, 0x4e, 0x71, 0xdb, 0x27, 0x4b, 0xbb, 0xff, 0x52, 0xc0, 0x9d, 0x0f, 0x86,
+	0x64, 0x8b, 0x7c, 0x03, 0xfd, 0x93, 0x5b, 0x96, 0xb5, 0x0e, 0x75, 0x06, 0xfb, 0xde, 0x1a, 0x5a,
+	0xb2, 0x75, 0x10, 0xbc, 0x08, 0xc8, 0x2f, 0xa0, 0x7f, 0xca, 0xc5, 0x74, 0xa5, 0x21, 0x26, 0x8d,
+	0x05, 0x29, 0xa6, 0xae, 0x60, 0xde, 0xc8, 0xa9, 0x26, 0xcd, 0x45, 0xf8, 0x05, 0x71, 0x6f, 0xf1,
+	0x44, 0x24, 0x5b, 0x2f, 0x02, 0xbc, 0x72, 0x5a, 0x8b, 0xb5, 0x0e, 0x34, 0x41, 0xb4, 0x73, 0xdd,
+	0x76, 0xcd, 0xe0, 0x04, 0x67, 0xaa, 0x5e, 0x31, 0xde, 0x62, 0xc8, 0xf4, 0x8a, 0xa3, 0x33, 0x37,
+	0x0b, 0xd7, 0x5c, 0x76, 0x23, 0x6e, 0xa7, 0xa5, 0x15, 0xff, 0x12, 0x46, 0xc7, 0xb2, 0x9a, 0xff,
+	0x49, 0xc9, 0xb2, 0xad, 0xf1, 0xe6, 0x0b, 0x61, 0xd5, 0xe7, 0xe7, 0x58, 0xb9, 0xd5, 0xfc, 0x5c,
+	0xde, 0x95, 0x5c, 0xca, 0xc7, 0x41, 0x70, 0x31, 0xb0, 0x7f, 0x9c, 0x7e, 0xf3, 0xdf, 0x01, 0x00,
+	0xc1, 0x44, 0x50, 0xa0, 0x7e, 0x12, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// ContainerServerClient is the client API for ContainerServer service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type ContainerServerClient interface {
+	GetInfo(ctx context.Context, in *ContainerID, opts ...grpc.CallOption) (*Container, error)
+	List(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Containers, error)
+	Start(ctx context.Context, in *ContainerID, opts ...grpc.CallOption) (*Err, error)
+	Stop(ctx context.Context, in *ContainerID, opts ...grpc.CallOption) (*Err, error)
+	Restart(ctx context.Context, in *ContainerID, opts ...grpc.CallOption) (*Err, error)
+	Pause(ctx context.Context, in *ContainerID, opts ...grpc.CallOption) (*Err, error)
+	Unpause(ctx context.Context, in *ContainerID, opts ...grpc.CallOption) (*Err, error)
+	Kill(ctx context.Context, in *KillOpts, opts ...grpc.CallOption) (*Err, error)
+	Rename(ctx context.Context, in *RenameOpts, opts ...grpc.CallOption) (*Err, error)
+	Commit(ctx context.Context, in *CommitOpts, opts ...grpc.CallOption) (*ImageID, error)
+	Debug(ctx context.Context, in *DebugOpts, opts ...grpc.CallOption) (*ContainerID, error)
+	Create(ctx context.Context, in *CreateOpts, opts ...grpc.CallOption) (*ContainerID, error)
+	Top(ctx context.Context, in *ContainerID, opts ...grpc.CallOption) (*Processes, error)
+	Diff(ctx context.Context, in *ContainerID, opts ...grpc.CallOption) (*Changes, error)
+	Inspect(ctx context.Context, in *ContainerID, opts ...grpc.CallOption) (*Detail, error)
+	UpdateLabels(ctx context.Context, in *LabelsUpdate, opts ...grpc.CallOption) (*Err, error)
+	Prune(ctx context.Context, in *PruneOpts, opts ...grpc.CallOption) (*PruneReport, error)
+	Exec(ctx context.Context, opts ...grpc.CallOption) (ContainerServer_ExecClient, error)
+	Ping(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Pong, error)
+	Logs(ctx context.Context, in *LogOpts, opts ...grpc.CallOption) (ContainerServer_LogsClient, error)
+	Run(ctx context.Context, in *ExecOptions, opts ...grpc.CallOption) (*RunResult, error)
+	Events(ctx context.Context, in *Empty, opts ...grpc.CallOption) (ContainerServer_EventsClient, error)
+	Stats(ctx context.Context, in *ContainerID, opts ...grpc.CallOption) (ContainerServer_StatsClient, error)
+	CopyFrom(ctx context.Context, in *CopyOpts, opts ...grpc.CallOption) (ContainerServer_CopyFromClient, error)
+	CopyTo(ctx context.Context, opts ...grpc.CallOption) (ContainerServer_CopyToClient, error)
+}
+
+type containerServerClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewContainerServerClient(cc *grpc.ClientConn) ContainerServerClient {
+	return &containerServerClient{cc}
+}
+
+func (c *containerServerClient) GetInfo(ctx context.Context, in *ContainerID, opts ...grpc.CallOption) (*Container, error) {
+	out := new(Container)
+	err := c.cc.Invoke(ctx, "/pbrpc.containerServer/GetInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *containerServerClient) List(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Containers, error) {
+	out := new(Containers)
+	err := c.cc.Invoke(ctx, "/pbrpc.containerServer/List", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *containerServerClient) Start(ctx context.Context, in *ContainerID, opts ...grpc.CallOption) (*Err, error) {
+	out := new(Err)
+	err := c.cc.Invoke(ctx, "/pbrpc.containerServer/Start", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *containerServerClient) Stop(ctx context.Context, in *ContainerID, opts ...grpc.CallOption) (*Err, error) {
+	out := new(Err)
+	err := c.cc.Invoke(ctx, "/pbrpc.containerServer/Stop", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *containerServerClient) Restart(ctx context.Context, in *ContainerID, opts ...grpc.CallOption) (*Err, error) {
+	out := new(Err)
+	err := c.cc.Invoke(ctx, "/pbrpc.containerServer/Restart", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *containerServerClient) Pause(ctx context.Context, in *ContainerID, opts ...grpc.CallOption) (*Err, error) {
+	out := new(Err)
+	err := c.cc.Invoke(ctx, "/pbrpc.containerServer/Pause", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *containerServerClient) Unpause(ctx context.Context, in *ContainerID, opts ...grpc.CallOption) (*Err, error) {
+	out := new(Err)
+	err := c.cc.Invoke(ctx, "/pbrpc.containerServer/Unpause", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *containerServerClient) Kill(ctx context.Context, in *KillOpts, opts ...grpc.CallOption) (*Err, error) {
+	out := new(Err)
+	err := c.cc.Invoke(ctx, "/pbrpc.containerServer/Kill", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *containerServerClient) Rename(ctx context.Context, in *RenameOpts, opts ...grpc.CallOption) (*Err, error) {
+	out := new(Err)
+	err := c.cc.Invoke(ctx, "/pbrpc.containerServer/Rename", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *containerServerClient) Commit(ctx context.Context, in *CommitOpts, opts ...grpc.CallOption) (*ImageID, error) {
+	out := new(ImageID)
+	err := c.cc.Invoke(ctx, "/pbrpc.containerServer/Commit", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *containerServerClient) Debug(ctx context.Context, in *DebugOpts, opts ...grpc.CallOption) (*ContainerID, error) {
+	out := new(ContainerID)
+	err := c.cc.Invoke(ctx, "/pbrpc.containerServer/Debug", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *containerServerClient) Create(ctx context.Context, in *CreateOpts, opts ...grpc.CallOption) (*ContainerID, error) {
+	out := new(ContainerID)
+	err := c.cc.Invoke(ctx, "/pbrpc.containerServer/Create", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *containerServerClient) Top(ctx context.Context, in *ContainerID, opts ...grpc.CallOption) (*Processes, error) {
+	out := new(Processes)
+	err := c.cc.Invoke(ctx, "/pbrpc.containerServer/Top", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *containerServerClient) Diff(ctx context.Context, in *ContainerID, opts ...grpc.CallOption) (*Changes, error) {
+	out := new(Changes)
+	err := c.cc.Invoke(ctx, "/pbrpc.containerServer/Diff", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *containerServerClient) Inspect(ctx context.Context, in *ContainerID, opts ...grpc.CallOption) (*Detail, error) {
+	out := new(Detail)
+	err := c.cc.Invoke(ctx, "/pbrpc.containerServer/Inspect", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *containerServerClient) UpdateLabels(ctx context.Context, in *LabelsUpdate, opts ...grpc.CallOption) (*Err, error) {
+	out := new(Err)
+	err := c.cc.Invoke(ctx, "/pbrpc.containerServer/UpdateLabels", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *containerServerClient) Prune(ctx context.Context, in *PruneOpts, opts ...grpc.CallOption) (*PruneReport, error) {
+	out := new(PruneReport)
+	err := c.cc.Invoke(ctx, "/pbrpc.containerServer/Prune", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *containerServerClient) Exec(ctx context.Context, opts ...grpc.CallOption) (ContainerServer_ExecClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_ContainerServer_serviceDesc.Streams[0], "/pbrpc.containerServer/Exec", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &containerServerExecClient{stream}
+	return x, nil
+}
+
+type ContainerServer_ExecClient interface {
+	Send(*ExecOptions) error
+	Recv() (*ExecOptions, error)
+	grpc.ClientStream
+}
+
+type containerServerExecClient struct {
+	grpc.ClientStream
+}
+
+func (x *containerServerExecClient) Send(m *ExecOptions) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *containerServerExecClient) Recv() (*ExecOptions, error) {
+	m := new(ExecOptions)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
+func (c *containerServerClient) Ping(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Pong, error) {
+	out := new(Pong)
+	err := c.cc.Invoke(ctx, "/pbrpc.containerServer/Ping", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
 
-// Client API for ContainerServer service
+func (c *containerServerClient) Logs(ctx context.Context, in *LogOpts, opts ...grpc.CallOption) (ContainerServer_LogsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_ContainerServer_serviceDesc.Streams[1], "/pbrpc.containerServer/Logs", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &containerServerLogsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
 
-type ContainerServerClient interface {
-	GetInfo(ctx context.Context, in *ContainerID, opts ...grpc.CallOption) (*Container, error)
-	List(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Containers, error)
-	Start(ctx context.Context, in *ContainerID, opts ...grpc.CallOption) (*Err, error)
-	Stop(ctx context.Context, in *ContainerID, opts ...grpc.CallOption) (*Err, error)
-	Restart(ctx context.Context, in *ContainerID, opts ...grpc.CallOption) (*Err, error)
-	Exec(ctx context.Context, opts ...grpc.CallOption) (ContainerServer_ExecClient, error)
-	Ping(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Pong, error)
-	Logs(ctx context.Context, in *LogOpts, opts ...grpc.CallOption) (ContainerServer_LogsClient, error)
+type ContainerServer_LogsClient interface {
+	Recv() (*Io, error)
+	grpc.ClientStream
 }
 
-type containerServerClient struct {
-	cc *grpc.ClientConn
+type containerServerLogsClient struct {
+	grpc.ClientStream
 }
 
-func NewContainerServerClient(cc *grpc.ClientConn) ContainerServerClient {
-	return &containerServerClient{cc}
+func (x *containerServerLogsClient) Recv() (*Io, error) {
+	m := new(Io)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
 }
 
-func (c *containerServerClient) GetInfo(ctx context.Context, in *ContainerID, opts ...grpc.CallOption) (*Container, error) {
-	out := new(Container)
-	err := grpc.Invoke(ctx, "/pbrpc.containerServer/GetInfo", in, out, c.cc, opts...)
+func (c *containerServerClient) Run(ctx context.Context, in *ExecOptions, opts ...grpc.CallOption) (*RunResult, error) {
+	out := new(RunResult)
+	err := c.cc.Invoke(ctx, "/pbrpc.containerServer/Run", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *containerServerClient) List(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Containers, error) {
-	out := new(Containers)
-	err := grpc.Invoke(ctx, "/pbrpc.containerServer/List", in, out, c.cc, opts...)
+func (c *containerServerClient) Events(ctx context.Context, in *Empty, opts ...grpc.CallOption) (ContainerServer_EventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_ContainerServer_serviceDesc.Streams[2], "/pbrpc.containerServer/Events", opts...)
 	if err != nil {
 		return nil, err
 	}
-	return out, nil
+	x := &containerServerEventsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
 }
 
-func (c *containerServerClient) Start(ctx context.Context, in *ContainerID, opts ...grpc.CallOption) (*Err, error) {
-	out := new(Err)
-	err := grpc.Invoke(ctx, "/pbrpc.containerServer/Start", in, out, c.cc, opts...)
+type ContainerServer_EventsClient interface {
+	Recv() (*Event, error)
+	grpc.ClientStream
+}
+
+type containerServerEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *containerServerEventsClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *containerServerClient) Stats(ctx context.Context, in *ContainerID, opts ...grpc.CallOption) (ContainerServer_StatsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_ContainerServer_serviceDesc.Streams[3], "/pbrpc.containerServer/Stats", opts...)
 	if err != nil {
 		return nil, err
 	}
-	return out, nil
+	x := &containerServerStatsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
 }
 
-func (c *containerServerClient) Stop(ctx context.Context, in *ContainerID, opts ...grpc.CallOption) (*Err, error) {
-	out := new(Err)
-	err := grpc.Invoke(ctx, "/pbrpc.containerServer/Stop", in, out, c.cc, opts...)
+type ContainerServer_StatsClient interface {
+	Recv() (*Stats, error)
+	grpc.ClientStream
+}
+
+type containerServerStatsClient struct {
+	grpc.ClientStream
+}
+
+func (x *containerServerStatsClient) Recv() (*Stats, error) {
+	m := new(Stats)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *containerServerClient) CopyFrom(ctx context.Context, in *CopyOpts, opts ...grpc.CallOption) (ContainerServer_CopyFromClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_ContainerServer_serviceDesc.Streams[4], "/pbrpc.containerServer/CopyFrom", opts...)
 	if err != nil {
 		return nil, err
 	}
-	return out, nil
+	x := &containerServerCopyFromClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
 }
 
-func (c *containerServerClient) Restart(ctx context.Context, in *ContainerID, opts ...grpc.CallOption) (*Err, error) {
-	out := new(Err)
-	err := grpc.Invoke(ctx, "/pbrpc.containerServer/Restart", in, out, c.cc, opts...)
+type ContainerServer_CopyFromClient interface {
+	Recv() (*Io, error)
+	grpc.ClientStream
+}
+
+type containerServerCopyFromClient struct {
+	grpc.ClientStream
+}
+
+func (x *containerServerCopyFromClient) Recv() (*Io, error) {
+	m := new(Io)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *containerServerClient) CopyTo(ctx context.Context, opts ...grpc.CallOption) (ContainerServer_CopyToClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_ContainerServer_serviceDesc.Streams[5], "/pbrpc.containerServer/CopyTo", opts...)
 	if err != nil {
 		return nil, err
 	}
-	return out, nil
+	x := &containerServerCopyToClient{stream}
+	return x, nil
+}
+
+type ContainerServer_CopyToClient interface {
+	Send(*CopyOpts) error
+	CloseAndRecv() (*Err, error)
+	grpc.ClientStream
+}
+
+type containerServerCopyToClient struct {
+	grpc.ClientStream
+}
+
+func (x *containerServerCopyToClient) Send(m *CopyOpts) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *containerServerCopyToClient) CloseAndRecv() (*Err, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(Err)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ContainerServerServer is the server API for ContainerServer service.
+type ContainerServerServer interface {
+	GetInfo(context.Context, *ContainerID) (*Container, error)
+	List(context.Context, *Empty) (*Containers, error)
+	Start(context.Context, *ContainerID) (*Err, error)
+	Stop(context.Context, *ContainerID) (*Err, error)
+	Restart(context.Context, *ContainerID) (*Err, error)
+	Pause(context.Context, *ContainerID) (*Err, error)
+	Unpause(context.Context, *ContainerID) (*Err, error)
+	Kill(context.Context, *KillOpts) (*Err, error)
+	Rename(context.Context, *RenameOpts) (*Err, error)
+	Commit(context.Context, *CommitOpts) (*ImageID, error)
+	Debug(context.Context, *DebugOpts) (*ContainerID, error)
+	Create(context.Context, *CreateOpts) (*ContainerID, error)
+	Top(context.Context, *ContainerID) (*Processes, error)
+	Diff(context.Context, *ContainerID) (*Changes, error)
+	Inspect(context.Context, *ContainerID) (*Detail, error)
+	UpdateLabels(context.Context, *LabelsUpdate) (*Err, error)
+	Prune(context.Context, *PruneOpts) (*PruneReport, error)
+	Exec(ContainerServer_ExecServer) error
+	Ping(context.Context, *Empty) (*Pong, error)
+	Logs(*LogOpts, ContainerServer_LogsServer) error
+	Run(context.Context, *ExecOptions) (*RunResult, error)
+	Events(*Empty, ContainerServer_EventsServer) error
+	Stats(*ContainerID, ContainerServer_StatsServer) error
+	CopyFrom(*CopyOpts, ContainerServer_CopyFromServer) error
+	CopyTo(ContainerServer_CopyToServer) error
+}
+
+func RegisterContainerServerServer(s *grpc.Server, srv ContainerServerServer) {
+	s.RegisterService(&_ContainerServer_serviceDesc, srv)
+}
+
+func _ContainerServer_GetInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ContainerID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContainerServerServer).GetInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pbrpc.containerServer/GetInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContainerServerServer).GetInfo(ctx, req.(*ContainerID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContainerServer_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContainerServerServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pbrpc.containerServer/List",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContainerServerServer).List(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContainerServer_Start_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ContainerID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContainerServerServer).Start(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pbrpc.containerServer/Start",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContainerServerServer).Start(ctx, req.(*ContainerID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContainerServer_Stop_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ContainerID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContainerServerServer).Stop(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pbrpc.containerServer/Stop",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContainerServerServer).Stop(ctx, req.(*ContainerID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContainerServer_Restart_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ContainerID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContainerServerServer).Restart(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pbrpc.containerServer/Restart",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContainerServerServer).Restart(ctx, req.(*ContainerID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContainerServer_Pause_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ContainerID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContainerServerServer).Pause(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pbrpc.containerServer/Pause",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContainerServerServer).Pause(ctx, req.(*ContainerID))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *containerServerClient) Exec(ctx context.Context, opts ...grpc.CallOption) (ContainerServer_ExecClient, error) {
-	stream, err := grpc.NewClientStream(ctx, &_ContainerServer_serviceDesc.Streams[0], c.cc, "/pbrpc.containerServer/Exec", opts...)
-	if err != nil {
+func _ContainerServer_Unpause_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ContainerID)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	x := &containerServerExecClient{stream}
-	return x, nil
-}
-
-type ContainerServer_ExecClient interface {
-	Send(*ExecOptions) error
-	Recv() (*ExecOptions, error)
-	grpc.ClientStream
-}
-
-type containerServerExecClient struct {
-	grpc.ClientStream
-}
-
-func (x *containerServerExecClient) Send(m *ExecOptions) error {
-	return x.ClientStream.SendMsg(m)
+	if interceptor == nil {
+		return srv.(ContainerServerServer).Unpause(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pbrpc.containerServer/Unpause",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContainerServerServer).Unpause(ctx, req.(*ContainerID))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (x *containerServerExecClient) Recv() (*ExecOptions, error) {
-	m := new(ExecOptions)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
+func _ContainerServer_Kill_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KillOpts)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return m, nil
+	if interceptor == nil {
+		return srv.(ContainerServerServer).Kill(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pbrpc.containerServer/Kill",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContainerServerServer).Kill(ctx, req.(*KillOpts))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *containerServerClient) Ping(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Pong, error) {
-	out := new(Pong)
-	err := grpc.Invoke(ctx, "/pbrpc.containerServer/Ping", in, out, c.cc, opts...)
-	if err != nil {
+func _ContainerServer_Rename_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenameOpts)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(ContainerServerServer).Rename(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pbrpc.containerServer/Rename",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContainerServerServer).Rename(ctx, req.(*RenameOpts))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *containerServerClient) Logs(ctx context.Context, in *LogOpts, opts ...grpc.CallOption) (ContainerServer_LogsClient, error) {
-	stream, err := grpc.NewClientStream(ctx, &_ContainerServer_serviceDesc.Streams[1], c.cc, "/pbrpc.containerServer/Logs", opts...)
-	if err != nil {
+func _ContainerServer_Commit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CommitOpts)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	x := &containerServerLogsClient{stream}
-	if err := x.ClientStream.SendMsg(in); err != nil {
-		return nil, err
+	if interceptor == nil {
+		return srv.(ContainerServerServer).Commit(ctx, in)
 	}
-	if err := x.ClientStream.CloseSend(); err != nil {
-		return nil, err
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pbrpc.containerServer/Commit",
 	}
-	return x, nil
-}
-
-type ContainerServer_LogsClient interface {
-	Recv() (*Io, error)
-	grpc.ClientStream
-}
-
-type containerServerLogsClient struct {
-	grpc.ClientStream
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContainerServerServer).Commit(ctx, req.(*CommitOpts))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (x *containerServerLogsClient) Recv() (*Io, error) {
-	m := new(Io)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
+func _ContainerServer_Debug_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DebugOpts)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return m, nil
-}
-
-// Server API for ContainerServer service
-
-type ContainerServerServer interface {
-	GetInfo(context.Context, *ContainerID) (*Container, error)
-	List(context.Context, *Empty) (*Containers, error)
-	Start(context.Context, *ContainerID) (*Err, error)
-	Stop(context.Context, *ContainerID) (*Err, error)
-	Restart(context.Context, *ContainerID) (*Err, error)
-	Exec(ContainerServer_ExecServer) error
-	Ping(context.Context, *Empty) (*Pong, error)
-	Logs(*LogOpts, ContainerServer_LogsServer) error
+	if interceptor == nil {
+		return srv.(ContainerServerServer).Debug(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pbrpc.containerServer/Debug",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContainerServerServer).Debug(ctx, req.(*DebugOpts))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func RegisterContainerServerServer(s *grpc.Server, srv ContainerServerServer) {
-	s.RegisterService(&_ContainerServer_serviceDesc, srv)
+func _ContainerServer_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateOpts)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContainerServerServer).Create(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pbrpc.containerServer/Create",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContainerServerServer).Create(ctx, req.(*CreateOpts))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _ContainerServer_GetInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+func _ContainerServer_Top_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ContainerID)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ContainerServerServer).GetInfo(ctx, in)
+		return srv.(ContainerServerServer).Top(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/pbrpc.containerServer/GetInfo",
+		FullMethod: "/pbrpc.containerServer/Top",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ContainerServerServer).GetInfo(ctx, req.(*ContainerID))
+		return srv.(ContainerServerServer).Top(ctx, req.(*ContainerID))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _ContainerServer_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(Empty)
+func _ContainerServer_Diff_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ContainerID)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ContainerServerServer).List(ctx, in)
+		return srv.(ContainerServerServer).Diff(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/pbrpc.containerServer/List",
+		FullMethod: "/pbrpc.containerServer/Diff",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ContainerServerServer).List(ctx, req.(*Empty))
+		return srv.(ContainerServerServer).Diff(ctx, req.(*ContainerID))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _ContainerServer_Start_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+func _ContainerServer_Inspect_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ContainerID)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ContainerServerServer).Start(ctx, in)
+		return srv.(ContainerServerServer).Inspect(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/pbrpc.containerServer/Start",
+		FullMethod: "/pbrpc.containerServer/Inspect",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ContainerServerServer).Start(ctx, req.(*ContainerID))
+		return srv.(ContainerServerServer).Inspect(ctx, req.(*ContainerID))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _ContainerServer_Stop_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ContainerID)
+func _ContainerServer_UpdateLabels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LabelsUpdate)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ContainerServerServer).Stop(ctx, in)
+		return srv.(ContainerServerServer).UpdateLabels(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/pbrpc.containerServer/Stop",
+		FullMethod: "/pbrpc.containerServer/UpdateLabels",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ContainerServerServer).Stop(ctx, req.(*ContainerID))
+		return srv.(ContainerServerServer).UpdateLabels(ctx, req.(*LabelsUpdate))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _ContainerServer_Restart_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ContainerID)
+func _ContainerServer_Prune_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PruneOpts)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ContainerServerServer).Restart(ctx, in)
+		return srv.(ContainerServerServer).Prune(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/pbrpc.containerServer/Restart",
+		FullMethod: "/pbrpc.containerServer/Prune",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ContainerServerServer).Restart(ctx, req.(*ContainerID))
+		return srv.(ContainerServerServer).Prune(ctx, req.(*PruneOpts))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -725,6 +2966,113 @@ func (x *containerServerLogsServer) Send(m *Io) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _ContainerServer_Run_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExecOptions)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContainerServerServer).Run(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pbrpc.containerServer/Run",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContainerServerServer).Run(ctx, req.(*ExecOptions))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContainerServer_Events_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ContainerServerServer).Events(m, &containerServerEventsServer{stream})
+}
+
+type ContainerServer_EventsServer interface {
+	Send(*Event) error
+	grpc.ServerStream
+}
+
+type containerServerEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *containerServerEventsServer) Send(m *Event) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ContainerServer_Stats_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ContainerID)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ContainerServerServer).Stats(m, &containerServerStatsServer{stream})
+}
+
+type ContainerServer_StatsServer interface {
+	Send(*Stats) error
+	grpc.ServerStream
+}
+
+type containerServerStatsServer struct {
+	grpc.ServerStream
+}
+
+func (x *containerServerStatsServer) Send(m *Stats) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ContainerServer_CopyFrom_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(CopyOpts)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ContainerServerServer).CopyFrom(m, &containerServerCopyFromServer{stream})
+}
+
+type ContainerServer_CopyFromServer interface {
+	Send(*Io) error
+	grpc.ServerStream
+}
+
+type containerServerCopyFromServer struct {
+	grpc.ServerStream
+}
+
+func (x *containerServerCopyFromServer) Send(m *Io) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ContainerServer_CopyTo_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ContainerServerServer).CopyTo(&containerServerCopyToServer{stream})
+}
+
+type ContainerServer_CopyToServer interface {
+	SendAndClose(*Err) error
+	Recv() (*CopyOpts, error)
+	grpc.ServerStream
+}
+
+type containerServerCopyToServer struct {
+	grpc.ServerStream
+}
+
+func (x *containerServerCopyToServer) SendAndClose(m *Err) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *containerServerCopyToServer) Recv() (*CopyOpts, error) {
+	m := new(CopyOpts)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 var _ContainerServer_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "pbrpc.containerServer",
 	HandlerType: (*ContainerServerServer)(nil),
@@ -749,10 +3097,62 @@ var _ContainerServer_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Restart",
 			Handler:    _ContainerServer_Restart_Handler,
 		},
+		{
+			MethodName: "Pause",
+			Handler:    _ContainerServer_Pause_Handler,
+		},
+		{
+			MethodName: "Unpause",
+			Handler:    _ContainerServer_Unpause_Handler,
+		},
+		{
+			MethodName: "Kill",
+			Handler:    _ContainerServer_Kill_Handler,
+		},
+		{
+			MethodName: "Rename",
+			Handler:    _ContainerServer_Rename_Handler,
+		},
+		{
+			MethodName: "Commit",
+			Handler:    _ContainerServer_Commit_Handler,
+		},
+		{
+			MethodName: "Debug",
+			Handler:    _ContainerServer_Debug_Handler,
+		},
+		{
+			MethodName: "Create",
+			Handler:    _ContainerServer_Create_Handler,
+		},
+		{
+			MethodName: "Top",
+			Handler:    _ContainerServer_Top_Handler,
+		},
+		{
+			MethodName: "Diff",
+			Handler:    _ContainerServer_Diff_Handler,
+		},
+		{
+			MethodName: "Inspect",
+			Handler:    _ContainerServer_Inspect_Handler,
+		},
+		{
+			MethodName: "UpdateLabels",
+			Handler:    _ContainerServer_UpdateLabels_Handler,
+		},
+		{
+			MethodName: "Prune",
+			Handler:    _ContainerServer_Prune_Handler,
+		},
 		{
 			MethodName: "Ping",
 			Handler:    _ContainerServer_Ping_Handler,
 		},
+		{
+			MethodName: "Run",
+			Handler:    _ContainerServer_Run_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -766,54 +3166,26 @@ var _ContainerServer_serviceDesc = grpc.ServiceDesc{
 			Handler:       _ContainerServer_Logs_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "Events",
+			Handler:       _ContainerServer_Events_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Stats",
+			Handler:       _ContainerServer_Stats_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "CopyFrom",
+			Handler:       _ContainerServer_CopyFrom_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "CopyTo",
+			Handler:       _ContainerServer_CopyTo_Handler,
+			ClientStreams: true,
+		},
 	},
 	Metadata: "api.proto",
 }
-
-func init() { proto.RegisterFile("api.proto", fileDescriptor0) }
-
-var fileDescriptor0 = []byte{
-	// 664 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x54, 0xcd, 0x6e, 0xdb, 0x38,
-	0x10, 0xb6, 0x7e, 0x1c, 0xdb, 0x23, 0xc7, 0x49, 0x88, 0xc5, 0x2e, 0xd7, 0xd9, 0x2d, 0x1c, 0x15,
-	0x29, 0x5c, 0x14, 0x30, 0x12, 0xb7, 0xa7, 0x5e, 0xd3, 0xa0, 0x08, 0x10, 0x24, 0x85, 0x8c, 0xa2,
-	0xc7, 0x40, 0x91, 0x18, 0x99, 0x80, 0x44, 0x12, 0x24, 0x1d, 0xa7, 0x7d, 0x8d, 0x3e, 0x44, 0x1f,
-	0xb0, 0x2f, 0x50, 0x90, 0xa2, 0xe4, 0x20, 0xf1, 0x21, 0xb7, 0xf9, 0x66, 0xbe, 0x19, 0x7d, 0xe2,
-	0x37, 0x24, 0x0c, 0x52, 0x41, 0x67, 0x42, 0x72, 0xcd, 0x51, 0x57, 0xdc, 0x4a, 0x91, 0xc5, 0x87,
-	0xd0, 0x25, 0x95, 0xd0, 0xdf, 0x11, 0x82, 0x30, 0x5d, 0xe9, 0x25, 0xf6, 0x26, 0xde, 0x74, 0x90,
-	0xd8, 0x38, 0xc6, 0x10, 0x0a, 0xce, 0x0a, 0xb4, 0x0f, 0x41, 0xa5, 0x0a, 0x57, 0x32, 0x61, 0xfc,
-	0x0f, 0x04, 0x44, 0x4a, 0x53, 0x20, 0x52, 0x36, 0x05, 0x22, 0x65, 0x7c, 0x0a, 0xd1, 0x19, 0x67,
-	0x3a, 0xa5, 0x8c, 0xc8, 0x8b, 0x4f, 0x68, 0x04, 0x3e, 0xcd, 0x5d, 0xdd, 0xa7, 0x79, 0xfb, 0x15,
-	0xff, 0xd1, 0x57, 0xbe, 0x41, 0xaf, 0xe4, 0xc5, 0xb5, 0xd0, 0x0a, 0x4d, 0xc0, 0xcb, 0x2c, 0x3b,
-	0x9a, 0xa3, 0x99, 0x15, 0x38, 0x7b, 0x34, 0x2d, 0xf1, 0x32, 0xf4, 0x37, 0xec, 0xdc, 0xf1, 0xb2,
-	0xe4, 0x6b, 0x3b, 0xa2, 0x9f, 0x38, 0x64, 0x06, 0xeb, 0x94, 0x96, 0x38, 0xa8, 0x07, 0x9b, 0x38,
-	0xfe, 0x15, 0xc0, 0xa0, 0x6d, 0xdf, 0x26, 0x85, 0xa5, 0x15, 0x69, 0xa4, 0x98, 0x18, 0xfd, 0x05,
-	0x5d, 0x5a, 0xa5, 0x05, 0x71, 0x63, 0x6a, 0x80, 0x30, 0xf4, 0x32, 0x5e, 0x55, 0x29, 0xcb, 0x71,
-	0x68, 0xf3, 0x0d, 0x34, 0x7c, 0xa5, 0x53, 0x4d, 0x70, 0xb7, 0xe6, 0x5b, 0x60, 0x34, 0x9a, 0x60,
-	0xa5, 0xf0, 0x8e, 0x4d, 0x3b, 0x64, 0x4e, 0x8b, 0x0a, 0x85, 0x7b, 0x93, 0xc0, 0x9c, 0x16, 0x15,
-	0xca, 0xf6, 0x2f, 0x49, 0x59, 0xe2, 0xbe, 0xeb, 0x37, 0x00, 0xfd, 0x0b, 0x7d, 0xc1, 0xf3, 0x1b,
-	0xab, 0x6e, 0x50, 0x7f, 0x50, 0xf0, 0xfc, 0xca, 0x08, 0x3c, 0x86, 0x51, 0xd6, 0xfc, 0x51, 0x4d,
-	0x00, 0x4b, 0xd8, 0x6d, 0xb3, 0x96, 0xf6, 0x1f, 0x0c, 0x4c, 0x51, 0x89, 0x34, 0x23, 0x38, 0xb2,
-	0x8c, 0x4d, 0x02, 0x1d, 0xc1, 0x50, 0xae, 0x18, 0xa3, 0xac, 0xb8, 0x61, 0x3c, 0x27, 0x78, 0x68,
-	0x09, 0x91, 0xcb, 0x5d, 0xf1, 0x9c, 0xa0, 0xff, 0x01, 0x4a, 0x9e, 0xdd, 0x28, 0x22, 0xef, 0x89,
-	0xc4, 0xbb, 0xf5, 0x84, 0x92, 0x67, 0x0b, 0x9b, 0x30, 0x27, 0x42, 0x1e, 0x48, 0x76, 0x56, 0xe5,
-	0x78, 0x54, 0x0b, 0x74, 0x10, 0x8d, 0xa1, 0x6f, 0xc2, 0xaf, 0x8a, 0x48, 0xbc, 0x67, 0x4b, 0x2d,
-	0x6e, 0xba, 0xce, 0xd9, 0x3d, 0xde, 0xdf, 0x74, 0x9d, 0xb3, 0xfb, 0x78, 0x06, 0xd0, 0x1a, 0x65,
-	0xb6, 0xc0, 0xcf, 0x14, 0xf6, 0x26, 0xc1, 0x34, 0x9a, 0xef, 0x3f, 0x5d, 0x83, 0xc4, 0xcf, 0x54,
-	0xfc, 0x06, 0x7c, 0xca, 0xad, 0xa3, 0xcc, 0x3a, 0x3a, 0x4c, 0x7c, 0xca, 0xcc, 0xf9, 0xf2, 0x95,
-	0xb6, 0x86, 0x0e, 0x13, 0x13, 0xc6, 0x1f, 0x01, 0xd6, 0x94, 0xe5, 0x7c, 0xbd, 0xa0, 0x3f, 0xac,
-	0x2f, 0x4b, 0x42, 0x8b, 0xa5, 0xb6, 0x3d, 0xdd, 0xc4, 0x21, 0xe3, 0xc2, 0x9a, 0xe6, 0x6e, 0x2b,
-	0xbb, 0x49, 0x0d, 0xe2, 0x9f, 0x1e, 0x44, 0x46, 0xdf, 0xb5, 0xd0, 0x94, 0x33, 0x85, 0x0e, 0x21,
-	0xc8, 0xaa, 0xdc, 0x6d, 0xe7, 0xc0, 0xc9, 0xa2, 0x3c, 0x31, 0x59, 0xf4, 0xca, 0x2c, 0xae, 0x6f,
-	0x4b, 0xcf, 0x15, 0x7b, 0x59, 0x73, 0x51, 0x82, 0xf6, 0xa2, 0xb4, 0x37, 0x21, 0xdc, 0xdc, 0x04,
-	0x74, 0x04, 0xfe, 0x5a, 0xd9, 0x5d, 0x8a, 0xe6, 0x07, 0x6e, 0xcc, 0x46, 0x7f, 0xe2, 0xaf, 0xd5,
-	0xfc, 0xb7, 0x0f, 0x7b, 0xad, 0xd7, 0xce, 0x8d, 0x53, 0xe8, 0x7d, 0x26, 0xfa, 0x82, 0xdd, 0x71,
-	0xb4, 0xe5, 0xd6, 0x8c, 0x9f, 0x09, 0x8a, 0x3b, 0xe8, 0x2d, 0x84, 0x97, 0x54, 0x69, 0x34, 0x74,
-	0x35, 0xfb, 0x06, 0x8c, 0x0f, 0x9e, 0x32, 0x95, 0xa5, 0x76, 0x17, 0x3a, 0x95, 0x7a, 0xeb, 0x6c,
-	0x68, 0xfa, 0xa5, 0x99, 0x3a, 0x85, 0x70, 0xa1, 0xb9, 0x78, 0x01, 0xf3, 0x1d, 0xf4, 0x12, 0xa2,
-	0x5e, 0x38, 0xf6, 0x03, 0x84, 0xe7, 0x0f, 0x24, 0x6b, 0x99, 0x8f, 0x5c, 0x19, 0x6f, 0xc9, 0xc5,
-	0x9d, 0xa9, 0x77, 0xe2, 0xa1, 0xd7, 0x10, 0x7e, 0xa1, 0xac, 0x78, 0xf2, 0x8b, 0x91, 0x43, 0xe6,
-	0x5d, 0x8b, 0x3b, 0xe8, 0x18, 0xc2, 0x4b, 0x5e, 0x28, 0x34, 0x72, 0x69, 0xf7, 0x10, 0x8d, 0x37,
-	0xfe, 0xc6, 0x9d, 0x13, 0xef, 0x76, 0xc7, 0xbe, 0x99, 0xef, 0xff, 0x04, 0x00, 0x00, 0xff, 0xff,
-	0xd5, 0x70, 0x81, 0x92, 0x40, 0x05, 0x00, 0x00,
-}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"time"
 
-	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
 
 	"github.com/wrfly/container-web-tty/container"
+	"github.com/wrfly/container-web-tty/event"
 	pbrpc "github.com/wrfly/container-web-tty/proxy/pb"
 )
 
@@ -18,18 +20,26 @@ type GrpcServer interface {
 }
 
 type grpcServer struct {
-	auth string
-	port int
-	cli  container.Cli
+	auth      string
+	port      int
+	cli       container.Cli
+	events    *event.Hub
+	keepalive time.Duration
 }
 
-// New proxy grpc server
-func New(auth string, port int, cli container.Cli) GrpcServer {
-	logrus.Infof("New grpc server with port %d", port)
+// minPingInterval is the most frequent keepalive ping permitted of the clients
+const minPingInterval = 5 * time.Second
+
+// New proxy grpc server, the events of the hub are relayed to the clients,
+// the clients are pinged every keepalive (0 to disable)
+func New(auth string, port int, cli container.Cli, events *event.Hub, keepalive time.Duration) GrpcServer {
+	log.Infof("New grpc server with port %d", port)
 	return &grpcServer{
-		auth: auth,
-		port: port,
-		cli:  cli,
+		auth:      auth,
+		port:      port,
+		cli:       cli,
+		events:    events,
+		keepalive: keepalive,
 	}
 }
 
@@ -39,18 +49,30 @@ func (gsrv *grpcServer) Run(ctx context.Context, gCtx context.Context) error {
 	if err != nil {
 		return err
 	}
-	srv := grpc.NewServer()
+	opts := []grpc.ServerOption{
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             minPingInterval,
+			PermitWithoutStream: true,
+		}),
+	}
+	if gsrv.keepalive > 0 {
+		opts = append(opts, grpc.KeepaliveParams(keepalive.ServerParameters{
+			Time:    gsrv.keepalive,
+			Timeout: gsrv.keepalive,
+		}))
+	}
+	srv := grpc.NewServer(opts...)
 
-	cs := newContainerService(gsrv.cli, gsrv.auth)
+	cs := newContainerService(gsrv.cli, gsrv.auth, gsrv.events)
 	pbrpc.RegisterContainerServerServer(srv, cs)
 
 	// serve
 	go func() {
-		logrus.Infof("Running grpc server at :%d", gsrv.port)
+		log.Infof("Running grpc server at :%d", gsrv.port)
 		if err := srv.Serve(listener); err != nil {
-			logrus.Errorf("GRPC API server error: %s", err)
+			log.Errorf("GRPC API server error: %s", err)
 		} else {
-			logrus.Infof("GRPC API server stopped: %s", ctx.Err())
+			log.Infof("GRPC API server stopped: %s", ctx.Err())
 		}
 	}()
 
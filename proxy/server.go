@@ -4,28 +4,34 @@ import (
 	"context"
 	"fmt"
 	"io"
-
-	"github.com/sirupsen/logrus"
+	"sync"
+	"time"
 
 	"github.com/wrfly/container-web-tty/container"
+	"github.com/wrfly/container-web-tty/event"
+	"github.com/wrfly/container-web-tty/logging"
 	pb "github.com/wrfly/container-web-tty/proxy/pb"
 	"github.com/wrfly/container-web-tty/types"
 	"github.com/wrfly/container-web-tty/util"
 )
 
+var log = logging.Module("proxy")
+
 const (
 	rpcCanceld = "rpc error: code = Canceled desc = context canceled"
 )
 
 type containerService struct {
-	cli  container.Cli
-	auth string
+	cli    container.Cli
+	auth   string
+	events *event.Hub
 }
 
-func newContainerService(cli container.Cli, auth string) pb.ContainerServerServer {
+func newContainerService(cli container.Cli, auth string, events *event.Hub) pb.ContainerServerServer {
 	return &containerService{
-		cli:  cli,
-		auth: auth,
+		cli:    cli,
+		auth:   auth,
+		events: events,
 	}
 }
 
@@ -74,7 +80,7 @@ func (svc *containerService) GetInfo(ctx context.Context, cid *pb.ContainerID) (
 	}
 
 	c := svc.wrapContainer(svc.cli.GetInfo(ctx, cid.Id))[0]
-	logrus.Debugf("get info of container: %s (%s)", c.Id, c.Shell)
+	log.Debugf("get info of container: %s (%s)", c.Id, c.Shell)
 	return c, nil
 }
 
@@ -92,7 +98,10 @@ func (svc *containerService) List(ctx context.Context, e *pb.Empty) (*pb.Contain
 	}, nil
 }
 
-func (svc *containerService) Start(ctx context.Context, cid *pb.ContainerID) (*pb.Err, error) {
+// action runs the container action, the error of the action is returned
+// in the pb.Err, an empty pb.Err means success
+func (svc *containerService) action(ctx context.Context, cid *pb.ContainerID, name string,
+	fn func(context.Context, string) error) (*pb.Err, error) {
 	if err := checkNil(cid); err != nil {
 		return nil, err
 	}
@@ -101,52 +110,298 @@ func (svc *containerService) Start(ctx context.Context, cid *pb.ContainerID) (*p
 		return nil, err
 	}
 
-	logrus.Debugf("start container: %s", cid.Id)
-	err := svc.cli.Start(ctx, cid.Id)
-	if err == nil {
-		return nil, nil
+	log.Debugf("%s container: %s", name, cid.Id)
+	if err := fn(ctx, cid.Id); err != nil {
+		return &pb.Err{Err: err.Error()}, nil
 	}
-	return &pb.Err{
-		Err: err.Error(),
-	}, nil
+	return &pb.Err{}, nil
+}
+
+func (svc *containerService) Start(ctx context.Context, cid *pb.ContainerID) (*pb.Err, error) {
+	return svc.action(ctx, cid, "start", svc.cli.Start)
 }
 
 func (svc *containerService) Stop(ctx context.Context, cid *pb.ContainerID) (*pb.Err, error) {
+	return svc.action(ctx, cid, "stop", svc.cli.Stop)
+}
+
+func (svc *containerService) Restart(ctx context.Context, cid *pb.ContainerID) (*pb.Err, error) {
+	return svc.action(ctx, cid, "restart", svc.cli.Restart)
+}
+
+func (svc *containerService) Pause(ctx context.Context, cid *pb.ContainerID) (*pb.Err, error) {
+	return svc.action(ctx, cid, "pause", svc.cli.Pause)
+}
+
+func (svc *containerService) Unpause(ctx context.Context, cid *pb.ContainerID) (*pb.Err, error) {
+	return svc.action(ctx, cid, "unpause", svc.cli.Unpause)
+}
+
+func (svc *containerService) Kill(ctx context.Context, opts *pb.KillOpts) (*pb.Err, error) {
+	if opts == nil || opts.C == nil {
+		return nil, fmt.Errorf("nil pointer")
+	}
+	return svc.action(ctx, opts.C, "kill", func(ctx context.Context, id string) error {
+		return svc.cli.Kill(ctx, id, opts.Signal)
+	})
+}
+
+func (svc *containerService) Top(ctx context.Context, cid *pb.ContainerID) (*pb.Processes, error) {
 	if err := checkNil(cid); err != nil {
 		return nil, err
 	}
-
 	if err := svc.checkAuth(cid.Auth); err != nil {
 		return nil, err
 	}
 
-	logrus.Debugf("stop container: %s", cid.Id)
-	err := svc.cli.Stop(ctx, cid.Id)
-	if err == nil {
-		return nil, nil
+	log.Debugf("get processes of container: %s", cid.Id)
+	top, err := svc.cli.Top(ctx, cid.Id)
+	if err != nil {
+		return nil, err
 	}
-	return &pb.Err{
-		Err: err.Error(),
-	}, nil
+	ps := &pb.Processes{Titles: top.Titles}
+	for _, p := range top.Processes {
+		ps.Ps = append(ps.Ps, &pb.Process{Fields: p})
+	}
+	return ps, nil
 }
 
-func (svc *containerService) Restart(ctx context.Context, cid *pb.ContainerID) (*pb.Err, error) {
+// Inspect returns the detail without masking, the secrets are
+// masked by the server serving the browsers
+func (svc *containerService) Inspect(ctx context.Context, cid *pb.ContainerID) (*pb.Detail, error) {
 	if err := checkNil(cid); err != nil {
 		return nil, err
 	}
+	if err := svc.checkAuth(cid.Auth); err != nil {
+		return nil, err
+	}
+
+	log.Debugf("inspect container: %s", cid.Id)
+	detail, err := svc.cli.Inspect(ctx, cid.Id)
+	if err != nil {
+		return nil, err
+	}
+	d := &pb.Detail{}
+	for _, e := range detail.Env {
+		d.Env = append(d.Env, &pb.EnvVar{Name: e.Name, Value: e.Value})
+	}
+	for _, m := range detail.Mounts {
+		d.Mounts = append(d.Mounts, &pb.Mount{
+			Type:        m.Type,
+			Source:      m.Source,
+			Destination: m.Destination,
+			ReadOnly:    m.ReadOnly,
+		})
+	}
+	if h := detail.Health; h != nil {
+		d.Health = &pb.Health{
+			Cmd:           h.Cmd,
+			Status:        h.Status,
+			FailingStreak: int32(h.FailingStreak),
+		}
+		for _, p := range h.Probes {
+			d.Health.Probes = append(d.Health.Probes, &pb.HealthProbe{
+				Start:    p.Start.UnixNano(),
+				End:      p.End.UnixNano(),
+				ExitCode: int32(p.ExitCode),
+				Output:   p.Output,
+			})
+		}
+	}
+	return d, nil
+}
+
+func (svc *containerService) CopyFrom(opts *pb.CopyOpts, stream pb.ContainerServer_CopyFromServer) error {
+	if opts == nil || opts.C == nil {
+		return fmt.Errorf("nil pointer")
+	}
+	if err := svc.checkAuth(opts.C.Auth); err != nil {
+		return err
+	}
+
+	log.Debugf("copy %s from container: %s", opts.Path, opts.C.Id)
+	rc, err := svc.cli.CopyFrom(stream.Context(), opts.C.Id, opts.Path)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	buff := make([]byte, 32<<10)
+	for {
+		n, err := rc.Read(buff)
+		if n > 0 {
+			if err := stream.Send(&pb.Io{Out: buff[:n]}); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (svc *containerService) CopyTo(stream pb.ContainerServer_CopyToServer) error {
+	opts, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	if opts.C == nil {
+		return fmt.Errorf("nil container")
+	}
+	if err := svc.checkAuth(opts.C.Auth); err != nil {
+		return err
+	}
+
+	log.Debugf("copy to %s of container: %s", opts.Path, opts.C.Id)
+	pr, pw := io.Pipe()
+	go func() {
+		for {
+			chunk, err := stream.Recv()
+			if err != nil {
+				if err == io.EOF {
+					err = nil
+				}
+				pw.CloseWithError(err)
+				return
+			}
+			if _, err := pw.Write(chunk.Data); err != nil {
+				return
+			}
+		}
+	}()
+	err = svc.cli.CopyTo(stream.Context(), opts.C.Id, opts.Path, pr)
+	pr.Close()
+	if err != nil {
+		return stream.SendAndClose(&pb.Err{Err: err.Error()})
+	}
+	return stream.SendAndClose(&pb.Err{})
+}
 
+func (svc *containerService) Diff(ctx context.Context, cid *pb.ContainerID) (*pb.Changes, error) {
+	if err := checkNil(cid); err != nil {
+		return nil, err
+	}
 	if err := svc.checkAuth(cid.Auth); err != nil {
 		return nil, err
 	}
 
-	logrus.Debugf("restart container: %s", cid.Id)
-	err := svc.cli.Restart(ctx, cid.Id)
-	if err == nil {
-		return nil, nil
+	log.Debugf("get changes of container: %s", cid.Id)
+	diff, err := svc.cli.Diff(ctx, cid.Id)
+	if err != nil {
+		return nil, err
 	}
-	return &pb.Err{
-		Err: err.Error(),
-	}, nil
+	changes := &pb.Changes{}
+	for _, c := range diff {
+		changes.Cs = append(changes.Cs, &pb.Change{Kind: c.Kind, Path: c.Path})
+	}
+	return changes, nil
+}
+
+func (svc *containerService) Commit(ctx context.Context, opts *pb.CommitOpts) (*pb.ImageID, error) {
+	if opts == nil || opts.C == nil {
+		return nil, fmt.Errorf("nil pointer")
+	}
+	if err := svc.checkAuth(opts.C.Auth); err != nil {
+		return nil, err
+	}
+
+	log.Debugf("commit container %s to %s", opts.C.Id, opts.Image)
+	id, err := svc.cli.Commit(ctx, opts.C.Id, types.CommitOptions{
+		Image:   opts.Image,
+		Comment: opts.Comment,
+		Pause:   opts.Pause,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ImageID{Id: id}, nil
+}
+
+func (svc *containerService) Create(ctx context.Context, opts *pb.CreateOpts) (*pb.ContainerID, error) {
+	if opts == nil {
+		return nil, fmt.Errorf("nil pointer")
+	}
+	if err := svc.checkAuth(opts.Auth); err != nil {
+		return nil, err
+	}
+
+	log.Debugf("create container of %s", opts.Image)
+	id, err := svc.cli.Create(ctx, types.CreateOptions{
+		Image:   opts.Image,
+		Name:    opts.Name,
+		Cmd:     opts.Cmd,
+		Env:     opts.Env,
+		Ports:   opts.Ports,
+		Volumes: opts.Volumes,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ContainerID{Id: id}, nil
+}
+
+func (svc *containerService) Debug(ctx context.Context, opts *pb.DebugOpts) (*pb.ContainerID, error) {
+	if opts == nil || opts.C == nil {
+		return nil, fmt.Errorf("nil pointer")
+	}
+	if err := svc.checkAuth(opts.C.Auth); err != nil {
+		return nil, err
+	}
+
+	log.Debugf("debug container %s with %s", opts.C.Id, opts.Image)
+	id, err := svc.cli.Debug(ctx, opts.C.Id, types.DebugOptions{Image: opts.Image})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ContainerID{Id: id}, nil
+}
+
+func (svc *containerService) Prune(ctx context.Context, opts *pb.PruneOpts) (*pb.PruneReport, error) {
+	if opts == nil {
+		return nil, fmt.Errorf("nil pointer")
+	}
+	if err := svc.checkAuth(opts.Auth); err != nil {
+		return nil, err
+	}
+
+	log.Debugf("prune %s (dry run: %v)", opts.Kind, opts.DryRun)
+	report, err := svc.cli.Prune(ctx, opts.Kind, opts.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	pbReport := &pb.PruneReport{Reclaimed: report.Reclaimed}
+	for _, item := range report.Items {
+		pbReport.Items = append(pbReport.Items, &pb.PruneItem{
+			Id:   item.ID,
+			Name: item.Name,
+			Size: item.Size,
+		})
+	}
+	return pbReport, nil
+}
+
+func (svc *containerService) Rename(ctx context.Context, opts *pb.RenameOpts) (*pb.Err, error) {
+	if opts == nil || opts.C == nil {
+		return nil, fmt.Errorf("nil pointer")
+	}
+	return svc.action(ctx, opts.C, "rename", func(ctx context.Context, id string) error {
+		return svc.cli.Rename(ctx, id, opts.Name)
+	})
+}
+
+func (svc *containerService) UpdateLabels(ctx context.Context, update *pb.LabelsUpdate) (*pb.Err, error) {
+	if update == nil || update.C == nil {
+		return nil, fmt.Errorf("nil pointer")
+	}
+	return svc.action(ctx, update.C, "update labels of", func(ctx context.Context, id string) error {
+		return svc.cli.UpdateLabels(ctx, id, types.LabelsUpdate{
+			Set:    update.Set,
+			Remove: update.Remove,
+		})
+	})
 }
 
 func (svc *containerService) Exec(stream pb.ContainerServer_ExecServer) error {
@@ -161,14 +416,14 @@ func (svc *containerService) Exec(stream pb.ContainerServer_ExecServer) error {
 	if execOpts.C == nil {
 		return fmt.Errorf("nil container")
 	}
-	logrus.Debugf("grpc server exec into container: %s", execOpts.C.Id)
+	log.Debugf("grpc server exec into container: %s", execOpts.C.Id)
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 	container := util.ConvertPbContainer(execOpts.C)
-	logrus.Debugf("container info: %v", container)
+	log.Debugf("container info: %v", container)
 	tty, err := svc.cli.Exec(ctx, container)
 	if err != nil {
-		logrus.Errorf("grpc server exec error: %s", err)
+		log.Errorf("grpc server exec error: %s", err)
 		return err
 	}
 	defer tty.Exit()
@@ -180,7 +435,7 @@ func (svc *containerService) Exec(stream pb.ContainerServer_ExecServer) error {
 				if err.Error() == rpcCanceld || err == io.EOF {
 					break
 				}
-				logrus.Debugf("grpc receive outputs error: %s", err)
+				log.Debugf("grpc receive outputs error: %s", err)
 				break
 			}
 			if execOpts == nil {
@@ -188,48 +443,75 @@ func (svc *containerService) Exec(stream pb.ContainerServer_ExecServer) error {
 			}
 			// resize terminal
 			if ws := execOpts.Ws; ws != nil {
-				logrus.Debugf("resize window to %dx%d", ws.Width, ws.Height)
+				log.Debugf("resize window to %dx%d", ws.Width, ws.Height)
 				tty.ResizeTerminal(int(ws.Width), int(ws.Height))
 				continue
 			}
-			// logrus.Debugf("tty write: %s", execOpts.Cmd.In)
+			// log.Debugf("tty write: %s", execOpts.Cmd.In)
 			_, err = tty.Write(execOpts.Cmd.In)
 			if err == io.EOF {
 				continue
 			}
 			if err != nil {
-				logrus.Debugf("tty write error: %s", err)
+				log.Debugf("tty write error: %s", err)
 				break
 			}
 		}
-		logrus.Debugf("grpc server receive done, break")
+		log.Debugf("grpc server receive done, break")
 	}()
 
+	// stream.Send is not safe to call in different goroutines
+	var sendMutex sync.Mutex
+	send := func(cmd *pb.Io) error {
+		sendMutex.Lock()
+		defer sendMutex.Unlock()
+		return stream.Send(&pb.ExecOptions{Cmd: cmd})
+	}
+
+	if stderr := tty.Stderr(); stderr != nil {
+		go func() {
+			bs := make([]byte, 1024)
+			for {
+				n, err := stderr.Read(bs)
+				if n > 0 {
+					if err := send(&pb.Io{Err: bs[:n]}); err != nil {
+						return
+					}
+				}
+				if err != nil {
+					return
+				}
+			}
+		}()
+	}
+
 	bs := make([]byte, 1024)
 	for {
 		n, err := tty.Read(bs)
 		if err == io.EOF {
 			break
 		}
-		// logrus.Debugf("tty read: %s", bs[:n])
-		err = stream.Send(&pb.ExecOptions{
-			Cmd: &pb.Io{
-				Out: bs[:n],
-			},
+		// log.Debugf("tty read: %s", bs[:n])
+		err = send(&pb.Io{
+			Out: bs[:n],
 		})
 		if err == io.EOF {
 			continue
 		}
 		if err != nil {
 			if err.Error() != rpcCanceld {
-				logrus.Debugf("grpc send command error: %s", err)
+				log.Debugf("grpc send command error: %s", err)
 			}
 			break
 		}
 	}
-	logrus.Debugf("tty read done, break")
+	log.Debugf("tty read done, break")
 
-	logrus.Debugf("grpc exec done")
+	if code, err := tty.ExitCode(); err == nil {
+		send(&pb.Io{Exited: true, ExitCode: int32(code)})
+	}
+
+	log.Debugf("grpc exec done")
 	return nil
 }
 
@@ -243,22 +525,28 @@ func (svc *containerService) Logs(logOpts *pb.LogOpts, stream pb.ContainerServer
 		return err
 	}
 
-	logrus.Debugf("get container logs: %s", cid.Id)
-	rc, err := svc.cli.Logs(stream.Context(), types.LogOptions{
-		Follow: logOpts.Follow,
-		Tail:   logOpts.Tail,
-		ID:     cid.Id,
-	})
+	log.Debugf("get container logs: %s", cid.Id)
+	opts := types.LogOptions{
+		Follow:     logOpts.Follow,
+		Tail:       logOpts.Tail,
+		ID:         cid.Id,
+		Timestamps: logOpts.Timestamps,
+	}
+	if logOpts.Since != 0 {
+		opts.Since = time.Unix(logOpts.Since, 0)
+	}
+	rc, err := svc.cli.Logs(stream.Context(), opts)
 	if err != nil {
 		return err
 	}
+	defer rc.Close()
 
 	buff := make([]byte, 2048)
 	for {
 		n, err := rc.Read(buff)
 		if err != nil {
 			if err != context.Canceled {
-				logrus.Errorf("read logs error: %s", err)
+				log.Errorf("read logs error: %s", err)
 			}
 			break
 		}
@@ -271,3 +559,95 @@ func (svc *containerService) Logs(logOpts *pb.LogOpts, stream pb.ContainerServer
 
 	return nil
 }
+
+func (svc *containerService) Run(ctx context.Context, execOpts *pb.ExecOptions) (*pb.RunResult, error) {
+	if err := checkNil(execOpts); err != nil {
+		return nil, err
+	}
+	if err := svc.checkAuth(execOpts.Auth); err != nil {
+		return nil, err
+	}
+	if execOpts.C == nil {
+		return nil, fmt.Errorf("nil container")
+	}
+
+	container := util.ConvertPbContainer(execOpts.C)
+	log.Debugf("grpc server run in container: %s", container.ID)
+	result, err := svc.cli.Run(ctx, container)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.RunResult{
+		Stdout:    []byte(result.Stdout),
+		Stderr:    []byte(result.Stderr),
+		ExitCode:  int32(result.ExitCode),
+		Truncated: result.Truncated,
+		Duration:  result.Duration,
+	}, nil
+}
+
+func (svc *containerService) Events(e *pb.Empty, stream pb.ContainerServer_EventsServer) error {
+	if err := checkNil(e); err != nil {
+		return err
+	}
+	if err := svc.checkAuth(e.Auth); err != nil {
+		return err
+	}
+	log.Debugf("grpc server subscribe events")
+
+	sub, cancel := svc.events.Subscribe(50)
+	defer cancel()
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case e, ok := <-sub:
+			if !ok {
+				return nil
+			}
+			err := stream.Send(&pb.Event{
+				Action: e.Action,
+				Id:     e.ID,
+				Name:   e.Name,
+				Time:   e.Time.UnixNano(),
+				Detail: e.Detail,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (svc *containerService) Stats(cid *pb.ContainerID, stream pb.ContainerServer_StatsServer) error {
+	if err := checkNil(cid); err != nil {
+		return err
+	}
+	if err := svc.checkAuth(cid.Auth); err != nil {
+		return err
+	}
+
+	log.Debugf("get container stats: %s", cid.Id)
+	stats, err := svc.cli.Stats(stream.Context(), cid.Id)
+	if err != nil {
+		return err
+	}
+	for s := range stats {
+		err := stream.Send(&pb.Stats{
+			Time:        s.Time.UnixNano(),
+			CpuPercent:  s.CPUPercent,
+			MemoryUsage: s.MemoryUsage,
+			MemoryLimit: s.MemoryLimit,
+			NetworkRx:   s.NetworkRx,
+			NetworkTx:   s.NetworkTx,
+			BlockRead:   s.BlockRead,
+			BlockWrite:  s.BlockWrite,
+			Pids:        s.PIDs,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
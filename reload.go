@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/urfave/cli.v2"
+
+	"github.com/wrfly/container-web-tty/config"
+	"github.com/wrfly/container-web-tty/route"
+)
+
+// configWatchInterval is the interval of checking the config file of
+// --config-watch
+const configWatchInterval = 5 * time.Second
+
+// reloadOnHangup reloads the config on SIGHUP, or once the config file is
+// changed with --config-watch, until the ctx is done
+func reloadOnHangup(ctx context.Context, c *cli.Context, srv *route.Server) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	path := configPath(c)
+	var watch <-chan time.Time
+	var mod time.Time
+	if c.Bool("config-watch") && path != "" {
+		ticker := time.NewTicker(configWatchInterval)
+		defer ticker.Stop()
+		watch = ticker.C
+		mod = modTime(path)
+	}
+	for {
+		select {
+		case <-hup:
+			logrus.Info("reloading the config on SIGHUP")
+		case <-watch:
+			m := modTime(path)
+			if m.Equal(mod) {
+				continue
+			}
+			mod = m
+			logrus.Infof("reloading the config, %s is changed", path)
+		case <-ctx.Done():
+			return
+		}
+		sdNotify("RELOADING=1")
+		if err := reload(c, srv); err != nil {
+			logrus.Errorf("reload the config error: %s, the old one is kept", err)
+		}
+		sdNotify("READY=1")
+	}
+}
+
+// reload parses the args, the env and the config file again as they're
+// at the start, and applies the reloadable options to the server
+func reload(c *cli.Context, srv *route.Server) error {
+	conf := config.New()
+	flags := appFlags(conf)
+	set := flag.NewFlagSet(c.App.Name, flag.ContinueOnError)
+	set.SetOutput(ioutil.Discard)
+	for _, f := range flags {
+		if ef, ok := f.(interface{ ApplyWithError(*flag.FlagSet) error }); ok {
+			if err := ef.ApplyWithError(set); err != nil {
+				return err
+			}
+		} else {
+			f.Apply(set)
+		}
+	}
+	if err := set.Parse(os.Args[1:]); err != nil {
+		return err
+	}
+	rc := cli.NewContext(&cli.App{Name: c.App.Name, Flags: flags}, set, nil)
+	if err := setup(rc, conf, configPath(rc)); err != nil {
+		return err
+	}
+	options, err := serverOptions(*conf)
+	if err != nil {
+		return err
+	}
+	return srv.Reload(options)
+}
+
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,517 @@
+// prune the unused resources with the admin API, a preview is a dry run
+
+(function () {
+    var table = document.getElementById("prune");
+    if (table === null) {
+        return;
+    }
+    if (typeof gotty_banner !== "undefined" && gotty_banner) {
+        var banner = document.getElementById("banner");
+        banner.textContent = gotty_banner;
+        banner.hidden = false;
+    }
+    var token = document.getElementById("admin-token");
+    // the token is kept only if the sessions are disabled
+    token.value = sessionStorage.getItem("admin-token") || "";
+    token.onchange = function () {
+        adminLogin(token.value, function (ok) {
+            if (!ok) {
+                sessionStorage.setItem("admin-token", token.value);
+                return;
+            }
+            sessionStorage.removeItem("admin-token");
+            token.value = "";
+            token.placeholder = "logged in";
+            loadMaintenance();
+            loadErrors();
+            loadJournal();
+            loadUpdate();
+        });
+    };
+
+    function size(bytes) {
+        var units = ["B", "KiB", "MiB", "GiB", "TiB"];
+        var i = 0;
+        while (bytes >= 1024 && i < units.length - 1) {
+            bytes /= 1024;
+            i++;
+        }
+        return (i == 0 ? bytes : bytes.toFixed(1)) + " " + units[i];
+    }
+
+    function render(report) {
+        var list = document.getElementById("prune-items");
+        list.innerHTML = "";
+        report.items.forEach(function (item) {
+            var li = document.createElement("li");
+            var s = document.createElement("span");
+            s.textContent = item.size > 0 ? size(item.size) : "-";
+            li.appendChild(s);
+            var name = item.id.substring(0, 19);
+            if (item.name && item.name != item.id) {
+                name += " " + item.name;
+            }
+            li.appendChild(document.createTextNode(name));
+            list.appendChild(li);
+        });
+        document.getElementById("prune-summary").textContent =
+            (report.dryRun ? "would remove " : "removed ") + report.items.length + " " +
+            report.kind + ", " + (report.dryRun ? "about " : "") + size(report.reclaimed) + " reclaimed";
+    }
+
+    // the solution of the login challenge, the request is retried
+    // once with it
+    function prune(kind, dryRun, solution) {
+        if (!dryRun && !solution && !confirm("prune the unused " + kind + "? this cannot be undone")) {
+            return;
+        }
+        var errP = document.getElementById("prune-error");
+        errP.textContent = "";
+        var xmlhttp = new XMLHttpRequest();
+        xmlhttp.open(dryRun ? "GET" : "POST", gotty_base_path + "/api/admin/prune/" + kind);
+        xmlhttp.setRequestHeader("X-CSRF-Token", csrfToken());
+        if (token.value) {
+            xmlhttp.setRequestHeader("Authorization", "Bearer " + token.value);
+        }
+        if (solution) {
+            xmlhttp.setRequestHeader("X-Login-Solution", solution);
+        }
+        xmlhttp.onreadystatechange = function () {
+            if (xmlhttp.readyState != 4) {
+                return;
+            }
+            var nonce;
+            if (xmlhttp.status == 401 && !solution && (nonce = solveChallenge(xmlhttp)) !== null) {
+                prune(kind, dryRun, nonce);
+                return;
+            }
+            try {
+                var j = JSON.parse(xmlhttp.responseText);
+                if (xmlhttp.status != 200) {
+                    errP.textContent = j.message;
+                    return;
+                }
+                render(j);
+            } catch (error) {
+                errP.textContent = "bad response: " + xmlhttp.status;
+            }
+        };
+        xmlhttp.send();
+    }
+
+    function renderMaintenance(m) {
+        document.getElementById("maintenance-status").textContent = m.enabled ?
+            "on since " + formatTime(m.since) + ": " + m.message : "off";
+    }
+
+    // GET the maintenance mode, or PUT it on or off
+    function loadMaintenance(set, solution) {
+        var errP = document.getElementById("maintenance-error");
+        errP.textContent = "";
+        var xmlhttp = new XMLHttpRequest();
+        xmlhttp.open(set ? "PUT" : "GET", gotty_base_path + "/api/admin/maintenance");
+        xmlhttp.setRequestHeader("X-CSRF-Token", csrfToken());
+        if (token.value) {
+            xmlhttp.setRequestHeader("Authorization", "Bearer " + token.value);
+        }
+        if (solution) {
+            xmlhttp.setRequestHeader("X-Login-Solution", solution);
+        }
+        xmlhttp.onreadystatechange = function () {
+            if (xmlhttp.readyState != 4) {
+                return;
+            }
+            var nonce;
+            if (xmlhttp.status == 401 && !solution && (nonce = solveChallenge(xmlhttp)) !== null) {
+                loadMaintenance(set, nonce);
+                return;
+            }
+            try {
+                var j = JSON.parse(xmlhttp.responseText);
+                if (xmlhttp.status != 200) {
+                    errP.textContent = j.message;
+                    return;
+                }
+                renderMaintenance(j);
+            } catch (error) {
+                errP.textContent = "bad response: " + xmlhttp.status;
+            }
+        };
+        xmlhttp.send(set ? JSON.stringify(set) : null);
+    }
+    document.getElementById("maintenance-on").onclick = function () {
+        loadMaintenance({ enabled: true, message: document.getElementById("maintenance-message").value });
+    };
+    document.getElementById("maintenance-off").onclick = function () {
+        loadMaintenance({ enabled: false });
+    };
+
+    function renderErrors(reports) {
+        var list = document.getElementById("errors");
+        list.innerHTML = "";
+        reports.forEach(function (r) {
+            var li = document.createElement("li");
+            var details = document.createElement("details");
+            var summary = document.createElement("summary");
+            summary.textContent = formatTime(r.time) + " " +
+                (r.method || "") + " " + (r.path || "") + ": " + r.message;
+            details.appendChild(summary);
+            var pre = document.createElement("pre");
+            pre.textContent = "id: " + r.id + "\nrequest: " + (r.request_id || "-") +
+                "\nclient: " + (r.client || "-") + "\n\n" + r.stack;
+            details.appendChild(pre);
+            li.appendChild(details);
+            list.appendChild(li);
+        });
+        if (reports.length == 0) {
+            list.textContent = "no panics";
+        }
+    }
+
+    function loadErrors(solution) {
+        var errP = document.getElementById("errors-error");
+        errP.textContent = "";
+        var xmlhttp = new XMLHttpRequest();
+        xmlhttp.open("GET", gotty_base_path + "/api/admin/errors");
+        if (token.value) {
+            xmlhttp.setRequestHeader("Authorization", "Bearer " + token.value);
+        }
+        if (solution) {
+            xmlhttp.setRequestHeader("X-Login-Solution", solution);
+        }
+        xmlhttp.onreadystatechange = function () {
+            if (xmlhttp.readyState != 4) {
+                return;
+            }
+            var nonce;
+            if (xmlhttp.status == 401 && !solution && (nonce = solveChallenge(xmlhttp)) !== null) {
+                loadErrors(nonce);
+                return;
+            }
+            try {
+                var j = JSON.parse(xmlhttp.responseText);
+                if (xmlhttp.status != 200) {
+                    errP.textContent = j.message;
+                    return;
+                }
+                renderErrors(j);
+            } catch (error) {
+                errP.textContent = "bad response: " + xmlhttp.status;
+            }
+        };
+        xmlhttp.send();
+    }
+    document.getElementById("errors-refresh").onclick = function () { loadErrors(); };
+
+    // the bundle is downloaded with the token, then saved by a link to it
+    function downloadDiagnostics(solution) {
+        var errP = document.getElementById("diagnostics-error");
+        errP.textContent = "";
+        var xmlhttp = new XMLHttpRequest();
+        xmlhttp.open("GET", gotty_base_path + "/api/admin/diagnostics");
+        xmlhttp.responseType = "blob";
+        if (token.value) {
+            xmlhttp.setRequestHeader("Authorization", "Bearer " + token.value);
+        }
+        if (solution) {
+            xmlhttp.setRequestHeader("X-Login-Solution", solution);
+        }
+        xmlhttp.onreadystatechange = function () {
+            if (xmlhttp.readyState != 4) {
+                return;
+            }
+            var nonce;
+            if (xmlhttp.status == 401 && !solution && (nonce = solveChallenge(xmlhttp)) !== null) {
+                downloadDiagnostics(nonce);
+                return;
+            }
+            if (xmlhttp.status != 200) {
+                errP.textContent = "download error: " + xmlhttp.status;
+                return;
+            }
+            var name = /filename="([^"]+)"/.exec(xmlhttp.getResponseHeader("Content-Disposition") || "");
+            var a = document.createElement("a");
+            a.href = URL.createObjectURL(xmlhttp.response);
+            a.download = name ? name[1] : "diagnostics.tar.gz";
+            document.body.appendChild(a);
+            a.click();
+            document.body.removeChild(a);
+            URL.revokeObjectURL(a.href);
+        };
+        xmlhttp.send();
+    }
+    document.getElementById("diagnostics").onclick = function () { downloadDiagnostics(); };
+
+    // the events of the journal, newest first, the older ones are
+    // appended before the last ID listed
+    var journalLast = 0;
+    function renderJournal(events, more) {
+        var list = document.getElementById("journal");
+        if (!more) {
+            list.innerHTML = "";
+        }
+        events.forEach(function (e) {
+            var li = document.createElement("li");
+            var s = document.createElement("span");
+            s.textContent = formatTime(e.time) + " " + e.kind;
+            li.appendChild(s);
+            var text = e.message;
+            for (var k in e.fields || {}) {
+                text += " " + k + "=" + e.fields[k];
+            }
+            li.appendChild(document.createTextNode(text));
+            list.appendChild(li);
+            journalLast = e.id;
+        });
+        if (!more && events.length == 0) {
+            list.textContent = "no events";
+        }
+        document.getElementById("journal-older").disabled = events.length < 100;
+    }
+
+    function loadJournal(before, solution) {
+        var errP = document.getElementById("journal-error");
+        errP.textContent = "";
+        var q = "?limit=100";
+        var kind = document.getElementById("journal-kind").value;
+        if (kind) {
+            q += "&kind=" + encodeURIComponent(kind);
+        }
+        if (before) {
+            q += "&before=" + before;
+        }
+        var xmlhttp = new XMLHttpRequest();
+        xmlhttp.open("GET", gotty_base_path + "/api/admin/journal" + q);
+        if (token.value) {
+            xmlhttp.setRequestHeader("Authorization", "Bearer " + token.value);
+        }
+        if (solution) {
+            xmlhttp.setRequestHeader("X-Login-Solution", solution);
+        }
+        xmlhttp.onreadystatechange = function () {
+            if (xmlhttp.readyState != 4) {
+                return;
+            }
+            var nonce;
+            if (xmlhttp.status == 401 && !solution && (nonce = solveChallenge(xmlhttp)) !== null) {
+                loadJournal(before, nonce);
+                return;
+            }
+            try {
+                var j = JSON.parse(xmlhttp.responseText);
+                if (xmlhttp.status != 200) {
+                    errP.textContent = j.message;
+                    return;
+                }
+                renderJournal(j, !!before);
+            } catch (error) {
+                errP.textContent = "bad response: " + xmlhttp.status;
+            }
+        };
+        xmlhttp.send();
+    }
+    document.getElementById("journal-kind").onchange = function () { loadJournal(); };
+    document.getElementById("journal-refresh").onclick = function () { loadJournal(); };
+    document.getElementById("journal-older").onclick = function () { loadJournal(journalLast); };
+
+    // the latest release of the update check, nothing is shown if it's
+    // disabled or this is the latest
+    function loadUpdate() {
+        var xmlhttp = new XMLHttpRequest();
+        xmlhttp.open("GET", gotty_base_path + "/api/admin/update");
+        if (token.value) {
+            xmlhttp.setRequestHeader("Authorization", "Bearer " + token.value);
+        }
+        xmlhttp.onreadystatechange = function () {
+            if (xmlhttp.readyState != 4 || xmlhttp.status != 200) {
+                return;
+            }
+            try {
+                var j = JSON.parse(xmlhttp.responseText);
+                if (!j.available) {
+                    return;
+                }
+                var p = document.getElementById("update");
+                var a = document.createElement("a");
+                a.href = j.url;
+                a.textContent = j.latest;
+                p.textContent = "A new release is available: ";
+                p.appendChild(a);
+                p.appendChild(document.createTextNode(", this is " + j.current + "."));
+                p.hidden = false;
+            } catch (error) {
+                return;
+            }
+        };
+        xmlhttp.send();
+    }
+
+    var rows = table.querySelectorAll("tr");
+    for (var i = 0; i < rows.length; ++i) {
+        var kind = rows[i].getAttribute("data-kind");
+        (function (kind) {
+            rows[i].querySelector(".preview").onclick = function () { prune(kind, true); };
+            rows[i].querySelector(".prune").onclick = function () { prune(kind, false); };
+        })(kind);
+    }
+})();
+
+// the active sessions and their bytes, refreshed every 5 seconds
+
+(function () {
+    var table = document.getElementById("sessions");
+    if (table === null) {
+        return;
+    }
+
+    function size(bytes) {
+        var units = ["B", "KiB", "MiB", "GiB", "TiB"];
+        var i = 0;
+        while (bytes >= 1024 && i < units.length - 1) {
+            bytes /= 1024;
+            i++;
+        }
+        return (i == 0 ? bytes : bytes.toFixed(1)) + " " + units[i];
+    }
+
+    function render(sessions) {
+        var tbody = table.querySelector("tbody");
+        tbody.innerHTML = "";
+        sessions.forEach(function (s) {
+            if (s.end_at) {
+                return;
+            }
+            var tr = document.createElement("tr");
+            [
+                s.container_id.substring(0, 12),
+                s.client,
+                s.cmd || "(shell)",
+                formatTime(s.start_at),
+                size(s.bytes_in),
+                size(s.bytes_out)
+            ].forEach(function (c) {
+                var td = document.createElement("td");
+                td.textContent = c;
+                tr.appendChild(td);
+            });
+            tbody.appendChild(tr);
+        });
+    }
+
+    function refresh() {
+        var errP = document.getElementById("sessions-error");
+        var xmlhttp = new XMLHttpRequest();
+        // the active sessions are listed first
+        xmlhttp.open("GET", gotty_base_path + "/api/sessions?limit=500");
+        xmlhttp.onreadystatechange = function () {
+            if (xmlhttp.readyState != 4) {
+                return;
+            }
+            try {
+                var j = JSON.parse(xmlhttp.responseText);
+                if (xmlhttp.status != 200) {
+                    errP.textContent = j.message;
+                    return;
+                }
+                errP.textContent = "";
+                render(j.items);
+            } catch (error) {
+                errP.textContent = "bad response: " + xmlhttp.status;
+            }
+        };
+        xmlhttp.send();
+    }
+
+    refresh();
+    setInterval(refresh, 5000);
+})();
+
+// the daily usage of the closed sessions by the users and the containers
+
+(function () {
+    var table = document.getElementById("usage");
+    if (table === null) {
+        return;
+    }
+
+    function size(bytes) {
+        var units = ["B", "KiB", "MiB", "GiB", "TiB"];
+        var i = 0;
+        while (bytes >= 1024 && i < units.length - 1) {
+            bytes /= 1024;
+            i++;
+        }
+        return (i == 0 ? bytes : bytes.toFixed(1)) + " " + units[i];
+    }
+
+    function duration(seconds) {
+        var h = Math.floor(seconds / 3600);
+        var m = Math.floor(seconds % 3600 / 60);
+        return h + "h" + (m < 10 ? "0" : "") + m + "m";
+    }
+
+    function query(format) {
+        var q = [];
+        ["by", "from", "to"].forEach(function (name) {
+            var v = document.getElementById("usage-" + name).value;
+            if (v) {
+                q.push(name + "=" + encodeURIComponent(v));
+            }
+        });
+        if (format) {
+            q.push("format=" + format);
+        }
+        return gotty_base_path + "/api/reports" + (q.length ? "?" + q.join("&") : "");
+    }
+
+    function render(report) {
+        var tbody = table.querySelector("tbody");
+        tbody.innerHTML = "";
+        var by = document.getElementById("usage-by").value;
+        report.forEach(function (u) {
+            var tr = document.createElement("tr");
+            [
+                u.day,
+                // the default user of the container is empty
+                by == "container" ? "" : u.user || "(default)",
+                (u.container_id || "").substring(0, 12),
+                u.sessions,
+                duration(u.seconds),
+                size(u.bytes_in),
+                size(u.bytes_out)
+            ].forEach(function (c) {
+                var td = document.createElement("td");
+                td.textContent = c;
+                tr.appendChild(td);
+            });
+            tbody.appendChild(tr);
+        });
+    }
+
+    function load() {
+        var errP = document.getElementById("usage-error");
+        document.getElementById("usage-csv").href = query("csv");
+        var xmlhttp = new XMLHttpRequest();
+        xmlhttp.open("GET", query());
+        xmlhttp.onreadystatechange = function () {
+            if (xmlhttp.readyState != 4) {
+                return;
+            }
+            try {
+                var j = JSON.parse(xmlhttp.responseText);
+                if (xmlhttp.status != 200) {
+                    errP.textContent = j.message;
+                    return;
+                }
+                errP.textContent = "";
+                render(j);
+            } catch (error) {
+                errP.textContent = "bad response: " + xmlhttp.status;
+            }
+        };
+        xmlhttp.send();
+    }
+
+    document.getElementById("usage-load").onclick = load;
+    load();
+})();
@@ -4,11 +4,33 @@ try {
     var htmlBtns = document.getElementsByTagName('button');
     for (var i = 0; i < htmlBtns.length; ++i) {
         htmlBtns[i].onclick = function () {
-            var cid = this.parentElement.parentElement.querySelector('a').getAttribute('value');
+            // the token of the container if the URLs are signed, and its ID
+            var link = this.parentElement.parentElement.querySelector('a');
+            var cid = link.getAttribute('value');
+            var short = link.getAttribute('data-id').substring(0, 8);
             var action = this.title;
-            var u = "/container/" + action + "/" + cid;
+            var u = gotty_base_path + "/container/" + action + "/" + cid;
+            if (action == "rename" || action == "labels" || action == "commit") {
+                edit(this, action, cid, short);
+                return;
+            }
+            if (action == "debug") {
+                debug(cid, short);
+                return;
+            }
+            if (action == "kill") {
+                var signal = prompt("kill container " + short +
+                    " with the signal (SIGTERM, SIGKILL, SIGHUP or any other):", "SIGTERM");
+                if (signal === null || signal == "") {
+                    return;
+                }
+                u += "?signal=" + encodeURIComponent(signal);
+            } else if (!confirm(action + " container " + short + "?")) {
+                return;
+            }
             var xmlhttp = new XMLHttpRequest();
             xmlhttp.open("POST", u);
+            xmlhttp.setRequestHeader("X-CSRF-Token", csrfToken());
             xmlhttp.onreadystatechange = function () {
                 if (xmlhttp.readyState == 4) {
                     var j = JSON.parse(xmlhttp.responseText);
@@ -18,11 +40,113 @@ try {
                     }
                 }
             };
-            alert(action + " container " + cid.substring(0, 8));
             console.debug("POST: " + u);
             xmlhttp.send();
         };
     }
 } catch (error) {
     console.error(error);
-}
\ No newline at end of file
+}
+
+// parseLabels parses "key=value,-key" into the labels to set and remove
+function parseLabels(input) {
+    var update = { set: {}, remove: [] };
+    var items = input.split(",");
+    for (var i = 0; i < items.length; ++i) {
+        var item = items[i].trim();
+        if (item == "") {
+            continue;
+        }
+        if (item[0] == "-") {
+            update.remove.push(item.substring(1));
+            continue;
+        }
+        var eq = item.indexOf("=");
+        if (eq <= 0) {
+            throw "bad label " + item + ", should be key=value or -key";
+        }
+        update.set[item.substring(0, eq)] = item.substring(eq + 1);
+    }
+    return update;
+}
+
+// edit renames, updates the labels or commits the container with the API
+function edit(btn, action, cid, short) {
+    var method, u, body;
+    if (action == "rename") {
+        var name = prompt("rename container " + short + " to:", btn.dataset.name);
+        if (name === null || name == "" || name == btn.dataset.name) {
+            return;
+        }
+        method = "POST";
+        u = gotty_base_path + "/api/containers/" + cid + "/rename";
+        body = { name: name };
+    } else if (action == "commit") {
+        var image = prompt("commit container " + short + " to the image:",
+            btn.dataset.name.toLowerCase() + ":debug");
+        if (image === null || image == "") {
+            return;
+        }
+        method = "POST";
+        u = gotty_base_path + "/api/containers/" + cid + "/commit";
+        body = { image: image };
+    } else {
+        var input = prompt("labels of container " + short + ":\n" +
+            (btn.dataset.labels || "(none)\n") +
+            "\nset with key=value, remove with -key, use comma for split:", "");
+        if (input === null || input.trim() == "") {
+            return;
+        }
+        try {
+            body = parseLabels(input);
+        } catch (error) {
+            alert(error);
+            return;
+        }
+        method = "PATCH";
+        u = gotty_base_path + "/api/containers/" + cid + "/labels";
+    }
+
+    var xmlhttp = new XMLHttpRequest();
+    xmlhttp.open(method, u);
+    xmlhttp.setRequestHeader("X-CSRF-Token", csrfToken());
+    xmlhttp.setRequestHeader("Content-Type", "application/json");
+    xmlhttp.onreadystatechange = function () {
+        if (xmlhttp.readyState == 4) {
+            if (xmlhttp.status != 200) {
+                alert(xmlhttp.responseText);
+                return;
+            }
+            if (action == "commit") {
+                alert("committed to " + body.image + ": " + JSON.parse(xmlhttp.responseText).id);
+                return;
+            }
+            location.reload();
+        }
+    };
+    console.debug(method + ": " + u);
+    xmlhttp.send(JSON.stringify(body));
+}
+// debug launches a debug container sharing the namespaces of the
+// container and opens the terminal of it
+function debug(cid, short) {
+    if (!confirm("launch a debug container for container " + short + "?")) {
+        return;
+    }
+    // open the window before the request, or it's blocked as a popup
+    var w = window.open("", "_blank");
+    var xmlhttp = new XMLHttpRequest();
+    xmlhttp.open("POST", gotty_base_path + "/api/containers/" + cid + "/debug");
+    xmlhttp.setRequestHeader("X-CSRF-Token", csrfToken());
+    xmlhttp.onreadystatechange = function () {
+        if (xmlhttp.readyState == 4) {
+            if (xmlhttp.status != 200) {
+                w.close();
+                alert(xmlhttp.responseText);
+                return;
+            }
+            w.location = JSON.parse(xmlhttp.responseText).url;
+        }
+    };
+    xmlhttp.send();
+}
@@ -0,0 +1,6 @@
+// csrfToken returns the token of the cookie, sent with the requests
+// changing anything so they can't be forged by the other sites
+function csrfToken() {
+    var m = document.cookie.match(/(?:^|;\s*)csrf_token=([^;]*)/);
+    return m ? decodeURIComponent(m[1]) : "";
+}
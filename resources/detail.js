@@ -0,0 +1,131 @@
+// env and mounts of a container
+
+(function () {
+    var root = document.getElementById("detail");
+    if (root === null) {
+        return;
+    }
+    var id = root.getAttribute("data-id");
+    var errorLine = document.getElementById("detail-error");
+
+    function row(tbody, cells) {
+        var tr = document.createElement("tr");
+        cells.forEach(function (c) {
+            var td = document.createElement("td");
+            td.textContent = c;
+            tr.appendChild(td);
+        });
+        tbody.appendChild(tr);
+        return tr;
+    }
+
+    function render(detail) {
+        var env = document.querySelector("#env tbody");
+        var mounts = document.querySelector("#mounts tbody");
+        env.innerHTML = "";
+        mounts.innerHTML = "";
+        detail.env.forEach(function (e) {
+            var tr = row(env, [e.name, e.value]);
+            if (e.masked) {
+                tr.className = "masked";
+                tr.title = "masked by --mask-env";
+            }
+        });
+        detail.mounts.forEach(function (m) {
+            row(mounts, [m.type, m.source, m.destination, m.read_only ? "ro" : "rw"]);
+        });
+        renderHealth(detail.health);
+    }
+
+    function probeRow(tbody, p) {
+        var ms = new Date(p.end) - new Date(p.start);
+        var tr = row(tbody, [formatTime(p.start), ms + "ms",
+            String(p.exit_code), p.output]);
+        tr.className = p.exit_code == 0 ? "healthy" : "unhealthy";
+        return tr;
+    }
+
+    function renderHealth(health) {
+        var section = document.getElementById("health");
+        if (!health) {
+            section.hidden = true;
+            return;
+        }
+        section.hidden = false;
+        var status = document.getElementById("health-status");
+        status.textContent = (health.status || "unknown") +
+            (health.failing_streak > 0 ? " (" + health.failing_streak + " failed)" : "");
+        status.className = health.status;
+        document.getElementById("health-cmd").textContent = health.cmd || "(not an exec probe)";
+        document.getElementById("health-check").disabled = !health.cmd;
+        var probes = document.querySelector("#probes tbody");
+        probes.innerHTML = "";
+        // newest first
+        health.probes.slice().reverse().forEach(function (p) {
+            probeRow(probes, p);
+        });
+    }
+
+    function check() {
+        var btn = document.getElementById("health-check");
+        btn.disabled = true;
+        var xmlhttp = new XMLHttpRequest();
+        xmlhttp.open("POST", gotty_base_path + "/api/containers/" + id + "/health/check");
+        xmlhttp.setRequestHeader("X-CSRF-Token", csrfToken());
+        xmlhttp.onreadystatechange = function () {
+            if (xmlhttp.readyState != 4) {
+                return;
+            }
+            btn.disabled = false;
+            try {
+                var j = JSON.parse(xmlhttp.responseText);
+                if (xmlhttp.status != 200) {
+                    errorLine.textContent = j.message;
+                    return;
+                }
+                errorLine.textContent = "";
+                var probes = document.querySelector("#probes tbody");
+                var tr = probeRow(probes, j);
+                tr.title = "checked on demand, not recorded by the backend";
+                tr.cells[0].className = "manual";
+                probes.insertBefore(tr, probes.firstChild);
+            } catch (error) {
+                errorLine.textContent = "bad response: " + xmlhttp.status;
+            }
+        };
+        xmlhttp.send();
+    }
+    document.getElementById("health-check").onclick = check;
+
+    function load(reveal) {
+        var xmlhttp = new XMLHttpRequest();
+        xmlhttp.open("GET", gotty_base_path + "/api/containers/" + id + "/detail" + (reveal ? "?reveal=1" : ""));
+        xmlhttp.onreadystatechange = function () {
+            if (xmlhttp.readyState != 4) {
+                return;
+            }
+            try {
+                var j = JSON.parse(xmlhttp.responseText);
+                if (xmlhttp.status != 200) {
+                    errorLine.textContent = j.message;
+                    return;
+                }
+                errorLine.textContent = "";
+                render(j);
+            } catch (error) {
+                errorLine.textContent = "bad response: " + xmlhttp.status;
+            }
+        };
+        xmlhttp.send();
+    }
+
+    var reveal = document.getElementById("reveal");
+    if (reveal !== null) {
+        reveal.onclick = function () {
+            if (confirm("reveal the masked env of container " + id.substring(0, 8) + "?")) {
+                load(true);
+            }
+        };
+    }
+    load(false);
+})();
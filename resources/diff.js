@@ -0,0 +1,60 @@
+// files changed by a container, compared to its image
+
+(function () {
+    var list = document.getElementById("diff");
+    if (list === null) {
+        return;
+    }
+    var id = list.getAttribute("data-id");
+    var kinds = document.querySelectorAll("#diff-filter input[type=checkbox]");
+    var path = document.getElementById("diff-path");
+    var changes = [];
+
+    function render() {
+        var show = {};
+        for (var i = 0; i < kinds.length; ++i) {
+            show[kinds[i].value] = kinds[i].checked;
+        }
+        list.innerHTML = "";
+        var n = 0;
+        changes.forEach(function (c) {
+            if (!show[c.kind] || c.path.indexOf(path.value) < 0) {
+                return;
+            }
+            var li = document.createElement("li");
+            var kind = document.createElement("span");
+            kind.className = c.kind;
+            kind.textContent = c.kind;
+            li.appendChild(kind);
+            li.appendChild(document.createTextNode(c.path));
+            list.appendChild(li);
+            n++;
+        });
+        document.getElementById("diff-count").textContent = n + " of " + changes.length + " changes";
+    }
+
+    for (var i = 0; i < kinds.length; ++i) {
+        kinds[i].onchange = render;
+    }
+    path.oninput = render;
+
+    var xmlhttp = new XMLHttpRequest();
+    xmlhttp.open("GET", gotty_base_path + "/api/containers/" + id + "/diff");
+    xmlhttp.onreadystatechange = function () {
+        if (xmlhttp.readyState != 4) {
+            return;
+        }
+        try {
+            var j = JSON.parse(xmlhttp.responseText);
+            if (xmlhttp.status != 200) {
+                document.getElementById("diff-error").textContent = j.message;
+                return;
+            }
+            changes = j;
+            render();
+        } catch (error) {
+            document.getElementById("diff-error").textContent = "bad response: " + xmlhttp.status;
+        }
+    };
+    xmlhttp.send();
+})();
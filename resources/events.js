@@ -0,0 +1,30 @@
+// live container list, updated by the container events
+
+try {
+    if (window.EventSource) {
+        var reloadTimer = null;
+        var source = new EventSource(gotty_base_path + "/api/events");
+        source.addEventListener("container", function (e) {
+            var ev = JSON.parse(e.data);
+            console.debug(ev);
+            var link = document.querySelector('a[data-id="' + ev.id.substring(0, 12) + '"]');
+            if (link === null || ev.action == "destroy") {
+                // the list is changed, reload it once for a burst of events
+                if (reloadTimer === null) {
+                    reloadTimer = setTimeout(function () { location.reload(); }, 1000);
+                }
+                return;
+            }
+            var state = link.parentElement.parentElement.querySelector('.column7');
+            if (state !== null) {
+                // show the transition, e.g. "running → die"
+                var target = state.querySelector('a') || state;
+                var prev = target.getAttribute('data-state') || target.textContent.trim();
+                target.textContent = prev == ev.action ? ev.action : prev + " \u2192 " + ev.action;
+                target.setAttribute('data-state', ev.action);
+            }
+        });
+    }
+} catch (error) {
+    console.error(error);
+}
@@ -0,0 +1,87 @@
+// exec history of a container, the sessions and the one-shot commands
+
+(function () {
+    var table = document.getElementById("history");
+    if (table === null) {
+        return;
+    }
+    var id = table.getAttribute("data-id");
+
+    function duration(s) {
+        var end = s.end_at ? new Date(s.end_at) : new Date();
+        var sec = Math.round((end - new Date(s.start_at)) / 1000);
+        if (sec < 60) {
+            return sec + "s";
+        }
+        if (sec < 3600) {
+            return Math.floor(sec / 60) + "m" + (sec % 60) + "s";
+        }
+        return Math.floor(sec / 3600) + "h" + Math.floor(sec % 3600 / 60) + "m";
+    }
+
+    function size(bytes) {
+        var units = ["B", "KiB", "MiB", "GiB", "TiB"];
+        var i = 0;
+        while (bytes >= 1024 && i < units.length - 1) {
+            bytes /= 1024;
+            i++;
+        }
+        return (i == 0 ? bytes : bytes.toFixed(1)) + " " + units[i];
+    }
+
+    // the bytes of the terminal sessions, none of the one-shot commands
+    // (closed by "run") and of the history recorded before them
+    function traffic(s) {
+        if (s.bytes_in === undefined || /^run( error|$)/.test(s.reason || "")) {
+            return "";
+        }
+        return size(s.bytes_in) + " / " + size(s.bytes_out);
+    }
+
+    function render(sessions) {
+        var tbody = table.querySelector("tbody");
+        tbody.innerHTML = "";
+        sessions.forEach(function (s) {
+            var tr = document.createElement("tr");
+            var exitCode = s.exit_code === undefined ? "" : String(s.exit_code);
+            [
+                formatTime(s.start_at),
+                s.client,
+                s.cmd || "(shell)",
+                duration(s),
+                traffic(s),
+                exitCode,
+                s.end_at ? s.reason : "active"
+            ].forEach(function (c) {
+                var td = document.createElement("td");
+                td.textContent = c;
+                tr.appendChild(td);
+            });
+            if (!s.end_at) {
+                tr.className = "active-session";
+            } else if (exitCode != "" && exitCode != "0") {
+                tr.className = "failed";
+            }
+            tbody.appendChild(tr);
+        });
+    }
+
+    var xmlhttp = new XMLHttpRequest();
+    xmlhttp.open("GET", gotty_base_path + "/api/containers/" + id + "/history");
+    xmlhttp.onreadystatechange = function () {
+        if (xmlhttp.readyState != 4) {
+            return;
+        }
+        try {
+            var j = JSON.parse(xmlhttp.responseText);
+            if (xmlhttp.status != 200) {
+                document.getElementById("history-error").textContent = j.message;
+                return;
+            }
+            render(j);
+        } catch (error) {
+            document.getElementById("history-error").textContent = "bad response: " + xmlhttp.status;
+        }
+    };
+    xmlhttp.send();
+})();
@@ -0,0 +1,14 @@
+// the share links are copied by clicking the images
+var clipboard = new ClipboardJS('.copy', {
+    text: function (trigger) {
+        return location.origin + gotty_base_path + trigger.getAttribute('data-clipboard-text');
+    }
+});
+clipboard.on('success', function (e) {
+    console.info('Action:', e.action);
+    console.info('Text:', e.text);
+    console.info('Trigger:', e.trigger);
+
+    e.clearSelection();
+    alert("share link (" + e.text + ") copied!");
+});
@@ -0,0 +1,85 @@
+// create and start a container with the admin API, then open the terminal of it
+
+(function () {
+    var form = document.getElementById("run");
+    if (form === null) {
+        return;
+    }
+    var token = document.getElementById("admin-token");
+    // the token is kept only if the sessions are disabled
+    token.value = sessionStorage.getItem("admin-token") || "";
+    token.onchange = function () {
+        adminLogin(token.value, function (ok) {
+            if (!ok) {
+                sessionStorage.setItem("admin-token", token.value);
+                return;
+            }
+            sessionStorage.removeItem("admin-token");
+            token.value = "";
+            token.placeholder = "logged in";
+        });
+    };
+
+    // split the value by the separator, the empty items are dropped
+    function split(value, sep) {
+        return value.split(sep).map(function (s) {
+            return s.trim();
+        }).filter(function (s) {
+            return s != "";
+        });
+    }
+
+    // the solution of the login challenge, the request is retried
+    // once with it
+    function run(body, solution) {
+        var errP = document.getElementById("run-error");
+        errP.textContent = "";
+
+        var xmlhttp = new XMLHttpRequest();
+        xmlhttp.open("POST", gotty_base_path + "/api/admin/containers");
+        xmlhttp.setRequestHeader("X-CSRF-Token", csrfToken());
+        xmlhttp.setRequestHeader("Content-Type", "application/json");
+        if (token.value) {
+            xmlhttp.setRequestHeader("Authorization", "Bearer " + token.value);
+        }
+        if (solution) {
+            xmlhttp.setRequestHeader("X-Login-Solution", solution);
+        }
+        xmlhttp.onreadystatechange = function () {
+            if (xmlhttp.readyState != 4) {
+                return;
+            }
+            var nonce;
+            if (xmlhttp.status == 401 && !solution && (nonce = solveChallenge(xmlhttp)) !== null) {
+                run(body, nonce);
+                return;
+            }
+            try {
+                var j = JSON.parse(xmlhttp.responseText);
+                if (xmlhttp.status != 200) {
+                    errP.textContent = j.message;
+                    return;
+                }
+                location = j.url;
+            } catch (error) {
+                errP.textContent = "bad response: " + xmlhttp.status;
+            }
+        };
+        xmlhttp.send(JSON.stringify(body));
+    }
+
+    form.onsubmit = function (e) {
+        e.preventDefault();
+        var f = form.elements;
+        var body = {
+            image: f.image.value.trim(),
+            name: f.name.value.trim(),
+            cmd: split(f.cmd.value, /\s+/),
+            env: split(f.env.value, "\n"),
+            ports: split(f.ports.value, /[\s,]+/),
+            volumes: split(f.volumes.value, "\n"),
+            server: f.server.value.trim()
+        };
+        run(body);
+    };
+})();
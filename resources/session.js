@@ -0,0 +1,24 @@
+// adminLogin logs in the admin session of the token, then the requests of
+// the pages carry the session cookie instead of the token, done gets
+// whether it's logged in, it's not if the sessions are disabled
+function adminLogin(token, done, solution) {
+    var xmlhttp = new XMLHttpRequest();
+    xmlhttp.open("POST", gotty_base_path + "/api/admin/session");
+    xmlhttp.setRequestHeader("X-CSRF-Token", csrfToken());
+    xmlhttp.setRequestHeader("Authorization", "Bearer " + token);
+    if (solution) {
+        xmlhttp.setRequestHeader("X-Login-Solution", solution);
+    }
+    xmlhttp.onreadystatechange = function () {
+        if (xmlhttp.readyState != 4) {
+            return;
+        }
+        var nonce;
+        if (xmlhttp.status == 401 && !solution && (nonce = solveChallenge(xmlhttp)) !== null) {
+            adminLogin(token, done, nonce);
+            return;
+        }
+        done(xmlhttp.status == 204);
+    };
+    xmlhttp.send();
+}
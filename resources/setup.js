@@ -0,0 +1,102 @@
+// the first-run setup, the options are filled once the setup code is
+// entered, then the config is saved and the server restarts with it
+
+(function () {
+    var form = document.getElementById("setup");
+    if (form === null) {
+        return;
+    }
+    var f = form.elements;
+    var errP = document.getElementById("setup-error");
+
+    function request(method, body, done) {
+        errP.textContent = "";
+        var xmlhttp = new XMLHttpRequest();
+        xmlhttp.open(method, "/api/setup");
+        xmlhttp.setRequestHeader("X-Setup-Code", f.code.value);
+        xmlhttp.setRequestHeader("Content-Type", "application/json");
+        xmlhttp.onreadystatechange = function () {
+            if (xmlhttp.readyState != 4) {
+                return;
+            }
+            try {
+                var j = JSON.parse(xmlhttp.responseText);
+                if (xmlhttp.status != 200) {
+                    errP.textContent = j.message;
+                    return;
+                }
+                done(j);
+            } catch (error) {
+                errP.textContent = "bad response: " + xmlhttp.status;
+            }
+        };
+        xmlhttp.send(body ? JSON.stringify(body) : null);
+    }
+
+    // only the options of the chosen backend are shown
+    function showBackend() {
+        ["docker", "kube", "grpc"].forEach(function (backend) {
+            var p = form.querySelector(".backend-" + backend);
+            p.hidden = f.backend.value != backend;
+        });
+    }
+    f.backend.onchange = showBackend;
+
+    f.code.onchange = function () {
+        request("GET", null, function (defaults) {
+            f.backend.value = defaults.backend || "docker";
+            f.docker_host.value = defaults.docker_host || "";
+            f.kube_config.value = defaults.kube_config || "";
+            f.grpc_servers.value = (defaults.grpc_servers || []).join(",");
+            // this host only unless it's changed
+            f.address.value = defaults.address == "0.0.0.0" ? "127.0.0.1" : defaults.address;
+            if (f.address.value == "") {
+                f.address.value = "127.0.0.1";
+            }
+            f.port.value = defaults.port;
+            document.getElementById("setup-options").disabled = false;
+            // those of the args or the env are kept
+            (defaults.fixed || []).forEach(function (name) {
+                if (name == "address") {
+                    f.address.value = defaults.address;
+                    if (f.address.value != defaults.address) {
+                        var option = document.createElement("option");
+                        option.value = option.textContent = defaults.address;
+                        f.address.appendChild(option);
+                        f.address.value = defaults.address;
+                    }
+                }
+                f[name].disabled = true;
+            });
+            showBackend();
+        });
+    };
+
+    form.onsubmit = function (e) {
+        e.preventDefault();
+        var body = {
+            code: f.code.value,
+            admin_token: f.admin_token.value,
+            backend: f.backend.value,
+            docker_host: f.docker_host.value.trim(),
+            kube_config: f.kube_config.value.trim(),
+            grpc_servers: f.grpc_servers.value.split(",").map(function (s) {
+                return s.trim();
+            }).filter(function (s) {
+                return s != "";
+            }),
+            address: f.address.value,
+            port: parseInt(f.port.value, 10)
+        };
+        request("POST", body, function (saved) {
+            form.hidden = true;
+            var host = saved.address == "0.0.0.0" ? location.hostname : saved.address;
+            var url = location.protocol + "//" + host + ":" + saved.port + "/";
+            var a = document.getElementById("setup-url");
+            a.href = a.textContent = url;
+            document.getElementById("setup-admin").href = url + "admin.html";
+            document.getElementById("setup-token").textContent = saved.admin_token;
+            document.getElementById("setup-done").hidden = false;
+        });
+    };
+})();
@@ -0,0 +1,101 @@
+// live resource usage of a container
+
+(function () {
+    var root = document.getElementById("stats");
+    if (root === null || !window.EventSource) {
+        return;
+    }
+    var id = root.getAttribute("data-id");
+    var maxSamples = 60;
+    var samples = [];
+
+    function bytes(n) {
+        var units = ["B", "KB", "MB", "GB", "TB"];
+        var i = 0;
+        while (n >= 1024 && i < units.length - 1) {
+            n /= 1024;
+            i++;
+        }
+        return n.toFixed(i == 0 ? 0 : 1) + units[i];
+    }
+
+    // draw the series of the values, max is the top of the chart
+    function draw(canvasID, series, max) {
+        var canvas = document.getElementById(canvasID);
+        var ctx = canvas.getContext("2d");
+        var w = canvas.width, h = canvas.height;
+        ctx.clearRect(0, 0, w, h);
+        if (!(max > 0)) {
+            max = 1;
+        }
+        series.forEach(function (s) {
+            ctx.strokeStyle = s.color;
+            ctx.lineWidth = 2;
+            ctx.beginPath();
+            s.values.forEach(function (v, i) {
+                var x = w - (s.values.length - 1 - i) * w / (maxSamples - 1);
+                var y = h - Math.min(v / max, 1) * (h - 4) - 2;
+                if (i == 0) {
+                    ctx.moveTo(x, y);
+                } else {
+                    ctx.lineTo(x, y);
+                }
+            });
+            ctx.stroke();
+        });
+    }
+
+    // rates per second of the counter between the samples
+    function rates(key) {
+        var values = [];
+        for (var i = 1; i < samples.length; i++) {
+            var seconds = (new Date(samples[i].time) - new Date(samples[i - 1].time)) / 1000;
+            var delta = samples[i][key] - samples[i - 1][key];
+            values.push(seconds > 0 && delta > 0 ? delta / seconds : 0);
+        }
+        return values;
+    }
+
+    function max(values) {
+        return values.reduce(function (a, b) { return Math.max(a, b); }, 0);
+    }
+
+    function render() {
+        var last = samples[samples.length - 1];
+        var pick = function (key) { return samples.map(function (s) { return s[key]; }); };
+
+        var cpu = pick("cpu_percent");
+        draw("cpu", [{ values: cpu, color: "#27ae60" }], Math.max(100, max(cpu)));
+        document.getElementById("cpu-value").textContent = last.cpu_percent.toFixed(1) + "%";
+
+        var memory = pick("memory_usage");
+        draw("memory", [{ values: memory, color: "#2980b9" }], last.memory_limit || max(memory));
+        document.getElementById("memory-value").textContent = bytes(last.memory_usage) +
+            (last.memory_limit ? " / " + bytes(last.memory_limit) : "");
+
+        var rx = rates("network_rx"), tx = rates("network_tx");
+        draw("network", [{ values: rx, color: "#27ae60" }, { values: tx, color: "#d35400" }],
+            Math.max(max(rx), max(tx)));
+        document.getElementById("network-value").textContent = "rx " + bytes(rx[rx.length - 1] || 0) +
+            "/s, tx " + bytes(tx[tx.length - 1] || 0) + "/s";
+
+        var read = rates("block_read"), write = rates("block_write");
+        draw("block", [{ values: read, color: "#27ae60" }, { values: write, color: "#d35400" }],
+            Math.max(max(read), max(write)));
+        document.getElementById("block-value").textContent = "read " + bytes(read[read.length - 1] || 0) +
+            "/s, write " + bytes(write[write.length - 1] || 0) + "/s";
+    }
+
+    var source = new EventSource(gotty_base_path + "/api/containers/" + id + "/stats");
+    source.addEventListener("stats", function (e) {
+        samples.push(JSON.parse(e.data));
+        if (samples.length > maxSamples) {
+            samples.shift();
+        }
+        document.getElementById("stats-error").textContent = "";
+        render();
+    });
+    source.onerror = function () {
+        document.getElementById("stats-error").textContent = "stats stream disconnected, retrying...";
+    };
+})();
@@ -0,0 +1,33 @@
+// the times of the pages in the zone of --timezone (gotty_timezone of
+// config.js), the browser's own if it's empty or unknown to the browser
+(function () {
+    "use strict";
+
+    var zone = typeof gotty_timezone === "string" ? gotty_timezone : "";
+
+    function format(t, method) {
+        var d = t instanceof Date ? t : new Date(t);
+        if (zone) {
+            try {
+                return d[method](undefined, { timeZone: zone, timeZoneName: "short" });
+            } catch (e) {
+                // the zone unknown to the browser
+            }
+        }
+        return d[method]();
+    }
+
+    window.formatTime = function (t) { return format(t, "toLocaleString"); };
+    window.formatClock = function (t) { return format(t, "toLocaleTimeString"); };
+
+    // the clock of the terminal page, --clock
+    document.addEventListener("DOMContentLoaded", function () {
+        var clock = document.getElementById("clock");
+        if (!clock) {
+            return;
+        }
+        var tick = function () { clock.textContent = window.formatClock(new Date()); };
+        tick();
+        setInterval(tick, 1000);
+    });
+})();
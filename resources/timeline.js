@@ -0,0 +1,58 @@
+// timeline of a container, the restarts, OOM kills and health transitions
+
+(function () {
+    var table = document.getElementById("timeline");
+    if (table === null) {
+        return;
+    }
+    var id = table.getAttribute("data-id");
+    var tbody = table.querySelector("tbody");
+    var actions = "start,restart,die,oom,kill,stop,health_status";
+
+    function row(e) {
+        var tr = document.createElement("tr");
+        [
+            formatTime(e.time),
+            e.action,
+            e.detail || ""
+        ].forEach(function (c) {
+            var td = document.createElement("td");
+            td.textContent = c;
+            tr.appendChild(td);
+        });
+        tr.className = e.action == "health_status" ? e.detail : e.action;
+        return tr;
+    }
+
+    function live() {
+        var source = new EventSource(gotty_base_path + "/api/events?action=" + actions + "&id=" + id);
+        source.addEventListener("container", function (msg) {
+            var e = JSON.parse(msg.data);
+            if (e.id == id) {
+                tbody.insertBefore(row(e), tbody.firstChild);
+            }
+        });
+    }
+
+    var xmlhttp = new XMLHttpRequest();
+    xmlhttp.open("GET", gotty_base_path + "/api/containers/" + id + "/timeline");
+    xmlhttp.onreadystatechange = function () {
+        if (xmlhttp.readyState != 4) {
+            return;
+        }
+        try {
+            var j = JSON.parse(xmlhttp.responseText);
+            if (xmlhttp.status != 200) {
+                document.getElementById("timeline-error").textContent = j.message;
+                return;
+            }
+            j.forEach(function (e) {
+                tbody.appendChild(row(e));
+            });
+            live();
+        } catch (error) {
+            document.getElementById("timeline-error").textContent = "bad response: " + xmlhttp.status;
+        }
+    };
+    xmlhttp.send();
+})();
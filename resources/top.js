@@ -0,0 +1,93 @@
+// process list of a container
+
+(function () {
+    var table = document.getElementById("top");
+    if (table === null) {
+        return;
+    }
+    var id = table.getAttribute("data-id");
+    var canKill = table.getAttribute("data-kill") == "true";
+    var refresh = document.getElementById("top-refresh");
+    var errorLine = document.getElementById("top-error");
+    var interval = 2000;
+
+    function row(tag, cells) {
+        var tr = document.createElement("tr");
+        cells.forEach(function (c) {
+            var td = document.createElement(tag);
+            td.textContent = c;
+            tr.appendChild(td);
+        });
+        return tr;
+    }
+
+    function kill(pid) {
+        var signal = prompt("kill process " + pid + " with the signal (TERM, KILL, HUP or any other):", "TERM");
+        if (signal === null || signal == "") {
+            return;
+        }
+        var xmlhttp = new XMLHttpRequest();
+        xmlhttp.open("POST", gotty_base_path + "/api/containers/" + id + "/top/" + pid + "/kill?signal=" + encodeURIComponent(signal));
+        xmlhttp.setRequestHeader("X-CSRF-Token", csrfToken());
+        xmlhttp.onreadystatechange = function () {
+            if (xmlhttp.readyState == 4) {
+                if (xmlhttp.status != 200) {
+                    alert(xmlhttp.responseText);
+                }
+                load();
+            }
+        };
+        xmlhttp.send();
+    }
+
+    function render(top) {
+        var pidColumn = top.titles.indexOf("PID");
+        var thead = table.querySelector("thead");
+        var tbody = table.querySelector("tbody");
+        thead.innerHTML = "";
+        tbody.innerHTML = "";
+        thead.appendChild(row("th", top.titles.concat(canKill && pidColumn >= 0 ? [""] : [])));
+        top.processes.forEach(function (p) {
+            var tr = row("td", p);
+            if (canKill && pidColumn >= 0) {
+                var td = document.createElement("td");
+                var btn = document.createElement("button");
+                btn.textContent = "Kill";
+                btn.onclick = function () { kill(p[pidColumn]); };
+                td.appendChild(btn);
+                tr.appendChild(td);
+            }
+            tbody.appendChild(tr);
+        });
+        document.getElementById("top-time").textContent = formatClock(new Date());
+    }
+
+    function load() {
+        var xmlhttp = new XMLHttpRequest();
+        xmlhttp.open("GET", gotty_base_path + "/api/containers/" + id + "/top");
+        xmlhttp.onreadystatechange = function () {
+            if (xmlhttp.readyState != 4) {
+                return;
+            }
+            try {
+                var j = JSON.parse(xmlhttp.responseText);
+                if (xmlhttp.status != 200) {
+                    errorLine.textContent = j.message;
+                    return;
+                }
+                errorLine.textContent = "";
+                render(j);
+            } catch (error) {
+                errorLine.textContent = "bad response: " + xmlhttp.status;
+            }
+        };
+        xmlhttp.send();
+    }
+
+    load();
+    setInterval(function () {
+        if (refresh.checked && !document.hidden) {
+            load();
+        }
+    }, interval);
+})();
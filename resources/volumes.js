@@ -0,0 +1,149 @@
+// browse and download the files of the volumes of a container
+
+(function () {
+    var div = document.getElementById("volumes");
+    if (div === null) {
+        return;
+    }
+    var id = div.getAttribute("data-id");
+    var select = document.getElementById("volume");
+    var tbody = document.querySelector("#files tbody");
+    var errorP = document.getElementById("volumes-error");
+
+    function get(url, cb) {
+        var xmlhttp = new XMLHttpRequest();
+        xmlhttp.open("GET", url);
+        xmlhttp.onreadystatechange = function () {
+            if (xmlhttp.readyState != 4) {
+                return;
+            }
+            try {
+                var j = JSON.parse(xmlhttp.responseText);
+                if (xmlhttp.status != 200) {
+                    errorP.textContent = j.message;
+                    return;
+                }
+                errorP.textContent = "";
+                cb(j);
+            } catch (error) {
+                errorP.textContent = "bad response: " + xmlhttp.status;
+            }
+        };
+        xmlhttp.send();
+    }
+
+    function query(volume, path) {
+        return "?volume=" + encodeURIComponent(volume) + "&path=" + encodeURIComponent(path);
+    }
+
+    function size(n) {
+        var units = ["B", "KB", "MB", "GB", "TB"];
+        var i = 0;
+        while (n >= 1024 && i < units.length - 1) {
+            n /= 1024;
+            i++;
+        }
+        return (i == 0 ? n : n.toFixed(1)) + units[i];
+    }
+
+    function join(dir, name) {
+        return (dir == "/" ? "" : dir) + "/" + name;
+    }
+
+    function cell(tr, content, className) {
+        var td = document.createElement("td");
+        if (typeof content == "string") {
+            td.textContent = content;
+        } else {
+            td.appendChild(content);
+        }
+        if (className) {
+            td.className = className;
+        }
+        tr.appendChild(td);
+    }
+
+    function link(text, onclick) {
+        var a = document.createElement("a");
+        a.href = "#";
+        a.textContent = text;
+        a.onclick = function (e) {
+            e.preventDefault();
+            onclick();
+        };
+        return a;
+    }
+
+    function breadcrumb(volume, path) {
+        var span = document.getElementById("path");
+        span.innerHTML = "";
+        span.appendChild(link(volume, function () { browse(volume, "/"); }));
+        var dir = "";
+        path.split("/").filter(Boolean).forEach(function (name) {
+            dir += "/" + name;
+            var target = dir;
+            span.appendChild(document.createTextNode("/"));
+            span.appendChild(link(name, function () { browse(volume, target); }));
+        });
+    }
+
+    function browse(volume, path) {
+        location.hash = volume + ":" + path;
+        breadcrumb(volume, path);
+        document.getElementById("download-dir").href =
+            gotty_base_path + "/api/containers/" + id + "/volumes/download" + query(volume, path);
+        get(gotty_base_path + "/api/containers/" + id + "/volumes/files" + query(volume, path), function (listing) {
+            tbody.innerHTML = "";
+            listing.entries.forEach(function (e) {
+                var tr = document.createElement("tr");
+                var target = join(listing.path, e.name);
+                if (e.type == "dir") {
+                    cell(tr, link(e.name + "/", function () { browse(volume, target); }));
+                } else if (e.type == "symlink") {
+                    cell(tr, e.name + " -> " + e.link, "symlink");
+                } else {
+                    cell(tr, e.name);
+                }
+                cell(tr, size(e.size), "size");
+                cell(tr, e.mode);
+                cell(tr, formatTime(e.mod_time));
+                var download = document.createElement("a");
+                if (e.type != "symlink") {
+                    download.href = gotty_base_path + "/api/containers/" + id + "/volumes/download" + query(volume, target);
+                    download.textContent = "download";
+                }
+                cell(tr, download);
+                tbody.appendChild(tr);
+            });
+            if (listing.truncated) {
+                errorP.textContent = "the volume is too large, the listing is partial";
+            }
+        });
+    }
+
+    select.onchange = function () {
+        browse(select.value, "/");
+    };
+
+    get(gotty_base_path + "/api/containers/" + id + "/volumes", function (mounts) {
+        if (mounts.length == 0) {
+            errorP.textContent = "no volumes";
+            return;
+        }
+        mounts.forEach(function (m) {
+            var option = document.createElement("option");
+            option.value = m.destination;
+            option.textContent = m.destination + " (" + m.type + (m.read_only ? ", ro" : "") + ")";
+            select.appendChild(option);
+        });
+        // restore the location of #/data:/sub/dir
+        var hash = decodeURIComponent(location.hash.slice(1));
+        var i = hash.indexOf(":");
+        if (i > 0 && mounts.some(function (m) { return m.destination == hash.slice(0, i); })) {
+            select.value = hash.slice(0, i);
+            browse(hash.slice(0, i), hash.slice(i + 1));
+            return;
+        }
+        browse(select.value, "/");
+    });
+})();
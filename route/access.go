@@ -0,0 +1,156 @@
+package route
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/wrfly/container-web-tty/config"
+)
+
+// accessLog writes a line of every request after it's served, apart from
+// the logs of the modules, in the combined format of Apache or as JSON
+type accessLog struct {
+	json  bool
+	ratio float64
+	skip  map[string]bool
+
+	m   sync.Mutex
+	out io.Writer
+}
+
+func newAccessLog(opts config.AccessLogConfig, basePath string) (*accessLog, error) {
+	l := &accessLog{
+		ratio: opts.SampleRatio,
+		skip:  make(map[string]bool),
+	}
+	switch opts.Format {
+	case "", "combined":
+	case "json":
+		l.json = true
+	default:
+		return nil, fmt.Errorf("bad access log format %s, must be combined or json", opts.Format)
+	}
+	if l.ratio < 0 || l.ratio > 1 {
+		return nil, fmt.Errorf("bad access log sample ratio %v, must be 0 to 1", l.ratio)
+	}
+	for _, p := range opts.SkipPaths {
+		if p = strings.TrimSpace(p); p != "" {
+			l.skip[basePath+"/"+strings.TrimPrefix(p, "/")] = true
+		}
+	}
+	if opts.Path == "-" {
+		l.out = os.Stdout
+		return l, nil
+	}
+	f, err := os.OpenFile(opts.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0640)
+	if err != nil {
+		return nil, fmt.Errorf("open the access log error: %s", err)
+	}
+	l.out = f
+	return l, nil
+}
+
+type accessEntry struct {
+	Time      string  `json:"time"`
+	RequestID string  `json:"request_id"`
+	Client    string  `json:"client"`
+	Method    string  `json:"method"`
+	Path      string  `json:"path"`
+	Proto     string  `json:"proto"`
+	Status    int     `json:"status"`
+	Bytes     int     `json:"bytes"`
+	Duration  float64 `json:"duration_ms"`
+	Referer   string  `json:"referer,omitempty"`
+	UserAgent string  `json:"user_agent,omitempty"`
+}
+
+// logRequests logs the request once it's served, the websockets and the
+// SSE streams when they are closed
+func (server *Server) logRequests(c *gin.Context) {
+	l := server.access
+	if l == nil || l.skip[c.Request.URL.Path] {
+		return
+	}
+	start := time.Now()
+	c.Next()
+
+	status := c.Writer.Status()
+	if status < 400 && l.ratio < 1 && rand.Float64() >= l.ratio {
+		return
+	}
+	path := server.maskedPath(c.Request.URL.Path)
+	if c.Request.URL.RawQuery != "" {
+		path += "?" + c.Request.URL.RawQuery
+	}
+	e := accessEntry{
+		Time:      start.Format(time.RFC3339),
+		RequestID: c.GetString(requestIDKey),
+		Client:    c.ClientIP(),
+		Method:    c.Request.Method,
+		Path:      path,
+		Proto:     c.Request.Proto,
+		Status:    status,
+		// -1 if nothing is written
+		Bytes:     c.Writer.Size(),
+		Duration:  float64(time.Since(start)) / float64(time.Millisecond),
+		Referer:   c.Request.Referer(),
+		UserAgent: c.Request.UserAgent(),
+	}
+	if e.Bytes < 0 {
+		e.Bytes = 0
+	}
+	l.write(e, start)
+}
+
+func (l *accessLog) write(e accessEntry, start time.Time) {
+	var line []byte
+	if l.json {
+		line, _ = json.Marshal(e)
+		line = append(line, '\n')
+	} else {
+		line = []byte(fmt.Sprintf("%s - - [%s] %q %d %d %q %q\n",
+			e.Client, start.Format("02/Jan/2006:15:04:05 -0700"),
+			e.Method+" "+e.Path+" "+e.Proto, e.Status, e.Bytes,
+			dashed(e.Referer), dashed(e.UserAgent)))
+	}
+	l.m.Lock()
+	defer l.m.Unlock()
+	if _, err := l.out.Write(line); err != nil {
+		log.Errorf("write the access log error: %s", err)
+	}
+}
+
+func dashed(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// maskedPath masks the tokens of the path logged and traced, the join and
+// the forward tokens and the signed session URLs, which are credentials
+func (server *Server) maskedPath(path string) string {
+	parts := strings.Split(path, "/")
+	for i := 0; i < len(parts)-1; i++ {
+		switch parts[i] {
+		case "join", "forward":
+		case "exec":
+			if server.signer == nil || parts[i+1] == "batch" {
+				continue
+			}
+		default:
+			continue
+		}
+		parts[i+1] = "***"
+		i++
+	}
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,29 @@
+package route
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// adminAuthKey is set if the Authenticator tells the request is of an admin
+const adminAuthKey = "admin_auth"
+
+// Authenticator authenticates the requests for a program embedding the
+// Server, it returns whether the request is of an admin, or an error to
+// deny it with 401. It's called for all the requests, including the
+// websockets of the terminals and the probes.
+type Authenticator func(r *http.Request) (admin bool, err error)
+
+// authenticate denies the requests the Authenticator refuses
+func authenticate(auth Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		admin, err := auth(c.Request)
+		if err != nil {
+			requestLog(c).Warnf("denied %s: %s", c.Request.URL.Path, err)
+			apiError(c, http.StatusUnauthorized, "%s", err)
+			return
+		}
+		c.Set(adminAuthKey, admin)
+	}
+}
@@ -0,0 +1,165 @@
+package route
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/bits"
+	"sync"
+	"time"
+)
+
+const (
+	// challengeHeader is the challenge of a client after its repeated
+	// failed logins, "<bits>:<challenge>", and solutionHeader is the nonce
+	// solving it, the sha256 of the challenge and the nonce starts with
+	// the zero bits
+	challengeHeader = "X-Login-Challenge"
+	solutionHeader  = "X-Login-Solution"
+	// the failed logins of a client are forgotten after
+	loginFailureTTL = 15 * time.Minute
+	// the interval of forgetting them
+	loginSweepInterval = time.Minute
+	// max clients of the failed logins, the failures of the others are
+	// counted together once there are so many
+	maxLoginClients = 10000
+)
+
+// loginGuard counts the failed logins (the bad admin tokens) of the
+// client IPs, a client must solve a proof-of-work challenge for each
+// try after the failures of --login-challenge-after
+type loginGuard struct {
+	after int
+	bits  int
+
+	m       sync.Mutex
+	clients map[string]*loginFailures
+	// the failures of the clients not in the full map
+	overflow loginFailures
+}
+
+type loginFailures struct {
+	count int
+	last  time.Time
+	// the challenge to solve, it's used once
+	challenge string
+}
+
+func newLoginGuard(after, bits int) *loginGuard {
+	return &loginGuard{
+		after:   after,
+		bits:    bits,
+		clients: make(map[string]*loginFailures),
+	}
+}
+
+// check returns the challenge of the ip and false if the client must
+// solve it before trying, the solution is used up
+func (g *loginGuard) check(ip, solution string, now time.Time) (string, bool) {
+	g.m.Lock()
+	defer g.m.Unlock()
+	f := g.failures(ip, false)
+	if f == nil || now.Sub(f.last) > loginFailureTTL || f.count < g.after {
+		return "", true
+	}
+	if f.challenge != "" && solution != "" && solved(f.challenge, solution, g.bits) {
+		f.challenge = ""
+		return "", true
+	}
+	if f.challenge == "" {
+		f.challenge = newChallenge()
+	}
+	return f.challenge, false
+}
+
+// fail counts the failed login of the ip, it returns the challenge of
+// the next try if it's required
+func (g *loginGuard) fail(ip string, now time.Time) string {
+	g.m.Lock()
+	defer g.m.Unlock()
+	f := g.failures(ip, true)
+	if now.Sub(f.last) > loginFailureTTL {
+		f.count = 0
+	}
+	f.count++
+	f.last = now
+	if f.count < g.after {
+		return ""
+	}
+	f.challenge = newChallenge()
+	return f.challenge
+}
+
+// failures returns the failed logins of the ip, the shared ones if the
+// ip isn't counted and the map is full, or a new one if it's not and
+// create is true
+func (g *loginGuard) failures(ip string, create bool) *loginFailures {
+	if f, ok := g.clients[ip]; ok {
+		return f
+	}
+	if len(g.clients) >= maxLoginClients {
+		return &g.overflow
+	}
+	if !create {
+		return nil
+	}
+	f := &loginFailures{}
+	g.clients[ip] = f
+	return f
+}
+
+// run forgets the expired failed logins every interval until the ctx is
+// done
+func (g *loginGuard) run(ctx context.Context) {
+	ticker := time.NewTicker(loginSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			g.sweep(now)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (g *loginGuard) sweep(now time.Time) {
+	g.m.Lock()
+	defer g.m.Unlock()
+	for ip, f := range g.clients {
+		if now.Sub(f.last) > loginFailureTTL {
+			delete(g.clients, ip)
+		}
+	}
+	if now.Sub(g.overflow.last) > loginFailureTTL {
+		g.overflow = loginFailures{}
+	}
+}
+
+// succeed forgets the failed logins of the ip
+func (g *loginGuard) succeed(ip string) {
+	g.m.Lock()
+	defer g.m.Unlock()
+	delete(g.clients, ip)
+}
+
+func newChallenge() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// solved reports whether the sha256 of the challenge and the nonce
+// starts with the zero bits
+func solved(challenge, nonce string, zeros int) bool {
+	sum := sha256.Sum256([]byte(challenge + nonce))
+	n := 0
+	for _, b := range sum {
+		n += bits.LeadingZeros8(b)
+		if b != 0 {
+			break
+		}
+	}
+	return n >= zeros
+}
@@ -0,0 +1,37 @@
+package route
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wrfly/container-web-tty/config"
+	"github.com/wrfly/container-web-tty/webtty"
+)
+
+// checkClipboard checks the OSC 52 policies of --clipboard and
+// --clipboard-user
+func checkClipboard(options config.ServerConfig) error {
+	if _, ok := webtty.ParseClipboardPolicy(options.Clipboard); !ok {
+		return fmt.Errorf("bad clipboard policy %s, must be off, on or ask", options.Clipboard)
+	}
+	for user, policy := range options.ClipboardUsers {
+		if _, ok := webtty.ParseClipboardPolicy(policy); !ok {
+			return fmt.Errorf("bad clipboard policy %s of %s, must be off, on or ask", policy, user)
+		}
+	}
+	return nil
+}
+
+// clipboardPolicy is the OSC 52 policy of the sessions of the exec user,
+// the one of the user (or the user of user:group) in --clipboard-user, or
+// --clipboard
+func (server *Server) clipboardPolicy(user string) webtty.ClipboardPolicy {
+	policy, ok := server.options.ClipboardUsers[user]
+	if !ok {
+		if policy, ok = server.options.ClipboardUsers[strings.SplitN(user, ":", 2)[0]]; !ok {
+			policy = server.options.Clipboard
+		}
+	}
+	p, _ := webtty.ParseClipboardPolicy(policy)
+	return p
+}
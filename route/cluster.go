@@ -0,0 +1,181 @@
+package route
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/wrfly/container-web-tty/config"
+	"github.com/wrfly/container-web-tty/store"
+	"github.com/wrfly/container-web-tty/types"
+)
+
+// relayHeader marks the connections relayed by another replica,
+// they are never relayed again
+const relayHeader = "X-Web-TTY-Relayed"
+
+var errRelayed = errors.New("relayed to the replica of the session")
+
+// sessionsKey is the list of the closed sessions in the storage
+const sessionsKey = "container-web-tty:sessions"
+
+// openStorage opens --storage or --redis-addr, nil if neither is set. The
+// replicas record their detached sessions in it by --replica-url so any
+// replica can resume them, which needs a storage they share.
+func openStorage(options config.ServerConfig) (store.Store, error) {
+	if options.Storage != "" && options.RedisAddr != "" {
+		return nil, fmt.Errorf("the storage and the redis can't be both set, use --storage redis://")
+	}
+	if options.RedisAddr != "" && options.ReplicaURL == "" {
+		return nil, fmt.Errorf("the replicas sharing a redis need the replica URLs, got %q", options.ReplicaURL)
+	}
+	if options.ReplicaURL != "" {
+		if options.Storage == "" && options.RedisAddr == "" {
+			return nil, fmt.Errorf("the replicas need a storage they share")
+		}
+		if u, err := url.Parse(options.ReplicaURL); err != nil || u.Host == "" ||
+			(u.Scheme != "http" && u.Scheme != "https") {
+			return nil, fmt.Errorf("bad replica URL %q, must be http(s)://host[:port]", options.ReplicaURL)
+		}
+		if options.ResumeTimeout <= 0 {
+			return nil, fmt.Errorf("the replicas sharing a storage need the resume timeout")
+		}
+	}
+
+	switch {
+	case options.RedisAddr != "":
+		r, err := store.NewRedis(options.RedisAddr, 3*time.Second)
+		if err != nil {
+			return nil, err
+		}
+		return r, nil
+	case options.Storage != "":
+		return store.Open(options.Storage, 3*time.Second)
+	}
+	return nil, nil
+}
+
+// sessionRoute is the replica of a detached session
+type sessionRoute struct {
+	Replica     string `json:"replica"`
+	ContainerID string `json:"container_id"`
+}
+
+func sessionRouteKey(token string) string {
+	return "container-web-tty:session:" + token
+}
+
+// publishDetached records the detached session to be resumed by other replicas
+func (server *Server) publishDetached(token, containerID string) {
+	if server.store == nil || server.options.ReplicaURL == "" {
+		return
+	}
+	route, _ := json.Marshal(sessionRoute{
+		Replica:     server.options.ReplicaURL,
+		ContainerID: containerID,
+	})
+	if err := server.store.Set(sessionRouteKey(token), string(route), server.options.ResumeTimeout); err != nil {
+		log.Errorf("publish detached session of container %s error: %s", containerID, err)
+	}
+}
+
+// unpublishDetached removes the detached session from the store once
+// it's resumed or expired
+func (server *Server) unpublishDetached(token string) {
+	if server.store == nil || server.options.ReplicaURL == "" {
+		return
+	}
+	if err := server.store.Del(sessionRouteKey(token)); err != nil {
+		log.Errorf("unpublish detached session error: %s", err)
+	}
+}
+
+// remoteReplica returns the replica of the detached session of another
+// replica, empty if it's not found
+func (server *Server) remoteReplica(conn master, token, containerID string) string {
+	wsw, ok := conn.(*wsWrapper)
+	if server.store == nil || server.options.ReplicaURL == "" || !ok || wsw.req.Header.Get(relayHeader) != "" {
+		return ""
+	}
+	v, ok, err := server.store.Get(sessionRouteKey(token))
+	if err != nil {
+		log.Errorf("lookup detached session of container %s error: %s", containerID, err)
+		return ""
+	}
+	var route sessionRoute
+	if !ok || json.Unmarshal([]byte(v), &route) != nil {
+		return ""
+	}
+	if route.ContainerID != containerID || route.Replica == server.options.ReplicaURL {
+		return ""
+	}
+	return route.Replica
+}
+
+// relaySession relays the connection to the websocket of the same path of
+// the replica, which resumes the detached session by the init message
+func (server *Server) relaySession(conn master, replica string, init types.InitMessage) error {
+	wsw := conn.(*wsWrapper)
+	u, err := url.Parse(strings.TrimSuffix(replica, "/") + wsw.req.URL.Path)
+	if err != nil {
+		return err
+	}
+	u.Scheme = strings.Replace(u.Scheme, "http", "ws", 1)
+
+	header := http.Header{}
+	for _, h := range []string{"Authorization", "Cookie"} {
+		if v := wsw.req.Header.Get(h); v != "" {
+			header.Set(h, v)
+		}
+	}
+	if server.options.WSOrigin != "" {
+		// the replicas check the origin by the same --ws-origin
+		header.Set("Origin", wsw.req.Header.Get("Origin"))
+	}
+	// believed by the replicas trusting this one as a proxy
+	if ip, _, err := net.SplitHostPort(wsw.remoteAddr()); err == nil {
+		header.Set("X-Forwarded-For", ip)
+	}
+	header.Set(relayHeader, server.options.ReplicaURL)
+	dialer := websocket.Dialer{
+		HandshakeTimeout: 10 * time.Second,
+		Subprotocols:     websocket.Subprotocols(wsw.req),
+	}
+	upstream, _, err := dialer.Dial(u.String(), header)
+	if err != nil {
+		return err
+	}
+	defer upstream.Close()
+
+	initLine, _ := json.Marshal(init)
+	if err := upstream.WriteMessage(websocket.TextMessage, initLine); err != nil {
+		return err
+	}
+	log.Infof("relay session of %s to the replica %s", wsw.remoteAddr(), replica)
+
+	errs := make(chan error, 2)
+	pipe := func(dst, src *websocket.Conn) {
+		for {
+			typ, msg, err := src.ReadMessage()
+			if err != nil {
+				errs <- err
+				return
+			}
+			if err := dst.WriteMessage(typ, msg); err != nil {
+				errs <- err
+				return
+			}
+		}
+	}
+	go pipe(upstream, wsw.Conn)
+	go pipe(wsw.Conn, upstream)
+	<-errs
+	return errRelayed
+}
@@ -0,0 +1,171 @@
+package route
+
+import (
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/wrfly/container-web-tty/config"
+)
+
+const defaultCookieName = "web_tty_session"
+
+// sessionCookies seals the admin sessions of the browsers into cookies by
+// the first key, any key opens them, so a new key is put first and the
+// old one is removed once the sessions of it expire, without logging the
+// browsers out. The sessions roll, they expire since their last use
+type sessionCookies struct {
+	opts config.CookieConfig
+	// the SameSite attribute, written by setCookie since SameSite=None is
+	// not of the http.Cookie of Go 1.12
+	sameSite string
+	aeads    []cipher.AEAD
+	// the sessions are bound to the admin token ([]byte of its hash), a
+	// new one logs out
+	binding atomic.Value
+}
+
+func newSessionCookies(opts config.CookieConfig, adminToken string) (*sessionCookies, error) {
+	s := &sessionCookies{opts: opts}
+	if s.opts.Name == "" {
+		s.opts.Name = defaultCookieName
+	}
+	switch strings.ToLower(opts.SameSite) {
+	case "", "strict":
+		s.sameSite = "Strict"
+	case "lax":
+		s.sameSite = "Lax"
+	case "none":
+		if opts.Secure == "never" {
+			return nil, fmt.Errorf("the cookies of SameSite=None must be secure")
+		}
+		s.sameSite = "None"
+	default:
+		return nil, fmt.Errorf("bad cookie SameSite %s, must be strict, lax or none", opts.SameSite)
+	}
+	switch opts.Secure {
+	case "", "auto", "always", "never":
+	default:
+		return nil, fmt.Errorf("bad cookie secure %s, must be auto, always or never", opts.Secure)
+	}
+
+	keys := []string{""}
+	if opts.Keys != "" {
+		keys = strings.Split(opts.Keys, ",")
+	}
+	for _, key := range keys {
+		if key = strings.TrimSpace(key); key == "" && opts.Keys != "" {
+			return nil, fmt.Errorf("empty cookie key")
+		}
+		aead, err := newAEAD(key, "session cookie")
+		if err != nil {
+			return nil, err
+		}
+		s.aeads = append(s.aeads, aead)
+	}
+	s.bind(adminToken)
+	return s, nil
+}
+
+// bind binds the sessions to the admin token, those of another one are
+// logged out
+func (s *sessionCookies) bind(adminToken string) {
+	sum := sha256.Sum256([]byte(s.opts.Name + "\x00" + adminToken))
+	s.binding.Store(sum[:])
+}
+
+// secure reports whether the cookies of the request are secure, the
+// CSRF one too
+func (s *sessionCookies) secure(r *http.Request) bool {
+	switch s.opts.Secure {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+	return r.TLS != nil
+}
+
+// set issues the session cookie expiring after the max age
+func (s *sessionCookies) set(c *gin.Context, path string, now time.Time) {
+	plain := make([]byte, 8)
+	binary.BigEndian.PutUint64(plain, uint64(now.Add(s.opts.MaxAge).Unix()))
+	s.setCookie(c, &http.Cookie{
+		Name:     s.opts.Name,
+		Value:    base64.RawURLEncoding.EncodeToString(s.aeads[0].Seal(nil, nil, plain, s.binding.Load().([]byte))),
+		Path:     path,
+		MaxAge:   int(s.opts.MaxAge / time.Second),
+		Secure:   s.secure(c.Request),
+		HttpOnly: s.opts.HTTPOnly,
+	})
+}
+
+// clear removes the session cookie of the browser
+func (s *sessionCookies) clear(c *gin.Context, path string) {
+	s.setCookie(c, &http.Cookie{
+		Name:     s.opts.Name,
+		Path:     path,
+		MaxAge:   -1,
+		Secure:   s.secure(c.Request),
+		HttpOnly: s.opts.HTTPOnly,
+	})
+}
+
+func (s *sessionCookies) setCookie(c *gin.Context, cookie *http.Cookie) {
+	c.Writer.Header().Add("Set-Cookie", cookie.String()+"; SameSite="+s.sameSite)
+}
+
+// valid reports whether the request has a session cookie not expired,
+// it's renewed after half of the max age so it rolls
+func (s *sessionCookies) valid(c *gin.Context, path string, now time.Time) bool {
+	if s.opts.MaxAge <= 0 {
+		return false
+	}
+	cookie, err := c.Request.Cookie(s.opts.Name)
+	if err != nil {
+		return false
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(cookie.Value)
+	if err != nil {
+		return false
+	}
+	binding := s.binding.Load().([]byte)
+	for i, aead := range s.aeads {
+		plain, err := aead.Open(nil, nil, sealed, binding)
+		if err != nil || len(plain) != 8 {
+			continue
+		}
+		expireAt := time.Unix(int64(binary.BigEndian.Uint64(plain)), 0)
+		if now.After(expireAt) {
+			return false
+		}
+		// sealed again by the new key
+		if i > 0 || expireAt.Sub(now) < s.opts.MaxAge/2 {
+			s.set(c, path, now)
+		}
+		return true
+	}
+	return false
+}
+
+// handleLogin issues the session cookie of the admin, the token is
+// checked by requireAdmin
+func (server *Server) handleLogin(c *gin.Context) {
+	server.cookies.set(c, server.options.BasePath+"/", time.Now())
+	requestLog(c).Info("logged in the admin session")
+	c.Status(http.StatusNoContent)
+}
+
+// handleLogout removes the session cookie of the admin
+func (server *Server) handleLogout(c *gin.Context) {
+	server.cookies.clear(c, server.options.BasePath+"/")
+	c.Status(http.StatusNoContent)
+}
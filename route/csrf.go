@@ -0,0 +1,73 @@
+package route
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// the token of the pages is the cookie, sent back with the header
+	// by the requests changing anything (double-submit)
+	csrfCookie = "csrf_token"
+	csrfHeader = "X-CSRF-Token"
+)
+
+// csrfToken gives the browsers a token of the path if they don't have one
+// yet, it's readable by the scripts of the pages but not by the other sites
+func csrfToken(path string, secure func(*http.Request) bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodGet {
+			if cookie, err := c.Request.Cookie(csrfCookie); err != nil || cookie.Value == "" {
+				token, _ := newSessionID()
+				http.SetCookie(c.Writer, &http.Cookie{
+					Name:     csrfCookie,
+					Value:    token,
+					Path:     path,
+					Secure:   secure(c.Request),
+					SameSite: http.SameSiteStrictMode,
+				})
+			}
+		}
+		c.Next()
+	}
+}
+
+// sameOrigin reports whether the Origin of r is the host of r, the
+// requests without it are not of the scripts of a browser
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// checkCSRF refuses the requests changing anything from the other origins,
+// and the ones with cookies but without the token of the cookie, the
+// requests without cookies (e.g. of the API clients) carry no credentials
+// of a browser to forge
+func checkCSRF(c *gin.Context) {
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return
+	}
+
+	if !sameOrigin(c.Request) {
+		apiError(c, http.StatusForbidden, "cross-origin request from %s", c.GetHeader("Origin"))
+		return
+	}
+	if len(c.Request.Cookies()) == 0 {
+		return
+	}
+	cookie, err := c.Request.Cookie(csrfCookie)
+	if err != nil || cookie.Value == "" ||
+		subtle.ConstantTimeCompare([]byte(c.GetHeader(csrfHeader)), []byte(cookie.Value)) != 1 {
+		apiError(c, http.StatusForbidden, "missing or bad CSRF token")
+		return
+	}
+}
@@ -0,0 +1,144 @@
+package route
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/wrfly/container-web-tty/crash"
+	"github.com/wrfly/container-web-tty/logging"
+)
+
+// diagnosticCheck is the result of a check of /readyz run for the bundle
+type diagnosticCheck struct {
+	Result    string  `json:"result"`
+	LatencyMS float64 `json:"latency_ms"`
+}
+
+// handleDiagnostics downloads the support bundle of the server, a tar.gz
+// of the version, the config with the secrets redacted, the checks of the
+// backend and the others of /readyz, the recent panics, the runtime stats
+// and a dump of the goroutines
+func (server *Server) handleDiagnostics(c *gin.Context) {
+	now := time.Now().UTC()
+	name := "container-web-tty-diagnostics-" + now.Format("20060102T150405Z")
+
+	host, _ := os.Hostname()
+	files := []struct {
+		name string
+		v    interface{}
+	}{
+		{"version.json", map[string]string{
+			"version":    server.options.Build.Version,
+			"commit":     server.options.Build.CommitID,
+			"build_at":   server.options.Build.BuildAt,
+			"go_version": runtime.Version(),
+			"os_arch":    runtime.GOOS + "/" + runtime.GOARCH,
+			"hostname":   host,
+		}},
+		{"config.json", server.options.Redacted},
+		{"options.json", server.settings().effective},
+		{"checks.json", server.diagnosticChecks(c.Request.Context())},
+		{"errors.json", crash.Recent()},
+		{"runtime.json", server.runtimeStats(now)},
+	}
+
+	buf := new(bytes.Buffer)
+	gw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gw)
+	add := func(file string, data []byte) error {
+		err := tw.WriteHeader(&tar.Header{
+			Name:    name + "/" + file,
+			Mode:    0644,
+			Size:    int64(len(data)),
+			ModTime: now,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = tw.Write(data)
+		return err
+	}
+	for _, f := range files {
+		data, err := json.MarshalIndent(f.v, "", "  ")
+		if err != nil {
+			apiError(c, http.StatusInternalServerError, "marshal %s error: %s", f.name, err)
+			return
+		}
+		if err := add(f.name, data); err != nil {
+			apiError(c, http.StatusInternalServerError, "bundle %s error: %s", f.name, err)
+			return
+		}
+	}
+	goroutines := new(bytes.Buffer)
+	pprof.Lookup("goroutine").WriteTo(goroutines, 2)
+	if err := add("goroutines.txt", goroutines.Bytes()); err != nil {
+		apiError(c, http.StatusInternalServerError, "bundle goroutines error: %s", err)
+		return
+	}
+	if err := tw.Close(); err != nil {
+		apiError(c, http.StatusInternalServerError, "bundle error: %s", err)
+		return
+	}
+	gw.Close()
+
+	requestLog(c).Infof("downloaded the diagnostics bundle %s", name)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+".tar.gz"))
+	c.Data(http.StatusOK, "application/gzip", buf.Bytes())
+}
+
+// diagnosticChecks runs all the checks of /readyz, whatever --ready-checks
+func (server *Server) diagnosticChecks(ctx context.Context) map[string]diagnosticCheck {
+	names := make([]string, 0, len(readyChecks))
+	for name := range readyChecks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	checks := make(map[string]diagnosticCheck, len(names))
+	for _, name := range names {
+		cctx, cancel := context.WithTimeout(ctx, readyCheckTimeout)
+		start := time.Now()
+		err := readyChecks[name](server, cctx)
+		cancel()
+		check := diagnosticCheck{
+			Result:    "ok",
+			LatencyMS: float64(time.Since(start)) / float64(time.Millisecond),
+		}
+		if err != nil {
+			check.Result = err.Error()
+		}
+		checks[name] = check
+	}
+	return checks
+}
+
+// runtimeStats are the stats of the process and of the sessions
+func (server *Server) runtimeStats(now time.Time) map[string]interface{} {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	level, modules := logging.Levels()
+	return map[string]interface{}{
+		"time":        now,
+		"goroutines":  runtime.NumGoroutine(),
+		"cpus":        runtime.NumCPU(),
+		"heap_alloc":  mem.HeapAlloc,
+		"heap_sys":    mem.HeapSys,
+		"gc_cycles":   mem.NumGC,
+		"connections": server.counter.count(),
+		"drain":       server.drainStatus(),
+		"log_level":   level,
+		"log_modules": modules,
+	}
+}
@@ -0,0 +1,195 @@
+package route
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/wrfly/container-web-tty/journal"
+	"github.com/wrfly/container-web-tty/types"
+	"github.com/wrfly/container-web-tty/webtty"
+)
+
+var errDraining = errors.New("the server is draining, no new sessions")
+
+// drainNotice is shown to the connected browsers of a draining server
+const drainNotice = "the server is restarting, the session will be closed"
+
+// drainPoll is the interval of checking the sessions left of a drain
+const drainPoll = time.Second
+
+// drainSession is a running terminal to be notified and closed by the
+// drain, or killed and exported by the admin API by the ID of its session
+type drainSession struct {
+	id     string
+	tty    *webtty.WebTTY
+	replay *replayTTY // nil if it can't be resumed
+	cancel context.CancelFunc
+}
+
+// addRunning registers the terminal of a connection until it's closed,
+// it's notified at once if the server is already draining
+func (server *Server) addRunning(id string, tty *webtty.WebTTY, replay *replayTTY, cancel context.CancelFunc) func() {
+	s := &drainSession{id: id, tty: tty, replay: replay, cancel: cancel}
+	server.drainMux.Lock()
+	server.running[s] = struct{}{}
+	deadline := server.drainDeadline
+	server.drainMux.Unlock()
+
+	if !deadline.IsZero() {
+		tty.Notify(drainNotice, secondsUntil(deadline))
+	}
+	return func() {
+		server.drainMux.Lock()
+		delete(server.running, s)
+		server.drainMux.Unlock()
+	}
+}
+
+// draining tells whether the server is draining
+func (server *Server) draining() bool {
+	server.drainMux.Lock()
+	defer server.drainMux.Unlock()
+	return !server.drainDeadline.IsZero()
+}
+
+// secondsUntil rounds up the seconds until the time
+func secondsUntil(t time.Time) int {
+	d := time.Until(t)
+	if d <= 0 {
+		return 0
+	}
+	return int((d + time.Second - 1) / time.Second)
+}
+
+// drainStatus reports the drain and the sessions left
+func (server *Server) drainStatus() types.DrainStatus {
+	server.drainMux.Lock()
+	deadline := server.drainDeadline
+	server.drainMux.Unlock()
+	server.dMux.Lock()
+	detached := len(server.detached)
+	server.dMux.Unlock()
+
+	status := types.DrainStatus{Sessions: server.counter.count() + detached}
+	if !deadline.IsZero() {
+		status.Draining = true
+		status.Deadline = &deadline
+	}
+	return status
+}
+
+// Drain stops accepting new sessions, notifies the connected browsers
+// and closes the sessions left after the timeout, Run returns once all
+// of them are closed. It returns false if the server is already draining.
+func (server *Server) Drain(timeout time.Duration) bool {
+	server.drainMux.Lock()
+	if !server.drainDeadline.IsZero() {
+		server.drainMux.Unlock()
+		return false
+	}
+	deadline := time.Now().Add(timeout)
+	server.drainDeadline = deadline
+	running := make([]*drainSession, 0, len(server.running))
+	for s := range server.running {
+		running = append(running, s)
+	}
+	server.drainMux.Unlock()
+
+	sessions := server.drainStatus().Sessions
+	log.Infof("draining %d sessions in %s", sessions, timeout)
+	server.journal.Log(journal.KindDrain, fmt.Sprintf("draining %d sessions in %s", sessions, timeout), nil)
+	for _, s := range running {
+		s.tty.Notify(drainNotice, secondsUntil(deadline))
+	}
+	go server.waitDrained(timeout)
+	return true
+}
+
+// waitDrained closes the drained channel once the sessions are closed,
+// the ones left after the timeout are closed by force
+func (server *Server) waitDrained(timeout time.Duration) {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	poll := time.NewTicker(drainPoll)
+	defer poll.Stop()
+
+	defer close(server.drained)
+	for server.drainStatus().Sessions > 0 {
+		select {
+		case <-poll.C:
+		case <-deadline.C:
+			log.Warnf("drain deadline passed, closing %d sessions", server.drainStatus().Sessions)
+			server.closeDrained()
+			return
+		}
+	}
+	log.Info("all sessions are closed, drained")
+}
+
+// closeDrained closes the connected and the detached sessions
+func (server *Server) closeDrained() {
+	server.drainMux.Lock()
+	for s := range server.running {
+		s.cancel()
+	}
+	server.drainMux.Unlock()
+
+	server.dMux.Lock()
+	detached := make(map[string]string, len(server.detached))
+	for token, d := range server.detached {
+		detached[token] = d.container.ID
+	}
+	server.dMux.Unlock()
+	for token, containerID := range detached {
+		if d := server.takeDetached(token, containerID); d != nil {
+			d.tty.Exit()
+			server.sessions.close(d.sess.ID, "drain deadline")
+		}
+	}
+}
+
+// handleDrain drains the server in ?timeout= (--drain-timeout by default),
+// GET reports the drain
+func (server *Server) handleDrain(c *gin.Context) {
+	if c.Request.Method == http.MethodGet {
+		c.JSON(http.StatusOK, server.drainStatus())
+		return
+	}
+
+	timeout := server.options.DrainTimeout
+	if t := c.Query("timeout"); t != "" {
+		var err error
+		if timeout, err = time.ParseDuration(t); err != nil || timeout <= 0 {
+			apiError(c, http.StatusBadRequest, "bad timeout: %s", t)
+			return
+		}
+	}
+	if timeout <= 0 {
+		apiError(c, http.StatusBadRequest, "no timeout, set ?timeout= or --drain-timeout")
+		return
+	}
+	if !server.Drain(timeout) {
+		apiError(c, http.StatusConflict, "the server is already draining")
+		return
+	}
+	requestLog(c).Infof("drained the server in %s", timeout)
+	c.JSON(http.StatusAccepted, server.drainStatus())
+}
+
+// rejectDraining rejects the new sessions of a draining server
+func (server *Server) rejectDraining(c *gin.Context) bool {
+	server.drainMux.Lock()
+	deadline := server.drainDeadline
+	server.drainMux.Unlock()
+	if deadline.IsZero() {
+		return false
+	}
+	c.Header("Retry-After", fmt.Sprint(secondsUntil(deadline)))
+	apiError(c, http.StatusServiceUnavailable, errDraining.Error())
+	return true
+}
@@ -0,0 +1,11 @@
+//go:build boringcrypto
+// +build boringcrypto
+
+package route
+
+import (
+	// only the FIPS-approved TLS settings are allowed by the whole program
+	_ "crypto/tls/fipsonly"
+)
+
+func init() { fipsBuild = true }
@@ -0,0 +1,19 @@
+//go:build go1.24
+// +build go1.24
+
+package route
+
+import (
+	"crypto/cipher"
+	"crypto/fips140"
+)
+
+// fipsModule reports whether the native FIPS module of Go 1.24 is on by
+// GODEBUG=fips140=on
+func fipsModule() bool { return fips140.Enabled() }
+
+// newRandomNonceGCM generates the nonces of AES-GCM internally, which is
+// approved by the FIPS module
+func newRandomNonceGCM(block cipher.Block) (cipher.AEAD, error) {
+	return cipher.NewGCMWithRandomNonce(block)
+}
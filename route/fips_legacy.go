@@ -0,0 +1,56 @@
+//go:build !go1.24
+// +build !go1.24
+
+package route
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+)
+
+// fipsModule is false before the native FIPS module of Go 1.24
+func fipsModule() bool { return false }
+
+// newRandomNonceGCM seals with AES-GCM of the random nonces prefixed to
+// the sealed data, the same as cipher.NewGCMWithRandomNonce of Go 1.24
+func newRandomNonceGCM(block cipher.Block) (cipher.AEAD, error) {
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return randomNonceAEAD{gcm}, nil
+}
+
+var errOpenGCM = errors.New("cipher: message authentication failed")
+
+// randomNonceAEAD takes no nonces, Seal generates one
+type randomNonceAEAD struct {
+	gcm cipher.AEAD
+}
+
+func (a randomNonceAEAD) NonceSize() int { return 0 }
+
+func (a randomNonceAEAD) Overhead() int { return a.gcm.NonceSize() + a.gcm.Overhead() }
+
+func (a randomNonceAEAD) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	if len(nonce) != 0 {
+		panic("crypto/cipher: non-empty nonce passed to the GCM with random nonces")
+	}
+	nonce = make([]byte, a.gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		panic(err)
+	}
+	return a.gcm.Seal(append(dst, nonce...), nonce, plaintext, additionalData)
+}
+
+func (a randomNonceAEAD) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != 0 {
+		panic("crypto/cipher: non-empty nonce passed to the GCM with random nonces")
+	}
+	size := a.gcm.NonceSize()
+	if len(ciphertext) < size+a.gcm.Overhead() {
+		return nil, errOpenGCM
+	}
+	return a.gcm.Open(dst, ciphertext[:size], ciphertext[size:], additionalData)
+}
@@ -0,0 +1,241 @@
+package route
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	"github.com/wrfly/container-web-tty/types"
+)
+
+// forwardDialTimeout is the max time to connect to the port of a container
+const forwardDialTimeout = 5 * time.Second
+
+// forward is a temporary URL forwarded to a port of a container
+type forward struct {
+	container types.Container
+	addr      string
+	proxy     *httputil.ReverseProxy
+	timer     *time.Timer
+}
+
+// forwardAddr returns the address of the port of the container,
+// the error is responded if it's not found
+func (server *Server) forwardAddr(c *gin.Context) (types.Container, string, bool) {
+	port, err := strconv.Atoi(c.Param("port"))
+	if err != nil || port <= 0 || port > 65535 {
+		apiError(c, http.StatusBadRequest, "bad port: %s", c.Param("port"))
+		return types.Container{}, "", false
+	}
+
+	container := server.containerCli.GetInfo(c.Request.Context(), c.Param("id"))
+	if container.ID == "" {
+		apiError(c, http.StatusNotFound, "container %s not found", c.Param("id"))
+		return container, "", false
+	}
+	// the IPs of the remote containers are not reachable from here
+	if container.LocServer != "" {
+		apiError(c, http.StatusNotImplemented, "port forwarding is not supported by the grpc backend")
+		return container, "", false
+	}
+	for _, ip := range container.IPs {
+		if ip != "" && ip != "null" {
+			return container, net.JoinHostPort(ip, strconv.Itoa(port)), true
+		}
+	}
+	apiError(c, http.StatusBadRequest, "container %s has no IP", container.ID)
+	return container, "", false
+}
+
+// handleForward returns a temporary URL forwarded to the
+// HTTP server listening on the port of the container
+func (server *Server) handleForward(c *gin.Context) {
+	var opts types.ForwardOptions
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&opts); err != nil {
+			apiError(c, http.StatusBadRequest, "bad request: %s", err)
+			return
+		}
+	}
+
+	ttl := server.options.ForwardTTL
+	if opts.TTL != "" {
+		t, err := time.ParseDuration(opts.TTL)
+		if err != nil || t <= 0 {
+			apiError(c, http.StatusBadRequest, "bad ttl: %s", opts.TTL)
+			return
+		}
+		// the server's ttl is the upper limit
+		if t < ttl {
+			ttl = t
+		}
+	}
+
+	container, addr, ok := server.forwardAddr(c)
+	if !ok {
+		return
+	}
+	token, err := newSessionID()
+	if err != nil {
+		apiError(c, http.StatusInternalServerError, "%s", err)
+		return
+	}
+
+	f := &forward{
+		container: container,
+		addr:      addr,
+		proxy:     httputil.NewSingleHostReverseProxy(&url.URL{Scheme: "http", Host: addr}),
+	}
+	director := f.proxy.Director
+	f.proxy.Director = func(r *http.Request) {
+		director(r)
+		// the forwarded apps are anonymous, the credentials sent to the
+		// server are not theirs
+		r.Header.Del("Cookie")
+		r.Header.Del("Authorization")
+		r.Header.Del(credentialHeader)
+	}
+	f.proxy.ModifyResponse = func(resp *http.Response) error {
+		resp.Header.Del("Set-Cookie")
+		return nil
+	}
+	f.proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		log.Debugf("forward to %s error: %s", addr, err)
+		http.Error(w, "forward error: "+err.Error(), http.StatusBadGateway)
+	}
+	server.fMux.Lock()
+	server.forwards[token] = f
+	f.timer = time.AfterFunc(ttl, func() {
+		server.fMux.Lock()
+		delete(server.forwards, token)
+		server.fMux.Unlock()
+	})
+	server.fMux.Unlock()
+
+	forwardURL := server.externalURL("/forward/" + token + "/")
+	if server.forwardHost != "" {
+		forwardURL = server.options.ForwardURL + server.options.BasePath + "/forward/" + token + "/"
+	}
+	requestLog(c).Infof("forwarded %s of container %s", addr, container.ID)
+	c.JSON(http.StatusOK, types.ForwardResult{
+		URL:      forwardURL,
+		ExpireAt: time.Now().Add(ttl),
+	})
+}
+
+// parseOrigin returns the host of the http(s) URL of an origin, empty for
+// an empty URL
+func parseOrigin(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("bad origin %s", s)
+	}
+	return u.Host, nil
+}
+
+// forwardOrigin serves only the forwards on the host of --forward-url, the
+// UI and the API are not of the origin of the forwarded apps
+func (server *Server) forwardOrigin(c *gin.Context) {
+	if server.forwardHost == "" || !strings.EqualFold(c.Request.Host, server.forwardHost) {
+		return
+	}
+	if !strings.HasPrefix(c.Request.URL.Path, server.options.BasePath+"/forward/") {
+		c.AbortWithStatus(http.StatusNotFound)
+	}
+}
+
+// handleForwardProxy proxies the requests of a temporary URL, on the host
+// of --forward-url, or sandboxed without it: the pages of the apps are of
+// a unique origin, which can't read the cookies or call the API of the UI
+func (server *Server) handleForwardProxy(c *gin.Context) {
+	if server.forwardHost != "" && !strings.EqualFold(c.Request.Host, server.forwardHost) {
+		c.String(http.StatusNotFound, "forward not found or expired")
+		return
+	}
+	server.fMux.Lock()
+	f := server.forwards[c.Param("token")]
+	server.fMux.Unlock()
+	if f == nil {
+		c.String(http.StatusNotFound, "forward not found or expired")
+		return
+	}
+
+	c.Request.URL.Path = c.Param("path")
+	c.Request.URL.RawPath = ""
+	clearSecurityHeaders(c.Writer.Header())
+	if server.forwardHost == "" {
+		c.Header("Content-Security-Policy", "sandbox")
+	}
+	f.proxy.ServeHTTP(c.Writer, c.Request)
+}
+
+// handleForwardTunnel tunnels the binary messages of the
+// websocket to the TCP port of the container
+func (server *Server) handleForwardTunnel(c *gin.Context) {
+	if !websocket.IsWebSocketUpgrade(c.Request) {
+		apiError(c, http.StatusBadRequest, "websocket upgrade required, or POST for a forward URL")
+		return
+	}
+	container, addr, ok := server.forwardAddr(c)
+	if !ok {
+		return
+	}
+	tcp, err := net.DialTimeout("tcp", addr, forwardDialTimeout)
+	if err != nil {
+		apiError(c, http.StatusBadGateway, "dial %s error: %s", addr, err)
+		return
+	}
+	defer tcp.Close()
+
+	conn, err := server.upgrade(c)
+	if err != nil {
+		log.Errorf("upgrade ws error: %s", err)
+		return
+	}
+	defer conn.Close()
+	requestLog(c).Infof("tunnels to %s of container %s", addr, container.ID)
+
+	go func() {
+		defer tcp.Close()
+		for {
+			_, r, err := conn.NextReader()
+			if err != nil {
+				return
+			}
+			if _, err := io.Copy(tcp, r); err != nil {
+				return
+			}
+		}
+	}()
+
+	buf := make([]byte, 32<<10)
+	for {
+		n, err := tcp.Read(buf)
+		if n > 0 {
+			if err := conn.WriteMessage(websocket.BinaryMessage, buf[:n]); err != nil {
+				return
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+		time.Now().Add(time.Second))
+}
@@ -3,110 +3,249 @@ package route
 import (
 	"bytes"
 	"context"
+	"crypto/subtle"
 	"encoding/json"
 	"fmt"
+	"html/template"
 	"net/http"
 	"net/url"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/gorilla/websocket"
-	log "github.com/sirupsen/logrus"
-	"github.com/yudai/gotty/webtty"
 
 	"github.com/wrfly/container-web-tty/audit"
+	"github.com/wrfly/container-web-tty/container"
+	"github.com/wrfly/container-web-tty/tracing"
 	"github.com/wrfly/container-web-tty/types"
+	"github.com/wrfly/container-web-tty/webtty"
 )
 
+// ttyOpener opens the tty of a new connection, it reads the init message,
+// updates the exec options of the container and sets the ID of the session
+type ttyOpener func(ctx context.Context, conn master,
+	container *types.Container, sess *types.Session) (types.TTY, error)
+
 func (server *Server) handleExec(c *gin.Context, counter *counter) {
-	cInfo := server.containerCli.GetInfo(c.Request.Context(), c.Param("id"))
-	server.generateHandleWS(c.Request.Context(), counter, cInfo).
-		ServeHTTP(c.Writer, c.Request)
+	ctx := c.Request.Context()
+	id, exec, ok := server.execTarget(c)
+	if !ok {
+		return
+	}
+	container := server.containerCli.GetInfo(ctx, id)
+	if container.ID == "" {
+		// the browser is told before the connection is closed
+		container.ID = id
+		server.serveTTY(c, counter, container, func(ctx context.Context, conn master,
+			container *types.Container, sess *types.Session) (types.TTY, error) {
+			return nil, fmt.Errorf("container %s not found", container.ID)
+		})
+		return
+	}
+	container.Exec = execOptions(exec)
+	server.serveTTY(c, counter, container, server.execTTY)
 }
 
-func (server *Server) generateHandleWS(ctx context.Context, counter *counter, container types.Container) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		if container.Shell == "" {
-			log.Errorf("cannot find a valid shell in container [%s]", container.ID)
-			return
-		}
-
-		num := counter.add(1)
-		closeReason := "unknown reason"
+func (server *Server) serveTTY(c *gin.Context, counter *counter,
+	container types.Container, open ttyOpener) {
+	ctx := c.Request.Context()
+	num := counter.add(1)
+	closeReason := "unknown reason"
+	detached := false
+	sess := &types.Session{
+		ContainerID: container.ID,
+		Client:      c.Request.RemoteAddr,
+		StartAt:     time.Now(),
+	}
 
-		defer func() {
-			num := counter.done()
-			if strings.Contains(closeReason, "error") {
-				log.Errorf("Connection closed by %s: %s, connections: %d",
-					closeReason, r.RemoteAddr, num)
-			}
-			log.Infof("Connection closed by %s: %s, connections: %d",
-				closeReason, r.RemoteAddr, num)
-		}()
+	// the id of the path is the token of a signed URL
+	sessLog := requestLog(c).WithField("container", container.ID)
+	if container.Exec.User != "" {
+		sessLog = sessLog.WithField("user", container.Exec.User)
+	}
 
-		if int64(server.options.MaxConnection) != 0 {
-			if num > server.options.MaxConnection {
-				closeReason = "exceeding max number of connections"
-				return
-			}
+	defer func() {
+		num := counter.done()
+		if strings.Contains(closeReason, "error") {
+			sessLog.Errorf("Connection closed by %s: %s, connections: %d",
+				closeReason, c.Request.RemoteAddr, num)
+		}
+		sessLog.Infof("Connection closed by %s: %s, connections: %d",
+			closeReason, c.Request.RemoteAddr, num)
+		if !detached {
+			server.sessions.close(sess.ID, closeReason)
 		}
+	}()
 
-		log.Infof("New client connected: %s, connections: %d", r.RemoteAddr, num)
+	sessLog.Infof("New client connected: %s, connections: %d", c.Request.RemoteAddr, num)
 
-		conn, err := server.upgrader.Upgrade(w, r, nil)
-		if err != nil {
-			closeReason = err.Error()
-			return
-		}
-		defer conn.Close()
-
-		cctx, timeoutCancel := context.WithCancel(ctx)
-		defer timeoutCancel()
-
-		err = server.processTTY(cctx, timeoutCancel, conn, container)
-		switch err {
-		case ctx.Err():
-			closeReason = "cancelation"
-		case cctx.Err():
-			closeReason = "time out"
-		case webtty.ErrSlaveClosed:
-			closeReason = "backend closed"
-		case webtty.ErrMasterClosed:
-			closeReason = "tab closed"
-		default:
-			closeReason = fmt.Sprintf("an error: %s", err)
+	start, ok := c.Get(requestStartKey)
+	if !ok {
+		start = time.Now()
+	}
+	setup := newSessionSetup(start.(time.Time))
+	conn, err := server.openMaster(c)
+	if err != nil {
+		setup.failed("connect")
+		closeReason = err.Error()
+		return
+	}
+	defer conn.Close()
+
+	cctx, timeoutCancel := context.WithCancel(ctx)
+	defer timeoutCancel()
+
+	err = server.processTTY(cctx, timeoutCancel, conn, container, sess, setup, open)
+	switch {
+	case err == ctx.Err(), err == webtty.ErrMasterClosed, err == errDetached,
+		err == errRelayed, err == errDraining, err == errMaintenance:
+		setup.abandoned()
+	case sess.ID == "":
+		// the session is named once the tty is opened
+		setup.failed("exec")
+	default:
+		setup.failed("output")
+	}
+	switch err {
+	case ctx.Err():
+		closeReason = "cancelation"
+	case cctx.Err():
+		closeReason = "time out"
+		if server.draining() {
+			closeReason = "drain deadline"
 		}
+	case webtty.ErrSlaveClosed:
+		closeReason = "backend closed"
+	case webtty.ErrMasterClosed:
+		closeReason = "tab closed"
+	case errDetached:
+		closeReason = "tab closed, session detached"
+		detached = true
+	case errRelayed:
+		closeReason = "relay closed"
+	case errDraining:
+		closeReason = "draining"
+	case errMaintenance:
+		closeReason = "maintenance"
+	default:
+		closeReason = fmt.Sprintf("an error: %s", err)
+		writeSessionError(conn, sessionError(err, c.GetString(requestIDKey)))
 	}
 }
 
-func (server *Server) processTTY(ctx context.Context, timeoutCancel context.CancelFunc,
-	conn *websocket.Conn, container types.Container) error {
-	arguments, err := server.readInitMessage(conn)
+// execTTY execs into the container with the arguments of the init message
+func (server *Server) execTTY(ctx context.Context, conn master,
+	container *types.Container, sess *types.Session) (types.TTY, error) {
+	init, err := server.readInit(conn)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if init.ResumeToken != "" && server.options.ResumeTimeout > 0 {
+		if d := server.takeDetached(init.ResumeToken, container.ID); d != nil {
+			log.Infof("resume session %s of container %s", d.sess.ID, container.ID)
+			*container = d.container
+			sess.ID = d.sess.ID
+			sess.Cmd = d.sess.Cmd
+			sess.User = d.sess.User
+			sess.StartAt = d.sess.StartAt
+			return d.tty.attach(true), nil
+		}
+		if replica := server.remoteReplica(conn, init.ResumeToken, container.ID); replica != "" {
+			return nil, server.relaySession(conn, replica, init)
+		}
+		// expired, start a new one
+	}
+	if server.draining() {
+		notice, _ := json.Marshal(map[string]interface{}{"message": errDraining.Error()})
+		conn.Write(append([]byte{webtty.Notice}, notice...))
+		return nil, errDraining
 	}
+	if message := server.maintenanceMessage(); message != "" {
+		notice, _ := json.Marshal(map[string]interface{}{"message": message})
+		conn.Write(append([]byte{webtty.Notice}, notice...))
+		return nil, errMaintenance
+	}
+	arguments := init.Arguments
 	log.Debugf("exec container: %s, params: %s", container.ID, arguments)
 
-	if q, err := parseQuery(strings.TrimSpace(arguments)); err != nil {
-		return err
-	} else {
-		container.Exec = types.ExecOptions{
-			Cmd:        q.Get("cmd"),
-			Env:        q.Get("env"),
-			User:       q.Get("user"),
-			Privileged: q.Get("p") != "",
+	// the options of a signed URL are of its token, not of the query
+	if server.signer == nil {
+		q, err := parseQuery(strings.TrimSpace(arguments))
+		if err != nil {
+			return nil, err
+		}
+		container.Exec = execOptions(q)
+	}
+	// the main process is attached without a shell
+	if container.Shell == "" && !container.Exec.Attach {
+		return nil, fmt.Errorf("cannot find a valid shell in container [%s]", container.ID)
+	}
+
+	if server.options.ResumeTimeout <= 0 {
+		containerTTY, err := server.containerCli.Exec(ctx, *container)
+		if err != nil {
+			return nil, fmt.Errorf("exec container error: %s", err)
+		}
+		if sess.ID, err = newSessionID(); err != nil {
+			containerTTY.Exit()
+			return nil, err
 		}
+		sess.Cmd = container.Exec.Cmd
+		sess.User = container.Exec.User
+		return containerTTY, nil
 	}
 
-	containerTTY, err := server.containerCli.Exec(ctx, container)
+	// the exec outlives the connection if it's detached, it's still of
+	// the trace of the request
+	execCtx, cancel := context.WithCancel(
+		tracing.WithSpan(context.Background(), tracing.FromContext(ctx)))
+	containerTTY, err := server.containerCli.Exec(execCtx, *container)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("exec container error: %s", err)
+	}
+	if sess.ID, err = newSessionID(); err != nil {
+		containerTTY.Exit()
+		cancel()
+		return nil, err
+	}
+	sess.Cmd = container.Exec.Cmd
+	sess.User = container.Exec.User
+	replay, err := newReplayTTY(containerTTY, server.options.ReplayBuffer, cancel)
 	if err != nil {
-		return fmt.Errorf("exec container error: %s", err)
+		containerTTY.Exit()
+		cancel()
+		return nil, err
 	}
-	defer containerTTY.Exit()
+	return replay.attach(false), nil
+}
+
+func (server *Server) processTTY(ctx context.Context, timeoutCancel context.CancelFunc,
+	conn master, container types.Container, sess *types.Session, setup *sessionSetup,
+	open ttyOpener) error {
+	containerTTY, err := open(ctx, conn, &container, sess)
+	if err != nil {
+		return err
+	}
+	// sessions of a replayTTY can be resumed
+	var replay *replayTTY
+	resumed := false
+	if a, ok := containerTTY.(*attachedTTY); ok && server.options.ResumeTimeout > 0 {
+		replay = a.replayTTY
+		resumed = a.gen > 1
+	}
+	detached := false
+	defer func() {
+		if !detached {
+			containerTTY.Exit()
+		}
+	}()
+	counted := server.sessions.add(sess)
 
 	// handle timeout
-	tout := server.options.IdleTime
+	tout := server.settings().idleTime
 	if tout.Seconds() != 0 {
 		go func() {
 			timer := time.NewTimer(tout)
@@ -120,6 +259,9 @@ func (server *Server) processTTY(ctx context.Context, timeoutCancel context.Canc
 				case <-activeChan:
 					// the connection is active, reset the timer
 					timer.Reset(tout)
+				case <-ctx.Done():
+					timer.Stop()
+					return
 				}
 			}
 
@@ -131,13 +273,28 @@ func (server *Server) processTTY(ctx context.Context, timeoutCancel context.Canc
 		return fmt.Errorf("failed to fill window title template: %s", err)
 	}
 
-	opts := []webtty.Option{
-		webtty.WithWindowTitle(titleBuf),
-		webtty.WithPermitWrite(),
-		// webtty.WithReconnect(10), // not work....
+	opts := append(server.relayOptions(), webtty.WithWindowTitle(titleBuf))
+	// read-only if attached without the stdin
+	if exec := container.Exec; !exec.Attach || exec.AttachStdin {
+		opts = append(opts, webtty.WithPermitWrite())
+	}
+	// the files of sz and rz
+	if server.options.EnableZmodem {
+		opts = append(opts, webtty.WithBinaryInput())
+	}
+	if replay != nil {
+		opts = append(opts,
+			webtty.WithReconnect(resumeReconnectDelay),
+			webtty.WithResumeToken(replay.token),
+		)
+	}
+	opts = append(opts, webtty.WithClipboard(server.clipboardPolicy(container.Exec.User),
+		server.options.ClipboardMaxSize))
+	// once a session, not again after resuming it
+	if motd := server.settings().motd; motd != "" && !resumed {
+		opts = append(opts, webtty.WithMOTD(motd))
 	}
 
-	wrapper := &wsWrapper{conn}
 	shareableTTY := types.NewShareTTY(containerTTY)
 	server.mMux.Lock()
 	server.masters[container.ID] = shareableTTY
@@ -149,7 +306,7 @@ func (server *Server) processTTY(ctx context.Context, timeoutCancel context.Canc
 	}()
 
 	if server.options.EnableAudit {
-		cIP := conn.RemoteAddr().String()
+		cIP := conn.remoteAddr()
 		r := shareableTTY.Fork(cIP)
 		go audit.LogTo(ctx, r, audit.LogOpts{
 			Dir:         server.options.AuditLogDir,
@@ -158,38 +315,53 @@ func (server *Server) processTTY(ctx context.Context, timeoutCancel context.Canc
 		})
 	}
 
-	tty, err := webtty.New(wrapper, shareableTTY, opts...)
+	tty, err := webtty.New(&countedMaster{Master: conn, sess: counted, setup: setup}, shareableTTY, opts...)
 	if err != nil {
 		return fmt.Errorf("failed to create webtty: %s", err)
 	}
+	defer server.addRunning(sess.ID, tty, replay, timeoutCancel)()
 
-	return tty.Run(ctx)
+	err = tty.Run(ctx)
+	if err == webtty.ErrMasterClosed && replay != nil && !replay.exited() {
+		detached = true
+		server.detach(replay, container, *sess)
+		return errDetached
+	}
+	if err == webtty.ErrSlaveClosed {
+		if code, e := containerTTY.ExitCode(); e == nil {
+			server.sessions.exited(sess.ID, code)
+		}
+	}
+	return err
 }
 
 func (server *Server) handleWSIndex(c *gin.Context) {
 	cInfo := server.containerCli.GetInfo(c.Request.Context(), c.Param("id"))
-	titleVars := server.titleVariables(
-		[]string{"server"},
-		map[string]map[string]interface{}{
-			"server": map[string]interface{}{
-				"containerName": cInfo.Name,
-				"containerID":   cInfo.ID,
-			},
-		},
-	)
+	server.renderTerminal(c, cInfo)
+}
 
-	titleBuf := new(bytes.Buffer)
-	err := titleTemplate.Execute(titleBuf, titleVars)
+func (server *Server) renderTerminal(c *gin.Context, cInfo types.Container) {
+	server.renderTerminalWith(c, cInfo, nil)
+}
+
+// renderTerminalWith renders the terminal page with the extra variables
+func (server *Server) renderTerminalWith(c *gin.Context, cInfo types.Container, vars map[string]interface{}) {
+	title, err := server.makeTitleBuff(cInfo)
 	if err != nil {
 		c.Error(err)
 	}
 
 	indexVars := map[string]interface{}{
-		"title": titleBuf.String(),
+		"title": string(title),
+		"embed": c.Query("embed") == "1",
+		"clock": server.options.Clock,
+	}
+	for k, v := range vars {
+		indexVars[k] = v
 	}
 
 	indexBuf := new(bytes.Buffer)
-	err = indexTemplate.Execute(indexBuf, indexVars)
+	err = server.execTemplate(indexBuf, "/index.html", indexVars)
 	if err != nil {
 		c.Error(err)
 	}
@@ -205,7 +377,11 @@ func (server *Server) handleAuthToken(c *gin.Context) {
 
 func (server *Server) handleConfig(c *gin.Context) {
 	c.Header("Content-Type", "application/javascript")
-	c.String(200, "var gotty_term = '%s';", server.options.Term)
+	c.String(200, "var gotty_term = '%s';\nvar gotty_embed_origin = '%s';\nvar gotty_base_path = '%s';\nvar gotty_banner = '%s';\nvar gotty_timezone = '%s';\nvar gotty_zmodem = %t;",
+		server.options.Term, template.JSEscapeString(server.options.EmbedOrigin),
+		template.JSEscapeString(server.options.BasePath),
+		template.JSEscapeString(server.settings().banner),
+		template.JSEscapeString(server.zoneName), server.options.EnableZmodem)
 }
 
 // titleVariables merges maps in a specified order.
@@ -231,17 +407,55 @@ func (server *Server) titleVariables(order []string, varUnits map[string]map[str
 	return titleVars
 }
 
+// cached is true if the backend caches the containers
+func (server *Server) cached() bool {
+	_, ok := server.containerCli.(container.Refresher)
+	return ok
+}
+
+// listContainers lists the containers, the cache of the backend is
+// refreshed with ?refresh=1
+func (server *Server) listContainers(c *gin.Context) []types.Container {
+	if r, ok := server.containerCli.(container.Refresher); ok && c.Query("refresh") == "1" {
+		r.Refresh()
+	}
+	return server.containerCli.List(c.Request.Context())
+}
+
 func (server *Server) handleListContainers(c *gin.Context) {
+	containers := server.listContainers(c)
+	// the path segments of the exec, container and share URLs by the IDs
+	execPaths := make(map[string]string, len(containers))
+	attachPaths := make(map[string]string, len(containers))
+	sharePaths := make(map[string]string, len(containers))
+	for _, container := range containers {
+		id := container.ID
+		if len(id) > 12 {
+			id = id[:12]
+		}
+		execPaths[container.ID] = server.execPath(c, id, nil)
+		attachPaths[container.ID] = server.execPath(c, id, url.Values{"attach": {"1"}})
+		if server.options.EnableShare {
+			sharePaths[container.ID] = server.sharePath(id)
+		}
+	}
 	listVars := map[string]interface{}{
-		"title":      "List Containers",
-		"containers": server.containerCli.List(c.Request.Context()),
-		"control":    server.options.Control,
-		"loc":        server.options.ShowLocation,
-		"share":      server.options.EnableShare,
+		"title":       "List Containers",
+		"containers":  containers,
+		"exec":        execPaths,
+		"attach":      attachPaths,
+		"shares":      sharePaths,
+		"control":     server.options.Control,
+		"loc":         server.options.ShowLocation,
+		"share":       server.options.EnableShare,
+		"admin":       server.settings().adminToken != "" || c.GetBool(adminAuthKey),
+		"cached":      server.cached(),
+		"banner":      server.settings().banner,
+		"maintenance": server.maintenanceMessage(),
 	}
 
 	listBuf := new(bytes.Buffer)
-	err := listTemplate.Execute(listBuf, listVars)
+	err := server.execTemplate(listBuf, "/list.html", listVars)
 	if err != nil {
 		c.Error(err)
 	}
@@ -249,11 +463,36 @@ func (server *Server) handleListContainers(c *gin.Context) {
 	c.Writer.Write(listBuf.Bytes())
 }
 
+// controlActions returns the container actions enabled by the control config
+func (server *Server) controlActions() []string {
+	ctl := server.options.Control
+	var actions []string
+	if ctl.Start || ctl.All {
+		actions = append(actions, "start")
+	}
+	if ctl.Stop || ctl.All {
+		actions = append(actions, "stop")
+	}
+	if ctl.Restart || ctl.All {
+		actions = append(actions, "restart")
+	}
+	if ctl.Pause || ctl.All {
+		actions = append(actions, "pause", "unpause")
+	}
+	if ctl.Kill || ctl.All {
+		actions = append(actions, "kill")
+	}
+	return actions
+}
+
+// validSignal matches the signal names like SIGTERM or TERM and the numbers
+var validSignal = regexp.MustCompile(`^([A-Z][A-Z0-9+-]{0,15}|[0-9]{1,2})$`)
+
 func (server *Server) handleContainerActions(c *gin.Context, action string) {
 	cid := c.Param("id")
-	log.Debugf("client [%s] is going to [%s] container [%s]",
-		c.ClientIP(), action, cid)
+	requestLog(c).Debugf("going to [%s] container [%s]", action, cid)
 	var err error
+	detail := ""
 	switch action {
 	case "start":
 		err = server.containerCli.Start(c.Request.Context(), cid)
@@ -261,36 +500,35 @@ func (server *Server) handleContainerActions(c *gin.Context, action string) {
 		err = server.containerCli.Stop(c.Request.Context(), cid)
 	case "restart":
 		err = server.containerCli.Restart(c.Request.Context(), cid)
+	case "pause":
+		err = server.containerCli.Pause(c.Request.Context(), cid)
+	case "unpause":
+		err = server.containerCli.Unpause(c.Request.Context(), cid)
+	case "kill":
+		signal := strings.ToUpper(c.DefaultQuery("signal", "SIGKILL"))
+		if !validSignal.MatchString(signal) {
+			apiError(c, http.StatusBadRequest, "bad signal: %s", signal)
+			return
+		}
+		err = server.containerCli.Kill(c.Request.Context(), cid, signal)
+		detail = " with " + signal
 	}
 	if err != nil {
-		c.JSON(500, types.ContainerActionMessage{
-			Code:  500,
-			Error: err.Error(),
-		})
+		apiError(c, http.StatusInternalServerError, "%s", err)
 		return
 	}
-	c.JSON(0, types.ContainerActionMessage{
+	c.JSON(http.StatusOK, types.ContainerActionMessage{
 		Code:    0,
-		Message: fmt.Sprintf("%s container %s successfully", action, cid[:7]),
+		Message: fmt.Sprintf("%s container %.7s%s successfully", action, cid, detail),
 	})
 }
 
-func (server *Server) handleStartContainer(c *gin.Context) {
-	server.handleContainerActions(c, "start")
-}
-
-func (server *Server) handleStopContainer(c *gin.Context) {
-	server.handleContainerActions(c, "stop")
-}
-
-func (server *Server) handleRestartContainer(c *gin.Context) {
-	server.handleContainerActions(c, "restart")
-}
-
-func (server *Server) handleLogs(c *gin.Context) {
+// handleLogs serves the logs in a terminal, the input is only
+// echoed as new lines if permitWrite is true
+func (server *Server) handleLogs(c *gin.Context, permitWrite bool) {
 	ctx := c.Request.Context()
 
-	conn, err := server.upgrader.Upgrade(c.Writer, c.Request, nil)
+	conn, err := server.openMaster(c)
 	if err != nil {
 		c.String(http.StatusInternalServerError, "server error: %s", err)
 		return
@@ -308,24 +546,16 @@ func (server *Server) handleLogs(c *gin.Context) {
 		c.String(http.StatusBadRequest, err.Error())
 		return
 	}
-	follow := true
-	if v := q.Get("follow"); v != "1" && v != "" {
-		follow = false
-	}
-	tail := "10"
-	if v := q.Get("tail"); v != "" {
-		tail = v
-	}
-	opts := types.LogOptions{
-		ID:     c.Param("id"),
-		Follow: follow,
-		Tail:   tail,
+	opts, err := parseLogOptions(c.Param("id"), q, true, "10")
+	if err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
 	}
 
 	container := server.containerCli.GetInfo(ctx, opts.ID)
 
 	log.Debugf("get logs of container: %s", container.ID)
-	logsReadCloser, err := server.containerCli.Logs(ctx, opts)
+	logsReadCloser, err := server.logs(ctx, opts)
 	if err != nil {
 		c.String(http.StatusInternalServerError, "get logs error: %s", err)
 		return
@@ -338,14 +568,11 @@ func (server *Server) handleLogs(c *gin.Context) {
 		return
 	}
 
-	tty, err := webtty.New(
-		&wsWrapper{conn},
-		newSlave(logsReadCloser, false),
-		[]webtty.Option{
-			webtty.WithWindowTitle(titleBuf),
-			webtty.WithPermitWrite(), // can type "enter"
-		}...,
-	)
+	ttyOpts := append(server.relayOptions(), webtty.WithWindowTitle(titleBuf))
+	if permitWrite {
+		ttyOpts = append(ttyOpts, webtty.WithPermitWrite()) // can type "enter"
+	}
+	tty, err := webtty.New(conn, newSlave(logsReadCloser, false), ttyOpts...)
 	if err != nil {
 		c.String(http.StatusInternalServerError, "failed to create webtty: %s", err)
 		return
@@ -363,9 +590,9 @@ func (server *Server) handleShare(c *gin.Context) {
 	cid := c.Param("id")
 	cInfo := server.containerCli.GetInfo(ctx, cid)
 
-	conn, err := server.upgrader.Upgrade(c.Writer, c.Request, nil)
+	conn, err := server.openMaster(c)
 	if err != nil {
-		log.Errorf("upgrade ws error: %s", err)
+		log.Errorf("open connection error: %s", err)
 		return
 	}
 	defer conn.Close()
@@ -379,14 +606,14 @@ func (server *Server) handleShare(c *gin.Context) {
 	server.mMux.RUnlock()
 	if !ok {
 		log.Error("share terminal error, master not found")
-		conn.WriteMessage(websocket.CloseMessage, []byte("not found"))
+		conn.closeWith("not found")
 		return
 	}
 
 	titleBuf, err := server.makeTitleBuff(cInfo)
 	if err != nil {
 		e := fmt.Sprintf("failed to fill window title template: %s", err)
-		conn.WriteMessage(websocket.CloseMessage, []byte(e))
+		conn.closeWith(e)
 		log.Error(e)
 		return
 	}
@@ -395,15 +622,15 @@ func (server *Server) handleShare(c *gin.Context) {
 	defer fork.Close()
 
 	tty, err := webtty.New(
-		&wsWrapper{conn},
+		conn,
 		newSlave(fork, true),
-		[]webtty.Option{
+		append(server.relayOptions(),
 			webtty.WithWindowTitle(titleBuf),
-			webtty.WithPermitWrite()}...,
+			webtty.WithPermitWrite())...,
 	)
 	if err != nil {
 		e := fmt.Sprintf("failed to create webtty: %s", err)
-		conn.WriteMessage(websocket.CloseMessage, []byte(e))
+		conn.closeWith(e)
 		log.Error(e)
 		return
 	}
@@ -414,8 +641,26 @@ func (server *Server) handleShare(c *gin.Context) {
 	}
 }
 
+// relayOptions are the buffer size and the output coalescing of the
+// terminals, the OSC 52 sequences are dropped unless the session allows them
+func (server *Server) relayOptions() []webtty.Option {
+	opts := []webtty.Option{webtty.WithClipboard(webtty.ClipboardOff, 0)}
+	if size := server.options.RelayBufferSize; size > 0 {
+		opts = append(opts, webtty.WithBufferSize(size))
+	}
+	if size := server.options.MaxInputFrame; size > 0 {
+		opts = append(opts, webtty.WithMaxInput(size))
+	}
+	if window := server.options.CoalesceWindow; window > 0 {
+		opts = append(opts, webtty.WithCoalescing(window, server.options.CoalesceSize))
+	}
+	return opts
+}
+
 func (server *Server) terminalPage(c *gin.Context) { server.handleWSIndex(c) }
 
+// makeTitleBuff fills the window title template of the container, the
+// docker or kube one of --title-format-* if it's set
 func (server *Server) makeTitleBuff(c types.Container) ([]byte, error) {
 	location := "127.0.0.1"
 	if c.LocServer != "" {
@@ -426,38 +671,81 @@ func (server *Server) makeTitleBuff(c types.Container) ([]byte, error) {
 		[]string{"server"},
 		map[string]map[string]interface{}{
 			"server": map[string]interface{}{
-				"containerLoc":  location,
-				"containerName": c.Name,
-				"containerID":   c.ID,
+				"containerLoc":   location,
+				"containerName":  c.Name,
+				"containerID":    c.ID,
+				"containerImage": c.Image,
+				"backend":        containerBackend(c),
+				"host":           server.titles.host(c),
+				"user":           c.Exec.User,
+				"namespace":      c.Namespace,
+				"podName":        c.PodName,
 			},
 		},
 	)
-	titleBuf := new(bytes.Buffer)
-	if err := titleTemplate.Execute(titleBuf, titleVars); err != nil {
-		return nil, err
-	}
+	return server.titles.execute(c, titleVars)
+}
 
-	return titleBuf.Bytes(), nil
+func (server *Server) readInitMessage(conn master) (string, error) {
+	init, err := server.readInit(conn)
+	return init.Arguments, err
 }
 
-func (server *Server) readInitMessage(conn *websocket.Conn) (string, error) {
-	typ, initLine, err := conn.ReadMessage()
-	if err != nil {
-		return "", fmt.Errorf("failed to authenticate websocket connection")
+// readInit reads and authenticates the init message of the connection
+func (server *Server) readInit(conn master) (types.InitMessage, error) {
+	var init types.InitMessage
+	if timeout := server.options.InitTimeout; timeout > 0 {
+		// the connections never sending it are closed
+		timer := time.AfterFunc(timeout, func() {
+			conn.closeWith("no init message in " + timeout.String())
+			conn.Close()
+		})
+		defer timer.Stop()
 	}
-	if typ != websocket.TextMessage {
-		return "", fmt.Errorf("failed to authenticate websocket connection: invalid message type")
+	initLine, err := conn.readMessage()
+	if err != nil {
+		return init, fmt.Errorf("failed to authenticate websocket connection")
 	}
 
-	var init types.InitMessage
 	if json.Unmarshal(initLine, &init) != nil {
-		return "", fmt.Errorf("failed to authenticate websocket connection")
+		return init, fmt.Errorf("failed to authenticate websocket connection")
 	}
-	if server.options.Credential != "" && init.AuthToken != server.options.Credential {
-		return "", fmt.Errorf("failed to authenticate websocket connection")
+	if !server.validCredential(init.AuthToken) {
+		return init, fmt.Errorf("failed to authenticate websocket connection")
 	}
 
-	return init.Arguments, nil
+	return init, nil
+}
+
+// credentialHeader is the header of --credential of the API requests
+// running commands, the terminals send it with the init message
+const credentialHeader = "X-Auth-Token"
+
+// validCredential tells whether the token is --credential, any token is
+// valid without it
+// externalURL is the URL of the path under the base path returned to the
+// users, a path without --external-url, never built from the Host header
+func (server *Server) externalURL(path string) string {
+	return server.options.ExternalURL + server.options.BasePath + path
+}
+
+func (server *Server) validCredential(token string) bool {
+	credential := server.options.Credential
+	return credential == "" || subtle.ConstantTimeCompare([]byte(token), []byte(credential)) == 1
+}
+
+// execOptions returns the exec options of the query of the exec URL
+func execOptions(q url.Values) types.ExecOptions {
+	return types.ExecOptions{
+		Cmd:        q.Get("cmd"),
+		Env:        q.Get("env"),
+		User:       q.Get("user"),
+		Privileged: q.Get("p") != "",
+		NoTTY:      q.Get("tty") == "0",
+		// attach to the main process
+		Attach:      q.Get("attach") == "1",
+		AttachStdin: q.Get("stdin") == "1",
+	}
 }
 
 func parseQuery(arguments string) (url.Values, error) {
@@ -471,3 +759,54 @@ func parseQuery(arguments string) (url.Values, error) {
 	}
 	return refURL.Query(), nil
 }
+
+// parseLogOptions parses follow, tail (a number or "all"), since and until
+// (a duration like 10m ago, a RFC3339 time or unix seconds) and timestamps
+// of the query
+func parseLogOptions(id string, q url.Values, follow bool, tail string) (types.LogOptions, error) {
+	opts := types.LogOptions{
+		ID:         id,
+		Follow:     follow,
+		Tail:       tail,
+		Timestamps: q.Get("timestamps") == "1" || q.Get("timestamps") == "true",
+	}
+	if v := q.Get("follow"); v != "" {
+		opts.Follow = v == "1" || v == "true"
+	}
+	if v := q.Get("tail"); v != "" {
+		if n, err := strconv.Atoi(v); v != "all" && (err != nil || n < 0) {
+			return opts, fmt.Errorf("bad tail: %s", v)
+		}
+		opts.Tail = v
+	}
+	var err error
+	if opts.Since, err = parseLogTime(q.Get("since")); err != nil {
+		return opts, fmt.Errorf("bad since: %s", q.Get("since"))
+	}
+	if opts.Until, err = parseLogTime(q.Get("until")); err != nil {
+		return opts, fmt.Errorf("bad until: %s", q.Get("until"))
+	}
+	if !opts.Until.IsZero() && opts.Until.Before(opts.Since) {
+		return opts, fmt.Errorf("until is before since")
+	}
+	return opts, nil
+}
+
+// parseLogTime parses a duration ago, a RFC3339 time or unix seconds,
+// empty is the zero time
+func parseLogTime(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(v); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	sec, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(sec, 0), nil
+}
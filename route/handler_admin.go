@@ -0,0 +1,173 @@
+package route
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/wrfly/container-web-tty/types"
+)
+
+// isAdmin reports whether the request has the admin token, the session
+// cookie of it or the admin of the Authenticator. The bearer tokens are
+// tried under the login challenge as requireAdmin does, the requests
+// without them are not counted
+func (server *Server) isAdmin(c *gin.Context) bool {
+	if c.GetBool(adminAuthKey) {
+		return true
+	}
+	adminToken := server.settings().adminToken
+	if adminToken == "" {
+		return false
+	}
+	if server.cookies.valid(c, server.options.BasePath+"/", time.Now()) {
+		return true
+	}
+	auth := c.GetHeader("Authorization")
+	token := strings.TrimPrefix(auth, "Bearer ")
+	if token == auth {
+		return false
+	}
+	ip := c.ClientIP()
+	if server.logins != nil {
+		if challenge, ok := server.logins.check(ip, c.GetHeader(solutionHeader), time.Now()); !ok {
+			c.Header(challengeHeader, fmt.Sprintf("%d:%s", server.logins.bits, challenge))
+			return false
+		}
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 && !server.validToken(token) {
+		requestLog(c).Warnf("denied the admin token of %s", c.Request.URL.Path)
+		if server.logins != nil {
+			if challenge := server.logins.fail(ip, time.Now()); challenge != "" {
+				c.Header(challengeHeader, fmt.Sprintf("%d:%s", server.logins.bits, challenge))
+			}
+		}
+		return false
+	}
+	if server.logins != nil {
+		server.logins.succeed(ip)
+	}
+	return true
+}
+
+// requireAdmin rejects the requests without the admin token
+// (Authorization: Bearer <token>) of --admin-token or the session cookie
+// of it, the clients failing repeatedly solve a challenge before each try
+func (server *Server) requireAdmin(c *gin.Context) {
+	// an admin of the Authenticator of WithAuth
+	if c.GetBool(adminAuthKey) {
+		return
+	}
+	if server.settings().adminToken == "" {
+		apiError(c, http.StatusForbidden, "admin required")
+		return
+	}
+	if server.cookies.valid(c, server.options.BasePath+"/", time.Now()) {
+		return
+	}
+	ip := c.ClientIP()
+	if server.logins != nil {
+		if challenge, ok := server.logins.check(ip, c.GetHeader(solutionHeader), time.Now()); !ok {
+			c.Header(challengeHeader, fmt.Sprintf("%d:%s", server.logins.bits, challenge))
+			apiError(c, http.StatusUnauthorized, "too many failed logins, the challenge must be solved")
+			return
+		}
+	}
+
+	auth := c.GetHeader("Authorization")
+	token := strings.TrimPrefix(auth, "Bearer ")
+	if token == auth ||
+		subtle.ConstantTimeCompare([]byte(token), []byte(server.settings().adminToken)) != 1 &&
+			!server.validToken(token) {
+		requestLog(c).Warnf("denied the admin API %s", c.Request.URL.Path)
+		if server.logins != nil {
+			if challenge := server.logins.fail(ip, time.Now()); challenge != "" {
+				c.Header(challengeHeader, fmt.Sprintf("%d:%s", server.logins.bits, challenge))
+			}
+		}
+		c.Header("WWW-Authenticate", `Bearer realm="admin"`)
+		apiError(c, http.StatusUnauthorized, "admin token required")
+		return
+	}
+	if server.logins != nil {
+		server.logins.succeed(ip)
+	}
+}
+
+// handleConfigOptions lists the effective options of the flags of the last
+// load, the empty list if they're not set by the program embedding the
+// Server
+func (server *Server) handleConfigOptions(c *gin.Context) {
+	options := server.settings().effective
+	if options == nil {
+		options = []types.ConfigOption{}
+	}
+	c.JSON(http.StatusOK, options)
+}
+
+// handlePrune removes the unused resources of the kind, GET is a dry run
+// which reports the resources to be removed
+func (server *Server) handlePrune(c *gin.Context) {
+	kind := c.Param("kind")
+	valid := false
+	for _, k := range types.PruneKinds {
+		valid = valid || k == kind
+	}
+	if !valid {
+		apiError(c, http.StatusBadRequest, "bad kind: %s, should be one of %s",
+			kind, strings.Join(types.PruneKinds, ", "))
+		return
+	}
+
+	dryRun := c.Request.Method == http.MethodGet
+	report, err := server.containerCli.Prune(c.Request.Context(), kind, dryRun)
+	if err != nil {
+		apiError(c, http.StatusInternalServerError, "prune %s error: %s", kind, err)
+		return
+	}
+	if !dryRun {
+		requestLog(c).Infof("pruned %d %s, reclaimed %d bytes",
+			len(report.Items), kind, report.Reclaimed)
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// handleCreate creates and starts a container, the terminal of its
+// main process is attached at the returned URL
+func (server *Server) handleCreate(c *gin.Context) {
+	var opts types.CreateOptions
+	if err := c.ShouldBindJSON(&opts); err != nil {
+		apiError(c, http.StatusBadRequest, "bad request: %s", err)
+		return
+	}
+	if !validImage.MatchString(strings.ToLower(opts.Image)) {
+		apiError(c, http.StatusBadRequest, "bad image: %q", opts.Image)
+		return
+	}
+	if opts.Name != "" && !validContainerName.MatchString(opts.Name) {
+		apiError(c, http.StatusBadRequest, "bad name: %q", opts.Name)
+		return
+	}
+	for _, env := range opts.Env {
+		if strings.Index(env, "=") <= 0 {
+			apiError(c, http.StatusBadRequest, "bad env: %q, should be KEY=value", env)
+			return
+		}
+	}
+
+	id, err := server.containerCli.Create(c.Request.Context(), opts)
+	if err != nil {
+		apiError(c, http.StatusInternalServerError, "create container error: %s", err)
+		return
+	}
+	requestLog(c).Infof("created container %s of %s", id, opts.Image)
+	c.JSON(http.StatusOK, types.CreateResult{
+		ID:  id,
+		URL: server.execURL(c, id, url.Values{"attach": {"1"}, "stdin": {"1"}}),
+	})
+}
@@ -0,0 +1,398 @@
+package route
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/wrfly/container-web-tty/types"
+)
+
+const (
+	requestIDHeader = "X-Request-ID"
+	requestIDKey    = "request_id"
+	// the time the request is received
+	requestStartKey = "request_start"
+
+	defaultPageLimit = 100
+	maxPageLimit     = 1000
+)
+
+var validRequestID = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,64}$`)
+
+// requestID echoes the X-Request-ID of the request or generates one
+func requestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(requestStartKey, time.Now())
+		id := c.GetHeader(requestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id, _ = newSessionID()
+		}
+		c.Set(requestIDKey, id)
+		c.Header(requestIDHeader, id)
+		c.Next()
+	}
+}
+
+func apiError(c *gin.Context, code int, format string, args ...interface{}) {
+	// e.g. the bad request of a body too large
+	if body, ok := c.Request.Body.(*limitedBody); ok && body.exceeded {
+		code = http.StatusRequestEntityTooLarge
+	}
+	c.AbortWithStatusJSON(code, types.APIError{
+		Code:      code,
+		Message:   fmt.Sprintf(format, args...),
+		RequestID: c.GetString(requestIDKey),
+	})
+}
+
+// paginate returns the range [start, end) of the page of a list of n items,
+// the cursor is the key of the last item of the previous page, and is
+// looked up in the list, so the pages follow the changes of the list
+func paginate(c *gin.Context, n int, key func(i int) string) (start, end int, next string, err error) {
+	limit := defaultPageLimit
+	if l := c.Query("limit"); l != "" {
+		if limit, err = strconv.Atoi(l); err != nil || limit <= 0 {
+			return 0, 0, "", fmt.Errorf("bad limit %q", l)
+		}
+		if limit > maxPageLimit {
+			limit = maxPageLimit
+		}
+	}
+
+	if cursor := c.Query("cursor"); cursor != "" {
+		last, e := base64.RawURLEncoding.DecodeString(cursor)
+		if e != nil {
+			return 0, 0, "", fmt.Errorf("bad cursor %q", cursor)
+		}
+		start = -1
+		for i := 0; i < n; i++ {
+			if key(i) == string(last) {
+				start = i + 1
+				break
+			}
+		}
+		if start < 0 {
+			return 0, 0, "", fmt.Errorf("cursor %q is expired", cursor)
+		}
+	}
+
+	end = start + limit
+	if end >= n {
+		return start, n, "", nil
+	}
+	return start, end, base64.RawURLEncoding.EncodeToString([]byte(key(end - 1))), nil
+}
+
+// runTimeout parses the timeout of a run request,
+// the server's timeout is the upper limit
+func (server *Server) runTimeout(timeout string) (time.Duration, error) {
+	max := server.options.RunTimeout
+	if timeout == "" {
+		return max, nil
+	}
+	t, err := time.ParseDuration(timeout)
+	if err != nil {
+		return 0, err
+	}
+	if t <= 0 {
+		return 0, fmt.Errorf("%s is not positive", timeout)
+	}
+	if t < max {
+		return t, nil
+	}
+	return max, nil
+}
+
+// handleRunCommand runs a one-shot command without a tty and
+// returns the stdout, stderr and exit code
+func (server *Server) handleRunCommand(c *gin.Context) {
+	if server.rejectMaintenance(c) {
+		return
+	}
+	if !server.validCredential(c.GetHeader(credentialHeader)) {
+		apiError(c, http.StatusUnauthorized, "bad credential of %s", credentialHeader)
+		return
+	}
+	var opts types.RunOptions
+	if err := c.ShouldBindJSON(&opts); err != nil {
+		apiError(c, http.StatusBadRequest, "bad request: %s", err)
+		return
+	}
+	if opts.Cmd == "" {
+		apiError(c, http.StatusBadRequest, "empty command")
+		return
+	}
+
+	timeout, err := server.runTimeout(opts.Timeout)
+	if err != nil {
+		apiError(c, http.StatusBadRequest, "bad timeout: %s", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	container := server.containerCli.GetInfo(ctx, c.Param("id"))
+	if container.ID == "" {
+		apiError(c, http.StatusNotFound, "container %s not found", c.Param("id"))
+		return
+	}
+	if container.Shell == "" {
+		apiError(c, http.StatusBadRequest, "cannot find a valid shell in container %s", container.ID)
+		return
+	}
+	container.Exec = types.ExecOptions{
+		Cmd:   opts.Cmd,
+		Env:   opts.Env,
+		User:  opts.User,
+		NoTTY: true,
+	}
+
+	requestLog(c).Debugf("run [%s] in container [%s]", opts.Cmd, container.ID)
+	result, err := server.run(ctx, c.Request.RemoteAddr, container)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			apiError(c, http.StatusGatewayTimeout, "run command timeout (%s)", timeout)
+			return
+		}
+		apiError(c, http.StatusInternalServerError, "run command error: %s", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// handleListSessions lists the active and recently closed terminal sessions
+func (server *Server) handleListSessions(c *gin.Context) {
+	sessions, err := server.sessions.list()
+	if err != nil {
+		apiError(c, http.StatusInternalServerError, "read the closed sessions error: %s", err)
+		return
+	}
+	start, end, next, err := paginate(c, len(sessions), func(i int) string {
+		return sessions[i].ID
+	})
+	if err != nil {
+		apiError(c, http.StatusBadRequest, "%s", err)
+		return
+	}
+	c.JSON(http.StatusOK, types.Page{Items: sessions[start:end], NextCursor: next})
+}
+
+// handleListContainersAPI lists the containers of the backend,
+// ?refresh=1 refreshes the cache of the backend
+func (server *Server) handleListContainersAPI(c *gin.Context) {
+	containers := server.listContainers(c)
+	if containers == nil {
+		containers = []types.Container{}
+	}
+	start, end, next, err := paginate(c, len(containers), func(i int) string {
+		return containers[i].ID
+	})
+	if err != nil {
+		apiError(c, http.StatusBadRequest, "%s", err)
+		return
+	}
+	c.JSON(http.StatusOK, types.Page{Items: containers[start:end], NextCursor: next})
+}
+
+// handleLogsAPI streams the logs as plain text, all the logs without
+// following by default, see parseLogOptions for the query. With download=1
+// the logs are a file without following, gzip'ed with gzip=1
+func (server *Server) handleLogsAPI(c *gin.Context) {
+	ctx := c.Request.Context()
+	opts, err := parseLogOptions(c.Param("id"), c.Request.URL.Query(), false, "all")
+	if err != nil {
+		apiError(c, http.StatusBadRequest, "%s", err)
+		return
+	}
+	download := c.Query("download") == "1"
+	compress := c.Query("gzip") == "1"
+	if download {
+		opts.Follow = false
+	}
+	container := server.containerCli.GetInfo(ctx, opts.ID)
+	if container.ID == "" {
+		apiError(c, http.StatusNotFound, "container %s not found", opts.ID)
+		return
+	}
+	opts.ID = container.ID
+
+	rc, err := server.logs(ctx, opts)
+	if err != nil {
+		apiError(c, http.StatusInternalServerError, "get logs error: %s", err)
+		return
+	}
+	defer rc.Close()
+
+	var (
+		w     io.Writer = c.Writer
+		flush           = c.Writer.Flush
+	)
+	c.Header("Content-Type", "text/plain; charset=utf-8")
+	c.Header("X-Content-Type-Options", "nosniff")
+	if compress {
+		gw := gzip.NewWriter(c.Writer)
+		defer gw.Close()
+		w = gw
+		flush = func() {
+			gw.Flush()
+			c.Writer.Flush()
+		}
+		c.Header("Content-Type", "application/gzip")
+	}
+	if download {
+		name := logsFileName(container, server.inZone(time.Now()))
+		if compress {
+			name += ".gz"
+		}
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	}
+	c.Status(http.StatusOK)
+	buf := make([]byte, 32<<10)
+	for {
+		n, err := rc.Read(buf)
+		if n > 0 {
+			if _, err := w.Write(buf[:n]); err != nil {
+				return
+			}
+			// the downloads are flushed by the buffers of the writers
+			if !download {
+				flush()
+			}
+		}
+		if err != nil {
+			if err != io.EOF && ctx.Err() == nil {
+				log.Errorf("read logs of container %s error: %s", container.ID, err)
+			}
+			return
+		}
+	}
+}
+
+// validImage is the accepted references of the committed images
+var validImage = regexp.MustCompile(`^[a-z0-9][a-z0-9._/:-]{0,254}$`)
+
+// handleCommit commits the container to a new image
+func (server *Server) handleCommit(c *gin.Context) {
+	var opts types.CommitOptions
+	if err := c.ShouldBindJSON(&opts); err != nil {
+		apiError(c, http.StatusBadRequest, "bad request: %s", err)
+		return
+	}
+	if !validImage.MatchString(strings.ToLower(opts.Image)) {
+		apiError(c, http.StatusBadRequest, "bad image: %q", opts.Image)
+		return
+	}
+
+	cid := c.Param("id")
+	if opts.Comment == "" {
+		opts.Comment = fmt.Sprintf("committed from %s by %s", c.ClientIP(), server.hostname)
+	}
+	id, err := server.containerCli.Commit(c.Request.Context(), cid, opts)
+	if err != nil {
+		apiError(c, http.StatusInternalServerError, "commit container error: %s", err)
+		return
+	}
+	requestLog(c).Infof("committed container %s to %s (%s)", cid, opts.Image, id)
+	c.JSON(http.StatusOK, types.CommitResult{ID: id, Image: opts.Image})
+}
+
+// handleDebug launches a debug container sharing the namespaces of the
+// container, the terminal of it is attached at the returned URL
+func (server *Server) handleDebug(c *gin.Context) {
+	if server.rejectMaintenance(c) {
+		return
+	}
+	ctx := c.Request.Context()
+	container := server.containerCli.GetInfo(ctx, c.Param("id"))
+	if container.ID == "" {
+		apiError(c, http.StatusNotFound, "container %s not found", c.Param("id"))
+		return
+	}
+
+	image := server.options.DebugImage
+	id, err := server.containerCli.Debug(ctx, container.ID, types.DebugOptions{Image: image})
+	if err != nil {
+		apiError(c, http.StatusInternalServerError, "debug container error: %s", err)
+		return
+	}
+	requestLog(c).Infof("launched debug container %s (%s) for container %s",
+		id, image, container.ID)
+	c.JSON(http.StatusOK, types.DebugResult{
+		ID:  id,
+		URL: server.execURL(c, id, url.Values{"attach": {"1"}, "stdin": {"1"}}),
+	})
+}
+
+// validContainerName is the container names accepted by docker
+var validContainerName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]+$`)
+
+// handleRenameContainer renames the container to the name of the body
+func (server *Server) handleRenameContainer(c *gin.Context) {
+	var opts types.RenameOptions
+	if err := c.ShouldBindJSON(&opts); err != nil {
+		apiError(c, http.StatusBadRequest, "bad request: %s", err)
+		return
+	}
+	if !validContainerName.MatchString(opts.Name) {
+		apiError(c, http.StatusBadRequest, "bad container name: %q", opts.Name)
+		return
+	}
+
+	cid := c.Param("id")
+	requestLog(c).Debugf("going to rename container [%s] to [%s]", cid, opts.Name)
+	if err := server.containerCli.Rename(c.Request.Context(), cid, opts.Name); err != nil {
+		apiError(c, http.StatusInternalServerError, "%s", err)
+		return
+	}
+	c.JSON(http.StatusOK, types.ContainerActionMessage{
+		Message: fmt.Sprintf("rename container %.7s to %s successfully", cid, opts.Name),
+	})
+}
+
+// handleUpdateLabels sets and removes the labels of the container
+func (server *Server) handleUpdateLabels(c *gin.Context) {
+	var update types.LabelsUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		apiError(c, http.StatusBadRequest, "bad request: %s", err)
+		return
+	}
+	if len(update.Set) == 0 && len(update.Remove) == 0 {
+		apiError(c, http.StatusBadRequest, "no labels to set or remove")
+		return
+	}
+	for k := range update.Set {
+		if k == "" {
+			apiError(c, http.StatusBadRequest, "empty label key")
+			return
+		}
+	}
+	for _, k := range update.Remove {
+		if _, ok := update.Set[k]; ok || k == "" {
+			apiError(c, http.StatusBadRequest, "bad label to remove: %q", k)
+			return
+		}
+	}
+
+	cid := c.Param("id")
+	requestLog(c).Debugf("going to update labels of container [%s]: %+v", cid, update)
+	if err := server.containerCli.UpdateLabels(c.Request.Context(), cid, update); err != nil {
+		apiError(c, http.StatusInternalServerError, "%s", err)
+		return
+	}
+	c.JSON(http.StatusOK, types.ContainerActionMessage{
+		Message: fmt.Sprintf("update labels of container %.7s successfully", cid),
+	})
+}
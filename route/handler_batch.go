@@ -0,0 +1,190 @@
+package route
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/wrfly/container-web-tty/types"
+)
+
+// labelRequirement is a term of a label selector
+type labelRequirement struct {
+	key, value string
+	// "=", "!=", "exists" or "!exists"
+	op string
+}
+
+// parseSelector parses a label selector like "app=web,tier!=db,canary,!legacy"
+func parseSelector(selector string) ([]labelRequirement, error) {
+	var reqs []labelRequirement
+	for _, term := range strings.Split(selector, ",") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+
+		var r labelRequirement
+		switch {
+		case strings.Contains(term, "!="):
+			kv := strings.SplitN(term, "!=", 2)
+			r = labelRequirement{key: kv[0], value: kv[1], op: "!="}
+		case strings.Contains(term, "="):
+			kv := strings.SplitN(term, "=", 2)
+			r = labelRequirement{key: kv[0], value: kv[1], op: "="}
+		case strings.HasPrefix(term, "!"):
+			r = labelRequirement{key: term[1:], op: "!exists"}
+		default:
+			r = labelRequirement{key: term, op: "exists"}
+		}
+		r.key = strings.TrimSpace(r.key)
+		r.value = strings.TrimSpace(r.value)
+		if r.key == "" {
+			return nil, fmt.Errorf("bad selector term %q", term)
+		}
+		reqs = append(reqs, r)
+	}
+	if len(reqs) == 0 {
+		return nil, fmt.Errorf("empty selector")
+	}
+	return reqs, nil
+}
+
+func matchLabels(reqs []labelRequirement, labels map[string]string) bool {
+	for _, r := range reqs {
+		v, ok := labels[r.key]
+		switch r.op {
+		case "=":
+			if !ok || v != r.value {
+				return false
+			}
+		case "!=":
+			if ok && v == r.value {
+				return false
+			}
+		case "exists":
+			if !ok {
+				return false
+			}
+		case "!exists":
+			if ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// handleBatchRun runs a one-shot command in all the containers matching
+// the label selector, the results are in the order of the container list
+func (server *Server) handleBatchRun(c *gin.Context) {
+	if server.rejectMaintenance(c) {
+		return
+	}
+	if !server.validCredential(c.GetHeader(credentialHeader)) {
+		apiError(c, http.StatusUnauthorized, "bad credential of %s", credentialHeader)
+		return
+	}
+	var opts types.BatchRunOptions
+	if err := c.ShouldBindJSON(&opts); err != nil {
+		apiError(c, http.StatusBadRequest, "bad request: %s", err)
+		return
+	}
+	if opts.Cmd == "" {
+		apiError(c, http.StatusBadRequest, "empty command")
+		return
+	}
+	reqs, err := parseSelector(opts.Selector)
+	if err != nil {
+		apiError(c, http.StatusBadRequest, "bad selector: %s", err)
+		return
+	}
+	// the timeout is the deadline of the whole batch
+	timeout, err := server.runTimeout(opts.Timeout)
+	if err != nil {
+		apiError(c, http.StatusBadRequest, "bad timeout: %s", err)
+		return
+	}
+	concurrency := server.options.BatchConcurrency
+	if opts.Concurrency > 0 && (opts.Concurrency < concurrency || concurrency <= 0) {
+		concurrency = opts.Concurrency
+	}
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	matched := []types.Container{}
+	for _, container := range server.containerCli.List(ctx) {
+		if matchLabels(reqs, container.Labels) {
+			matched = append(matched, container)
+		}
+	}
+
+	requestLog(c).Debugf("run [%s] in %d containers of selector [%s]",
+		opts.Cmd, len(matched), opts.Selector)
+
+	results := make([]types.BatchResult, len(matched))
+	sem := make(chan struct{}, concurrency)
+	wg := sync.WaitGroup{}
+	client := c.Request.RemoteAddr
+	for i, container := range matched {
+		results[i] = types.BatchResult{
+			ID:        container.ID,
+			Name:      container.Name,
+			LocServer: container.LocServer,
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			results[i].Error = fmt.Sprintf("not started: %s", ctx.Err())
+			continue
+		}
+		wg.Add(1)
+		go func(result *types.BatchResult, id string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			server.batchRunOne(ctx, result, client, id, opts.RunOptions)
+		}(&results[i], container.ID)
+	}
+	wg.Wait()
+
+	c.JSON(http.StatusOK, results)
+}
+
+func (server *Server) batchRunOne(ctx context.Context, result *types.BatchResult, client, id string, opts types.RunOptions) {
+	container := server.containerCli.GetInfo(ctx, id)
+	if container.ID == "" {
+		result.Error = "container not found"
+		return
+	}
+	if container.Shell == "" {
+		result.Error = "cannot find a valid shell"
+		return
+	}
+	container.Exec = types.ExecOptions{
+		Cmd:   opts.Cmd,
+		Env:   opts.Env,
+		User:  opts.User,
+		NoTTY: true,
+	}
+
+	r, err := server.run(ctx, client, container)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			result.Error = "run command timeout"
+			return
+		}
+		result.Error = fmt.Sprintf("run command error: %s", err)
+		return
+	}
+	result.Result = &r
+}
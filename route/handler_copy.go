@@ -0,0 +1,87 @@
+package route
+
+import (
+	"io"
+	"net/http"
+	"path"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/wrfly/container-web-tty/types"
+)
+
+// byteCounter counts the bytes read through it
+type byteCounter struct {
+	io.Reader
+	n int64
+}
+
+func (c *byteCounter) Read(p []byte) (int, error) {
+	n, err := c.Reader.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// handleCopy copies a path from a container into a directory of another one,
+// the tar archive is streamed through the server
+func (server *Server) handleCopy(c *gin.Context) {
+	if !server.validCredential(c.GetHeader(credentialHeader)) {
+		apiError(c, http.StatusUnauthorized, "bad credential of %s", credentialHeader)
+		return
+	}
+	var opts types.CopyOptions
+	if err := c.ShouldBindJSON(&opts); err != nil {
+		apiError(c, http.StatusBadRequest, "bad request: %s", err)
+		return
+	}
+	if !path.IsAbs(opts.From.Path) || !path.IsAbs(opts.To.Path) {
+		apiError(c, http.StatusBadRequest, "the paths must be absolute")
+		return
+	}
+
+	for _, p := range []string{opts.From.Path, opts.To.Path} {
+		if server.deniedPath(p) {
+			apiError(c, http.StatusForbidden, "%s is denied", p)
+			return
+		}
+	}
+
+	ctx := c.Request.Context()
+	for _, id := range []string{opts.From.ID, opts.To.ID} {
+		if server.containerCli.GetInfo(ctx, id).ID == "" {
+			apiError(c, http.StatusNotFound, "container %s not found", id)
+			return
+		}
+	}
+
+	rc, err := server.containerCli.CopyFrom(ctx, opts.From.ID, opts.From.Path)
+	if err != nil {
+		apiError(c, http.StatusInternalServerError, "copy from container %.12s error: %s", opts.From.ID, err)
+		return
+	}
+	defer rc.Close()
+
+	// the entries are checked before they're sent to the container
+	content := &byteCounter{Reader: rc}
+	pr, pw := io.Pipe()
+	checked := make(chan error, 1)
+	go func() {
+		err := server.newTransferCheck().copyArchive(pw, content, path.Dir(opts.From.Path))
+		pw.CloseWithError(err)
+		checked <- err
+	}()
+	err = server.containerCli.CopyTo(ctx, opts.To.ID, opts.To.Path, pr)
+	pr.Close()
+	// the other errors of the archive fail the reading of the container
+	if te, ok := (<-checked).(*transferError); ok {
+		apiError(c, te.code, "copy from container %.12s refused: %s", opts.From.ID, te)
+		return
+	}
+	if err != nil {
+		apiError(c, http.StatusInternalServerError, "copy to container %.12s error: %s", opts.To.ID, err)
+		return
+	}
+	requestLog(c).Infof("copied %s of container %s to %s of container %s (%d bytes)",
+		opts.From.Path, opts.From.ID, opts.To.Path, opts.To.ID, content.n)
+	c.JSON(http.StatusOK, types.CopyResult{Bytes: content.n})
+}
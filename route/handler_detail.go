@@ -0,0 +1,110 @@
+package route
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/wrfly/container-web-tty/types"
+)
+
+// maskedValue replaces the values of the masked env
+const maskedValue = "******"
+
+// handleDetail returns the env, mounts and healthcheck of the container, the env
+// matching --mask-env are masked unless ?reveal=1 is allowed
+func (server *Server) handleDetail(c *gin.Context) {
+	reveal := c.Query("reveal") == "1"
+	if reveal && !server.options.RevealSecrets {
+		apiError(c, http.StatusForbidden, "revealing the secrets is not allowed")
+		return
+	}
+
+	cid := c.Param("id")
+	detail, err := server.containerCli.Inspect(c.Request.Context(), cid)
+	if err != nil {
+		apiError(c, http.StatusInternalServerError, "inspect container error: %s", err)
+		return
+	}
+	if reveal {
+		requestLog(c).Infof("revealed the env of container %s", cid)
+	}
+	maskEnv := server.settings().maskEnv
+	for i, env := range detail.Env {
+		if maskEnv != nil && maskEnv.MatchString(env.Name) {
+			detail.Env[i].Masked = true
+			if !reveal {
+				detail.Env[i].Value = maskedValue
+			}
+		}
+	}
+	c.JSON(http.StatusOK, detail)
+}
+
+func (server *Server) handleDetailPage(c *gin.Context) {
+	container := server.containerCli.GetInfo(c.Request.Context(), c.Param("id"))
+	if container.ID == "" {
+		c.String(http.StatusNotFound, "container %s not found", c.Param("id"))
+		return
+	}
+
+	buf := new(bytes.Buffer)
+	err := server.execTemplate(buf, "/detail.html", map[string]interface{}{
+		"title":     "Details of " + container.Name,
+		"tab":       "detail",
+		"container": container,
+		"id":        pathID(c, container.ID),
+		"reveal":    server.options.RevealSecrets,
+	})
+	if err != nil {
+		c.Error(err)
+	}
+	c.Writer.Write(buf.Bytes())
+}
+
+// handleHealthCheck runs the healthcheck command of the container on demand
+// by exec, the result is not recorded by the backend
+func (server *Server) handleHealthCheck(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), server.options.RunTimeout)
+	defer cancel()
+
+	container := server.containerCli.GetInfo(ctx, c.Param("id"))
+	if container.ID == "" {
+		apiError(c, http.StatusNotFound, "container %s not found", c.Param("id"))
+		return
+	}
+	detail, err := server.containerCli.Inspect(ctx, container.ID)
+	if err != nil {
+		apiError(c, http.StatusInternalServerError, "inspect container error: %s", err)
+		return
+	}
+	if detail.Health == nil || detail.Health.Cmd == "" {
+		apiError(c, http.StatusBadRequest, "container %s has no healthcheck command", container.ID)
+		return
+	}
+	if container.Shell == "" {
+		apiError(c, http.StatusBadRequest, "cannot find a valid shell in container %s", container.ID)
+		return
+	}
+	container.Exec = types.ExecOptions{Cmd: detail.Health.Cmd, NoTTY: true}
+
+	start := time.Now()
+	result, err := server.run(ctx, c.Request.RemoteAddr, container)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			apiError(c, http.StatusGatewayTimeout, "healthcheck timeout (%s)", server.options.RunTimeout)
+			return
+		}
+		apiError(c, http.StatusInternalServerError, "run healthcheck error: %s", err)
+		return
+	}
+	c.JSON(http.StatusOK, types.HealthProbe{
+		Start:    start,
+		End:      time.Now(),
+		ExitCode: result.ExitCode,
+		Output:   result.Stdout + result.Stderr,
+	})
+}
@@ -0,0 +1,50 @@
+package route
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/wrfly/container-web-tty/types"
+)
+
+// handleDiff lists the files changed by the container, ?kind=A,D
+// filters the kinds of the changes
+func (server *Server) handleDiff(c *gin.Context) {
+	diff, err := server.containerCli.Diff(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apiError(c, http.StatusInternalServerError, "get changes error: %s", err)
+		return
+	}
+
+	changes := []types.Change{}
+	kinds := strings.ToUpper(c.Query("kind"))
+	for _, change := range diff {
+		if kinds == "" || strings.Contains(kinds, change.Kind) {
+			changes = append(changes, change)
+		}
+	}
+	c.JSON(http.StatusOK, changes)
+}
+
+func (server *Server) handleDiffPage(c *gin.Context) {
+	container := server.containerCli.GetInfo(c.Request.Context(), c.Param("id"))
+	if container.ID == "" {
+		c.String(http.StatusNotFound, "container %s not found", c.Param("id"))
+		return
+	}
+
+	buf := new(bytes.Buffer)
+	err := server.execTemplate(buf, "/diff.html", map[string]interface{}{
+		"title":     "Changes of " + container.Name,
+		"tab":       "diff",
+		"container": container,
+		"id":        pathID(c, container.ID),
+	})
+	if err != nil {
+		c.Error(err)
+	}
+	c.Writer.Write(buf.Bytes())
+}
@@ -0,0 +1,123 @@
+package route
+
+import (
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	"github.com/wrfly/container-web-tty/types"
+)
+
+// eventFilter matches the events by the comma separated
+// actions and IDs, empty means all
+type eventFilter struct {
+	actions map[string]bool
+	ids     []string
+}
+
+func newEventFilter(actions, ids string) eventFilter {
+	f := eventFilter{actions: map[string]bool{}}
+	for _, a := range strings.Split(actions, ",") {
+		if a != "" {
+			f.actions[a] = true
+		}
+	}
+	for _, id := range strings.Split(ids, ",") {
+		if id != "" {
+			f.ids = append(f.ids, id)
+		}
+	}
+	return f
+}
+
+func (f eventFilter) match(e types.Event) bool {
+	if len(f.actions) != 0 && !f.actions[e.Action] {
+		return false
+	}
+	if len(f.ids) == 0 {
+		return true
+	}
+	for _, id := range f.ids {
+		// short IDs are fine
+		if strings.HasPrefix(e.ID, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// handleEvents streams the container events as Server-Sent Events,
+// or as JSON messages if it's a websocket request,
+// filtered by `?action=start,die&id=xxx`
+func (server *Server) handleEvents(c *gin.Context) {
+	filter := newEventFilter(c.Query("action"), c.Query("id"))
+	sub, cancel := server.events.Subscribe(50)
+	defer cancel()
+
+	if websocket.IsWebSocketUpgrade(c.Request) {
+		server.wsEvents(c, sub, filter)
+		return
+	}
+
+	requestLog(c).Debug("subscribes events")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	ping := time.NewTicker(time.Second * 30)
+	defer ping.Stop()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case e, ok := <-sub:
+			if !ok {
+				return false
+			}
+			if filter.match(e) {
+				c.SSEvent("container", e)
+			}
+		case <-ping.C:
+			// keep the connection alive through the proxies
+			w.Write([]byte(": ping\n\n"))
+		}
+		return true
+	})
+}
+
+func (server *Server) wsEvents(c *gin.Context, sub <-chan types.Event, filter eventFilter) {
+	conn, err := server.upgrade(c)
+	if err != nil {
+		log.Errorf("upgrade ws error: %s", err)
+		return
+	}
+	defer conn.Close()
+
+	// read until the client goes away
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case e, ok := <-sub:
+			if !ok {
+				conn.WriteMessage(websocket.CloseMessage, []byte("no events"))
+				return
+			}
+			if !filter.match(e) {
+				continue
+			}
+			if err := conn.WriteJSON(e); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package route
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/wrfly/container-web-tty/container"
+	"github.com/wrfly/container-web-tty/graphql"
+	"github.com/wrfly/container-web-tty/types"
+)
+
+// handleGraphQL serves the GraphQL queries of the containers:
+//
+//	type Query {
+//	  containers(selector: String, state: String): [Container]
+//	  container(id: String!): Container
+//	  pods(namespace: String): [Pod]
+//	}
+//	type Container {
+//	  id name image command state status shell ips: [String]
+//	  labels: [Label] label(key: String!): String
+//	  podName containerName namespace node locServer
+//	  pod: Pod
+//	}
+//	type Pod { name namespace node containers: [Container] }
+//	type Label { key value }
+func (server *Server) handleGraphQL(c *gin.Context) {
+	var req graphql.Request
+	if c.Request.Method == http.MethodGet {
+		req.Query = c.Query("query")
+		req.OperationName = c.Query("operationName")
+		if v := c.Query("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				apiError(c, http.StatusBadRequest, "bad variables: %s", err)
+				return
+			}
+		}
+	} else if err := c.ShouldBindJSON(&req); err != nil {
+		apiError(c, http.StatusBadRequest, "bad request: %s", err)
+		return
+	}
+	if req.Query == "" {
+		apiError(c, http.StatusBadRequest, "empty query")
+		return
+	}
+
+	resp := graphql.Execute(c.Request.Context(), &queryObject{cli: server.containerCli}, req)
+	c.JSON(http.StatusOK, resp)
+}
+
+// queryObject lists the containers once per query, the fields of the
+// containers and the pods resolve from the same list
+type queryObject struct {
+	cli        container.Cli
+	containers []types.Container
+	podList    []*podObject
+	podIndex   map[string]*podObject
+	listed     bool
+}
+
+func (q *queryObject) list(ctx context.Context) []types.Container {
+	if !q.listed {
+		q.containers = q.cli.List(ctx)
+		q.podList, q.podIndex = q.groupPods()
+		q.listed = true
+	}
+	return q.containers
+}
+
+func (q *queryObject) Resolve(ctx context.Context, field string, args map[string]interface{}) (interface{}, error) {
+	switch field {
+	case "containers":
+		selector, err := graphql.StringArg(args, "selector")
+		if err != nil {
+			return nil, err
+		}
+		state, err := graphql.StringArg(args, "state")
+		if err != nil {
+			return nil, err
+		}
+		var reqs []labelRequirement
+		if selector != "" {
+			if reqs, err = parseSelector(selector); err != nil {
+				return nil, err
+			}
+		}
+
+		list := []graphql.Object{}
+		for _, container := range q.list(ctx) {
+			if !matchLabels(reqs, container.Labels) {
+				continue
+			}
+			if state != "" && !strings.Contains(strings.ToLower(container.State), strings.ToLower(state)) {
+				continue
+			}
+			list = append(list, containerObject{container, q})
+		}
+		return list, nil
+
+	case "container":
+		id, err := graphql.StringArg(args, "id")
+		if err != nil {
+			return nil, err
+		}
+		if id == "" {
+			return nil, fmt.Errorf("argument \"id\" is required")
+		}
+		container := q.cli.GetInfo(ctx, id)
+		if container.ID == "" {
+			return nil, nil
+		}
+		return containerObject{container, q}, nil
+
+	case "pods":
+		namespace, err := graphql.StringArg(args, "namespace")
+		if err != nil {
+			return nil, err
+		}
+		list := []graphql.Object{}
+		for _, pod := range q.pods(ctx) {
+			if namespace == "" || pod.namespace == namespace {
+				list = append(list, pod)
+			}
+		}
+		return list, nil
+	}
+	return nil, fmt.Errorf("unknown field %q of Query", field)
+}
+
+// pods are the pods of the containers listed
+func (q *queryObject) pods(ctx context.Context) []*podObject {
+	q.list(ctx)
+	return q.podList
+}
+
+// groupPods groups the containers of the k8s backend by their pods
+func (q *queryObject) groupPods() ([]*podObject, map[string]*podObject) {
+	var pods []*podObject
+	index := map[string]*podObject{}
+	for _, container := range q.containers {
+		if container.PodName == "" {
+			continue
+		}
+		key := container.Namespace + "/" + container.PodName
+		pod, ok := index[key]
+		if !ok {
+			pod = &podObject{
+				name:      container.PodName,
+				namespace: container.Namespace,
+				node:      container.RunningNode,
+			}
+			index[key] = pod
+			pods = append(pods, pod)
+		}
+		pod.containers = append(pod.containers, containerObject{container, q})
+	}
+	return pods, index
+}
+
+type containerObject struct {
+	c types.Container
+	q *queryObject
+}
+
+func (o containerObject) Resolve(ctx context.Context, field string, args map[string]interface{}) (interface{}, error) {
+	c := o.c
+	switch field {
+	case "id":
+		return c.ID, nil
+	case "name":
+		return c.Name, nil
+	case "image":
+		return c.Image, nil
+	case "command":
+		return c.Command, nil
+	case "state":
+		return c.State, nil
+	case "status":
+		return c.Status, nil
+	case "shell":
+		return c.Shell, nil
+	case "ips":
+		return c.IPs, nil
+	case "podName":
+		return c.PodName, nil
+	case "containerName":
+		return c.ContainerName, nil
+	case "namespace":
+		return c.Namespace, nil
+	case "node":
+		return c.RunningNode, nil
+	case "locServer":
+		return c.LocServer, nil
+	case "labels":
+		keys := make([]string, 0, len(c.Labels))
+		for k := range c.Labels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		list := make([]graphql.Object, len(keys))
+		for i, k := range keys {
+			list[i] = labelObject{k, c.Labels[k]}
+		}
+		return list, nil
+	case "label":
+		key, err := graphql.StringArg(args, "key")
+		if err != nil {
+			return nil, err
+		}
+		if v, ok := c.Labels[key]; ok {
+			return v, nil
+		}
+		return nil, nil
+	case "pod":
+		if c.PodName == "" {
+			return nil, nil
+		}
+		o.q.list(ctx)
+		if pod, ok := o.q.podIndex[c.Namespace+"/"+c.PodName]; ok {
+			return pod, nil
+		}
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unknown field %q of Container", field)
+}
+
+type podObject struct {
+	name, namespace, node string
+	containers            []graphql.Object
+}
+
+func (o *podObject) Resolve(ctx context.Context, field string, args map[string]interface{}) (interface{}, error) {
+	switch field {
+	case "name":
+		return o.name, nil
+	case "namespace":
+		return o.namespace, nil
+	case "node":
+		return o.node, nil
+	case "containers":
+		return o.containers, nil
+	}
+	return nil, fmt.Errorf("unknown field %q of Pod", field)
+}
+
+type labelObject struct {
+	key, value string
+}
+
+func (o labelObject) Resolve(ctx context.Context, field string, args map[string]interface{}) (interface{}, error) {
+	switch field {
+	case "key":
+		return o.key, nil
+	case "value":
+		return o.value, nil
+	}
+	return nil, fmt.Errorf("unknown field %q of Label", field)
+}
@@ -0,0 +1,82 @@
+package route
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/wrfly/container-web-tty/route/asset"
+	"github.com/wrfly/container-web-tty/types"
+)
+
+// readyCheckTimeout limits the time of each readiness check
+const readyCheckTimeout = 3 * time.Second
+
+// readyChecks are the available checks of /readyz, see --ready-checks
+var readyChecks = map[string]func(server *Server, ctx context.Context) error{
+	"backend": func(server *Server, ctx context.Context) error {
+		return server.containerCli.Ping(ctx)
+	},
+	"assets": func(server *Server, ctx context.Context) error {
+		return checkAssets()
+	},
+	"storage": func(server *Server, ctx context.Context) error {
+		if server.store == nil {
+			return nil
+		}
+		return server.store.Ping()
+	},
+}
+
+// checkAssets verifies every embedded file can be uncompressed
+func checkAssets() error {
+	for _, f := range asset.List() {
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			continue
+		}
+		if int64(len(f.Bytes())) != info.Size() {
+			return fmt.Errorf("asset %s is corrupted", f.Name())
+		}
+	}
+	return nil
+}
+
+// handleHealthz is the liveness probe, the server is alive as long as it responds
+func (server *Server) handleHealthz(c *gin.Context) {
+	c.JSON(http.StatusOK, types.HealthStatus{Status: "ok"})
+}
+
+// handleReadyz is the readiness probe, it runs the checks of --ready-checks
+func (server *Server) handleReadyz(c *gin.Context) {
+	status := types.HealthStatus{
+		Status: "ok",
+		Checks: make(map[string]string, len(server.options.ReadyChecks)),
+	}
+	code := http.StatusOK
+	if server.draining() {
+		// no new sessions to this replica
+		status.Status = "fail"
+		status.Checks["drain"] = errDraining.Error()
+		code = http.StatusServiceUnavailable
+	}
+	for _, name := range server.options.ReadyChecks {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), readyCheckTimeout)
+		err := readyChecks[name](server, ctx)
+		cancel()
+		if err != nil {
+			status.Status = "fail"
+			status.Checks[name] = err.Error()
+			code = http.StatusServiceUnavailable
+			continue
+		}
+		status.Checks[name] = "ok"
+	}
+	c.JSON(code, status)
+}
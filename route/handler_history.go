@@ -0,0 +1,147 @@
+package route
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/wrfly/container-web-tty/audit"
+	"github.com/wrfly/container-web-tty/types"
+)
+
+// maxHistory is the max sessions of an exec history request
+const maxHistory = 1000
+
+// recordHistory appends the closed session to the exec history of the
+// storage, or of the audit dir without it
+func (server *Server) recordHistory(s types.Session) {
+	s.StartAt = server.inZone(s.StartAt)
+	if s.EndAt != nil {
+		end := server.inZone(*s.EndAt)
+		s.EndAt = &end
+	}
+	var err error
+	if server.store != nil {
+		bs, _ := json.Marshal(s)
+		err = server.store.Push(historyKey(s.ContainerID), string(bs), maxHistory)
+	} else {
+		err = audit.Record(server.options.AuditLogDir, s)
+	}
+	if err != nil {
+		log.Errorf("record exec history of container %s error: %s", s.ContainerID, err)
+	}
+}
+
+// historyKey is the exec history of the container in the storage, of the
+// short ID like the audit dir
+func historyKey(containerID string) string {
+	if len(containerID) > 12 {
+		containerID = containerID[:12]
+	}
+	return "container-web-tty:history:" + containerID
+}
+
+// history returns the last n sessions of the exec history of the
+// container, newest first
+func (server *Server) history(containerID string, n int) ([]types.Session, error) {
+	if server.store == nil {
+		return audit.History(server.options.AuditLogDir, containerID, n)
+	}
+	values, err := server.store.Latest(historyKey(containerID), n)
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]types.Session, 0, len(values))
+	for _, v := range values {
+		var s types.Session
+		if json.Unmarshal([]byte(v), &s) == nil {
+			sessions = append(sessions, s)
+		}
+	}
+	return sessions, nil
+}
+
+// run runs the one-shot command of the container, it's recorded
+// in the exec history if the audit is enabled
+func (server *Server) run(ctx context.Context, client string, container types.Container) (types.RunResult, error) {
+	start := time.Now()
+	result, err := server.containerCli.Run(ctx, container)
+	if !server.options.EnableAudit {
+		return result, err
+	}
+
+	id, _ := newSessionID()
+	end := time.Now()
+	s := types.Session{
+		ID:          id,
+		ContainerID: container.ID,
+		Client:      client,
+		Cmd:         container.Exec.Cmd,
+		User:        container.Exec.User,
+		StartAt:     start,
+		EndAt:       &end,
+		Reason:      "run",
+	}
+	if err != nil {
+		s.Reason = "run error: " + err.Error()
+	} else {
+		s.ExitCode = &result.ExitCode
+	}
+	server.recordHistory(s)
+	return result, err
+}
+
+// handleHistory lists the exec history of the container, the active sessions
+// first, followed by the last ?limit=100 closed sessions and commands
+func (server *Server) handleHistory(c *gin.Context) {
+	if !server.options.EnableAudit {
+		apiError(c, http.StatusNotFound, "the exec history needs --enable-audit")
+		return
+	}
+	limit := 100
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > maxHistory {
+			apiError(c, http.StatusBadRequest, "bad limit: %s, should be 1 to %d", v, maxHistory)
+			return
+		}
+		limit = n
+	}
+	container := server.containerCli.GetInfo(c.Request.Context(), c.Param("id"))
+	if container.ID == "" {
+		apiError(c, http.StatusNotFound, "container %s not found", c.Param("id"))
+		return
+	}
+
+	history, err := server.history(container.ID, limit)
+	if err != nil {
+		apiError(c, http.StatusInternalServerError, "read exec history error: %s", err)
+		return
+	}
+	c.JSON(http.StatusOK, append(server.sessions.activeOf(container.ID), history...))
+}
+
+func (server *Server) handleHistoryPage(c *gin.Context) {
+	container := server.containerCli.GetInfo(c.Request.Context(), c.Param("id"))
+	if container.ID == "" {
+		c.String(http.StatusNotFound, "container %s not found", c.Param("id"))
+		return
+	}
+
+	buf := new(bytes.Buffer)
+	err := server.execTemplate(buf, "/history.html", map[string]interface{}{
+		"title":     "Exec history of " + container.Name,
+		"tab":       "history",
+		"container": container,
+		"id":        pathID(c, container.ID),
+	})
+	if err != nil {
+		c.Error(err)
+	}
+	c.Writer.Write(buf.Bytes())
+}
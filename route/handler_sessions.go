@@ -0,0 +1,79 @@
+package route
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// killReason is the close reason of the sessions killed by the admin API
+const killReason = "killed by the admin"
+
+// runningSession returns the running terminal of the session, nil if it's
+// not running here
+func (server *Server) runningSession(id string) *drainSession {
+	server.drainMux.Lock()
+	defer server.drainMux.Unlock()
+	for s := range server.running {
+		if s.id == id {
+			return s
+		}
+	}
+	return nil
+}
+
+// detachedSession returns the detached session of the ID, nil if it's not
+// detached here
+func (server *Server) detachedSession(id string) *detachedSession {
+	server.dMux.Lock()
+	defer server.dMux.Unlock()
+	for _, d := range server.detached {
+		if d.sess.ID == id {
+			return d
+		}
+	}
+	return nil
+}
+
+// handleKillSession closes the running or the detached session of the ID
+func (server *Server) handleKillSession(c *gin.Context) {
+	id := c.Param("id")
+	if s := server.runningSession(id); s != nil {
+		// closed first, not by the reason of the canceled session
+		server.sessions.close(id, killReason)
+		s.cancel()
+	} else if d := server.detachedSession(id); d != nil && server.takeDetached(d.tty.token, d.container.ID) != nil {
+		d.tty.Exit()
+		server.sessions.close(id, killReason)
+	} else {
+		apiError(c, http.StatusNotFound, "session %s is not running", id)
+		return
+	}
+	requestLog(c).Warnf("killed the session %s", id)
+	c.Status(http.StatusNoContent)
+}
+
+// handleSessionReplay exports the recent output of the running or the
+// detached session, the bytes replayed to a resumed browser
+func (server *Server) handleSessionReplay(c *gin.Context) {
+	id := c.Param("id")
+	var replay *replayTTY
+	if s := server.runningSession(id); s != nil {
+		replay = s.replay
+	} else if d := server.detachedSession(id); d != nil {
+		replay = d.tty
+	} else {
+		apiError(c, http.StatusNotFound, "session %s is not running", id)
+		return
+	}
+	var output []byte
+	if replay != nil {
+		output = replay.replayed()
+	}
+	if output == nil {
+		apiError(c, http.StatusNotFound, "no replay of the session %s, it needs --resume-timeout and --replay-buffer", id)
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="`+id+`.replay"`)
+	c.Data(http.StatusOK, "application/octet-stream", output)
+}
@@ -0,0 +1,119 @@
+package route
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	"github.com/wrfly/container-web-tty/types"
+)
+
+// handleStats streams the resource usage of the container as Server-Sent
+// Events, or a websocket of JSON messages if it's a websocket upgrade,
+// ?stream=0 returns a single sample
+func (server *Server) handleStats(c *gin.Context) {
+	ctx := c.Request.Context()
+	container := server.containerCli.GetInfo(ctx, c.Param("id"))
+	if container.ID == "" {
+		apiError(c, http.StatusNotFound, "container %s not found", c.Param("id"))
+		return
+	}
+	stats, err := server.containerCli.Stats(ctx, container.ID)
+	if err != nil {
+		apiError(c, http.StatusInternalServerError, "get stats error: %s", err)
+		return
+	}
+
+	if c.Query("stream") == "0" {
+		s, ok := <-stats
+		if !ok {
+			apiError(c, http.StatusInternalServerError, "no stats of container %s", container.ID)
+			return
+		}
+		c.JSON(http.StatusOK, s)
+		return
+	}
+
+	if websocket.IsWebSocketUpgrade(c.Request) {
+		server.wsStats(c, stats)
+		return
+	}
+
+	requestLog(c).Debugf("subscribes stats of %s", container.ID)
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	ping := time.NewTicker(time.Second * 30)
+	defer ping.Stop()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case s, ok := <-stats:
+			if !ok {
+				return false
+			}
+			c.SSEvent("stats", s)
+		case <-ping.C:
+			// keep the connection alive through the proxies
+			w.Write([]byte(": ping\n\n"))
+		}
+		return true
+	})
+}
+
+func (server *Server) wsStats(c *gin.Context, stats <-chan types.Stats) {
+	conn, err := server.upgrade(c)
+	if err != nil {
+		log.Errorf("upgrade ws error: %s", err)
+		return
+	}
+	defer conn.Close()
+
+	// read until the client goes away
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case s, ok := <-stats:
+			if !ok {
+				conn.WriteMessage(websocket.CloseMessage, []byte("no stats"))
+				return
+			}
+			if err := conn.WriteJSON(s); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (server *Server) handleStatsPage(c *gin.Context) {
+	container := server.containerCli.GetInfo(c.Request.Context(), c.Param("id"))
+	if container.ID == "" {
+		c.String(http.StatusNotFound, "container %s not found", c.Param("id"))
+		return
+	}
+
+	buf := new(bytes.Buffer)
+	err := server.execTemplate(buf, "/stats.html", map[string]interface{}{
+		"title":     "Stats of " + container.Name,
+		"tab":       "stats",
+		"container": container,
+		"id":        pathID(c, container.ID),
+	})
+	if err != nil {
+		c.Error(err)
+	}
+	c.Writer.Write(buf.Bytes())
+}
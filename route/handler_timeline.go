@@ -0,0 +1,39 @@
+package route
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handleTimeline lists the restarts, OOM kills and health transitions
+// of the container seen since the server started, the newest first
+func (server *Server) handleTimeline(c *gin.Context) {
+	container := server.containerCli.GetInfo(c.Request.Context(), c.Param("id"))
+	if container.ID == "" {
+		apiError(c, http.StatusNotFound, "container %s not found", c.Param("id"))
+		return
+	}
+	c.JSON(http.StatusOK, server.events.Timeline(container.ID))
+}
+
+func (server *Server) handleTimelinePage(c *gin.Context) {
+	container := server.containerCli.GetInfo(c.Request.Context(), c.Param("id"))
+	if container.ID == "" {
+		c.String(http.StatusNotFound, "container %s not found", c.Param("id"))
+		return
+	}
+
+	buf := new(bytes.Buffer)
+	err := server.execTemplate(buf, "/timeline.html", map[string]interface{}{
+		"title":     "Timeline of " + container.Name,
+		"tab":       "timeline",
+		"container": container,
+		"id":        pathID(c, container.ID),
+	})
+	if err != nil {
+		c.Error(err)
+	}
+	c.Writer.Write(buf.Bytes())
+}
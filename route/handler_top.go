@@ -0,0 +1,92 @@
+package route
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/wrfly/container-web-tty/types"
+)
+
+// handleTop lists the processes of the container
+func (server *Server) handleTop(c *gin.Context) {
+	top, err := server.containerCli.Top(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apiError(c, http.StatusInternalServerError, "list processes error: %s", err)
+		return
+	}
+	if top.Processes == nil {
+		top.Processes = [][]string{}
+	}
+	c.JSON(http.StatusOK, top)
+}
+
+// handleKillProcess kills a process of the container by exec'ing kill,
+// the signal is TERM by default
+func (server *Server) handleKillProcess(c *gin.Context) {
+	pid, err := strconv.Atoi(c.Param("pid"))
+	if err != nil || pid <= 0 {
+		apiError(c, http.StatusBadRequest, "bad pid: %s", c.Param("pid"))
+		return
+	}
+	signal := strings.TrimPrefix(strings.ToUpper(c.DefaultQuery("signal", "TERM")), "SIG")
+	if !validSignal.MatchString(signal) {
+		apiError(c, http.StatusBadRequest, "bad signal: %s", signal)
+		return
+	}
+
+	ctx := c.Request.Context()
+	container := server.containerCli.GetInfo(ctx, c.Param("id"))
+	if container.ID == "" {
+		apiError(c, http.StatusNotFound, "container %s not found", c.Param("id"))
+		return
+	}
+	if container.Shell == "" {
+		apiError(c, http.StatusBadRequest, "cannot find a valid shell in container %s", container.ID)
+		return
+	}
+	container.Exec = types.ExecOptions{Cmd: fmt.Sprintf("kill -s %s %d", signal, pid)}
+
+	requestLog(c).Debugf("going to kill process %d of container [%s] with %s",
+		pid, container.ID, signal)
+	result, err := server.run(ctx, c.Request.RemoteAddr, container)
+	if err != nil {
+		apiError(c, http.StatusInternalServerError, "run kill error: %s", err)
+		return
+	}
+	if result.ExitCode != 0 {
+		apiError(c, http.StatusBadRequest, "kill process %d error: %s",
+			pid, strings.TrimSpace(result.Stderr))
+		return
+	}
+	c.JSON(http.StatusOK, types.ContainerActionMessage{
+		Message: fmt.Sprintf("kill process %d of container %.7s with %s successfully",
+			pid, container.ID, signal),
+	})
+}
+
+func (server *Server) handleTopPage(c *gin.Context) {
+	container := server.containerCli.GetInfo(c.Request.Context(), c.Param("id"))
+	if container.ID == "" {
+		c.String(http.StatusNotFound, "container %s not found", c.Param("id"))
+		return
+	}
+
+	ctl := server.options.Control
+	buf := new(bytes.Buffer)
+	err := server.execTemplate(buf, "/top.html", map[string]interface{}{
+		"title":     "Processes of " + container.Name,
+		"tab":       "top",
+		"container": container,
+		"id":        pathID(c, container.ID),
+		"kill":      ctl.Kill || ctl.All,
+	})
+	if err != nil {
+		c.Error(err)
+	}
+	c.Writer.Write(buf.Bytes())
+}
@@ -0,0 +1,328 @@
+package route
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/wrfly/container-web-tty/types"
+)
+
+// maxVolumeScan is the max tar entries scanned to list a directory
+const maxVolumeScan = 10000
+
+// volumeTarget resolves the ?volume= mount and the ?path= in it, the path
+// is cleaned to stay inside the volume
+func (server *Server) volumeTarget(c *gin.Context) (types.Container, types.Mount, string, bool) {
+	if ctl := server.options.Control; !ctl.Browse && !ctl.All {
+		apiError(c, http.StatusForbidden, "browsing volumes needs --control-browse")
+		return types.Container{}, types.Mount{}, "", false
+	}
+	ctx := c.Request.Context()
+	container := server.containerCli.GetInfo(ctx, c.Param("id"))
+	if container.ID == "" {
+		apiError(c, http.StatusNotFound, "container %s not found", c.Param("id"))
+		return container, types.Mount{}, "", false
+	}
+	detail, err := server.containerCli.Inspect(ctx, container.ID)
+	if err != nil {
+		apiError(c, http.StatusInternalServerError, "inspect container %.12s error: %s", container.ID, err)
+		return container, types.Mount{}, "", false
+	}
+	for _, mount := range detail.Mounts {
+		if mount.Destination == c.Query("volume") {
+			return container, mount, path.Clean("/" + c.Query("path")), true
+		}
+	}
+	apiError(c, http.StatusNotFound, "volume %q not found in container %.12s", c.Query("volume"), container.ID)
+	return container, types.Mount{}, "", false
+}
+
+// volumeEntries reads the tar archive of the whole volume, so the symlinks
+// are archived as they are and never followed out of it, fn gets the path
+// of the entries relative to the volume, it stops the scan by returning false
+func volumeEntries(tr *tar.Reader, fn func(rel string, hdr *tar.Header) bool) error {
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !fn(volumeRel(hdr.Name), hdr) {
+			return nil
+		}
+	}
+}
+
+// volumeRel returns the path of the tar entry relative to the volume,
+// the first element of the name is the base name of the volume
+func volumeRel(name string) string {
+	name = strings.TrimPrefix(strings.TrimSuffix(name, "/"), "./")
+	if i := strings.Index(name, "/"); i != -1 {
+		return path.Clean(name[i:])
+	}
+	return "/"
+}
+
+func volumeEntry(name string, hdr *tar.Header) types.VolumeEntry {
+	e := types.VolumeEntry{
+		Name:    name,
+		Type:    "file",
+		Size:    hdr.Size,
+		Mode:    hdr.FileInfo().Mode().String(),
+		ModTime: hdr.ModTime,
+	}
+	switch hdr.Typeflag {
+	case tar.TypeDir:
+		e.Type = "dir"
+		e.Size = 0
+	case tar.TypeSymlink:
+		e.Type = "symlink"
+		e.Link = hdr.Linkname
+	}
+	return e
+}
+
+// handleVolumes lists the mounts of the container
+func (server *Server) handleVolumes(c *gin.Context) {
+	if ctl := server.options.Control; !ctl.Browse && !ctl.All {
+		apiError(c, http.StatusForbidden, "browsing volumes needs --control-browse")
+		return
+	}
+	container := server.containerCli.GetInfo(c.Request.Context(), c.Param("id"))
+	if container.ID == "" {
+		apiError(c, http.StatusNotFound, "container %s not found", c.Param("id"))
+		return
+	}
+	detail, err := server.containerCli.Inspect(c.Request.Context(), container.ID)
+	if err != nil {
+		apiError(c, http.StatusInternalServerError, "inspect container %.12s error: %s", container.ID, err)
+		return
+	}
+	c.JSON(http.StatusOK, detail.Mounts)
+}
+
+// handleVolumeFiles lists the directory ?path=/ of the volume ?volume=/data
+func (server *Server) handleVolumeFiles(c *gin.Context) {
+	container, mount, dir, ok := server.volumeTarget(c)
+	if !ok {
+		return
+	}
+	rc, err := server.containerCli.CopyFrom(c.Request.Context(), container.ID, mount.Destination)
+	if err != nil {
+		apiError(c, http.StatusInternalServerError, "read volume %s error: %s", mount.Destination, err)
+		return
+	}
+	defer rc.Close()
+
+	listing := types.VolumeListing{
+		Volume:  mount.Destination,
+		Path:    dir,
+		Entries: []types.VolumeEntry{},
+	}
+	var (
+		found   = dir == "/"
+		isFile  bool
+		scanned int
+		entries = map[string]int{}
+	)
+	err = volumeEntries(tar.NewReader(rc), func(rel string, hdr *tar.Header) bool {
+		if scanned++; scanned > maxVolumeScan {
+			listing.Truncated = true
+			return false
+		}
+		if rel == dir {
+			found, isFile = true, hdr.Typeflag != tar.TypeDir
+			return !isFile
+		}
+		if dir != "/" && !strings.HasPrefix(rel, dir+"/") {
+			return true
+		}
+		// the direct child of the dir, or a file under it
+		child := strings.SplitN(strings.TrimPrefix(rel, strings.TrimSuffix(dir, "/")), "/", 3)[1]
+		i, ok := entries[child]
+		if !ok {
+			i = len(listing.Entries)
+			entries[child] = i
+			listing.Entries = append(listing.Entries, types.VolumeEntry{Name: child, Type: "dir"})
+		}
+		if rel == path.Join(dir, child) {
+			size := listing.Entries[i].Size
+			listing.Entries[i] = volumeEntry(child, hdr)
+			listing.Entries[i].Size += size
+		} else if hdr.FileInfo().Mode().IsRegular() {
+			listing.Entries[i].Size += hdr.Size
+		}
+		return true
+	})
+	if err != nil {
+		apiError(c, http.StatusInternalServerError, "read volume %s error: %s", mount.Destination, err)
+		return
+	}
+	if !found && !listing.Truncated {
+		apiError(c, http.StatusNotFound, "%s not found in volume %s", dir, mount.Destination)
+		return
+	}
+	if isFile {
+		apiError(c, http.StatusBadRequest, "%s is not a directory", dir)
+		return
+	}
+
+	sort.Slice(listing.Entries, func(i, j int) bool {
+		a, b := listing.Entries[i], listing.Entries[j]
+		if (a.Type == "dir") != (b.Type == "dir") {
+			return a.Type == "dir"
+		}
+		return a.Name < b.Name
+	})
+	c.JSON(http.StatusOK, listing)
+}
+
+// handleVolumeDownload downloads the file ?path= of the volume ?volume=,
+// or a tar archive if it's a directory
+func (server *Server) handleVolumeDownload(c *gin.Context) {
+	container, mount, target, ok := server.volumeTarget(c)
+	if !ok {
+		return
+	}
+	if server.deniedPath(path.Join(mount.Destination, target)) {
+		apiError(c, http.StatusForbidden, "%s of volume %s is denied", target, mount.Destination)
+		return
+	}
+	rc, err := server.containerCli.CopyFrom(c.Request.Context(), container.ID, mount.Destination)
+	if err != nil {
+		apiError(c, http.StatusInternalServerError, "read volume %s error: %s", mount.Destination, err)
+		return
+	}
+	defer rc.Close()
+
+	base := path.Base(target)
+	if target == "/" {
+		base = path.Base(mount.Destination)
+	}
+	inTarget := func(rel string) bool {
+		return target == "/" || rel == target || strings.HasPrefix(rel, target+"/")
+	}
+	rename := func(rel string) string {
+		if rel == target {
+			return base
+		}
+		return base + strings.TrimPrefix(rel, strings.TrimSuffix(target, "/"))
+	}
+	var (
+		tr      = tar.NewReader(rc)
+		tw      *tar.Writer
+		written int64
+		check   = server.newTransferCheck()
+		refused error
+	)
+	err = volumeEntries(tr, func(rel string, hdr *tar.Header) bool {
+		if !inTarget(rel) {
+			return true
+		}
+		p := path.Join(mount.Destination, rel)
+		if refused = check.entry(p, hdr); refused != nil {
+			return false
+		}
+		var content io.Reader = tr
+		if hasContent(hdr) {
+			if content, refused = check.content(p, tr); refused != nil {
+				return false
+			}
+		}
+		if tw == nil && rel == target {
+			switch {
+			case hdr.Typeflag == tar.TypeDir:
+			case hdr.FileInfo().Mode().IsRegular():
+				c.DataFromReader(http.StatusOK, hdr.Size, "application/octet-stream", content, map[string]string{
+					"Content-Disposition": fmt.Sprintf("attachment; filename=%q", base),
+				})
+				written = hdr.Size
+				return false
+			default:
+				apiError(c, http.StatusBadRequest, "%s is not a regular file or a directory", target)
+				return false
+			}
+		}
+		h := *hdr
+		h.Name = rename(rel)
+		if hdr.Typeflag == tar.TypeLink {
+			// the hard link to a file out of the directory is dropped
+			linkRel := volumeRel(hdr.Linkname)
+			if !inTarget(linkRel) {
+				return true
+			}
+			h.Linkname = rename(linkRel)
+		}
+		if tw == nil {
+			c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", base+".tar"))
+			c.Header("Content-Type", "application/x-tar")
+			tw = tar.NewWriter(c.Writer)
+		}
+		if hdr.Typeflag == tar.TypeDir {
+			h.Name += "/"
+		}
+		if err := tw.WriteHeader(&h); err != nil {
+			return false
+		}
+		n, _ := io.Copy(tw, content)
+		written += n
+		return true
+	})
+	if refused != nil {
+		// the archive being written is left broken
+		log.Errorf("download %s of volume %s of container %.12s refused: %s", target, mount.Destination, container.ID, refused)
+		if te, ok := refused.(*transferError); ok && !c.Writer.Written() {
+			apiError(c, te.code, "download %s of volume %s refused: %s", target, mount.Destination, te)
+		}
+		return
+	}
+	if tw != nil {
+		tw.Close()
+	}
+	if c.IsAborted() {
+		return
+	}
+	if err != nil {
+		log.Errorf("download %s of volume %s of container %.12s error: %s", target, mount.Destination, container.ID, err)
+		if !c.Writer.Written() {
+			apiError(c, http.StatusInternalServerError, "read volume %s error: %s", mount.Destination, err)
+		}
+		return
+	}
+	if !c.Writer.Written() {
+		apiError(c, http.StatusNotFound, "%s not found in volume %s", target, mount.Destination)
+		return
+	}
+	requestLog(c).Infof("downloaded %s of volume %s of container %s (%d bytes)",
+		target, mount.Destination, container.ID, written)
+}
+
+func (server *Server) handleVolumesPage(c *gin.Context) {
+	container := server.containerCli.GetInfo(c.Request.Context(), c.Param("id"))
+	if container.ID == "" {
+		c.String(http.StatusNotFound, "container %s not found", c.Param("id"))
+		return
+	}
+
+	buf := new(bytes.Buffer)
+	err := server.execTemplate(buf, "/volumes.html", map[string]interface{}{
+		"title":     "Volumes of " + container.Name,
+		"tab":       "volumes",
+		"container": container,
+		"id":        pathID(c, container.ID),
+	})
+	if err != nil {
+		c.Error(err)
+	}
+	c.Writer.Write(buf.Bytes())
+}
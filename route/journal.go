@@ -0,0 +1,114 @@
+package route
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/wrfly/container-web-tty/journal"
+	"github.com/wrfly/container-web-tty/types"
+)
+
+const (
+	// backendWatchInterval is the interval of pinging the backend to
+	// record its outages in the journal
+	backendWatchInterval = 30 * time.Second
+	// maxJournal is the max events of a journal request
+	maxJournal = 1000
+)
+
+// watchBackend records the outages of the backend in the journal until
+// the ctx is done, once when it's down and once when it's up again
+func (server *Server) watchBackend(ctx context.Context) {
+	ticker := time.NewTicker(backendWatchInterval)
+	defer ticker.Stop()
+	var downAt time.Time
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+		pctx, cancel := context.WithTimeout(ctx, readyCheckTimeout)
+		err := server.containerCli.Ping(pctx)
+		cancel()
+		switch {
+		case err != nil && ctx.Err() != nil:
+			return
+		case err != nil && downAt.IsZero():
+			downAt = time.Now()
+			server.journal.Log(journal.KindBackendDown, "the backend is down: "+err.Error(), nil)
+		case err == nil && !downAt.IsZero():
+			server.journal.Log(journal.KindBackendUp, fmt.Sprintf("the backend is up after %s",
+				time.Since(downAt).Round(time.Second)), nil)
+			downAt = time.Time{}
+		}
+	}
+}
+
+// journalSession records the summary of the closed session
+func (server *Server) journalSession(s types.Session) {
+	if server.journal == nil {
+		return
+	}
+	end := time.Now()
+	if s.EndAt != nil {
+		end = *s.EndAt
+	}
+	fields := map[string]string{
+		"session":   s.ID,
+		"container": s.ContainerID,
+		"client":    s.Client,
+		"duration":  end.Sub(s.StartAt).Round(time.Second).String(),
+		"bytes_in":  strconv.FormatInt(s.BytesIn, 10),
+		"bytes_out": strconv.FormatInt(s.BytesOut, 10),
+	}
+	if s.User != "" {
+		fields["user"] = s.User
+	}
+	if s.Cmd != "" {
+		fields["cmd"] = s.Cmd
+	}
+	if s.ExitCode != nil {
+		fields["exit_code"] = strconv.Itoa(*s.ExitCode)
+	}
+	server.journal.Log(journal.KindSession, fmt.Sprintf("session of container %s closed by %s",
+		s.ContainerID, s.Reason), fields)
+}
+
+// handleJournal lists the events of the journal, newest first, ?kind of
+// them, ?before the ID and ?limit=100
+func (server *Server) handleJournal(c *gin.Context) {
+	if server.journal == nil {
+		apiError(c, http.StatusNotFound, "the journal needs --journal-dir")
+		return
+	}
+	limit := 100
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > maxJournal {
+			apiError(c, http.StatusBadRequest, "bad limit: %s, should be 1 to %d", v, maxJournal)
+			return
+		}
+		limit = n
+	}
+	var before int64
+	if v := c.Query("before"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 {
+			apiError(c, http.StatusBadRequest, "bad before: %s", v)
+			return
+		}
+		before = n
+	}
+	events, err := server.journal.Query(c.Query("kind"), before, limit)
+	if err != nil {
+		apiError(c, http.StatusInternalServerError, "read the journal error: %s", err)
+		return
+	}
+	c.JSON(http.StatusOK, events)
+}
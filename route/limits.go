@@ -0,0 +1,126 @@
+package route
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// connLimiter limits the terminal connections of the server and of
+// every client IP, 0 is unlimited
+type connLimiter struct {
+	max      int
+	maxPerIP int
+
+	m     sync.Mutex
+	total int
+	perIP map[string]int
+}
+
+func newConnLimiter(max, maxPerIP int) *connLimiter {
+	return &connLimiter{
+		max:      max,
+		maxPerIP: maxPerIP,
+		perIP:    make(map[string]int),
+	}
+}
+
+// set changes the limits and returns the old ones, the connections
+// beyond the new ones are kept
+func (l *connLimiter) set(max, maxPerIP int) (int, int) {
+	l.m.Lock()
+	defer l.m.Unlock()
+	oldMax, oldMaxPerIP := l.max, l.maxPerIP
+	l.max, l.maxPerIP = max, maxPerIP
+	return oldMax, oldMaxPerIP
+}
+
+// acquire takes a connection of the ip, it returns the error
+// if any of the limits is reached
+func (l *connLimiter) acquire(ip string) error {
+	l.m.Lock()
+	defer l.m.Unlock()
+	if l.max > 0 && l.total >= l.max {
+		return fmt.Errorf("too many connections, the max is %d", l.max)
+	}
+	if l.maxPerIP > 0 && l.perIP[ip] >= l.maxPerIP {
+		return fmt.Errorf("too many connections of %s, the max is %d", ip, l.maxPerIP)
+	}
+	l.total++
+	l.perIP[ip]++
+	return nil
+}
+
+func (l *connLimiter) release(ip string) {
+	l.m.Lock()
+	defer l.m.Unlock()
+	l.total--
+	if l.perIP[ip]--; l.perIP[ip] <= 0 {
+		delete(l.perIP, ip)
+	}
+}
+
+// limitConnections rejects the terminal connections beyond --max-conn
+// and --max-conn-per-ip before they are upgraded
+func (server *Server) limitConnections(c *gin.Context) {
+	ip := c.ClientIP()
+	if err := server.limiter.acquire(ip); err != nil {
+		requestLog(c).Warnf("rejected: %s", err)
+		apiError(c, http.StatusTooManyRequests, err.Error())
+		return
+	}
+	defer server.limiter.release(ip)
+	c.Next()
+}
+
+// limitedBody is a request body of --max-body-size, the error of reading
+// beyond it is responded with 413 by apiError
+type limitedBody struct {
+	io.ReadCloser
+	max, read int64
+	exceeded  bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	// the error of http.MaxBytesReader after all the max bytes are read
+	if err != nil && err != io.EOF && b.read >= b.max {
+		b.exceeded = true
+	}
+	return n, err
+}
+
+// limitBody limits the request bodies to --max-body-size, the ones of
+// a larger Content-Length are refused at once, the forwarded requests
+// are of the servers of the containers and are not limited
+func (server *Server) limitBody(c *gin.Context) {
+	max := server.options.MaxBodySize
+	if max <= 0 || c.Request.Body == nil ||
+		strings.HasPrefix(c.Request.URL.Path, server.options.BasePath+"/forward/") {
+		return
+	}
+	if c.Request.ContentLength > max {
+		apiError(c, http.StatusRequestEntityTooLarge, "request body larger than %d bytes", max)
+		return
+	}
+	c.Request.Body = &limitedBody{ReadCloser: http.MaxBytesReader(c.Writer, c.Request.Body, max), max: max}
+}
+
+// upgrade upgrades the request to a websocket of the messages of
+// --max-ws-message, a larger one is closed with 1009 (message too big)
+func (server *Server) upgrade(c *gin.Context) (*websocket.Conn, error) {
+	conn, err := server.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return nil, err
+	}
+	if max := server.options.MaxWSMessage; max > 0 {
+		conn.SetReadLimit(max)
+	}
+	return conn, nil
+}
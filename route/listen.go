@@ -0,0 +1,373 @@
+package route
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+)
+
+// the routes served on a listener
+const (
+	routesAll = "all"
+	// all but the admin API and pages
+	routesPublic = "public"
+	// only the admin API and pages, and the assets of them
+	routesAdmin = "admin"
+)
+
+// the IP families of the TCP listeners, --ip-family
+const (
+	familyDual = "dual"
+	familyIPv4 = "ipv4"
+	familyIPv6 = "ipv6"
+)
+
+// bind is a network and an address to listen on
+type bind struct {
+	network string
+	address string
+}
+
+// bindAddrs resolves the hosts of --addr (use comma for split), the IPs,
+// the hostnames or the names of the interfaces (all the IPs of them), to
+// the addresses of the port to listen on of the family. A wildcard alone
+// listens on both the stacks unless a family is set, the IPs of a list
+// listen on their own stacks, e.g. 0.0.0.0,:: are two listeners.
+func bindAddrs(address string, port int, family string) ([]bind, error) {
+	var hosts []string
+	for _, h := range strings.Split(address, ",") {
+		h = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(h), "["), "]")
+		if net.ParseIP(h) == nil && h != "" && !strings.Contains(h, "%") {
+			if iface, err := net.InterfaceByName(h); err == nil {
+				ips, err := interfaceIPs(iface, family)
+				if err != nil {
+					return nil, err
+				}
+				hosts = append(hosts, ips...)
+				continue
+			}
+		}
+		hosts = append(hosts, h)
+	}
+
+	binds := make([]bind, 0, len(hosts))
+	for _, h := range hosts {
+		network, host, err := familyHost(h, family, len(hosts) == 1)
+		if err != nil {
+			return nil, err
+		}
+		binds = append(binds, bind{network, net.JoinHostPort(host, strconv.Itoa(port))})
+	}
+	return binds, nil
+}
+
+// interfaceIPs are the IPs of the interface of the family, with the zone of
+// the interface for the link-local ones
+func interfaceIPs(iface *net.Interface, family string) ([]string, error) {
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, fmt.Errorf("get the addresses of the interface %s error: %s", iface.Name, err)
+	}
+	var ips []string
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok {
+			continue
+		}
+		ip := ipNet.IP
+		if v4 := ip.To4() != nil; (v4 && family == familyIPv6) || (!v4 && family == familyIPv4) {
+			continue
+		}
+		if ip.To4() == nil && ip.IsLinkLocalUnicast() {
+			ips = append(ips, ip.String()+"%"+iface.Name)
+			continue
+		}
+		ips = append(ips, ip.String())
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("no address of the interface %s to listen on", iface.Name)
+	}
+	return ips, nil
+}
+
+// familyHost is the network and the host to listen on the host for the
+// family, the wildcard of the other family is the one of the family
+func familyHost(host, family string, alone bool) (string, string, error) {
+	ip := net.ParseIP(strings.SplitN(host, "%", 2)[0])
+	switch family {
+	case familyIPv4:
+		if ip != nil && ip.Equal(net.IPv6unspecified) {
+			host = "0.0.0.0"
+		} else if ip != nil && ip.To4() == nil {
+			return "", "", fmt.Errorf("the IPv6 address %s is disabled by the IP family ipv4", host)
+		}
+		return "tcp4", host, nil
+	case familyIPv6:
+		if ip != nil && ip.Equal(net.IPv4zero) {
+			host = "::"
+		} else if ip != nil && ip.To4() != nil {
+			return "", "", fmt.Errorf("the IPv4 address %s is disabled by the IP family ipv6", host)
+		}
+		return "tcp6", host, nil
+	}
+	switch {
+	case alone || ip == nil:
+		return "tcp", host, nil
+	case ip.To4() != nil:
+		return "tcp4", host, nil
+	}
+	return "tcp6", host, nil
+}
+
+// listener is a listener of the HTTP server and the routes served on it
+type listener struct {
+	net.Listener
+	routes string
+}
+
+// parseListen parses a listener of --listen, [routes@]address, the
+// address is host:port or unix:/path/of/socket
+func parseListen(s string) (routes, network, address string, err error) {
+	routes, address = routesAll, strings.TrimSpace(s)
+	if i := strings.Index(address, "@"); i >= 0 {
+		routes, address = address[:i], address[i+1:]
+	}
+	switch routes {
+	case routesAll, routesPublic, routesAdmin:
+	default:
+		return "", "", "", fmt.Errorf("bad routes %s of the listener %s, must be all, public or admin", routes, s)
+	}
+	if strings.HasPrefix(address, "unix:") {
+		if address = strings.TrimPrefix(address, "unix:"); address == "" {
+			return "", "", "", fmt.Errorf("no path of the unix socket %s", s)
+		}
+		return routes, "unix", address, nil
+	}
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		return "", "", "", fmt.Errorf("bad address of the listener %s: %s", s, err)
+	}
+	return routes, "tcp", address, nil
+}
+
+// listeners listens on the address and port (or the listener of the run
+// options instead) unless the port is disabled, on the ones of --listen
+// and on the admin address
+func (server *Server) listeners(opts *RunOptions) ([]listener, error) {
+	var listeners []listener
+	closeAll := func() {
+		for _, l := range listeners {
+			l.Close()
+		}
+	}
+	if ln := opts.listener; ln != nil {
+		listeners = append(listeners, listener{ln, routesAll})
+	} else if server.options.Port > 0 {
+		binds, err := bindAddrs(server.options.Address, server.options.Port, server.options.IPFamily)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range binds {
+			ln, err := net.Listen(b.network, b.address)
+			if err != nil {
+				closeAll()
+				return nil, err
+			}
+			listeners = append(listeners, listener{ln, routesAll})
+		}
+	}
+	for _, s := range server.options.Listen {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		routes, network, address, err := parseListen(s)
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		ln, err := server.listen(network, address)
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		listeners = append(listeners, listener{ln, routes})
+	}
+	if addr := server.options.AdminAddr; addr != "" {
+		// the others never serve the admin routes then
+		for i := range listeners {
+			if listeners[i].routes == routesAll {
+				listeners[i].routes = routesPublic
+			}
+		}
+		routes, network, address, err := parseListen(addr)
+		if err == nil && routes != routesAll {
+			err = fmt.Errorf("bad admin address %s, the routes of it are admin", addr)
+		}
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		ln, err := server.listen(network, address)
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		listeners = append(listeners, listener{ln, routesAdmin})
+	}
+	if len(listeners) == 0 {
+		return nil, fmt.Errorf("no listener of the HTTP server")
+	}
+	return listeners, nil
+}
+
+// listen listens on the address of --listen or --admin-addr, the TCP ones
+// of the IP family
+func (server *Server) listen(network, address string) (net.Listener, error) {
+	if network == "unix" {
+		return server.listenUnix(address)
+	}
+	host, port, _ := net.SplitHostPort(address)
+	network, host, err := familyHost(host, server.options.IPFamily, true)
+	if err != nil {
+		return nil, err
+	}
+	return net.Listen(network, net.JoinHostPort(host, port))
+}
+
+// listenUnix listens on the unix socket of the path, of the mode and the
+// owner of the options, a socket left by the last run is removed first
+func (server *Server) listenUnix(path string) (net.Listener, error) {
+	if info, err := os.Stat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if mode := server.options.SocketMode; mode != "" {
+		m, err := strconv.ParseUint(mode, 8, 32)
+		if err == nil {
+			err = os.Chmod(path, os.FileMode(m))
+		}
+		if err != nil {
+			ln.Close()
+			return nil, fmt.Errorf("bad mode %s of the socket %s: %s", mode, path, err)
+		}
+	}
+	if owner := server.options.SocketOwner; owner != "" {
+		uid, gid, err := lookupOwner(owner)
+		if err == nil {
+			err = os.Chown(path, uid, gid)
+		}
+		if err != nil {
+			ln.Close()
+			return nil, fmt.Errorf("bad owner %s of the socket %s: %s", owner, path, err)
+		}
+	}
+	return ln, nil
+}
+
+// lookupOwner returns the IDs of user[:group], by their names or IDs, -1
+// of the group if it's left out
+func lookupOwner(owner string) (int, int, error) {
+	parts := strings.SplitN(owner, ":", 2)
+	uid, gid := -1, -1
+	if parts[0] != "" {
+		u, err := user.Lookup(parts[0])
+		if err != nil {
+			if u, err = user.LookupId(parts[0]); err != nil {
+				return 0, 0, err
+			}
+		}
+		if uid, err = strconv.Atoi(u.Uid); err != nil {
+			return 0, 0, err
+		}
+	}
+	if len(parts) == 2 && parts[1] != "" {
+		g, err := user.LookupGroup(parts[1])
+		if err != nil {
+			if g, err = user.LookupGroupId(parts[1]); err != nil {
+				return 0, 0, err
+			}
+		}
+		if gid, err = strconv.Atoi(g.Gid); err != nil {
+			return 0, 0, err
+		}
+	}
+	return uid, gid, nil
+}
+
+// adminPath reports whether the path (under the base path) is of the
+// admin API and pages
+func adminPath(p string) bool {
+	return p == "/api/admin" || strings.HasPrefix(p, "/api/admin/") ||
+		p == "/admin.html" || p == "/run.html"
+}
+
+// sharedPath reports whether the path is of the assets and the probes
+// served on the admin listeners too
+func sharedPath(p string) bool {
+	switch p {
+	case "/config.js", "/auth_token.js", "/favicon.png", "/healthz", "/readyz":
+		return true
+	}
+	return strings.HasPrefix(p, "/js/") || strings.HasPrefix(p, "/css/")
+}
+
+// serveRoutes serves the routes of the listener of the network, the
+// others are not found. The admin listeners serve the metrics and the
+// profiles (/debug/vars and /debug/pprof/) too, the public ones never do.
+// The peers of the unix sockets are of 127.0.0.1, e.g. a proxy of the host
+// (see --trusted-proxies).
+func (server *Server) serveRoutes(h http.Handler, routes, network string) http.Handler {
+	if routes == routesAll && network != "unix" {
+		return h
+	}
+	debugMux := http.NewServeMux()
+	handlePprof(debugMux)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if network == "unix" {
+			r.RemoteAddr = "127.0.0.1:0"
+		}
+		// the profiles are not under the base path
+		debug := strings.HasPrefix(r.URL.Path, "/debug/")
+		p := strings.TrimPrefix(r.URL.Path, server.options.BasePath)
+		switch {
+		case routes == routesAdmin && debug:
+			debugMux.ServeHTTP(w, r)
+		case routes == routesPublic && (adminPath(p) || debug),
+			routes == routesAdmin && !adminPath(p) && !sharedPath(p):
+			http.NotFound(w, r)
+		default:
+			h.ServeHTTP(w, r)
+		}
+	})
+}
+
+// httpServers are the servers of the listeners
+type httpServers []*http.Server
+
+func (servers httpServers) Shutdown(ctx context.Context) error {
+	var err error
+	for _, srv := range servers {
+		if e := srv.Shutdown(ctx); e != nil {
+			err = e
+		}
+	}
+	return err
+}
+
+func (servers httpServers) Close() {
+	for _, srv := range servers {
+		srv.Close()
+	}
+}
@@ -0,0 +1,31 @@
+package route
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/wrfly/container-web-tty/logging"
+	"github.com/wrfly/container-web-tty/tracing"
+)
+
+var log = logging.Module("route")
+
+// requestLog is the logger of the request, with its ID, the client, and
+// the container and the user of it, at the debug level if any of them is
+// debugged
+func requestLog(c *gin.Context) *logrus.Entry {
+	fields := logrus.Fields{
+		"request_id": c.GetString(requestIDKey),
+		"client":     c.ClientIP(),
+	}
+	if id := c.Param("id"); id != "" {
+		fields["container"] = id
+	}
+	if user := c.Query("user"); user != "" {
+		fields["user"] = user
+	}
+	if span := tracing.FromContext(c.Request.Context()); span != nil {
+		fields["trace_id"] = span.TraceID()
+	}
+	return logging.Debugged(log.WithFields(fields))
+}
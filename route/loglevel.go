@@ -0,0 +1,80 @@
+package route
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/wrfly/container-web-tty/logging"
+	"github.com/wrfly/container-web-tty/types"
+)
+
+const (
+	defaultLogDebug = 10 * time.Minute
+	maxLogDebug     = 24 * time.Hour
+)
+
+// handleLogLevels reports the levels of the logs, PUT changes them until
+// the server restarts
+func (server *Server) handleLogLevels(c *gin.Context) {
+	if c.Request.Method == http.MethodPut {
+		var levels types.LogLevels
+		if err := c.ShouldBindJSON(&levels); err != nil {
+			apiError(c, http.StatusBadRequest, "bad request: %s", err)
+			return
+		}
+		if err := logging.SetLevels(levels.Level, levels.Modules); err != nil {
+			apiError(c, http.StatusBadRequest, "bad levels: %s, the modules are %s",
+				err, strings.Join(logging.ModuleNames(), ", "))
+			return
+		}
+		requestLog(c).Warnf("set the log level to %s, of the modules %v",
+			levels.Level, levels.Modules)
+	}
+	level, modules := logging.Levels()
+	c.JSON(http.StatusOK, types.LogLevels{Level: level, Modules: modules})
+}
+
+// handleLogDebug lists the debugged users, clients and containers, whose
+// requests and sessions are logged at the debug level, POST adds one
+func (server *Server) handleLogDebug(c *gin.Context) {
+	if c.Request.Method == http.MethodPost {
+		var debug types.LogDebug
+		if err := c.ShouldBindJSON(&debug); err != nil {
+			apiError(c, http.StatusBadRequest, "bad request: %s", err)
+			return
+		}
+		switch debug.Field {
+		case "user", "client", "container":
+		default:
+			apiError(c, http.StatusBadRequest, "bad field: %q, should be user, client or container", debug.Field)
+			return
+		}
+		if debug.Value == "" {
+			apiError(c, http.StatusBadRequest, "empty value of the %s", debug.Field)
+			return
+		}
+		d := defaultLogDebug
+		if debug.Duration != "" {
+			var err error
+			if d, err = time.ParseDuration(debug.Duration); err != nil || d <= 0 || d > maxLogDebug {
+				apiError(c, http.StatusBadRequest, "bad duration: %q, should be at most %s",
+					debug.Duration, maxLogDebug)
+				return
+			}
+		}
+		logging.Debug(debug.Field, debug.Value, time.Now().Add(d))
+		requestLog(c).Warnf("debug the logs of the %s %s for %s", debug.Field, debug.Value, d)
+	}
+
+	debugs := []types.LogDebug{}
+	for target, until := range logging.Debugs() {
+		parts := strings.SplitN(target, "=", 2)
+		debugs = append(debugs, types.LogDebug{Field: parts[0], Value: parts[1], Until: until})
+	}
+	sort.Slice(debugs, func(i, j int) bool { return debugs[i].Until.Before(debugs[j].Until) })
+	c.JSON(http.StatusOK, debugs)
+}
@@ -0,0 +1,209 @@
+package route
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/wrfly/container-web-tty/types"
+)
+
+// logs reads the logs of the backend, the backends cannot stop at
+// opts.Until, so the logs are read with timestamps and cut here
+func (server *Server) logs(ctx context.Context, opts types.LogOptions) (io.ReadCloser, error) {
+	if opts.Until.IsZero() {
+		rc, err := server.containerCli.Logs(ctx, opts)
+		if err != nil || !opts.Follow || server.options.LogsBuffer <= 0 {
+			return rc, err
+		}
+		return newDroppingLogs(rc, server.options.LogsBuffer), nil
+	}
+	strip := !opts.Timestamps
+	opts.Timestamps = true
+	rc, err := server.containerCli.Logs(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &untilLogs{rc: rc, r: bufio.NewReader(rc), until: opts.Until, strip: strip}, nil
+}
+
+// droppingLogs reads the followed logs ahead into a buffer of max bytes,
+// the oldest lines are dropped if the client can't keep up with a chatty
+// container, and a marker of the dropped lines is read in their place
+type droppingLogs struct {
+	rc  io.ReadCloser
+	max int
+
+	m       sync.Mutex
+	cond    *sync.Cond
+	lines   [][]byte
+	size    int
+	dropped int
+	err     error
+}
+
+func newDroppingLogs(rc io.ReadCloser, max int) *droppingLogs {
+	l := &droppingLogs{rc: rc, max: max}
+	l.cond = sync.NewCond(&l.m)
+	go l.readLoop()
+	return l
+}
+
+func (l *droppingLogs) readLoop() {
+	// the longer lines are split
+	r := bufio.NewReaderSize(l.rc, 32<<10)
+	for {
+		line, err := r.ReadSlice('\n')
+		if err == bufio.ErrBufferFull {
+			err = nil
+		}
+
+		l.m.Lock()
+		if len(line) > 0 {
+			l.lines = append(l.lines, append([]byte(nil), line...))
+			l.size += len(line)
+			for l.size > l.max && len(l.lines) > 1 {
+				l.size -= len(l.lines[0])
+				l.lines[0] = nil
+				l.lines = l.lines[1:]
+				l.dropped++
+			}
+		}
+		if err != nil && l.err == nil {
+			l.err = err
+		}
+		err = l.err
+		l.cond.Signal()
+		l.m.Unlock()
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Read reads the whole lines fitting in p, the marker of the dropped
+// lines goes before the ones after them
+func (l *droppingLogs) Read(p []byte) (int, error) {
+	l.m.Lock()
+	defer l.m.Unlock()
+	for len(l.lines) == 0 && l.dropped == 0 && l.err == nil {
+		l.cond.Wait()
+	}
+
+	n := 0
+	if l.dropped > 0 {
+		n = copy(p, fmt.Sprintf("\n[%d lines dropped, the client is too slow]\n", l.dropped))
+		l.dropped = 0
+	}
+	for len(l.lines) > 0 && n < len(p) {
+		line := l.lines[0]
+		if len(line) > len(p)-n {
+			if n > 0 {
+				break
+			}
+			// a line longer than p is read in pieces
+			m := copy(p, line)
+			l.lines[0] = line[m:]
+			l.size -= m
+			return m, nil
+		}
+		n += copy(p[n:], line)
+		l.size -= len(line)
+		l.lines[0] = nil
+		l.lines = l.lines[1:]
+	}
+	if n == 0 {
+		return 0, l.err
+	}
+	return n, nil
+}
+
+// Close stops reading the backend, the buffered lines are not read
+func (l *droppingLogs) Close() error {
+	l.m.Lock()
+	l.lines = nil
+	if l.err == nil {
+		l.err = io.ErrClosedPipe
+	}
+	l.cond.Broadcast()
+	l.m.Unlock()
+	return l.rc.Close()
+}
+
+// untilLogs reads the timestamped logs until the first line after
+// until, the timestamps are removed if strip is true
+type untilLogs struct {
+	rc    io.ReadCloser
+	r     *bufio.Reader
+	until time.Time
+	strip bool
+	line  []byte
+	err   error
+}
+
+func (l *untilLogs) Read(p []byte) (int, error) {
+	for len(l.line) == 0 {
+		if l.err != nil {
+			return 0, l.err
+		}
+		var line []byte
+		line, l.err = l.r.ReadBytes('\n')
+		// the lines without a timestamp are continued lines
+		if i := bytes.IndexByte(line, ' '); i > 0 {
+			if t, err := time.Parse(time.RFC3339Nano, string(line[:i])); err == nil {
+				if t.After(l.until) {
+					return 0, io.EOF
+				}
+				if l.strip {
+					line = line[i+1:]
+				}
+			}
+		}
+		l.line = line
+	}
+	n := copy(p, l.line)
+	l.line = l.line[n:]
+	return n, nil
+}
+
+func (l *untilLogs) Close() error { return l.rc.Close() }
+
+// logsFileName is the name of the downloaded logs of the container
+func logsFileName(c types.Container, now time.Time) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '.' {
+			return r
+		}
+		return '_'
+	}, strings.TrimPrefix(c.Name, "/"))
+	if name == "" {
+		name = c.ID
+		if len(name) > 12 {
+			name = name[:12]
+		}
+	}
+	return name + "-" + now.Format("20060102-150405") + ".log"
+}
+
+// handleLogsPage is the read-only logs viewer, the logs can be downloaded
+// in the time range of the viewer (since, until and timestamps)
+func (server *Server) handleLogsPage(c *gin.Context) {
+	cInfo := server.containerCli.GetInfo(c.Request.Context(), c.Param("id"))
+	q := url.Values{"download": []string{"1"}}
+	for _, key := range []string{"since", "until", "timestamps"} {
+		if v := c.Query(key); v != "" {
+			q.Set(key, v)
+		}
+	}
+	server.renderTerminalWith(c, cInfo, map[string]interface{}{
+		"download": server.options.BasePath + "/api/containers/" + pathID(c, c.Param("id")) + "/logs?" + q.Encode(),
+	})
+}
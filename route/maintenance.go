@@ -0,0 +1,88 @@
+package route
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/wrfly/container-web-tty/journal"
+	"github.com/wrfly/container-web-tty/types"
+)
+
+// defaultMaintenance is the message of the maintenance mode without one
+const defaultMaintenance = "the server is under maintenance, no new sessions"
+
+var errMaintenance = errors.New("the server is under maintenance")
+
+// maintenanceStatus reports the maintenance mode
+func (server *Server) maintenanceStatus() types.Maintenance {
+	server.maintenanceMux.Lock()
+	defer server.maintenanceMux.Unlock()
+	return server.maintenance
+}
+
+// maintenanceMessage is the message of the maintenance mode, empty if
+// it's off
+func (server *Server) maintenanceMessage() string {
+	server.maintenanceMux.Lock()
+	defer server.maintenanceMux.Unlock()
+	return server.maintenance.Message
+}
+
+// SetMaintenance turns the maintenance mode on or off, the new sessions
+// and commands are refused with the message (or the default one) while
+// it's on, the running sessions and the pages are left as they are
+func (server *Server) SetMaintenance(enabled bool, message string) types.Maintenance {
+	server.maintenanceMux.Lock()
+	was := server.maintenance
+	m := types.Maintenance{}
+	if enabled {
+		if message == "" {
+			message = defaultMaintenance
+		}
+		now := time.Now()
+		m = types.Maintenance{Enabled: true, Message: message, Since: &now}
+		if was.Enabled {
+			m.Since = was.Since
+		}
+	}
+	server.maintenance = m
+	server.maintenanceMux.Unlock()
+
+	switch {
+	case enabled:
+		log.Warnf("maintenance mode on: %s", message)
+		server.journal.Log(journal.KindMaintenance, "maintenance on: "+message, nil)
+	case was.Enabled:
+		log.Warn("maintenance mode off")
+		server.journal.Log(journal.KindMaintenance, "maintenance off", nil)
+	}
+	return m
+}
+
+// rejectMaintenance rejects the new sessions in the maintenance mode
+func (server *Server) rejectMaintenance(c *gin.Context) bool {
+	message := server.maintenanceMessage()
+	if message == "" {
+		return false
+	}
+	apiError(c, http.StatusServiceUnavailable, "%s", message)
+	return true
+}
+
+// handleMaintenance reports the maintenance mode, PUT turns it on or off
+func (server *Server) handleMaintenance(c *gin.Context) {
+	if c.Request.Method == http.MethodPut {
+		var m types.Maintenance
+		if err := c.ShouldBindJSON(&m); err != nil {
+			apiError(c, http.StatusBadRequest, "bad request: %s", err)
+			return
+		}
+		requestLog(c).Infof("set the maintenance mode %v", m.Enabled)
+		c.JSON(http.StatusOK, server.SetMaintenance(m.Enabled, m.Message))
+		return
+	}
+	c.JSON(http.StatusOK, server.maintenanceStatus())
+}
@@ -0,0 +1,189 @@
+package route
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/wrfly/container-web-tty/types"
+)
+
+// maxProvisionBuffer is the bytes of output kept of a provisioned
+// session before it's joined, the oldest bytes are dropped
+const maxProvisionBuffer = 1 << 20
+
+var errJoined = errors.New("session not found or already joined")
+
+// provisioned is an exec started by the API and waiting to be joined
+type provisioned struct {
+	container types.Container
+	tty       *replayTTY
+	sess      *types.Session
+	timer     *time.Timer
+}
+
+// takeProvisioned removes the provisioned session of the token, a session
+// can only be joined once, it returns nil if it's not found
+func (server *Server) takeProvisioned(token string) *provisioned {
+	server.pMux.Lock()
+	defer server.pMux.Unlock()
+	p, ok := server.provisions[token]
+	if !ok {
+		return nil
+	}
+	delete(server.provisions, token)
+	p.timer.Stop()
+	return p
+}
+
+func (server *Server) peekProvisioned(token string) *provisioned {
+	server.pMux.Lock()
+	defer server.pMux.Unlock()
+	return server.provisions[token]
+}
+
+// handleProvision starts an exec and returns the URL to join it later,
+// the output is buffered until then, it's an admin API
+func (server *Server) handleProvision(c *gin.Context) {
+	if server.rejectDraining(c) || server.rejectMaintenance(c) {
+		return
+	}
+	var opts types.ProvisionOptions
+	if err := c.ShouldBindJSON(&opts); err != nil {
+		apiError(c, http.StatusBadRequest, "bad request: %s", err)
+		return
+	}
+
+	ttl := server.options.ProvisionTTL
+	if opts.TTL != "" {
+		t, err := time.ParseDuration(opts.TTL)
+		if err != nil {
+			apiError(c, http.StatusBadRequest, "bad ttl: %s", err)
+			return
+		}
+		if t <= 0 {
+			apiError(c, http.StatusBadRequest, "bad ttl: %s is not positive", opts.TTL)
+			return
+		}
+		// the server's ttl is the upper limit
+		if t < ttl {
+			ttl = t
+		}
+	}
+
+	container := server.containerCli.GetInfo(c.Request.Context(), c.Param("id"))
+	if container.ID == "" {
+		apiError(c, http.StatusNotFound, "container %s not found", c.Param("id"))
+		return
+	}
+	if container.Shell == "" {
+		apiError(c, http.StatusBadRequest, "cannot find a valid shell in container %s", container.ID)
+		return
+	}
+	container.Exec = types.ExecOptions{
+		Cmd:  opts.Cmd,
+		Env:  opts.Env,
+		User: opts.User,
+	}
+
+	token, err := newSessionID()
+	if err != nil {
+		apiError(c, http.StatusInternalServerError, "%s", err)
+		return
+	}
+	sess := &types.Session{
+		ContainerID: container.ID,
+		Client:      c.Request.RemoteAddr,
+		Cmd:         opts.Cmd,
+		User:        opts.User,
+		StartAt:     time.Now(),
+	}
+	if sess.ID, err = newSessionID(); err != nil {
+		apiError(c, http.StatusInternalServerError, "%s", err)
+		return
+	}
+
+	// the exec outlives the request
+	ctx, cancel := context.WithCancel(context.Background())
+	containerTTY, err := server.containerCli.Exec(ctx, container)
+	if err != nil {
+		cancel()
+		apiError(c, http.StatusInternalServerError, "exec container error: %s", err)
+		return
+	}
+
+	tty, err := newReplayTTY(containerTTY, maxProvisionBuffer, cancel)
+	if err != nil {
+		containerTTY.Exit()
+		cancel()
+		apiError(c, http.StatusInternalServerError, "%s", err)
+		return
+	}
+	p := &provisioned{
+		container: container,
+		tty:       tty,
+		sess:      sess,
+	}
+	server.sessions.add(sess)
+	server.pMux.Lock()
+	server.provisions[token] = p
+	p.timer = time.AfterFunc(ttl, func() {
+		if server.takeProvisioned(token) == nil {
+			return // joined
+		}
+		log.Infof("provisioned session %s of container %s expired", sess.ID, container.ID)
+		p.tty.Exit()
+		server.sessions.close(sess.ID, "provision expired")
+	})
+	server.pMux.Unlock()
+
+	requestLog(c).Infof("provisioned session %s of container %s", sess.ID, container.ID)
+	c.JSON(http.StatusOK, types.ProvisionResult{
+		SessionID: sess.ID,
+		JoinURL:   server.externalURL("/join/" + token + "/"),
+		ExpireAt:  time.Now().Add(ttl),
+	})
+}
+
+func (server *Server) handleJoinPage(c *gin.Context) {
+	p := server.peekProvisioned(c.Param("token"))
+	if p == nil {
+		c.String(http.StatusNotFound, errJoined.Error())
+		return
+	}
+	server.renderTerminal(c, p.container)
+}
+
+func (server *Server) handleJoin(c *gin.Context, counter *counter) {
+	if server.rejectDraining(c) {
+		return
+	}
+	token := c.Param("token")
+	p := server.peekProvisioned(token)
+	if p == nil {
+		log.Errorf("join session error: %s", errJoined)
+		return
+	}
+
+	server.serveTTY(c, counter, p.container, func(ctx context.Context, conn master,
+		container *types.Container, sess *types.Session) (types.TTY, error) {
+		// authenticate before taking the session
+		if _, err := server.readInitMessage(conn); err != nil {
+			return nil, err
+		}
+		p := server.takeProvisioned(token)
+		if p == nil {
+			return nil, errJoined
+		}
+		*container = p.container
+		sess.ID = p.sess.ID
+		sess.Cmd = p.sess.Cmd
+		sess.User = p.sess.User
+		sess.StartAt = p.sess.StartAt
+		// the output before the join is replayed
+		return p.tty.attach(true), nil
+	})
+}
@@ -0,0 +1,105 @@
+package route
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// trustedProxies are the networks of the proxies in front, only their
+// X-Forwarded-For and X-Real-Ip are believed
+type trustedProxies []*net.IPNet
+
+// parseTrustedProxies parses the CIDRs, a single IP is a network of itself
+func parseTrustedProxies(cidrs []string) (trustedProxies, error) {
+	var proxies trustedProxies
+	for _, cidr := range cidrs {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+		if !strings.Contains(cidr, "/") {
+			ip := net.ParseIP(cidr)
+			if ip == nil {
+				return nil, fmt.Errorf("bad trusted proxy %s", cidr)
+			}
+			bits := 32
+			if ip.To4() == nil {
+				bits = 128
+			}
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("bad trusted proxy %s: %s", cidr, err)
+		}
+		proxies = append(proxies, network)
+	}
+	return proxies, nil
+}
+
+func (p trustedProxies) trusted(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, network := range p {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP returns the IP of the client of a request from the peer, the
+// forwarded addresses are walked from the nearest one while they're
+// of the trusted proxies, so a client can't fake the ones before them
+func (p trustedProxies) clientIP(r *http.Request) string {
+	peer, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil || !p.trusted(peer) {
+		return peer
+	}
+
+	var hops []string
+	for _, header := range r.Header["X-Forwarded-For"] {
+		for _, hop := range strings.Split(header, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+	if len(hops) == 0 {
+		if realIP := strings.TrimSpace(r.Header.Get("X-Real-Ip")); net.ParseIP(realIP) != nil {
+			return realIP
+		}
+		return peer
+	}
+	ip := peer
+	for i := len(hops) - 1; i >= 0; i-- {
+		if net.ParseIP(hops[i]) == nil {
+			break
+		}
+		ip = hops[i]
+		if !p.trusted(ip) {
+			break
+		}
+	}
+	return ip
+}
+
+// realClientIP sets the remote address of the requests to the one of
+// the client, so the logs, the limits and the audit see the client
+// instead of the proxies
+func (server *Server) realClientIP(c *gin.Context) {
+	if len(server.proxies) == 0 {
+		return
+	}
+	r := c.Request
+	if _, port, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		r.RemoteAddr = net.JoinHostPort(server.proxies.clientIP(r), port)
+	}
+}
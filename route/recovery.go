@@ -0,0 +1,50 @@
+package route
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/wrfly/container-web-tty/crash"
+	"github.com/wrfly/container-web-tty/types"
+)
+
+// recoverPanics recovers the panics of the handlers, which are logged and
+// reported by the crash package with the request but none of its secrets,
+// the headers other than the user agent are not reported
+func (server *Server) recoverPanics(c *gin.Context) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		if r == http.ErrAbortHandler {
+			// the response is aborted on purpose
+			panic(r)
+		}
+		stack := string(debug.Stack())
+		report := crash.Capture(types.ErrorReport{
+			Message:   fmt.Sprint(r),
+			Stack:     stack,
+			RequestID: c.GetString(requestIDKey),
+			Method:    c.Request.Method,
+			Path:      server.maskedPath(c.Request.URL.Path),
+			Client:    c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		})
+		requestLog(c).WithField("panic_id", report.ID).Errorf("panic: %v\n%s", r, stack)
+		if c.Writer.Written() {
+			c.Abort()
+			return
+		}
+		apiError(c, http.StatusInternalServerError, "internal error %s", report.ID)
+	}()
+	c.Next()
+}
+
+// handleErrors lists the recent panics for the admins
+func (server *Server) handleErrors(c *gin.Context) {
+	c.JSON(http.StatusOK, crash.Recent())
+}
@@ -0,0 +1,126 @@
+package route
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wrfly/container-web-tty/config"
+	"github.com/wrfly/container-web-tty/journal"
+	"github.com/wrfly/container-web-tty/types"
+)
+
+// reloadable are the options changed by Reload, read by the new requests
+// and sessions, the running sessions keep those they started with
+type reloadable struct {
+	adminToken string
+	idleTime   time.Duration
+	// env names masked in the container detail
+	maskEnv   *regexp.Regexp
+	denyPaths []string
+	denyMIME  []string
+	// shown on the list and the login pages, and written to the terminals
+	banner string
+	motd   string
+	// the effective options of the flags of the last load
+	effective []types.ConfigOption
+}
+
+func newReloadable(options config.ServerConfig) (*reloadable, error) {
+	r := &reloadable{
+		adminToken: options.AdminToken,
+		idleTime:   options.IdleTime,
+		denyPaths:  options.TransferDenyPaths,
+		denyMIME:   options.TransferDenyMIME,
+		banner:     options.Banner,
+		motd:       options.MOTD,
+	}
+	r.effective, _ = options.Effective.([]types.ConfigOption)
+	if options.MaskEnv != "" {
+		var err error
+		if r.maskEnv, err = regexp.Compile(options.MaskEnv); err != nil {
+			return nil, fmt.Errorf("failed to compile regular expression of masked env: %s", options.MaskEnv)
+		}
+	}
+	return r, nil
+}
+
+func (r *reloadable) maskEnvExpr() string {
+	if r.maskEnv == nil {
+		return ""
+	}
+	return r.maskEnv.String()
+}
+
+// settings returns the reloadable options of now
+func (server *Server) settings() *reloadable {
+	return server.current.Load().(*reloadable)
+}
+
+// Reload applies the reloadable options, the admin token (the browser
+// sessions of the old one are logged out, the admin API is refused
+// without it), the idle time, the masked env,
+// the denied paths and MIME types of the transfers, the banner, the MOTD
+// and the connection limits, and loads the certificate files of TLS again. They're applied
+// to the new requests and sessions without closing the running ones, the
+// other options are kept until the server restarts.
+func (server *Server) Reload(options config.ServerConfig) error {
+	r, err := newReloadable(options)
+	if err != nil {
+		return err
+	}
+	if server.certs != nil {
+		if err := server.certs.reloadNow(); err != nil {
+			return fmt.Errorf("reload the certificate error: %s", err)
+		}
+	}
+
+	old := server.settings()
+	changed := make(map[string]string)
+	if r.adminToken != old.adminToken {
+		server.cookies.bind(r.adminToken)
+		changed["admin_token"] = "<redacted>"
+	}
+	if r.idleTime != old.idleTime {
+		changed["idle_time"] = r.idleTime.String()
+	}
+	if options.MaskEnv != old.maskEnvExpr() {
+		changed["mask_env"] = options.MaskEnv
+	}
+	if strings.Join(r.denyPaths, ",") != strings.Join(old.denyPaths, ",") {
+		changed["transfer_deny_paths"] = strings.Join(r.denyPaths, ",")
+	}
+	if strings.Join(r.denyMIME, ",") != strings.Join(old.denyMIME, ",") {
+		changed["transfer_deny_mime"] = strings.Join(r.denyMIME, ",")
+	}
+	if r.banner != old.banner {
+		changed["banner"] = r.banner
+	}
+	if r.motd != old.motd {
+		changed["motd"] = r.motd
+	}
+	server.current.Store(r)
+
+	max, maxPerIP := server.limiter.set(options.MaxConnection, options.MaxConnectionPerIP)
+	if options.MaxConnection != max {
+		changed["max_conn"] = strconv.Itoa(options.MaxConnection)
+	}
+	if options.MaxConnectionPerIP != maxPerIP {
+		changed["max_conn_per_ip"] = strconv.Itoa(options.MaxConnectionPerIP)
+	}
+
+	names := make([]string, 0, len(changed))
+	for name := range changed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		names = append(names, "nothing")
+	}
+	log.Infof("reloaded the config, changed: %s", strings.Join(names, ", "))
+	server.journal.Log(journal.KindReload, "reloaded the config", changed)
+	return nil
+}
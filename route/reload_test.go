@@ -0,0 +1,65 @@
+package route
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wrfly/container-web-tty/config"
+	"github.com/wrfly/container-web-tty/container"
+	"github.com/wrfly/container-web-tty/event"
+	"github.com/wrfly/container-web-tty/types"
+)
+
+func TestReloadSettings(t *testing.T) {
+	server, err := New(container.Unimplemented{}, event.NewHub(), config.ServerConfig{
+		AdminToken: "old",
+		IdleTime:   time.Minute,
+		MaskEnv:    "PASSWORD",
+		Banner:     "hello",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := server.settings(); s.adminToken != "old" || s.idleTime != time.Minute ||
+		s.maskEnvExpr() != "PASSWORD" || s.banner != "hello" {
+		t.Fatalf("unexpected settings: %+v", s)
+	}
+
+	effective := []types.ConfigOption{{Name: "admin-token", Value: "<redacted>", Source: types.SourceFile}}
+	if err := server.Reload(config.ServerConfig{
+		AdminToken:        "new",
+		IdleTime:          time.Hour,
+		MaskEnv:           "TOKEN|SECRET",
+		TransferDenyPaths: []string{"/proc"},
+		TransferDenyMIME:  []string{"text/html"},
+		Banner:            "maintenance",
+		MOTD:              "welcome",
+		Effective:         effective,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	s := server.settings()
+	if s.adminToken != "new" || s.idleTime != time.Hour || s.maskEnvExpr() != "TOKEN|SECRET" ||
+		strings.Join(s.denyPaths, ",") != "/proc" || strings.Join(s.denyMIME, ",") != "text/html" ||
+		s.banner != "maintenance" || s.motd != "welcome" ||
+		len(s.effective) != 1 || s.effective[0] != effective[0] {
+		t.Fatalf("unexpected settings after the reload: %+v", s)
+	}
+
+	// a bad one is not applied
+	if err := server.Reload(config.ServerConfig{AdminToken: "bad", MaskEnv: "("}); err == nil {
+		t.Fatal("expect an error of the masked env")
+	}
+	if s := server.settings(); s.adminToken != "new" {
+		t.Fatalf("unexpected settings after a bad reload: %+v", s)
+	}
+
+	// cleared too, the admin token included
+	if err := server.Reload(config.ServerConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	if s := server.settings(); s.adminToken != "" || s.idleTime != 0 || s.maskEnv != nil {
+		t.Fatalf("unexpected settings after clearing them: %+v", s)
+	}
+}
@@ -0,0 +1,234 @@
+package route
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/wrfly/container-web-tty/types"
+)
+
+// maxPendingOutput is the bytes of output not read by the attached
+// connection yet, the tty is not read until there is room again
+const maxPendingOutput = 1 << 20
+
+// resumeReconnectDelay is the seconds a browser waits to reconnect
+const resumeReconnectDelay = 1
+
+var (
+	errDetached = errors.New("session detached")
+	errNoResume = errors.New("session detached or resumed by another connection")
+)
+
+// replayTTY reads the output of a tty in the background, the last bytes
+// are kept to be replayed when a connection attaches to it again
+type replayTTY struct {
+	types.TTY
+	cancel context.CancelFunc
+	// the secret browsers resume the session with
+	token string
+
+	m    sync.Mutex
+	cond *sync.Cond
+	tail *ringBuffer // the last bytes of the output, nil without replay
+	// output not read by the attached connection
+	pending []byte
+	err     error
+	// increased by every attach and detach, readers of old attachments fail
+	gen      int
+	attached bool
+	closed   bool
+}
+
+func newReplayTTY(tty types.TTY, size int, cancel context.CancelFunc) (*replayTTY, error) {
+	token, err := newSessionID()
+	if err != nil {
+		return nil, err
+	}
+	t := &replayTTY{TTY: tty, cancel: cancel, token: token}
+	if size > 0 {
+		t.tail = newRingBuffer(size)
+	}
+	t.cond = sync.NewCond(&t.m)
+	go t.readLoop()
+	return t, nil
+}
+
+func (t *replayTTY) readLoop() {
+	p := make([]byte, 32<<10)
+	for {
+		n, err := t.TTY.Read(p)
+
+		t.m.Lock()
+		if t.tail != nil {
+			t.tail.Write(p[:n])
+		}
+		if t.attached {
+			gen := t.gen
+			for t.gen == gen && !t.closed && len(t.pending) >= maxPendingOutput {
+				t.cond.Wait()
+			}
+			if t.gen == gen && t.attached {
+				t.pending = append(t.pending, p[:n]...)
+			}
+		}
+		if err != nil {
+			t.err = err
+		}
+		t.cond.Broadcast()
+		t.m.Unlock()
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+// attach returns the tty of a new connection, the tail of
+// the output is read first if replay is true
+func (t *replayTTY) attach(replay bool) types.TTY {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	t.gen++
+	t.attached = true
+	t.pending = nil
+	if replay && t.tail != nil {
+		t.pending = t.tail.Bytes()
+	}
+	t.cond.Broadcast()
+	return &attachedTTY{t, t.gen}
+}
+
+// detach keeps the tty running without a connection
+func (t *replayTTY) detach() {
+	t.m.Lock()
+	t.gen++
+	t.attached = false
+	t.pending = nil
+	t.cond.Broadcast()
+	t.m.Unlock()
+}
+
+// replayed returns the last bytes of the output, nil without replay
+func (t *replayTTY) replayed() []byte {
+	t.m.Lock()
+	defer t.m.Unlock()
+	if t.tail == nil {
+		return nil
+	}
+	return t.tail.Bytes()
+}
+
+// exited tells whether the output is closed
+func (t *replayTTY) exited() bool {
+	t.m.Lock()
+	defer t.m.Unlock()
+	return t.err != nil && len(t.pending) == 0
+}
+
+func (t *replayTTY) Exit() error {
+	t.m.Lock()
+	t.closed = true
+	if t.tail != nil {
+		t.tail.release()
+		t.tail = nil
+	}
+	t.cond.Broadcast()
+	t.m.Unlock()
+
+	err := t.TTY.Exit()
+	if t.cancel != nil {
+		t.cancel()
+	}
+	return err
+}
+
+// attachedTTY is the tty of a connection attached to a replayTTY
+type attachedTTY struct {
+	*replayTTY
+	gen int
+}
+
+func (a *attachedTTY) Read(p []byte) (int, error) {
+	t := a.replayTTY
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	for t.gen == a.gen && len(t.pending) == 0 && t.err == nil && !t.closed {
+		t.cond.Wait()
+	}
+	if t.gen != a.gen {
+		return 0, errNoResume
+	}
+	if len(t.pending) != 0 {
+		n := copy(p, t.pending)
+		t.pending = t.pending[n:]
+		t.cond.Broadcast()
+		return n, nil
+	}
+	if t.err != nil {
+		return 0, t.err
+	}
+	return 0, io.EOF
+}
+
+func (a *attachedTTY) ExitCode() (int, error) {
+	a.m.Lock()
+	gen := a.replayTTY.gen
+	a.m.Unlock()
+	if gen != a.gen {
+		return 0, errNoResume
+	}
+	return a.replayTTY.ExitCode()
+}
+
+// detachedSession is a session waiting for its connection to be resumed
+type detachedSession struct {
+	tty       *replayTTY
+	container types.Container
+	sess      types.Session
+	timer     *time.Timer
+}
+
+// detach keeps the session until it's resumed or ResumeTimeout passes
+func (server *Server) detach(t *replayTTY, container types.Container, sess types.Session) {
+	d := &detachedSession{
+		tty:       t,
+		container: container,
+		sess:      sess,
+	}
+	t.detach()
+
+	server.dMux.Lock()
+	server.detached[t.token] = d
+	d.timer = time.AfterFunc(server.options.ResumeTimeout, func() {
+		if server.takeDetached(t.token, container.ID) == nil {
+			return // resumed
+		}
+		log.Infof("detached session %s of container %s expired", sess.ID, container.ID)
+		t.Exit()
+		server.sessions.close(sess.ID, "detached timeout")
+	})
+	server.dMux.Unlock()
+
+	server.publishDetached(t.token, container.ID)
+}
+
+// takeDetached removes the detached session of the token and the container
+func (server *Server) takeDetached(token, containerID string) *detachedSession {
+	server.dMux.Lock()
+	d, ok := server.detached[token]
+	if !ok || d.container.ID != containerID {
+		server.dMux.Unlock()
+		return nil
+	}
+	delete(server.detached, token)
+	d.timer.Stop()
+	server.dMux.Unlock()
+
+	server.unpublishDetached(token)
+	return d
+}
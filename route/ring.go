@@ -0,0 +1,93 @@
+package route
+
+import (
+	"expvar"
+	"sync"
+)
+
+// replayMetrics are the replay buffers in use and the output evicted
+// from them, served in /debug/vars
+var replayMetrics = expvar.NewMap("replay")
+
+var (
+	ringPools   = make(map[int]*sync.Pool)
+	ringPoolMux sync.Mutex
+)
+
+// ringBuffer keeps the last bytes written to it in a fixed buffer, the
+// buffers of the same size are reused
+type ringBuffer struct {
+	buf   []byte
+	start int // the oldest byte
+	n     int
+}
+
+func newRingBuffer(size int) *ringBuffer {
+	ringPoolMux.Lock()
+	pool, ok := ringPools[size]
+	if !ok {
+		pool = &sync.Pool{New: func() interface{} {
+			buf := make([]byte, size)
+			return &buf
+		}}
+		ringPools[size] = pool
+	}
+	ringPoolMux.Unlock()
+
+	replayMetrics.Add("buffers", 1)
+	replayMetrics.Add("buffer_bytes", int64(size))
+	return &ringBuffer{buf: *pool.Get().(*[]byte)}
+}
+
+// Write keeps the last bytes of p, the oldest ones are evicted
+func (r *ringBuffer) Write(p []byte) {
+	size := len(r.buf)
+	if len(p) >= size {
+		r.evicted(r.n + len(p) - size)
+		copy(r.buf, p[len(p)-size:])
+		r.start, r.n = 0, size
+		return
+	}
+
+	end := (r.start + r.n) % size
+	copied := copy(r.buf[end:], p)
+	copy(r.buf, p[copied:])
+	if r.n += len(p); r.n > size {
+		r.evicted(r.n - size)
+		r.start = (r.start + r.n - size) % size
+		r.n = size
+	}
+}
+
+func (r *ringBuffer) evicted(n int) {
+	if n > 0 {
+		replayMetrics.Add("evictions", 1)
+		replayMetrics.Add("evicted_bytes", int64(n))
+	}
+}
+
+// Bytes returns a copy of the bytes kept, the oldest first
+func (r *ringBuffer) Bytes() []byte {
+	b := make([]byte, r.n)
+	end := r.start + r.n
+	if end > len(r.buf) {
+		end = len(r.buf)
+	}
+	copied := copy(b, r.buf[r.start:end])
+	copy(b[copied:], r.buf[:r.n-copied])
+	return b
+}
+
+// release returns the buffer to the pool, the ring is not used after it
+func (r *ringBuffer) release() {
+	size := len(r.buf)
+	ringPoolMux.Lock()
+	pool := ringPools[size]
+	ringPoolMux.Unlock()
+	buf := r.buf
+	pool.Put(&buf)
+	r.buf, r.start, r.n = nil, 0, 0
+
+	replayMetrics.Add("buffers", -1)
+	replayMetrics.Add("buffer_bytes", -int64(size))
+}
@@ -1,73 +1,131 @@
 package route
 
 import (
+	"compress/flate"
 	"context"
+	"expvar"
 	"fmt"
-	"html/template"
 	"net"
 	"net/http"
 	pprof "net/http/pprof"
 	"os"
 	"regexp"
+	"strings"
 	"sync"
-	noesctmpl "text/template"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
-	log "github.com/sirupsen/logrus"
-	"github.com/yudai/gotty/webtty"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/acme/autocert"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 
 	"github.com/wrfly/container-web-tty/config"
 	"github.com/wrfly/container-web-tty/container"
+	"github.com/wrfly/container-web-tty/event"
+	"github.com/wrfly/container-web-tty/journal"
 	"github.com/wrfly/container-web-tty/route/asset"
+	"github.com/wrfly/container-web-tty/store"
 	"github.com/wrfly/container-web-tty/types"
+	"github.com/wrfly/container-web-tty/webtty"
 )
 
 // Server provides a webtty HTTP endpoint.
 type Server struct {
 	options      config.ServerConfig
 	containerCli container.Cli
+	events       *event.Hub
 	upgrader     *websocket.Upgrader
 	srv          *http.Server
 	hostname     string
 
 	masters map[string]*types.ShareTTY
 	mMux    sync.RWMutex
-}
 
-var (
-	indexTemplate *template.Template
-	listTemplate  *template.Template
-	titleTemplate *noesctmpl.Template
-)
+	sseMasters map[string]*sseMaster
+	sseMux     sync.RWMutex
 
-func init() {
-	indexData, err := asset.Find("/index.html")
-	if err != nil {
-		log.Fatal(err)
-	}
-	indexTemplate = indexData.Template()
+	sessions *sessionRegistry
+	counter  *counter
+	limiter  *connLimiter
+	// the daily usage of the closed sessions
+	usage *usageRollup
+	// the events of the server, nil without --journal-dir
+	journal *journal.Journal
 
-	listIndexData, err := asset.Find("/list.html")
-	if err != nil {
-		log.Fatal(err)
-	}
-	listTemplate = listIndexData.Template()
+	// provisioned sessions by their join tokens
+	provisions map[string]*provisioned
+	pMux       sync.Mutex
 
-	titleFormat := "{{ .containerName }} - {{ printf \"%.8s\" .containerID }}@{{ .containerLoc }}"
-	titleTemplate, err = noesctmpl.New("title").Parse(titleFormat)
-	if err != nil {
-		log.Fatal(err)
-	}
+	// sessions waiting for their browsers to reconnect
+	detached map[string]*detachedSession
+	dMux     sync.Mutex
+	// the replicas of the detached sessions, nil without replicas
+	store store.Store
+	// the admin tokens created by the admin API, in the storage or the
+	// memory without it
+	tokens store.Store
+
+	// the running terminals, the deadline of the drain, zero if
+	// it's not draining, drained is closed once it's done
+	running       map[*drainSession]struct{}
+	drainDeadline time.Time
+	drainMux      sync.Mutex
+	drained       chan struct{}
+
+	// the maintenance mode, the new sessions are refused
+	maintenance    types.Maintenance
+	maintenanceMux sync.Mutex
+
+	// the options changed by Reload, *reloadable
+	current atomic.Value
+
+	// temporary URLs forwarded to the ports of the containers
+	forwards map[string]*forward
+	fMux     sync.Mutex
+	// the host of --forward-url, empty to serve the forwards on any
+	forwardHost string
+
+	assets *pageAssets
+	// the proxies whose forwarded client IPs are believed
+	proxies trustedProxies
+
+	// the certificate of TLS or the ACME manager, nil without them, and
+	// the profile of the TLS config
+	certs      *certReloader
+	acme       *autocert.Manager
+	tlsProfile string
+	// the tokens of the exec URLs, nil if they're not signed
+	signer *sessionURLs
+	// the failed admin logins, nil without the challenge
+	logins *loginGuard
+	// the admin sessions of the browsers
+	cookies *sessionCookies
+	// nil unless --access-log is set
+	access *accessLog
+	// nil unless --update-check is set
+	updates *updateChecker
+	// the location of the times written by the server and the zone of the
+	// pages, nil and empty for the browser-local time
+	zone     *time.Location
+	zoneName string
+	// the window title templates of the terminals
+	titles *titleTemplates
 }
 
 // New creates a new instance of Server.
 // Server will use the New() of the factory provided to handle each request.
-func New(containerCli container.Cli, options config.ServerConfig) (*Server, error) {
+// The container events of the hub are served in /api/events.
+func New(containerCli container.Cli, events *event.Hub, options config.ServerConfig) (*Server, error) {
 
-	var originChekcer func(r *http.Request) bool
-	if options.WSOrigin != "" {
+	// only the pages of the server upgrade by default
+	originChekcer := sameOrigin
+	switch {
+	case options.WSOrigin != "" && options.WSOriginAny:
+		return nil, fmt.Errorf("the Websocket Origin can't be both a regexp and any")
+	case options.WSOrigin != "":
 		matcher, err := regexp.Compile(options.WSOrigin)
 		if err != nil {
 			return nil, fmt.Errorf("failed to compile regular expression of Websocket Origin: %s", options.WSOrigin)
@@ -75,140 +133,621 @@ func New(containerCli container.Cli, options config.ServerConfig) (*Server, erro
 		originChekcer = func(r *http.Request) bool {
 			return matcher.MatchString(r.Header.Get("Origin"))
 		}
+	case options.WSOriginAny:
+		log.Warn("the websockets are upgraded from any origin")
+		originChekcer = func(r *http.Request) bool { return true }
+	}
+
+	if options.WSPingInterval > 0 && options.WSPingTimeout <= options.WSPingInterval {
+		return nil, fmt.Errorf("the ping timeout (%s) must be longer than the ping interval (%s)",
+			options.WSPingTimeout, options.WSPingInterval)
+	}
+
+	if options.WSCompression && (options.WSCompressionLevel < flate.BestSpeed ||
+		options.WSCompressionLevel > flate.BestCompression) {
+		return nil, fmt.Errorf("bad websocket compression level %d, must be 1 to 9",
+			options.WSCompressionLevel)
+	}
+
+	if options.EmbedOrigin != "" {
+		if _, err := regexp.Compile(options.EmbedOrigin); err != nil {
+			return nil, fmt.Errorf("failed to compile regular expression of embed origin: %s", options.EmbedOrigin)
+		}
+	}
+
+	for name, size := range map[string]int{
+		"websocket read buffer":  options.WSReadBufferSize,
+		"websocket write buffer": options.WSWriteBufferSize,
+		"relay buffer":           options.RelayBufferSize,
+		"coalesce size":          options.CoalesceSize,
+	} {
+		if size < 0 {
+			return nil, fmt.Errorf("bad %s size %d", name, size)
+		}
+	}
+
+	if options.Control.Create && options.AdminToken == "" {
+		return nil, fmt.Errorf("creating containers requires the admin token")
+	}
+
+	cookies, err := newSessionCookies(options.Cookie, options.AdminToken)
+	if err != nil {
+		return nil, err
+	}
+
+	var logins *loginGuard
+	if options.LoginChallengeAfter > 0 {
+		if options.LoginChallengeBits < 1 || options.LoginChallengeBits > 32 {
+			return nil, fmt.Errorf("bad login challenge bits %d, must be 1 to 32", options.LoginChallengeBits)
+		}
+		logins = newLoginGuard(options.LoginChallengeAfter, options.LoginChallengeBits)
+	}
+
+	checks := make([]string, len(options.ReadyChecks))
+	for i, check := range options.ReadyChecks {
+		if check == "redis" {
+			// the storage was only the redis
+			check = "storage"
+		}
+		checks[i] = check
+		if _, ok := readyChecks[check]; !ok {
+			return nil, fmt.Errorf("unknown ready check: %s", check)
+		}
+	}
+	options.ReadyChecks = checks
+
+	settings, err := newReloadable(options)
+	if err != nil {
+		return nil, err
+	}
+
+	// no trailing slash, empty for the root
+	options.BasePath = strings.TrimSuffix(options.BasePath, "/")
+	if options.BasePath != "" && !strings.HasPrefix(options.BasePath, "/") {
+		return nil, fmt.Errorf("bad base path %s, must start with /", options.BasePath)
+	}
+	options.ExternalURL = strings.TrimSuffix(options.ExternalURL, "/")
+	if _, err := parseOrigin(options.ExternalURL); err != nil {
+		return nil, fmt.Errorf("bad external URL %s, must be http(s)://host[:port]", options.ExternalURL)
+	}
+	options.ForwardURL = strings.TrimSuffix(options.ForwardURL, "/")
+	forwardHost, err := parseOrigin(options.ForwardURL)
+	if err != nil {
+		return nil, fmt.Errorf("bad forward URL %s, must be http(s)://host[:port]", options.ForwardURL)
+	}
+	switch options.IPFamily {
+	case "", familyDual, familyIPv4, familyIPv6:
+	default:
+		return nil, fmt.Errorf("bad IP family %s, must be dual, ipv4 or ipv6", options.IPFamily)
+	}
+	if err := checkClipboard(options); err != nil {
+		return nil, err
+	}
+	zone, zoneName, err := timezone(options.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	titles, err := newTitleTemplates(options.TitleFormat, map[string]string{
+		backendDocker: options.TitleFormatDocker,
+		backendKube:   options.TitleFormatKube,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verifyAssets(embeddedFiles(), asset.Sums); err != nil {
+		return nil, fmt.Errorf("bad embedded assets: %s", err)
+	}
+	assets, err := newPageAssets(options.DevAssets, options.StaticDir, options.BasePath)
+	if err != nil {
+		return nil, fmt.Errorf("bad assets: %s", err)
+	}
+
+	proxies, err := parseTrustedProxies(options.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.MaxWSMessage > 0 && options.MaxWSMessage <= int64(options.MaxInputFrame) {
+		return nil, fmt.Errorf("the max websocket message must be larger than the max input frame %d", options.MaxInputFrame)
+	}
+
+	profile, err := tlsProfile(options)
+	if err != nil {
+		return nil, err
+	}
+	var certs *certReloader
+	if (options.TLSCert == "") != (options.TLSKey == "") {
+		return nil, fmt.Errorf("TLS requires both the certificate and the key")
+	}
+	if options.TLSCert != "" {
+		if options.LetsEncrypt {
+			return nil, fmt.Errorf("the certificate can't be used with Let's Encrypt")
+		}
+		if certs, err = newCertReloader(options.TLSCert, options.TLSKey); err != nil {
+			return nil, err
+		}
+	}
+	var signer *sessionURLs
+	if options.SessionURLTTL > 0 {
+		if signer, err = newSessionURLs(options.SessionURLSecret, options.SessionURLTTL); err != nil {
+			return nil, err
+		}
+	}
+	var acme *autocert.Manager
+	if options.LetsEncrypt {
+		if acme, err = newACMEManager(options); err != nil {
+			return nil, err
+		}
+	}
+
+	var access *accessLog
+	if options.AccessLog.Path != "" {
+		if access, err = newAccessLog(options.AccessLog, options.BasePath); err != nil {
+			return nil, err
+		}
+	}
+	var serverJournal *journal.Journal
+	if options.JournalDir != "" {
+		if serverJournal, err = journal.Open(options.JournalDir, options.JournalMaxSize); err != nil {
+			return nil, fmt.Errorf("open the journal error: %s", err)
+		}
+	}
+	if certs != nil {
+		certs.journal = serverJournal
+	}
+	var updates *updateChecker
+	if options.UpdateCheck {
+		if options.Build.Version == "" {
+			log.Warn("the version of the build is unknown, the update check is disabled")
+		} else {
+			updates = newUpdateChecker(options.UpdateCheckURL, options.Build.Version)
+		}
+	}
+
+	// opened last, not left open by the errors above
+	state, err := openStorage(options)
+	if err != nil {
+		return nil, err
 	}
 
 	h, _ := os.Hostname()
-	return &Server{
+	server := &Server{
 		options:      options,
 		containerCli: containerCli,
+		events:       events,
 		masters:      make(map[string]*types.ShareTTY, 50),
+		sseMasters:   make(map[string]*sseMaster),
+		sessions:     newSessionRegistry(state),
+		usage:        newUsageRollup(),
+		provisions:   make(map[string]*provisioned),
+		detached:     make(map[string]*detachedSession),
+		forwards:     make(map[string]*forward),
+		counter:      newCounter(options.IdleTime),
 		hostname:     h,
+		store:        state,
+		tokens:       state,
+		running:      make(map[*drainSession]struct{}),
+		limiter:      newConnLimiter(options.MaxConnection, options.MaxConnectionPerIP),
+		drained:      make(chan struct{}),
+		assets:       assets,
+		proxies:      proxies,
+		certs:        certs,
+		acme:         acme,
+		signer:       signer,
+		logins:       logins,
+		cookies:      cookies,
+		access:       access,
+		journal:      serverJournal,
+		updates:      updates,
+		zone:         zone,
+		zoneName:     zoneName,
+		titles:       titles,
+		tlsProfile:   profile,
+		forwardHost:  forwardHost,
 
 		upgrader: &websocket.Upgrader{
-			ReadBufferSize:  1024,
-			WriteBufferSize: 1024,
-			Subprotocols:    webtty.Protocols,
-			CheckOrigin:     originChekcer,
+			ReadBufferSize:    options.WSReadBufferSize,
+			WriteBufferSize:   options.WSWriteBufferSize,
+			Subprotocols:      webtty.Protocols,
+			CheckOrigin:       originChekcer,
+			EnableCompression: options.WSCompression,
 		},
-	}, nil
+	}
+	server.current.Store(settings)
+	if server.tokens == nil {
+		server.tokens = store.NewMemory()
+	}
+	server.sessions.onClose = server.sessionClosed
+	return server, nil
 }
 
-// Run starts the main process of the Server.
-// The cancelation of ctx will shutdown the server immediately with aborting
-// existing connections. Use WithGracefulContext() to support graceful shutdown.
-func (server *Server) Run(ctx context.Context, options ...RunOption) error {
-	cctx, cancel := context.WithCancel(ctx)
-	defer cancel()
-
-	opts := &RunOptions{gracefulCtx: context.Background()}
+// Handler returns the HTTP handler of the Server, which is served by Run(),
+// the options of gin and the middleware are taken from the options.
+func (server *Server) Handler(options ...RunOption) http.Handler {
+	opts := &RunOptions{}
 	for _, opt := range options {
 		opt(opts)
 	}
-
-	router := gin.New()
-	router.Use(gin.Recovery())
-	if gin.Mode() == gin.DebugMode {
-		router.Use(gin.Logger())
+	if opts.releaseMode {
+		gin.SetMode(gin.ReleaseMode)
+	}
+	engine := gin.New()
+	// the client IPs are of the trusted proxies only
+	engine.ForwardedByClientIP = false
+	engine.Use(server.recoverPanics, server.realClientIP, requestID(), server.logRequests, server.traceRequests, server.limitBody,
+		csrfToken(server.options.BasePath+"/", server.cookies.secure), server.securityHeaders(), server.forwardOrigin)
+	if gin.Mode() == gin.DebugMode && !opts.noLogger {
+		engine.Use(gin.Logger())
+	}
+	if opts.auth != nil {
+		engine.Use(authenticate(opts.auth))
 	}
+	engine.Use(opts.middleware...)
+	// all the routes are under the base path
+	router := engine.Group(server.options.BasePath)
 
 	// Routes
 	router.GET("/", server.handleListContainers)
 	router.GET("/auth_token.js", server.handleAuthToken)
 	router.GET("/config.js", server.handleConfig)
+	router.GET("/healthz", server.handleHealthz)
+	router.GET("/readyz", server.handleReadyz)
 
-	h := gin.WrapF(asset.Handler)
+	h := gin.WrapH(http.StripPrefix(server.options.BasePath, http.HandlerFunc(asset.Handler)))
 	for _, f := range asset.List() {
-		if f.Name() != "/" {
+		if stat, _ := f.Stat(); f.Name() != "/" && stat.IsDir() {
 			router.GET(f.Name(), h)
 		}
 	}
+	// the new files of the dev dir need a restart to be routed
+	assets, err := server.assets.load()
+	if err != nil {
+		assets = staticAssets
+	}
+	for name := range assets {
+		if name == setupPage {
+			// served by the setup only
+			continue
+		}
+		router.GET(name, server.handleStatic)
+	}
 
 	// exec
-	counter := newCounter(server.options.IdleTime)
-	router.GET("/exec/:id/", server.terminalPage)
-	router.GET("/exec/:id/"+"ws", func(c *gin.Context) { server.handleExec(c, counter) })
+	counter := server.counter
+	limit := server.limitConnections
+	// the tokens of the containers of the paths if the URLs are signed
+	sealed, shared := server.openContainerID(true), server.openContainerID(false)
+	router.GET("/exec/:id/", server.execPage)
+	router.GET("/exec/:id/"+"ws", limit, func(c *gin.Context) { server.handleExec(c, counter) })
+	router.GET("/exec/:id/"+"sse", limit, func(c *gin.Context) { server.handleExec(c, counter) })
+	router.POST("/exec/:id/sse/:sid", server.handleSSEInput)
+
+	// join the provisioned sessions
+	router.GET("/join/:token/", server.handleJoinPage)
+	router.GET("/join/:token/ws", limit, func(c *gin.Context) { server.handleJoin(c, counter) })
+	router.GET("/join/:token/sse", limit, func(c *gin.Context) { server.handleJoin(c, counter) })
+	router.POST("/join/:token/sse/:sid", server.handleSSEInput)
 
 	if server.options.EnableShare {
 		// share screen
-		router.GET("/share/:id/", server.terminalPage)
-		router.GET("/share/:id/ws", func(c *gin.Context) { server.handleShare(c) })
+		router.GET("/share/:id/", shared, server.terminalPage)
+		router.GET("/share/:id/ws", shared, limit, func(c *gin.Context) { server.handleShare(c) })
+		router.GET("/share/:id/sse", shared, limit, func(c *gin.Context) { server.handleShare(c) })
+		router.POST("/share/:id/sse/:sid", server.handleSSEInput)
 	}
 
 	// logs
-	router.GET("/logs/:id/", server.terminalPage)
-	router.GET("/logs/:id/"+"ws", func(c *gin.Context) { server.handleLogs(c) })
+	router.GET("/logs/:id/", sealed, server.terminalPage)
+	router.GET("/logs/:id/"+"ws", sealed, limit, func(c *gin.Context) { server.handleLogs(c, true) })
+	router.GET("/logs/:id/"+"sse", sealed, limit, func(c *gin.Context) { server.handleLogs(c, true) })
+	router.POST("/logs/:id/sse/:sid", server.handleSSEInput)
+	// read-only logs viewer
+	router.GET("/c/:id/logs/", sealed, server.handleLogsPage)
+	router.GET("/c/:id/logs/"+"ws", sealed, limit, func(c *gin.Context) { server.handleLogs(c, false) })
+	router.GET("/c/:id/logs/"+"sse", sealed, limit, func(c *gin.Context) { server.handleLogs(c, false) })
+	router.POST("/c/:id/logs/sse/:sid", server.handleSSEInput)
 
-	ctl := server.options.Control
-	if ctl.Enable {
-		// container actions: start|stop|restart
-		containerG := router.Group("/container")
-		if ctl.Start || ctl.All {
-			containerG.POST("/start/:id", server.handleStartContainer)
+	// stats
+	router.GET("/c/:id/stats/", sealed, server.handleStatsPage)
+
+	// processes
+	router.GET("/c/:id/top/", sealed, server.handleTopPage)
+
+	// changed files
+	router.GET("/c/:id/diff/", sealed, server.handleDiffPage)
+
+	// env and mounts
+	router.GET("/c/:id/detail/", sealed, server.handleDetailPage)
+
+	// exec history
+	router.GET("/c/:id/history/", sealed, server.handleHistoryPage)
+
+	// restarts, OOM kills and health transitions
+	router.GET("/c/:id/timeline/", sealed, server.handleTimelinePage)
+
+	// files of the volumes
+	router.GET("/c/:id/volumes/", sealed, server.handleVolumesPage)
+
+	// API
+	api := router.Group("/api", checkCSRF)
+	api.GET("/version", server.handleVersion)
+	api.GET("/containers", server.handleListContainersAPI)
+	api.GET("/containers/:id/logs", sealed, server.handleLogsAPI)
+	api.POST("/containers/:id/run", sealed, server.handleRunCommand)
+	api.GET("/containers/:id/stats", sealed, server.handleStats)
+	api.GET("/containers/:id/top", sealed, server.handleTop)
+	api.GET("/containers/:id/diff", sealed, server.handleDiff)
+	api.GET("/containers/:id/detail", sealed, server.handleDetail)
+	api.POST("/containers/:id/health/check", sealed, server.handleHealthCheck)
+	api.GET("/containers/:id/history", sealed, server.handleHistory)
+	api.GET("/containers/:id/timeline", sealed, server.handleTimeline)
+	api.GET("/containers/:id/volumes", sealed, server.handleVolumes)
+	api.GET("/containers/:id/volumes/files", sealed, server.handleVolumeFiles)
+	api.GET("/containers/:id/volumes/download", sealed, server.handleVolumeDownload)
+	api.GET("/containers/:id/session-url", server.requireAdmin, server.handleSessionURL)
+	if server.options.ForwardTTL > 0 {
+		api.GET("/containers/:id/forward/:port", sealed, server.handleForwardTunnel)
+		api.POST("/containers/:id/forward/:port", sealed, server.handleForward)
+		router.Any("/forward/:token/*path", server.handleForwardProxy)
+	}
+	api.POST("/exec/batch", server.requireAdminIfSigned, server.handleBatchRun)
+	api.GET("/sessions", server.handleListSessions)
+	api.GET("/reports", server.handleReports)
+	api.GET("/events", server.handleEvents)
+	if server.options.EnableGraphQL {
+		api.GET("/graphql", server.handleGraphQL)
+		api.POST("/graphql", server.handleGraphQL)
+	}
+
+	// the admin token may be set by a reload, requireAdmin refuses the
+	// requests without it
+	admin := api.Group("/admin", server.requireAdmin)
+	admin.GET("/prune/:kind", server.handlePrune)
+	admin.POST("/prune/:kind", server.handlePrune)
+	if server.options.Cookie.MaxAge > 0 {
+		admin.POST("/session", server.handleLogin)
+		api.DELETE("/admin/session", server.handleLogout)
+	}
+	admin.GET("/errors", server.handleErrors)
+	admin.GET("/diagnostics", server.handleDiagnostics)
+	admin.GET("/journal", server.handleJournal)
+	admin.GET("/update", server.handleUpdate)
+	admin.GET("/config", server.handleConfigOptions)
+	admin.GET("/log/levels", server.handleLogLevels)
+	admin.PUT("/log/levels", server.handleLogLevels)
+	admin.GET("/log/debug", server.handleLogDebug)
+	admin.POST("/log/debug", server.handleLogDebug)
+	admin.GET("/drain", server.handleDrain)
+	admin.POST("/drain", server.handleDrain)
+	admin.GET("/maintenance", server.handleMaintenance)
+	admin.PUT("/maintenance", server.handleMaintenance)
+	admin.GET("/sessions", server.handleListSessions)
+	admin.DELETE("/sessions/:id", server.handleKillSession)
+	admin.GET("/sessions/:id/replay", server.handleSessionReplay)
+	admin.POST("/tokens", server.handleCreateToken)
+	admin.DELETE("/tokens/:id", server.handleRevokeToken)
+	if server.options.Control.Create {
+		admin.POST("/containers", server.handleCreate)
+	}
+	if server.options.ProvisionTTL > 0 {
+		admin.POST("/containers/:id/provision", server.handleProvision)
+	}
+
+	if server.options.Control.Enable {
+		// container actions: start|stop|restart|pause|unpause
+		containerG := router.Group("/container", checkCSRF)
+		for _, action := range server.controlActions() {
+			action := action
+			handler := func(c *gin.Context) { server.handleContainerActions(c, action) }
+			containerG.POST("/"+action+"/:id", sealed, handler)
+			api.POST("/containers/:id/"+action, sealed, handler)
+		}
+		if ctl := server.options.Control; ctl.Kill || ctl.All {
+			api.POST("/containers/:id/top/:pid/kill", sealed, server.handleKillProcess)
 		}
-		if ctl.Stop || ctl.All {
-			containerG.POST("/stop/:id", server.handleStopContainer)
+		if ctl := server.options.Control; ctl.Copy || ctl.All {
+			api.POST("/copy", server.requireAdminIfSigned, server.handleCopy)
 		}
-		if ctl.Restart || ctl.All {
-			containerG.POST("/restart/:id", server.handleRestartContainer)
+		if server.options.Control.Commit {
+			api.POST("/containers/:id/commit", sealed, server.handleCommit)
+		}
+		if server.options.Control.Debug {
+			api.POST("/containers/:id/debug", sealed, server.handleDebug)
+		}
+		if ctl := server.options.Control; ctl.Edit || ctl.All {
+			api.POST("/containers/:id/rename", sealed, server.handleRenameContainer)
+			api.PATCH("/containers/:id/labels", sealed, server.handleUpdateLabels)
 		}
 	}
 
 	// pprof
 	rootMux := http.NewServeMux()
-	if log.GetLevel() == log.DebugLevel {
-		rootMux.HandleFunc("/debug/pprof/", pprof.Index)
-		rootMux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
-		rootMux.HandleFunc("/debug/pprof/profile", pprof.Profile)
-		rootMux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
-		rootMux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	if log.Logger.IsLevelEnabled(logrus.DebugLevel) {
+		handlePprof(rootMux)
 	}
-	rootMux.Handle("/", router)
+	rootMux.Handle("/", engine)
 
-	hostPort := net.JoinHostPort(server.options.Address,
-		fmt.Sprint(server.options.Port))
-	srv := &http.Server{
-		Addr:    hostPort,
-		Handler: rootMux,
+	if server.options.H2C {
+		// HTTP/2 without TLS for the trusted proxies speaking it, the
+		// websockets still upgrade from HTTP/1.1 connections
+		return h2c.NewHandler(rootMux, &http2.Server{})
 	}
+	return rootMux
+}
 
-	srvErr := make(chan error, 1)
-	go func() {
-		srvErr <- srv.ListenAndServe()
-	}()
+func handlePprof(mux *http.ServeMux) {
+	mux.Handle("/debug/vars", expvar.Handler())
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+}
+
+// Start runs the background work of the Server until the ctx is done, the
+// watch of the backend of the journal, the update check and the sweep of
+// the failed logins. Run starts it, a program serving the Handler on its
+// own starts it instead.
+func (server *Server) Start(ctx context.Context) {
+	if server.journal != nil {
+		go server.watchBackend(ctx)
+	}
+	if server.updates != nil {
+		go server.updates.run(ctx)
+	}
+	if server.logins != nil {
+		go server.logins.run(ctx)
+	}
+}
+
+// Run starts the main process of the Server.
+// The cancelation of ctx will shutdown the server immediately with aborting
+// existing connections. Use WithGracefulContext() to support graceful shutdown.
+func (server *Server) Run(ctx context.Context, options ...RunOption) error {
+	cctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	opts := &RunOptions{gracefulCtx: context.Background()}
+	for _, opt := range options {
+		opt(opts)
+	}
+
+	listeners, err := server.listeners(opts)
+	if err != nil {
+		return err
+	}
+	handler := server.Handler(options...)
+	tlsConfig := server.TLSConfig()
+	scheme := "http"
+	if tlsConfig != nil {
+		scheme = "https"
+	}
+	var srvs httpServers
+	addrs := make([]string, 0, len(listeners))
+	srvErr := make(chan error, len(listeners))
+	for _, ln := range listeners {
+		network := ln.Addr().Network()
+		srv := &http.Server{
+			Handler: server.serveRoutes(handler, ln.routes, network),
+			// the requests stuck before upgrading don't pile up
+			ReadHeaderTimeout: server.options.InitTimeout,
+			MaxHeaderBytes:    server.options.MaxHeaderBytes,
+			TLSConfig:         tlsConfig,
+		}
+		srvs = append(srvs, srv)
+		addr := fmt.Sprintf("%s://%s", scheme, ln.Addr())
+		if network == "unix" {
+			addr = fmt.Sprintf("%s+unix://%s", scheme, ln.Addr())
+		}
+		if ln.routes != routesAll {
+			addr += " (" + ln.routes + ")"
+		}
+		addrs = append(addrs, addr)
+		go func(ln net.Listener) {
+			if tlsConfig != nil {
+				// the certificate is of the TLS config
+				srvErr <- srv.ServeTLS(ln, "", "")
+				return
+			}
+			srvErr <- srv.Serve(ln)
+		}(ln.Listener)
+	}
+
+	if addr := server.options.PprofAddr; addr != "" {
+		// a separate server to profile the relay under load, it's
+		// not exposed with the terminals
+		pprofMux := http.NewServeMux()
+		handlePprof(pprofMux)
+		pprofSrv := &http.Server{Addr: addr, Handler: pprofMux}
+		defer pprofSrv.Close()
+		go func() {
+			log.Infof("pprof running at http://%s/debug/pprof/", addr)
+			if err := pprofSrv.ListenAndServe(); err != http.ErrServerClosed {
+				log.Errorf("pprof server error: %s", err)
+			}
+		}()
+	}
+
+	if addr := server.options.ACMEHTTPAddr; server.acme != nil && addr != "" {
+		// the HTTP-01 challenges, the other requests are redirected to HTTPS
+		acmeSrv := &http.Server{Addr: addr, Handler: server.acme.HTTPHandler(nil)}
+		defer acmeSrv.Close()
+		go func() {
+			log.Infof("ACME challenges running at http://%s", addr)
+			if err := acmeSrv.ListenAndServe(); err != http.ErrServerClosed {
+				log.Errorf("ACME challenge server error: %s", err)
+			}
+		}()
+	}
 
 	go func() {
 		select {
 		case <-opts.gracefulCtx.Done():
 			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 			defer cancel()
-			if err := srv.Shutdown(ctx); err != nil {
+			if err := srvs.Shutdown(ctx); err != nil {
 				log.Fatal("Server Shutdown:", err)
 			}
 		case <-cctx.Done():
 		}
 	}()
+	if opts.drainCtx != nil {
+		go func() {
+			select {
+			case <-opts.drainCtx.Done():
+				server.Drain(server.options.DrainTimeout)
+			case <-cctx.Done():
+			}
+		}()
+	}
 
-	log.Infof("Server running at http://%s", hostPort)
+	for _, addr := range addrs {
+		log.Infof("Server running at %s", addr)
+	}
+	server.journal.Log(journal.KindStart, fmt.Sprintf("server %s started at %s",
+		server.options.Build.Version, strings.Join(addrs, ", ")), nil)
+	server.Start(cctx)
+	if opts.ready != nil {
+		opts.ready()
+	}
 
-	var err error
 	select {
 	case err = <-srvErr:
 		if err == http.ErrServerClosed { // by graceful ctx
 			err = nil
+			// the listeners of the others are closed as well
+			for i := 1; i < len(srvs); i++ {
+				<-srvErr
+			}
 		} else {
 			cancel()
+			srvs.Close()
 		}
+	case <-server.drained:
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		err = srvs.Shutdown(ctx)
 	case <-cctx.Done():
-		srv.Close()
+		srvs.Close()
 		err = cctx.Err()
 	}
 
-	conn := counter.count()
+	conn := server.counter.count()
 	if conn > 0 {
 		log.Printf("Waiting for %d connections to be closed", conn)
 		fmt.Println("Ctl-C to force close")
 	}
-	counter.wait()
+	server.counter.wait()
 
+	stopped := "server stopped"
+	if err != nil && err != context.Canceled {
+		stopped += ": " + err.Error()
+	}
+	server.journal.Log(journal.KindStop, stopped, nil)
+	server.journal.Close()
+	if server.store != nil {
+		server.store.Close()
+	}
 	return err
 }
@@ -0,0 +1,83 @@
+package route
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// the headers of securityHeaders, cleared by the responses of the
+// forwarded ports which are not of the UI
+var securityHeaderNames = []string{
+	"Content-Security-Policy",
+	"X-Frame-Options",
+	"X-Content-Type-Options",
+	"Referrer-Policy",
+	"Strict-Transport-Security",
+}
+
+// contentSecurityPolicy allows the scripts of the served files only, the
+// inline styles are allowed for the ones xterm renders
+func contentSecurityPolicy(frameAncestors string) string {
+	csp := "default-src 'self'; script-src 'self'; style-src 'self' 'unsafe-inline'; " +
+		"img-src 'self' data:; font-src 'self' data:; connect-src 'self'; " +
+		"object-src 'none'; base-uri 'self'; form-action 'self'"
+	if frameAncestors != "" {
+		csp += "; frame-ancestors " + frameAncestors
+	}
+	return csp
+}
+
+// frameAncestors returns the frame-ancestors of the CSP, the embedding
+// parents of --embed-origin are a regexp and can't be listed, so the
+// pages are allowed in any frame unless they're given
+func (server *Server) frameAncestors() string {
+	switch {
+	case server.options.FrameAncestors != "":
+		return server.options.FrameAncestors
+	case server.options.EmbedOrigin != "":
+		return ""
+	default:
+		return "'self'"
+	}
+}
+
+func (server *Server) securityHeaders() gin.HandlerFunc {
+	ancestors := server.frameAncestors()
+	csp := contentSecurityPolicy(ancestors)
+	frameOptions := ""
+	switch ancestors {
+	case "'self'":
+		frameOptions = "SAMEORIGIN"
+	case "'none'":
+		frameOptions = "DENY"
+	}
+	hsts := ""
+	if age := server.options.HSTSMaxAge; age > 0 {
+		hsts = fmt.Sprintf("max-age=%d", int64(age.Seconds()))
+	}
+
+	return func(c *gin.Context) {
+		h := c.Writer.Header()
+		h.Set("Content-Security-Policy", csp)
+		if frameOptions != "" {
+			h.Set("X-Frame-Options", frameOptions)
+		}
+		h.Set("X-Content-Type-Options", "nosniff")
+		// the tokens in the paths (e.g. of the forwarded URLs)
+		// are not sent to the other sites
+		h.Set("Referrer-Policy", "same-origin")
+		if hsts != "" && c.Request.TLS != nil {
+			h.Set("Strict-Transport-Security", hsts)
+		}
+		c.Next()
+	}
+}
+
+// clearSecurityHeaders leaves the headers of a response to the upstream
+func clearSecurityHeaders(h http.Header) {
+	for _, name := range securityHeaderNames {
+		h.Del(name)
+	}
+}
@@ -0,0 +1,58 @@
+package route
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/wrfly/container-web-tty/types"
+	"github.com/wrfly/container-web-tty/webtty"
+)
+
+// sessionErrors are the failures of the sessions by the messages of their
+// errors, which are the strings of the docker, kube and grpc backends,
+// the first match wins
+var sessionErrors = []struct {
+	code     string
+	message  string
+	patterns []string
+}{
+	{types.SessionUnauthorized, "the session is not authenticated",
+		[]string{"failed to authenticate"}},
+	{types.SessionBadRequest, "bad arguments of the session",
+		[]string{"bad arguments"}},
+	{types.SessionNoShell, "no shell is found in the container",
+		[]string{"cannot find a valid shell"}},
+	// before the others, e.g. the permission of the docker socket
+	{types.SessionBackendDown, "the backend is unreachable, the daemon may be down",
+		[]string{"cannot connect to the docker daemon", "connection refused", "no such host",
+			"i/o timeout", "deadline exceeded", "code = unavailable", "the backend is being created"}},
+	{types.SessionContainerGone, "the container is gone, it's removed or stopped",
+		[]string{"no such container", "not found", "is not running", "code = notfound"}},
+	{types.SessionExecDenied, "the exec is denied, the user may be unknown or not permitted",
+		[]string{"forbidden", "permission denied", "unauthorized", "unable to find user",
+			"no matching entries in passwd", "code = permissiondenied"}},
+}
+
+// sessionError maps the error of a session to the one shown to the user,
+// the error itself is only logged since it may tell the internals
+func sessionError(err error, requestID string) types.SessionError {
+	msg := strings.ToLower(err.Error())
+	for _, e := range sessionErrors {
+		for _, p := range e.patterns {
+			if strings.Contains(msg, p) {
+				return types.SessionError{Code: e.code, Message: e.message, RequestID: requestID}
+			}
+		}
+	}
+	return types.SessionError{
+		Code:      types.SessionExecFailed,
+		Message:   "the exec failed",
+		RequestID: requestID,
+	}
+}
+
+// writeSessionError sends the failure of the session before it's closed
+func writeSessionError(conn master, e types.SessionError) {
+	payload, _ := json.Marshal(e)
+	conn.Write(append([]byte{webtty.Error}, payload...))
+}
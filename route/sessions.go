@@ -0,0 +1,177 @@
+package route
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/wrfly/container-web-tty/store"
+	"github.com/wrfly/container-web-tty/types"
+	"github.com/wrfly/container-web-tty/webtty"
+)
+
+// maxClosedSessions is the number of closed sessions kept in memory
+const maxClosedSessions = 100
+
+// sessionRegistry records the active and recently closed sessions, the
+// closed ones are in the storage if it's set, shared by the replicas of it
+type sessionRegistry struct {
+	m      sync.RWMutex
+	active map[string]*activeSession
+	closed []types.Session // oldest first
+	store  store.Store
+	// onClose is called with the closed sessions if it's not nil
+	onClose func(types.Session)
+}
+
+func newSessionRegistry(s store.Store) *sessionRegistry {
+	return &sessionRegistry{
+		active: make(map[string]*activeSession),
+		store:  s,
+	}
+}
+
+// activeSession is an active session and its bytes by now, which are
+// counted atomically by the connections of it
+type activeSession struct {
+	sess    *types.Session
+	in, out int64
+}
+
+// session returns the session with its bytes, it's called with the lock
+func (a *activeSession) session() types.Session {
+	s := *a.sess
+	s.BytesIn = atomic.LoadInt64(&a.in)
+	s.BytesOut = atomic.LoadInt64(&a.out)
+	return s
+}
+
+// add adds the session, or the next connection of an active one (e.g. a
+// provisioned session joined, a detached one resumed), and returns it
+func (r *sessionRegistry) add(s *types.Session) *activeSession {
+	r.m.Lock()
+	defer r.m.Unlock()
+	a, ok := r.active[s.ID]
+	if !ok {
+		a = &activeSession{}
+		r.active[s.ID] = a
+	}
+	a.sess = s
+	return a
+}
+
+func (r *sessionRegistry) exited(id string, code int) {
+	r.m.Lock()
+	if a, ok := r.active[id]; ok {
+		a.sess.ExitCode = &code
+	}
+	r.m.Unlock()
+}
+
+// close moves the session to the closed ones, unknown sessions are ignored
+func (r *sessionRegistry) close(id string, reason string) {
+	r.m.Lock()
+	a, ok := r.active[id]
+	if !ok {
+		r.m.Unlock()
+		return
+	}
+	delete(r.active, id)
+
+	now := time.Now()
+	s := a.session()
+	s.EndAt = &now
+	s.Reason = reason
+	if r.store == nil {
+		r.closed = append(r.closed, s)
+		if len(r.closed) > maxClosedSessions {
+			r.closed = r.closed[len(r.closed)-maxClosedSessions:]
+		}
+	}
+	r.m.Unlock()
+
+	if r.store != nil {
+		bs, _ := json.Marshal(s)
+		if err := r.store.Push(sessionsKey, string(bs), maxClosedSessions); err != nil {
+			log.Errorf("store the closed session %s error: %s", s.ID, err)
+		}
+	}
+
+	if r.onClose != nil {
+		r.onClose(s)
+	}
+}
+
+// activeOf returns the active sessions of the container, newest first
+func (r *sessionRegistry) activeOf(containerID string) []types.Session {
+	r.m.RLock()
+	defer r.m.RUnlock()
+
+	sessions := []types.Session{}
+	for _, a := range r.active {
+		if a.sess.ContainerID == containerID {
+			sessions = append(sessions, a.session())
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].StartAt.After(sessions[j].StartAt)
+	})
+	return sessions
+}
+
+// list returns the active sessions followed by the closed ones, newest first
+func (r *sessionRegistry) list() ([]types.Session, error) {
+	var closed []types.Session
+	if r.store != nil {
+		values, err := r.store.Latest(sessionsKey, maxClosedSessions)
+		if err != nil {
+			return nil, err
+		}
+		closed = make([]types.Session, 0, len(values))
+		for _, v := range values {
+			var s types.Session
+			if json.Unmarshal([]byte(v), &s) == nil {
+				closed = append(closed, s)
+			}
+		}
+	}
+
+	r.m.RLock()
+	defer r.m.RUnlock()
+	sessions := make([]types.Session, 0, len(r.active)+len(r.closed)+len(closed))
+	for _, a := range r.active {
+		sessions = append(sessions, a.session())
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].StartAt.After(sessions[j].StartAt)
+	})
+	for i := len(r.closed) - 1; i >= 0; i-- {
+		sessions = append(sessions, r.closed[i])
+	}
+	return append(sessions, closed...), nil
+}
+
+// countedMaster counts the bytes of the connection of the session, the
+// setup of it is established by the first output
+type countedMaster struct {
+	webtty.Master
+	sess  *activeSession
+	setup *sessionSetup
+}
+
+func (m *countedMaster) Read(p []byte) (int, error) {
+	n, err := m.Master.Read(p)
+	atomic.AddInt64(&m.sess.in, int64(n))
+	return n, err
+}
+
+func (m *countedMaster) Write(p []byte) (int, error) {
+	n, err := m.Master.Write(p)
+	atomic.AddInt64(&m.sess.out, int64(n))
+	if err == nil && len(p) != 0 && p[0] == webtty.Output {
+		m.setup.established()
+	}
+	return n, err
+}
@@ -0,0 +1,79 @@
+package route
+
+import (
+	"expvar"
+	"sync/atomic"
+	"time"
+)
+
+// setupMetrics are the setups of the terminal sessions, from the request
+// of the terminal to the first output of the shell sent to the browser,
+// served in /debug/vars. The latencies are a cumulative histogram of the
+// buckets (le_<bound>) and their sum, the failures are counted by the
+// stage they failed at:
+//
+//   - connect: the websocket or the SSE stream is not opened
+//   - exec: the init message is bad or the exec of the backend failed
+//   - output: the exec ended (or failed) before any output
+//
+// The setups abandoned by the browsers before the output, relayed to the
+// other replicas or refused by the drain are not counted as failures.
+var setupMetrics = expvar.NewMap("session_setup")
+
+// the upper bounds of the latency buckets
+var setupBuckets = []struct {
+	bound time.Duration
+	name  string
+}{
+	{100 * time.Millisecond, "le_100ms"},
+	{250 * time.Millisecond, "le_250ms"},
+	{500 * time.Millisecond, "le_500ms"},
+	{time.Second, "le_1s"},
+	{2500 * time.Millisecond, "le_2.5s"},
+	{5 * time.Second, "le_5s"},
+	{10 * time.Second, "le_10s"},
+}
+
+// sessionSetup is the setup of a session, it ends once
+type sessionSetup struct {
+	start time.Time
+	ended int32
+}
+
+func newSessionSetup(start time.Time) *sessionSetup {
+	setupMetrics.Add("attempts", 1)
+	return &sessionSetup{start: start}
+}
+
+func (s *sessionSetup) end() bool {
+	return atomic.CompareAndSwapInt32(&s.ended, 0, 1)
+}
+
+// established records the latency of the setup at the first output
+func (s *sessionSetup) established() {
+	if !s.end() {
+		return
+	}
+	latency := time.Since(s.start)
+	setupMetrics.Add("established", 1)
+	setupMetrics.Add("latency_ms_sum", int64(latency/time.Millisecond))
+	for _, b := range setupBuckets {
+		if latency <= b.bound {
+			setupMetrics.Add(b.name, 1)
+		}
+	}
+	setupMetrics.Add("le_inf", 1)
+}
+
+func (s *sessionSetup) failed(stage string) {
+	if s.end() {
+		setupMetrics.Add("failures", 1)
+		setupMetrics.Add("failures_"+stage, 1)
+	}
+}
+
+func (s *sessionSetup) abandoned() {
+	if s.end() {
+		setupMetrics.Add("abandoned", 1)
+	}
+}
@@ -0,0 +1,258 @@
+package route
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/wrfly/container-web-tty/types"
+)
+
+var errBadSessionURL = errors.New("bad or expired session URL")
+
+// sessionURLs seals the container and the exec options of the exec URLs
+// into expiring tokens, they're encrypted so the IDs are not exposed, and
+// bound to the IP of the client they're issued to
+type sessionURLs struct {
+	aead cipher.AEAD
+	ttl  time.Duration
+}
+
+// newSessionURLs seals the tokens by the key of the secret, random without
+// it, then the tokens are only valid in this process
+func newSessionURLs(secret string, ttl time.Duration) (*sessionURLs, error) {
+	aead, err := newAEAD(secret, "session URL")
+	if err != nil {
+		return nil, err
+	}
+	return &sessionURLs{aead: aead, ttl: ttl}, nil
+}
+
+// newAEAD derives the key of the secret for the use by HMAC, a random one
+// without it. The tokens are sealed with the random nonces of AES-GCM,
+// both are approved by the FIPS mode
+func newAEAD(secret, use string) (cipher.AEAD, error) {
+	key := make([]byte, 32)
+	if secret != "" {
+		// the keys of HMAC shorter than 112 bits are refused by FIPS
+		if fipsMode() && len(secret) < 14 {
+			return nil, fmt.Errorf("the %s secret must be at least 14 bytes in the FIPS mode", use)
+		}
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte("container-web-tty " + use))
+		key = mac.Sum(nil)
+	} else if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return newRandomNonceGCM(block)
+}
+
+// sign returns the token of the container and the encoded exec options for
+// the client
+func (s *sessionURLs) sign(id, exec, ip string, now time.Time) string {
+	plain := make([]byte, 8, 8+len(id)+1+len(exec))
+	binary.BigEndian.PutUint64(plain, uint64(now.Add(s.ttl).Unix()))
+	plain = append(plain, id...)
+	plain = append(plain, 0)
+	plain = append(plain, exec...)
+	return base64.RawURLEncoding.EncodeToString(s.aead.Seal(nil, nil, plain, []byte(ip)))
+}
+
+// open returns the container and the encoded exec options of the token of
+// the client
+func (s *sessionURLs) open(token, ip string, now time.Time) (id, exec string, err error) {
+	sealed, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return "", "", errBadSessionURL
+	}
+	plain, err := s.aead.Open(nil, nil, sealed, []byte(ip))
+	if err != nil || len(plain) < 8 {
+		return "", "", errBadSessionURL
+	}
+	if now.Unix() > int64(binary.BigEndian.Uint64(plain)) {
+		return "", "", errBadSessionURL
+	}
+	parts := strings.SplitN(string(plain[8:]), "\x00", 2)
+	if len(parts) != 2 {
+		return "", "", errBadSessionURL
+	}
+	return parts[0], parts[1], nil
+}
+
+func remoteIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return ip
+}
+
+// execKeys are the exec options of the query, they're sealed into the
+// tokens instead if the URLs are signed
+var execKeys = []string{"cmd", "env", "user", "p", "tty", "attach", "stdin"}
+
+// execQuery returns the exec options of the query
+func execQuery(q url.Values) url.Values {
+	exec := url.Values{}
+	for _, k := range execKeys {
+		if v := q.Get(k); v != "" {
+			exec.Set(k, v)
+		}
+	}
+	return exec
+}
+
+// execPath returns the path segment of the exec URL of the container with
+// the exec options for the client, the token if the URLs are signed
+func (server *Server) execPath(c *gin.Context, id string, exec url.Values) string {
+	if server.signer == nil {
+		return id
+	}
+	return server.signer.sign(id, exec.Encode(), remoteIP(c.Request), time.Now())
+}
+
+// execURL returns the exec URL of the container with the exec options for
+// the client, they're of the query if the URLs are not signed
+func (server *Server) execURL(c *gin.Context, id string, exec url.Values) string {
+	u := server.options.BasePath + "/exec/" + server.execPath(c, id, exec) + "/"
+	if server.signer == nil && len(exec) > 0 {
+		u += "?" + exec.Encode()
+	}
+	return u
+}
+
+// sharePath returns the path segment of the share URL of the container,
+// the token is not bound to the IP since the URL is for the others
+func (server *Server) sharePath(id string) string {
+	if server.signer == nil {
+		return id
+	}
+	return server.signer.sign(id, "", "", time.Now())
+}
+
+// pathTokenKey keeps the token of the container of the path, the pages
+// call the API of the container with it
+const pathTokenKey = "path_token"
+
+// openContainerID opens the token of the :id of the path into the ID of
+// the container if the URLs are signed, the raw IDs are refused but of the
+// admins. The tokens of the share URLs are not bound to the IPs
+func (server *Server) openContainerID(bound bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if server.signer == nil {
+			return
+		}
+		ip := ""
+		if bound {
+			ip = remoteIP(c.Request)
+		}
+		token := c.Param("id")
+		id, _, err := server.signer.open(token, ip, time.Now())
+		if err != nil {
+			if !server.isAdmin(c) {
+				apiError(c, http.StatusForbidden, "%s", err)
+			}
+			return
+		}
+		for i := range c.Params {
+			if c.Params[i].Key == "id" {
+				c.Params[i].Value = id
+			}
+		}
+		c.Set(pathTokenKey, token)
+	}
+}
+
+// requireAdminIfSigned refuses the APIs picking the containers by the raw
+// IDs of the bodies or the selectors but to the admins if the URLs are signed, the
+// tokens don't reach them
+func (server *Server) requireAdminIfSigned(c *gin.Context) {
+	if server.signer != nil {
+		server.requireAdmin(c)
+	}
+}
+
+// pathID is the path segment of the container of the page, the token of
+// the path if the URLs are signed
+func pathID(c *gin.Context, id string) string {
+	if token := c.GetString(pathTokenKey); token != "" {
+		return token
+	}
+	return id
+}
+
+// execTarget returns the container and the pinned exec options of the exec
+// URL, the raw IDs are refused if the URLs are signed. The options are nil
+// if they're not, the ones of the init message are used then
+func (server *Server) execTarget(c *gin.Context) (id string, exec url.Values, ok bool) {
+	if server.signer == nil {
+		return c.Param("id"), nil, true
+	}
+	id, encoded, err := server.signer.open(c.Param("id"), remoteIP(c.Request), time.Now())
+	if err == nil {
+		exec, err = url.ParseQuery(encoded)
+	}
+	if err != nil {
+		apiError(c, http.StatusForbidden, "%s", errBadSessionURL)
+		return "", nil, false
+	}
+	return id, exec, true
+}
+
+// execPage renders the terminal page of the exec URL
+func (server *Server) execPage(c *gin.Context) {
+	id, _, ok := server.execTarget(c)
+	if !ok {
+		return
+	}
+	server.renderTerminal(c, server.containerCli.GetInfo(c.Request.Context(), id))
+}
+
+// handleSessionURL issues the exec URL of the container with the exec
+// options of the query, it's behind requireAdmin. The token is bound to
+// the ip of the query, the admin's IP without it
+func (server *Server) handleSessionURL(c *gin.Context) {
+	container := server.containerCli.GetInfo(c.Request.Context(), c.Param("id"))
+	if container.ID == "" {
+		apiError(c, http.StatusNotFound, "container %s not found", c.Param("id"))
+		return
+	}
+	exec := execQuery(c.Request.URL.Query())
+	if server.signer == nil {
+		c.JSON(http.StatusOK, types.SessionURL{URL: server.execURL(c, container.ID, exec)})
+		return
+	}
+
+	ip := remoteIP(c.Request)
+	if v := c.Query("ip"); v != "" {
+		parsed := net.ParseIP(v)
+		if parsed == nil {
+			apiError(c, http.StatusBadRequest, "bad ip: %s", v)
+			return
+		}
+		ip = parsed.String()
+	}
+	now := time.Now()
+	expireAt := now.Add(server.signer.ttl)
+	c.JSON(http.StatusOK, types.SessionURL{
+		URL:      server.options.BasePath + "/exec/" + server.signer.sign(container.ID, exec.Encode(), ip, now) + "/",
+		ExpireAt: &expireAt,
+	})
+}
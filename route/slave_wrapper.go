@@ -4,7 +4,7 @@ import (
 	"bytes"
 	"io"
 
-	"github.com/yudai/gotty/webtty"
+	"github.com/wrfly/container-web-tty/webtty"
 )
 
 type slaveWrapper struct {
@@ -40,6 +40,8 @@ func newSlave(rc io.ReadCloser, share bool) webtty.Slave {
 		defer rc.Close()
 
 		bs := make([]byte, 2048)
+		// the converted lines, written at once
+		out := make([]byte, 0, 2*len(bs))
 		for {
 			n, err := rc.Read(bs)
 			if err != nil {
@@ -60,17 +62,19 @@ func newSlave(rc io.ReadCloser, share bool) webtty.Slave {
 			}
 
 			// only \n or a long log string containes \n
-			s, e := 0, 0
+			out = out[:0]
+			e := 0
 			for e < n {
 				x := bytes.IndexByte(bs[e:n], 10)
 				if x == -1 {
 					break
 				}
-				s = e
-				e += x
-				pw.Write(bs[s:e])
-				pw.Write([]byte{13, 10})
-				e++ // skip this \n
+				out = append(out, bs[e:e+x]...)
+				out = append(out, 13, 10)
+				e += x + 1 // skip this \n
+			}
+			if len(out) != 0 {
+				pw.Write(out)
 			}
 		}
 	}()
@@ -0,0 +1,238 @@
+package route
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/wrfly/container-web-tty/webtty"
+)
+
+// maxSSEInput limits the body of a single POST of the SSE transport
+const maxSSEInput = 1 << 20
+
+// sseMaster is the SSE fallback of the websocket transport: messages to
+// the browser are written as server-sent events of a long-lived GET
+// request, messages from the browser arrive with separate POST requests
+type sseMaster struct {
+	id   string
+	path string
+	addr string
+
+	w     gin.ResponseWriter
+	wMux  sync.Mutex
+	input chan []byte
+	// the rest of an input message longer than a read
+	rest []byte
+
+	done chan struct{}
+	once sync.Once
+}
+
+func (server *Server) openSSE(c *gin.Context) (*sseMaster, error) {
+	id, err := newSessionID()
+	if err != nil {
+		return nil, err
+	}
+
+	m := &sseMaster{
+		id:    id,
+		path:  c.Request.URL.Path,
+		addr:  c.Request.RemoteAddr,
+		w:     c.Writer,
+		input: make(chan []byte, 16),
+		done:  make(chan struct{}),
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	if err := m.writeEvent("session", []byte(id)); err != nil {
+		return nil, err
+	}
+
+	server.sseMux.Lock()
+	server.sseMasters[id] = m
+	server.sseMux.Unlock()
+
+	// the context of gin is reused after the handler returns
+	ctx := c.Request.Context()
+	go func() {
+		// write a comment from time to time, so a dead
+		// connection is detected by the failed write
+		var ping <-chan time.Time
+		if interval := server.options.WSPingInterval; interval > 0 {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			ping = ticker.C
+		}
+	loop:
+		for {
+			select {
+			case <-ctx.Done():
+				m.Close()
+				break loop
+			case <-m.done:
+				break loop
+			case <-ping:
+				if m.ping() != nil {
+					m.Close()
+					break loop
+				}
+			}
+		}
+		server.sseMux.Lock()
+		delete(server.sseMasters, id)
+		server.sseMux.Unlock()
+	}()
+
+	return m, nil
+}
+
+func (m *sseMaster) writeEvent(event string, data []byte) error {
+	buf := new(bytes.Buffer)
+	if event != "" {
+		buf.WriteString("event: " + event + "\n")
+	}
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		buf.WriteString("data: ")
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	buf.WriteByte('\n')
+
+	if _, err := m.w.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	m.w.Flush()
+	return nil
+}
+
+func (m *sseMaster) ping() error {
+	m.wMux.Lock()
+	defer m.wMux.Unlock()
+
+	select {
+	case <-m.done:
+		return io.ErrClosedPipe
+	default:
+	}
+	if _, err := m.w.Write([]byte(": ping\n\n")); err != nil {
+		return err
+	}
+	m.w.Flush()
+	return nil
+}
+
+func (m *sseMaster) Write(p []byte) (int, error) {
+	m.wMux.Lock()
+	defer m.wMux.Unlock()
+
+	select {
+	case <-m.done:
+		return 0, io.ErrClosedPipe
+	default:
+	}
+	if err := m.writeEvent("", p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Read reads a message, an input message longer than p is read on
+// by the next reads as input messages, the rest of the others is dropped
+func (m *sseMaster) Read(p []byte) (int, error) {
+	if len(m.rest) > 0 {
+		p[0] = webtty.Input
+		n := copy(p[1:], m.rest)
+		m.rest = m.rest[n:]
+		return n + 1, nil
+	}
+	msg, err := m.readMessage()
+	n := copy(p, msg)
+	if n < len(msg) && msg[0] == webtty.Input {
+		m.rest = msg[n:]
+	}
+	return n, err
+}
+
+func (m *sseMaster) readMessage() ([]byte, error) {
+	select {
+	case msg := <-m.input:
+		return msg, nil
+	case <-m.done:
+		return nil, io.EOF
+	}
+}
+
+func (m *sseMaster) remoteAddr() string { return m.addr }
+
+func (m *sseMaster) closeWith(reason string) {
+	m.wMux.Lock()
+	select {
+	case <-m.done:
+	default:
+		m.writeEvent("close", []byte(reason))
+	}
+	m.wMux.Unlock()
+	m.Close()
+}
+
+func (m *sseMaster) Close() error {
+	m.once.Do(func() {
+		// wait for the running write, nothing will
+		// touch the response writer after this
+		m.wMux.Lock()
+		close(m.done)
+		m.wMux.Unlock()
+	})
+	return nil
+}
+
+// handleSSEInput receives the messages of an SSE session, the body is a
+// JSON array of webtty messages in the order they were sent
+func (server *Server) handleSSEInput(c *gin.Context) {
+	server.sseMux.RLock()
+	m, ok := server.sseMasters[c.Param("sid")]
+	server.sseMux.RUnlock()
+	if !ok || m.path != path.Dir(c.Request.URL.Path) {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	var msgs []string
+	body := io.LimitReader(c.Request.Body, maxSSEInput)
+	if err := json.NewDecoder(body).Decode(&msgs); err != nil {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	for _, msg := range msgs {
+		select {
+		case m.input <- []byte(msg):
+		case <-m.done:
+			c.AbortWithStatus(http.StatusGone)
+			return
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func newSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,392 @@
+package route
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/wrfly/container-web-tty/route/asset"
+)
+
+// staticAsset is a bundled file prepared to be served, the gzipped
+// bytes are nil if they're not smaller
+type staticAsset struct {
+	cType   string
+	body    []byte
+	gzipped []byte
+	// the first bytes of the sha256 of the body in hex
+	hash string
+	// the subresource integrity of the scripts and styles
+	integrity string
+}
+
+// staticAssets are the bundled files by their paths, the URLs of the
+// scripts, styles and images in the HTML carry their hashes (?v=) so
+// they are cached forever, and a new build changes them
+var staticAssets = loadStaticAssets()
+
+func loadStaticAssets() map[string]*staticAsset {
+	return prepareAssets(embeddedFiles(), true, "")
+}
+
+func embeddedFiles() map[string][]byte {
+	files := make(map[string][]byte)
+	for _, f := range asset.List() {
+		if stat, _ := f.Stat(); !stat.IsDir() {
+			files[f.Name()] = f.Bytes()
+		}
+	}
+	return files
+}
+
+// verifyAssets checks the embedded files by the sums of `make asset`, a
+// modified binary doesn't serve the terminal with the scripts of others
+func verifyAssets(files map[string][]byte, sums map[string]string) error {
+	for name, sum := range sums {
+		body, ok := files[name]
+		if !ok {
+			return fmt.Errorf("asset %s is missing", name)
+		}
+		if got := sha256.Sum256(body); hex.EncodeToString(got[:]) != sum {
+			return fmt.Errorf("asset %s is modified", name)
+		}
+	}
+	for name := range files {
+		if _, ok := sums[name]; !ok {
+			return fmt.Errorf("asset %s is unknown", name)
+		}
+	}
+	return nil
+}
+
+// customCSS is the style of the static dir, linked by all the pages if it's
+// there
+const customCSS = "/css/custom.css"
+
+// prepareAssets hashes the files and versions their URLs in the pages,
+// the text files are gzipped if compress, the URLs of the pages (src="/..."
+// and href="/...") are prefixed with the base path, and the scripts and
+// styles they load carry their integrity so the browsers refuse them once
+// they're modified on the way
+func prepareAssets(files map[string][]byte, compress bool, base string) map[string]*staticAsset {
+	assets := make(map[string]*staticAsset)
+	var pages []string
+	for name, body := range files {
+		if strings.HasSuffix(name, ".html") {
+			pages = append(pages, name)
+			continue
+		}
+		assets[name] = newStaticAsset(name, body, compress)
+	}
+
+	// the pages refer to the versioned URLs
+	_, custom := files[customCSS]
+	for _, page := range pages {
+		body := files[page]
+		if custom {
+			body = bytes.Replace(body, []byte("</head>"),
+				[]byte(`  <link rel="stylesheet" href="`+customCSS+`" />`+"\n</head>"), 1)
+		}
+		if base != "" {
+			for _, attr := range []string{` src="/`, ` href="/`} {
+				body = bytes.Replace(body, []byte(attr),
+					[]byte(attr[:len(attr)-1]+base+"/"), -1)
+			}
+		}
+		for name, a := range assets {
+			versioned := base + name + "?v=" + a.hash
+			if a.integrity != "" {
+				for _, attr := range []string{` src="`, ` href="`} {
+					body = bytes.Replace(body, []byte(attr+base+name+`"`),
+						[]byte(attr+versioned+`" integrity="`+a.integrity+`"`), -1)
+				}
+			}
+			body = bytes.Replace(body, []byte(`"`+base+name+`"`),
+				[]byte(`"`+versioned+`"`), -1)
+		}
+		assets[page] = newStaticAsset(page, body, compress)
+	}
+	return assets
+}
+
+func newStaticAsset(name string, body []byte, compress bool) *staticAsset {
+	sum := sha256.Sum256(body)
+	a := &staticAsset{
+		cType: contentType(name, body),
+		body:  body,
+		hash:  hex.EncodeToString(sum[:8]),
+	}
+	if strings.HasSuffix(name, ".js") || strings.HasSuffix(name, ".css") {
+		sri := sha512.Sum384(body)
+		a.integrity = "sha384-" + base64.StdEncoding.EncodeToString(sri[:])
+	}
+	if !compress || strings.HasPrefix(a.cType, "image/") {
+		return a
+	}
+	buf := new(bytes.Buffer)
+	gw, _ := gzip.NewWriterLevel(buf, gzip.BestCompression)
+	gw.Write(body)
+	gw.Close()
+	if buf.Len() < len(body) {
+		a.gzipped = buf.Bytes()
+	}
+	return a
+}
+
+func contentType(name string, body []byte) string {
+	switch {
+	case strings.HasSuffix(name, ".html"):
+		return "text/html; charset=utf-8"
+	case strings.HasSuffix(name, ".css"):
+		return "text/css; charset=utf-8"
+	case strings.HasSuffix(name, ".js"):
+		return "application/javascript"
+	case strings.HasSuffix(name, ".svg"):
+		return "image/svg+xml"
+	}
+	return http.DetectContentType(body)
+}
+
+// pageAssets are the embedded assets (with the files of --static-dir over
+// them), or the ones of the --dev-assets dir read on every request, the
+// templates of the embedded pages are parsed on their first use
+type pageAssets struct {
+	dir string
+	// the base path of the URLs, and the embedded assets of it
+	base     string
+	embedded map[string]*staticAsset
+
+	m         sync.Mutex
+	templates map[string]*template.Template
+}
+
+func newPageAssets(dir, staticDir, base string) (*pageAssets, error) {
+	if dir != "" && staticDir != "" {
+		return nil, fmt.Errorf("the dev assets and the static dir can't be both set")
+	}
+	if dir != "" {
+		if stat, err := os.Stat(dir); err != nil {
+			return nil, err
+		} else if !stat.IsDir() {
+			return nil, fmt.Errorf("%s is not a directory", dir)
+		}
+		log.Warnf("serving the assets of %s, reloaded on every request", dir)
+	}
+	embedded := staticAssets
+	if staticDir != "" {
+		files, err := readStaticDir(staticDir)
+		if err != nil {
+			return nil, err
+		}
+		embedded = prepareAssets(files, true, base)
+	} else if base != "" && dir == "" {
+		embedded = prepareAssets(embeddedFiles(), true, base)
+	}
+	return &pageAssets{
+		dir:       dir,
+		base:      base,
+		embedded:  embedded,
+		templates: make(map[string]*template.Template),
+	}, nil
+}
+
+// load returns the assets by their paths, not prefixed with the base path
+func (p *pageAssets) load() (map[string]*staticAsset, error) {
+	if p.dir == "" {
+		return p.embedded, nil
+	}
+	return readDevAssets(p.dir, p.base)
+}
+
+// template returns the parsed page of the name
+func (p *pageAssets) template(name string) (*template.Template, error) {
+	if p.dir == "" {
+		p.m.Lock()
+		defer p.m.Unlock()
+		if t, ok := p.templates[name]; ok {
+			return t, nil
+		}
+	}
+
+	assets, err := p.load()
+	if err != nil {
+		return nil, err
+	}
+	a, ok := assets[name]
+	if !ok {
+		return nil, fmt.Errorf("asset %s not found", name)
+	}
+	t, err := template.New(strings.TrimPrefix(name, "/")).Parse(string(a.body))
+	if err != nil {
+		return nil, fmt.Errorf("parse %s error: %s", name, err)
+	}
+	if p.dir == "" {
+		p.templates[name] = t
+	}
+	return t, nil
+}
+
+// readDevAssets reads the resources dir laid out like `make asset`,
+// the embedded files are used for the ones not in it, except the
+// bundle of js/dist next to it if it's built (with `webpack --watch`)
+func readDevAssets(dir, base string) (map[string]*staticAsset, error) {
+	files := embeddedFiles()
+	if _, err := overlayDir(files, dir); err != nil {
+		return nil, err
+	}
+	bundle := filepath.Join(dir, "..", "js", "dist", "gotty-bundle.js")
+	if b, err := ioutil.ReadFile(bundle); err == nil {
+		files["/js/gotty-bundle.js"] = b
+	}
+	return prepareAssets(files, false, base), nil
+}
+
+// readStaticDir lays the files of the dir, laid out like the resources dir,
+// over the embedded ones once, the pages of it are checked at once
+func readStaticDir(dir string) (map[string][]byte, error) {
+	if stat, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("bad static dir: %s", err)
+	} else if !stat.IsDir() {
+		return nil, fmt.Errorf("bad static dir: %s is not a directory", dir)
+	}
+	files := embeddedFiles()
+	embedded := make(map[string]bool, len(files))
+	for name := range files {
+		embedded[name] = true
+	}
+	names, err := overlayDir(files, dir)
+	if err != nil {
+		return nil, fmt.Errorf("read the static dir error: %s", err)
+	}
+	var replaced, added []string
+	for _, name := range names {
+		if strings.HasSuffix(name, ".html") {
+			if _, err := template.New(name).Parse(string(files[name])); err != nil {
+				return nil, fmt.Errorf("bad page %s of the static dir: %s", name, err)
+			}
+		}
+		if embedded[name] {
+			replaced = append(replaced, name)
+		} else {
+			added = append(added, name)
+		}
+	}
+	log.Infof("the static dir %s replaces the assets %v, adds %v", dir, replaced, added)
+	return files, nil
+}
+
+// overlayDir reads the files of the dir over the files by their URLs, and
+// returns the URLs read, the others are ignored
+func overlayDir(files map[string][]byte, dir string) ([]string, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		name := devAssetPath(e.Name())
+		if e.IsDir() || name == "" {
+			continue
+		}
+		if files[name], err = ioutil.ReadFile(filepath.Join(dir, e.Name())); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// devAssetPath is the URL of a file of the resources dir
+func devAssetPath(file string) string {
+	switch filepath.Ext(file) {
+	case ".html", ".png", ".svg", ".ico", ".jpg", ".gif", ".webp":
+		return "/" + file
+	case ".js":
+		return "/js/" + file
+	case ".css":
+		return "/css/" + file
+	}
+	return ""
+}
+
+// execTemplate renders the page of the name
+func (server *Server) execTemplate(w io.Writer, name string, data interface{}) error {
+	t, err := server.assets.template(name)
+	if err != nil {
+		return err
+	}
+	return t.Execute(w, data)
+}
+
+// handleStatic serves the bundled file with its ETag, the versioned URLs
+// are cached forever and the others are revalidated, the gzipped bytes
+// are served to the browsers accepting them
+func (server *Server) handleStatic(c *gin.Context) {
+	assets, err := server.assets.load()
+	if err != nil {
+		apiError(c, http.StatusInternalServerError, "load assets error: %s", err)
+		return
+	}
+	a, ok := assets[strings.TrimPrefix(c.Request.URL.Path, server.options.BasePath)]
+	if !ok {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	etag := `"` + a.hash + `"`
+	c.Header("ETag", etag)
+	if c.Query("v") == a.hash && server.assets.dir == "" {
+		c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		c.Header("Cache-Control", "no-cache")
+	}
+	if a.gzipped != nil {
+		c.Header("Vary", "Accept-Encoding")
+	}
+	if etagMatch(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	body := a.body
+	if a.gzipped != nil && acceptsGzip(c.GetHeader("Accept-Encoding")) {
+		c.Header("Content-Encoding", "gzip")
+		body = a.gzipped
+	}
+	c.Data(http.StatusOK, a.cType, body)
+}
+
+// etagMatch tells whether the If-None-Match header matches the etag
+func etagMatch(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == etag || tag == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func acceptsGzip(header string) bool {
+	for _, enc := range strings.Split(header, ",") {
+		enc = strings.TrimSpace(enc)
+		if enc == "gzip" || (strings.HasPrefix(enc, "gzip;") && !strings.HasSuffix(enc, "q=0")) {
+			return true
+		}
+	}
+	return false
+}
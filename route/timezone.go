@@ -0,0 +1,65 @@
+package route
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// the zones of --timezone besides the IANA names
+const (
+	zoneBrowser = "browser"
+	zoneServer  = "server"
+)
+
+// timezone resolves the option to the location of the times written by the
+// server (the audit logs, the replays and the downloads) and the IANA name
+// the pages render the times in, nil and empty for the browser-local time
+func timezone(name string) (*time.Location, string, error) {
+	switch strings.ToLower(name) {
+	case "", zoneBrowser:
+		return nil, "", nil
+	case "utc":
+		return time.UTC, "UTC", nil
+	case zoneServer:
+		zone := serverZone()
+		if zone == "" {
+			log.Warn("the timezone of the server is unknown, the pages use the browsers' own")
+		}
+		return time.Local, zone, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, "", fmt.Errorf("bad timezone %s: %s", name, err)
+	}
+	return loc, loc.String(), nil
+}
+
+// serverZone is the IANA name of the local time of the server, of $TZ or
+// the link of /etc/localtime, empty if it's unknown
+func serverZone() string {
+	if tz := strings.TrimPrefix(os.Getenv("TZ"), ":"); tz != "" {
+		if _, err := time.LoadLocation(tz); err == nil {
+			return tz
+		}
+		return ""
+	}
+	link, err := os.Readlink("/etc/localtime")
+	if err != nil {
+		return ""
+	}
+	if i := strings.Index(link, "zoneinfo/"); i >= 0 {
+		return link[i+len("zoneinfo/"):]
+	}
+	return ""
+}
+
+// inZone converts the time to the zone of the server, it's left as it is
+// for the browser-local time
+func (server *Server) inZone(t time.Time) time.Time {
+	if server.zone == nil {
+		return t
+	}
+	return t.In(server.zone)
+}
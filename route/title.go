@@ -0,0 +1,88 @@
+package route
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	noesctmpl "text/template"
+
+	"github.com/wrfly/container-web-tty/types"
+)
+
+// DefaultTitleFormat is the window title template of the terminals
+// without --title-format
+const DefaultTitleFormat = "{{ .containerName }} - {{ printf \"%.8s\" .containerID }}@{{ .containerLoc }}"
+
+const (
+	backendDocker = "docker"
+	backendKube   = "kube"
+)
+
+// titleTemplates are the window title templates of the terminals, the
+// ones of the backends of the containers if they're set
+type titleTemplates struct {
+	all      *noesctmpl.Template
+	backends map[string]*noesctmpl.Template
+	// the hostname of the server, the host of the local containers
+	hostname string
+}
+
+// newTitleTemplates parses the title format (the default one if it's
+// empty) and the ones of the backends
+func newTitleTemplates(format string, backends map[string]string) (*titleTemplates, error) {
+	if format == "" {
+		format = DefaultTitleFormat
+	}
+	all, err := noesctmpl.New("title").Parse(format)
+	if err != nil {
+		return nil, fmt.Errorf("bad title format: %s", err)
+	}
+	titles := &titleTemplates{all: all, backends: map[string]*noesctmpl.Template{}}
+	for backend, format := range backends {
+		if format == "" {
+			continue
+		}
+		if titles.backends[backend], err = noesctmpl.New("title-" + backend).Parse(format); err != nil {
+			return nil, fmt.Errorf("bad title format of %s: %s", backend, err)
+		}
+	}
+	if titles.hostname, err = os.Hostname(); err != nil {
+		titles.hostname = "localhost"
+	}
+	return titles, nil
+}
+
+// containerBackend is the backend of the container, kube for the ones of
+// the pods, docker for the others, also of the upstream servers of grpc
+func containerBackend(c types.Container) string {
+	if c.PodName != "" {
+		return backendKube
+	}
+	return backendDocker
+}
+
+// template is the title template of the backend of the container
+func (titles *titleTemplates) template(c types.Container) *noesctmpl.Template {
+	if t, ok := titles.backends[containerBackend(c)]; ok {
+		return t
+	}
+	return titles.all
+}
+
+// host is the upstream server of the container of grpc, the hostname of
+// the server for the others
+func (titles *titleTemplates) host(c types.Container) string {
+	if c.LocServer != "" {
+		return c.LocServer
+	}
+	return titles.hostname
+}
+
+// execute fills the title template of the container with the variables
+func (titles *titleTemplates) execute(c types.Container, vars map[string]interface{}) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := titles.template(c).Execute(buf, vars); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
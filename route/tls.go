@@ -0,0 +1,193 @@
+package route
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+
+	"github.com/wrfly/container-web-tty/config"
+	"github.com/wrfly/container-web-tty/journal"
+)
+
+// modernCiphers are the ECDHE suites with AEAD of TLS 1.2 allowed by
+// the modern profile, the suites of TLS 1.3 are all modern
+var modernCiphers = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305,
+	tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305,
+}
+
+// fipsCiphers are the ECDHE suites with AES-GCM of TLS 1.2 allowed by the
+// fips profile, the suites of TLS 1.3 are limited by the FIPS mode of Go
+var fipsCiphers = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+}
+
+// the TLS profiles of --tls-profile
+const (
+	tlsProfileDefault = "default"
+	tlsProfileModern  = "modern"
+	tlsProfileFIPS    = "fips"
+)
+
+// fipsBuild is true if it's built with GOEXPERIMENT=boringcrypto
+var fipsBuild bool
+
+// fipsMode reports whether only the FIPS-approved crypto is allowed, by
+// the boringcrypto build or by GODEBUG=fips140=on of the native module
+func fipsMode() bool {
+	return fipsBuild || fipsModule()
+}
+
+// tlsProfile returns the TLS profile of the options, the fips one by
+// default in the FIPS mode, the others are refused by it
+func tlsProfile(options config.ServerConfig) (string, error) {
+	profile := options.TLSProfile
+	if profile == "" {
+		profile = tlsProfileDefault
+		if fipsMode() {
+			profile = tlsProfileFIPS
+		}
+	}
+	switch profile {
+	case tlsProfileDefault, tlsProfileModern, tlsProfileFIPS:
+	default:
+		return "", fmt.Errorf("unknown TLS profile %s", profile)
+	}
+	if fipsMode() && profile != tlsProfileFIPS {
+		return "", fmt.Errorf("the %s TLS profile is not allowed in the FIPS mode", profile)
+	}
+	return profile, nil
+}
+
+// certReloader serves the certificate of the files, it's loaded again
+// by the handshakes after the files are changed, so a renewed certificate
+// is served without restarting
+type certReloader struct {
+	certFile, keyFile string
+
+	m    sync.Mutex
+	cert *tls.Certificate
+	// the mod times of the files loaded or failed to load last
+	certMod, keyMod time.Time
+	// the last error logged
+	lastErr string
+	// the reloads are recorded in it
+	journal *journal.Journal
+}
+
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// reload loads the files if they're changed, the last certificate is
+// kept if they're bad (e.g. the key is not written yet), r.m is held
+func (r *certReloader) reload() error {
+	certStat, err := os.Stat(r.certFile)
+	if err != nil {
+		return err
+	}
+	keyStat, err := os.Stat(r.keyFile)
+	if err != nil {
+		return err
+	}
+	if r.cert != nil && certStat.ModTime().Equal(r.certMod) && keyStat.ModTime().Equal(r.keyMod) {
+		return nil
+	}
+	r.certMod, r.keyMod = certStat.ModTime(), keyStat.ModTime()
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("load certificate error: %s", err)
+	}
+	if r.cert != nil {
+		log.Infof("reloaded the certificate of %s", r.certFile)
+		r.journal.Log(journal.KindReload, "reloaded the certificate of "+r.certFile, nil)
+	}
+	r.cert = &cert
+	return nil
+}
+
+// reloadNow loads the files whether they're changed or not, a bad one is
+// returned and the last certificate is kept
+func (r *certReloader) reloadNow() error {
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.certMod, r.keyMod = time.Time{}, time.Time{}
+	return r.reload()
+}
+
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	err := r.reload()
+	if err != nil && err.Error() != r.lastErr {
+		log.Errorf("reload the certificate of %s error: %s", r.certFile, err)
+	}
+	r.lastErr = ""
+	if err != nil {
+		r.lastErr = err.Error()
+	}
+	return r.cert, nil
+}
+
+// newACMEManager gets the certificates of the domains once they're
+// requested, the other host names are refused
+func newACMEManager(options config.ServerConfig) (*autocert.Manager, error) {
+	if len(options.Domains) == 0 {
+		return nil, fmt.Errorf("Let's Encrypt requires the domains")
+	}
+	if options.ACMECacheDir == "" {
+		return nil, fmt.Errorf("Let's Encrypt requires the cache dir")
+	}
+	m := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		Cache:      autocert.DirCache(options.ACMECacheDir),
+		HostPolicy: autocert.HostWhitelist(options.Domains...),
+		Email:      options.ACMEEmail,
+	}
+	if options.ACMEDirectory != "" {
+		m.Client = &acme.Client{DirectoryURL: options.ACMEDirectory}
+	}
+	return m, nil
+}
+
+// TLSConfig returns the TLS config of --tls-cert and --tls-key or of
+// --letsencrypt served by Run(), nil without them
+func (server *Server) TLSConfig() *tls.Config {
+	var conf *tls.Config
+	switch {
+	case server.acme != nil:
+		// with the TLS-ALPN challenges
+		conf = server.acme.TLSConfig()
+	case server.certs != nil:
+		conf = &tls.Config{GetCertificate: server.certs.GetCertificate}
+	default:
+		return nil
+	}
+	conf.MinVersion = tls.VersionTLS12
+	switch server.tlsProfile {
+	case tlsProfileModern:
+		conf.CipherSuites = modernCiphers
+		conf.CurvePreferences = []tls.CurveID{tls.X25519, tls.CurveP256}
+	case tlsProfileFIPS:
+		conf.CipherSuites = fipsCiphers
+		conf.CurvePreferences = []tls.CurveID{tls.CurveP256, tls.CurveP384, tls.CurveP521}
+	}
+	return conf
+}
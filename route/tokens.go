@@ -0,0 +1,119 @@
+package route
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/wrfly/container-web-tty/types"
+)
+
+// tokenPrefix tells the admin tokens created by the admin API from
+// --admin-token, they're cwt_<id>_<secret>
+const tokenPrefix = "cwt_"
+
+// adminTokenHash is an admin token in the storage, by the hash of its secret
+type adminTokenHash struct {
+	types.AdminToken
+	Hash string `json:"hash"`
+}
+
+func adminTokenKey(id string) string {
+	return "container-web-tty:admin-token:" + id
+}
+
+func tokenHash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// validToken tells whether the token is an admin token created by the
+// admin API which is neither revoked nor expired
+func (server *Server) validToken(token string) bool {
+	parts := strings.SplitN(strings.TrimPrefix(token, tokenPrefix), "_", 2)
+	if !strings.HasPrefix(token, tokenPrefix) || len(parts) != 2 {
+		return false
+	}
+	v, ok, err := server.tokens.Get(adminTokenKey(parts[0]))
+	if err != nil {
+		log.Errorf("get the admin token %s error: %s", parts[0], err)
+		return false
+	}
+	var t adminTokenHash
+	if !ok || json.Unmarshal([]byte(v), &t) != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(t.Hash), []byte(tokenHash(parts[1]))) == 1
+}
+
+// handleCreateToken creates an admin token of the name valid for the TTL,
+// the token is only in the response
+func (server *Server) handleCreateToken(c *gin.Context) {
+	var opts types.AdminTokenOptions
+	if err := c.ShouldBindJSON(&opts); err != nil {
+		apiError(c, http.StatusBadRequest, "bad request: %s", err)
+		return
+	}
+	var ttl time.Duration
+	if opts.TTL != "" {
+		var err error
+		if ttl, err = time.ParseDuration(opts.TTL); err != nil || ttl <= 0 {
+			apiError(c, http.StatusBadRequest, "bad ttl: %s", opts.TTL)
+			return
+		}
+	}
+
+	id, err := newSessionID()
+	if err != nil {
+		apiError(c, http.StatusInternalServerError, "create the token error: %s", err)
+		return
+	}
+	id = id[:16]
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		apiError(c, http.StatusInternalServerError, "create the token error: %s", err)
+		return
+	}
+	secret := base64.RawURLEncoding.EncodeToString(b)
+
+	t := adminTokenHash{
+		AdminToken: types.AdminToken{ID: id, Name: opts.Name, CreatedAt: time.Now()},
+		Hash:       tokenHash(secret),
+	}
+	if ttl > 0 {
+		expires := t.CreatedAt.Add(ttl)
+		t.ExpiresAt = &expires
+	}
+	bs, _ := json.Marshal(t)
+	if err := server.tokens.Set(adminTokenKey(id), string(bs), ttl); err != nil {
+		apiError(c, http.StatusInternalServerError, "store the token error: %s", err)
+		return
+	}
+	requestLog(c).Warnf("created the admin token %s %q", id, opts.Name)
+	created := t.AdminToken
+	created.Token = tokenPrefix + id + "_" + secret
+	c.JSON(http.StatusCreated, created)
+}
+
+// handleRevokeToken revokes the admin token of the ID
+func (server *Server) handleRevokeToken(c *gin.Context) {
+	id := c.Param("id")
+	if _, ok, err := server.tokens.Get(adminTokenKey(id)); err != nil || !ok {
+		apiError(c, http.StatusNotFound, "admin token %s not found", id)
+		return
+	}
+	if err := server.tokens.Del(adminTokenKey(id)); err != nil {
+		apiError(c, http.StatusInternalServerError, "revoke the token error: %s", err)
+		return
+	}
+	requestLog(c).Warnf("revoked the admin token %s", id)
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,38 @@
+package route
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/wrfly/container-web-tty/tracing"
+)
+
+// traceRequests records the server span of the request, as a child of the
+// traceparent of the client, the backend calls of the handlers are its
+// children
+func (server *Server) traceRequests(c *gin.Context) {
+	if !tracing.Enabled() {
+		return
+	}
+	ctx := tracing.Extract(c.Request.Context(), c.Request.Header)
+	ctx, span := tracing.Start(ctx, "HTTP "+c.Request.Method, tracing.KindServer)
+	if span == nil {
+		return
+	}
+	defer span.End()
+	c.Request = c.Request.WithContext(ctx)
+	span.SetAttr("http.method", c.Request.Method)
+	span.SetAttr("http.target", server.maskedPath(c.Request.URL.Path))
+	span.SetAttr("http.client_ip", c.ClientIP())
+	span.SetAttr("request_id", c.GetString(requestIDKey))
+
+	c.Next()
+
+	status := c.Writer.Status()
+	span.SetAttr("http.status_code", status)
+	if status >= 500 {
+		span.SetError(fmt.Errorf("%s", http.StatusText(status)))
+	}
+}
@@ -0,0 +1,160 @@
+package route
+
+import (
+	"archive/tar"
+	"bufio"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// transferError is an archive entry refused by the transfer limits,
+// responded with the code
+type transferError struct {
+	code int
+	msg  string
+}
+
+func (e *transferError) Error() string { return e.msg }
+
+func transferErrorf(code int, format string, args ...interface{}) error {
+	return &transferError{code: code, msg: fmt.Sprintf(format, args...)}
+}
+
+// deniedPath reports whether the path of a container is under one of
+// the --transfer-deny-paths
+func (server *Server) deniedPath(p string) bool {
+	p = path.Clean("/" + p)
+	for _, deny := range server.settings().denyPaths {
+		if deny = strings.TrimSpace(deny); deny == "" {
+			continue
+		}
+		deny = path.Clean("/" + deny)
+		if deny == "/" || p == deny || strings.HasPrefix(p, deny+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// transferCheck checks the entries of an archive transferred from a
+// container by the limits of the server, it's used by one archive
+type transferCheck struct {
+	server  *Server
+	entries int
+	size    int64
+	// the symlinks of the archive, no entry is under them
+	symlinks map[string]bool
+}
+
+func (server *Server) newTransferCheck() *transferCheck {
+	return &transferCheck{server: server, symlinks: make(map[string]bool)}
+}
+
+// entry checks the header of the entry of the path p in the container
+func (t *transferCheck) entry(p string, hdr *tar.Header) error {
+	opts := t.server.options
+	if t.entries++; opts.MaxTransferEntries > 0 && t.entries > opts.MaxTransferEntries {
+		return transferErrorf(http.StatusRequestEntityTooLarge,
+			"more than %d entries", opts.MaxTransferEntries)
+	}
+	switch hdr.Typeflag {
+	case tar.TypeChar, tar.TypeBlock, tar.TypeFifo:
+		return transferErrorf(http.StatusForbidden, "%s is a device node", p)
+	case tar.TypeSymlink:
+		t.symlinks[p] = true
+	}
+	if t.server.deniedPath(p) {
+		return transferErrorf(http.StatusForbidden, "%s is denied", p)
+	}
+	for dir := path.Dir(p); dir != "/" && dir != "."; dir = path.Dir(dir) {
+		if t.symlinks[dir] {
+			return transferErrorf(http.StatusBadRequest, "%s is under the symlink %s", p, dir)
+		}
+	}
+	if hasContent(hdr) {
+		t.size += hdr.Size
+	}
+	if opts.MaxTransferSize > 0 && t.size > opts.MaxTransferSize {
+		return transferErrorf(http.StatusRequestEntityTooLarge,
+			"more than %d bytes", opts.MaxTransferSize)
+	}
+	return nil
+}
+
+// hasContent reports whether the entry carries data, all but the links,
+// the dirs and the device nodes do whatever their type flags are, as
+// TypeRegA, the sparse files or the unknown ones
+func hasContent(hdr *tar.Header) bool {
+	switch hdr.Typeflag {
+	case tar.TypeLink, tar.TypeSymlink, tar.TypeChar, tar.TypeBlock, tar.TypeDir, tar.TypeFifo:
+		return false
+	}
+	return true
+}
+
+// content returns the content of the file p once its sniffed MIME
+// type is not one of the --transfer-deny-mime
+func (t *transferCheck) content(p string, r io.Reader) (io.Reader, error) {
+	denyMIME := t.server.settings().denyMIME
+	if len(denyMIME) == 0 {
+		return r, nil
+	}
+	br := bufio.NewReaderSize(r, 512)
+	head, err := br.Peek(512)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, err
+	}
+	typ, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	for _, deny := range denyMIME {
+		if deny = strings.TrimSpace(deny); deny != "" && strings.HasPrefix(typ, deny) {
+			return nil, transferErrorf(http.StatusForbidden, "%s is %s", p, typ)
+		}
+	}
+	return br, nil
+}
+
+// outOfArchive reports whether the name of an entry is out of the dir
+// the archive is extracted to
+func outOfArchive(name string) bool {
+	clean := path.Clean(name)
+	return path.IsAbs(name) || clean == ".." || strings.HasPrefix(clean, "../")
+}
+
+// copyArchive copies the checked entries of the archive of dir in the
+// container, it stops at the first refused one before it's written
+func (t *transferCheck) copyArchive(dst io.Writer, src io.Reader, dir string) error {
+	tr := tar.NewReader(src)
+	tw := tar.NewWriter(dst)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return tw.Close()
+		}
+		if err != nil {
+			return err
+		}
+		if outOfArchive(hdr.Name) || (hdr.Typeflag == tar.TypeLink && outOfArchive(hdr.Linkname)) {
+			return transferErrorf(http.StatusBadRequest, "%s is out of the archive", hdr.Name)
+		}
+		p := path.Join(dir, hdr.Name)
+		if err := t.entry(p, hdr); err != nil {
+			return err
+		}
+		var content io.Reader = tr
+		if hasContent(hdr) {
+			if content, err = t.content(p, tr); err != nil {
+				return err
+			}
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := io.Copy(tw, content); err != nil {
+			return err
+		}
+	}
+}
@@ -2,11 +2,22 @@ package route
 
 import (
 	"context"
+	"net"
+
+	"github.com/gin-gonic/gin"
 )
 
 // RunOptions holds a set of configurations for Server.Run().
 type RunOptions struct {
 	gracefulCtx context.Context
+	drainCtx    context.Context
+	listener    net.Listener
+	ready       func()
+
+	releaseMode bool
+	noLogger    bool
+	middleware  []gin.HandlerFunc
+	auth        Authenticator
 }
 
 // RunOption is an option of Server.Run().
@@ -19,3 +30,62 @@ func WithGracefullContext(ctx context.Context) RunOption {
 		options.gracefulCtx = ctx
 	}
 }
+
+// WithDrainContext accepts a context to drain a Server, it stops
+// accepting new sessions and exits after the existing ones are closed
+// or the drain timeout passes.
+func WithDrainContext(ctx context.Context) RunOption {
+	return func(options *RunOptions) {
+		options.drainCtx = ctx
+	}
+}
+
+// WithListener serves on the listener (e.g. a socket of the systemd
+// socket activation) instead of listening on the address and port.
+func WithListener(l net.Listener) RunOption {
+	return func(options *RunOptions) {
+		options.listener = l
+	}
+}
+
+// WithReleaseMode sets gin to the release mode before the routes are added,
+// so they're not printed. The mode of gin is of the whole process.
+func WithReleaseMode() RunOption {
+	return func(options *RunOptions) {
+		options.releaseMode = true
+	}
+}
+
+// WithoutGinLogger disables the request logger of gin added in its debug
+// mode, the access log and the debug logs of the requests are kept.
+func WithoutGinLogger() RunOption {
+	return func(options *RunOptions) {
+		options.noLogger = true
+	}
+}
+
+// WithMiddleware adds the middleware of an embedding program to all the
+// routes, after those of the Server, so the client IP, the request ID and
+// the recovery of the panics are set up when they run.
+func WithMiddleware(middleware ...gin.HandlerFunc) RunOption {
+	return func(options *RunOptions) {
+		options.middleware = append(options.middleware, middleware...)
+	}
+}
+
+// WithAuth authenticates all the requests by the auth before the routes and
+// the middleware of WithMiddleware. The admins of it are allowed to the
+// admin API without the admin token, which is enabled even if the admin
+// token is not set.
+func WithAuth(auth Authenticator) RunOption {
+	return func(options *RunOptions) {
+		options.auth = auth
+	}
+}
+
+// WithReady calls the ready once the Server is listening.
+func WithReady(ready func()) RunOption {
+	return func(options *RunOptions) {
+		options.ready = ready
+	}
+}
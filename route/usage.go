@@ -0,0 +1,173 @@
+package route
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/wrfly/container-web-tty/types"
+)
+
+const (
+	// usageDays is the days of the usage kept in memory
+	usageDays = 90
+	// defaultUsageDays is the days of a report without ?from
+	defaultUsageDays = 7
+	dayLayout        = "2006-01-02"
+)
+
+type usageKey struct {
+	day, user, container string
+}
+
+// usageRollup is the daily usage of the closed sessions by the exec
+// users and the containers, since the server started
+type usageRollup struct {
+	m    sync.Mutex
+	days map[usageKey]*types.Usage
+}
+
+func newUsageRollup() *usageRollup {
+	return &usageRollup{days: make(map[usageKey]*types.Usage)}
+}
+
+// record adds the closed session to the usage of the day it started,
+// the days older than usageDays are dropped
+func (r *usageRollup) record(s types.Session) {
+	end := time.Now()
+	if s.EndAt != nil {
+		end = *s.EndAt
+	}
+	k := usageKey{s.StartAt.UTC().Format(dayLayout), s.User, s.ContainerID}
+
+	r.m.Lock()
+	defer r.m.Unlock()
+	u, ok := r.days[k]
+	if !ok {
+		u = &types.Usage{Day: k.day, User: k.user, ContainerID: k.container}
+		r.days[k] = u
+
+		oldest := time.Now().UTC().AddDate(0, 0, -usageDays).Format(dayLayout)
+		for k := range r.days {
+			if k.day < oldest {
+				delete(r.days, k)
+			}
+		}
+	}
+	u.Sessions++
+	u.Seconds += end.Sub(s.StartAt).Seconds()
+	u.BytesIn += s.BytesIn
+	u.BytesOut += s.BytesOut
+}
+
+// report returns the usage of the days from and to (inclusive), by the
+// user, the container or both of them, newest first
+func (r *usageRollup) report(from, to, by string) []types.Usage {
+	r.m.Lock()
+	defer r.m.Unlock()
+	rows := make(map[usageKey]*types.Usage)
+	for k, u := range r.days {
+		if k.day < from || k.day > to {
+			continue
+		}
+		switch by {
+		case "user":
+			k.container = ""
+		case "container":
+			k.user = ""
+		}
+		row, ok := rows[k]
+		if !ok {
+			row = &types.Usage{Day: k.day, User: k.user, ContainerID: k.container}
+			rows[k] = row
+		}
+		row.Sessions += u.Sessions
+		row.Seconds += u.Seconds
+		row.BytesIn += u.BytesIn
+		row.BytesOut += u.BytesOut
+	}
+
+	report := make([]types.Usage, 0, len(rows))
+	for _, row := range rows {
+		report = append(report, *row)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		a, b := report[i], report[j]
+		if a.Day != b.Day {
+			return a.Day > b.Day
+		}
+		if a.User != b.User {
+			return a.User < b.User
+		}
+		return a.ContainerID < b.ContainerID
+	})
+	return report
+}
+
+// sessionClosed records the usage of the closed session, its summary in
+// the journal, and the exec history if the audit is enabled
+func (server *Server) sessionClosed(s types.Session) {
+	server.usage.record(s)
+	server.journalSession(s)
+	if server.options.EnableAudit {
+		server.recordHistory(s)
+	}
+}
+
+// handleReports reports the usage of the days ?from and ?to (the last 7
+// days by default) ?by=user, container or both by default, as CSV with
+// ?format=csv
+func (server *Server) handleReports(c *gin.Context) {
+	today := time.Now().UTC()
+	from := today.AddDate(0, 0, 1-defaultUsageDays).Format(dayLayout)
+	to := today.Format(dayLayout)
+	for _, q := range []struct {
+		name string
+		day  *string
+	}{{"from", &from}, {"to", &to}} {
+		if v := c.Query(q.name); v != "" {
+			if _, err := time.Parse(dayLayout, v); err != nil {
+				apiError(c, http.StatusBadRequest, "bad %s: %q, should be YYYY-MM-DD", q.name, v)
+				return
+			}
+			*q.day = v
+		}
+	}
+	by := c.Query("by")
+	switch by {
+	case "", "user", "container":
+	default:
+		apiError(c, http.StatusBadRequest, "bad by: %q, should be user or container", by)
+		return
+	}
+	report := server.usage.report(from, to, by)
+
+	switch format := c.Query("format"); format {
+	case "", "json":
+		c.JSON(http.StatusOK, report)
+	case "csv":
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition",
+			fmt.Sprintf("attachment; filename=%q", "usage-"+from+"-"+to+".csv"))
+		w := csv.NewWriter(c.Writer)
+		w.Write([]string{"day", "user", "container_id", "sessions", "seconds", "bytes_in", "bytes_out"})
+		for _, u := range report {
+			w.Write([]string{
+				u.Day, u.User, u.ContainerID,
+				strconv.Itoa(u.Sessions),
+				strconv.FormatFloat(u.Seconds, 'f', 0, 64),
+				strconv.FormatInt(u.BytesIn, 10),
+				strconv.FormatInt(u.BytesOut, 10),
+			})
+		}
+		w.Flush()
+	default:
+		apiError(c, http.StatusBadRequest, "bad format: %q, should be json or csv", format)
+	}
+}
@@ -0,0 +1,175 @@
+package route
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/wrfly/container-web-tty/types"
+)
+
+const (
+	// versionTimeout is the max time to get the versions of the backend
+	versionTimeout = 3 * time.Second
+	// updateCheckInterval is the interval of the update checks
+	updateCheckInterval = 24 * time.Hour
+)
+
+// handleVersion reports the build of the server and the versions of the
+// backend
+func (server *Server) handleVersion(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), versionTimeout)
+	defer cancel()
+	backends, err := server.containerCli.Version(ctx)
+	if err != nil {
+		backends = []types.BackendVersion{{Name: "backend", Error: err.Error()}}
+	}
+	c.JSON(http.StatusOK, types.Version{
+		Version:   server.options.Build.Version,
+		CommitID:  server.options.Build.CommitID,
+		BuildAt:   server.options.Build.BuildAt,
+		GoVersion: runtime.Version(),
+		Backends:  backends,
+	})
+}
+
+// handleUpdate reports the latest release found by the update check, 404
+// if it's disabled
+func (server *Server) handleUpdate(c *gin.Context) {
+	if server.updates == nil {
+		apiError(c, http.StatusNotFound, "the update check is disabled")
+		return
+	}
+	c.JSON(http.StatusOK, server.updates.last())
+}
+
+// updateChecker gets the latest release from the URL (of the GitHub API)
+// once a day. Nothing but a plain GET is sent, without the version, the
+// hostname or any cookie, the version is compared here.
+type updateChecker struct {
+	url     string
+	current string
+	client  *http.Client
+
+	m      sync.Mutex
+	result types.UpdateCheck
+}
+
+func newUpdateChecker(url, current string) *updateChecker {
+	return &updateChecker{
+		url:     url,
+		current: current,
+		client:  &http.Client{Timeout: 30 * time.Second},
+		result:  types.UpdateCheck{Current: current},
+	}
+}
+
+func (u *updateChecker) last() types.UpdateCheck {
+	u.m.Lock()
+	defer u.m.Unlock()
+	return u.result
+}
+
+// run checks at once and then every interval until the ctx is done
+func (u *updateChecker) run(ctx context.Context) {
+	ticker := time.NewTicker(updateCheckInterval)
+	defer ticker.Stop()
+	for {
+		u.check(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (u *updateChecker) check(ctx context.Context) {
+	result := types.UpdateCheck{Current: u.current, CheckedAt: time.Now().UTC()}
+	latest, url, err := u.latest(ctx)
+	if err != nil {
+		log.Warnf("check the updates error: %s", err)
+		result.Error = err.Error()
+	} else {
+		result.Latest, result.URL = latest, url
+		result.Available = newerVersion(latest, u.current)
+	}
+
+	u.m.Lock()
+	notify := result.Available && result.Latest != u.result.Latest
+	u.result = result
+	u.m.Unlock()
+	if notify {
+		log.Infof("a new release %s is available, this is %s: %s", result.Latest, u.current, result.URL)
+	}
+}
+
+// latest returns the tag and the page of the latest release
+func (u *updateChecker) latest(ctx context.Context) (string, string, error) {
+	req, err := http.NewRequest(http.MethodGet, u.url, nil)
+	if err != nil {
+		return "", "", err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("User-Agent", "container-web-tty")
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("%s of %s", resp.Status, u.url)
+	}
+	var release struct {
+		TagName string `json:"tag_name"`
+		HTMLURL string `json:"html_url"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&release); err != nil {
+		return "", "", fmt.Errorf("bad release of %s: %s", u.url, err)
+	}
+	if release.TagName == "" {
+		return "", "", fmt.Errorf("no tag of the release of %s", u.url)
+	}
+	return release.TagName, release.HTMLURL, nil
+}
+
+// newerVersion tells if the version a (e.g. v0.2.0) is newer than b, the
+// numbers are compared one by one and the suffixes (-rc1) are ignored
+func newerVersion(a, b string) bool {
+	parse := func(v string) []int {
+		v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+		if i := strings.IndexAny(v, "-+"); i >= 0 {
+			v = v[:i]
+		}
+		var nums []int
+		for _, part := range strings.Split(v, ".") {
+			n, _ := strconv.Atoi(part)
+			nums = append(nums, n)
+		}
+		return nums
+	}
+	va, vb := parse(a), parse(b)
+	for i := 0; i < len(va) || i < len(vb); i++ {
+		var x, y int
+		if i < len(va) {
+			x = va[i]
+		}
+		if i < len(vb) {
+			y = vb[i]
+		}
+		if x != y {
+			return x > y
+		}
+	}
+	return false
+}
@@ -0,0 +1,192 @@
+package route
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/wrfly/container-web-tty/types"
+)
+
+// setupPage is the page of the first-run setup, served by RunSetup only
+const setupPage = "/setup.html"
+
+// the assets of the setup page
+var setupAssets = []string{setupPage, "/js/setup.js", "/css/admin.css", "/favicon.png"}
+
+// minAdminToken is the shortest admin token the setup accepts
+const minAdminToken = 16
+
+// RunSetup serves the setup page on the address instead of the terminals
+// until a config is submitted with the code and saved, then returns it.
+// The page is filled with the defaults once the code is entered, the fixed
+// ones can't be changed. It returns the error of the ctx if it's done
+// before.
+func RunSetup(ctx context.Context, hostPort, code string, defaults types.SetupConfig,
+	save func(types.SetupConfig) error) (types.SetupConfig, error) {
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		http.Redirect(w, r, setupPage, http.StatusFound)
+	})
+	for _, name := range setupAssets {
+		a, ok := staticAssets[name]
+		if !ok {
+			return types.SetupConfig{}, fmt.Errorf("asset %s not found", name)
+		}
+		mux.HandleFunc(name, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", a.cType)
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Write(a.body)
+		})
+	}
+
+	var (
+		m     sync.Mutex
+		done  bool
+		saved = make(chan types.SetupConfig, 1)
+	)
+	mux.HandleFunc("/api/setup", func(w http.ResponseWriter, r *http.Request) {
+		var cfg types.SetupConfig
+		switch r.Method {
+		case http.MethodGet:
+			cfg.Code = r.Header.Get("X-Setup-Code")
+		case http.MethodPost:
+			if !sameOrigin(r) {
+				setupError(w, http.StatusForbidden, "cross-origin request from %s", r.Header.Get("Origin"))
+				return
+			}
+			if err := json.NewDecoder(io.LimitReader(r.Body, 1<<16)).Decode(&cfg); err != nil {
+				setupError(w, http.StatusBadRequest, "bad setup config: %s", err)
+				return
+			}
+		default:
+			setupError(w, http.StatusMethodNotAllowed, "method %s not allowed", r.Method)
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(cfg.Code), []byte(code)) != 1 {
+			log.Warnf("bad setup code from %s", r.RemoteAddr)
+			setupError(w, http.StatusUnauthorized, "bad setup code, it's in the logs of the server")
+			return
+		}
+		if r.Method == http.MethodGet {
+			setupJSON(w, http.StatusOK, defaults)
+			return
+		}
+
+		cfg.Code = ""
+		if err := checkSetup(&cfg); err != nil {
+			setupError(w, http.StatusBadRequest, "%s", err)
+			return
+		}
+		keepFixed(&cfg, defaults)
+		m.Lock()
+		defer m.Unlock()
+		if done {
+			setupError(w, http.StatusConflict, "the server is set up already")
+			return
+		}
+		if err := save(cfg); err != nil {
+			setupError(w, http.StatusInternalServerError, "save the config error: %s", err)
+			return
+		}
+		done = true
+		log.Infof("the server is set up by %s", r.RemoteAddr)
+		setupJSON(w, http.StatusOK, cfg)
+		saved <- cfg
+	})
+
+	ln, err := net.Listen("tcp", hostPort)
+	if err != nil {
+		return types.SetupConfig{}, err
+	}
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	srvErr := make(chan error, 1)
+	go func() { srvErr <- srv.Serve(ln) }()
+
+	select {
+	case cfg := <-saved:
+		// the response of the submit is sent before the server restarts
+		sctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(sctx)
+		return cfg, nil
+	case err := <-srvErr:
+		return types.SetupConfig{}, err
+	case <-ctx.Done():
+		srv.Close()
+		return types.SetupConfig{}, ctx.Err()
+	}
+}
+
+// keepFixed keeps the fixed options of the defaults
+func keepFixed(cfg *types.SetupConfig, defaults types.SetupConfig) {
+	for _, name := range defaults.Fixed {
+		switch name {
+		case "backend":
+			cfg.Backend = defaults.Backend
+		case "docker_host":
+			cfg.DockerHost = defaults.DockerHost
+		case "kube_config":
+			cfg.KubeConfig = defaults.KubeConfig
+		case "grpc_servers":
+			cfg.GRPCServers = defaults.GRPCServers
+		case "address":
+			cfg.Address = defaults.Address
+		case "port":
+			cfg.Port = defaults.Port
+		}
+	}
+	cfg.Fixed = nil
+}
+
+// checkSetup checks the submitted config, the admin token is generated if
+// it's empty
+func checkSetup(cfg *types.SetupConfig) error {
+	switch cfg.Backend {
+	case "docker", "kube":
+	case "grpc":
+		if len(cfg.GRPCServers) == 0 {
+			return fmt.Errorf("no servers of the grpc backend")
+		}
+	default:
+		return fmt.Errorf("bad backend %s, must be docker, kube or grpc", cfg.Backend)
+	}
+	if cfg.Port <= 0 || cfg.Port > 65535 {
+		return fmt.Errorf("bad port %d", cfg.Port)
+	}
+	if cfg.Address != "" && net.ParseIP(cfg.Address) == nil {
+		return fmt.Errorf("bad address %s, must be an IP", cfg.Address)
+	}
+	if cfg.AdminToken == "" {
+		token, err := newSessionID()
+		if err != nil {
+			return err
+		}
+		cfg.AdminToken = token
+	} else if len(cfg.AdminToken) < minAdminToken {
+		return fmt.Errorf("the admin token is shorter than %d characters", minAdminToken)
+	}
+	return nil
+}
+
+func setupJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func setupError(w http.ResponseWriter, code int, format string, args ...interface{}) {
+	setupJSON(w, code, types.APIError{Code: code, Message: fmt.Sprintf(format, args...)})
+}
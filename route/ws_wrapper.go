@@ -1,14 +1,117 @@
 package route
 
 import (
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
+
+	"github.com/wrfly/container-web-tty/webtty"
 )
 
+// errBinaryMessage closes the connections sending a binary message, the
+// messages of the terminals are text
+var errBinaryMessage = errors.New("unexpected binary websocket message")
+
+// master is the browser end of a terminal, connected either
+// by a websocket or by the SSE fallback
+type master interface {
+	webtty.Master
+	Close() error
+
+	remoteAddr() string
+	// readMessage reads a whole text message
+	readMessage() ([]byte, error)
+	// closeWith tells the browser why the connection is closed
+	closeWith(reason string)
+}
+
+// openMaster picks the transport by the last element of the
+// request path, "ws" for websocket and "sse" for the fallback
+func (server *Server) openMaster(c *gin.Context) (master, error) {
+	if path.Base(c.Request.URL.Path) == "sse" {
+		return server.openSSE(c)
+	}
+
+	conn, err := server.upgrade(c)
+	if err != nil {
+		return nil, err
+	}
+	wsw := &wsWrapper{Conn: conn, req: c.Request, done: make(chan struct{})}
+	if server.options.WSCompression {
+		// no-op if the browser doesn't support it
+		conn.SetCompressionLevel(server.options.WSCompressionLevel)
+		wsw.compressThreshold = server.options.WSCompressionThreshold
+	}
+	if server.options.WSPingInterval > 0 {
+		wsw.keepalive(server.options.WSPingInterval, server.options.WSPingTimeout)
+	}
+	return wsw, nil
+}
+
+// pingWriteWait is the time limit of writing a ping
+const pingWriteWait = 10 * time.Second
+
 type wsWrapper struct {
 	*websocket.Conn
+	// the upgraded request
+	req *http.Request
+
+	// the read deadline, extended by any message or pong
+	timeout time.Duration
+	// compress the messages not shorter than it, 0 to compress all
+	compressThreshold int
+	done              chan struct{}
+	once              sync.Once
+	// the rest of an input message longer than a read
+	input io.Reader
+}
+
+// keepalive pings the browser and closes the connection if nothing is
+// received before the timeout, so half-open connections don't leak
+func (wsw *wsWrapper) keepalive(interval, timeout time.Duration) {
+	wsw.timeout = timeout
+	wsw.extendDeadline()
+	wsw.Conn.SetPongHandler(func(string) error {
+		wsw.extendDeadline()
+		return nil
+	})
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				deadline := time.Now().Add(pingWriteWait)
+				if err := wsw.Conn.WriteControl(websocket.PingMessage, nil, deadline); err != nil {
+					return
+				}
+			case <-wsw.done:
+				return
+			}
+		}
+	}()
+}
+
+func (wsw *wsWrapper) extendDeadline() {
+	if wsw.timeout > 0 {
+		wsw.Conn.SetReadDeadline(time.Now().Add(wsw.timeout))
+	}
+}
+
+func (wsw *wsWrapper) Close() error {
+	wsw.once.Do(func() { close(wsw.done) })
+	return wsw.Conn.Close()
 }
 
 func (wsw *wsWrapper) Write(p []byte) (n int, err error) {
+	wsw.Conn.EnableWriteCompression(len(p) >= wsw.compressThreshold)
 	writer, err := wsw.Conn.NextWriter(websocket.TextMessage)
 	if err != nil {
 		return 0, err
@@ -17,16 +120,55 @@ func (wsw *wsWrapper) Write(p []byte) (n int, err error) {
 	return writer.Write(p)
 }
 
+// Read reads a message, an input message longer than p is read on
+// by the next reads as input messages, the rest of the others is dropped
 func (wsw *wsWrapper) Read(p []byte) (n int, err error) {
-	for {
-		msgType, reader, err := wsw.Conn.NextReader()
-		if err != nil {
-			return 0, err
+	if wsw.input != nil {
+		n, _ := io.ReadFull(wsw.input, p[1:])
+		if n == len(p)-1 {
+			p[0] = webtty.Input
+			return n + 1, nil
 		}
-
-		if msgType != websocket.TextMessage {
-			continue
+		wsw.input = nil
+		if n > 0 {
+			p[0] = webtty.Input
+			return n + 1, nil
 		}
-		return reader.Read(p)
 	}
+
+	msgType, reader, err := wsw.Conn.NextReader()
+	if err != nil {
+		return 0, err
+	}
+	wsw.extendDeadline()
+	if msgType != websocket.TextMessage {
+		return 0, errBinaryMessage
+	}
+	n, err = io.ReadFull(reader, p)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = nil
+	}
+	if err == nil && n == len(p) && p[0] == webtty.Input {
+		wsw.input = reader
+	}
+	return n, err
+}
+
+func (wsw *wsWrapper) readMessage() ([]byte, error) {
+	msgType, p, err := wsw.Conn.ReadMessage()
+	if err != nil {
+		return nil, err
+	}
+	wsw.extendDeadline()
+	if msgType != websocket.TextMessage {
+		return nil, errBinaryMessage
+	}
+	return p, nil
+}
+
+// remoteAddr is the one of the request, of the client behind the trusted proxies
+func (wsw *wsWrapper) remoteAddr() string { return wsw.req.RemoteAddr }
+
+func (wsw *wsWrapper) closeWith(reason string) {
+	wsw.Conn.WriteMessage(websocket.CloseMessage, []byte(reason))
 }
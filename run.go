@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"net"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/wrfly/ecp"
@@ -9,26 +11,66 @@ import (
 
 	"github.com/wrfly/container-web-tty/config"
 	"github.com/wrfly/container-web-tty/container"
+	"github.com/wrfly/container-web-tty/crash"
+	"github.com/wrfly/container-web-tty/event"
 	"github.com/wrfly/container-web-tty/proxy"
 	"github.com/wrfly/container-web-tty/route"
+	"github.com/wrfly/container-web-tty/systemd"
+	"github.com/wrfly/container-web-tty/tracing"
 	"github.com/wrfly/container-web-tty/util"
 )
 
-func run(c *cli.Context, conf config.Config) {
+// serverOptions are the options of the HTTP server of the config
+func serverOptions(conf config.Config) (config.ServerConfig, error) {
 	srvOptions := conf.Server
+	srvOptions.Build = config.BuildInfo{Version: Version, CommitID: CommitID, BuildAt: BuildAt}
+	srvOptions.Redacted = redacted(conf)
 
 	if len(conf.Backend.GRPC.Servers) > 0 {
 		srvOptions.ShowLocation = true
 	}
-	if err := ecp.Default(&srvOptions); err != nil {
+	err := ecp.Default(&srvOptions)
+	return srvOptions, err
+}
+
+func run(c *cli.Context, conf config.Config) {
+	srvOptions, err := serverOptions(conf)
+	if err != nil {
 		logrus.Fatal(err)
 	}
 
-	if srvOptions.GrpcPort <= 0 && srvOptions.Port <= 0 {
+	// the sockets of the systemd socket activation
+	sockets, err := systemd.Listeners()
+	if err != nil {
+		logrus.Fatal(err)
+	}
+	socket := httpSocket(sockets)
+	serveHTTP := srvOptions.Port > 0 || len(srvOptions.Listen) > 0 ||
+		srvOptions.AdminAddr != "" || socket != nil
+	if srvOptions.GrpcPort <= 0 && !serveHTTP {
 		logrus.Fatal("bad config, no port listenning")
 	}
 
-	containerCli, err := container.NewCliBackend(conf.Backend)
+	if err := crash.Setup(conf.Report.SentryDSN, conf.Report.Webhook, Version); err != nil {
+		logrus.Fatal(err)
+	}
+	if conf.Trace.Endpoint != "" {
+		shutdown, err := tracing.Setup(conf.Trace.Endpoint,
+			conf.Trace.Service, conf.Trace.SampleRatio)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := shutdown(ctx); err != nil {
+				logrus.Errorf("export the traces error: %s", err)
+			}
+		}()
+	}
+
+	events := event.NewHub()
+	containerCli, err := container.NewCliBackend(conf.Backend, events)
 	if err != nil {
 		logrus.Fatalf("Create backend client error: %s", err)
 	}
@@ -36,31 +78,108 @@ func run(c *cli.Context, conf config.Config) {
 
 	ctx, cancel := context.WithCancel(context.Background())
 	gCtx, gCancel := context.WithCancel(ctx)
+	dCtx, dCancel := context.WithCancel(ctx)
+	defer dCancel()
+	var drainCancel context.CancelFunc
+	if srvOptions.DrainTimeout > 0 && serveHTTP {
+		drainCancel = stopping(dCancel)
+	}
 	errs := make(chan error, 2)
+	go watchdog(ctx, systemd.WatchdogInterval())
 
-	// run HTTP server if port > 0
-	if srvOptions.Port > 0 {
+	// run HTTP server if port > 0 or there're other listeners
+	if serveHTTP {
+		srv, err := route.New(containerCli, events, srvOptions)
+		if err != nil {
+			logrus.Fatalf("Create server error: %s", err)
+		}
+		runOptions := []route.RunOption{
+			route.WithGracefullContext(gCtx),
+			route.WithDrainContext(dCtx),
+			route.WithReady(func() { sdNotify("READY=1") }),
+		}
+		if socket != nil {
+			runOptions = append(runOptions, route.WithListener(socket))
+		}
 		go func() {
-			srv, err := route.New(containerCli, srvOptions)
-			if err != nil {
-				logrus.Fatalf("Create server error: %s", err)
-			}
-			errs <- srv.Run(ctx, route.WithGracefullContext(gCtx))
+			errs <- srv.Run(ctx, runOptions...)
 		}()
+		go reloadOnHangup(ctx, c, srv)
 	}
 
 	// run grpc server if grpc-port > 0
 	if srvOptions.GrpcPort > 0 {
 		go func() {
 			grpcServer := proxy.New(conf.Backend.GRPC.Auth,
-				srvOptions.GrpcPort, containerCli)
+				srvOptions.GrpcPort, containerCli, events, conf.Backend.Keepalive)
 			errs <- grpcServer.Run(ctx, gCtx)
 		}()
+		if !serveHTTP {
+			sdNotify("READY=1")
+		}
 	}
 
-	err = util.WaitSignals(errs, cancel, gCancel)
+	err = util.WaitSignals(errs, stopping(cancel), stopping(gCancel), drainCancel)
 	if err != nil && err != context.Canceled {
 		logrus.Fatalf("Server closed with error: %s", err)
 	}
 	logrus.Info("Server closed")
 }
+
+// httpSocket is the socket of the HTTP server passed by systemd, the one
+// named "http" (FileDescriptorName=) or the first one, the others are
+// closed
+func httpSocket(sockets []systemd.Listener) net.Listener {
+	var socket net.Listener
+	for _, s := range sockets {
+		if s.Name == "http" {
+			socket = s.Listener
+		}
+	}
+	for _, s := range sockets {
+		if socket == nil {
+			socket = s.Listener
+		}
+		if s.Listener != socket {
+			logrus.Warnf("closed the unused socket %s (%s) of systemd", s.Addr(), s.Name)
+			s.Close()
+		}
+	}
+	if socket != nil {
+		logrus.Infof("serving on the socket %s of systemd", socket.Addr())
+	}
+	return socket
+}
+
+// sdNotify notifies systemd of the state if it's run by systemd
+func sdNotify(state string) {
+	if _, err := systemd.Notify(state); err != nil {
+		logrus.Warnf("notify systemd error: %s", err)
+	}
+}
+
+// stopping notifies systemd the server is stopping before the cancel
+func stopping(cancel context.CancelFunc) context.CancelFunc {
+	return func() {
+		sdNotify("STOPPING=1")
+		cancel()
+	}
+}
+
+// watchdog pings systemd in half of the interval of its watchdog until the
+// ctx is done, the server is restarted by systemd if it hangs
+func watchdog(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			sdNotify("WATCHDOG=1")
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/urfave/cli.v2"
+
+	"github.com/wrfly/container-web-tty/config"
+	"github.com/wrfly/container-web-tty/util"
+)
+
+// secret is a secret option of the config, it's read from the env or
+// from the file of --<name>-file instead of the args, which are visible
+// to the other users of the host
+type secret struct {
+	name string
+	dst  *string
+}
+
+func secrets(conf *config.Config) []secret {
+	return []secret{
+		{"admin-token", &conf.Server.AdminToken},
+		{"cookie-keys", &conf.Server.Cookie.Keys},
+		{"credential", &conf.Server.Credential},
+		{"error-webhook", &conf.Report.Webhook},
+		{"grpc-auth", &conf.Backend.GRPC.Auth},
+		{"redis-addr", &conf.Server.RedisAddr},
+		{"sentry-dsn", &conf.Report.SentryDSN},
+		{"session-url-secret", &conf.Server.SessionURLSecret},
+		{"storage", &conf.Server.Storage},
+	}
+}
+
+// secretFileFlags are the --<name>-file flags of the secrets
+func secretFileFlags(conf *config.Config) []cli.Flag {
+	var flags []cli.Flag
+	for _, s := range secrets(conf) {
+		flags = append(flags, &cli.StringFlag{
+			Name:    s.name + "-file",
+			EnvVars: util.EnvVars(s.name + "-file"),
+			Usage:   fmt.Sprintf("file of --%s, read instead of the args", s.name),
+		})
+	}
+	return flags
+}
+
+// readSecrets reads the secrets of the files, the secrets in the args
+// are warned about, not those of the config file
+func readSecrets(c *cli.Context, conf *config.Config, fromFile map[string]bool) error {
+	for _, s := range secrets(conf) {
+		env := util.EnvVars(s.name)[0]
+		if file := c.String(s.name + "-file"); file != "" {
+			bs, err := ioutil.ReadFile(file)
+			if err != nil {
+				return fmt.Errorf("read the file of %s error: %s", s.name, err)
+			}
+			*s.dst = strings.TrimRight(string(bs), "\r\n")
+		} else if c.IsSet(s.name) && os.Getenv(env) == "" && !fromFile[s.name] {
+			logrus.Warnf("--%s in the args is visible to the other users of the host, use %s or --%s-file instead",
+				s.name, env, s.name)
+		}
+	}
+	return nil
+}
+
+// redacted returns the config without the secrets to be logged
+func redacted(conf config.Config) config.Config {
+	for _, s := range secrets(&conf) {
+		if *s.dst != "" {
+			*s.dst = "<redacted>"
+		}
+	}
+	return conf
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/wrfly/container-web-tty/config"
+)
+
+// secretField is the names of the fields of the config holding secrets
+var secretField = regexp.MustCompile(`Token|Secret|Keys|Credential|Password|DSN|Auth$|Webhook|Storage|RedisAddr`)
+
+// setSecrets sets the secret fields of the struct to the value, the names
+// of the fields set are returned
+func setSecrets(v reflect.Value, prefix, value string) []string {
+	var names []string
+	for i := 0; i < v.NumField(); i++ {
+		f, field := v.Field(i), v.Type().Field(i)
+		switch {
+		case f.Kind() == reflect.Struct && f.CanSet():
+			names = append(names, setSecrets(f, prefix+field.Name+".", value)...)
+		case f.Kind() == reflect.String && f.CanSet() && secretField.MatchString(field.Name):
+			f.SetString(value)
+			names = append(names, prefix+field.Name)
+		}
+	}
+	return names
+}
+
+func TestRedacted(t *testing.T) {
+	var conf config.Config
+	names := setSecrets(reflect.ValueOf(&conf).Elem(), "", "s3cret")
+	if len(names) < len(secrets(&conf)) {
+		t.Fatalf("unexpected secret fields: %v", names)
+	}
+
+	dump := fmt.Sprintf("%+v", redacted(conf))
+	if strings.Contains(dump, "s3cret") {
+		t.Fatalf("secrets of %v in the dump: %s", names, dump)
+	}
+	if n := strings.Count(dump, "<redacted>"); n != len(names) {
+		t.Fatalf("%d of %d secrets redacted: %s", n, len(names), dump)
+	}
+	// the config is copied
+	if conf.Server.Credential != "s3cret" {
+		t.Fatalf("the config is redacted in place: %+v", conf.Server)
+	}
+}
@@ -0,0 +1,178 @@
+package store
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	// boltValues is the bucket of the values of the keys
+	boltValues = []byte("values")
+	// boltLists is the bucket of the lists, a bucket of each key
+	boltLists = []byte("lists")
+)
+
+// Bolt is the storage in a bbolt file of a single server, the file is
+// locked by the server which opens it
+type Bolt struct {
+	db *bolt.DB
+}
+
+type boltValue struct {
+	Value    string `json:"v"`
+	ExpireAt int64  `json:"e,omitempty"` // unix ms, 0 if it never expires
+}
+
+// OpenBolt opens the file, it's created if it doesn't exist, the timeout
+// is of the lock of the file
+func OpenBolt(path string, timeout time.Duration) (*Bolt, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: timeout})
+	if err != nil {
+		return nil, fmt.Errorf("open bolt %s error: %s", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, b := range [][]byte{boltValues, boltLists} {
+			if _, err := tx.CreateBucketIfNotExists(b); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("open bolt %s error: %s", path, err)
+	}
+	return &Bolt{db: db}, nil
+}
+
+// Set sets the value of the key which expires after the ttl, the expired
+// ones are deleted
+func (s *Bolt) Set(key, value string, ttl time.Duration) error {
+	v := boltValue{Value: value}
+	if ttl > 0 {
+		v.ExpireAt = unixMilli(time.Now().Add(ttl))
+	}
+	bs, _ := json.Marshal(v)
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(boltValues)
+		now := unixMilli(time.Now())
+		var expired [][]byte
+		b.ForEach(func(k, data []byte) error {
+			var v boltValue
+			if json.Unmarshal(data, &v) == nil && v.ExpireAt > 0 && v.ExpireAt < now {
+				expired = append(expired, append([]byte(nil), k...))
+			}
+			return nil
+		})
+		for _, k := range expired {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		return b.Put([]byte(key), bs)
+	})
+}
+
+// Get returns the value of the key, false if it doesn't exist
+func (s *Bolt) Get(key string) (string, bool, error) {
+	var v boltValue
+	var ok bool
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(boltValues).Get([]byte(key))
+		if data == nil {
+			return nil
+		}
+		ok = true
+		return json.Unmarshal(data, &v)
+	})
+	if err != nil || !ok || v.ExpireAt > 0 && v.ExpireAt < unixMilli(time.Now()) {
+		return "", false, err
+	}
+	return v.Value, true, nil
+}
+
+// Del deletes the key
+func (s *Bolt) Del(key string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(boltValues).Delete([]byte(key)); err != nil {
+			return err
+		}
+		err := tx.Bucket(boltLists).DeleteBucket([]byte(key))
+		if err == bolt.ErrBucketNotFound {
+			return nil
+		}
+		return err
+	})
+}
+
+// Push appends the value to the list of the key, the values are keyed by
+// the sequence of the bucket of the key
+func (s *Bolt) Push(key, value string, max int) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.Bucket(boltLists).CreateBucketIfNotExists([]byte(key))
+		if err != nil {
+			return err
+		}
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		var id [8]byte
+		binary.BigEndian.PutUint64(id[:], seq)
+		if err := b.Put(id[:], []byte(value)); err != nil {
+			return err
+		}
+		if max <= 0 {
+			return nil
+		}
+		// the keys are the sequences of the values, the oldest first
+		if seq <= uint64(max) {
+			return nil
+		}
+		// the key of the value is kept by the transaction
+		var last [8]byte
+		binary.BigEndian.PutUint64(last[:], seq-uint64(max))
+		var old [][]byte
+		c := b.Cursor()
+		for k, _ := c.First(); k != nil && string(k) <= string(last[:]); k, _ = c.Next() {
+			old = append(old, append([]byte(nil), k...))
+		}
+		for _, k := range old {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Latest returns the latest n values of the list, newest first
+func (s *Bolt) Latest(key string, n int) ([]string, error) {
+	values := make([]string, 0, n)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(boltLists).Bucket([]byte(key))
+		if b == nil {
+			return nil
+		}
+		c := b.Cursor()
+		for k, v := c.Last(); k != nil && len(values) < n; k, v = c.Prev() {
+			values = append(values, string(v))
+		}
+		return nil
+	})
+	return values, err
+}
+
+// Ping checks the file is open
+func (s *Bolt) Ping() error {
+	return s.db.View(func(tx *bolt.Tx) error { return nil })
+}
+
+// Close closes the file
+func (s *Bolt) Close() error { return s.db.Close() }
+
+func unixMilli(t time.Time) int64 { return t.UnixNano() / int64(time.Millisecond) }
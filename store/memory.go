@@ -0,0 +1,98 @@
+package store
+
+import (
+	"sync"
+	"time"
+)
+
+// Memory is the storage in memory of a single server, lost on restart
+type Memory struct {
+	m      sync.Mutex
+	values map[string]memoryValue
+	lists  map[string][]string // oldest first
+}
+
+type memoryValue struct {
+	value    string
+	expireAt time.Time // zero if it never expires
+}
+
+// NewMemory returns an empty storage in memory
+func NewMemory() *Memory {
+	return &Memory{
+		values: make(map[string]memoryValue),
+		lists:  make(map[string][]string),
+	}
+}
+
+// Set sets the value of the key which expires after the ttl
+func (s *Memory) Set(key, value string, ttl time.Duration) error {
+	v := memoryValue{value: value}
+	if ttl > 0 {
+		v.expireAt = time.Now().Add(ttl)
+	}
+	s.m.Lock()
+	defer s.m.Unlock()
+	now := time.Now()
+	for k, v := range s.values {
+		if !v.expireAt.IsZero() && now.After(v.expireAt) {
+			delete(s.values, k)
+		}
+	}
+	s.values[key] = v
+	return nil
+}
+
+// Get returns the value of the key, false if it doesn't exist
+func (s *Memory) Get(key string) (string, bool, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	v, ok := s.values[key]
+	if !ok {
+		return "", false, nil
+	}
+	if !v.expireAt.IsZero() && time.Now().After(v.expireAt) {
+		delete(s.values, key)
+		return "", false, nil
+	}
+	return v.value, true, nil
+}
+
+// Del deletes the key
+func (s *Memory) Del(key string) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	delete(s.values, key)
+	delete(s.lists, key)
+	return nil
+}
+
+// Push appends the value to the list of the key
+func (s *Memory) Push(key, value string, max int) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	list := append(s.lists[key], value)
+	if max > 0 && len(list) > max {
+		list = append([]string(nil), list[len(list)-max:]...)
+	}
+	s.lists[key] = list
+	return nil
+}
+
+// Latest returns the latest n values of the list, newest first
+func (s *Memory) Latest(key string, n int) ([]string, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	list := s.lists[key]
+	values := make([]string, 0, n)
+	for i := len(list) - 1; i >= 0 && len(values) < n; i-- {
+		values = append(values, list[i])
+	}
+	return values, nil
+}
+
+// Ping checks nothing
+func (s *Memory) Ping() error { return nil }
+
+// Close closes nothing
+func (s *Memory) Close() error { return nil }
@@ -0,0 +1,10 @@
+package store
+
+import (
+	// the postgres driver of database/sql
+	_ "github.com/lib/pq"
+)
+
+func init() {
+	sqlDialects["postgres"] = sqlDialect{serial: "BIGSERIAL PRIMARY KEY", numbered: true}
+}
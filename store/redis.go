@@ -0,0 +1,259 @@
+// Package store is the external store shared by the replicas of the server
+package store
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Redis is a minimal client of the redis commands used by the server,
+// the commands are sent through one connection which is redialed after
+// an error
+type Redis struct {
+	addr     string
+	password string
+	db       int
+	timeout  time.Duration
+
+	m    sync.Mutex
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// NewRedis returns a client of the address, "host:port" or a URL of
+// "redis://:password@host:port/db", it connects on the first command
+func NewRedis(addr string, timeout time.Duration) (*Redis, error) {
+	r := &Redis{addr: addr, timeout: timeout}
+	if !strings.HasPrefix(addr, "redis://") {
+		return r, nil
+	}
+
+	u, err := url.Parse(addr)
+	if err != nil {
+		// not the URL, it has the password
+		if urlErr, ok := err.(*url.Error); ok {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("bad redis URL: %s", err)
+	}
+	r.addr = u.Host
+	if u.Port() == "" {
+		r.addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if u.User != nil {
+		r.password, _ = u.User.Password()
+	}
+	if db := strings.TrimPrefix(u.Path, "/"); db != "" {
+		if r.db, err = strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("bad redis db %s", db)
+		}
+	}
+	return r, nil
+}
+
+// Set sets the value of the key which expires after the ttl, never if
+// it's 0
+func (r *Redis) Set(key, value string, ttl time.Duration) error {
+	args := []string{"SET", key, value}
+	if ttl > 0 {
+		args = append(args, "PX", strconv.FormatInt(int64(ttl/time.Millisecond), 10))
+	}
+	_, err := r.do(args...)
+	return err
+}
+
+// Get returns the value of the key, false if it doesn't exist
+func (r *Redis) Get(key string) (string, bool, error) {
+	reply, err := r.do("GET", key)
+	if err != nil || reply == nil {
+		return "", false, err
+	}
+	value, ok := reply.(string)
+	if !ok {
+		return "", false, fmt.Errorf("unexpected reply of GET: %v", reply)
+	}
+	return value, true, nil
+}
+
+// Push appends the value to the list of the key, which is trimmed to the
+// latest max values
+func (r *Redis) Push(key, value string, max int) error {
+	if _, err := r.do("RPUSH", key, value); err != nil || max <= 0 {
+		return err
+	}
+	_, err := r.do("LTRIM", key, strconv.Itoa(-max), "-1")
+	return err
+}
+
+// Latest returns the latest n values of the list, newest first
+func (r *Redis) Latest(key string, n int) ([]string, error) {
+	if n <= 0 {
+		return []string{}, nil
+	}
+	reply, err := r.do("LRANGE", key, strconv.Itoa(-n), "-1")
+	if err != nil {
+		return nil, err
+	}
+	replies, ok := reply.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected reply of LRANGE: %v", reply)
+	}
+	values := make([]string, 0, len(replies))
+	for i := len(replies) - 1; i >= 0; i-- {
+		v, ok := replies[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("unexpected reply of LRANGE: %v", reply)
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// Del deletes the key
+func (r *Redis) Del(key string) error {
+	_, err := r.do("DEL", key)
+	return err
+}
+
+// Ping checks the connection
+func (r *Redis) Ping() error {
+	_, err := r.do("PING")
+	return err
+}
+
+// Close closes the connection
+func (r *Redis) Close() error {
+	r.m.Lock()
+	defer r.m.Unlock()
+	if r.conn == nil {
+		return nil
+	}
+	err := r.conn.Close()
+	r.conn = nil
+	return err
+}
+
+func (r *Redis) do(args ...string) (interface{}, error) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	if r.conn == nil {
+		if err := r.dial(); err != nil {
+			return nil, err
+		}
+	}
+	reply, err := r.roundTrip(args)
+	if _, ok := err.(redisError); err != nil && !ok {
+		// the connection is broken
+		r.conn.Close()
+		r.conn = nil
+	}
+	return reply, err
+}
+
+func (r *Redis) dial() error {
+	conn, err := net.DialTimeout("tcp", r.addr, r.timeout)
+	if err != nil {
+		return err
+	}
+	r.conn, r.r = conn, bufio.NewReader(conn)
+
+	if r.password != "" {
+		if _, err := r.roundTrip([]string{"AUTH", r.password}); err != nil {
+			r.conn.Close()
+			r.conn = nil
+			return fmt.Errorf("redis auth error: %s", err)
+		}
+	}
+	if r.db != 0 {
+		if _, err := r.roundTrip([]string{"SELECT", strconv.Itoa(r.db)}); err != nil {
+			r.conn.Close()
+			r.conn = nil
+			return fmt.Errorf("redis select db error: %s", err)
+		}
+	}
+	return nil
+}
+
+func (r *Redis) roundTrip(args []string) (interface{}, error) {
+	if r.timeout > 0 {
+		r.conn.SetDeadline(time.Now().Add(r.timeout))
+	}
+	if _, err := r.conn.Write(encodeCommand(args)); err != nil {
+		return nil, err
+	}
+	return readReply(r.r)
+}
+
+// redisError is an error reply, the connection is still fine
+type redisError string
+
+func (e redisError) Error() string { return string(e) }
+
+// encodeCommand encodes the command as an array of bulk strings
+func encodeCommand(args []string) []byte {
+	buf := make([]byte, 0, 64)
+	buf = append(buf, '*')
+	buf = strconv.AppendInt(buf, int64(len(args)), 10)
+	buf = append(buf, '\r', '\n')
+	for _, arg := range args {
+		buf = append(buf, '$')
+		buf = strconv.AppendInt(buf, int64(len(arg)), 10)
+		buf = append(buf, '\r', '\n')
+		buf = append(buf, arg...)
+		buf = append(buf, '\r', '\n')
+	}
+	return buf
+}
+
+// readReply reads a reply, the bulk strings are strings, nil if it's null
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || !strings.HasSuffix(line, "\r\n") {
+		return nil, errors.New("bad redis reply: " + strconv.Quote(line))
+	}
+	line = line[:len(line)-2]
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		replies := make([]interface{}, n)
+		for i := range replies {
+			if replies[i], err = readReply(r); err != nil {
+				return nil, err
+			}
+		}
+		return replies, nil
+	}
+	return nil, errors.New("bad redis reply: " + strconv.Quote(line))
+}
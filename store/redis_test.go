@@ -0,0 +1,148 @@
+package store
+
+import (
+	"bufio"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis serves SET, GET, DEL, RPUSH, LTRIM and LRANGE of the negative
+// indexes, PING and AUTH of one password, until it's closed
+func fakeRedis(t *testing.T, password string) (string, map[string]string, func()) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var m sync.Mutex
+	data := map[string]string{}
+	lists := map[string][]string{}
+	// the last n of the list of the negative index
+	tail := func(list []string, index string) []string {
+		n, _ := strconv.Atoi(strings.TrimPrefix(index, "-"))
+		if n < len(list) {
+			return list[len(list)-n:]
+		}
+		return list
+	}
+	serve := func(conn net.Conn) {
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		authed := password == ""
+		for {
+			reply, err := readReply(r)
+			if err != nil {
+				return
+			}
+			var args []string
+			for _, arg := range reply.([]interface{}) {
+				args = append(args, arg.(string))
+			}
+			m.Lock()
+			switch cmd := strings.ToUpper(args[0]); {
+			case cmd == "AUTH" && args[1] == password:
+				authed = true
+				conn.Write([]byte("+OK\r\n"))
+			case cmd == "AUTH":
+				conn.Write([]byte("-ERR invalid password\r\n"))
+			case !authed:
+				conn.Write([]byte("-NOAUTH Authentication required.\r\n"))
+			case cmd == "PING":
+				conn.Write([]byte("+PONG\r\n"))
+			case cmd == "SET":
+				data[args[1]] = args[2]
+				conn.Write([]byte("+OK\r\n"))
+			case cmd == "GET":
+				if v, ok := data[args[1]]; ok {
+					conn.Write(encodeCommand([]string{v})[4:])
+				} else {
+					conn.Write([]byte("$-1\r\n"))
+				}
+			case cmd == "DEL":
+				delete(data, args[1])
+				delete(lists, args[1])
+				conn.Write([]byte(":1\r\n"))
+			case cmd == "RPUSH":
+				lists[args[1]] = append(lists[args[1]], args[2])
+				conn.Write([]byte(":" + strconv.Itoa(len(lists[args[1]])) + "\r\n"))
+			case cmd == "LTRIM":
+				lists[args[1]] = tail(lists[args[1]], args[2])
+				conn.Write([]byte("+OK\r\n"))
+			case cmd == "LRANGE":
+				conn.Write(encodeCommand(tail(lists[args[1]], args[2])))
+			default:
+				conn.Write([]byte("-ERR unknown command\r\n"))
+			}
+			m.Unlock()
+		}
+	}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serve(conn)
+		}
+	}()
+	return ln.Addr().String(), data, func() { ln.Close() }
+}
+
+func TestRedis(t *testing.T) {
+	addr, _, closeRedis := fakeRedis(t, "secret")
+	defer closeRedis()
+
+	r, err := NewRedis("redis://:secret@"+addr, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if err := r.Ping(); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Set("k", "a value\r\n", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	v, ok, err := r.Get("k")
+	if err != nil || !ok || v != "a value\r\n" {
+		t.Fatalf("get k: %q %v %v", v, ok, err)
+	}
+	if err := r.Del("k"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, err := r.Get("k"); err != nil || ok {
+		t.Fatalf("get deleted k: %v %v", ok, err)
+	}
+	for _, v := range []string{"a", "b", "c"} {
+		if err := r.Push("list", v, 2); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if values, err := r.Latest("list", 10); err != nil || !reflect.DeepEqual(values, []string{"c", "b"}) {
+		t.Fatalf("latest of the list: %v %v", values, err)
+	}
+
+	// the password is not in the error of a bad URL
+	if _, err := NewRedis("redis://:secret@%zz", time.Second); err == nil || strings.Contains(err.Error(), "secret") {
+		t.Fatalf("unexpected error of a bad URL: %v", err)
+	}
+
+	// a broken connection is redialed
+	r.conn.Close()
+	if err := r.Ping(); err == nil {
+		t.Fatal("ping on a closed connection")
+	}
+	if err := r.Ping(); err != nil {
+		t.Fatal(err)
+	}
+
+	bad, _ := NewRedis("redis://:wrong@"+addr, time.Second)
+	if err := bad.Ping(); err == nil || !strings.Contains(err.Error(), "auth") {
+		t.Fatalf("ping with a wrong password: %v", err)
+	}
+}
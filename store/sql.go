@@ -0,0 +1,167 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sqlDialect is the SQL of a driver of database/sql, registered by the
+// files of the drivers
+type sqlDialect struct {
+	// the column of the ids of the lists
+	serial string
+	// the placeholders are ? in the queries, $1... of postgres
+	numbered bool
+}
+
+var sqlDialects = map[string]sqlDialect{}
+
+// SQL is the storage in the tables of a SQL database, cwt_values of the
+// values and cwt_lists of the lists, created if they don't exist
+type SQL struct {
+	db      *sql.DB
+	dialect sqlDialect
+}
+
+// OpenSQL opens the database of the driver, sqlite3 (only built with the
+// sqlite tag) or postgres
+func OpenSQL(driver, dsn string) (*SQL, error) {
+	dialect, ok := sqlDialects[driver]
+	if !ok {
+		return nil, fmt.Errorf("the %s storage isn't built in, build with -tags %s", driver, strings.TrimSuffix(driver, "3"))
+	}
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open %s error: %s", driver, err)
+	}
+	s := &SQL{db: db, dialect: dialect}
+	for _, q := range []string{
+		"CREATE TABLE IF NOT EXISTS cwt_values (k TEXT PRIMARY KEY, v TEXT NOT NULL, expire_at BIGINT NOT NULL)",
+		"CREATE TABLE IF NOT EXISTS cwt_lists (id " + dialect.serial + ", k TEXT NOT NULL, v TEXT NOT NULL)",
+		"CREATE INDEX IF NOT EXISTS cwt_lists_k ON cwt_lists (k, id)",
+	} {
+		if _, err := db.Exec(q); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("create the tables of %s error: %s", driver, err)
+		}
+	}
+	return s, nil
+}
+
+// query numbers the placeholders of the dialect
+func (s *SQL) query(q string) string {
+	if !s.dialect.numbered {
+		return q
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range q {
+		if r != '?' {
+			b.WriteRune(r)
+			continue
+		}
+		n++
+		b.WriteString("$" + strconv.Itoa(n))
+	}
+	return b.String()
+}
+
+// Set sets the value of the key which expires after the ttl, the expired
+// ones are deleted
+func (s *SQL) Set(key, value string, ttl time.Duration) error {
+	var expireAt int64
+	if ttl > 0 {
+		expireAt = unixMilli(time.Now().Add(ttl))
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(s.query("DELETE FROM cwt_values WHERE expire_at > 0 AND expire_at < ?"),
+		unixMilli(time.Now())); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(s.query("INSERT INTO cwt_values (k, v, expire_at) VALUES (?, ?, ?) "+
+		"ON CONFLICT (k) DO UPDATE SET v = excluded.v, expire_at = excluded.expire_at"),
+		key, value, expireAt); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Get returns the value of the key, false if it doesn't exist
+func (s *SQL) Get(key string) (string, bool, error) {
+	var value string
+	err := s.db.QueryRow(s.query("SELECT v FROM cwt_values WHERE k = ? AND (expire_at = 0 OR expire_at >= ?)"),
+		key, unixMilli(time.Now())).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+// Del deletes the key
+func (s *SQL) Del(key string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, q := range []string{"DELETE FROM cwt_values WHERE k = ?", "DELETE FROM cwt_lists WHERE k = ?"} {
+		if _, err := tx.Exec(s.query(q), key); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Push appends the value to the list of the key
+func (s *SQL) Push(key, value string, max int) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(s.query("INSERT INTO cwt_lists (k, v) VALUES (?, ?)"), key, value); err != nil {
+		return err
+	}
+	if max > 0 {
+		if _, err := tx.Exec(s.query("DELETE FROM cwt_lists WHERE k = ? AND id <= "+
+			"(SELECT id FROM cwt_lists WHERE k = ? ORDER BY id DESC LIMIT 1 OFFSET ?)"),
+			key, key, max); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Latest returns the latest n values of the list, newest first
+func (s *SQL) Latest(key string, n int) ([]string, error) {
+	rows, err := s.db.Query(s.query("SELECT v FROM cwt_lists WHERE k = ? ORDER BY id DESC LIMIT ?"), key, n)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	values := make([]string, 0, n)
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, rows.Err()
+}
+
+// Ping checks the connection
+func (s *SQL) Ping() error { return s.db.Ping() }
+
+// Close closes the database
+func (s *SQL) Close() error { return s.db.Close() }
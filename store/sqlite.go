@@ -0,0 +1,13 @@
+//go:build sqlite
+// +build sqlite
+
+package store
+
+import (
+	// the sqlite3 driver of database/sql, it needs cgo
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func init() {
+	sqlDialects["sqlite3"] = sqlDialect{serial: "INTEGER PRIMARY KEY AUTOINCREMENT"}
+}
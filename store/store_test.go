@@ -0,0 +1,88 @@
+package store
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStores(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cwt-store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, addr := range []string{
+		"memory://",
+		"bolt://" + filepath.Join(dir, "state.db"),
+		"sqlite://" + filepath.Join(dir, "state.sqlite"),
+	} {
+		s, err := Open(addr, time.Second)
+		if err != nil && strings.Contains(err.Error(), "isn't built in") {
+			t.Logf("skip %s: %s", addr, err)
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		testStore(t, addr, s)
+		s.Close()
+	}
+
+	if _, err := Open("localhost:6379", time.Second); err == nil {
+		t.Fatal("expect an error of the storage without a scheme")
+	}
+	if _, err := Open("mysql://localhost", time.Second); err == nil {
+		t.Fatal("expect an error of an unknown storage")
+	}
+}
+
+func testStore(t *testing.T, name string, s Store) {
+	if err := s.Ping(); err != nil {
+		t.Fatalf("%s: %s", name, err)
+	}
+	if err := s.Set("k", "v1", 0); err != nil {
+		t.Fatalf("%s: %s", name, err)
+	}
+	if err := s.Set("k", "v2", 0); err != nil {
+		t.Fatalf("%s: %s", name, err)
+	}
+	if v, ok, err := s.Get("k"); err != nil || !ok || v != "v2" {
+		t.Fatalf("%s: get k: %q %v %v", name, v, ok, err)
+	}
+	if err := s.Set("expiring", "v", 10*time.Millisecond); err != nil {
+		t.Fatalf("%s: %s", name, err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if _, ok, err := s.Get("expiring"); err != nil || ok {
+		t.Fatalf("%s: get expired key: %v %v", name, ok, err)
+	}
+	if err := s.Del("k"); err != nil {
+		t.Fatalf("%s: %s", name, err)
+	}
+	if _, ok, err := s.Get("k"); err != nil || ok {
+		t.Fatalf("%s: get deleted k: %v %v", name, ok, err)
+	}
+
+	for _, v := range []string{"a", "b", "c", "d"} {
+		if err := s.Push("list", v, 3); err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+	}
+	for n, want := range map[int][]string{
+		2:  {"d", "c"},
+		10: {"d", "c", "b"},
+	} {
+		if values, err := s.Latest("list", n); err != nil || !reflect.DeepEqual(values, want) {
+			t.Fatalf("%s: latest %d: %v %v", name, n, values, err)
+		}
+	}
+	if values, err := s.Latest("empty", 10); err != nil || len(values) != 0 {
+		t.Fatalf("%s: latest of an empty list: %v %v", name, values, err)
+	}
+}
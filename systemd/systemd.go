@@ -0,0 +1,99 @@
+// Package systemd gets the sockets passed by the socket activation of
+// systemd (LISTEN_FDS) and notifies it of the state of the server with
+// the sd_notify protocol (NOTIFY_SOCKET), without linking libsystemd
+package systemd
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// the first fd passed by systemd, after stdin, stdout and stderr
+const listenFdsStart = 3
+
+// Listener is a socket passed by systemd, the name is of FileDescriptorName=
+// of the socket unit, "unknown" by default
+type Listener struct {
+	net.Listener
+	Name string
+}
+
+// Listeners returns the sockets passed by the socket activation, none if
+// the process is not activated. The env of them is unset, so they're not
+// passed to the children.
+func Listeners() ([]Listener, error) {
+	defer func() {
+		os.Unsetenv("LISTEN_PID")
+		os.Unsetenv("LISTEN_FDS")
+		os.Unsetenv("LISTEN_FDNAMES")
+	}()
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || n <= 0 {
+		return nil, nil
+	}
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+
+	listeners := make([]Listener, 0, n)
+	for i := 0; i < n; i++ {
+		fd := listenFdsStart + i
+		name := "unknown"
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+		// the listener is of a dup of the fd, closed on exec
+		f := os.NewFile(uintptr(fd), name)
+		l, err := net.FileListener(f)
+		f.Close()
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return nil, fmt.Errorf("the socket %d (%s) of systemd is not a listener: %s", fd, name, err)
+		}
+		listeners = append(listeners, Listener{Listener: l, Name: name})
+	}
+	return listeners, nil
+}
+
+// Notify sends the state (e.g. "READY=1") to systemd, it returns false if
+// the process is not run by systemd with NOTIFY_SOCKET
+func Notify(state string) (bool, error) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return false, nil
+	}
+	if strings.HasPrefix(socket, "@") {
+		// an abstract socket
+		socket = "\x00" + socket[1:]
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// WatchdogInterval returns the interval systemd expects the "WATCHDOG=1"
+// pings in (WatchdogSec= of the unit), 0 if the watchdog is disabled
+func WatchdogInterval() time.Duration {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0
+	}
+	return time.Duration(usec) * time.Microsecond
+}
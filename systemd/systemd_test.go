@@ -0,0 +1,63 @@
+package systemd
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestNotify(t *testing.T) {
+	os.Unsetenv("NOTIFY_SOCKET")
+	if ok, err := Notify("READY=1"); ok || err != nil {
+		t.Fatalf("expect no notification without systemd, got %v, %v", ok, err)
+	}
+
+	dir, err := ioutil.TempDir("", "systemd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "notify")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	os.Setenv("NOTIFY_SOCKET", socket)
+	defer os.Unsetenv("NOTIFY_SOCKET")
+
+	if ok, err := Notify("READY=1"); !ok || err != nil {
+		t.Fatalf("notify error: %v, %v", ok, err)
+	}
+	buf := make([]byte, 64)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, err := conn.Read(buf)
+	if err != nil || string(buf[:n]) != "READY=1" {
+		t.Fatalf("unexpected state %q, %v", buf[:n], err)
+	}
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	defer os.Unsetenv("WATCHDOG_USEC")
+	defer os.Unsetenv("WATCHDOG_PID")
+	for _, tc := range []struct {
+		usec, pid string
+		interval  time.Duration
+	}{
+		{"", "", 0},
+		{"bad", "", 0},
+		{"30000000", "", 30 * time.Second},
+		{"30000000", strconv.Itoa(os.Getpid()), 30 * time.Second},
+		{"30000000", "1", 0},
+	} {
+		os.Setenv("WATCHDOG_USEC", tc.usec)
+		os.Setenv("WATCHDOG_PID", tc.pid)
+		if interval := WatchdogInterval(); interval != tc.interval {
+			t.Fatalf("expect the interval %s of %+v, got %s", tc.interval, tc, interval)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/wrfly/container-web-tty/logging"
+)
+
+var log = logging.Module("tracing")
+
+const (
+	// the spans are exported in the batches of the size or the interval,
+	// the ones beyond the queue are dropped
+	batchSize      = 512
+	exportInterval = 5 * time.Second
+	queueSize      = 4096
+)
+
+// exporter posts the ended spans to the OTLP/HTTP endpoint of a collector
+// as JSON, which the collectors accept like the protobuf
+type exporter struct {
+	url      string
+	resource resource
+	client   *http.Client
+
+	spans chan *Span
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// Setup exports the spans to the collector of the endpoint, e.g.
+// http://otel-collector:4318, as the service, ratio of the traces started
+// by this process are sampled, the traces of the clients follow their
+// sampled flag. The returned func flushes the spans and stops exporting
+func Setup(endpoint, service string, ratio float64) (func(context.Context) error, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("bad OTLP endpoint %s, must be http(s)://host:port", endpoint)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = "/v1/traces"
+	}
+	if ratio < 0 || ratio > 1 {
+		return nil, fmt.Errorf("bad trace sample ratio %v, must be 0 to 1", ratio)
+	}
+	e := &exporter{
+		url: u.String(),
+		resource: resource{Attributes: []keyValue{
+			attr("service.name", service),
+		}},
+		client: &http.Client{Timeout: 10 * time.Second},
+		spans:  make(chan *Span, queueSize),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go e.run()
+	exp, sampleRatio = e, ratio
+	return e.shutdown, nil
+}
+
+func (e *exporter) add(s *Span) {
+	select {
+	case e.spans <- s:
+	default:
+		log.Debugf("the span queue is full, dropped the span %s", s.name)
+	}
+}
+
+func (e *exporter) run() {
+	defer close(e.done)
+	ticker := time.NewTicker(exportInterval)
+	defer ticker.Stop()
+	var batch []*Span
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := e.export(batch); err != nil {
+			log.Errorf("export %d spans error: %s", len(batch), err)
+		}
+		batch = nil
+	}
+	for {
+		select {
+		case s := <-e.spans:
+			if batch = append(batch, s); len(batch) >= batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-e.stop:
+			for {
+				select {
+				case s := <-e.spans:
+					batch = append(batch, s)
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// shutdown exports the queued spans, the later ones are not
+func (e *exporter) shutdown(ctx context.Context) error {
+	close(e.stop)
+	select {
+	case <-e.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (e *exporter) export(batch []*Span) error {
+	spans := make([]span, 0, len(batch))
+	for _, s := range batch {
+		spans = append(spans, s.otlp())
+	}
+	body, err := json.Marshal(exportRequest{ResourceSpans: []resourceSpans{{
+		Resource: e.resource,
+		ScopeSpans: []scopeSpans{{
+			Scope: scope{Name: "github.com/wrfly/container-web-tty"},
+			Spans: spans,
+		}},
+	}}})
+	if err != nil {
+		return err
+	}
+	resp, err := e.client.Post(e.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s", resp.Status)
+	}
+	return nil
+}
+
+// the JSON of the OTLP ExportTraceServiceRequest, the IDs are in hex and
+// the 64-bit ints are strings
+
+type exportRequest struct {
+	ResourceSpans []resourceSpans `json:"resourceSpans"`
+}
+
+type resourceSpans struct {
+	Resource   resource     `json:"resource"`
+	ScopeSpans []scopeSpans `json:"scopeSpans"`
+}
+
+type resource struct {
+	Attributes []keyValue `json:"attributes"`
+}
+
+type scopeSpans struct {
+	Scope scope  `json:"scope"`
+	Spans []span `json:"spans"`
+}
+
+type scope struct {
+	Name string `json:"name"`
+}
+
+type span struct {
+	TraceID           string     `json:"traceId"`
+	SpanID            string     `json:"spanId"`
+	ParentSpanID      string     `json:"parentSpanId,omitempty"`
+	Name              string     `json:"name"`
+	Kind              int        `json:"kind"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string     `json:"endTimeUnixNano"`
+	Attributes        []keyValue `json:"attributes,omitempty"`
+	Status            status     `json:"status"`
+}
+
+type status struct {
+	// 0 unset, 2 error
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+type anyValue struct {
+	StringValue *string `json:"stringValue,omitempty"`
+	IntValue    *string `json:"intValue,omitempty"`
+	BoolValue   *bool   `json:"boolValue,omitempty"`
+}
+
+func attr(key string, value interface{}) keyValue {
+	kv := keyValue{Key: key}
+	switch v := value.(type) {
+	case string:
+		kv.Value.StringValue = &v
+	case int:
+		i := strconv.Itoa(v)
+		kv.Value.IntValue = &i
+	case int64:
+		i := strconv.FormatInt(v, 10)
+		kv.Value.IntValue = &i
+	case bool:
+		kv.Value.BoolValue = &v
+	default:
+		str := fmt.Sprint(v)
+		kv.Value.StringValue = &str
+	}
+	return kv
+}
+
+// otlp is the span of the JSON, it's called after the span ended
+func (s *Span) otlp() span {
+	o := span{
+		TraceID:           hex.EncodeToString(s.traceID[:]),
+		SpanID:            hex.EncodeToString(s.spanID[:]),
+		Name:              s.name,
+		Kind:              s.kind,
+		StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+	}
+	if s.parentID != [8]byte{} {
+		o.ParentSpanID = hex.EncodeToString(s.parentID[:])
+	}
+	for k, v := range s.attrs {
+		o.Attributes = append(o.Attributes, attr(k, v))
+	}
+	if s.err != "" {
+		o.Status = status{Code: 2, Message: s.err}
+	}
+	return o
+}
@@ -0,0 +1,180 @@
+// Package tracing records the spans of the requests and of the backend
+// calls and exports them to an OpenTelemetry collector by OTLP/HTTP, the
+// spans are nil and no-ops until it's set up
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	mrand "math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// the kinds of the spans of OTLP
+const (
+	KindInternal = 1
+	KindServer   = 2
+	KindClient   = 3
+)
+
+const traceparentHeader = "traceparent"
+
+var (
+	exp *exporter
+	// the ratio of the traces started by this process that are sampled
+	sampleRatio float64
+)
+
+// Enabled reports whether the spans are exported
+func Enabled() bool {
+	return exp != nil
+}
+
+// Span is an operation of a trace, a nil Span is a no-op
+type Span struct {
+	traceID  [16]byte
+	spanID   [8]byte
+	parentID [8]byte
+	name     string
+	kind     int
+	start    time.Time
+
+	m     sync.Mutex
+	end   time.Time
+	attrs map[string]interface{}
+	err   string
+	ended bool
+	// the remote parent of an unsampled traceparent, its children are
+	// not recorded
+	unsampled bool
+}
+
+type spanKey struct{}
+
+// FromContext returns the span of ctx, nil if there is none
+func FromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(spanKey{}).(*Span)
+	return s
+}
+
+// WithSpan returns ctx with the span, e.g. a context outliving the
+// request of the span, ctx if it's nil
+func WithSpan(ctx context.Context, s *Span) context.Context {
+	if s == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, spanKey{}, s)
+}
+
+// Start starts the span of the name as a child of the span of ctx, or of a
+// new trace, the span is nil if it's not sampled
+func Start(ctx context.Context, name string, kind int) (context.Context, *Span) {
+	if exp == nil {
+		return ctx, nil
+	}
+	s := &Span{
+		name:  name,
+		kind:  kind,
+		start: time.Now(),
+		attrs: make(map[string]interface{}),
+	}
+	if parent := FromContext(ctx); parent != nil {
+		if parent.unsampled {
+			return ctx, nil
+		}
+		s.traceID = parent.traceID
+		s.parentID = parent.spanID
+	} else {
+		if mrand.Float64() >= sampleRatio {
+			return ctx, nil
+		}
+		rand.Read(s.traceID[:])
+	}
+	rand.Read(s.spanID[:])
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// SetAttr sets the attribute of the span, the value is a string, an
+// int or a bool
+func (s *Span) SetAttr(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.m.Lock()
+	defer s.m.Unlock()
+	if !s.ended {
+		s.attrs[key] = value
+	}
+}
+
+// SetError marks the span failed of the error, nil is ignored
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.m.Lock()
+	defer s.m.Unlock()
+	if !s.ended {
+		s.err = err.Error()
+	}
+}
+
+// End ends the span and queues it to be exported, it's ended once
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.m.Lock()
+	if s.ended {
+		s.m.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = time.Now()
+	s.m.Unlock()
+	if exp != nil {
+		exp.add(s)
+	}
+}
+
+// TraceID returns the ID of the trace in hex, empty for nil
+func (s *Span) TraceID() string {
+	if s == nil {
+		return ""
+	}
+	return hex.EncodeToString(s.traceID[:])
+}
+
+// Extract returns ctx with the remote parent span of the traceparent
+// header (W3C Trace Context), ctx if it has none or a bad one
+func Extract(ctx context.Context, header http.Header) context.Context {
+	if exp == nil {
+		return ctx
+	}
+	parts := strings.Split(header.Get(traceparentHeader), "-")
+	if len(parts) != 4 || len(parts[0]) != 2 || parts[0] == "ff" ||
+		len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return ctx
+	}
+	s := &Span{}
+	flags, err1 := hex.DecodeString(parts[3])
+	_, err2 := hex.Decode(s.traceID[:], []byte(parts[1]))
+	_, err3 := hex.Decode(s.spanID[:], []byte(parts[2]))
+	if err1 != nil || err2 != nil || err3 != nil || s.traceID == [16]byte{} || s.spanID == [8]byte{} {
+		return ctx
+	}
+	s.unsampled = flags[0]&1 == 0
+	return context.WithValue(ctx, spanKey{}, s)
+}
+
+// Inject sets the traceparent header of the span of ctx
+func Inject(ctx context.Context, header http.Header) {
+	if s := FromContext(ctx); s != nil && !s.unsampled {
+		header.Set(traceparentHeader, fmt.Sprintf("00-%x-%x-01", s.traceID, s.spanID))
+	}
+}
@@ -0,0 +1,110 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTracing(t *testing.T) {
+	var (
+		m        sync.Mutex
+		exported []span
+		paths    []string
+	)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		var req exportRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Errorf("bad export request: %s", err)
+		}
+		m.Lock()
+		defer m.Unlock()
+		paths = append(paths, r.URL.Path)
+		for _, rs := range req.ResourceSpans {
+			if v := rs.Resource.Attributes[0].Value.StringValue; v == nil || *v != "test" {
+				t.Errorf("bad service name of %s", body)
+			}
+			for _, ss := range rs.ScopeSpans {
+				exported = append(exported, ss.Spans...)
+			}
+		}
+	}))
+	defer collector.Close()
+
+	if _, s := Start(context.Background(), "disabled", KindInternal); s != nil {
+		t.Fatal("expect no span before the setup")
+	}
+	if _, err := Setup("ftp://collector", "test", 1); err == nil {
+		t.Fatal("expect an error of a bad endpoint")
+	}
+	if _, err := Setup(collector.URL, "test", 2); err == nil {
+		t.Fatal("expect an error of a bad ratio")
+	}
+	shutdown, err := Setup(collector.URL, "test", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { exp = nil }()
+
+	header := http.Header{}
+	header.Set("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	ctx, server := Start(Extract(context.Background(), header), "HTTP GET", KindServer)
+	if got := server.TraceID(); got != "0af7651916cd43dd8448eb211c80319c" {
+		t.Fatalf("expect the trace of the traceparent, got %s", got)
+	}
+	_, child := Start(ctx, "container.inspect", KindInternal)
+	child.SetAttr("container.id", "abc")
+	child.SetError(errors.New("no such container"))
+	child.End()
+	server.SetAttr("http.status_code", 200)
+	server.End()
+	server.End()
+
+	out := http.Header{}
+	Inject(ctx, out)
+	if got := out.Get("traceparent"); got != "00-0af7651916cd43dd8448eb211c80319c-"+
+		exportedID(server)+"-01" {
+		t.Fatalf("bad injected traceparent %s", got)
+	}
+
+	// the children of an unsampled client are not recorded
+	header.Set("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-00")
+	if _, s := Start(Extract(context.Background(), header), "HTTP GET", KindServer); s != nil {
+		t.Fatal("expect no span of an unsampled parent")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	m.Lock()
+	defer m.Unlock()
+	if len(paths) != 1 || paths[0] != "/v1/traces" {
+		t.Fatalf("expect the spans exported once to /v1/traces, got %v", paths)
+	}
+	if len(exported) != 2 {
+		t.Fatalf("expect 2 spans, got %d", len(exported))
+	}
+	inspect, srv := exported[0], exported[1]
+	if inspect.Name != "container.inspect" || inspect.ParentSpanID != srv.SpanID ||
+		inspect.Status.Code != 2 || inspect.Status.Message != "no such container" {
+		t.Fatalf("bad child span %+v", inspect)
+	}
+	if srv.ParentSpanID != "b7ad6b7169203331" || srv.Kind != KindServer ||
+		*srv.Attributes[0].Value.IntValue != "200" {
+		t.Fatalf("bad server span %+v", srv)
+	}
+}
+
+func exportedID(s *Span) string {
+	return s.otlp().SpanID
+}
@@ -6,7 +6,7 @@ import (
 	"sync"
 	"time"
 
-	"github.com/yudai/gotty/webtty"
+	"github.com/wrfly/container-web-tty/webtty"
 )
 
 // TTY is webtty.Slave with some additional methods.
@@ -15,6 +15,12 @@ type TTY interface {
 	Exit() error
 	// ActiveChan is to notify that the connection is active
 	ActiveChan() <-chan struct{}
+	// Stderr returns the separate stderr stream of a non-tty exec,
+	// it's nil when the exec is attached to a tty
+	Stderr() io.Reader
+	// ExitCode waits for the exec to exit and returns its exit code,
+	// it should be called after the output is closed
+	ExitCode() (int, error)
 }
 
 type ShareTTY struct {
@@ -25,9 +31,14 @@ type ShareTTY struct {
 
 func (t *ShareTTY) Read(p []byte) (n int, err error) {
 	n, e := t.TTY.Read(p)
-	bs := make([]byte, n)
-	copy(bs, p[:n])
-	go t.writeShares(bs)
+	t.m.Lock()
+	shared := len(t.shares) != 0
+	t.m.Unlock()
+	if shared {
+		bs := webtty.GetBuffer(n)
+		copy(*bs, p[:n])
+		go t.writeShares(bs)
+	}
 	return n, e
 }
 
@@ -61,12 +72,15 @@ func (t *ShareTTY) Fork(clientIP string) io.ReadCloser {
 	return &s
 }
 
-func (t *ShareTTY) writeShares(bs []byte) {
+// writeShares writes the output to the forks, the buffer is put back to
+// the pool after it's read by all of them
+func (t *ShareTTY) writeShares(bs *[]byte) {
 	t.m.Lock()
 	for _, s := range t.shares {
-		s.pw.Write(bs)
+		s.pw.Write(*bs)
 	}
 	t.m.Unlock()
+	webtty.PutBuffer(bs)
 }
 
 func NewShareTTY(t TTY) *ShareTTY {
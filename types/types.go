@@ -1,5 +1,10 @@
 package types
 
+import (
+	"fmt"
+	"time"
+)
+
 // Container instance
 type Container struct {
 	// common
@@ -8,6 +13,7 @@ type Container struct {
 	State, Status  string // "running"  "Up 13 minutes"
 	IPs            []string
 	Shell          string
+	Labels         map[string]string
 
 	// k8s
 	PodName, ContainerName string
@@ -24,6 +30,190 @@ type Container struct {
 	Exec ExecOptions
 }
 
+// EnvVar is an environment variable of a container, the value
+// is hidden if Masked is true
+type EnvVar struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Masked bool   `json:"masked,omitempty"`
+}
+
+// Mount is a volume or a bind mount of a container
+type Mount struct {
+	Type        string `json:"type"`
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	ReadOnly    bool   `json:"read_only"`
+}
+
+// VolumeEntry is a file, a directory or a symlink in a volume,
+// the size of a directory is the total size of its files
+type VolumeEntry struct {
+	Name string `json:"name"`
+	// file, dir or symlink
+	Type    string    `json:"type"`
+	Size    int64     `json:"size"`
+	Mode    string    `json:"mode"`
+	ModTime time.Time `json:"mod_time"`
+	// the target of a symlink, it's never followed
+	Link string `json:"link,omitempty"`
+}
+
+// VolumeListing is the entries of a directory of a volume, Truncated is
+// true if the volume is too large to be scanned, the entries are partial
+type VolumeListing struct {
+	Volume    string        `json:"volume"`
+	Path      string        `json:"path"`
+	Entries   []VolumeEntry `json:"entries"`
+	Truncated bool          `json:"truncated,omitempty"`
+}
+
+// ContainerDetail is the environment variables, mounts
+// and healthcheck of a container
+type ContainerDetail struct {
+	Env    []EnvVar `json:"env"`
+	Mounts []Mount  `json:"mounts"`
+	// nil if the container has no healthcheck
+	Health *Health `json:"health,omitempty"`
+}
+
+// Health is the healthcheck of a container
+type Health struct {
+	// the command of the healthcheck run by the shell, empty if
+	// it cannot be run by exec (e.g. an HTTP probe of kube)
+	Cmd string `json:"cmd"`
+	// starting, healthy or unhealthy
+	Status        string        `json:"status"`
+	FailingStreak int           `json:"failing_streak"`
+	Probes        []HealthProbe `json:"probes"` // oldest first
+}
+
+// HealthProbe is a result of the healthcheck, the exit code 0 is healthy
+type HealthProbe struct {
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	ExitCode int       `json:"exit_code"`
+	Output   string    `json:"output"`
+}
+
+// Change is a file changed by the container, Kind is "A" (added),
+// "C" (changed) or "D" (deleted)
+type Change struct {
+	Kind string `json:"kind"`
+	Path string `json:"path"`
+}
+
+// Processes is the process list of a container, like the output of ps
+type Processes struct {
+	Titles    []string   `json:"titles"`
+	Processes [][]string `json:"processes"`
+}
+
+// CommitOptions is the request to commit a container to an image
+type CommitOptions struct {
+	// reference of the new image, e.g. "debug/web:before-fix"
+	Image   string `json:"image"`
+	Comment string `json:"comment"`
+	// pause the container while committing
+	Pause bool `json:"pause"`
+}
+
+// CommitResult is the image committed from a container
+type CommitResult struct {
+	ID    string `json:"id"`
+	Image string `json:"image"`
+}
+
+// DebugOptions is the debug container launched for a container
+type DebugOptions struct {
+	// the toolbox image of the debug container
+	Image string `json:"image"`
+}
+
+// DebugResult is the debug container launched for a container
+type DebugResult struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+}
+
+// SessionURL is the exec URL of a container, it expires at ExpireAt
+// if the URLs are signed
+type SessionURL struct {
+	URL      string     `json:"url"`
+	ExpireAt *time.Time `json:"expire_at,omitempty"`
+}
+
+// CreateOptions is the request to create and start a container,
+// like docker run -it
+type CreateOptions struct {
+	Image string   `json:"image"`
+	Name  string   `json:"name,omitempty"`
+	Cmd   []string `json:"cmd,omitempty"`
+	// KEY=value
+	Env []string `json:"env,omitempty"`
+	// published ports, [ip:][hostPort:]containerPort[/protocol] like docker run -p
+	Ports []string `json:"ports,omitempty"`
+	// volumes, source:destination[:ro] like docker run -v
+	Volumes []string `json:"volumes,omitempty"`
+	// location server of the grpc backend, required if there're more than one
+	Server string `json:"server,omitempty"`
+}
+
+// CreateResult is the container created, the terminal of it is at URL
+type CreateResult struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+}
+
+// CopyPath is a path in a container
+type CopyPath struct {
+	ID   string `json:"id"`
+	Path string `json:"path"`
+}
+
+// CopyOptions is the request to copy a file or a directory of a
+// container into a directory of another one (or the same one)
+type CopyOptions struct {
+	From CopyPath `json:"from"`
+	To   CopyPath `json:"to"`
+}
+
+// CopyResult is the size of the tar archive copied
+type CopyResult struct {
+	Bytes int64 `json:"bytes"`
+}
+
+// PruneKinds are the kinds of the unused resources to be pruned
+var PruneKinds = []string{"containers", "images", "volumes"}
+
+// PruneItem is a resource to be pruned, or pruned
+type PruneItem struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+	// bytes, 0 if it's unknown
+	Size int64 `json:"size"`
+}
+
+// PruneReport is the resources pruned, or to be pruned if DryRun is true,
+// Reclaimed is the estimated bytes of a dry run
+type PruneReport struct {
+	Kind      string      `json:"kind"`
+	DryRun    bool        `json:"dryRun"`
+	Items     []PruneItem `json:"items"`
+	Reclaimed uint64      `json:"reclaimed"`
+}
+
+// RenameOptions is the new name of a container
+type RenameOptions struct {
+	Name string `json:"name"`
+}
+
+// LabelsUpdate adds labels of Set and removes labels of Remove
+type LabelsUpdate struct {
+	Set    map[string]string `json:"set,omitempty"`
+	Remove []string          `json:"remove,omitempty"`
+}
+
 // ContainerActionMessage tells the web browser the action's status
 type ContainerActionMessage struct {
 	Error   string `json:"err"`
@@ -31,15 +221,71 @@ type ContainerActionMessage struct {
 	Message string `json:"msg"`
 }
 
+// APIError is the error envelope of the JSON APIs,
+// Code is the HTTP status code
+type APIError struct {
+	Code      int    `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id"`
+}
+
+func (e APIError) Error() string {
+	return fmt.Sprintf("%s (code %d, request %s)", e.Message, e.Code, e.RequestID)
+}
+
+// SessionError is the failure of a terminal session sent to the browser
+// before closing, the Code tells the failures apart
+type SessionError struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+func (e SessionError) Error() string {
+	return fmt.Sprintf("%s (%s, request %s)", e.Message, e.Code, e.RequestID)
+}
+
+// The codes of SessionError
+const (
+	// the container is removed or stopped
+	SessionContainerGone = "container_gone"
+	// the backend denies the exec, e.g. an unknown user or no permission
+	SessionExecDenied = "exec_denied"
+	// the backend is unreachable, e.g. the docker daemon is down
+	SessionBackendDown = "backend_down"
+	// the init message is not authenticated
+	SessionUnauthorized = "unauthorized"
+	// bad arguments of the init message
+	SessionBadRequest = "bad_request"
+	// no shell is found in the container
+	SessionNoShell = "no_shell"
+	// the other failures of the exec
+	SessionExecFailed = "exec_failed"
+)
+
+// Page is a page of a list API, pass NextCursor as the
+// "cursor" argument to get the next page, it's empty at the end
+type Page struct {
+	Items      interface{} `json:"items"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
 type InitMessage struct {
 	Arguments string `json:"Arguments,omitempty"`
 	AuthToken string `json:"AuthToken,omitempty"`
+	// resumes a detached session instead of starting a new exec
+	ResumeToken string `json:"ResumeToken,omitempty"`
 }
 
 type LogOptions struct {
 	ID     string
 	Follow bool
 	Tail   string
+	// only the logs after Since if it's not zero
+	Since time.Time
+	// only the logs before Until if it's not zero
+	Until      time.Time
+	Timestamps bool
 }
 
 type ContainerAct int
@@ -55,4 +301,292 @@ type ExecOptions struct {
 	Cmd  string
 	// alias as `p`
 	Privileged bool
+	// run the command without a tty (command mode),
+	// the stderr will be a separate stream
+	NoTTY bool
+	// attach to the main process instead of starting a new one,
+	// the input is sent only if AttachStdin is true
+	Attach      bool
+	AttachStdin bool
+}
+
+// RunOptions is the request to run a one-shot command in a container
+type RunOptions struct {
+	Cmd  string `json:"cmd"`
+	Env  string `json:"env"`
+	User string `json:"user"`
+	// timeout of the command, e.g. "10s"
+	Timeout string `json:"timeout"`
+}
+
+// MaxRunOutput is the max bytes kept of stdout or stderr of a one-shot command
+const MaxRunOutput = 1 << 20
+
+// Stats is a sample of the resource usage of a container, the network
+// and block IO are the total bytes, fields not known by the backend are 0
+type Stats struct {
+	Time time.Time `json:"time"`
+	// 100 is a whole CPU core
+	CPUPercent  float64 `json:"cpu_percent"`
+	MemoryUsage uint64  `json:"memory_usage"`
+	MemoryLimit uint64  `json:"memory_limit"`
+	NetworkRx   uint64  `json:"network_rx"`
+	NetworkTx   uint64  `json:"network_tx"`
+	BlockRead   uint64  `json:"block_read"`
+	BlockWrite  uint64  `json:"block_write"`
+	PIDs        uint64  `json:"pids"`
+}
+
+// RunResult is the result of a one-shot command
+type RunResult struct {
+	Stdout   string `json:"stdout"`
+	Stderr   string `json:"stderr"`
+	ExitCode int    `json:"exit_code"`
+	// the outputs exceed the limit and are truncated
+	Truncated bool   `json:"truncated"`
+	Duration  string `json:"duration"`
+}
+
+// BatchRunOptions is the request to run a one-shot command in
+// all the containers matching the label selector
+type BatchRunOptions struct {
+	RunOptions
+	// e.g. "app=web,tier!=db,canary,!legacy"
+	Selector string `json:"selector"`
+	// max commands running at the same time,
+	// the server's --batch-concurrency is the upper limit
+	Concurrency int `json:"concurrency"`
+}
+
+// BatchResult is the result of a batch run in one container,
+// Error is set if the command cannot be run
+type BatchResult struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	LocServer string     `json:"loc_server,omitempty"`
+	Result    *RunResult `json:"result,omitempty"`
+	Error     string     `json:"err,omitempty"`
+}
+
+// ProvisionOptions is the request to start a terminal session to be joined later
+type ProvisionOptions struct {
+	Cmd  string `json:"cmd"`
+	Env  string `json:"env"`
+	User string `json:"user"`
+	// how long the session waits to be joined, e.g. "5m"
+	TTL string `json:"ttl"`
+}
+
+// ProvisionResult is a provisioned session, JoinURL can only be opened once
+type ProvisionResult struct {
+	SessionID string    `json:"session_id"`
+	JoinURL   string    `json:"join_url"`
+	ExpireAt  time.Time `json:"expire_at"`
+}
+
+// ForwardOptions is the request of a URL forwarded to a port of a container
+type ForwardOptions struct {
+	// how long the URL is valid, e.g. "30m"
+	TTL string `json:"ttl"`
+}
+
+// ForwardResult is a temporary URL forwarded to a port of a container, a
+// path without the --external-url or --forward-url of the server
+type ForwardResult struct {
+	URL      string    `json:"url"`
+	ExpireAt time.Time `json:"expire_at"`
+}
+
+// Session is a terminal session exec'ed into a container
+type Session struct {
+	ID          string    `json:"id"`
+	ContainerID string    `json:"container_id"`
+	Client      string    `json:"client"`
+	Cmd         string    `json:"cmd,omitempty"`
+	User        string    `json:"user,omitempty"` // the exec user
+	StartAt     time.Time `json:"start_at"`
+	// the bytes read from and written to the browsers, the messages of
+	// the websockets (or the SSE streams) by now
+	BytesIn  int64 `json:"bytes_in"`
+	BytesOut int64 `json:"bytes_out"`
+	// the fields below are set when the session is closed
+	EndAt    *time.Time `json:"end_at,omitempty"`
+	ExitCode *int       `json:"exit_code,omitempty"`
+	Reason   string     `json:"reason,omitempty"`
+}
+
+// Usage is the rollup of the terminal sessions started in a day (UTC) by
+// an exec user in a container, or of all the containers (users) of it in
+// a report by the user (container)
+type Usage struct {
+	Day         string `json:"day"`
+	User        string `json:"user,omitempty"`
+	ContainerID string `json:"container_id,omitempty"`
+	Sessions    int    `json:"sessions"`
+	// the total duration of the sessions
+	Seconds  float64 `json:"seconds"`
+	BytesIn  int64   `json:"bytes_in"`
+	BytesOut int64   `json:"bytes_out"`
+}
+
+// Event is a state change of a container reported by the backend
+type Event struct {
+	// e.g. start, die, oom, destroy
+	Action    string    `json:"action"`
+	ID        string    `json:"id"`
+	Name      string    `json:"name,omitempty"`
+	LocServer string    `json:"loc_server,omitempty"`
+	Time      time.Time `json:"time"`
+	// e.g. the exit code of die, the status of health_status
+	Detail string `json:"detail,omitempty"`
+}
+
+// ServerEvent is an event of the server itself in its journal, e.g. a
+// start, an outage of the backend or the summary of a session
+type ServerEvent struct {
+	ID      int64             `json:"id"`
+	Time    time.Time         `json:"time"`
+	Kind    string            `json:"kind"`
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields,omitempty"`
+}
+
+// DrainStatus is the drain of the server before it exits, the sessions
+// are the connected and the detached ones
+type DrainStatus struct {
+	Draining bool       `json:"draining"`
+	Deadline *time.Time `json:"deadline,omitempty"`
+	Sessions int        `json:"sessions"`
+}
+
+// AdminToken is a token of the admin API created by it, the token itself
+// is only returned once by the creation, then only its hash is kept
+type AdminToken struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name,omitempty"`
+	Token     string     `json:"token,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// AdminTokenOptions are the name and the TTL (e.g. 24h, empty to never
+// expire) of a new admin token
+type AdminTokenOptions struct {
+	Name string `json:"name"`
+	TTL  string `json:"ttl,omitempty"`
+}
+
+// Maintenance is the maintenance mode of the server, the new sessions
+// are refused with the message, the others and the pages still work
+type Maintenance struct {
+	Enabled bool       `json:"enabled"`
+	Message string     `json:"message,omitempty"`
+	Since   *time.Time `json:"since,omitempty"`
+}
+
+// HealthStatus is the response of /healthz and /readyz,
+// Checks maps the name of a check to "ok" or its error
+type HealthStatus struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// ErrorReport is a panic recovered from a request, with the request but
+// none of its secrets (the headers and the tokens of the path)
+type ErrorReport struct {
+	ID        string    `json:"id"`
+	Time      time.Time `json:"time"`
+	Message   string    `json:"message"`
+	Stack     string    `json:"stack"`
+	RequestID string    `json:"request_id,omitempty"`
+	Method    string    `json:"method,omitempty"`
+	Path      string    `json:"path,omitempty"`
+	Client    string    `json:"client,omitempty"`
+	UserAgent string    `json:"user_agent,omitempty"`
+}
+
+// LogLevels are the levels of the logs, the level of the standard logger
+// and the modules' own levels, the other modules follow the standard one
+type LogLevels struct {
+	Level   string            `json:"level"`
+	Modules map[string]string `json:"modules"`
+}
+
+// LogDebug logs the requests and the sessions of the field (user, client
+// or container) of the value at the debug level for the duration
+type LogDebug struct {
+	Field string `json:"field"`
+	Value string `json:"value"`
+	// e.g. 10m, at most 24h
+	Duration string    `json:"duration,omitempty"`
+	Until    time.Time `json:"until"`
+}
+
+// SetupConfig is the first-run config submitted on the setup page, the
+// admin token is generated if it's empty
+type SetupConfig struct {
+	// the setup code of the logs, only in the request
+	Code        string   `json:"code,omitempty"`
+	AdminToken  string   `json:"admin_token"`
+	Backend     string   `json:"backend"`
+	DockerHost  string   `json:"docker_host,omitempty"`
+	KubeConfig  string   `json:"kube_config,omitempty"`
+	GRPCServers []string `json:"grpc_servers,omitempty"`
+	Address     string   `json:"address"`
+	Port        int      `json:"port"`
+	// the options (their JSON names) set by the args or the env, which are
+	// kept, only in the defaults
+	Fixed []string `json:"fixed,omitempty"`
+}
+
+// BackendVersion is the version of a backend, the upstream servers of the
+// grpc backend are listed without their versions
+type BackendVersion struct {
+	Name       string `json:"name"`
+	Version    string `json:"version,omitempty"`
+	APIVersion string `json:"api_version,omitempty"`
+	// os/arch of the docker daemon or of the kubernetes API server
+	Platform string `json:"platform,omitempty"`
+	// the address of an upstream server of grpc
+	Server string `json:"server,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Version is the build of the server and the versions of the backends
+type Version struct {
+	Version   string           `json:"version"`
+	CommitID  string           `json:"commit"`
+	BuildAt   string           `json:"build_at"`
+	GoVersion string           `json:"go_version"`
+	Backends  []BackendVersion `json:"backends"`
+}
+
+// UpdateCheck is the latest release found by the update check, CheckedAt
+// is zero until the first check
+type UpdateCheck struct {
+	Current   string    `json:"current"`
+	Latest    string    `json:"latest,omitempty"`
+	URL       string    `json:"url,omitempty"`
+	Available bool      `json:"available"`
+	CheckedAt time.Time `json:"checked_at"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// the sources of the effective options
+const (
+	SourceDefault    = "default"
+	SourceFile       = "file"
+	SourceEnv        = "env"
+	SourceFlag       = "flag"
+	SourceSecretFile = "secret-file"
+)
+
+// ConfigOption is an option of the effective config by the name of its flag,
+// the value after the defaults, the config file, the env and the args, and
+// the source of it, the secrets are redacted
+type ConfigOption struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+	Env    string `json:"env,omitempty"`
 }
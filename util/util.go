@@ -1,6 +1,7 @@
 package util
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"os"
@@ -30,10 +31,15 @@ func ConvertPbContainer(c *pb.Container) types.Container {
 		Namespace:     c.Namespace,
 		RunningNode:   c.RunningNode,
 		LocServer:     c.LocServer,
+		Labels:        c.Labels,
 		Exec: types.ExecOptions{
-			Cmd:  c.ExecCmd,
-			Env:  c.ExecEnv,
-			User: c.ExecUser,
+			Cmd:   c.ExecCmd,
+			Env:   c.ExecEnv,
+			User:  c.ExecUser,
+			NoTTY: c.ExecNoTTY,
+
+			Attach:      c.ExecAttach,
+			AttachStdin: c.ExecAttachStdin,
 		},
 	}
 }
@@ -41,22 +47,26 @@ func ConvertPbContainer(c *pb.Container) types.Container {
 // ConvertTpContainer types.Container -> *pb.Container
 func ConvertTpContainer(c types.Container) *pb.Container {
 	return &pb.Container{
-		Id:            c.ID,
-		Name:          c.Name,
-		Image:         c.Image,
-		Command:       c.Command,
-		State:         c.State,
-		Status:        c.Status,
-		Ips:           c.IPs,
-		Shell:         c.Shell,
-		PodName:       c.PodName,
-		ContainerName: c.ContainerName,
-		Namespace:     c.Namespace,
-		RunningNode:   c.RunningNode,
-		LocServer:     c.LocServer,
-		ExecCmd:       c.Exec.Cmd,
-		ExecEnv:       c.Exec.Env,
-		ExecUser:      c.Exec.User,
+		Id:              c.ID,
+		Name:            c.Name,
+		Image:           c.Image,
+		Command:         c.Command,
+		State:           c.State,
+		Status:          c.Status,
+		Ips:             c.IPs,
+		Shell:           c.Shell,
+		PodName:         c.PodName,
+		ContainerName:   c.ContainerName,
+		Namespace:       c.Namespace,
+		RunningNode:     c.RunningNode,
+		LocServer:       c.LocServer,
+		ExecCmd:         c.Exec.Cmd,
+		ExecEnv:         c.Exec.Env,
+		ExecUser:        c.Exec.User,
+		ExecNoTTY:       c.Exec.NoTTY,
+		ExecAttach:      c.Exec.Attach,
+		ExecAttachStdin: c.Exec.AttachStdin,
+		Labels:          c.Labels,
 	}
 }
 
@@ -87,7 +97,10 @@ func EnvVars(e string) []string {
 	return []string{"WEB_TTY_" + strings.Replace(e, "-", "_", -1)}
 }
 
-func WaitSignals(errs chan error, cancel context.CancelFunc, gracefullCancel context.CancelFunc) error {
+// WaitSignals waits for the errors and the signals, SIGINT shuts down
+// gracefully, SIGTERM drains if drainCancel is not nil, a second
+// signal cancels at once
+func WaitSignals(errs chan error, cancel, gracefullCancel, drainCancel context.CancelFunc) error {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(
 		sigChan,
@@ -111,9 +124,90 @@ func WaitSignals(errs chan error, cancel context.CancelFunc, gracefullCancel con
 				cancel()
 				return nil
 			}
+		case syscall.SIGTERM:
+			if drainCancel == nil {
+				cancel()
+				return nil
+			}
+			drainCancel()
+			select {
+			case err := <-errs:
+				return err
+			case <-sigChan:
+				fmt.Println("Force closing...")
+				cancel()
+				return nil
+			}
 		default:
 			cancel()
 			return nil
 		}
 	}
 }
+
+// CappedBuffer is a bytes.Buffer which discards the bytes
+// beyond its capacity
+type CappedBuffer struct {
+	bytes.Buffer
+	cap       int
+	Truncated bool
+}
+
+// NewCappedBuffer returns a buffer which holds at most cap bytes
+func NewCappedBuffer(cap int) *CappedBuffer {
+	return &CappedBuffer{cap: cap}
+}
+
+func (b *CappedBuffer) Write(p []byte) (int, error) {
+	if left := b.cap - b.Len(); len(p) > left {
+		b.Truncated = true
+		if left > 0 {
+			b.Buffer.Write(p[:left])
+		}
+		// pretend to write all of them
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
+
+// ParsePS parses the output of ps, the last column (the command)
+// may have spaces
+func ParsePS(out string) types.Processes {
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	ps := types.Processes{
+		Titles:    strings.Fields(lines[0]),
+		Processes: [][]string{},
+	}
+	n := len(ps.Titles)
+	if n == 0 {
+		return ps
+	}
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) > n {
+			fields = append(fields[:n-1], strings.Join(fields[n-1:], " "))
+		}
+		ps.Processes = append(ps.Processes, fields)
+	}
+	return ps
+}
+
+// ShellJoin joins the arguments into a command of the shell,
+// the arguments are quoted if they have special characters
+func ShellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg != "" && strings.IndexFunc(arg, func(r rune) bool {
+			return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' ||
+				strings.ContainsRune("-_./=:,@%+", r))
+		}) < 0 {
+			quoted[i] = arg
+			continue
+		}
+		quoted[i] = "'" + strings.Replace(arg, "'", `'"'"'`, -1) + "'"
+	}
+	return strings.Join(quoted, " ")
+}
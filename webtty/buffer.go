@@ -0,0 +1,35 @@
+package webtty
+
+import (
+	"sync"
+)
+
+// maxPooledBuffer is the max capacity of the buffers put back to the
+// pool, the larger ones are left to the GC
+const maxPooledBuffer = 64 << 10
+
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		b := make([]byte, 0, 4<<10)
+		return &b
+	},
+}
+
+// GetBuffer returns a buffer of the length n from the pool,
+// it should be put back by PutBuffer once it's not used
+func GetBuffer(n int) *[]byte {
+	b := bufferPool.Get().(*[]byte)
+	if cap(*b) < n {
+		*b = make([]byte, n)
+	}
+	*b = (*b)[:n]
+	return b
+}
+
+// PutBuffer puts the buffer back to the pool
+func PutBuffer(b *[]byte) {
+	if cap(*b) > maxPooledBuffer {
+		return
+	}
+	bufferPool.Put(b)
+}
@@ -0,0 +1,169 @@
+package webtty
+
+import (
+	"bytes"
+	"encoding/base64"
+)
+
+// ClipboardPolicy tells what is done with the OSC 52 sequences of the
+// slave setting the clipboard of the master
+type ClipboardPolicy string
+
+const (
+	// The sequences are dropped
+	ClipboardOff ClipboardPolicy = "off"
+	// The master sets the clipboard
+	ClipboardOn ClipboardPolicy = "on"
+	// The master asks the user before setting the clipboard
+	ClipboardAsk ClipboardPolicy = "ask"
+)
+
+// ParseClipboardPolicy parses the policy, empty for off
+func ParseClipboardPolicy(s string) (ClipboardPolicy, bool) {
+	switch p := ClipboardPolicy(s); p {
+	case "":
+		return ClipboardOff, true
+	case ClipboardOff, ClipboardOn, ClipboardAsk:
+		return p, true
+	}
+	return "", false
+}
+
+var osc52 = []byte("\x1b]52;")
+
+// clipboardFilter takes the OSC 52 sequences out of the output of the
+// slave, also the ones split across the reads
+type clipboardFilter struct {
+	policy ClipboardPolicy
+	// max bytes of a copied text, the longer ones are dropped
+	maxSize int
+	// the start of a sequence not terminated yet, and the bytes of its
+	// body already checked
+	pending []byte
+	scanned int
+	// the start of a sequence too long is dropped, and the rest of it
+	// until its end
+	dropping bool
+}
+
+// filter returns the output without the sequences, and the texts of the
+// ones setting the clipboard, the ones reading it are never answered
+func (f *clipboardFilter) filter(data []byte) ([]byte, [][]byte) {
+	scanned := 0
+	if len(f.pending) > 0 {
+		data = append(f.pending, data...)
+		scanned = f.scanned
+		f.pending, f.scanned = nil, 0
+	}
+	var (
+		out   []byte
+		texts [][]byte
+	)
+	for len(data) > 0 {
+		start := 0
+		body := data
+		if !f.dropping {
+			if start = bytes.Index(data, osc52); start < 0 {
+				keep := partialPrefix(data, osc52)
+				out = append(out, data[:len(data)-keep]...)
+				if keep > 0 {
+					f.pending = append([]byte(nil), data[len(data)-keep:]...)
+				}
+				return out, texts
+			}
+			out = append(out, data[:start]...)
+			body = data[start+len(osc52):]
+		}
+
+		n := 0
+		if start == 0 {
+			n, scanned = scanned, 0
+		}
+		for n < len(body) && oscChar(body[n]) {
+			n++
+		}
+		if n == len(body) || n == len(body)-1 && body[n] == 0x1b {
+			// not terminated yet, the next read may start with the
+			// rest of ST
+			if !f.dropping && n > base64.StdEncoding.EncodedLen(f.maxSize)+16 {
+				f.dropping = true
+			}
+			if f.dropping {
+				if n < len(body) {
+					f.pending = []byte{0x1b}
+				}
+			} else {
+				f.pending = append([]byte(nil), data[start:]...)
+				f.scanned = n
+			}
+			return out, texts
+		}
+		// terminated, or aborted by another byte which the terminal
+		// handles without the start of the sequence
+		end := terminatorLen(body[n:])
+		if end > 0 && !f.dropping && f.policy != ClipboardOff {
+			if text := f.text(body[:n]); text != nil {
+				texts = append(texts, text)
+			}
+		}
+		f.dropping = false
+		data = body[n+end:]
+	}
+	return out, texts
+}
+
+// text is the text copied by the body of the sequence (the selections and
+// the base64 data), nil if it's a query, malformed or too long
+func (f *clipboardFilter) text(body []byte) []byte {
+	i := bytes.IndexByte(body, ';')
+	if i < 0 {
+		return nil
+	}
+	data := body[i+1:]
+	if len(data) == 0 || base64.StdEncoding.DecodedLen(len(data)) > f.maxSize+2 {
+		return nil
+	}
+	text, err := base64.StdEncoding.DecodeString(string(data))
+	if err != nil {
+		if text, err = base64.RawStdEncoding.DecodeString(string(data)); err != nil {
+			return nil
+		}
+	}
+	if len(text) == 0 || len(text) > f.maxSize {
+		return nil
+	}
+	return text
+}
+
+// oscChar tells whether the byte can be in the body of an OSC 52 sequence
+func oscChar(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9' ||
+		b == '+' || b == '/' || b == '=' || b == ';' || b == '?'
+}
+
+// terminatorLen is the length of the terminator the data starts with, 0
+// without one
+func terminatorLen(data []byte) int {
+	if len(data) > 0 && data[0] == 0x07 {
+		return 1
+	}
+	if len(data) > 1 && data[0] == 0x1b && data[1] == '\\' {
+		return 2
+	}
+	return 0
+}
+
+// partialPrefix is the length of the longest end of the data which is the
+// start of the prefix
+func partialPrefix(data, prefix []byte) int {
+	n := len(prefix) - 1
+	if n > len(data) {
+		n = len(data)
+	}
+	for ; n > 0; n-- {
+		if bytes.HasPrefix(prefix, data[len(data)-n:]) {
+			return n
+		}
+	}
+	return 0
+}
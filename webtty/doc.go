@@ -0,0 +1,7 @@
+// Package webtty provides a protocl and an implementation to
+// controll terminals thorough networks.
+//
+// It's forked from github.com/yudai/gotty/webtty (MIT License,
+// Copyright (c) 2015-2017 Iwasaki Yudai) with some additional
+// message types for the container terminals.
+package webtty
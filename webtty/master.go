@@ -0,0 +1,9 @@
+package webtty
+
+import (
+	"io"
+)
+
+// Master represents a PTY master, usually it's a websocket connection.
+// A Write is a whole message, the message is reused after the Write.
+type Master io.ReadWriter
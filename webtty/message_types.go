@@ -0,0 +1,69 @@
+package webtty
+
+// Protocols defines the name of this protocol,
+// which is supposed to be used to the subprotocol of Websockt streams.
+var Protocols = []string{"webtty"}
+
+const (
+	// Unknown message type, maybe sent by a bug
+	UnknownInput = '0'
+	// User input typically from a keyboard
+	Input = '1'
+	// Ping to the server
+	Ping = '2'
+	// Notify that the browser size has been changed
+	ResizeTerminal = '3'
+	// Enable the flow control, the payload is a JSON object
+	// with the max bytes of output not acknowledged yet
+	SetFlowWindow = '4'
+	// Acknowledge the output handled by the browser,
+	// the payload is the number of bytes
+	Ack = '5'
+	// Input of the bytes which may not be UTF-8, like the files sent by
+	// ZMODEM, the payload is base64 encoded
+	InputBinary = '6'
+	// A transfer of the binary input starts or ends, the payload is a
+	// JSON object with whether it's active, the output waits for the
+	// flow window during it instead of being dropped
+	Transfer = '7'
+)
+
+const (
+	// Unknown message type, maybe set by a bug
+	UnknownOutput = '0'
+	// Normal output to the terminal
+	Output = '1'
+	// Pong to the browser
+	Pong = '2'
+	// Set window title of the terminal
+	SetWindowTitle = '3'
+	// Set terminal preference
+	SetPreferences = '4'
+	// Make terminal to reconnect
+	SetReconnect = '5'
+	// Stderr output of the slave, only sent when the slave
+	// is not attached to a pseudo terminal
+	OutputStderr = '6'
+	// The process of the slave exited, the payload is a JSON
+	// object with its exit code, sent as the last message
+	Exited = '7'
+	// Output is dropped since the flow window is full, the payload
+	// is a JSON object with the number of bytes dropped
+	OutputTruncated = '8'
+	// Set the token to resume the session after reconnecting, the
+	// master sends it back with the init message of the new connection
+	SetResumeToken = '9'
+	// Show a notice over the terminal, the payload is a JSON object with
+	// the message and the seconds of its countdown, 0 without one
+	Notice = 'A'
+	// Set the max bytes of an input message, the payload is a JSON object
+	// with the bytes, the master splits the larger input (like a paste)
+	SetMaxInput = 'B'
+	// The session failed, the payload is a JSON object with the code and
+	// the message of types.SessionError, sent before closing
+	Error = 'C'
+	// Set the clipboard of the master by an OSC 52 sequence of the slave,
+	// the payload is a JSON object with the base64 text and whether the
+	// user is asked first
+	SetClipboard = 'D'
+)
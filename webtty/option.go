@@ -0,0 +1,144 @@
+package webtty
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Option is an option for WebTTY.
+type Option func(*WebTTY) error
+
+// WithPermitWrite sets a WebTTY to accept input from slaves.
+func WithPermitWrite() Option {
+	return func(wt *WebTTY) error {
+		wt.permitWrite = true
+		return nil
+	}
+}
+
+// WithBinaryInput sets a WebTTY to accept the binary input and the
+// transfers of the master, like the files of ZMODEM, they're ignored
+// without it. The input must be permitted too.
+func WithBinaryInput() Option {
+	return func(wt *WebTTY) error {
+		wt.permitBinary = true
+		return nil
+	}
+}
+
+// WithFixedColumns sets a fixed width to TTY master.
+func WithFixedColumns(columns int) Option {
+	return func(wt *WebTTY) error {
+		wt.columns = columns
+		return nil
+	}
+}
+
+// WithFixedRows sets a fixed height to TTY master.
+func WithFixedRows(rows int) Option {
+	return func(wt *WebTTY) error {
+		wt.rows = rows
+		return nil
+	}
+}
+
+// WithWindowTitle sets the default window title of the session
+func WithWindowTitle(windowTitle []byte) Option {
+	return func(wt *WebTTY) error {
+		wt.windowTitle = windowTitle
+		return nil
+	}
+}
+
+// WithReconnect enables reconnection on the master side.
+func WithReconnect(timeInSeconds int) Option {
+	return func(wt *WebTTY) error {
+		wt.reconnect = timeInSeconds
+		return nil
+	}
+}
+
+// WithResumeToken sets the token the master resumes the session with.
+func WithResumeToken(token string) Option {
+	return func(wt *WebTTY) error {
+		wt.resumeToken = token
+		return nil
+	}
+}
+
+// WithMaxInput sets the max bytes of an input message told to the master,
+// the buffer reading the master holds a message of it
+func WithMaxInput(size int) Option {
+	return func(wt *WebTTY) error {
+		if size <= 0 {
+			return errors.Errorf("bad max input %d", size)
+		}
+		wt.maxInput = size
+		return nil
+	}
+}
+
+// WithMasterPreferences sets an optional configuration of master.
+func WithMasterPreferences(preferences interface{}) Option {
+	return func(wt *WebTTY) error {
+		prefs, err := json.Marshal(preferences)
+		if err != nil {
+			return errors.Wrapf(err, "failed to marshal preferences as JSON")
+		}
+		wt.masterPrefs = prefs
+		return nil
+	}
+}
+
+// WithMOTD writes the message to the master before the output of the slave,
+// the lines end with CRLF as the terminal needs
+func WithMOTD(motd string) Option {
+	return func(wt *WebTTY) error {
+		lines := strings.Split(strings.TrimRight(motd, "\r\n"), "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimSuffix(line, "\r")
+		}
+		wt.motd = []byte(strings.Join(lines, "\r\n") + "\r\n")
+		return nil
+	}
+}
+
+// WithBufferSize sets the size of the buffers reading the slave and the master.
+func WithBufferSize(size int) Option {
+	return func(wt *WebTTY) error {
+		if size <= 0 {
+			return errors.Errorf("bad buffer size %d", size)
+		}
+		wt.bufferSize = size
+		return nil
+	}
+}
+
+// WithCoalescing coalesces the output of the slave into one message until the
+// window since the first byte passes or the size (8KB if 0) is reached.
+func WithCoalescing(window time.Duration, size int) Option {
+	return func(wt *WebTTY) error {
+		if size <= 0 {
+			size = 8 << 10
+		}
+		wt.coalesceWindow = window
+		wt.coalesceSize = size
+		return nil
+	}
+}
+
+// WithClipboard takes the OSC 52 sequences out of the output of the slave,
+// the texts copied by them up to the max bytes (1MB if 0) are sent to the
+// master unless the policy is off.
+func WithClipboard(policy ClipboardPolicy, maxSize int) Option {
+	return func(wt *WebTTY) error {
+		if maxSize <= 0 {
+			maxSize = 1 << 20
+		}
+		wt.clipboard = &clipboardFilter{policy: policy, maxSize: maxSize}
+		return nil
+	}
+}
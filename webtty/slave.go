@@ -0,0 +1,35 @@
+package webtty
+
+import (
+	"io"
+)
+
+// Slave represents a PTY slave, typically it's a local command.
+type Slave interface {
+	io.ReadWriter
+
+	// WindowTitleVariables returns any values that can be used to fill out
+	// the title of a terminal.
+	WindowTitleVariables() map[string]interface{}
+
+	// ResizeTerminal sets a new size of the terminal.
+	ResizeTerminal(columns int, rows int) error
+}
+
+// StderrSlave is a Slave which carries its stderr as a separate stream,
+// typically a command which is not attached to a pseudo terminal.
+type StderrSlave interface {
+	Slave
+
+	// Stderr returns the stderr stream of the slave,
+	// or nil if the stderr is merged into the output.
+	Stderr() io.Reader
+}
+
+// ExitSlave is a Slave which can report the exit status of its process.
+type ExitSlave interface {
+	Slave
+
+	// ExitCode waits for the process to exit and returns its exit code.
+	ExitCode() (int, error)
+}
@@ -0,0 +1,530 @@
+package webtty
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// WebTTY bridges a PTY slave and its PTY master.
+// To support text-based streams and side channel commands such as
+// terminal resizing, WebTTY uses an original protocol.
+type WebTTY struct {
+	// PTY Master, which probably a connection to browser
+	masterConn Master
+	// PTY Slave
+	slave Slave
+
+	windowTitle  []byte
+	permitWrite  bool
+	permitBinary bool
+	columns      int
+	rows         int
+	reconnect    int // in seconds
+	masterPrefs  []byte
+	resumeToken  string
+	motd         []byte
+
+	bufferSize int
+	// max bytes of an input message, 0 for bufferSize-1
+	maxInput   int
+	writeMutex sync.Mutex
+
+	// flow control, disabled until the master sets the window, the
+	// output waits for the window during a transfer until it's closed
+	flowMutex  sync.Mutex
+	flowCond   *sync.Cond
+	flowWindow int64
+	inflight   int64
+	dropped    int64
+	transfer   bool
+	flowClosed bool
+
+	// output coalescing, disabled if the window is 0
+	coalesceWindow time.Duration
+	coalesceSize   int
+	coalesceMutex  sync.Mutex
+	coalesced      []byte
+	coalesceTimer  *time.Timer
+	coalesceArmed  bool
+	coalesceErr    error
+
+	// the OSC 52 sequences of the output, left in it if it's nil
+	clipboard *clipboardFilter
+}
+
+// MaxFlowWindow is the upper limit of the flow window set by masters
+const MaxFlowWindow = 16 << 20
+
+// New creates a new instance of WebTTY.
+// masterConn is a connection to the PTY master,
+// typically it's a websocket connection to a client.
+// slave is a PTY slave such as a local command with a PTY.
+func New(masterConn Master, slave Slave, options ...Option) (*WebTTY, error) {
+	wt := &WebTTY{
+		masterConn: masterConn,
+		slave:      slave,
+
+		permitWrite: false,
+		columns:     0,
+		rows:        0,
+
+		bufferSize: 1024,
+	}
+	wt.flowCond = sync.NewCond(&wt.flowMutex)
+
+	for _, option := range options {
+		option(wt)
+	}
+
+	return wt, nil
+}
+
+// Run starts the main process of the WebTTY.
+// This method blocks until the context is canceled.
+// Note that the master and slave are left intact even
+// after the context is canceled. Closing them is caller's
+// responsibility.
+// If the connection to one end gets closed, returns ErrSlaveClosed or ErrMasterClosed.
+func (wt *WebTTY) Run(ctx context.Context) error {
+	err := wt.sendInitializeMessage()
+	if err != nil {
+		return errors.Wrapf(err, "failed to send initializing message")
+	}
+	defer wt.closeFlow()
+
+	errs := make(chan error, 2)
+
+	if ss, ok := wt.slave.(StderrSlave); ok {
+		if stderr := ss.Stderr(); stderr != nil {
+			go wt.relayStderr(stderr)
+		}
+	}
+
+	go func() {
+		errs <- func() error {
+			buffer := make([]byte, wt.bufferSize)
+			for {
+				n, err := wt.slave.Read(buffer)
+				if err != nil {
+					wt.flushCoalesced()
+					wt.sendExitCode()
+					return ErrSlaveClosed
+				}
+
+				err = wt.handleSlaveReadEvent(buffer[:n])
+				if err != nil {
+					return err
+				}
+			}
+		}()
+	}()
+
+	go func() {
+		errs <- func() error {
+			// a message is read at once, with its type
+			size := wt.bufferSize
+			if wt.maxInput+1 > size {
+				size = wt.maxInput + 1
+			}
+			buffer := make([]byte, size)
+			for {
+				n, err := wt.masterConn.Read(buffer)
+				if err != nil {
+					return ErrMasterClosed
+				}
+
+				err = wt.handleMasterReadEvent(buffer[:n])
+				if err != nil {
+					return err
+				}
+			}
+		}()
+	}()
+
+	select {
+	case <-ctx.Done():
+		err = ctx.Err()
+	case err = <-errs:
+	}
+
+	return err
+}
+
+func (wt *WebTTY) sendInitializeMessage() error {
+	err := wt.masterWrite(append([]byte{SetWindowTitle}, wt.windowTitle...))
+	if err != nil {
+		return errors.Wrapf(err, "failed to send window title")
+	}
+
+	if wt.reconnect > 0 {
+		reconnect, _ := json.Marshal(wt.reconnect)
+		err := wt.masterWrite(append([]byte{SetReconnect}, reconnect...))
+		if err != nil {
+			return errors.Wrapf(err, "failed to set reconnect")
+		}
+	}
+
+	if wt.resumeToken != "" {
+		err := wt.masterWrite(append([]byte{SetResumeToken}, wt.resumeToken...))
+		if err != nil {
+			return errors.Wrapf(err, "failed to set resume token")
+		}
+	}
+
+	if wt.maxInput > 0 {
+		maxInput, _ := json.Marshal(map[string]int{"bytes": wt.maxInput})
+		err := wt.masterWrite(append([]byte{SetMaxInput}, maxInput...))
+		if err != nil {
+			return errors.Wrapf(err, "failed to set max input")
+		}
+	}
+
+	if wt.masterPrefs != nil {
+		err := wt.masterWrite(append([]byte{SetPreferences}, wt.masterPrefs...))
+		if err != nil {
+			return errors.Wrapf(err, "failed to set preferences")
+		}
+	}
+
+	if wt.motd != nil {
+		if err := wt.sendOutput(Output, wt.motd); err != nil {
+			return errors.Wrapf(err, "failed to send motd")
+		}
+	}
+
+	return nil
+}
+
+func (wt *WebTTY) handleSlaveReadEvent(data []byte) error {
+	var texts [][]byte
+	if wt.clipboard != nil {
+		data, texts = wt.clipboard.filter(data)
+	}
+
+	if len(data) > 0 {
+		var err error
+		if wt.coalesceWindow > 0 {
+			err = wt.coalesce(data)
+		} else {
+			err = wt.sendOutput(Output, data)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to send message to master")
+		}
+	}
+
+	for _, text := range texts {
+		clipboard, _ := json.Marshal(map[string]interface{}{
+			"data": text,
+			"ask":  wt.clipboard.policy == ClipboardAsk,
+		})
+		if err := wt.masterWrite(append([]byte{SetClipboard}, clipboard...)); err != nil {
+			return errors.Wrapf(err, "failed to set clipboard")
+		}
+	}
+
+	return nil
+}
+
+// coalesce appends the output to the pending one, which is sent once it's
+// large enough, or when the window since the first pending byte passes
+func (wt *WebTTY) coalesce(data []byte) error {
+	wt.coalesceMutex.Lock()
+	defer wt.coalesceMutex.Unlock()
+
+	if wt.coalesceErr != nil {
+		return wt.coalesceErr
+	}
+	wt.coalesced = append(wt.coalesced, data...)
+	if len(wt.coalesced) >= wt.coalesceSize {
+		return wt.flushCoalescedLocked()
+	}
+	if !wt.coalesceArmed {
+		// the timer is reused, a stale fire flushes early at most
+		if wt.coalesceTimer == nil {
+			wt.coalesceTimer = time.AfterFunc(wt.coalesceWindow, wt.coalesceTimeout)
+		} else {
+			wt.coalesceTimer.Reset(wt.coalesceWindow)
+		}
+		wt.coalesceArmed = true
+	}
+	return nil
+}
+
+func (wt *WebTTY) coalesceTimeout() {
+	wt.coalesceMutex.Lock()
+	defer wt.coalesceMutex.Unlock()
+	if err := wt.flushCoalescedLocked(); err != nil {
+		// returned by the next output
+		wt.coalesceErr = err
+	}
+}
+
+// flushCoalesced sends the pending output, e.g. before the slave exits
+func (wt *WebTTY) flushCoalesced() error {
+	wt.coalesceMutex.Lock()
+	defer wt.coalesceMutex.Unlock()
+	return wt.flushCoalescedLocked()
+}
+
+func (wt *WebTTY) flushCoalescedLocked() error {
+	if wt.coalesceArmed {
+		wt.coalesceTimer.Stop()
+		wt.coalesceArmed = false
+	}
+	if len(wt.coalesced) == 0 {
+		return nil
+	}
+	err := wt.sendOutput(Output, wt.coalesced)
+	wt.coalesced = wt.coalesced[:0]
+	return err
+}
+
+// sendOutput sends the output of the slave to the master, if the flow
+// control is enabled and the window is full, the output is dropped and
+// the master is told how many bytes are dropped once there is room again.
+// During a transfer it waits for the room instead, as the transfer would
+// be corrupted by the dropped bytes, and the slave isn't read meanwhile.
+func (wt *WebTTY) sendOutput(typ byte, data []byte) error {
+	wt.flowMutex.Lock()
+	for wt.transfer && !wt.flowClosed && wt.flowFull(len(data)) {
+		wt.flowCond.Wait()
+	}
+	if wt.flowWindow > 0 {
+		if wt.flowFull(len(data)) {
+			wt.dropped += int64(len(data))
+			wt.flowMutex.Unlock()
+			return nil
+		}
+		wt.inflight += int64(len(data))
+	}
+	dropped := wt.dropped
+	wt.dropped = 0
+	wt.flowMutex.Unlock()
+
+	if dropped > 0 {
+		if err := wt.sendTruncated(dropped); err != nil {
+			return err
+		}
+	}
+	// encode into a pooled buffer, the master doesn't retain it
+	buf := GetBuffer(1 + base64.StdEncoding.EncodedLen(len(data)))
+	defer PutBuffer(buf)
+	(*buf)[0] = typ
+	base64.StdEncoding.Encode((*buf)[1:], data)
+	return wt.masterWrite(*buf)
+}
+
+// flowFull tells whether the window is full for n more bytes, something is
+// always sent if nothing is in flight
+func (wt *WebTTY) flowFull(n int) bool {
+	return wt.flowWindow > 0 && wt.inflight > 0 && wt.inflight+int64(n) > wt.flowWindow
+}
+
+// closeFlow stops the waits of the output when the WebTTY stops
+func (wt *WebTTY) closeFlow() {
+	wt.flowMutex.Lock()
+	wt.flowClosed = true
+	wt.flowCond.Broadcast()
+	wt.flowMutex.Unlock()
+}
+
+func (wt *WebTTY) sendTruncated(dropped int64) error {
+	truncated, _ := json.Marshal(map[string]int64{"dropped": dropped})
+	return wt.masterWrite(append([]byte{OutputTruncated}, truncated...))
+}
+
+// relayStderr sends the stderr of the slave to the master until
+// the stream is closed, closing stderr doesn't close the session
+func (wt *WebTTY) relayStderr(stderr io.Reader) {
+	buffer := make([]byte, wt.bufferSize)
+	for {
+		n, err := stderr.Read(buffer)
+		if n > 0 {
+			if wt.sendOutput(OutputStderr, buffer[:n]) != nil {
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// sendExitCode tells the master how the process of the slave exited
+func (wt *WebTTY) sendExitCode() {
+	es, ok := wt.slave.(ExitSlave)
+	if !ok {
+		return
+	}
+	code, err := es.ExitCode()
+	if err != nil {
+		return
+	}
+	exited, _ := json.Marshal(map[string]int{"code": code})
+	wt.masterWrite(append([]byte{Exited}, exited...))
+}
+
+// Notify shows the message to the master, with a countdown of the
+// seconds if it's not 0
+func (wt *WebTTY) Notify(message string, seconds int) error {
+	notice, _ := json.Marshal(map[string]interface{}{
+		"message": message,
+		"seconds": seconds,
+	})
+	return wt.masterWrite(append([]byte{Notice}, notice...))
+}
+
+func (wt *WebTTY) masterWrite(data []byte) error {
+	wt.writeMutex.Lock()
+	defer wt.writeMutex.Unlock()
+
+	_, err := wt.masterConn.Write(data)
+	if err != nil {
+		return errors.Wrapf(err, "failed to write to master")
+	}
+
+	return nil
+}
+
+func (wt *WebTTY) handleMasterReadEvent(data []byte) error {
+	if len(data) == 0 {
+		return errors.New("unexpected zero length read from master")
+	}
+
+	switch data[0] {
+	case Input:
+		if !wt.permitWrite {
+			return nil
+		}
+
+		if len(data) <= 1 {
+			return nil
+		}
+
+		_, err := wt.slave.Write(data[1:])
+		if err != nil {
+			return errors.Wrapf(err, "failed to write received data to slave")
+		}
+
+	case InputBinary:
+		if !wt.permitWrite || !wt.permitBinary {
+			return nil
+		}
+
+		input, err := base64.StdEncoding.DecodeString(string(data[1:]))
+		if err != nil {
+			return errors.Wrapf(err, "received malformed data for binary input")
+		}
+		if len(input) == 0 {
+			return nil
+		}
+		if _, err := wt.slave.Write(input); err != nil {
+			return errors.Wrapf(err, "failed to write received data to slave")
+		}
+
+	case Ping:
+		err := wt.masterWrite([]byte{Pong})
+		if err != nil {
+			return errors.Wrapf(err, "failed to return Pong message to master")
+		}
+
+	case ResizeTerminal:
+		if wt.columns != 0 && wt.rows != 0 {
+			break
+		}
+
+		if len(data) <= 1 {
+			return errors.New("received malformed remote command for terminal resize: empty payload")
+		}
+
+		var args argResizeTerminal
+		err := json.Unmarshal(data[1:], &args)
+		if err != nil {
+			return errors.Wrapf(err, "received malformed data for terminal resize")
+		}
+		rows := wt.rows
+		if rows == 0 {
+			rows = int(args.Rows)
+		}
+
+		columns := wt.columns
+		if columns == 0 {
+			columns = int(args.Columns)
+		}
+
+		wt.slave.ResizeTerminal(columns, rows)
+
+	case SetFlowWindow:
+		var args argSetFlowWindow
+		if err := json.Unmarshal(data[1:], &args); err != nil {
+			return errors.Wrapf(err, "received malformed data for flow window")
+		}
+		if args.Window > MaxFlowWindow {
+			args.Window = MaxFlowWindow
+		}
+		wt.flowMutex.Lock()
+		wt.flowWindow = args.Window
+		wt.flowCond.Broadcast()
+		wt.flowMutex.Unlock()
+
+	case Transfer:
+		if !wt.permitWrite || !wt.permitBinary {
+			return nil
+		}
+		var args argTransfer
+		if err := json.Unmarshal(data[1:], &args); err != nil {
+			return errors.Wrapf(err, "received malformed data for transfer")
+		}
+		wt.flowMutex.Lock()
+		wt.transfer = args.Active
+		wt.flowCond.Broadcast()
+		wt.flowMutex.Unlock()
+
+	case Ack:
+		n, err := strconv.ParseInt(string(data[1:]), 10, 64)
+		if err != nil {
+			return errors.Wrapf(err, "received malformed data for ack")
+		}
+		wt.flowMutex.Lock()
+		if wt.inflight -= n; wt.inflight < 0 {
+			wt.inflight = 0
+		}
+		dropped := wt.dropped
+		wt.dropped = 0
+		wt.flowCond.Broadcast()
+		wt.flowMutex.Unlock()
+
+		// tell the master as soon as possible, the slave may be quiet now
+		if dropped > 0 {
+			if err := wt.sendTruncated(dropped); err != nil {
+				return errors.Wrapf(err, "failed to send truncated message to master")
+			}
+		}
+
+	default:
+		return errors.Errorf("unknown message type `%c`", data[0])
+	}
+
+	return nil
+}
+
+type argSetFlowWindow struct {
+	Window int64 `json:"window"`
+}
+
+type argTransfer struct {
+	Active bool `json:"active"`
+}
+
+type argResizeTerminal struct {
+	Columns float64
+	Rows    float64
+}
@@ -0,0 +1,463 @@
+package webtty
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+type pipePair struct {
+	*io.PipeReader
+	*io.PipeWriter
+}
+
+type testSlave struct {
+	pipePair
+	stderr io.Reader
+}
+
+func (s *testSlave) WindowTitleVariables() map[string]interface{} { return nil }
+
+func (s *testSlave) ResizeTerminal(columns int, rows int) error { return nil }
+
+func (s *testSlave) Stderr() io.Reader { return s.stderr }
+
+func readMessage(t *testing.T, r io.Reader) (byte, []byte) {
+	buf := make([]byte, 1024)
+	n, err := r.Read(buf)
+	if err != nil {
+		t.Fatalf("Unexpected error from Read(): %s", err)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(string(buf[1:n]))
+	if err != nil {
+		t.Fatalf("Unexpected error from Decode(): %s", err)
+	}
+	return buf[0], decoded
+}
+
+func TestWriteFromSlave(t *testing.T) {
+	masterR, masterW := io.Pipe() // written by webtty
+	inR, _ := io.Pipe()           // read by webtty
+	slaveR, slaveW := io.Pipe()
+	stderrR, stderrW := io.Pipe()
+
+	slave := &testSlave{
+		pipePair: pipePair{slaveR, nil},
+		stderr:   stderrR,
+	}
+	wt, err := New(pipePair{inR, masterW}, slave)
+	if err != nil {
+		t.Fatalf("Unexpected error from New(): %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go wt.Run(ctx)
+
+	// window title
+	if typ, _ := readMessage(t, masterR); typ != SetWindowTitle {
+		t.Fatalf("Unexpected message type `%c`", typ)
+	}
+
+	for _, c := range []struct {
+		w   io.Writer
+		typ byte
+		msg string
+	}{
+		{slaveW, Output, "foobar"},
+		{stderrW, OutputStderr, "no such file"},
+	} {
+		go c.w.Write([]byte(c.msg))
+		typ, decoded := readMessage(t, masterR)
+		if typ != c.typ {
+			t.Fatalf("Unexpected message type `%c`", typ)
+		}
+		if !bytes.Equal(decoded, []byte(c.msg)) {
+			t.Fatalf("Unexpected message received: `%s`", decoded)
+		}
+	}
+}
+
+type exitSlave struct {
+	testSlave
+	code int
+}
+
+func (s *exitSlave) ExitCode() (int, error) { return s.code, nil }
+
+func TestExitCode(t *testing.T) {
+	masterR, masterW := io.Pipe()
+	inR, _ := io.Pipe()
+	slaveR, slaveW := io.Pipe()
+
+	slave := &exitSlave{
+		testSlave: testSlave{pipePair: pipePair{slaveR, nil}},
+		code:      137,
+	}
+	wt, err := New(pipePair{inR, masterW}, slave)
+	if err != nil {
+		t.Fatalf("Unexpected error from New(): %s", err)
+	}
+
+	errs := make(chan error, 1)
+	go func() { errs <- wt.Run(context.Background()) }()
+
+	// window title
+	buf := make([]byte, 1024)
+	masterR.Read(buf)
+
+	slaveW.Close()
+	n, err := masterR.Read(buf)
+	if err != nil {
+		t.Fatalf("Unexpected error from Read(): %s", err)
+	}
+	if string(buf[:n]) != `7{"code":137}` {
+		t.Fatalf("Unexpected message received: `%s`", buf[:n])
+	}
+	if err := <-errs; err != ErrSlaveClosed {
+		t.Fatalf("Unexpected error from Run(): %s", err)
+	}
+}
+
+// recordMaster records the messages written by webtty
+type recordMaster struct {
+	io.Reader
+	messages []string
+}
+
+func (m *recordMaster) Write(p []byte) (int, error) {
+	m.messages = append(m.messages, string(p))
+	return len(p), nil
+}
+
+func TestFlowControl(t *testing.T) {
+	master := &recordMaster{}
+	wt, err := New(master, &testSlave{})
+	if err != nil {
+		t.Fatalf("Unexpected error from New(): %s", err)
+	}
+
+	for _, step := range []struct {
+		input  string // from the master
+		output string // from the slave
+		expect []string
+	}{
+		{input: `4{"window":10}`},
+		{output: "0123456789", expect: []string{"1MDEyMzQ1Njc4OQ=="}},
+		// the window is full
+		{output: "abc"},
+		{output: "de"},
+		// told as soon as there is room
+		{input: "54", expect: []string{`8{"dropped":5}`}},
+		{output: "ab", expect: []string{"1YWI="}},
+		{output: "cdefg"},
+		{input: "510", expect: []string{`8{"dropped":5}`}},
+	} {
+		master.messages = nil
+		if step.input != "" {
+			if err := wt.handleMasterReadEvent([]byte(step.input)); err != nil {
+				t.Fatalf("Unexpected error from handleMasterReadEvent(): %s", err)
+			}
+		}
+		if step.output != "" {
+			if err := wt.sendOutput(Output, []byte(step.output)); err != nil {
+				t.Fatalf("Unexpected error from sendOutput(): %s", err)
+			}
+		}
+		if len(master.messages) != len(step.expect) {
+			t.Fatalf("Unexpected messages of %+v: %q", step, master.messages)
+		}
+		for i := range step.expect {
+			if master.messages[i] != step.expect[i] {
+				t.Fatalf("Unexpected messages of %+v: %q", step, master.messages)
+			}
+		}
+	}
+}
+
+func TestFlowControlTransfer(t *testing.T) {
+	master := &recordMaster{}
+	wt, err := New(master, &testSlave{}, WithPermitWrite(), WithBinaryInput())
+	if err != nil {
+		t.Fatalf("Unexpected error from New(): %s", err)
+	}
+	for _, input := range []string{`4{"window":10}`, `7{"active":true}`} {
+		if err := wt.handleMasterReadEvent([]byte(input)); err != nil {
+			t.Fatalf("Unexpected error from handleMasterReadEvent(): %s", err)
+		}
+	}
+	if err := wt.sendOutput(Output, []byte("0123456789")); err != nil {
+		t.Fatalf("Unexpected error from sendOutput(): %s", err)
+	}
+
+	// waits for the room instead of dropping the output
+	sent := make(chan error)
+	go func() { sent <- wt.sendOutput(Output, []byte("abc")) }()
+	select {
+	case <-sent:
+		t.Fatal("Unexpected output sent beyond the window")
+	case <-time.After(50 * time.Millisecond):
+	}
+	if err := wt.handleMasterReadEvent([]byte("54")); err != nil {
+		t.Fatalf("Unexpected error from handleMasterReadEvent(): %s", err)
+	}
+	if err := <-sent; err != nil {
+		t.Fatalf("Unexpected error from sendOutput(): %s", err)
+	}
+	if len(master.messages) != 2 || master.messages[1] != "1YWJj" {
+		t.Fatalf("Unexpected messages: %q", master.messages)
+	}
+
+	// and dropped again after the transfer
+	if err := wt.handleMasterReadEvent([]byte(`7{"active":false}`)); err != nil {
+		t.Fatalf("Unexpected error from handleMasterReadEvent(): %s", err)
+	}
+	if err := wt.sendOutput(Output, []byte("defgh")); err != nil {
+		t.Fatalf("Unexpected error from sendOutput(): %s", err)
+	}
+	if len(master.messages) != 2 {
+		t.Fatalf("Unexpected messages: %q", master.messages)
+	}
+
+	// a waiting output is released when the WebTTY stops
+	wt.handleMasterReadEvent([]byte(`7{"active":true}`))
+	go func() { sent <- wt.sendOutput(Output, []byte("ijklmnop")) }()
+	wt.closeFlow()
+	select {
+	case <-sent:
+	case <-time.After(time.Second):
+		t.Fatal("Output still waiting after closing")
+	}
+}
+
+// chanMaster sends the messages written by webtty to the channel
+type chanMaster struct {
+	io.Reader
+	messages chan string
+}
+
+func (m *chanMaster) Write(p []byte) (int, error) {
+	m.messages <- string(p)
+	return len(p), nil
+}
+
+func TestCoalescing(t *testing.T) {
+	master := &chanMaster{messages: make(chan string, 10)}
+	wt, err := New(master, &testSlave{}, WithCoalescing(20*time.Millisecond, 8))
+	if err != nil {
+		t.Fatalf("Unexpected error from New(): %s", err)
+	}
+
+	for _, output := range []string{"ab", "cd"} {
+		if err := wt.handleSlaveReadEvent([]byte(output)); err != nil {
+			t.Fatalf("Unexpected error from handleSlaveReadEvent(): %s", err)
+		}
+	}
+	select {
+	case msg := <-master.messages:
+		t.Fatalf("Unexpected message before the window passes: %q", msg)
+	default:
+	}
+	// sent after the window
+	if msg := <-master.messages; msg != "1YWJjZA==" {
+		t.Fatalf("Unexpected message received: %q", msg)
+	}
+
+	// sent at once if it's large enough
+	wt.handleSlaveReadEvent([]byte("0123456789"))
+	select {
+	case msg := <-master.messages:
+		if msg != "1MDEyMzQ1Njc4OQ==" {
+			t.Fatalf("Unexpected message received: %q", msg)
+		}
+	default:
+		t.Fatal("Expect a message of the full buffer")
+	}
+
+	wt.handleSlaveReadEvent([]byte("ab"))
+	wt.flushCoalesced()
+	if msg := <-master.messages; msg != "1YWI=" {
+		t.Fatalf("Unexpected message received: %q", msg)
+	}
+}
+
+// discardMaster counts the bytes written by webtty
+type discardMaster struct {
+	io.Reader
+	n int
+}
+
+func (m *discardMaster) Write(p []byte) (int, error) {
+	m.n += len(p)
+	return len(p), nil
+}
+
+func benchmarkSendOutput(b *testing.B, size int) {
+	wt, err := New(&discardMaster{}, &testSlave{})
+	if err != nil {
+		b.Fatalf("Unexpected error from New(): %s", err)
+	}
+	data := bytes.Repeat([]byte("x"), size)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(size))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := wt.sendOutput(Output, data); err != nil {
+			b.Fatalf("Unexpected error from sendOutput(): %s", err)
+		}
+	}
+}
+
+func BenchmarkSendOutput1K(b *testing.B)  { benchmarkSendOutput(b, 1<<10) }
+func BenchmarkSendOutput32K(b *testing.B) { benchmarkSendOutput(b, 32<<10) }
+
+// benchmarkRelay relays the output of a slave like `yes` to the master
+func benchmarkRelay(b *testing.B, options ...Option) {
+	output := bytes.Repeat([]byte("y\r\n"), 1<<20)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(output)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		inR, inW := io.Pipe()
+		slaveR, slaveW := io.Pipe()
+		wt, err := New(&discardMaster{Reader: inR}, &testSlave{pipePair: pipePair{slaveR, nil}}, options...)
+		if err != nil {
+			b.Fatalf("Unexpected error from New(): %s", err)
+		}
+		go func() {
+			slaveW.Write(output)
+			slaveW.Close()
+		}()
+		if err := wt.Run(context.Background()); err != ErrSlaveClosed {
+			b.Fatalf("Unexpected error from Run(): %s", err)
+		}
+		inW.Close()
+	}
+}
+
+func BenchmarkRelay(b *testing.B)    { benchmarkRelay(b) }
+func BenchmarkRelay32K(b *testing.B) { benchmarkRelay(b, WithBufferSize(32<<10)) }
+func BenchmarkRelayCoalesced(b *testing.B) {
+	benchmarkRelay(b, WithCoalescing(5*time.Millisecond, 8<<10))
+}
+
+func TestMOTD(t *testing.T) {
+	masterR, masterW := io.Pipe()
+	inR, _ := io.Pipe()
+	slaveR, slaveW := io.Pipe()
+
+	wt, err := New(pipePair{inR, masterW}, &testSlave{pipePair: pipePair{slaveR, nil}},
+		WithMOTD("This session is recorded\nby the audit\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error from New(): %s", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go wt.Run(ctx)
+
+	if typ, _ := readMessage(t, masterR); typ != SetWindowTitle {
+		t.Fatalf("Unexpected message type `%c`", typ)
+	}
+	// before the output of the slave
+	go slaveW.Write([]byte("$ "))
+	for _, msg := range []string{"This session is recorded\r\nby the audit\r\n", "$ "} {
+		typ, decoded := readMessage(t, masterR)
+		if typ != Output || string(decoded) != msg {
+			t.Fatalf("Unexpected message `%c` %q", typ, decoded)
+		}
+	}
+}
+
+func TestBinaryInput(t *testing.T) {
+	masterR, masterW := io.Pipe()
+	inR, inW := io.Pipe()
+	slaveR, _ := io.Pipe()
+	inputR, inputW := io.Pipe()
+
+	wt, err := New(pipePair{inR, masterW}, &testSlave{pipePair: pipePair{slaveR, inputW}},
+		WithPermitWrite(), WithBinaryInput())
+	if err != nil {
+		t.Fatalf("Unexpected error from New(): %s", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go wt.Run(ctx)
+	go io.Copy(ioutil.Discard, masterR)
+
+	// not UTF-8, like a ZMODEM header
+	input := []byte("*\x18C\x04\x00\x00\x00\x00\xff\x90")
+	go inW.Write(append([]byte{InputBinary}, base64.StdEncoding.EncodeToString(input)...))
+	buf := make([]byte, 1024)
+	n, err := inputR.Read(buf)
+	if err != nil {
+		t.Fatalf("Unexpected error from Read(): %s", err)
+	}
+	if !bytes.Equal(buf[:n], input) {
+		t.Fatalf("Unexpected input %q", buf[:n])
+	}
+
+	// ignored without WithBinaryInput
+	wt, err = New(pipePair{inR, masterW}, &testSlave{pipePair: pipePair{slaveR, inputW}},
+		WithPermitWrite())
+	if err != nil {
+		t.Fatalf("Unexpected error from New(): %s", err)
+	}
+	handled := make(chan error, 1)
+	go func() {
+		handled <- wt.handleMasterReadEvent(append([]byte{InputBinary},
+			base64.StdEncoding.EncodeToString(input)...))
+	}()
+	select {
+	case err := <-handled:
+		if err != nil {
+			t.Fatalf("Unexpected error from handleMasterReadEvent(): %s", err)
+		}
+	case <-time.After(time.Second):
+		n, _ := inputR.Read(buf)
+		t.Fatalf("Unexpected input %q", buf[:n])
+	}
+}
+
+func TestClipboardFilter(t *testing.T) {
+	copied := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte("hello")) + "\a"
+	for _, c := range []struct {
+		name   string
+		policy ClipboardPolicy
+		reads  []string
+		output string
+		texts  []string
+	}{
+		{"bel", ClipboardOn, []string{"a" + copied + "b"}, "ab", []string{"hello"}},
+		{"st", ClipboardAsk, []string{"\x1b]52;;aGk=\x1b\\$ "}, "$ ", []string{"hi"}},
+		{"split", ClipboardOn, []string{"a\x1b]5", "2;c;aG", "k=\x1b", "\\b"}, "ab", []string{"hi"}},
+		{"off", ClipboardOff, []string{copied + "$ "}, "$ ", nil},
+		{"query", ClipboardOn, []string{"\x1b]52;c;?\a$ "}, "$ ", nil},
+		{"too long", ClipboardOn, []string{"\x1b]52;c;aGVsbG8gd29y", "bGQ=", "\a$ "}, "$ ", nil},
+		{"dropped", ClipboardOn, []string{"\x1b]52;c;" + strings.Repeat("QUFB", 10), "QUFB\x1b", "\\$ "}, "$ ", nil},
+		{"aborted", ClipboardOn, []string{"\x1b]52;c;aGk=\r\n$ "}, "\r\n$ ", nil},
+		{"other osc", ClipboardOn, []string{"\x1b]0;title\a"}, "\x1b]0;title\a", nil},
+	} {
+		f := &clipboardFilter{policy: c.policy, maxSize: 5}
+		var (
+			output string
+			texts  []string
+		)
+		for _, read := range c.reads {
+			out, copied := f.filter([]byte(read))
+			output += string(out)
+			for _, text := range copied {
+				texts = append(texts, string(text))
+			}
+		}
+		if output != c.output || strings.Join(texts, ",") != strings.Join(c.texts, ",") {
+			t.Errorf("%s: unexpected output %q and texts %q", c.name, output, texts)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"syscall"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/urfave/cli.v2"
+	"gopkg.in/yaml.v2"
+
+	"github.com/wrfly/container-web-tty/config"
+	"github.com/wrfly/container-web-tty/route"
+	"github.com/wrfly/container-web-tty/types"
+)
+
+// defaultConfigFile is the config file written by the setup, read if
+// --config is not set
+const defaultConfigFile = "container-web-tty.yaml"
+
+// configPath is the config file of --config, or the one written by the
+// setup if it exists
+func configPath(c *cli.Context) string {
+	if path := c.String("config"); path != "" {
+		return path
+	}
+	if _, err := os.Stat(defaultConfigFile); err == nil {
+		return defaultConfigFile
+	}
+	return ""
+}
+
+// needsSetup tells if the server is started without a config file and the
+// admin token, so it runs the setup instead of serving the terminals to
+// anyone
+func needsSetup(c *cli.Context, conf *config.Config) bool {
+	return !c.Bool("no-setup") && configPath(c) == "" &&
+		conf.Server.AdminToken == "" && conf.Server.Port > 0 &&
+		// not run by the systemd socket activation
+		os.Getenv("LISTEN_FDS") == ""
+}
+
+// runSetup serves the setup page on the address and port until the config
+// is submitted, and returns the config file it's written to. The options
+// set by the args or the env are not changed by it.
+func runSetup(c *cli.Context, conf *config.Config) (string, error) {
+	path, err := filepath.Abs(defaultConfigFile)
+	if err != nil {
+		return "", err
+	}
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	code := hex.EncodeToString(b)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigs)
+	go func() {
+		select {
+		case <-sigs:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	hostPort := net.JoinHostPort(conf.Server.Address, strconv.Itoa(conf.Server.Port))
+	logrus.Warnf("no config file and no admin token, serving the setup at http://%s/ instead of the terminals (--no-setup to skip it)", hostPort)
+	logrus.Warnf("the setup code is %s", code)
+	defaults := types.SetupConfig{
+		Backend:     conf.Backend.Type,
+		DockerHost:  conf.Backend.Docker.DockerHost,
+		KubeConfig:  conf.Backend.Kube.ConfigPath,
+		GRPCServers: conf.Backend.GRPC.Servers,
+		Address:     conf.Server.Address,
+		Port:        conf.Server.Port,
+	}
+	for flag, name := range map[string]string{
+		"backend":      "backend",
+		"docker-host":  "docker_host",
+		"kube-config":  "kube_config",
+		"grpc-servers": "grpc_servers",
+		"addr":         "address",
+		"port":         "port",
+	} {
+		if c.IsSet(flag) {
+			defaults.Fixed = append(defaults.Fixed, name)
+		}
+	}
+	_, err = route.RunSetup(ctx, hostPort, code, defaults, func(s types.SetupConfig) error {
+		return writeSetupConfig(path, s)
+	})
+	if err != nil {
+		return "", err
+	}
+	logrus.Infof("the config is saved to %s", path)
+	return path, nil
+}
+
+// writeSetupConfig writes the config file of the setup, readable by the
+// owner only since the admin token is in it
+func writeSetupConfig(path string, s types.SetupConfig) error {
+	doc := yaml.MapSlice{
+		{Key: "admin-token", Value: s.AdminToken},
+		{Key: "addr", Value: s.Address},
+		{Key: "port", Value: s.Port},
+		{Key: "backend", Value: s.Backend},
+	}
+	switch s.Backend {
+	case "docker":
+		if s.DockerHost != "" {
+			doc = append(doc, yaml.MapItem{Key: "docker-host", Value: s.DockerHost})
+		}
+	case "kube":
+		if s.KubeConfig != "" {
+			doc = append(doc, yaml.MapItem{Key: "kube-config", Value: s.KubeConfig})
+		}
+	case "grpc":
+		doc = append(doc, yaml.MapItem{Key: "grpc-servers", Value: s.GRPCServers})
+	}
+	bs, err := yaml.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	header := "# written by the setup, the other options are of `container-web-tty --help`\n"
+	if _, err := f.WriteString(header + string(bs)); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}